@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controllerscheme "github.com/elastic/cloud-on-k8s/pkg/controller/common/scheme"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+)
+
+// Command returns the command printing the credentials of the elastic user of an Elasticsearch cluster.
+func Command() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "get-credentials <namespace>/<name>",
+		Short: "Print the endpoint and the elastic user credentials of an Elasticsearch cluster",
+		Long: "Print the endpoint and the elastic user credentials of an Elasticsearch cluster. " +
+			"The password is redacted if the current user is not allowed to get the Secret holding it.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != textOutput && output != jsonOutput {
+				return fmt.Errorf("unsupported output format %s, expected %s or %s", output, textOutput, jsonOutput)
+			}
+			esNSN, err := k8s.ParseNamespacedName(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, err := ctrl.GetConfig()
+			if err != nil {
+				return err
+			}
+			controllerscheme.SetupScheme()
+			c, err := client.New(cfg, client.Options{Scheme: clientgoscheme.Scheme})
+			if err != nil {
+				return err
+			}
+			clientset, err := kubernetes.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			creds, err := getCredentials(cmd.Context(), c, clientset, esNSN)
+			if err != nil {
+				return err
+			}
+			if creds.Redacted {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: not allowed to get the secret holding the password of Elasticsearch %s\n", esNSN)
+			}
+			return printCredentials(cmd.OutOrStdout(), creds, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", textOutput, fmt.Sprintf("Output format, one of: %s, %s", textOutput, jsonOutput))
+
+	return cmd
+}
+
+func printCredentials(out io.Writer, creds Credentials, output string) error {
+	if output == jsonOutput {
+		return json.NewEncoder(out).Encode(creds)
+	}
+	_, err := fmt.Fprintf(out, "Endpoint: %s\nUsername: %s\nPassword: %s\n", creds.Endpoint, creds.Username, creds.Password)
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const redactedPassword = "<redacted>"
+
+// Credentials of the elastic user of an Elasticsearch cluster.
+type Credentials struct {
+	Endpoint string `json:"endpoint"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Redacted is true if the current user is not allowed to read the password.
+	Redacted bool `json:"redacted"`
+}
+
+// canGetSecret checks whether the current user is allowed to get the given Secret.
+func canGetSecret(ctx context.Context, clientset kubernetes.Interface, secret types.NamespacedName) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: secret.Namespace,
+				Verb:      "get",
+				Resource:  "secrets",
+				Name:      secret.Name,
+			},
+		},
+	}
+	review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed && !review.Status.Denied, nil
+}
+
+// getCredentials returns the endpoint and the credentials of the elastic user of the given Elasticsearch cluster.
+// The password is redacted if the current user is not allowed to read the Secret holding it.
+func getCredentials(ctx context.Context, c k8s.Client, clientset kubernetes.Interface, esNSN types.NamespacedName) (Credentials, error) {
+	var es esv1.Elasticsearch
+	if err := c.Get(ctx, esNSN, &es); err != nil {
+		return Credentials{}, err
+	}
+
+	creds := Credentials{
+		Endpoint: services.ExternalServiceURL(es),
+		Username: user.ElasticUserName,
+	}
+
+	secretNSN := types.NamespacedName{Namespace: es.Namespace, Name: esv1.ElasticUserSecret(es.Name)}
+	allowed, err := canGetSecret(ctx, clientset, secretNSN)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if !allowed {
+		creds.Password = redactedPassword
+		creds.Redacted = true
+		return creds, nil
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, secretNSN, &secret); err != nil {
+		return Credentials{}, err
+	}
+	password, exists := secret.Data[user.ElasticUserName]
+	if !exists {
+		return Credentials{}, fmt.Errorf("key %s not found in secret %s", user.ElasticUserName, secretNSN)
+	}
+	creds.Password = string(password)
+	return creds, nil
+}
@@ -0,0 +1,79 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// newClientset returns a fake clientset answering self subject access reviews with the given result.
+func newClientset(allowed bool) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed
+		return true, review, nil
+	})
+	return clientset
+}
+
+func Test_getCredentials(t *testing.T) {
+	esNSN := types.NamespacedName{Namespace: "ns", Name: "es"}
+	objs := []runtime.Object{
+		&esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es-es-elastic-user"},
+			Data:       map[string][]byte{"elastic": []byte("changeme")},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		allowed bool
+		want    Credentials
+	}{
+		{
+			name:    "allowed to get the secret",
+			allowed: true,
+			want: Credentials{
+				Endpoint: "https://es-es-http.ns.svc:9200",
+				Username: "elastic",
+				Password: "changeme",
+			},
+		},
+		{
+			name:    "not allowed to get the secret",
+			allowed: false,
+			want: Credentials{
+				Endpoint: "https://es-es-http.ns.svc:9200",
+				Username: "elastic",
+				Password: redactedPassword,
+				Redacted: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.NewFakeClient(objs...)
+			got, err := getCredentials(context.Background(), c, newClientset(tt.allowed), esNSN)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
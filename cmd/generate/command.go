@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/examples"
+)
+
+// Command returns the command generating manifests.
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate manifests of Elastic Stack resources",
+	}
+	cmd.AddCommand(exampleCommand())
+	return cmd
+}
+
+func exampleCommand() *cobra.Command {
+	var opts examples.Options
+	var profile string
+
+	profiles := make([]string, len(examples.Profiles))
+	for i, p := range examples.Profiles {
+		profiles[i] = string(p)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "example",
+		Short: "Generate a ready-to-apply example manifest for a common topology",
+		Example: "  elastic-operator generate example --kind Elasticsearch --version 8.x --profile hot-warm\n" +
+			"  elastic-operator generate example --kind Kibana --version 7.15.2 --name quickstart | kubectl apply -f -",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.Profile = examples.Profile(profile)
+			objs, err := examples.Generate(opts)
+			if err != nil {
+				return err
+			}
+			out, err := examples.ToYAML(objs)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Kind, "kind", esv1.Kind,
+		fmt.Sprintf("Kind of the resource to generate along with its dependencies, one of: %s", strings.Join(examples.Kinds(), ", ")))
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the resource to generate (defaults to <kind>-example)")
+	cmd.Flags().StringVar(&opts.Namespace, "namespace", "", "Namespace of the generated resources")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Version of the Elastic Stack, either a full version or the latest known version of a major version such as 8.x")
+	cmd.Flags().StringVar(&profile, "profile", string(examples.DefaultProfile),
+		fmt.Sprintf("Topology of the Elasticsearch cluster, one of: %s", strings.Join(profiles, ", ")))
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
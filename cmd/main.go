@@ -8,7 +8,12 @@ import (
 	"github.com/spf13/cobra"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 
+	"github.com/elastic/cloud-on-k8s/cmd/credentials"
+	"github.com/elastic/cloud-on-k8s/cmd/generate"
 	"github.com/elastic/cloud-on-k8s/cmd/manager"
+	"github.com/elastic/cloud-on-k8s/cmd/replay"
+	"github.com/elastic/cloud-on-k8s/cmd/restart"
+	"github.com/elastic/cloud-on-k8s/cmd/webhookrelay"
 	"github.com/elastic/cloud-on-k8s/pkg/about"
 	"github.com/elastic/cloud-on-k8s/pkg/dev"
 )
@@ -23,6 +28,11 @@ func main() {
 		SilenceUsage: true,
 	}
 	rootCmd.AddCommand(manager.Command())
+	rootCmd.AddCommand(generate.Command())
+	rootCmd.AddCommand(restart.Command())
+	rootCmd.AddCommand(credentials.Command())
+	rootCmd.AddCommand(webhookrelay.Command())
+	rootCmd.AddCommand(replay.Command())
 
 	// development mode is only available as a command line flag to avoid accidentally enabling it
 	rootCmd.PersistentFlags().BoolVar(&dev.Enabled, "development", false, "turns on development mode")
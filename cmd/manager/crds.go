@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+)
+
+// crdDiscoveryInterval is the interval at which the API server is polled for newly installed CRDs.
+const crdDiscoveryInterval = 1 * time.Minute
+
+// controllerRegistration registers a controller requiring the given kinds to be served by the API server.
+type controllerRegistration struct {
+	name     string
+	kinds    []schema.GroupVersionKind
+	register func() error
+}
+
+// lazyRegistry registers controllers only once the CRDs of the kinds they watch are installed, so that a partial
+// installation of the ECK CRDs does not prevent the operator from starting, nor make it watch kinds that do not exist.
+type lazyRegistry struct {
+	discovery discovery.DiscoveryInterface
+	pending   []controllerRegistration
+}
+
+func newLazyRegistry(d discovery.DiscoveryInterface, registrations []controllerRegistration) *lazyRegistry {
+	return &lazyRegistry{discovery: d, pending: registrations}
+}
+
+// registerAvailable registers the pending controllers whose kinds are all served by the API server.
+// It stops at the first registration error.
+func (l *lazyRegistry) registerAvailable() error {
+	served, err := l.servedKinds()
+	if err != nil {
+		return err
+	}
+
+	var stillPending []controllerRegistration
+	for i, r := range l.pending {
+		if missing := missingKinds(r.kinds, served); len(missing) > 0 {
+			log.V(1).Info("Deferring controller registration until its CRDs are installed", "controller", r.name, "missing_kinds", missing)
+			stillPending = append(stillPending, r)
+			continue
+		}
+		if err := r.register(); err != nil {
+			l.pending = append(stillPending, l.pending[i+1:]...)
+			log.Error(err, "Failed to register controller", "controller", r.name)
+			return fmt.Errorf("failed to register %s controller: %w", r.name, err)
+		}
+		log.V(1).Info("Registered controller", "controller", r.name)
+	}
+	l.pending = stillPending
+	return nil
+}
+
+// run registers the pending controllers as their CRDs get installed, until all of them are registered or the context
+// is cancelled.
+func (l *lazyRegistry) run(ctx context.Context, interval time.Duration) {
+	if len(l.pending) == 0 {
+		return
+	}
+	for _, r := range l.pending {
+		log.Info("Controller not started as its CRDs are not installed, will retry", "controller", r.name)
+	}
+	_ = wait.PollUntil(interval, func() (bool, error) {
+		if err := l.registerAvailable(); err != nil {
+			log.Error(err, "Failed to register controllers for newly installed CRDs")
+		}
+		return len(l.pending) == 0, nil
+	}, ctx.Done())
+}
+
+// servedKinds returns the kinds of the pending registrations that are served by the API server.
+func (l *lazyRegistry) servedKinds() (map[schema.GroupVersionKind]bool, error) {
+	served := make(map[schema.GroupVersionKind]bool)
+	checked := make(map[schema.GroupVersion]bool)
+	for _, r := range l.pending {
+		for _, kind := range r.kinds {
+			gv := kind.GroupVersion()
+			if checked[gv] {
+				continue
+			}
+			checked[gv] = true
+			resources, err := l.discovery.ServerResourcesForGroupVersion(gv.String())
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("while discovering resources of %s: %w", gv, err)
+			}
+			for _, resource := range resources.APIResources {
+				served[gv.WithKind(resource.Kind)] = true
+			}
+		}
+	}
+	return served, nil
+}
+
+func missingKinds(kinds []schema.GroupVersionKind, served map[schema.GroupVersionKind]bool) []string {
+	var missing []string
+	for _, kind := range kinds {
+		if !served[kind] {
+			missing = append(missing, kind.String())
+		}
+	}
+	return missing
+}
@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+)
+
+// fakeDiscovery returns a NotFound error for group versions that are not served, as the API server does.
+type fakeDiscovery struct {
+	*fake.FakeDiscovery
+}
+
+func (f fakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	for _, resources := range f.Resources {
+		if resources.GroupVersion == groupVersion {
+			return resources, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{}, groupVersion)
+}
+
+func TestLazyRegistry(t *testing.T) {
+	log = logf.Log.WithName("test")
+	discovery := fakeDiscovery{FakeDiscovery: &fake.FakeDiscovery{Fake: &k8stesting.Fake{}}}
+	served := func(gv schema.GroupVersion, kind string) {
+		discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+			GroupVersion: gv.String(),
+			APIResources: []metav1.APIResource{{Kind: kind}},
+		})
+	}
+	served(esv1.GroupVersion, esv1.Kind)
+
+	var registered []string
+	registration := func(name string, kinds ...schema.GroupVersionKind) controllerRegistration {
+		return controllerRegistration{
+			name:  name,
+			kinds: kinds,
+			register: func() error {
+				registered = append(registered, name)
+				return nil
+			},
+		}
+	}
+	esKind := esv1.GroupVersion.WithKind(esv1.Kind)
+	kbKind := kbv1.GroupVersion.WithKind(kbv1.Kind)
+	registry := newLazyRegistry(discovery, []controllerRegistration{
+		registration("no-kind"),
+		registration("es", esKind),
+		registration("kb", kbKind),
+		registration("kb-es", kbKind, esKind),
+	})
+
+	// only controllers whose kinds are served are registered
+	require.NoError(t, registry.registerAvailable())
+	require.Equal(t, []string{"no-kind", "es"}, registered)
+	require.Len(t, registry.pending, 2)
+
+	// nothing changes until new CRDs are installed
+	require.NoError(t, registry.registerAvailable())
+	require.Equal(t, []string{"no-kind", "es"}, registered)
+
+	served(kbv1.GroupVersion, kbv1.Kind)
+	require.NoError(t, registry.registerAvailable())
+	require.Equal(t, []string{"no-kind", "es", "kb", "kb-es"}, registered)
+	require.Empty(t, registry.pending)
+}
+
+func TestLazyRegistry_RegistrationError(t *testing.T) {
+	log = logf.Log.WithName("test")
+	discovery := fakeDiscovery{FakeDiscovery: &fake.FakeDiscovery{Fake: &k8stesting.Fake{}}}
+	registry := newLazyRegistry(discovery, []controllerRegistration{
+		{name: "failing", register: func() error { return errors.New("boom") }},
+	})
+	require.Error(t, registry.registerAvailable())
+	// failed registrations are not retried, as they may have partially set up watches
+	require.Empty(t, registry.pending)
+}
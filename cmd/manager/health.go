@@ -0,0 +1,145 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+)
+
+// setupHealthChecks adds the checks of the /healthz liveness and /readyz readiness probes of the operator.
+// The liveness probe fails if the manager does not start its runnables, stops recording heartbeats, or if a
+// reconciliation gets stuck, so that a wedged operator is restarted. It does not depend on the API server, so that the
+// operator is not restarted during an outage of the control plane. The readiness probe fails if the API server cannot
+// be reached, until the caches of the controllers are synced and the webhook certificate is loaded, and once the
+// operator starts shutting down.
+func setupHealthChecks(
+	ctx context.Context,
+	mgr manager.Manager,
+	clientset kubernetes.Interface,
+	liveness *operator.Liveness,
+	webhookCertDir string,
+) error {
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+	if err := mgr.Add(heartbeat{liveness: liveness, interval: operator.HeartbeatInterval}); err != nil {
+		return err
+	}
+	if err := mgr.AddHealthzCheck("liveness", live(liveness)); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("api-server", apiServerReachable(clientset)); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("shutdown", notShuttingDown(ctx)); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("cache", cacheSynced(ctx, mgr.GetCache())); err != nil {
+		return err
+	}
+	if webhookCertDir != "" {
+		if err := mgr.AddReadyzCheck("webhook-cert", webhookCertLoaded(webhookCertDir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heartbeat is a runnable recording heartbeats of the manager at the given interval, once the manager has started its
+// runnables.
+type heartbeat struct {
+	liveness *operator.Liveness
+	interval time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = heartbeat{}
+
+// Start records heartbeats until the given context is done.
+func (h heartbeat) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.liveness.Beat()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, so that the replicas of the operator that are not leaders record heartbeats too.
+func (h heartbeat) NeedLeaderElection() bool {
+	return false
+}
+
+// live returns a liveness check failing if the manager or the reconciliations of the controllers are stuck.
+func live(liveness *operator.Liveness) healthz.Checker {
+	return func(_ *http.Request) error {
+		return liveness.Check(time.Now())
+	}
+}
+
+// apiServerReachable returns a check failing if the health endpoint of the API server cannot be reached.
+func apiServerReachable(clientset kubernetes.Interface) healthz.Checker {
+	return func(req *http.Request) error {
+		return clientset.Discovery().RESTClient().Get().AbsPath("/healthz").Do(req.Context()).Error()
+	}
+}
+
+// notShuttingDown returns a readiness check failing once the given context is done, so that the operator is reported as
+// not ready while in-flight reconciliations complete.
+func notShuttingDown(ctx context.Context) healthz.Checker {
+	return func(_ *http.Request) error {
+		select {
+		case <-ctx.Done():
+			return errors.New("operator shutting down")
+		default:
+			return nil
+		}
+	}
+}
+
+// cacheSynced returns a check failing until the given cache, from which the controllers are fed, is synced.
+func cacheSynced(ctx context.Context, c cache.Cache) healthz.Checker {
+	var synced int32
+	go func() {
+		if c.WaitForCacheSync(ctx) {
+			atomic.StoreInt32(&synced, 1)
+		}
+	}()
+	return func(_ *http.Request) error {
+		if atomic.LoadInt32(&synced) == 0 {
+			return errors.New("caches not synced")
+		}
+		return nil
+	}
+}
+
+// webhookCertLoaded returns a check failing if the webhook server certificate and key in the given directory cannot be
+// loaded.
+func webhookCertLoaded(certDir string) healthz.Checker {
+	return func(_ *http.Request) error {
+		_, err := tls.LoadX509KeyPair(
+			filepath.Join(certDir, certificates.CertFileName),
+			filepath.Join(certDir, certificates.KeyFileName),
+		)
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+)
+
+func Test_notShuttingDown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	check := notShuttingDown(ctx)
+	require.NoError(t, check(nil))
+	cancel()
+	require.Error(t, check(nil))
+}
+
+func Test_webhookCertLoaded(t *testing.T) {
+	certDir := t.TempDir()
+	check := webhookCertLoaded(certDir)
+	require.Error(t, check(nil))
+
+	ca, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{})
+	require.NoError(t, err)
+	key, err := certificates.EncodePEMPrivateKey(ca.PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(certDir, certificates.CertFileName), certificates.EncodePEMCert(ca.Cert.Raw), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(certDir, certificates.KeyFileName), key, 0600))
+	require.NoError(t, check(nil))
+}
+
+func Test_heartbeat(t *testing.T) {
+	liveness := operator.NewLiveness()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- heartbeat{liveness: liveness, interval: time.Millisecond}.Start(ctx)
+	}()
+	// heartbeats are recorded once the manager starts its runnables, after which the operator is not live anymore if
+	// they stop
+	require.Eventually(t, func() bool {
+		return liveness.Check(time.Now().Add(2*time.Minute)) != nil
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, live(liveness)(nil))
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -7,6 +7,7 @@ package manager
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
@@ -23,15 +24,19 @@ import (
 	"go.uber.org/automaxprocs/maxprocs"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // allow gcp authentication
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
@@ -47,13 +52,18 @@ import (
 	entv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1beta1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	kbv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
 	emsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/maps/v1alpha1"
+	securityv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/security/v1alpha1"
+	snapshotv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/snapshot/v1alpha1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/agent"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	associationctl "github.com/elastic/cloud-on-k8s/pkg/controller/association/controller"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/autoscaling"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/beat"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/container"
 	commonlicense "github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
@@ -64,17 +74,23 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch"
 	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	esvalidation "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/enterprisesearch"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/license"
 	licensetrial "github.com/elastic/cloud-on-k8s/pkg/controller/license/trial"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/logstash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/maps"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/remoteca"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/security"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/snapshot"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/stackconfigpolicy"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/webhook"
 	"github.com/elastic/cloud-on-k8s/pkg/dev"
 	"github.com/elastic/cloud-on-k8s/pkg/dev/portforward"
+	"github.com/elastic/cloud-on-k8s/pkg/dev/tunnel"
 	licensing "github.com/elastic/cloud-on-k8s/pkg/license"
 	"github.com/elastic/cloud-on-k8s/pkg/telemetry"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -124,7 +140,13 @@ func Command() *cobra.Command {
 				}
 			}
 
+			logconf.SetSampling(viper.GetInt(logconf.SamplingInitialFlagName), viper.GetInt(logconf.SamplingThereafterFlagName))
 			logconf.ChangeVerbosity(viper.GetInt(logconf.FlagName))
+			loggerVerbosities, err := logconf.ParseLoggerVerbosities(viper.GetStringSlice(logconf.LevelsFlagName))
+			if err != nil {
+				return err
+			}
+			logconf.SetLoggerVerbosities(loggerVerbosities)
 			log = logf.Log.WithName("manager")
 
 			return nil
@@ -132,6 +154,11 @@ func Command() *cobra.Command {
 		RunE: doRun,
 	}
 
+	cmd.Flags().Duration(
+		operator.AllocationExplainDelayFlag,
+		5*time.Minute,
+		"Duration after which the allocation of unassigned shards of Elasticsearch clusters that are not green is explained in their status. 0 to disable.",
+	)
 	cmd.Flags().Bool(
 		operator.AutoPortForwardFlag,
 		false,
@@ -169,10 +196,22 @@ func Command() *cobra.Command {
 		container.DefaultContainerRegistry,
 		"Container registry to use when downloading Elastic Stack container images",
 	)
+	cmd.Flags().StringSlice(
+		operator.ContainerRepositoriesFlag,
+		[]string{},
+		"Comma-separated list of <image>=<repository> pairs overriding the repository of the default container image of an application, "+
+			"for example elasticsearch=registry.example.com/elastic/elasticsearch. The image is one of apm-server, elastic-agent, elastic-maps-server, "+
+			"elasticsearch, enterprise-search, kibana, logstash, or the name of a Beat. Takes precedence over the container registry.",
+	)
 	cmd.Flags().String(
 		operator.DebugHTTPListenFlag,
 		"localhost:6060",
-		"Listen address for debug HTTP server (only available in development mode)",
+		fmt.Sprintf("Listen address for the debug HTTP server exposing pprof and expvar, enabled by %s or in development mode", operator.EnableDebugEndpointFlag),
+	)
+	cmd.Flags().Bool(
+		operator.DefaultTopologySpreadFlag,
+		false,
+		"Enables setting default topology spread constraints on Elasticsearch Pods whose template does not specify any, spreading the Pods of each NodeSet across zones and hosts.",
 	)
 	cmd.Flags().Bool(
 		operator.DisableConfigWatch,
@@ -184,6 +223,41 @@ func Command() *cobra.Command {
 		3*time.Minute,
 		"Default timeout for requests made by the Elasticsearch client.",
 	)
+	cmd.Flags().Duration(
+		operator.ElasticsearchObserverInterval,
+		observer.DefaultObservationInterval,
+		"Default interval at which the health of Elasticsearch clusters is observed. Can be overridden per cluster with the eck.k8s.elastic.co/es-observer-interval annotation.",
+	)
+	cmd.Flags().String(
+		operator.ElasticsearchPluginsMirror,
+		"",
+		"Base URL of a mirror of https://artifacts.elastic.co/downloads/elasticsearch-plugins to install the Elasticsearch plugins declared by name in init tasks and NodeSets from. Plugins are downloaded from Elastic if empty.",
+	)
+	cmd.Flags().Duration(
+		operator.ElasticsearchResyncInterval,
+		0,
+		"Default interval at which Elasticsearch clusters are reconciled even if nothing changed. 0 to only reconcile them on changes. Can be overridden per cluster with the eck.k8s.elastic.co/es-resync-interval annotation.",
+	)
+	cmd.Flags().Bool(
+		operator.ElasticsearchSkipUnchanged,
+		false,
+		"Skip the reconciliations of Elasticsearch clusters whose inputs did not change since the last reconciliation which left nothing to do.",
+	)
+	cmd.Flags().Duration(
+		operator.ElasticsearchSlowPollAfter,
+		0,
+		"Duration after which Elasticsearch clusters green and unchanged are observed and resynced at most every elasticsearch-slow-poll-interval. 0 to disable.",
+	)
+	cmd.Flags().Duration(
+		operator.ElasticsearchSlowPollInterval,
+		2*time.Minute,
+		"Minimum observation and resync interval of the Elasticsearch clusters green and unchanged for elasticsearch-slow-poll-after.",
+	)
+	cmd.Flags().Duration(
+		operator.ElasticsearchStateCacheTTL,
+		esclient.DefaultStateCacheTTL,
+		"Duration during which the state of an Elasticsearch cluster (health, version, nodes, license) is shared between controllers without requesting it again. 0 to disable.",
+	)
 	cmd.Flags().Bool(
 		operator.DisableTelemetryFlag,
 		false,
@@ -199,15 +273,50 @@ func Command() *cobra.Command {
 		false, // Set to false for backward compatibility
 		"Restrict cross-namespace resource association through RBAC (eg. referencing Elasticsearch from Kibana)",
 	)
+	cmd.Flags().Bool(
+		operator.EnableDebugEndpointFlag,
+		false,
+		fmt.Sprintf("Enables the debug HTTP server exposing pprof and expvar on %s.", operator.DebugHTTPListenFlag),
+	)
+	cmd.Flags().Bool(
+		operator.DryRunFlag,
+		false,
+		"Log the changes the operator would make to Kubernetes resources and Elasticsearch clusters instead of applying them, to validate an operator upgrade against existing resources. Changes to Kubernetes resources are validated by the API server in dry-run mode.",
+	)
 	cmd.Flags().Bool(
 		operator.EnableLeaderElection,
 		true,
 		"Enable leader election. Enabling this will ensure there is only one active operator.",
 	)
+	cmd.Flags().String(
+		operator.LeaderElectionNamespaceFlag,
+		"",
+		"Namespace of the leader election lock. Defaults to the operator namespace if empty.",
+	)
+	cmd.Flags().Duration(
+		operator.LeaderElectionLeaseDurationFlag,
+		15*time.Second,
+		"Duration that replicas waiting for the leadership wait before acquiring it when the leader stops renewing it.",
+	)
+	cmd.Flags().Duration(
+		operator.LeaderElectionRenewDeadlineFlag,
+		10*time.Second,
+		"Duration during which the leader retries to renew the leadership before giving it up. Must be lower than leader-election-lease-duration.",
+	)
+	cmd.Flags().Duration(
+		operator.LeaderElectionRetryPeriodFlag,
+		2*time.Second,
+		"Interval between two attempts of the replicas to acquire or renew the leadership. Must be lower than leader-election-renew-deadline.",
+	)
 	cmd.Flags().Bool(
 		operator.EnableTracingFlag,
 		false,
 		"Enable APM tracing in the operator. Endpoint, token etc are to be configured via environment variables. See https://www.elastic.co/guide/en/apm/agent/go/1.x/configuration.html")
+	cmd.Flags().String(
+		operator.OTLPEndpointFlag,
+		"",
+		"URL of an OpenTelemetry OTLP/HTTP endpoint, for example http://otel-collector:4318, to which the operator traces are exported as OpenTelemetry spans. Enables tracing without requiring an APM server.",
+	)
 	cmd.Flags().Bool(
 		operator.EnableWebhookFlag,
 		false,
@@ -233,16 +342,38 @@ func Command() *cobra.Command {
 		true,
 		"Enables automatic certificates management for the webhook. The Secret and the ValidatingWebhookConfiguration must be created before running the operator",
 	)
+	cmd.Flags().StringSlice(
+		operator.ControllerConcurrencyFlag,
+		[]string{},
+		"Comma-separated list of <controller>=<count> pairs overriding max-concurrent-reconciles for specific controllers, such as elasticsearch-controller=10,kibana-controller=5.",
+	)
 	cmd.Flags().Int(
 		operator.MaxConcurrentReconcilesFlag,
 		3,
 		"Sets maximum number of concurrent reconciles per controller (Elasticsearch, Kibana, Apm Server etc). Affects the ability of the operator to process changes concurrently.",
 	)
+	cmd.Flags().Int(
+		operator.HealthProbePortFlag,
+		0,
+		"Port to use for exposing the /healthz liveness and /readyz readiness probes (set 0 to disable). "+
+			"The liveness probe checks that the manager and the reconciliations of the controllers are not stuck. The readiness probe "+
+			"checks the connectivity to the API server, that the caches are synced and the webhook certificate is loaded, and fails while shutting down.",
+	)
+	cmd.Flags().String(
+		operator.MetricsCertDirFlag,
+		"",
+		fmt.Sprintf("Directory containing the %s and %s files used to serve the metrics over TLS when %s is set. A self-signed certificate is generated if empty.", certificates.CertFileName, certificates.KeyFileName, operator.MetricsSecureFlag),
+	)
 	cmd.Flags().Int(
 		operator.MetricsPortFlag,
 		DefaultMetricPort,
 		"Port to use for exposing metrics in the Prometheus format (set 0 to disable)",
 	)
+	cmd.Flags().Bool(
+		operator.MetricsSecureFlag,
+		false,
+		"Serve the metrics over TLS, only to clients presenting a bearer token authorized to get the /metrics non-resource URL.",
+	)
 	cmd.Flags().StringSlice(
 		operator.NamespacesFlag,
 		nil,
@@ -253,6 +384,11 @@ func Command() *cobra.Command {
 		"",
 		"Kubernetes namespace the operator runs in",
 	)
+	cmd.Flags().String(
+		operator.TelemetryConfigMapFlag,
+		"",
+		"Name of a ConfigMap in the operator namespace to which ECK telemetry data is also written, for consumers other than Kibana. Disabled if empty.",
+	)
 	cmd.Flags().Duration(
 		operator.TelemetryIntervalFlag,
 		1*time.Hour,
@@ -279,6 +415,19 @@ func Command() *cobra.Command {
 		"",
 		fmt.Sprintf("Kubernetes secret mounted into the path designated by %s to be used for webhook certificates", operator.WebhookCertDirFlag),
 	)
+	cmd.Flags().Bool(
+		operator.WebhookTunnelFlag,
+		false,
+		"Serves the webhook through a relay Pod deployed in the operator namespace when running outside of Kubernetes "+
+			"(for dev use only, requires auto-port-forward)",
+	)
+	cmd.Flags().String(
+		operator.WebhookTunnelImageFlag,
+		"",
+		"Container image used by the webhook relay Pod, it must contain an operator binary built from the current sources "+
+			"and be pushed to a registry reachable from the cluster, for example with 'make docker-build docker-push' "+
+			"(for dev use only, required by webhook-tunnel)",
+	)
 	cmd.Flags().String(
 		operator.WebhookNameFlag,
 		DefaultWebhookName,
@@ -289,10 +438,55 @@ func Command() *cobra.Command {
 		true,
 		"Enables setting the default security context with fsGroup=1000 for Elasticsearch 8.0+ Pods. Ignored pre-8.0.",
 	)
+	cmd.Flags().Bool(
+		operator.RestrictedSecurityContextFlag,
+		false,
+		"Enables restricted security contexts on all the Pods created by the operator (non-root user, RuntimeDefault seccomp profile, "+
+			"read-only root filesystem, no privilege escalation, all capabilities dropped). Pod templates weakening them are rejected.",
+	)
+	cmd.Flags().Bool(
+		operator.ServerSideApplyFlag,
+		false,
+		"Opt in to server-side apply to create and update the Secrets, Services, StatefulSets and ConfigMaps managed by the operator. Other resources are still written with update requests. Requires Kubernetes 1.18+.",
+	)
+	cmd.Flags().Duration(
+		operator.StatusFlushIntervalFlag,
+		common.DefaultStatusFlushInterval,
+		"Minimum duration between two status updates of a resource. Updates changing the health of the resource, or the phase, conditions or observed generation of an Elasticsearch resource, are always written immediately. Set to 0 to write all updates immediately.",
+	)
+	cmd.Flags().Duration(
+		operator.ShutdownGracePeriodFlag,
+		30*time.Second,
+		"Duration during which in-flight reconciliations are allowed to complete when the operator is stopped.",
+	)
+	cmd.Flags().StringSlice(
+		operator.OperatorRolesFlag,
+		[]string{operator.AllRoles},
+		fmt.Sprintf("Comma-separated list of the components run by this operator process: %s, %s or %s. "+
+			"Running only the %s role allows scaling the webhook server independently of the controllers.",
+			operator.AllRoles, operator.ControllersRole, operator.WebhookRole, operator.WebhookRole),
+	)
+	cmd.Flags().Int(
+		operator.ShardCountFlag,
+		0,
+		"Number of operator replicas sharing the reconciliation of resources. Each replica reconciles a deterministic subset of the resources. Disabled if lower than 2.",
+	)
+	cmd.Flags().Int(
+		operator.ShardIndexFlag,
+		-1,
+		"Index of the shard reconciled by this operator replica, between 0 and shard-count - 1. Derived from the ordinal of the operator Pod if negative.",
+	)
+	cmd.Flags().String(
+		operator.ResourceLabelSelectorFlag,
+		"",
+		"Label selector restricting the resources managed by this operator instance, such as eck.k8s.elastic.co/operator=team-a. "+
+			"Allows several operator instances to manage disjoint subsets of the resources. Manages all resources if empty.",
+	)
 
 	// hide development mode flags from the usage message
 	_ = cmd.Flags().MarkHidden(operator.AutoPortForwardFlag)
-	_ = cmd.Flags().MarkHidden(operator.DebugHTTPListenFlag)
+	_ = cmd.Flags().MarkHidden(operator.WebhookTunnelFlag)
+	_ = cmd.Flags().MarkHidden(operator.WebhookTunnelImageFlag)
 
 	// hide flags set by the build process
 	_ = cmd.Flags().MarkHidden(operator.DistributionChannelFlag)
@@ -349,11 +543,12 @@ func doRun(_ *cobra.Command, _ []string) error {
 		case <-ctx.Done(): // signal received
 			log.Info("Shutting down due to signal")
 
-			return nil
+			return <-errChan
 		case <-confUpdateChan: // config file updated
 			log.Info("Shutting down to apply updated configuration")
+			cancelFunc()
 
-			return nil
+			return <-errChan
 		}
 	}
 }
@@ -372,14 +567,16 @@ func startOperator(ctx context.Context) error {
 		return err
 	}
 
-	if dev.Enabled {
-		// expose pprof if development mode is enabled
+	if dev.Enabled || viper.GetBool(operator.EnableDebugEndpointFlag) {
+		// expose pprof and expvar if development mode or the debug endpoint is enabled
 		mux := http.NewServeMux()
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
 		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle("/debug/vars", expvar.Handler())
+		mux.Handle("/debug/log-levels", logconf.LevelsHandler())
 
 		pprofServer := http.Server{
 			Addr:    viper.GetString(operator.DebugHTTPListenFlag),
@@ -415,6 +612,11 @@ func startOperator(ctx context.Context) error {
 		dialer = portforward.NewForwardingDialer()
 	}
 
+	webhookTunnel := viper.GetBool(operator.WebhookTunnelFlag)
+	if webhookTunnel && !autoPortForward {
+		return fmt.Errorf("%s must be enabled to use %s", operator.AutoPortForwardFlag, operator.WebhookTunnelFlag)
+	}
+
 	operatorNamespace := viper.GetString(operator.OperatorNamespaceFlag)
 	if operatorNamespace == "" {
 		err := fmt.Errorf("operator namespace must be specified using %s", operator.OperatorNamespaceFlag)
@@ -427,6 +629,17 @@ func startOperator(ctx context.Context) error {
 	log.Info("Setting default container registry", "container_registry", containerRegistry)
 	container.SetContainerRegistry(containerRegistry)
 
+	// override the repository of some images, regardless of the container registry
+	imageRepositories, err := container.ParseImageRepositories(viper.GetStringSlice(operator.ContainerRepositoriesFlag))
+	if err != nil {
+		log.Error(err, "Failed to parse the container repositories")
+		return err
+	}
+	if len(imageRepositories) > 0 {
+		log.Info("Overriding container image repositories", "container_repositories", imageRepositories)
+		container.SetImageRepositories(imageRepositories)
+	}
+
 	// enforce UBI stack images if requested
 	ubiOnly := viper.GetBool(operator.UBIOnlyFlag)
 	if ubiOnly {
@@ -446,6 +659,11 @@ func startOperator(ctx context.Context) error {
 
 	// set the timeout for Elasticsearch requests
 	esclient.DefaultESClientTimeout = viper.GetDuration(operator.ElasticsearchClientTimeout)
+	// share the state of the clusters between all the controllers creating Elasticsearch clients
+	esclient.SharedStateCache = esclient.NewStateCache(viper.GetDuration(operator.ElasticsearchStateCacheTTL))
+
+	// log the changes instead of applying them in dry-run mode
+	dryRun := viper.GetBool(operator.DryRunFlag)
 
 	// Setup Scheme for all resources
 	log.Info("Setting up scheme")
@@ -453,14 +671,63 @@ func startOperator(ctx context.Context) error {
 	// also set up the v1beta1 scheme, used by the v1beta1 webhook
 	controllerscheme.SetupV1beta1Scheme()
 
+	roles, err := operator.ParseRoles(viper.GetStringSlice(operator.OperatorRolesFlag))
+	if err != nil {
+		log.Error(err, "Failed to parse the operator roles")
+		return err
+	}
+	if roles.WebhookOnly() && !viper.GetBool(operator.EnableWebhookFlag) {
+		return fmt.Errorf("%s must be enabled to run only the %s role", operator.EnableWebhookFlag, operator.WebhookRole)
+	}
+
+	hostname, _ := os.Hostname()
+	shard, err := operator.NewShard(viper.GetInt(operator.ShardIndexFlag), viper.GetInt(operator.ShardCountFlag), hostname)
+	if err != nil {
+		log.Error(err, "Failed to configure sharding")
+		return err
+	}
+	resourceSelector, err := operator.NewResourceSelector(viper.GetString(operator.ResourceLabelSelectorFlag))
+	if err != nil {
+		log.Error(err, "Failed to parse the resource label selector")
+		return err
+	}
+	if resourceSelector.Enabled() {
+		log.Info("Operator configured to manage resources matching a label selector", "selector", resourceSelector.String())
+	}
+
+	leaderElectionID := LeaderElectionConfigMapName
+	if shard.Enabled() {
+		log.Info("Reconciliation sharded across operator replicas", "shard", shard.String())
+		// replicas of the same shard elect a leader among themselves, replicas of different shards run concurrently
+		leaderElectionID = fmt.Sprintf("%s-%d", LeaderElectionConfigMapName, shard.Index)
+	}
+	if roles.WebhookOnly() {
+		log.Info("Operator configured to only run the webhook server")
+		// webhook replicas elect the leader managing the webhook certificates independently of the controllers replicas
+		leaderElectionID = fmt.Sprintf("%s-%s", LeaderElectionConfigMapName, operator.WebhookRole)
+	}
+
+	leaseDuration, renewDeadline, retryPeriod, err := validateLeaderElectionFlags()
+	if err != nil {
+		log.Error(err, "Invalid leader election parameters")
+		return err
+	}
+	leaderElectionNamespace := viper.GetString(operator.LeaderElectionNamespaceFlag)
+	if leaderElectionNamespace == "" {
+		leaderElectionNamespace = operatorNamespace
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	opts := ctrl.Options{
 		Scheme:                     clientgoscheme.Scheme,
 		CertDir:                    viper.GetString(operator.WebhookCertDirFlag),
 		LeaderElection:             viper.GetBool(operator.EnableLeaderElection),
 		LeaderElectionResourceLock: resourcelock.ConfigMapsResourceLock, // TODO: Revert to ConfigMapsLeases when support for 1.13 is dropped
-		LeaderElectionID:           LeaderElectionConfigMapName,
-		LeaderElectionNamespace:    operatorNamespace,
+		LeaderElectionID:           leaderElectionID,
+		LeaderElectionNamespace:    leaderElectionNamespace,
+		LeaseDuration:              &leaseDuration,
+		RenewDeadline:              &renewDeadline,
+		RetryPeriod:                &retryPeriod,
 		Logger:                     log.WithName("eck-operator"),
 	}
 
@@ -488,10 +755,31 @@ func startOperator(ctx context.Context) error {
 
 	// only expose prometheus metrics if provided a non-zero port
 	metricsPort := viper.GetInt(operator.MetricsPortFlag)
+	metricsSecure := viper.GetBool(operator.MetricsSecureFlag)
 	if metricsPort != 0 {
-		log.Info("Exposing Prometheus metrics on /metrics", "port", metricsPort)
+		log.Info("Exposing Prometheus metrics on /metrics", "port", metricsPort, "secure", metricsSecure)
 	}
 	opts.MetricsBindAddress = fmt.Sprintf(":%d", metricsPort) // 0 to disable
+	if metricsSecure {
+		// metrics are served by the secure metrics server instead
+		opts.MetricsBindAddress = "0"
+	}
+
+	// only expose health probes if provided a non-zero port
+	healthProbePort := viper.GetInt(operator.HealthProbePortFlag)
+	if healthProbePort != 0 {
+		log.Info("Exposing health probes on /healthz and /readyz", "port", healthProbePort)
+		opts.HealthProbeBindAddress = fmt.Sprintf(":%d", healthProbePort)
+	}
+
+	// let in-flight reconciliations complete when stopping the manager
+	shutdownGracePeriod := viper.GetDuration(operator.ShutdownGracePeriodFlag)
+	opts.GracefulShutdownTimeout = &shutdownGracePeriod
+
+	if dryRun {
+		log.Info("Operator running in dry-run mode, changes to Kubernetes resources and Elasticsearch clusters are logged but not applied")
+		opts.NewClient = newDryRunClient
+	}
 
 	opts.Port = WebhookPort
 	mgr, err := ctrl.NewManager(cfg, opts)
@@ -499,6 +787,10 @@ func startOperator(ctx context.Context) error {
 		log.Error(err, "Failed to create controller manager")
 		return err
 	}
+	if dryRun {
+		// events are logged instead of emitted
+		mgr = dryRunManager{Manager: mgr}
+	}
 
 	// Verify cert validity options
 	caCertValidity, caCertRotateBefore, err := validateCertExpirationFlags(operator.CACertValidityFlag, operator.CACertRotateBeforeFlag)
@@ -530,6 +822,26 @@ func startOperator(ctx context.Context) error {
 		return err
 	}
 
+	if metricsSecure && metricsPort != 0 {
+		if err := setupSecureMetrics(mgr, clientset, metricsPort, viper.GetString(operator.MetricsCertDirFlag)); err != nil {
+			log.Error(err, "Failed to set up the secure metrics server")
+			return err
+		}
+	}
+
+	var liveness *operator.Liveness
+	if healthProbePort != 0 {
+		liveness = operator.NewLiveness()
+		var webhookCertDir string
+		if viper.GetBool(operator.EnableWebhookFlag) && roles.Has(operator.WebhookRole) && !dryRun {
+			webhookCertDir = mgr.GetWebhookServer().CertDir
+		}
+		if err := setupHealthChecks(ctx, mgr, clientset, liveness, webhookCertDir); err != nil {
+			log.Error(err, "Failed to set up the health checks")
+			return err
+		}
+	}
+
 	distributionChannel := viper.GetString(operator.DistributionChannelFlag)
 	operatorInfo, err := about.GetOperatorInfo(clientset, operatorNamespace, distributionChannel)
 	if err != nil {
@@ -539,7 +851,9 @@ func startOperator(ctx context.Context) error {
 
 	log.Info("Setting up controllers")
 	var tracer *apm.Tracer
-	if viper.GetBool(operator.EnableTracingFlag) {
+	if endpoint := viper.GetString(operator.OTLPEndpointFlag); endpoint != "" {
+		tracer = tracing.NewOTLPTracer("elastic-operator", endpoint)
+	} else if viper.GetBool(operator.EnableTracingFlag) {
 		tracer = tracing.NewTracer("elastic-operator")
 	}
 
@@ -549,6 +863,12 @@ func startOperator(ctx context.Context) error {
 		return err
 	}
 
+	controllerConcurrency, err := operator.ParseControllerConcurrency(viper.GetStringSlice(operator.ControllerConcurrencyFlag))
+	if err != nil {
+		log.Error(err, "Failed to parse the controller concurrency")
+		return err
+	}
+
 	params := operator.Parameters{
 		Dialer:            dialer,
 		ExposedNodeLabels: exposedNodeLabels,
@@ -563,33 +883,49 @@ func startOperator(ctx context.Context) error {
 			Validity:     certValidity,
 			RotateBefore: certRotateBefore,
 		},
+		AllocationExplainDelay:    viper.GetDuration(operator.AllocationExplainDelayFlag),
+		DefaultTopologySpread:     viper.GetBool(operator.DefaultTopologySpreadFlag),
+		PluginsMirror:             viper.GetString(operator.ElasticsearchPluginsMirror),
 		MaxConcurrentReconciles:   viper.GetInt(operator.MaxConcurrentReconcilesFlag),
+		ControllerConcurrency:     controllerConcurrency,
+		ObservationInterval:       viper.GetDuration(operator.ElasticsearchObserverInterval),
+		ResyncInterval:            viper.GetDuration(operator.ElasticsearchResyncInterval),
+		SkipUnchanged:             viper.GetBool(operator.ElasticsearchSkipUnchanged),
+		SlowPollAfter:             viper.GetDuration(operator.ElasticsearchSlowPollAfter),
+		SlowPollInterval:          viper.GetDuration(operator.ElasticsearchSlowPollInterval),
+		RestrictedSecurityContext: viper.GetBool(operator.RestrictedSecurityContextFlag),
 		SetDefaultSecurityContext: viper.GetBool(operator.SetDefaultSecurityContextFlag),
+		ServerSideApply:           viper.GetBool(operator.ServerSideApplyFlag),
+		StatusFlushInterval:       viper.GetDuration(operator.StatusFlushIntervalFlag),
 		ValidateStorageClass:      viper.GetBool(operator.ValidateStorageClassFlag),
 		Tracer:                    tracer,
+		Shard:                     shard,
+		ResourceSelector:          resourceSelector,
+		DryRun:                    dryRun,
+		Liveness:                  liveness,
 	}
 
-	if viper.GetBool(operator.EnableWebhookFlag) {
-		setupWebhook(mgr, params.CertRotation, params.ValidateStorageClass, clientset, exposedNodeLabels)
+	if dryRun && viper.GetBool(operator.EnableWebhookFlag) {
+		log.Info("Webhook disabled in dry-run mode")
 	}
-
-	enforceRbacOnRefs := viper.GetBool(operator.EnforceRBACOnRefsFlag)
-
-	var accessReviewer rbac.AccessReviewer
-	if enforceRbacOnRefs {
-		accessReviewer = rbac.NewSubjectAccessReviewer(clientset)
-	} else {
-		accessReviewer = rbac.NewPermissiveAccessReviewer()
+	if viper.GetBool(operator.EnableWebhookFlag) && roles.Has(operator.WebhookRole) && !dryRun {
+		if webhookTunnel {
+			deleteRelayPod, err := setupWebhookTunnel(ctx, cfg, dialer, operatorNamespace)
+			if err != nil {
+				log.Error(err, "Failed to setup the webhook tunnel")
+				return err
+			}
+			defer deleteRelayPod()
+		}
+		setupWebhook(mgr, params.CertRotation, params.ValidateStorageClass, params.ResourceSelector, clientset, exposedNodeLabels)
 	}
 
-	if err := registerControllers(mgr, params, accessReviewer); err != nil {
-		return err
+	if roles.Has(operator.ControllersRole) {
+		if err := setupControllers(ctx, mgr, cfg, clientset, params, shard, managedNamespaces, operatorInfo); err != nil {
+			return err
+		}
 	}
 
-	disableTelemetry := viper.GetBool(operator.DisableTelemetryFlag)
-	telemetryInterval := viper.GetDuration(operator.TelemetryIntervalFlag)
-	go asyncTasks(mgr, cfg, managedNamespaces, operatorNamespace, operatorInfo, disableTelemetry, telemetryInterval)
-
 	log.Info("Starting the manager", "uuid", operatorInfo.OperatorUUID,
 		"namespace", operatorNamespace, "version", operatorInfo.BuildInfo.Version,
 		"build_hash", operatorInfo.BuildInfo.Hash, "build_date", operatorInfo.BuildInfo.Date,
@@ -597,14 +933,6 @@ func startOperator(ctx context.Context) error {
 
 	exitOnErr := make(chan error)
 
-	// start the manager
-	go func() {
-		if err := mgr.Start(ctx); err != nil {
-			log.Error(err, "Failed to start the controller manager")
-			exitOnErr <- err
-		}
-	}()
-
 	// check operator license key
 	go func() {
 		mgr.GetCache().WaitForCacheSync(ctx)
@@ -619,16 +947,72 @@ func startOperator(ctx context.Context) error {
 		}
 	}()
 
+	return runManager(ctx, mgr, shutdownGracePeriod, exitOnErr)
+}
+
+// runManager starts the manager and blocks until it stops, or an error is sent to exitOnErr. Once the given context is
+// done, the manager stops after in-flight reconciliations complete, or after the shutdown grace period elapses.
+func runManager(ctx context.Context, mgr manager.Manager, shutdownGracePeriod time.Duration, exitOnErr chan error) error {
+	managerStopped := make(chan struct{})
+	go func() {
+		defer close(managerStopped)
+		if err := mgr.Start(ctx); err != nil {
+			log.Error(err, "Failed to start the controller manager")
+			exitOnErr <- err
+		}
+	}()
+
 	for {
 		select {
-		case err = <-exitOnErr:
+		case err := <-exitOnErr:
 			return err
 		case <-ctx.Done():
-			return nil
+			log.Info("Waiting for in-flight reconciliations to complete", "grace_period", shutdownGracePeriod)
+			select {
+			case err := <-exitOnErr:
+				return err
+			case <-managerStopped:
+				return nil
+			}
 		}
 	}
 }
 
+// setupControllers registers the controllers with the manager, and schedules the tasks that are not specific to a
+// resource.
+func setupControllers(
+	ctx context.Context,
+	mgr manager.Manager,
+	cfg *rest.Config,
+	clientset kubernetes.Interface,
+	params operator.Parameters,
+	shard operator.Shard,
+	managedNamespaces []string,
+	operatorInfo about.OperatorInfo,
+) error {
+	var accessReviewer rbac.AccessReviewer
+	if viper.GetBool(operator.EnforceRBACOnRefsFlag) {
+		accessReviewer = rbac.NewSubjectAccessReviewer(clientset)
+	} else {
+		accessReviewer = rbac.NewPermissiveAccessReviewer()
+	}
+
+	registry := newLazyRegistry(clientset.Discovery(), controllerRegistrations(mgr, params, accessReviewer))
+	if err := registry.registerAvailable(); err != nil {
+		return err
+	}
+	go registry.run(ctx, crdDiscoveryInterval)
+
+	disableTelemetry := viper.GetBool(operator.DisableTelemetryFlag)
+	telemetryInterval := viper.GetDuration(operator.TelemetryIntervalFlag)
+	telemetryConfigMap := viper.GetString(operator.TelemetryConfigMapFlag)
+	if shard.IsFirst() {
+		// tasks that are not specific to a resource only run in the first shard
+		go asyncTasks(mgr, cfg, managedNamespaces, params.OperatorNamespace, operatorInfo, disableTelemetry, telemetryInterval, telemetryConfigMap, params.DryRun, params.ServerSideApply)
+	}
+	return nil
+}
+
 // asyncTasks schedules some tasks to be started when this instance of the operator is elected
 func asyncTasks(
 	mgr manager.Manager,
@@ -638,6 +1022,9 @@ func asyncTasks(
 	operatorInfo about.OperatorInfo,
 	disableTelemetry bool,
 	telemetryInterval time.Duration,
+	telemetryConfigMap string,
+	dryRun bool,
+	serverSideApply bool,
 ) {
 	<-mgr.Elected() // wait for this operator instance to be elected
 
@@ -656,18 +1043,39 @@ func asyncTasks(
 	if !disableTelemetry {
 		// Start the telemetry reporter
 		go func() {
-			tr := telemetry.NewReporter(operatorInfo, mgr.GetClient(), operatorNamespace, managedNamespaces, telemetryInterval)
+			tr := telemetry.NewReporter(operatorInfo, mgr.GetClient(), operatorNamespace, managedNamespaces, telemetryInterval, telemetryConfigMap, serverSideApply)
 			tr.Start()
 		}()
 	}
 
 	// Garbage collect orphaned secrets leftover from deleted resources while the operator was not running
-	// - association user secrets
-	garbageCollectUsers(cfg, managedNamespaces)
+	// - association user secrets, deleted through a dedicated client which does not support dry-run
+	if !dryRun {
+		garbageCollectUsers(cfg, managedNamespaces)
+	}
 	// - soft-owned secrets
 	garbageCollectSoftOwnedSecrets(mgr.GetClient())
 }
 
+// dryRunManager is a manager whose event recorders log the events instead of emitting them.
+type dryRunManager struct {
+	manager.Manager
+}
+
+func (m dryRunManager) GetEventRecorderFor(_ string) record.EventRecorder {
+	return k8s.NewDryRunRecorder()
+}
+
+// newDryRunClient creates the default manager client, wrapped to log the changes it is asked to make and submit them
+// to the API server in dry-run mode.
+func newDryRunClient(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+	c, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+	if err != nil {
+		return nil, err
+	}
+	return k8s.NewDryRunClient(c), nil
+}
+
 func chooseAndValidateIPFamily(ipFamilyStr string, ipFamilyDefault corev1.IPFamily) (corev1.IPFamily, error) {
 	switch strings.ToLower(ipFamilyStr) {
 	case "":
@@ -681,58 +1089,83 @@ func chooseAndValidateIPFamily(ipFamilyStr string, ipFamilyDefault corev1.IPFami
 	}
 }
 
-func registerControllers(mgr manager.Manager, params operator.Parameters, accessReviewer rbac.AccessReviewer) error {
+// controllerRegistrations returns the registrations of all the controllers, along with the kinds they watch.
+func controllerRegistrations(mgr manager.Manager, params operator.Parameters, accessReviewer rbac.AccessReviewer) []controllerRegistration {
+	esKind := esv1.GroupVersion.WithKind(esv1.Kind)
+	kbKind := kbv1.GroupVersion.WithKind(kbv1.Kind)
+	apmKind := apmv1.GroupVersion.WithKind(apmv1.Kind)
+	entKind := entv1.GroupVersion.WithKind(entv1.Kind)
+	beatKind := beatv1beta1.GroupVersion.WithKind(beatv1beta1.Kind)
+	agentKind := agentv1alpha1.GroupVersion.WithKind(agentv1alpha1.Kind)
+	emsKind := emsv1alpha1.GroupVersion.WithKind(emsv1alpha1.Kind)
+	lsKind := lsv1alpha1.GroupVersion.WithKind(lsv1alpha1.Kind)
+	snapshotRepositoryKind := snapshotv1alpha1.GroupVersion.WithKind(snapshotv1alpha1.SnapshotRepositoryKind)
+	snapshotPolicyKind := snapshotv1alpha1.GroupVersion.WithKind(snapshotv1alpha1.SnapshotPolicyKind)
+	stackConfigPolicyKind := policyv1alpha1.GroupVersion.WithKind(policyv1alpha1.StackConfigPolicyKind)
+	esUserKind := securityv1alpha1.GroupVersion.WithKind(securityv1alpha1.ElasticsearchUserKind)
+	esRoleKind := securityv1alpha1.GroupVersion.WithKind(securityv1alpha1.ElasticsearchRoleKind)
+
 	controllers := []struct {
 		name         string
+		kinds        []schema.GroupVersionKind
 		registerFunc func(manager.Manager, operator.Parameters) error
 	}{
-		{name: "APMServer", registerFunc: apmserver.Add},
-		{name: "Elasticsearch", registerFunc: elasticsearch.Add},
-		{name: "ElasticsearchAutoscaling", registerFunc: autoscaling.Add},
-		{name: "Kibana", registerFunc: kibana.Add},
-		{name: "EnterpriseSearch", registerFunc: enterprisesearch.Add},
-		{name: "Beats", registerFunc: beat.Add},
-		{name: "License", registerFunc: license.Add},
+		{name: "APMServer", kinds: []schema.GroupVersionKind{apmKind}, registerFunc: apmserver.Add},
+		{name: "Elasticsearch", kinds: []schema.GroupVersionKind{esKind}, registerFunc: elasticsearch.Add},
+		{name: "ElasticsearchAutoscaling", kinds: []schema.GroupVersionKind{esKind}, registerFunc: autoscaling.Add},
+		{name: "Kibana", kinds: []schema.GroupVersionKind{kbKind}, registerFunc: kibana.Add},
+		{name: "EnterpriseSearch", kinds: []schema.GroupVersionKind{entKind}, registerFunc: enterprisesearch.Add},
+		{name: "Beats", kinds: []schema.GroupVersionKind{beatKind}, registerFunc: beat.Add},
+		{name: "License", kinds: []schema.GroupVersionKind{esKind}, registerFunc: license.Add},
 		{name: "LicenseTrial", registerFunc: licensetrial.Add},
-		{name: "Agent", registerFunc: agent.Add},
-		{name: "Maps", registerFunc: maps.Add},
-	}
-
-	for _, c := range controllers {
-		if err := c.registerFunc(mgr, params); err != nil {
-			log.Error(err, "Failed to register controller", "controller", c.name)
-			return fmt.Errorf("failed to register %s controller: %w", c.name, err)
-		}
+		{name: "Agent", kinds: []schema.GroupVersionKind{agentKind}, registerFunc: agent.Add},
+		{name: "Maps", kinds: []schema.GroupVersionKind{emsKind}, registerFunc: maps.Add},
+		{name: "Logstash", kinds: []schema.GroupVersionKind{lsKind}, registerFunc: logstash.Add},
+		{name: "Snapshot", kinds: []schema.GroupVersionKind{snapshotRepositoryKind, snapshotPolicyKind, esKind}, registerFunc: snapshot.Add},
+		{name: "Security", kinds: []schema.GroupVersionKind{esUserKind, esRoleKind, esKind}, registerFunc: security.Add},
+		{name: "StackConfigPolicy", kinds: []schema.GroupVersionKind{stackConfigPolicyKind, esKind}, registerFunc: stackconfigpolicy.Add},
 	}
 
 	assocControllers := []struct {
 		name         string
+		kinds        []schema.GroupVersionKind
 		registerFunc func(manager.Manager, rbac.AccessReviewer, operator.Parameters) error
 	}{
-		{name: "RemoteCA", registerFunc: remoteca.Add},
-		{name: "APM-ES", registerFunc: associationctl.AddApmES},
-		{name: "APM-KB", registerFunc: associationctl.AddApmKibana},
-		{name: "KB-ES", registerFunc: associationctl.AddKibanaES},
-		{name: "KB-ENT", registerFunc: associationctl.AddKibanaEnt},
-		{name: "ENT-ES", registerFunc: associationctl.AddEntES},
-		{name: "BEAT-ES", registerFunc: associationctl.AddBeatES},
-		{name: "BEAT-KB", registerFunc: associationctl.AddBeatKibana},
-		{name: "AGENT-ES", registerFunc: associationctl.AddAgentES},
-		{name: "AGENT-KB", registerFunc: associationctl.AddAgentKibana},
-		{name: "AGENT-FS", registerFunc: associationctl.AddAgentFleetServer},
-		{name: "EMS-ES", registerFunc: associationctl.AddMapsES},
-		{name: "ES-MONITORING", registerFunc: associationctl.AddEsMonitoring},
-		{name: "KB-MONITORING", registerFunc: associationctl.AddKbMonitoring},
+		{name: "RemoteCA", kinds: []schema.GroupVersionKind{esKind}, registerFunc: remoteca.Add},
+		{name: "APM-ES", kinds: []schema.GroupVersionKind{apmKind, esKind}, registerFunc: associationctl.AddApmES},
+		{name: "APM-KB", kinds: []schema.GroupVersionKind{apmKind, kbKind}, registerFunc: associationctl.AddApmKibana},
+		{name: "KB-ES", kinds: []schema.GroupVersionKind{kbKind, esKind}, registerFunc: associationctl.AddKibanaES},
+		{name: "KB-ENT", kinds: []schema.GroupVersionKind{kbKind, entKind}, registerFunc: associationctl.AddKibanaEnt},
+		{name: "ENT-ES", kinds: []schema.GroupVersionKind{entKind, esKind}, registerFunc: associationctl.AddEntES},
+		{name: "BEAT-ES", kinds: []schema.GroupVersionKind{beatKind, esKind}, registerFunc: associationctl.AddBeatES},
+		{name: "BEAT-KB", kinds: []schema.GroupVersionKind{beatKind, kbKind}, registerFunc: associationctl.AddBeatKibana},
+		{name: "AGENT-ES", kinds: []schema.GroupVersionKind{agentKind, esKind}, registerFunc: associationctl.AddAgentES},
+		{name: "AGENT-KB", kinds: []schema.GroupVersionKind{agentKind, kbKind}, registerFunc: associationctl.AddAgentKibana},
+		{name: "AGENT-FS", kinds: []schema.GroupVersionKind{agentKind}, registerFunc: associationctl.AddAgentFleetServer},
+		{name: "EMS-ES", kinds: []schema.GroupVersionKind{emsKind, esKind}, registerFunc: associationctl.AddMapsES},
+		{name: "LS-ES", kinds: []schema.GroupVersionKind{lsKind, esKind}, registerFunc: associationctl.AddLogstashES},
+		{name: "ES-MONITORING", kinds: []schema.GroupVersionKind{esKind}, registerFunc: associationctl.AddEsMonitoring},
+		{name: "KB-MONITORING", kinds: []schema.GroupVersionKind{kbKind, esKind}, registerFunc: associationctl.AddKbMonitoring},
 	}
 
+	registrations := make([]controllerRegistration, 0, len(controllers)+len(assocControllers))
+	for _, c := range controllers {
+		registerFunc := c.registerFunc
+		registrations = append(registrations, controllerRegistration{
+			name:     c.name,
+			kinds:    c.kinds,
+			register: func() error { return registerFunc(mgr, params) },
+		})
+	}
 	for _, c := range assocControllers {
-		if err := c.registerFunc(mgr, accessReviewer, params); err != nil {
-			log.Error(err, "Failed to register association controller", "controller", c.name)
-			return fmt.Errorf("failed to register %s association controller: %w", c.name, err)
-		}
+		registerFunc := c.registerFunc
+		registrations = append(registrations, controllerRegistration{
+			name:     c.name + " association",
+			kinds:    c.kinds,
+			register: func() error { return registerFunc(mgr, accessReviewer, params) },
+		})
 	}
-
-	return nil
+	return registrations
 }
 
 func validateCertExpirationFlags(validityFlag string, rotateBeforeFlag string) (time.Duration, time.Duration, error) {
@@ -746,6 +1179,26 @@ func validateCertExpirationFlags(validityFlag string, rotateBeforeFlag string) (
 	return certValidity, certRotateBefore, nil
 }
 
+// validateLeaderElectionFlags returns the lease duration, renew deadline and retry period of the leader election,
+// or an error if the leader could lose the leadership before the other replicas are allowed to acquire it.
+func validateLeaderElectionFlags() (time.Duration, time.Duration, time.Duration, error) {
+	leaseDuration := viper.GetDuration(operator.LeaderElectionLeaseDurationFlag)
+	renewDeadline := viper.GetDuration(operator.LeaderElectionRenewDeadlineFlag)
+	retryPeriod := viper.GetDuration(operator.LeaderElectionRetryPeriodFlag)
+
+	if retryPeriod <= 0 {
+		return leaseDuration, renewDeadline, retryPeriod, fmt.Errorf("%s must be positive", operator.LeaderElectionRetryPeriodFlag)
+	}
+	if renewDeadline <= retryPeriod {
+		return leaseDuration, renewDeadline, retryPeriod, fmt.Errorf("%s must be larger than %s", operator.LeaderElectionRenewDeadlineFlag, operator.LeaderElectionRetryPeriodFlag)
+	}
+	if leaseDuration <= renewDeadline {
+		return leaseDuration, renewDeadline, retryPeriod, fmt.Errorf("%s must be larger than %s", operator.LeaderElectionLeaseDurationFlag, operator.LeaderElectionRenewDeadlineFlag)
+	}
+
+	return leaseDuration, renewDeadline, retryPeriod, nil
+}
+
 func garbageCollectUsers(cfg *rest.Config, managedNamespaces []string) {
 	ugc, err := association.NewUsersGarbageCollector(cfg, managedNamespaces)
 	if err != nil {
@@ -759,6 +1212,7 @@ func garbageCollectUsers(cfg *rest.Config, managedNamespaces []string) {
 		For(&beatv1beta1.BeatList{}, associationctl.BeatAssociationLabelNamespace, associationctl.BeatAssociationLabelName).
 		For(&agentv1alpha1.AgentList{}, associationctl.AgentAssociationLabelNamespace, associationctl.AgentAssociationLabelName).
 		For(&emsv1alpha1.ElasticMapsServerList{}, associationctl.MapsESAssociationLabelNamespace, associationctl.MapsESAssociationLabelName).
+		For(&lsv1alpha1.LogstashList{}, associationctl.LogstashESAssociationLabelNamespace, associationctl.LogstashESAssociationLabelName).
 		DoGarbageCollection()
 	if err != nil {
 		log.Error(err, "user garbage collector failed")
@@ -775,6 +1229,7 @@ func garbageCollectSoftOwnedSecrets(k8sClient k8s.Client) {
 		beatv1beta1.Kind:   &beatv1beta1.Beat{},
 		agentv1alpha1.Kind: &agentv1alpha1.Agent{},
 		emsv1alpha1.Kind:   &emsv1alpha1.ElasticMapsServer{},
+		lsv1alpha1.Kind:    &lsv1alpha1.Logstash{},
 	}); err != nil {
 		log.Error(err, "Orphan secrets garbage collection failed, will be attempted again at next operator restart.")
 		return
@@ -782,10 +1237,43 @@ func garbageCollectSoftOwnedSecrets(k8sClient k8s.Client) {
 	log.Info("Orphan secrets garbage collection complete")
 }
 
+// setupWebhookTunnel deploys the webhook relay Pod and starts forwarding the webhook connections it receives to the
+// local webhook server. It returns a function deleting the relay Pod, to be called once the operator stops.
+func setupWebhookTunnel(ctx context.Context, cfg *rest.Config, dialer net.Dialer, operatorNamespace string) (func(), error) {
+	image := viper.GetString(operator.WebhookTunnelImageFlag)
+	if image == "" {
+		return nil, fmt.Errorf("%s is required by %s", operator.WebhookTunnelImageFlag, operator.WebhookTunnelFlag)
+	}
+	log.Info("Enabling webhook tunneling, intended for development only",
+		"namespace", operatorNamespace, "pod_name", tunnel.RelayPodName, "image", image)
+	c, err := client.New(cfg, client.Options{Scheme: clientgoscheme.Scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	// the relay Pod takes the place of the operator Pod behind the webhook Service
+	if err := tunnel.EnsureNoOperatorPod(c, operatorNamespace); err != nil {
+		return nil, err
+	}
+	relay := tunnel.NewRelayPod(operatorNamespace, image, WebhookPort, tunnel.RelayLabels)
+	if err := tunnel.ReconcileRelayPod(c, relay); err != nil {
+		return nil, err
+	}
+
+	agent := tunnel.NewAgent(dialer, tunnel.RelayAgentAddr(operatorNamespace), fmt.Sprintf("127.0.0.1:%d", WebhookPort))
+	go agent.Run(ctx)
+	return func() {
+		if err := tunnel.DeleteRelayPod(c, operatorNamespace); err != nil {
+			log.Error(err, "Failed to delete the webhook relay Pod", "namespace", operatorNamespace, "pod_name", tunnel.RelayPodName)
+		}
+	}, nil
+}
+
 func setupWebhook(
 	mgr manager.Manager,
 	certRotation certificates.RotationParams,
 	validateStorageClass bool,
+	resourceSelector operator.ResourceSelector,
 	clientset kubernetes.Interface,
 	exposedNodeLabels esvalidation.NodeLabels) {
 	manageWebhookCerts := viper.GetBool(operator.ManageWebhookCertsFlag)
@@ -818,6 +1306,18 @@ func setupWebhook(
 		}
 	}
 
+	if viper.GetBool(operator.WebhookTunnelFlag) {
+		// the webhook secret is not mounted in the local filesystem when running outside of Kubernetes
+		webhookSecret := types.NamespacedName{
+			Namespace: viper.GetString(operator.OperatorNamespaceFlag),
+			Name:      viper.GetString(operator.WebhookSecretFlag),
+		}
+		if err := tunnel.WriteWebhookCertificates(context.Background(), mgr.GetAPIReader(), webhookSecret, mgr.GetWebhookServer().CertDir); err != nil {
+			log.Error(err, "unable to write the webhook certificates", "namespace", webhookSecret.Namespace, "secret_name", webhookSecret.Name)
+			os.Exit(1)
+		}
+	}
+
 	// setup webhooks for supported types
 	webhookObjects := []interface {
 		runtime.Object
@@ -833,6 +1333,7 @@ func setupWebhook(
 		&kbv1.Kibana{},
 		&kbv1beta1.Kibana{},
 		&emsv1alpha1.ElasticMapsServer{},
+		&lsv1alpha1.Logstash{},
 	}
 	for _, obj := range webhookObjects {
 		if err := obj.SetupWebhookWithManager(mgr); err != nil {
@@ -844,6 +1345,12 @@ func setupWebhook(
 	// esv1 validating webhook is wired up differently, in order to access the k8s client
 	esvalidation.RegisterWebhook(mgr, validateStorageClass, exposedNodeLabels)
 
+	// prevent label changes that would transfer resources between operator instances managing disjoint subsets of them
+	webhook.RegisterOwnershipWebhook(mgr, resourceSelector)
+
+	// prevent the creation of resources in namespaces the operator does not manage, where they would never be reconciled
+	webhook.RegisterManagedNamespacesWebhook(mgr, viper.GetStringSlice(operator.NamespacesFlag))
+
 	// wait for the secret to be populated in the local filesystem before returning
 	interval := time.Second * 1
 	timeout := time.Second * 30
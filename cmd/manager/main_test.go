@@ -6,21 +6,28 @@ package manager
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
 	beatv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/beat/v1beta1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	entv1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
@@ -192,3 +199,139 @@ func Test_garbageCollectSoftOwnedSecrets(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateLeaderElectionFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		leaseDuration time.Duration
+		renewDeadline time.Duration
+		retryPeriod   time.Duration
+		wantErr       bool
+	}{
+		{
+			name:          "defaults",
+			leaseDuration: 15 * time.Second,
+			renewDeadline: 10 * time.Second,
+			retryPeriod:   2 * time.Second,
+		},
+		{
+			name:          "renew deadline not lower than the lease duration",
+			leaseDuration: 10 * time.Second,
+			renewDeadline: 10 * time.Second,
+			retryPeriod:   2 * time.Second,
+			wantErr:       true,
+		},
+		{
+			name:          "retry period not lower than the renew deadline",
+			leaseDuration: 15 * time.Second,
+			renewDeadline: 10 * time.Second,
+			retryPeriod:   20 * time.Second,
+			wantErr:       true,
+		},
+		{
+			name:          "no retry period",
+			leaseDuration: 15 * time.Second,
+			renewDeadline: 10 * time.Second,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(operator.LeaderElectionLeaseDurationFlag, tt.leaseDuration)
+			viper.Set(operator.LeaderElectionRenewDeadlineFlag, tt.renewDeadline)
+			viper.Set(operator.LeaderElectionRetryPeriodFlag, tt.retryPeriod)
+			defer viper.Reset()
+
+			leaseDuration, renewDeadline, retryPeriod, err := validateLeaderElectionFlags()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.leaseDuration, leaseDuration)
+			require.Equal(t, tt.renewDeadline, renewDeadline)
+			require.Equal(t, tt.retryPeriod, retryPeriod)
+		})
+	}
+}
+
+func Test_shutdownGracePeriodFlag(t *testing.T) {
+	flag := Command().Flags().Lookup(operator.ShutdownGracePeriodFlag)
+	require.NotNil(t, flag)
+	require.Equal(t, "30s", flag.DefValue)
+}
+
+func Test_runManager(t *testing.T) {
+	log = logf.Log.WithName("test")
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		drain       time.Duration
+		wantDrained bool
+		wantErr     bool
+	}{
+		{
+			name:        "in-flight reconciliations complete within the grace period",
+			gracePeriod: 5 * time.Second,
+			drain:       200 * time.Millisecond,
+			wantDrained: true,
+		},
+		{
+			name:        "in-flight reconciliations do not complete within the grace period",
+			gracePeriod: 200 * time.Millisecond,
+			drain:       5 * time.Second,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gracePeriod := tt.gracePeriod
+			mgr, err := manager.New(&rest.Config{Host: "https://127.0.0.1:1"}, manager.Options{
+				MetricsBindAddress:      "0",
+				GracefulShutdownTimeout: &gracePeriod,
+				MapperProvider: func(_ *rest.Config) (meta.RESTMapper, error) {
+					return meta.NewDefaultRESTMapper(nil), nil
+				},
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ready := notShuttingDown(ctx)
+			started := make(chan struct{})
+			var drained, unreadyWhileDraining int32
+			// simulate an in-flight reconciliation lasting for the drain duration once the context is cancelled
+			require.NoError(t, mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				if ready(nil) != nil {
+					atomic.StoreInt32(&unreadyWhileDraining, 1)
+				}
+				time.Sleep(tt.drain)
+				atomic.StoreInt32(&drained, 1)
+				return nil
+			})))
+
+			require.NoError(t, ready(nil))
+			done := make(chan error, 1)
+			go func() {
+				done <- runManager(ctx, mgr, tt.gracePeriod, make(chan error))
+			}()
+			<-started
+			cancel()
+
+			start := time.Now()
+			select {
+			case err := <-done:
+				require.Equal(t, tt.wantErr, err != nil)
+			case <-time.After(10 * time.Second):
+				require.Fail(t, "the manager did not stop")
+			}
+			// the operator is not ready as soon as it starts shutting down
+			require.Error(t, ready(nil))
+			require.Equal(t, int32(1), atomic.LoadInt32(&unreadyWhileDraining))
+			// the manager returns once in-flight reconciliations complete, or at the latest after the grace period
+			require.Equal(t, tt.wantDrained, atomic.LoadInt32(&drained) == 1)
+			require.Less(t, time.Since(start), tt.gracePeriod+time.Second)
+		})
+	}
+}
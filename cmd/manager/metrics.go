@@ -0,0 +1,247 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+)
+
+const (
+	metricsPath = "/metrics"
+	// metricsCertRotateBefore is how long before its expiration the self-signed metrics certificate is renewed.
+	metricsCertRotateBefore = 24 * time.Hour
+	// metricsAuthCacheTTL is how long the authentication and authorization of a bearer token are cached.
+	metricsAuthCacheTTL = 1 * time.Minute
+)
+
+// secureMetricsServer serves the Prometheus metrics over HTTPS, to clients presenting a bearer token allowed to get
+// the /metrics non-resource URL. It runs on all operator replicas, whether they are elected or not.
+type secureMetricsServer struct {
+	server *http.Server
+}
+
+var _ manager.LeaderElectionRunnable = &secureMetricsServer{}
+
+// setupSecureMetrics replaces the plaintext metrics listener of the manager by a secure one on the given port.
+// The certificate and key are read from certDir if not empty, otherwise a self-signed certificate is generated.
+func setupSecureMetrics(mgr manager.Manager, clientset kubernetes.Interface, port int, certDir string) error {
+	getCertificate := selfSignedMetricsCertificate()
+	if certDir != "" {
+		getCertificate = metricsCertificateFromDir(certDir)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, authorizeMetrics(clientset, promhttp.HandlerFor(crmetrics.Registry, promhttp.HandlerOpts{})))
+	return mgr.Add(&secureMetricsServer{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: mux,
+			TLSConfig: &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: getCertificate,
+			},
+		},
+	})
+}
+
+func (s *secureMetricsServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *secureMetricsServer) Start(ctx context.Context) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.server.ListenAndServeTLS("", "")
+	}()
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return s.server.Shutdown(shutdownCtx)
+	case err := <-errChan:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// metricsCertificateFromDir returns the certificate and key read from the given directory, which are reloaded on each
+// TLS handshake so that they can be rotated without restarting the operator.
+func metricsCertificateFromDir(certDir string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(
+			filepath.Join(certDir, certificates.CertFileName),
+			filepath.Join(certDir, certificates.KeyFileName),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &cert, nil
+	}
+}
+
+// selfSignedMetricsCertificate returns a self-signed certificate, renewed when it is about to expire.
+func selfSignedMetricsCertificate() func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	var mutex sync.Mutex
+	var current *tls.Certificate
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if current != nil && certificates.CertIsValid(*current.Leaf, metricsCertRotateBefore) {
+			return current, nil
+		}
+		ca, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{
+			Subject: pkix.Name{CommonName: "elastic-operator-metrics", OrganizationalUnit: []string{"elastic-operator"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		current = &tls.Certificate{Certificate: [][]byte{ca.Cert.Raw}, PrivateKey: ca.PrivateKey, Leaf: ca.Cert}
+		return current, nil
+	}
+}
+
+// authorizeMetrics only lets through the requests with a bearer token authenticated by the API server, whose user is
+// allowed to get the /metrics non-resource URL. Decisions are cached by token for metricsAuthCacheTTL, so that
+// periodic scrapes do not each send a TokenReview and a SubjectAccessReview to the API server.
+func authorizeMetrics(clientset kubernetes.Interface, next http.Handler) http.Handler {
+	return newMetricsAuthorizer(clientset, metricsAuthCacheTTL).handler(next)
+}
+
+// metricsAuthDecision is the HTTP status resulting from the authentication and authorization of a token.
+type metricsAuthDecision struct {
+	status    int
+	expiresAt time.Time
+}
+
+type metricsAuthorizer struct {
+	clientset kubernetes.Interface
+	ttl       time.Duration
+	now       func() time.Time
+
+	mutex     sync.Mutex
+	decisions map[[sha256.Size]byte]metricsAuthDecision
+}
+
+func newMetricsAuthorizer(clientset kubernetes.Interface, ttl time.Duration) *metricsAuthorizer {
+	return &metricsAuthorizer{
+		clientset: clientset,
+		ttl:       ttl,
+		now:       time.Now,
+		decisions: make(map[[sha256.Size]byte]metricsAuthDecision),
+	}
+}
+
+func (a *metricsAuthorizer) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(authorization, "Bearer ")
+		if !strings.HasPrefix(authorization, "Bearer ") || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		status, err := a.authorize(r.Context(), token)
+		if err != nil {
+			log.Error(err, "Failed to authorize metrics request")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize returns the cached decision for the given token, or reviews it with the API server and caches the
+// resulting decision. Errors are not cached.
+func (a *metricsAuthorizer) authorize(ctx context.Context, token string) (int, error) {
+	key := sha256.Sum256([]byte(token))
+	a.mutex.Lock()
+	decision, exists := a.decisions[key]
+	a.mutex.Unlock()
+	if exists && a.now().Before(decision.expiresAt) {
+		return decision.status, nil
+	}
+
+	status, err := a.review(ctx, token)
+	if err != nil {
+		return 0, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := a.now()
+	// drop the expired decisions, for the tokens which are not used anymore
+	for k, d := range a.decisions {
+		if !now.Before(d.expiresAt) {
+			delete(a.decisions, k)
+		}
+	}
+	a.decisions[key] = metricsAuthDecision{status: status, expiresAt: now.Add(a.ttl)}
+	return status, nil
+}
+
+// review authenticates the token and checks that its user is allowed to get the metrics, through the API server.
+func (a *metricsAuthorizer) review(ctx context.Context, token string) (int, error) {
+	tokenReview, err := a.clientset.AuthenticationV1().TokenReviews().Create(
+		ctx,
+		&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, nil
+	}
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	accessReview, err := a.clientset.AuthorizationV1().SubjectAccessReviews().Create(
+		ctx,
+		&authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: metricsPath,
+				Verb: "get",
+			},
+		}},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, nil
+	}
+	return http.StatusOK, nil
+}
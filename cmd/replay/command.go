@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package replay
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	defaultTopologySpreadFlag     = "default-topology-spread"
+	dumpDirFlag                   = "dump-dir"
+	ipFamilyFlag                  = "ip-family"
+	pluginsMirrorFlag             = "elasticsearch-plugins-mirror"
+	setDefaultSecurityContextFlag = "set-default-security-context"
+)
+
+// Command returns the command replaying the calculation phase of the Elasticsearch reconciliation against a dump of
+// Kubernetes resources, for debugging purposes.
+func Command() *cobra.Command {
+	var dumpDir, ipFamily string
+	var opts nodespec.Options
+
+	cmd := &cobra.Command{
+		Use:   "replay <namespace>/<name>",
+		Short: "Print the changes the operator would apply to an Elasticsearch cluster from a dump of its namespace (development only)",
+		Long: "Load the Kubernetes resources of a namespace dumped by eck-diagnostics, run the calculation phase of the " +
+			"Elasticsearch reconciliation against them, and print the changes the operator would apply to the StatefulSets. " +
+			"Nothing is written to any Kubernetes cluster. Since diagnostics redact Secrets, differences related to " +
+			"secure settings or configuration may be reported spuriously.",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			esNSN, err := k8s.ParseNamespacedName(args[0])
+			if err != nil {
+				return err
+			}
+			objs, err := LoadDump(dumpDir, esNSN.Namespace, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			c := k8s.NewFakeClient(objs...)
+			var es esv1.Elasticsearch
+			if err := c.Get(cmd.Context(), esNSN, &es); err != nil {
+				return fmt.Errorf("while getting Elasticsearch %s from the dump: %w", esNSN, err)
+			}
+			changes, err := driver.PlanNodesChanges(cmd.Context(), c, es, corev1.IPFamily(ipFamily), opts)
+			if err != nil {
+				return err
+			}
+			printChanges(cmd.OutOrStdout(), changes)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dumpDir, dumpDirFlag, "", "Directory containing the resources dumped by eck-diagnostics")
+	cmd.Flags().StringVar(&ipFamily, ipFamilyFlag, string(corev1.IPv4Protocol), "IP family of the operator environment (IPv4 or IPv6)")
+	cmd.Flags().BoolVar(&opts.SetDefaultSecurityContext, setDefaultSecurityContextFlag, true, "Whether the operator sets a default security context on Elasticsearch Pods")
+	cmd.Flags().BoolVar(&opts.DefaultTopologySpread, defaultTopologySpreadFlag, false, "Whether the operator sets default topology spread constraints on Elasticsearch Pods")
+	cmd.Flags().StringVar(&opts.PluginsMirror, pluginsMirrorFlag, "", "Base URL of the mirror the operator installs Elasticsearch plugins from")
+	_ = cmd.MarkFlagRequired(dumpDirFlag)
+	return cmd
+}
+
+func printChanges(w io.Writer, changes []driver.PlannedChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No change to apply")
+		return
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s StatefulSet %s: %s\n", change.Type, change.StatefulSet, change.Details)
+	}
+}
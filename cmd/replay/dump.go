@@ -0,0 +1,122 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	controllerscheme "github.com/elastic/cloud-on-k8s/pkg/controller/common/scheme"
+)
+
+// LoadDump returns the Kubernetes resources of the given namespace, along with cluster-scoped resources, found in the
+// JSON and YAML files of dir and its subdirectories. Lists are flattened into their items. Documents that are not
+// Kubernetes resources, or whose kind is not known to the operator scheme, are ignored. Files that cannot be parsed
+// are reported to warnings and skipped.
+func LoadDump(dir string, namespace string, warnings io.Writer) ([]runtime.Object, error) {
+	controllerscheme.SetupScheme()
+
+	var objs []runtime.Object
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+		fileObjs, err := loadFile(path, namespace)
+		if err != nil {
+			fmt.Fprintf(warnings, "Skipping %s: %v\n", path, err)
+			return nil
+		}
+		objs = append(objs, fileObjs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+func loadFile(path string, namespace string) ([]runtime.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []runtime.Object
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, err
+		}
+		u := unstructured.Unstructured{Object: doc}
+		if u.GetKind() == "" || u.GetAPIVersion() == "" {
+			// not a Kubernetes resource, probably the output of an Elasticsearch API
+			continue
+		}
+		if !u.IsList() {
+			obj, err := toTypedObject(u, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if obj != nil {
+				objs = append(objs, obj)
+			}
+			continue
+		}
+		list, err := u.ToList()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			obj, err := toTypedObject(item, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if obj != nil {
+				objs = append(objs, obj)
+			}
+		}
+	}
+}
+
+// toTypedObject converts u to its typed counterpart, or returns nil if u belongs to another namespace or its kind
+// is unknown.
+func toTypedObject(u unstructured.Unstructured, namespace string) (runtime.Object, error) {
+	if u.GetNamespace() != "" && u.GetNamespace() != namespace {
+		return nil, nil
+	}
+	obj, err := clientgoscheme.Scheme.New(u.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, fmt.Errorf("while converting %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+	}
+	return obj, nil
+}
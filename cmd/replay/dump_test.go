@@ -0,0 +1,96 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package replay
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+)
+
+const (
+	elasticsearchYAML = `apiVersion: elasticsearch.k8s.elastic.co/v1
+kind: Elasticsearch
+metadata:
+  name: es
+  namespace: ns
+spec:
+  version: 7.15.2
+  nodeSets:
+  - name: default
+    count: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+  namespace: other-ns
+`
+	statefulSetsJSON = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "apps/v1", "kind": "StatefulSet", "metadata": {"name": "es-es-default", "namespace": "ns"}},
+    {"apiVersion": "example.com/v1", "kind": "Unknown", "metadata": {"name": "unknown", "namespace": "ns"}}
+  ]
+}`
+	storageClassJSON  = `{"apiVersion": "storage.k8s.io/v1", "kind": "StorageClass", "metadata": {"name": "standard"}}`
+	clusterHealthJSON = `{"cluster_name": "es", "status": "green"}`
+	catShardsJSON     = `[{"index": "data"}]`
+)
+
+func TestLoadDump(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ns/elasticsearch.yaml":                   elasticsearchYAML,
+		"ns/statefulsets.json":                    statefulSetsJSON,
+		"storageclasses.json":                     storageClassJSON,
+		"ns/elasticsearch/es/cluster_health.json": clusterHealthJSON,
+		"ns/elasticsearch/es/cat_shards.json":     catShardsJSON,
+		"ns/logs.txt":                             "not a resource",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	warnings := &bytes.Buffer{}
+	objs, err := LoadDump(dir, "ns", warnings)
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, obj := range objs {
+		o, ok := obj.(client.Object)
+		require.True(t, ok)
+		names[o.GetName()] = true
+	}
+	require.Equal(t, map[string]bool{"es": true, "es-es-default": true, "standard": true}, names)
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *esv1.Elasticsearch:
+			require.Equal(t, int32(3), o.Spec.NodeSets[0].Count)
+		case *appsv1.StatefulSet:
+			require.Equal(t, "ns", o.Namespace)
+		case *corev1.ConfigMap:
+			t.Errorf("unexpected ConfigMap from another namespace")
+		}
+	}
+	require.Contains(t, warnings.String(), "cat_shards.json")
+	require.NotContains(t, warnings.String(), "cluster_health.json")
+}
+
+func TestLoadDump_MissingDir(t *testing.T) {
+	_, err := LoadDump(filepath.Join(t.TempDir(), "missing"), "ns", &bytes.Buffer{})
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package restart
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controllerscheme "github.com/elastic/cloud-on-k8s/pkg/controller/common/scheme"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const progressInterval = 10 * time.Second
+
+// Command returns the command triggering rolling restarts of managed resources.
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Trigger a rolling restart of a resource managed by the operator",
+	}
+	cmd.AddCommand(elasticsearchCommand())
+	return cmd
+}
+
+func elasticsearchCommand() *cobra.Command {
+	var nodeSets []string
+	var wait bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "elasticsearch <namespace>/<name>",
+		Aliases: []string{"es"},
+		Short:   "Trigger a rolling restart of the Pods of an Elasticsearch cluster",
+		Long: "Trigger a rolling restart of the Pods of an Elasticsearch cluster, following the same orchestration " +
+			"as version upgrades: Pods are restarted one at a time when the cluster health allows it, and nodes are " +
+			"prepared for shutdown when supported by Elasticsearch. The restart is requested by setting the " +
+			"eck.k8s.elastic.co/restart annotation, or the eck.k8s.elastic.co/restart.<nodeSet> annotations, on the " +
+			"Elasticsearch resource.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			esNSN, err := k8s.ParseNamespacedName(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			return restartElasticsearch(cmd.Context(), c, cmd.OutOrStdout(), esNSN, nodeSets, wait, timeout)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&nodeSets, "nodeset", nil, "Comma-separated list of nodeSets to restart (defaults to all nodeSets)")
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait for all the Pods to be restarted, printing progress")
+	cmd.Flags().DurationVar(&timeout, "timeout", 1*time.Hour, "Maximum duration to wait for the restart to complete")
+
+	return cmd
+}
+
+func newClient() (k8s.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	controllerscheme.SetupScheme()
+	return client.New(cfg, client.Options{Scheme: clientgoscheme.Scheme})
+}
+
+func restartElasticsearch(
+	ctx context.Context,
+	c k8s.Client,
+	out io.Writer,
+	esNSN types.NamespacedName,
+	nodeSets []string,
+	waitForRestart bool,
+	timeout time.Duration,
+) error {
+	trigger := time.Now().UTC().Format(time.RFC3339)
+	restarted, err := triggerRestart(ctx, c, esNSN, nodeSets, trigger)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Rolling restart of Elasticsearch %s triggered for nodeSets: %s\n", esNSN, strings.Join(restarted, ", "))
+
+	if !waitForRestart {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(progressInterval, func() (bool, error) {
+		progress, err := restartProgress(ctx, c, esNSN, restarted)
+		if err != nil {
+			return false, err
+		}
+		done := true
+		status := make([]string, len(progress))
+		for i, p := range progress {
+			status[i] = p.String()
+			done = done && p.done()
+		}
+		fmt.Fprintf(out, "Restarted Pods: %s\n", strings.Join(status, ", "))
+		if done {
+			fmt.Fprintln(out, "Rolling restart complete")
+		}
+		return done, nil
+	}, ctx.Done())
+}
@@ -0,0 +1,104 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package restart
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+// triggerRestart sets the restart annotation of the given NodeSets to the given value on the Elasticsearch resource, or
+// the restart annotation of the whole cluster if no NodeSet is specified. It returns the names of the NodeSets to be
+// restarted.
+func triggerRestart(ctx context.Context, c k8s.Client, esNSN types.NamespacedName, nodeSets []string, trigger string) ([]string, error) {
+	var restarted []string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var es esv1.Elasticsearch
+		if err := c.Get(ctx, esNSN, &es); err != nil {
+			return err
+		}
+
+		for _, name := range nodeSets {
+			if !stringsutil.StringInSlice(name, esv1.NodeSetList(es.Spec.NodeSets).Names()) {
+				return fmt.Errorf("nodeSet %s not found in Elasticsearch %s", name, esNSN)
+			}
+		}
+
+		if es.Annotations == nil {
+			es.Annotations = map[string]string{}
+		}
+		if len(nodeSets) == 0 {
+			es.Annotations[esv1.RestartAnnotation] = trigger
+		}
+		for _, name := range nodeSets {
+			es.Annotations[esv1.NodeSetRestartAnnotationPrefix+name] = trigger
+		}
+
+		restarted = restarted[:0]
+		for _, nodeSet := range es.Spec.NodeSets {
+			if len(nodeSets) == 0 || stringsutil.StringInSlice(nodeSet.Name, nodeSets) {
+				restarted = append(restarted, nodeSet.Name)
+			}
+		}
+		return c.Update(ctx, &es)
+	})
+	return restarted, err
+}
+
+// nodeSetProgress is the restart progress of the Pods of a NodeSet.
+type nodeSetProgress struct {
+	name      string
+	restarted int32
+	replicas  int32
+}
+
+// done returns true once all the Pods of the NodeSet have been restarted.
+func (p nodeSetProgress) done() bool {
+	return p.restarted == p.replicas
+}
+
+func (p nodeSetProgress) String() string {
+	return fmt.Sprintf("%s: %d/%d", p.name, p.restarted, p.replicas)
+}
+
+// restartProgress returns the restart progress of the given NodeSets. Pods are considered restarted once they run
+// the revision of the StatefulSet that includes the current restart annotations of the Elasticsearch resource.
+func restartProgress(ctx context.Context, c k8s.Client, esNSN types.NamespacedName, nodeSets []string) ([]nodeSetProgress, error) {
+	var es esv1.Elasticsearch
+	if err := c.Get(ctx, esNSN, &es); err != nil {
+		return nil, err
+	}
+	progress := make([]nodeSetProgress, 0, len(nodeSets))
+	for _, nodeSet := range nodeSets {
+		var sset appsv1.StatefulSet
+		err := c.Get(ctx, types.NamespacedName{Namespace: esNSN.Namespace, Name: esv1.StatefulSet(esNSN.Name, nodeSet)}, &sset)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		p := nodeSetProgress{name: nodeSet}
+		if sset.Spec.Replicas != nil {
+			p.replicas = *sset.Spec.Replicas
+		}
+		// the StatefulSet may not have been updated by the operator yet
+		actualHash := sset.Spec.Template.Labels[label.RestartTriggerHashLabelName]
+		if err == nil && actualHash == label.RestartTriggerHash(es, nodeSet, actualHash) &&
+			sset.Status.ObservedGeneration >= sset.Generation {
+			p.restarted = sset.Status.UpdatedReplicas
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
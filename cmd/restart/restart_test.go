@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package restart
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
+)
+
+var esNSN = types.NamespacedName{Namespace: "ns", Name: "es"}
+
+func newES() *esv1.Elasticsearch {
+	return &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: esNSN.Namespace, Name: esNSN.Name},
+		Spec: esv1.ElasticsearchSpec{
+			NodeSets: []esv1.NodeSet{{Name: "master", Count: 3}, {Name: "data", Count: 3}},
+		},
+	}
+}
+
+func Test_triggerRestart(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeSets      []string
+		wantRestarted []string
+		wantErr       bool
+	}{
+		{
+			name:          "all nodeSets",
+			wantRestarted: []string{"master", "data"},
+		},
+		{
+			name:          "a single nodeSet",
+			nodeSets:      []string{"data"},
+			wantRestarted: []string{"data"},
+		},
+		{
+			name:     "unknown nodeSet",
+			nodeSets: []string{"data", "ingest"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.NewFakeClient(newES())
+			restarted, err := triggerRestart(context.Background(), c, esNSN, tt.nodeSets, "trigger")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRestarted, restarted)
+
+			var es esv1.Elasticsearch
+			require.NoError(t, c.Get(context.Background(), esNSN, &es))
+			for _, nodeSet := range es.Spec.NodeSets {
+				assert.Empty(t, nodeSet.PodTemplate.Annotations)
+				if len(tt.nodeSets) == 0 || nodeSet.Name == "data" {
+					assert.NotEmpty(t, es.RestartTrigger(nodeSet.Name))
+				} else {
+					assert.Empty(t, es.RestartTrigger(nodeSet.Name))
+				}
+			}
+		})
+	}
+}
+
+func Test_restartProgress(t *testing.T) {
+	es := newES()
+	es.Annotations = map[string]string{esv1.RestartAnnotation: "trigger"}
+	previous := newES()
+	previous.Annotations = map[string]string{esv1.RestartAnnotation: "previous-trigger"}
+	sset := func(name string, es *esv1.Elasticsearch, updated int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: esNSN.Namespace, Name: esv1.StatefulSet(esNSN.Name, name)},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: pointer.Int32(3),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+						label.RestartTriggerHashLabelName: label.RestartTriggerHash(*es, name, ""),
+					}},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{UpdatedReplicas: updated},
+		}
+	}
+	c := k8s.NewFakeClient(
+		es,
+		// restart in progress
+		sset("master", es, 1),
+		// not updated by the operator yet
+		sset("data", previous, 3),
+	)
+
+	progress, err := restartProgress(context.Background(), c, esNSN, []string{"master", "data"})
+	require.NoError(t, err)
+	assert.Equal(t, []nodeSetProgress{
+		{name: "master", restarted: 1, replicas: 3},
+		{name: "data", restarted: 0, replicas: 3},
+	}, progress)
+	assert.False(t, progress[0].done())
+}
@@ -0,0 +1,40 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package webhookrelay
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/elastic/cloud-on-k8s/pkg/dev/tunnel"
+)
+
+const (
+	webhookPortFlag = "webhook-port"
+	agentPortFlag   = "agent-port"
+)
+
+// Command returns the command running the webhook relay, used in development mode to serve the webhook from an
+// operator running outside of Kubernetes.
+func Command() *cobra.Command {
+	var webhookPort, agentPort int
+	cmd := &cobra.Command{
+		Use:    tunnel.RelayCommand,
+		Short:  "Relay webhook connections to an operator running outside of Kubernetes (development only)",
+		Hidden: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return tunnel.RunRelay(
+				signals.SetupSignalHandler(),
+				fmt.Sprintf(":%d", webhookPort),
+				fmt.Sprintf(":%d", agentPort),
+			)
+		},
+	}
+	cmd.Flags().IntVar(&webhookPort, webhookPortFlag, 9443, "Port to accept webhook connections on")
+	cmd.Flags().IntVar(&agentPort, agentPortFlag, tunnel.RelayAgentPort, "Port to accept connections from the operator on")
+	return cmd
+}
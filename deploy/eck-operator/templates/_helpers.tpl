@@ -126,6 +126,12 @@ updating docs/operating-eck/eck-permissions.asciidoc file.
   - subjectaccessreviews
   verbs:
   - create
+- apiGroups:
+  - "authentication.k8s.io"
+  resources:
+  - tokenreviews
+  verbs:
+  - create
 - apiGroups:
   - ""
   resources:
@@ -177,6 +183,18 @@ updating docs/operating-eck/eck-permissions.asciidoc file.
   - update
   - patch
   - delete
+- apiGroups:
+  - cert-manager.io
+  resources:
+  - certificates
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - patch
+  - delete
 - apiGroups:
   - elasticsearch.k8s.elastic.co
   resources:
@@ -268,6 +286,56 @@ updating docs/operating-eck/eck-permissions.asciidoc file.
   - create
   - update
   - patch
+- apiGroups:
+  - logstash.k8s.elastic.co
+  resources:
+  - logstashes
+  - logstashes/status
+  - logstashes/finalizers # needed for ownerReferences with blockOwnerDeletion on OCP
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - patch
+- apiGroups:
+  - snapshot.k8s.elastic.co
+  resources:
+  - snapshotrepositories
+  - snapshotrepositories/status
+  - snapshotpolicies
+  - snapshotpolicies/status
+  verbs:
+  - get
+  - list
+  - watch
+  - update
+  - patch
+- apiGroups:
+  - security.k8s.elastic.co
+  resources:
+  - elasticsearchusers
+  - elasticsearchusers/status
+  - elasticsearchroles
+  - elasticsearchroles/status
+  verbs:
+  - get
+  - list
+  - watch
+  - update
+  - patch
+- apiGroups:
+  - stackconfigpolicy.k8s.elastic.co
+  resources:
+  - stackconfigpolicies
+  - stackconfigpolicies/status
+  verbs:
+  - get
+  - list
+  - watch
+  - update
+  - patch
 {{- end -}}
 
 {{/*
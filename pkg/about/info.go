@@ -37,6 +37,17 @@ var (
 	}
 )
 
+// openShiftAPIGroup is an API group only served by OpenShift clusters.
+const openShiftAPIGroup = "config.openshift.io"
+
+// platformVersionSuffixes maps the suffixes managed Kubernetes platforms add to the server version to the platform name.
+var platformVersionSuffixes = map[string]string{
+	"-gke.": "gke",
+	"-eks-": "eks",
+	"+k3s":  "k3s",
+	"+rke2": "rke2",
+}
+
 var defaultOperatorNamespaces = []string{"elastic-system"}
 
 // OperatorInfo contains information about the operator.
@@ -44,6 +55,7 @@ type OperatorInfo struct {
 	OperatorUUID            types.UID `json:"operator_uuid"`
 	CustomOperatorNamespace bool      `json:"custom_operator_namespace"`
 	Distribution            string    `json:"distribution"`
+	Platform                string    `json:"platform"`
 	DistributionChannel     string    `json:"distributionChannel"`
 	BuildInfo               BuildInfo `json:"build"`
 }
@@ -93,6 +105,11 @@ func GetOperatorInfo(clientset kubernetes.Interface, operatorNs, distributionCha
 		return OperatorInfo{}, err
 	}
 
+	platform, err := getPlatform(clientset, distribution)
+	if err != nil {
+		return OperatorInfo{}, err
+	}
+
 	customOperatorNs := true
 	for _, ns := range defaultOperatorNamespaces {
 		if operatorNs == ns {
@@ -110,6 +127,7 @@ func GetOperatorInfo(clientset kubernetes.Interface, operatorNs, distributionCha
 		OperatorUUID:            operatorUUID,
 		CustomOperatorNamespace: customOperatorNs,
 		Distribution:            distribution,
+		Platform:                platform,
 		DistributionChannel:     distributionChannel,
 		BuildInfo:               GetBuildInfo(),
 	}, nil
@@ -174,6 +192,26 @@ func getDistribution(clientset kubernetes.Interface) (string, error) {
 	return version.GitVersion, nil
 }
 
+// getPlatform returns the Kubernetes platform the operator runs on, such as openshift or gke, or an empty string if it
+// is not recognized. OpenShift is detected through its API groups, managed platforms through their version suffix.
+func getPlatform(clientset kubernetes.Interface, gitVersion string) (string, error) {
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups.Groups {
+		if group.Name == openShiftAPIGroup {
+			return "openshift", nil
+		}
+	}
+	for suffix, platform := range platformVersionSuffixes {
+		if strings.Contains(gitVersion, suffix) {
+			return platform, nil
+		}
+	}
+	return "", nil
+}
+
 // GetBuildInfo returns information about the current build.
 func GetBuildInfo() BuildInfo {
 	return BuildInfo{
@@ -13,6 +13,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -119,3 +121,52 @@ func TestGetOperatorInfo_DistributionChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOperatorInfo_Platform(t *testing.T) {
+	tests := []struct {
+		name         string
+		gitVersion   string
+		apiGroups    []string
+		wantPlatform string
+	}{
+		{
+			name:         "openshift",
+			gitVersion:   "v1.22.0-rc.0+75ee307",
+			apiGroups:    []string{"apps/v1", "config.openshift.io/v1"},
+			wantPlatform: "openshift",
+		},
+		{
+			name:         "gke",
+			gitVersion:   "v1.21.5-gke.1302",
+			apiGroups:    []string{"apps/v1"},
+			wantPlatform: "gke",
+		},
+		{
+			name:         "eks",
+			gitVersion:   "v1.21.2-eks-06eac09",
+			wantPlatform: "eks",
+		},
+		{
+			name:         "unknown platform",
+			gitVersion:   "v1.22.3",
+			apiGroups:    []string{"apps/v1"},
+			wantPlatform: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fakeClientset := k8sfake.NewSimpleClientset()
+			discovery := fakeClientset.Discovery().(*fakediscovery.FakeDiscovery)
+			discovery.FakedServerVersion = &k8sversion.Info{GitVersion: test.gitVersion}
+			for _, group := range test.apiGroups {
+				discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{GroupVersion: group})
+			}
+
+			operatorInfo, err := GetOperatorInfo(fakeClientset, fakeOperatorNs, fakeDistributionChannel)
+			require.NoError(t, err)
+			require.Equal(t, test.gitVersion, operatorInfo.Distribution)
+			require.Equal(t, test.wantPlatform, operatorInfo.Platform)
+		})
+	}
+}
@@ -90,6 +90,11 @@ type AgentSpec struct {
 	// Don't set unless `mode` is set to `fleet`.
 	// +kubebuilder:validation:Optional
 	FleetServerRef commonv1.ObjectSelector `json:"fleetServerRef,omitempty"`
+
+	// PolicyID optionally determines into which Agent Policy this Agent will be enrolled. If left empty the default
+	// policy will be used. Don't set unless `mode` is set to `fleet`. Requires `kibanaRef` unless Fleet Server is enabled.
+	// +kubebuilder:validation:Optional
+	PolicyID string `json:"policyID,omitempty"`
 }
 
 type Output struct {
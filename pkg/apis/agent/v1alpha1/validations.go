@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
@@ -26,10 +27,14 @@ var (
 		checkSpec,
 		checkEmptyConfigForFleetMode,
 		checkFleetServerOnlyInFleetMode,
+		checkPolicyIDOnlyInFleetMode,
+		checkPolicyIDRequiresKibanaRef,
 		checkHTTPConfigOnlyForFleetServer,
 		checkFleetServerOrFleetServerRef,
 		checkReferenceSetForMode,
 		checkSingleESRefInFleetMode,
+		checkWindowsNodes,
+		checkAssociations,
 	}
 
 	updateChecks = []func(old, curr *Agent) field.ErrorList{
@@ -159,6 +164,30 @@ func checkFleetServerOnlyInFleetMode(a *Agent) field.ErrorList {
 	return nil
 }
 
+func checkPolicyIDOnlyInFleetMode(a *Agent) field.ErrorList {
+	if a.Spec.StandaloneModeEnabled() && a.Spec.PolicyID != "" {
+		return field.ErrorList{field.Invalid(
+			field.NewPath("spec").Child("policyID"),
+			a.Spec.PolicyID,
+			"remove policyID, it can't be set in standalone mode",
+		)}
+	}
+	return nil
+}
+
+// checkPolicyIDRequiresKibanaRef checks that Elastic Agents enrolling in a given policy reference Kibana, from which
+// the enrollment token of the policy is retrieved. Fleet Server receives its policy ID directly.
+func checkPolicyIDRequiresKibanaRef(a *Agent) field.ErrorList {
+	if a.Spec.FleetModeEnabled() && !a.Spec.FleetServerEnabled && a.Spec.PolicyID != "" && !a.Spec.KibanaRef.IsDefined() {
+		return field.ErrorList{field.Invalid(
+			field.NewPath("spec").Child("policyID"),
+			a.Spec.PolicyID,
+			"set kibanaRef, it is required to retrieve the enrollment token of the policy",
+		)}
+	}
+	return nil
+}
+
 func checkFleetServerOrFleetServerRef(a *Agent) field.ErrorList {
 	if a.Spec.FleetServerEnabled && a.Spec.FleetServerRef.IsDefined() {
 		return field.ErrorList{
@@ -228,3 +257,50 @@ func checkSingleESRefInFleetMode(a *Agent) field.ErrorList {
 	}
 	return nil
 }
+
+// podTemplate returns the pod template of the Agent along with its path in the spec.
+func (a *Agent) podTemplate() (corev1.PodTemplateSpec, *field.Path) {
+	if a.Spec.Deployment != nil {
+		return a.Spec.Deployment.PodTemplate, field.NewPath("spec").Child("deployment", "podTemplate")
+	}
+	if a.Spec.DaemonSet != nil {
+		return a.Spec.DaemonSet.PodTemplate, field.NewPath("spec").Child("daemonSet", "podTemplate")
+	}
+	return corev1.PodTemplateSpec{}, nil
+}
+
+func checkWindowsNodes(a *Agent) field.ErrorList {
+	podTemplate, path := a.podTemplate()
+	if path == nil {
+		return nil
+	}
+	errs := commonv1.CheckPodTemplateOS(podTemplate, path)
+	if !commonv1.IsWindowsPodTemplate(podTemplate) {
+		return errs
+	}
+	if a.Spec.Image == "" {
+		errs = append(errs, field.Required(
+			field.NewPath("spec").Child("image"),
+			"Image is required when running on Windows nodes, default images only support Linux"))
+	}
+	if a.Spec.FleetServerEnabled {
+		errs = append(errs, field.Invalid(
+			field.NewPath("spec").Child("fleetServerEnabled"),
+			a.Spec.FleetServerEnabled,
+			"disable Fleet Server, it can't be enabled on Windows nodes",
+		))
+	}
+	return errs
+}
+
+func checkAssociations(a *Agent) field.ErrorList {
+	esRefs := make([]commonv1.ObjectSelector, 0, len(a.Spec.ElasticsearchRefs))
+	for _, output := range a.Spec.ElasticsearchRefs {
+		esRefs = append(esRefs, output.ObjectSelector)
+	}
+	var errs field.ErrorList
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRefs"), esRefs...)...)
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("kibanaRef"), a.Spec.KibanaRef)...)
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("fleetServerRef"), a.Spec.FleetServerRef)...)
+	return errs
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 )
@@ -352,6 +353,195 @@ func Test_checkFleetServerOnlyInFleetMode(t *testing.T) {
 	}
 }
 
+func Test_checkPolicyIDOnlyInFleetMode(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		a       *Agent
+		wantErr bool
+	}{
+		{
+			name: "no policy ID in standalone mode: OK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode: AgentStandaloneMode,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "policy ID in fleet mode: OK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode:     AgentFleetMode,
+					PolicyID: "my-policy",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "policy ID in standalone mode: NOK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode:     AgentStandaloneMode,
+					PolicyID: "my-policy",
+				},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkPolicyIDOnlyInFleetMode(tt.a)
+			assert.Equal(t, tt.wantErr, len(got) > 0)
+		})
+	}
+}
+
+func Test_checkPolicyIDRequiresKibanaRef(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		a       *Agent
+		wantErr bool
+	}{
+		{
+			name:    "no policy ID: OK",
+			a:       &Agent{Spec: AgentSpec{Mode: AgentFleetMode}},
+			wantErr: false,
+		},
+		{
+			name: "policy ID with a Kibana reference: OK",
+			a: &Agent{Spec: AgentSpec{
+				Mode:      AgentFleetMode,
+				PolicyID:  "my-policy",
+				KibanaRef: commonv1.ObjectSelector{Name: "kibana"},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "Fleet Server policy ID without a Kibana reference: OK",
+			a: &Agent{Spec: AgentSpec{
+				Mode:               AgentFleetMode,
+				FleetServerEnabled: true,
+				PolicyID:           "my-policy",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "Elastic Agent policy ID without a Kibana reference: NOK",
+			a: &Agent{Spec: AgentSpec{
+				Mode:     AgentFleetMode,
+				PolicyID: "my-policy",
+			}},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkPolicyIDRequiresKibanaRef(tt.a)
+			assert.Equal(t, tt.wantErr, len(got) > 0)
+		})
+	}
+}
+
+func Test_checkAssociations(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		a       *Agent
+		wantErr string
+	}{
+		{
+			name: "managed references: OK",
+			a: &Agent{Spec: AgentSpec{
+				ElasticsearchRefs: []Output{{ObjectSelector: commonv1.ObjectSelector{Name: "es"}}},
+				KibanaRef:         commonv1.ObjectSelector{Name: "kibana"},
+				FleetServerRef:    commonv1.ObjectSelector{Name: "fleet-server"},
+			}},
+		},
+		{
+			name: "references to resources not managed by the operator: OK",
+			a: &Agent{Spec: AgentSpec{
+				ElasticsearchRefs: []Output{{ObjectSelector: commonv1.ObjectSelector{SecretName: "es"}}},
+				KibanaRef:         commonv1.ObjectSelector{SecretName: "kibana"},
+			}},
+		},
+		{
+			name: "output with both secretName and name: NOK",
+			a: &Agent{Spec: AgentSpec{
+				ElasticsearchRefs: []Output{
+					{ObjectSelector: commonv1.ObjectSelector{Name: "es"}, OutputName: "default"},
+					{ObjectSelector: commonv1.ObjectSelector{SecretName: "es", Name: "es"}, OutputName: "monitoring"},
+				},
+			}},
+			wantErr: "spec.elasticsearchRefs[1]: Forbidden",
+		},
+		{
+			name: "Fleet Server reference with both secretName and name: NOK",
+			a: &Agent{Spec: AgentSpec{
+				FleetServerRef: commonv1.ObjectSelector{SecretName: "fleet-server", Namespace: "ns"},
+			}},
+			wantErr: "spec.fleetServerRef: Forbidden",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkAssociations(tt.a)
+			if tt.wantErr == "" {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Len(t, got, 1)
+			assert.Contains(t, got.ToAggregate().Error(), tt.wantErr)
+		})
+	}
+}
+
+func Test_checkWindowsNodes(t *testing.T) {
+	windowsPodTemplate := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{corev1.LabelOSStable: commonv1.WindowsOS},
+		},
+	}
+	for _, tt := range []struct {
+		name       string
+		a          *Agent
+		wantErrors int
+	}{
+		{
+			name: "linux nodes: OK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode:               AgentFleetMode,
+					FleetServerEnabled: true,
+					Deployment:         &DeploymentSpec{},
+				},
+			},
+		},
+		{
+			name: "windows nodes with a custom image: OK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode:      AgentFleetMode,
+					Image:     "my-registry/elastic-agent-windows:7.15.0",
+					DaemonSet: &DaemonSetSpec{PodTemplate: windowsPodTemplate},
+				},
+			},
+		},
+		{
+			name: "windows nodes without image and with fleet server: NOK",
+			a: &Agent{
+				Spec: AgentSpec{
+					Mode:               AgentFleetMode,
+					FleetServerEnabled: true,
+					Deployment:         &DeploymentSpec{PodTemplate: windowsPodTemplate},
+				},
+			},
+			wantErrors: 2,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkWindowsNodes(tt.a)
+			assert.Len(t, got, tt.wantErrors)
+		})
+	}
+}
+
 func Test_checkFleetServerOrFleetServerRef(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
@@ -32,6 +32,7 @@ var (
 		checkNameLength,
 		checkSupportedVersion,
 		checkAgentConfigurationMinVersion,
+		checkAssociations,
 	}
 
 	updateChecks = []func(old, curr *ApmServer) field.ErrorList{
@@ -132,3 +133,10 @@ func checkAgentConfigurationMinVersion(as *ApmServer) field.ErrorList {
 	}
 	return nil
 }
+
+func checkAssociations(as *ApmServer) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), as.Spec.ElasticsearchRef)...)
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("kibanaRef"), as.Spec.KibanaRef)...)
+	return errs
+}
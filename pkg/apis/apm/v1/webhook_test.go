@@ -124,6 +124,30 @@ func TestWebhook(t *testing.T) {
 			},
 			Check: test.ValidationWebhookSucceeded,
 		},
+		{
+			Name:      "external-kibanaRef",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				as := mkApmServer(uid)
+				as.Spec.KibanaRef = commonv1.ObjectSelector{SecretName: "external"}
+				return serialize(t, as)
+			},
+			Check: test.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "external-kibanaRef-with-name",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				as := mkApmServer(uid)
+				as.Spec.KibanaRef = commonv1.ObjectSelector{SecretName: "external", Name: "managed"}
+				return serialize(t, as)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.kibanaRef: Forbidden: specify secretName or name, namespace and serviceName, not both`,
+			),
+		},
 		{
 			Name:      "update-valid",
 			Operation: admissionv1beta1.Update,
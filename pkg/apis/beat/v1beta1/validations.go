@@ -7,6 +7,7 @@ package v1beta1
 import (
 	"regexp"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
@@ -23,6 +24,8 @@ var (
 		checkBeatType,
 		checkSingleConfigSource,
 		checkSpec,
+		checkWindowsNodes,
+		checkAssociations,
 	}
 
 	updateChecks = []func(old, curr *Beat) field.ErrorList{
@@ -104,3 +107,43 @@ func checkSpec(b *Beat) field.ErrorList {
 	}
 	return nil
 }
+
+// podTemplate returns the pod template of the Beat along with its path in the spec.
+func (b *Beat) podTemplate() (corev1.PodTemplateSpec, *field.Path) {
+	if b.Spec.Deployment != nil {
+		return b.Spec.Deployment.PodTemplate, field.NewPath("spec").Child("deployment", "podTemplate")
+	}
+	if b.Spec.DaemonSet != nil {
+		return b.Spec.DaemonSet.PodTemplate, field.NewPath("spec").Child("daemonSet", "podTemplate")
+	}
+	return corev1.PodTemplateSpec{}, nil
+}
+
+func checkWindowsNodes(b *Beat) field.ErrorList {
+	podTemplate, path := b.podTemplate()
+	if path == nil {
+		return nil
+	}
+	errs := commonv1.CheckPodTemplateOS(podTemplate, path)
+	if !commonv1.IsWindowsPodTemplate(podTemplate) {
+		return errs
+	}
+	if b.Spec.Image == "" {
+		errs = append(errs, field.Required(
+			field.NewPath("spec").Child("image"),
+			"Image is required when running on Windows nodes, default images only support Linux"))
+	}
+	if len(b.Spec.SecureSettings) > 0 {
+		errs = append(errs, field.Forbidden(
+			field.NewPath("spec").Child("secureSettings"),
+			"Secure settings are not supported on Windows nodes"))
+	}
+	return errs
+}
+
+func checkAssociations(b *Beat) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), b.Spec.ElasticsearchRef)...)
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("kibanaRef"), b.Spec.KibanaRef)...)
+	return errs
+}
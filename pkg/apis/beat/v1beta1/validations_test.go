@@ -9,6 +9,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 )
 
 func Test_checkBeatType(t *testing.T) {
@@ -98,3 +101,101 @@ func Test_checkSpec(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkAssociations(t *testing.T) {
+	tests := []struct {
+		name    string
+		beat    Beat
+		wantErr bool
+	}{
+		{
+			name: "managed references",
+			beat: Beat{Spec: BeatSpec{
+				ElasticsearchRef: commonv1.ObjectSelector{Name: "es"},
+				KibanaRef:        commonv1.ObjectSelector{Name: "kb"},
+			}},
+		},
+		{
+			name: "references to resources not managed by the operator",
+			beat: Beat{Spec: BeatSpec{
+				ElasticsearchRef: commonv1.ObjectSelector{SecretName: "es"},
+				KibanaRef:        commonv1.ObjectSelector{SecretName: "kb"},
+			}},
+		},
+		{
+			name: "secretName with name",
+			beat: Beat{Spec: BeatSpec{
+				KibanaRef: commonv1.ObjectSelector{SecretName: "kb", Name: "kb"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := checkAssociations(&tc.beat)
+			assert.Equal(t, tc.wantErr, len(got) > 0)
+		})
+	}
+}
+
+func Test_checkWindowsNodes(t *testing.T) {
+	windowsPodTemplate := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{corev1.LabelOSStable: commonv1.WindowsOS},
+		},
+	}
+	tests := []struct {
+		name       string
+		beat       Beat
+		wantErrors int
+	}{
+		{
+			name: "linux nodes",
+			beat: Beat{
+				Spec: BeatSpec{
+					SecureSettings: []commonv1.SecretSource{{SecretName: "foo"}},
+					DaemonSet:      &DaemonSetSpec{},
+				},
+			},
+		},
+		{
+			name: "windows nodes with a custom image",
+			beat: Beat{
+				Spec: BeatSpec{
+					Image:     "my-registry/filebeat-windows:7.15.0",
+					DaemonSet: &DaemonSetSpec{PodTemplate: windowsPodTemplate},
+				},
+			},
+		},
+		{
+			name: "windows nodes without image and with secure settings",
+			beat: Beat{
+				Spec: BeatSpec{
+					SecureSettings: []commonv1.SecretSource{{SecretName: "foo"}},
+					Deployment:     &DeploymentSpec{PodTemplate: windowsPodTemplate},
+				},
+			},
+			wantErrors: 2,
+		},
+		{
+			name: "other os left to the scheduler",
+			beat: Beat{
+				Spec: BeatSpec{
+					DaemonSet: &DaemonSetSpec{PodTemplate: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							NodeSelector: map[string]string{corev1.LabelOSStable: "darwin"},
+						},
+					}},
+				},
+			},
+			wantErrors: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := checkWindowsNodes(&tc.beat)
+			assert.Len(t, got, tc.wantErrors)
+		})
+	}
+}
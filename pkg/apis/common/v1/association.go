@@ -191,9 +191,11 @@ func FormatNameWithID(template string, id string) string {
 type AssociationConf struct {
 	AuthSecretName string `json:"authSecretName"`
 	AuthSecretKey  string `json:"authSecretKey"`
-	CACertProvided bool   `json:"caCertProvided"`
-	CASecretName   string `json:"caSecretName"`
-	URL            string `json:"url"`
+	// IsServiceAccount is true if the auth secret holds an Elasticsearch service account token instead of a password.
+	IsServiceAccount bool   `json:"isServiceAccount,omitempty"`
+	CACertProvided   bool   `json:"caCertProvided"`
+	CASecretName     string `json:"caSecretName"`
+	URL              string `json:"url"`
 	// Version of the referenced resource. If a version upgrade is in progress,
 	// matches the lowest running version. May be empty if unknown.
 	Version string `json:"version"`
@@ -255,6 +257,13 @@ func (ac *AssociationConf) GetAuthSecretKey() string {
 	return ac.AuthSecretKey
 }
 
+func (ac *AssociationConf) GetIsServiceAccount() bool {
+	if ac == nil {
+		return false
+	}
+	return ac.IsServiceAccount
+}
+
 func (ac *AssociationConf) GetCACertProvided() bool {
 	if ac == nil {
 		return false
@@ -51,13 +51,24 @@ type SecretRef struct {
 // ObjectSelector defines a reference to a Kubernetes object.
 type ObjectSelector struct {
 	// Name of the Kubernetes object.
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
 	// Namespace of the Kubernetes object. If empty, defaults to the current namespace.
 	Namespace string `json:"namespace,omitempty"`
 	// ServiceName is the name of an existing Kubernetes service which is used to make requests to the referenced
 	// object. It has to be in the same namespace as the referenced resource. If left empty, the default HTTP service of
 	// the referenced resource is used.
 	ServiceName string `json:"serviceName,omitempty"`
+	// SecretName is the name of an existing Kubernetes secret that contains connection information for associating an
+	// Elastic resource not managed by the operator. The referenced secret must live in the same namespace as the
+	// referencing resource and contain the following:
+	//
+	// - `url`: the URL to reach the Elastic resource
+	// - `username`: the username of the user to be authenticated to the Elastic resource
+	// - `password`: the password of the user to be authenticated to the Elastic resource
+	// - `ca.crt`: the CA certificate in PEM format (optional).
+	//
+	// This field cannot be used in combination with the other fields name, namespace or serviceName.
+	SecretName string `json:"secretName,omitempty"`
 }
 
 // WithDefaultNamespace adds a default namespace to a given ObjectSelector if none is set.
@@ -69,21 +80,38 @@ func (o ObjectSelector) WithDefaultNamespace(defaultNamespace string) ObjectSele
 		Namespace:   defaultNamespace,
 		Name:        o.Name,
 		ServiceName: o.ServiceName,
+		SecretName:  o.SecretName,
 	}
 }
 
 // NamespacedName is a convenience method to turn an ObjectSelector into a NamespacedName.
+// For references to resources not managed by the operator, it identifies the Secret describing the resource.
 func (o ObjectSelector) NamespacedName() types.NamespacedName {
 	return types.NamespacedName{
-		Name:      o.Name,
+		Name:      o.NameOrSecretName(),
 		Namespace: o.Namespace,
 	}
 }
 
-// IsDefined checks if the object selector is not nil and has a name.
+// NameOrSecretName returns the name of the referenced resource, or the name of the Secret describing the referenced
+// resource if it is not managed by the operator.
+func (o ObjectSelector) NameOrSecretName() string {
+	if o.IsExternal() {
+		return o.SecretName
+	}
+	return o.Name
+}
+
+// IsDefined checks if the object selector is not nil and has a name or a secret name.
 // Namespace is not mandatory as it may be inherited by the parent object.
 func (o *ObjectSelector) IsDefined() bool {
-	return o != nil && o.Name != ""
+	return o != nil && (o.Name != "" || o.SecretName != "")
+}
+
+// IsExternal returns true if the object selector references a resource not managed by the operator, through a Secret
+// describing how to connect to it.
+func (o ObjectSelector) IsExternal() bool {
+	return o.SecretName != ""
 }
 
 // HTTPConfig holds the HTTP layer configuration for resources.
@@ -192,6 +220,14 @@ type KeyToPath struct {
 	Path string `json:"path,omitempty"`
 }
 
+// SecretKeySelector selects a key of a Kubernetes Secret in the same namespace as the resource that references it.
+type SecretKeySelector struct {
+	// SecretName is the name of the secret.
+	SecretName string `json:"secretName"`
+	// Key is the key contained in the secret.
+	Key string `json:"key"`
+}
+
 // ConfigSource references configuration settings.
 type ConfigSource struct {
 	// SecretName references a Kubernetes Secret in the same namespace as the resource that will consume it.
@@ -7,6 +7,8 @@ package v1
 import (
 	"reflect"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestTLSOptions_Enabled(t *testing.T) {
@@ -141,6 +143,7 @@ func TestObjectSelector_WithDefaultNamespace(t *testing.T) {
 		Name        string
 		Namespace   string
 		ServiceName string
+		SecretName  string
 	}
 	type args struct {
 		defaultNamespace string
@@ -183,6 +186,19 @@ func TestObjectSelector_WithDefaultNamespace(t *testing.T) {
 				ServiceName: "c",
 			},
 		},
+		{
+			name: "default empty namespace, keep secretName",
+			fields: fields{
+				SecretName: "e",
+			},
+			args: args{
+				defaultNamespace: "d",
+			},
+			want: ObjectSelector{
+				Namespace:  "d",
+				SecretName: "e",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -190,6 +206,7 @@ func TestObjectSelector_WithDefaultNamespace(t *testing.T) {
 				Name:        tt.fields.Name,
 				Namespace:   tt.fields.Namespace,
 				ServiceName: tt.fields.ServiceName,
+				SecretName:  tt.fields.SecretName,
 			}
 			if got := o.WithDefaultNamespace(tt.args.defaultNamespace); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("WithDefaultNamespace() = %+v, want %+v", got, tt.want)
@@ -197,3 +214,44 @@ func TestObjectSelector_WithDefaultNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectSelector_NamespacedName(t *testing.T) {
+	tests := []struct {
+		name        string
+		o           ObjectSelector
+		want        types.NamespacedName
+		wantDefined bool
+	}{
+		{
+			name:        "empty selector",
+			o:           ObjectSelector{},
+			want:        types.NamespacedName{},
+			wantDefined: false,
+		},
+		{
+			name:        "reference to a managed resource",
+			o:           ObjectSelector{Name: "es", Namespace: "ns"},
+			want:        types.NamespacedName{Name: "es", Namespace: "ns"},
+			wantDefined: true,
+		},
+		{
+			name:        "reference to a resource not managed by the operator",
+			o:           ObjectSelector{SecretName: "external-es", Namespace: "ns"},
+			want:        types.NamespacedName{Name: "external-es", Namespace: "ns"},
+			wantDefined: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.NamespacedName(); got != tt.want {
+				t.Errorf("NamespacedName() = %v, want %v", got, tt.want)
+			}
+			if got := tt.o.IsDefined(); got != tt.wantDefined {
+				t.Errorf("IsDefined() = %v, want %v", got, tt.wantDefined)
+			}
+			if got := tt.o.IsExternal(); got != (tt.o.SecretName != "") {
+				t.Errorf("IsExternal() = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// WindowsOS is the value of the kubernetes.io/os node label of Windows nodes.
+	WindowsOS = "windows"
+
+	windowsLinuxOnlySettingMsg = "Linux-only setting, not supported on Windows nodes"
+)
+
+// IsWindowsPodTemplate returns true if the pod template selects Windows nodes through the kubernetes.io/os node
+// selector.
+func IsWindowsPodTemplate(podTemplate v1.PodTemplateSpec) bool {
+	return podTemplate.Spec.NodeSelector[v1.LabelOSStable] == WindowsOS
+}
+
+// CheckPodTemplateOS validates that pods scheduled on Windows nodes, through the kubernetes.io/os node selector, do
+// not rely on Linux-only security settings. Other node selectors are left to the scheduler.
+func CheckPodTemplateOS(podTemplate v1.PodTemplateSpec, path *field.Path) field.ErrorList {
+	if !IsWindowsPodTemplate(podTemplate) {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if sc := podTemplate.Spec.SecurityContext; sc != nil {
+		scPath := path.Child("spec", "securityContext")
+		errs = append(errs, forbidLinuxOnlySettings(scPath, []linuxOnlySetting{
+			{name: "runAsUser", set: sc.RunAsUser != nil},
+			{name: "runAsGroup", set: sc.RunAsGroup != nil},
+			{name: "fsGroup", set: sc.FSGroup != nil},
+			{name: "seLinuxOptions", set: sc.SELinuxOptions != nil},
+			{name: "supplementalGroups", set: len(sc.SupplementalGroups) > 0},
+			{name: "sysctls", set: len(sc.Sysctls) > 0},
+		})...)
+	}
+	for i, c := range podTemplate.Spec.InitContainers {
+		errs = append(errs, checkWindowsContainerSecurityContext(c.SecurityContext, path.Child("spec", "initContainers").Index(i).Child("securityContext"))...)
+	}
+	for i, c := range podTemplate.Spec.Containers {
+		errs = append(errs, checkWindowsContainerSecurityContext(c.SecurityContext, path.Child("spec", "containers").Index(i).Child("securityContext"))...)
+	}
+	return errs
+}
+
+func checkWindowsContainerSecurityContext(sc *v1.SecurityContext, path *field.Path) field.ErrorList {
+	if sc == nil {
+		return nil
+	}
+	return forbidLinuxOnlySettings(path, []linuxOnlySetting{
+		{name: "privileged", set: sc.Privileged != nil && *sc.Privileged},
+		{name: "runAsUser", set: sc.RunAsUser != nil},
+		{name: "runAsGroup", set: sc.RunAsGroup != nil},
+		{name: "seLinuxOptions", set: sc.SELinuxOptions != nil},
+		{name: "capabilities", set: sc.Capabilities != nil},
+		{name: "readOnlyRootFilesystem", set: sc.ReadOnlyRootFilesystem != nil && *sc.ReadOnlyRootFilesystem},
+		{name: "allowPrivilegeEscalation", set: sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation},
+	})
+}
+
+type linuxOnlySetting struct {
+	name string
+	set  bool
+}
+
+func forbidLinuxOnlySettings(path *field.Path, settings []linuxOnlySetting) field.ErrorList {
+	var errs field.ErrorList
+	for _, s := range settings {
+		if s.set {
+			errs = append(errs, field.Forbidden(path.Child(s.name), windowsLinuxOnlySettingMsg))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestCheckPodTemplateOS(t *testing.T) {
+	path := field.NewPath("spec", "podTemplate")
+	tests := []struct {
+		name        string
+		podTemplate corev1.PodTemplateSpec
+		wantFields  []string
+	}{
+		{
+			name: "no node selector",
+		},
+		{
+			name: "linux arm64 nodes with Linux-only settings",
+			podTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector:    map[string]string{corev1.LabelOSStable: "linux", corev1.LabelArchStable: "arm64"},
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: pointer.Int64Ptr(0)},
+			}},
+		},
+		{
+			name: "other os and arch are left to the scheduler",
+			podTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector:    map[string]string{corev1.LabelOSStable: "darwin", corev1.LabelArchStable: "s390x"},
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: pointer.Int64Ptr(0)},
+			}},
+		},
+		{
+			name: "windows amd64 nodes",
+			podTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{corev1.LabelOSStable: WindowsOS, corev1.LabelArchStable: "amd64"},
+				SecurityContext: &corev1.PodSecurityContext{
+					WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: pointer.StringPtr("ContainerAdministrator")},
+				},
+			}},
+		},
+		{
+			name: "windows nodes with Linux-only settings",
+			podTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{corev1.LabelOSStable: WindowsOS, corev1.LabelArchStable: "arm64"},
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsUser: pointer.Int64Ptr(0),
+					FSGroup:   pointer.Int64Ptr(1000),
+				},
+				InitContainers: []corev1.Container{{
+					SecurityContext: &corev1.SecurityContext{Privileged: pointer.BoolPtr(false)},
+				}},
+				Containers: []corev1.Container{{
+					SecurityContext: &corev1.SecurityContext{
+						Privileged:   pointer.BoolPtr(true),
+						Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+					},
+				}},
+			}},
+			wantFields: []string{
+				"spec.podTemplate.spec.securityContext.runAsUser",
+				"spec.podTemplate.spec.securityContext.fsGroup",
+				"spec.podTemplate.spec.containers[0].securityContext.privileged",
+				"spec.podTemplate.spec.containers[0].securityContext.capabilities",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := CheckPodTemplateOS(tt.podTemplate, path)
+			gotFields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				gotFields = append(gotFields, err.Field)
+			}
+			if len(tt.wantFields) == 0 {
+				require.Empty(t, gotFields)
+				return
+			}
+			require.Equal(t, tt.wantFields, gotFields)
+		})
+	}
+}
@@ -76,6 +76,38 @@ func CheckSupportedStackVersion(ver string, supported version.MinMaxVersion) fie
 	return nil
 }
 
+// CheckAssociationRefs checks that the given references either identify a resource managed by the operator by its name,
+// or a resource not managed by the operator through a Secret, but not both.
+func CheckAssociationRefs(path *field.Path, refs ...ObjectSelector) field.ErrorList {
+	var errs field.ErrorList
+	for i, ref := range refs {
+		if ref.SecretName != "" && (ref.Name != "" || ref.Namespace != "" || ref.ServiceName != "") {
+			refPath := path
+			if len(refs) > 1 {
+				refPath = path.Index(i)
+			}
+			errs = append(errs, field.Forbidden(refPath, "specify secretName or name, namespace and serviceName, not both"))
+		}
+	}
+	return errs
+}
+
+// CheckManagedRefs checks that the given references identify a resource managed by the operator by its name, for
+// references which do not support resources not managed by the operator.
+func CheckManagedRefs(path *field.Path, refs ...ObjectSelector) field.ErrorList {
+	var errs field.ErrorList
+	for i, ref := range refs {
+		if ref.IsExternal() {
+			refPath := path
+			if len(refs) > 1 {
+				refPath = path.Index(i)
+			}
+			errs = append(errs, field.Forbidden(refPath.Child("secretName"), "references to resources not managed by the operator are not supported"))
+		}
+	}
+	return errs
+}
+
 // CheckNoDowngrade checks current and previous versions to ensure no downgrades are happening.
 func CheckNoDowngrade(prev, curr string) field.ErrorList {
 	prevVer, err := ParseVersion(prev)
@@ -19,6 +19,7 @@ type NodeRole string
 const (
 	DataColdRole            NodeRole = "data_cold"
 	DataContentRole         NodeRole = "data_content"
+	DataFrozenRole          NodeRole = "data_frozen"
 	DataHotRole             NodeRole = "data_hot"
 	DataRole                NodeRole = "data"
 	DataWarmRole            NodeRole = "data_warm"
@@ -72,7 +73,7 @@ func (n *Node) HasRole(role NodeRole) bool {
 	switch role {
 	case DataRole:
 		return pointer.BoolPtrDerefOr(n.Data, true)
-	case DataColdRole, DataContentRole, DataHotRole, DataWarmRole:
+	case DataColdRole, DataContentRole, DataFrozenRole, DataHotRole, DataWarmRole:
 		// These roles should really be defined in node.roles. Since they were not, assume they are enabled unless node.data is set to false.
 		return pointer.BoolPtrDerefOr(n.Data, true)
 	case IngestRole:
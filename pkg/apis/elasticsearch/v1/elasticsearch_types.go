@@ -16,6 +16,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 )
 
 const (
@@ -25,6 +26,29 @@ const (
 	// SuspendAnnotation allows users to annotate the Elasticsearch resource with the names of Pods they want to suspend
 	// for debugging purposes.
 	SuspendAnnotation = "eck.k8s.elastic.co/suspend"
+	// RestartAnnotation can be set on the Elasticsearch resource to trigger a rolling restart of all its Pods without
+	// changing its spec. Each new value, for example the current date, restarts the Pods following the usual upgrade
+	// orchestration.
+	RestartAnnotation = "eck.k8s.elastic.co/restart"
+	// NodeSetRestartAnnotationPrefix, followed by the name of a NodeSet, can be set on the Elasticsearch resource to
+	// trigger a rolling restart of the Pods of that NodeSet only, in the same way as RestartAnnotation.
+	NodeSetRestartAnnotationPrefix = "eck.k8s.elastic.co/restart."
+	// MaintenanceAnnotation can be set to "true" on the Elasticsearch resource to freeze the cluster: the operator keeps
+	// observing it and updating its status, but stops applying changes to its resources, certificates, users and
+	// settings, including the ones requested by other resources such as snapshot repositories or stack config policies.
+	MaintenanceAnnotation = "eck.k8s.elastic.co/maintenance"
+	// JVMHeapFromMemoryLimitsAnnotation can be set to "true" on the Elasticsearch resource to have the JVM heap size of
+	// each node derived from the memory limit of its container, and to forbid heap sizes above half of that limit.
+	JVMHeapFromMemoryLimitsAnnotation = "eck.k8s.elastic.co/jvm-heap-from-memory-limits"
+	// SkipPreUpgradeChecksAnnotation can be set to "true" on the Elasticsearch resource to start a major version upgrade
+	// even if the deprecation info API reports critical issues.
+	SkipPreUpgradeChecksAnnotation = "eck.k8s.elastic.co/skip-pre-upgrade-checks"
+	// RotateCredentialsAnnotation can be set on the Elasticsearch resource to regenerate the passwords of the elastic
+	// user and of the internal users of the operator. Each new value, for example the current date, triggers a rotation.
+	RotateCredentialsAnnotation = "eck.k8s.elastic.co/rotate-credentials"
+	// CredentialsRotationIntervalAnnotation can be set on the Elasticsearch resource to a duration, for example "720h",
+	// after which the passwords of the elastic user and of the internal users of the operator are regenerated.
+	CredentialsRotationIntervalAnnotation = "eck.k8s.elastic.co/credentials-rotation-interval"
 	// Kind is inferred from the struct name using reflection in SchemeBuilder.Register()
 	// we duplicate it as a constant here for practical purposes.
 	Kind = "Elasticsearch"
@@ -59,6 +83,12 @@ type ElasticsearchSpec struct {
 	// +kubebuilder:validation:Optional
 	Transport TransportConfig `json:"transport,omitempty"`
 
+	// CertManager configures cert-manager to issue the HTTP and transport certificates of the cluster, instead of the
+	// CAs of the operator. The operator requests them with cert-manager Certificate resources, and uses the Secrets
+	// cert-manager stores them in. It cannot be combined with user-provided HTTP or transport certificates.
+	// +kubebuilder:validation:Optional
+	CertManager *CertManagerConfig `json:"certManager,omitempty"`
+
 	// NodeSets allow specifying groups of Elasticsearch nodes sharing the same configuration and Pod templates.
 	// +kubebuilder:validation:MinItems=1
 	NodeSets []NodeSet `json:"nodeSets"`
@@ -67,12 +97,44 @@ type ElasticsearchSpec struct {
 	// +kubebuilder:validation:Optional
 	UpdateStrategy UpdateStrategy `json:"updateStrategy,omitempty"`
 
+	// DownscalePolicy specifies the order in which nodes are removed when several NodeSets are scaled down.
+	// +kubebuilder:validation:Optional
+	DownscalePolicy DownscalePolicy `json:"downscalePolicy,omitempty"`
+
+	// InitTasks are run, in order, by dedicated init containers of all Elasticsearch Pods before Elasticsearch starts.
+	// Pods are only restarted when the tasks change.
+	// +kubebuilder:validation:Optional
+	InitTasks []InitTask `json:"initTasks,omitempty"`
+
+	// ZoneAwareness enables zone-aware shard allocation. Each node gets a zone attribute set from the zone label of the
+	// Kubernetes node it runs on, and Elasticsearch is configured to spread the copies of the shards across zones.
+	// +kubebuilder:validation:Optional
+	ZoneAwareness *ZoneAwareness `json:"zoneAwareness,omitempty"`
+
+	// DataTiers assigns NodeSets to data tiers, whose roles replace the data roles of their nodes.
+	// An index lifecycle management policy moving indices through the tiers can also be published.
+	// +kubebuilder:validation:Optional
+	DataTiers *DataTiers `json:"dataTiers,omitempty"`
+
+	// InitialSnapshotRestore restores a snapshot into the cluster when it is first bootstrapped, before it is reported
+	// Ready, for example to clone an existing cluster. It is ignored once the cluster has been bootstrapped.
+	// +kubebuilder:validation:Optional
+	InitialSnapshotRestore *InitialSnapshotRestore `json:"initialSnapshotRestore,omitempty"`
+
 	// PodDisruptionBudget provides access to the default pod disruption budget for the Elasticsearch cluster.
 	// The default budget selects all cluster pods and sets `maxUnavailable` to 1. To disable, set `PodDisruptionBudget`
 	// to the empty value (`{}` in YAML).
 	// +kubebuilder:validation:Optional
 	PodDisruptionBudget *commonv1.PodDisruptionBudgetTemplate `json:"podDisruptionBudget,omitempty"`
 
+	// PodDisruptionBudgetPerTier replaces the default pod disruption budget with one budget per tier, so that draining
+	// Kubernetes nodes does not disrupt several nodes of the same tier at once. A tier is made of the master nodes, or
+	// of the nodes holding shards of the same data tier, generic data nodes holding shards of all the data tiers. Other
+	// nodes are grouped by roles. The budgets inherit the metadata of the PodDisruptionBudget template, which must not
+	// define its own spec.
+	// +kubebuilder:validation:Optional
+	PodDisruptionBudgetPerTier *PodDisruptionBudgetPerTier `json:"podDisruptionBudgetPerTier,omitempty"`
+
 	// Auth contains user authentication and authorization security settings for Elasticsearch.
 	// +kubebuilder:validation:Optional
 	Auth Auth `json:"auth,omitempty"`
@@ -90,6 +152,11 @@ type ElasticsearchSpec struct {
 	// +optional
 	RemoteClusters []RemoteCluster `json:"remoteClusters,omitempty"`
 
+	// RemoteClusterServer enables the remote cluster server of the nodes, to accept the connections of the clusters
+	// using this cluster as a remote cluster with a cross-cluster API key. Requires Elasticsearch 8.10+.
+	// +kubebuilder:validation:Optional
+	RemoteClusterServer RemoteClusterServer `json:"remoteClusterServer,omitempty"`
+
 	// VolumeClaimDeletePolicy sets the policy for handling deletion of PersistentVolumeClaims for all NodeSets.
 	// Possible values are DeleteOnScaledownOnly and DeleteOnScaledownAndClusterDeletion. Defaults to DeleteOnScaledownAndClusterDeletion.
 	// +kubebuilder:validation:Optional
@@ -139,6 +206,24 @@ const (
 	DeleteOnScaledownOnlyPolicy VolumeClaimDeletePolicy = "DeleteOnScaledownOnly"
 )
 
+// CertManagerConfig configures the issuance of the certificates of a cluster by cert-manager.
+type CertManagerConfig struct {
+	// IssuerRef references the cert-manager issuer of the certificates.
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+}
+
+// CertManagerIssuerRef references a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerRef struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+	// Kind of the issuer, Issuer by default for an issuer in the namespace of the cluster, or ClusterIssuer.
+	// +kubebuilder:validation:Optional
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer, cert-manager.io by default. Set it to use an external issuer.
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+}
+
 // TransportConfig holds the transport layer settings for Elasticsearch.
 type TransportConfig struct {
 	// Service defines the template for the associated Kubernetes Service object.
@@ -157,12 +242,24 @@ type TransportTLSOptions struct {
 	// - `ca.crt`: The CA certificate in PEM format.
 	// - `ca.key`: The private key for the CA certificate in PEM format.
 	Certificate commonv1.SecretRef `json:"certificate,omitempty"`
+	// NodeCertificate is a reference to a Kubernetes secret that contains a certificate issued outside of the operator,
+	// for example by Vault or cert-manager, used by all the nodes instead of the certificates issued by the operator.
+	// It cannot be combined with Certificate. The referenced secret should contain the following:
+	//
+	// - `tls.crt`: The certificate in PEM format. It is used by all the nodes, for both server and client authentication.
+	// - `tls.key`: The private key for the certificate in PEM format.
+	// - `ca.crt`: The certificates of the CAs to trust in PEM format, including the CA which issued the certificate.
+	NodeCertificate commonv1.SecretRef `json:"nodeCertificate,omitempty"`
 }
 
 func (tto TransportTLSOptions) UserDefinedCA() bool {
 	return tto.Certificate.SecretName != ""
 }
 
+func (tto TransportTLSOptions) UserDefinedNodeCertificate() bool {
+	return tto.NodeCertificate.SecretName != ""
+}
+
 // RemoteCluster declares a remote Elasticsearch cluster connection.
 type RemoteCluster struct {
 	// Name is the name of the remote cluster as it is set in the Elasticsearch settings.
@@ -174,10 +271,92 @@ type RemoteCluster struct {
 	// ElasticsearchRef is a reference to an Elasticsearch cluster running within the same k8s cluster.
 	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// FollowerIndices are replicated from indices of the remote cluster with cross-cluster replication. They are
+	// created if they do not exist, but are not unfollowed once removed from the list.
+	// +kubebuilder:validation:Optional
+	FollowerIndices []FollowerIndex `json:"followerIndices,omitempty"`
+
+	// AutoFollowPatterns create follower indices for the new indices of the remote cluster matching them, with
+	// cross-cluster replication. Auto-follow patterns removed from the list are deleted.
+	// +kubebuilder:validation:Optional
+	AutoFollowPatterns []AutoFollowPattern `json:"autoFollowPatterns,omitempty"`
+
+	// APIKey connects to the remote cluster server of the remote cluster with a cross-cluster API key granting the
+	// given access, instead of relying on the trust between the transport certificates only. The API key is created
+	// in the remote cluster, stored in the keystore and rotated by the operator. Requires Elasticsearch 8.10+.
+	// +kubebuilder:validation:Optional
+	APIKey *RemoteClusterAPIKey `json:"apiKey,omitempty"`
+
 	// TODO: Allow the user to specify some options (transport.compress, transport.ping_schedule)
 
 }
 
+// FollowerIndex is an index replicated from an index of a remote cluster.
+type FollowerIndex struct {
+	// Name of the follower index.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// LeaderIndex is the name of the index of the remote cluster to replicate. Defaults to the name of the follower index.
+	// +kubebuilder:validation:Optional
+	LeaderIndex string `json:"leaderIndex,omitempty"`
+}
+
+// LeaderIndexOrDefault returns the name of the index of the remote cluster to replicate.
+func (f FollowerIndex) LeaderIndexOrDefault() string {
+	if f.LeaderIndex == "" {
+		return f.Name
+	}
+	return f.LeaderIndex
+}
+
+// AutoFollowPattern creates follower indices for the new indices of a remote cluster matching patterns.
+type AutoFollowPattern struct {
+	// Name of the auto-follow pattern, unique across all the remote clusters.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// LeaderIndexPatterns are the patterns matching the names of the indices of the remote cluster to replicate.
+	// +kubebuilder:validation:MinItems=1
+	LeaderIndexPatterns []string `json:"leaderIndexPatterns"`
+
+	// FollowIndexPattern is the name of the follower indices, in which the leader_index placeholder is replaced by
+	// the name of the replicated index. Defaults to the name of the replicated index.
+	// +kubebuilder:validation:Optional
+	FollowIndexPattern string `json:"followIndexPattern,omitempty"`
+}
+
+// RemoteClusterAPIKey describes the cross-cluster API key used to connect to a remote cluster.
+type RemoteClusterAPIKey struct {
+	// Access is the access to the indices of the remote cluster granted by the API key.
+	Access RemoteClusterAccess `json:"access"`
+}
+
+// RemoteClusterAccess is the access to the indices of a remote cluster granted by a cross-cluster API key.
+type RemoteClusterAccess struct {
+	// Search grants access to the indices for cross-cluster search.
+	// +kubebuilder:validation:Optional
+	Search *RemoteClusterIndices `json:"search,omitempty"`
+
+	// Replication grants access to the indices for cross-cluster replication.
+	// +kubebuilder:validation:Optional
+	Replication *RemoteClusterIndices `json:"replication,omitempty"`
+}
+
+// RemoteClusterIndices selects indices of a remote cluster.
+type RemoteClusterIndices struct {
+	// Names of the indices, which may include wildcards.
+	// +kubebuilder:validation:MinItems=1
+	Names []string `json:"names"`
+}
+
+// RemoteClusterServer configures the remote cluster server of the Elasticsearch nodes.
+type RemoteClusterServer struct {
+	// Enabled starts the remote cluster server on port 9443, secured with the transport certificates.
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
 func (r RemoteCluster) ConfigHash() string {
 	return hash.HashObject(r)
 }
@@ -204,6 +383,80 @@ type Auth struct {
 	Roles []RoleSource `json:"roles,omitempty"`
 	// FileRealm to propagate to the Elasticsearch cluster.
 	FileRealm []FileRealmSource `json:"fileRealm,omitempty"`
+	// Realms configures OpenID Connect and SAML realms in the Elasticsearch cluster, after the file and native realms
+	// of the operator. The files they reference are mounted in the Pods, and their secure settings added to the keystore.
+	// +kubebuilder:validation:Optional
+	Realms []Realm `json:"realms,omitempty"`
+}
+
+// RealmType is the type of a realm configured in the Auth spec.
+type RealmType string
+
+const (
+	// OIDCRealmType is the type of OpenID Connect realms.
+	OIDCRealmType RealmType = "oidc"
+	// SAMLRealmType is the type of SAML realms.
+	SAMLRealmType RealmType = "saml"
+)
+
+// Realm configures an OpenID Connect or SAML realm.
+type Realm struct {
+	// Type of the realm, either oidc or saml.
+	// +kubebuilder:validation:Enum=oidc;saml
+	Type RealmType `json:"type"`
+
+	// Name of the realm. It must be unique, and a valid DNS label of at most 27 characters.
+	Name string `json:"name"`
+
+	// Order of the realm in the realm chain. It must be unique among all the realms of the cluster, and greater than
+	// the order of the native realm of the operator (-99).
+	Order int `json:"order"`
+
+	// Config holds the settings of the realm, relative to xpack.security.authc.realms.<type>.<name>.
+	// The settings derived from the files and secrets referenced below are set by the operator.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Config *commonv1.Config `json:"config,omitempty"`
+
+	// IdPMetadata references a Secret key holding the metadata of the SAML identity provider. It is mounted in the
+	// Pods and set as the idp.metadata.path setting of the realm.
+	// +kubebuilder:validation:Optional
+	IdPMetadata *commonv1.SecretKeySelector `json:"idpMetadata,omitempty"`
+
+	// SigningSecretName references a Secret holding the certificate and private key (tls.crt and tls.key) used by the
+	// SAML service provider to sign its messages. They are mounted in the Pods and set as the signing.certificate and
+	// signing.key settings of the realm.
+	// +kubebuilder:validation:Optional
+	SigningSecretName string `json:"signingSecretName,omitempty"`
+
+	// ClientSecret references a Secret key holding the client secret of the OpenID Connect relying party. It is added
+	// to the keystore as the rp.client_secret secure setting of the realm.
+	// +kubebuilder:validation:Optional
+	ClientSecret *commonv1.SecretKeySelector `json:"clientSecret,omitempty"`
+
+	// JWKSet references a Secret key holding the JSON Web Key Set of the OpenID Connect provider. It is mounted in the
+	// Pods and set as the op.jwkset_path setting of the realm.
+	// +kubebuilder:validation:Optional
+	JWKSet *commonv1.SecretKeySelector `json:"jwkSet,omitempty"`
+}
+
+// realmSecureSettings returns the secret sources projecting the secure settings of the realms to their keystore entries.
+func (a Auth) realmSecureSettings() []commonv1.SecretSource {
+	var sources []commonv1.SecretSource
+	for _, realm := range a.Realms {
+		if realm.Type == OIDCRealmType && realm.ClientSecret != nil {
+			sources = append(sources, commonv1.SecretSource{
+				SecretName: realm.ClientSecret.SecretName,
+				Entries:    []commonv1.KeyToPath{{Key: realm.ClientSecret.Key, Path: realm.SettingsPrefix() + ".rp.client_secret"}},
+			})
+		}
+	}
+	return sources
+}
+
+// SettingsPrefix returns the prefix of the settings of the realm.
+func (r Realm) SettingsPrefix() string {
+	return fmt.Sprintf("xpack.security.authc.realms.%s.%s", r.Type, r.Name)
 }
 
 // RoleSource references roles to create in the Elasticsearch cluster.
@@ -292,6 +545,53 @@ type NodeSet struct {
 	// Items defined here take precedence over any default claims added by the operator with the same name.
 	// +kubebuilder:validation:Optional
 	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
+	// Plugins is a list of Elasticsearch plugins, by name or URL, installed by an init task of the Pods of this NodeSet,
+	// which runs before the init tasks of the cluster. The Pods are only restarted when the list changes.
+	// +kubebuilder:validation:Optional
+	Plugins []string `json:"plugins,omitempty"`
+
+	// ReadinessProbe customizes the readiness probe of the Elasticsearch containers of this NodeSet, for example when
+	// the local node is reached through a custom network setup. It cannot be combined with a readiness probe set in the
+	// PodTemplate.
+	// +kubebuilder:validation:Optional
+	ReadinessProbe *ReadinessProbe `json:"readinessProbe,omitempty"`
+}
+
+// ReadinessProbe customizes the readiness probe of the Elasticsearch containers. The probe must request the local
+// Elasticsearch node: Pods must not be reported ready based on the health of other nodes.
+type ReadinessProbe struct {
+	// Port of the local node requested by the probe. Defaults to 9200.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Path requested by the probe. It must only depend on the local node, for example / or /_nodes/_local, or include
+	// the local=true query parameter. Defaults to /.
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// TimeoutSeconds after which the probe times out. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// PeriodSeconds is how often the probe is performed. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures after which the Pod is reported not ready. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// Script references a key of a ConfigMap, in the same namespace, holding a script run by the probe instead of the
+	// default one. The script gets the port and path to request in the READINESS_PROBE_PORT and READINESS_PROBE_PATH
+	// environment variables, and must request the local node.
+	// +kubebuilder:validation:Optional
+	Script *corev1.ConfigMapKeySelector `json:"script,omitempty"`
 }
 
 // +kubebuilder:object:generate=false
@@ -332,6 +632,195 @@ type ChangeBudget struct {
 	// the specification. MaxSurge is only taken into consideration when scaling up. Setting a negative value will
 	// disable the restriction. Defaults to unbounded if not specified.
 	MaxSurge *int32 `json:"maxSurge,omitempty"`
+
+	// MaxUnavailablePerTier is the maximum number of pods of a single tier, made of the nodes with the same roles, that
+	// can be restarted in parallel during a rolling upgrade. When set, the nodes of a single tier are restarted at a time,
+	// and MaxUnavailable remains the overall limit: raise both to restart many nodes of large tiers in parallel.
+	// Nodes holding copies of the same shards are never restarted in parallel. Defaults to no per-tier restriction.
+	// +kubebuilder:validation:Minimum=1
+	MaxUnavailablePerTier *int32 `json:"maxUnavailablePerTier,omitempty"`
+}
+
+// PodDisruptionBudgetPerTier specifies the pod disruption budgets of the tiers of the cluster.
+type PodDisruptionBudgetPerTier struct {
+	// MaxUnavailable is the maximum number of Pods of a single tier that can be disrupted at the same time, as long as
+	// the cluster has a green health. Tiers holding the single master, data or ingest node of the cluster allow no
+	// disruption, and tiers of master nodes allow at most one. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// GetMaxUnavailableOrDefault returns the maximum number of Pods of a single tier that can be disrupted, or 1 if not set.
+func (p PodDisruptionBudgetPerTier) GetMaxUnavailableOrDefault() int32 {
+	if p.MaxUnavailable == nil {
+		return 1
+	}
+	return *p.MaxUnavailable
+}
+
+// DownscalePolicy specifies the order in which nodes are removed when several NodeSets are scaled down.
+type DownscalePolicy struct {
+	// TierOrder lists node roles, for example data_cold, data_warm and data_hot, in the order in which the nodes having
+	// them are removed. The nodes of a NodeSet are only removed once no node with a role listed earlier remains to be
+	// removed. Nodes with none of the listed roles are removed last. Defaults to removing nodes of all NodeSets at once.
+	// +kubebuilder:validation:Optional
+	TierOrder []NodeRole `json:"tierOrder,omitempty"`
+
+	// MaxRemovalsPerTier is the maximum number of nodes of a single tier, made of the nodes with the same roles, that
+	// can be removed at the same time. Defaults to no per-tier restriction.
+	// +kubebuilder:validation:Minimum=1
+	MaxRemovalsPerTier *int32 `json:"maxRemovalsPerTier,omitempty"`
+}
+
+// InitTask is a task run by a dedicated init container of the Elasticsearch Pods, with the Elasticsearch image, to
+// prepare the Elasticsearch installation. Exactly one of the task types must be specified.
+type InitTask struct {
+	// Name of the task, unique among the tasks. The init container running the task is named after it, which allows
+	// to customize the container, for example its resources, through the Pod template of the NodeSets.
+	Name string `json:"name"`
+
+	// InstallPlugins is a list of Elasticsearch plugins to install, by name or URL.
+	// +kubebuilder:validation:Optional
+	InstallPlugins []string `json:"installPlugins,omitempty"`
+
+	// DownloadFiles is a list of files to download into the Elasticsearch configuration directory, for example
+	// synonyms files or custom dictionaries.
+	// +kubebuilder:validation:Optional
+	DownloadFiles []FileDownload `json:"downloadFiles,omitempty"`
+}
+
+// NodeSetPluginsInitTaskName is the name of the init task installing the plugins declared in a NodeSet. It cannot be
+// used by the init tasks of the cluster.
+const NodeSetPluginsInitTaskName = "nodeset-plugins"
+
+// FileDownload is a file downloaded into the Elasticsearch configuration directory.
+type FileDownload struct {
+	// URL of the file.
+	URL string `json:"url"`
+	// Path of the file, relative to the Elasticsearch configuration directory.
+	Path string `json:"path"`
+}
+
+// DefaultZoneAwarenessTopologyKey is the well-known label of the Kubernetes nodes holding their zone.
+const DefaultZoneAwarenessTopologyKey = "topology.kubernetes.io/zone"
+
+// ZoneAwareness configures zone-aware shard allocation.
+type ZoneAwareness struct {
+	// TopologyKey is the label of the Kubernetes nodes holding their zone. Defaults to topology.kubernetes.io/zone.
+	// The label must be part of the node labels exposed by the operator.
+	// +kubebuilder:validation:Optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// TopologyKeyOrDefault returns the label of the Kubernetes nodes holding their zone.
+func (z ZoneAwareness) TopologyKeyOrDefault() string {
+	if z.TopologyKey == "" {
+		return DefaultZoneAwarenessTopologyKey
+	}
+	return z.TopologyKey
+}
+
+// DataTiers assigns NodeSets to data tiers.
+type DataTiers struct {
+	// Hot holds the most recent and most frequently queried data. Its nodes also get the data_content role.
+	// +kubebuilder:validation:Optional
+	Hot *DataTier `json:"hot,omitempty"`
+
+	// Warm holds data which is queried less frequently and no longer updated.
+	// +kubebuilder:validation:Optional
+	Warm *DataTier `json:"warm,omitempty"`
+
+	// Cold holds data which is rarely queried.
+	// +kubebuilder:validation:Optional
+	Cold *DataTier `json:"cold,omitempty"`
+
+	// Frozen holds data which is almost never queried, as searchable snapshots. Available as of Elasticsearch 7.12.0.
+	// +kubebuilder:validation:Optional
+	Frozen *DataTier `json:"frozen,omitempty"`
+
+	// ILMPolicy is an index lifecycle management policy moving indices through the configured tiers, published in
+	// Elasticsearch if set.
+	// +kubebuilder:validation:Optional
+	ILMPolicy *DataTiersILMPolicy `json:"ilmPolicy,omitempty"`
+}
+
+// DataTier assigns NodeSets to a data tier.
+type DataTier struct {
+	// NodeSets are the names of the NodeSets whose nodes hold the data of the tier.
+	NodeSets []string `json:"nodeSets"`
+
+	// MinAge is the age at which indices enter the tier in the ILM policy, for example 30d. Ignored for the hot tier.
+	// +kubebuilder:validation:Optional
+	MinAge string `json:"minAge,omitempty"`
+}
+
+// DataTiersILMPolicy specifies the index lifecycle management policy published for the data tiers.
+type DataTiersILMPolicy struct {
+	// Name of the ILM policy.
+	Name string `json:"name"`
+
+	// SnapshotRepository is the repository the searchable snapshots of the frozen tier are stored in.
+	// Required if the frozen tier is configured.
+	// +kubebuilder:validation:Optional
+	SnapshotRepository string `json:"snapshotRepository,omitempty"`
+
+	// DeleteAfter is the age at which indices are deleted. Indices are never deleted if empty.
+	// +kubebuilder:validation:Optional
+	DeleteAfter string `json:"deleteAfter,omitempty"`
+}
+
+// DataTierRoles returns the data tier roles of the given NodeSet, nil if it does not belong to any tier.
+func (dt *DataTiers) DataTierRoles(nodeSet string) []NodeRole {
+	if dt == nil {
+		return nil
+	}
+	var roles []NodeRole
+	for _, tier := range []struct {
+		tier  *DataTier
+		roles []NodeRole
+	}{
+		{tier: dt.Hot, roles: []NodeRole{DataHotRole, DataContentRole}},
+		{tier: dt.Warm, roles: []NodeRole{DataWarmRole}},
+		{tier: dt.Cold, roles: []NodeRole{DataColdRole}},
+		{tier: dt.Frozen, roles: []NodeRole{DataFrozenRole}},
+	} {
+		if tier.tier != nil && stringsutil.StringInSlice(nodeSet, tier.tier.NodeSets) {
+			roles = append(roles, tier.roles...)
+		}
+	}
+	return roles
+}
+
+// InitialSnapshotRestore specifies a snapshot restored into a new cluster.
+type InitialSnapshotRestore struct {
+	// Repository is the snapshot repository the snapshot is stored in. It is registered in the cluster before the
+	// snapshot is restored. Repository credentials must be provided through the secure settings.
+	Repository SnapshotRestoreRepository `json:"repository"`
+
+	// Snapshot is the name of the snapshot to restore.
+	Snapshot string `json:"snapshot"`
+
+	// Indices is a list of indices or data streams to restore, wildcards are supported. Defaults to all of them.
+	// +kubebuilder:validation:Optional
+	Indices []string `json:"indices,omitempty"`
+
+	// IncludeGlobalState restores the cluster state of the snapshot, including templates and persistent settings.
+	// +kubebuilder:validation:Optional
+	IncludeGlobalState bool `json:"includeGlobalState,omitempty"`
+}
+
+// SnapshotRestoreRepository is a snapshot repository registered to restore a snapshot.
+type SnapshotRestoreRepository struct {
+	// Name of the repository in Elasticsearch.
+	Name string `json:"name"`
+
+	// Type of the repository, for example s3, gcs, azure or fs.
+	Type string `json:"type"`
+
+	// Settings of the repository, as accepted by the Elasticsearch snapshot repository API.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Settings *commonv1.Config `json:"settings,omitempty"`
 }
 
 // DefaultChangeBudget is used when no change budget is provided. It might not be the most effective, but should work in
@@ -410,6 +899,8 @@ const (
 	ElasticsearchMigratingDataPhase ElasticsearchOrchestrationPhase = "MigratingData"
 	// ElasticsearchNodeShutdownStalledPhase Elasticsearch cannot make progress with a node shutdown during downscale or rolling upgrade.
 	ElasticsearchNodeShutdownStalledPhase ElasticsearchOrchestrationPhase = "Stalled"
+	// ElasticsearchRestoringSnapshotPhase Elasticsearch is restoring the initial snapshot of a new cluster.
+	ElasticsearchRestoringSnapshotPhase ElasticsearchOrchestrationPhase = "RestoringSnapshot"
 	// ElasticsearchResourceInvalid is marking a resource as invalid, should never happen if admission control is installed correctly.
 	ElasticsearchResourceInvalid ElasticsearchOrchestrationPhase = "Invalid"
 )
@@ -423,10 +914,110 @@ type ElasticsearchStatus struct {
 	Version string                          `json:"version,omitempty"`
 	Health  ElasticsearchHealth             `json:"health,omitempty"`
 	Phase   ElasticsearchOrchestrationPhase `json:"phase,omitempty"`
+	// PhaseTransitionTime is the time at which the cluster entered its current phase.
+	PhaseTransitionTime *metav1.Time `json:"phaseTransitionTime,omitempty"`
 
 	MonitoringAssociationsStatus commonv1.AssociationStatusMap `json:"monitoringAssociationStatus,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed for this Elasticsearch cluster.
+	// It corresponds to the metadata generation, which is updated on mutation by the API Server.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the latest observations of the state of the Elasticsearch cluster.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// ElasticsearchReachableCondition is the type of the condition reporting whether the operator can reach the
+	// Elasticsearch API. It is false while requests to the cluster fail fast after consecutive failures.
+	ElasticsearchReachableCondition = "ElasticsearchReachable"
+	// CircuitBreakerClosedReason is the reason of the ElasticsearchReachableCondition when requests are performed.
+	CircuitBreakerClosedReason = "CircuitBreakerClosed"
+	// CircuitBreakerOpenReason is the reason of the ElasticsearchReachableCondition when requests fail fast.
+	CircuitBreakerOpenReason = "CircuitBreakerOpen"
+	// MaintenanceModeCondition is the type of the condition set while the cluster is in maintenance mode.
+	MaintenanceModeCondition = "MaintenanceMode"
+	// MaintenanceAnnotationReason is the reason of the MaintenanceModeCondition.
+	MaintenanceAnnotationReason = "MaintenanceAnnotation"
+	// NodeShutdownStalledCondition is the type of the condition set while a node shutdown, requested through the
+	// Elasticsearch node shutdown API to remove or restart a node, cannot make progress.
+	NodeShutdownStalledCondition = "NodeShutdownStalled"
+	// ShardMigrationStalledReason is the reason of the NodeShutdownStalledCondition. The condition message holds the
+	// explanation returned by Elasticsearch, for example unassigned shards or indices waiting for an ILM step.
+	ShardMigrationStalledReason = "ShardMigrationStalled"
+	// ReconciliationCompleteCondition is the type of the condition reporting whether the last reconciliation applied
+	// the whole specification of the cluster without error.
+	ReconciliationCompleteCondition = "ReconciliationComplete"
+	// ReconciledReason is the reason of the ReconciliationCompleteCondition when the cluster matches its specification.
+	ReconciledReason = "Reconciled"
+	// ReconciliationInProgressReason is the reason of the ReconciliationCompleteCondition while changes are applied.
+	ReconciliationInProgressReason = "ReconciliationInProgress"
+	// ReconciliationErrorReason is the reason of the ReconciliationCompleteCondition when the reconciliation failed.
+	ReconciliationErrorReason = "ReconciliationError"
+	// UpgradeInProgressCondition is the type of the condition reporting whether Pods are waiting to be restarted by a
+	// rolling upgrade.
+	UpgradeInProgressCondition = "UpgradeInProgress"
+	// PodsToUpgradeReason is the reason of the UpgradeInProgressCondition when some Pods must be restarted.
+	PodsToUpgradeReason = "PodsToUpgrade"
+	// PodsUpToDateReason is the reason of the UpgradeInProgressCondition when all Pods run their expected spec.
+	PodsUpToDateReason = "PodsUpToDate"
+	// DataMigratingCondition is the type of the condition reporting whether data is migrated away from nodes to remove.
+	DataMigratingCondition = "DataMigrating"
+	// DataMigrationInProgressReason is the reason of the DataMigratingCondition while data is migrated.
+	DataMigrationInProgressReason = "DataMigrationInProgress"
+	// NoDataMigrationReason is the reason of the DataMigratingCondition when no data migration is pending.
+	NoDataMigrationReason = "NoDataMigration"
+	// CertificatesReadyCondition is the type of the condition reporting whether the transport and HTTP certificates
+	// of the cluster are reconciled.
+	CertificatesReadyCondition = "CertificatesReady"
+	// CertificatesReconciledReason is the reason of the CertificatesReadyCondition when certificates are reconciled.
+	CertificatesReconciledReason = "CertificatesReconciled"
+	// CertificatesErrorReason is the reason of the CertificatesReadyCondition when certificates cannot be reconciled.
+	CertificatesErrorReason = "CertificatesError"
+	// UpgradeBlockedCondition is the type of the condition set while a major version upgrade is not started because
+	// the pre-upgrade checks did not pass.
+	UpgradeBlockedCondition = "UpgradeBlocked"
+	// CriticalDeprecationsReason is the reason of the UpgradeBlockedCondition when the deprecation info API reports
+	// critical issues.
+	CriticalDeprecationsReason = "CriticalDeprecations"
+	// PreUpgradeChecksErrorReason is the reason of the UpgradeBlockedCondition when the deprecation info API cannot
+	// be queried.
+	PreUpgradeChecksErrorReason = "PreUpgradeChecksError"
+	// VotingConfigExclusionsCondition is the type of the condition set while master nodes are excluded from the voting
+	// configuration, before being removed from the cluster. The condition message lists the excluded nodes.
+	VotingConfigExclusionsCondition = "VotingConfigExclusions"
+	// NodesExcludedFromVotingReason is the reason of the VotingConfigExclusionsCondition.
+	NodesExcludedFromVotingReason = "NodesExcludedFromVoting"
+	// SnapshotRestoredCondition is the type of the condition reporting the progress of the restore of the initial
+	// snapshot of a new cluster.
+	SnapshotRestoredCondition = "SnapshotRestored"
+	// SnapshotRestoreInProgressReason is the reason of the SnapshotRestoredCondition until the snapshot is restored.
+	SnapshotRestoreInProgressReason = "SnapshotRestoreInProgress"
+	// SnapshotRestoredReason is the reason of the SnapshotRestoredCondition once the snapshot is restored.
+	SnapshotRestoredReason = "SnapshotRestored"
+	// SnapshotRestoreErrorReason is the reason of the SnapshotRestoredCondition when the restore cannot proceed.
+	SnapshotRestoreErrorReason = "SnapshotRestoreError"
+	// VolumeExpansionCondition is the type of the condition set while the PVCs of a NodeSet are resized following an
+	// increase of the storage requests of its volume claim templates, or if they cannot be resized.
+	VolumeExpansionCondition = "VolumeExpansion"
+	// VolumeExpansionInProgressReason is the reason of the VolumeExpansionCondition until the volumes are resized.
+	VolumeExpansionInProgressReason = "VolumeExpansionInProgress"
+	// FileSystemResizePendingReason is the reason of the VolumeExpansionCondition while the file system of resized
+	// volumes waits to be expanded, until their Pods are restarted if needed.
+	FileSystemResizePendingReason = "FileSystemResizePending"
+	// VolumeExpansionErrorReason is the reason of the VolumeExpansionCondition when the requested storage change is
+	// not supported, for example if the storage class does not allow volume expansion.
+	VolumeExpansionErrorReason = "VolumeExpansionError"
+	// ShardsUnassignedCondition is the type of the condition set when the health of the cluster stays yellow or red
+	// long enough for the allocation of its unassigned shards to be explained.
+	ShardsUnassignedCondition = "ShardsUnassigned"
+	// AllocationExplanationReason is the reason of the ShardsUnassignedCondition, whose message summarizes the
+	// Elasticsearch cluster allocation explain API response.
+	AllocationExplanationReason = "AllocationExplanation"
+)
+
 type ZenDiscoveryStatus struct {
 	MinimumMasterNodes int `json:"minimumMasterNodes,omitempty"`
 }
@@ -456,14 +1047,22 @@ type Elasticsearch struct {
 	AssocConfs map[types.NamespacedName]commonv1.AssociationConf `json:"-"`
 }
 
-// DownwardNodeLabels returns the set of expected node labels to be copied as annotations on the Elasticsearch Pods.
+// DownwardNodeLabels returns the set of expected node labels to be copied as annotations on the Elasticsearch Pods,
+// including the zone label if zone awareness is enabled.
 func (es Elasticsearch) DownwardNodeLabels() []string {
+	var nodeLabels []string
 	expectedAnnotations, exist := es.Annotations[DownwardNodeLabelsAnnotation]
 	expectedAnnotations = strings.TrimSpace(expectedAnnotations)
-	if !exist || expectedAnnotations == "" {
-		return nil
+	if exist && expectedAnnotations != "" {
+		nodeLabels = strings.Split(expectedAnnotations, ",")
 	}
-	return strings.Split(expectedAnnotations, ",")
+	if es.Spec.ZoneAwareness != nil {
+		topologyKey := es.Spec.ZoneAwareness.TopologyKeyOrDefault()
+		if !stringsutil.StringInSlice(topologyKey, nodeLabels) {
+			nodeLabels = append(nodeLabels, topologyKey)
+		}
+	}
+	return nodeLabels
 }
 
 // HasDownwardNodeLabels returns true if some node labels are expected on the Elasticsearch Pods.
@@ -491,8 +1090,53 @@ func (es Elasticsearch) AutoscalingSpec() string {
 	return es.Annotations[ElasticsearchAutoscalingSpecAnnotationName]
 }
 
+// SecureSettings returns the secret sources of the Elasticsearch keystore, including the ones projecting the secure
+// settings of the realms.
 func (es Elasticsearch) SecureSettings() []commonv1.SecretSource {
-	return es.Spec.SecureSettings
+	realmSecureSettings := es.Spec.Auth.realmSecureSettings()
+	if len(realmSecureSettings) == 0 {
+		return es.Spec.SecureSettings
+	}
+	secureSettings := make([]commonv1.SecretSource, 0, len(es.Spec.SecureSettings)+len(realmSecureSettings))
+	secureSettings = append(secureSettings, es.Spec.SecureSettings...)
+	return append(secureSettings, realmSecureSettings...)
+}
+
+// InitTasksFor returns the init tasks run by the Pods of the given NodeSet: a task installing the plugins of the NodeSet,
+// if any, followed by the init tasks of the cluster.
+func (es Elasticsearch) InitTasksFor(nodeSet NodeSet) []InitTask {
+	if len(nodeSet.Plugins) == 0 {
+		return es.Spec.InitTasks
+	}
+	tasks := make([]InitTask, 0, len(es.Spec.InitTasks)+1)
+	tasks = append(tasks, InitTask{Name: NodeSetPluginsInitTaskName, InstallPlugins: nodeSet.Plugins})
+	return append(tasks, es.Spec.InitTasks...)
+}
+
+// IsInMaintenance returns true if the Elasticsearch resource is annotated to be in maintenance mode.
+func (es Elasticsearch) IsInMaintenance() bool {
+	return es.Annotations[MaintenanceAnnotation] == "true"
+}
+
+// IsJVMHeapFromMemoryLimits returns true if the JVM heap size of the nodes must be derived from their memory limits.
+func (es Elasticsearch) IsJVMHeapFromMemoryLimits() bool {
+	return es.Annotations[JVMHeapFromMemoryLimitsAnnotation] == "true"
+}
+
+// RestartTrigger returns the value of the restart annotations of the Elasticsearch resource that apply to the given
+// NodeSet, or an empty string if none is set.
+func (es Elasticsearch) RestartTrigger(nodeSetName string) string {
+	clusterTrigger := es.Annotations[RestartAnnotation]
+	nodeSetTrigger := es.Annotations[NodeSetRestartAnnotationPrefix+nodeSetName]
+	if clusterTrigger == "" && nodeSetTrigger == "" {
+		return ""
+	}
+	return clusterTrigger + "," + nodeSetTrigger
+}
+
+// SkipPreUpgradeChecks returns true if major version upgrades must not be blocked by the pre-upgrade checks.
+func (es Elasticsearch) SkipPreUpgradeChecks() bool {
+	return es.Annotations[SkipPreUpgradeChecksAnnotation] == "true"
 }
 
 func (es Elasticsearch) SuspendedPodNames() set.StringSet {
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
 )
@@ -257,3 +258,81 @@ func TestElasticsearch_SuspendedPodNames(t *testing.T) {
 		})
 	}
 }
+
+func TestElasticsearch_SecureSettings(t *testing.T) {
+	es := Elasticsearch{Spec: ElasticsearchSpec{
+		SecureSettings: []commonv1.SecretSource{{SecretName: "gcs-credentials"}},
+		Auth: Auth{Realms: []Realm{
+			{Type: SAMLRealmType, Name: "saml1", Order: 2},
+			{Type: OIDCRealmType, Name: "oidc1", Order: 3, ClientSecret: &commonv1.SecretKeySelector{SecretName: "oidc", Key: "client-secret"}},
+		}},
+	}}
+	require.Equal(t, []commonv1.SecretSource{
+		{SecretName: "gcs-credentials"},
+		{SecretName: "oidc", Entries: []commonv1.KeyToPath{{Key: "client-secret", Path: "xpack.security.authc.realms.oidc.oidc1.rp.client_secret"}}},
+	}, es.SecureSettings())
+}
+
+func TestElasticsearch_InitTasksFor(t *testing.T) {
+	es := Elasticsearch{Spec: ElasticsearchSpec{
+		InitTasks: []InitTask{{Name: "synonyms", DownloadFiles: []FileDownload{{URL: "https://example.com/synonyms.txt", Path: "synonyms.txt"}}}},
+	}}
+	require.Equal(t, es.Spec.InitTasks, es.InitTasksFor(NodeSet{Name: "default"}))
+	// the plugins of the NodeSet are installed first
+	require.Equal(t, []InitTask{
+		{Name: NodeSetPluginsInitTaskName, InstallPlugins: []string{"analysis-icu"}},
+		es.Spec.InitTasks[0],
+	}, es.InitTasksFor(NodeSet{Name: "default", Plugins: []string{"analysis-icu"}}))
+}
+
+func TestElasticsearch_DownwardNodeLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		zoneAwareness *ZoneAwareness
+		want          []string
+	}{
+		{
+			name: "no node labels",
+		},
+		{
+			name:        "node labels from the annotation",
+			annotations: map[string]string{DownwardNodeLabelsAnnotation: "topology.kubernetes.io/region,topology.kubernetes.io/zone"},
+			want:        []string{"topology.kubernetes.io/region", "topology.kubernetes.io/zone"},
+		},
+		{
+			name:          "zone label with zone awareness",
+			zoneAwareness: &ZoneAwareness{},
+			want:          []string{"topology.kubernetes.io/zone"},
+		},
+		{
+			name:          "zone label not duplicated",
+			annotations:   map[string]string{DownwardNodeLabelsAnnotation: "example.com/rack,topology.kubernetes.io/region"},
+			zoneAwareness: &ZoneAwareness{TopologyKey: "example.com/rack"},
+			want:          []string{"example.com/rack", "topology.kubernetes.io/region"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       ElasticsearchSpec{ZoneAwareness: tt.zoneAwareness},
+			}
+			require.Equal(t, tt.want, es.DownwardNodeLabels())
+		})
+	}
+}
+
+func TestDataTiers_DataTierRoles(t *testing.T) {
+	tiers := &DataTiers{
+		Hot:    &DataTier{NodeSets: []string{"hot", "hot-warm"}},
+		Warm:   &DataTier{NodeSets: []string{"hot-warm"}},
+		Frozen: &DataTier{NodeSets: []string{"frozen"}},
+	}
+	require.Equal(t, []NodeRole{DataHotRole, DataContentRole}, tiers.DataTierRoles("hot"))
+	require.Equal(t, []NodeRole{DataHotRole, DataContentRole, DataWarmRole}, tiers.DataTierRoles("hot-warm"))
+	require.Equal(t, []NodeRole{DataFrozenRole}, tiers.DataTierRoles("frozen"))
+	require.Nil(t, tiers.DataTierRoles("master"))
+	var noTiers *DataTiers
+	require.Nil(t, noTiers.DataTierRoles("hot"))
+}
@@ -45,6 +45,11 @@ const (
 	XPackSecurityTransportSslVerificationMode       = "xpack.security.transport.ssl.verification_mode"
 
 	XPackLicenseUploadTypes = "xpack.license.upload.types" // supported >= 7.6.0 used as of 7.8.1
+
+	RemoteClusterServerEnabled                                = "remote_cluster_server.enabled"
+	XPackSecurityRemoteClusterServerSslCertificate            = "xpack.security.remote_cluster_server.ssl.certificate"
+	XPackSecurityRemoteClusterServerSslKey                    = "xpack.security.remote_cluster_server.ssl.key"
+	XPackSecurityRemoteClusterClientSslCertificateAuthorities = "xpack.security.remote_cluster_client.ssl.certificate_authorities"
 )
 
 var UnsupportedSettings = []string{
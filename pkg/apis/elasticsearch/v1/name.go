@@ -25,7 +25,9 @@ const (
 	unicastHostsConfigMapSuffix                  = "unicast-hosts"
 	licenseSecretSuffix                          = "license"
 	defaultPodDisruptionBudget                   = "default"
+	tierPodDisruptionBudgetSuffix                = "pdb"
 	scriptsConfigMapSuffix                       = "scripts"
+	orchestrationHintsConfigMapSuffix            = "orchestration-hints"
 	legacyTransportCertsSecretSuffix             = "transport-certificates"
 	statefulSetTransportCertificatesSecretSuffix = "transport-certs"
 
@@ -36,6 +38,17 @@ const (
 	// remoteCaNameSuffix is a suffix for the secret that contains the concatenation of all the remote CAs
 	remoteCaNameSuffix = "remote-ca"
 
+	// remoteAPIKeysSecretSuffix is a suffix for the secret that contains the cross-cluster API keys of the remote clusters
+	remoteAPIKeysSecretSuffix = "remote-api-keys"
+
+	// policySecureSettingsSecretSuffix is a suffix for the secret that contains the secure settings of the
+	// StackConfigPolicy applied to the cluster
+	policySecureSettingsSecretSuffix = "policy-secure-settings"
+
+	// snapshotCredentialsSecretSuffix is a suffix for the secret that contains the secure settings, such as the client
+	// credentials, of the SnapshotRepositories referencing the cluster
+	snapshotCredentialsSecretSuffix = "snapshot-credentials"
+
 	controllerRevisionHashLen = 10
 )
 
@@ -54,8 +67,12 @@ var (
 		licenseSecretSuffix,
 		defaultPodDisruptionBudget,
 		scriptsConfigMapSuffix,
+		orchestrationHintsConfigMapSuffix,
 		statefulSetTransportCertificatesSecretSuffix,
 		remoteCaNameSuffix,
+		remoteAPIKeysSecretSuffix,
+		policySecureSettingsSecretSuffix,
+		snapshotCredentialsSecretSuffix,
 	}
 )
 
@@ -153,6 +170,11 @@ func ScriptsConfigMap(esName string) string {
 	return ESNamer.Suffix(esName, scriptsConfigMapSuffix)
 }
 
+// OrchestrationHintsConfigMap returns the name of the ConfigMap that persists the orchestration hints of a given cluster.
+func OrchestrationHintsConfigMap(esName string) string {
+	return ESNamer.Suffix(esName, orchestrationHintsConfigMapSuffix)
+}
+
 func LicenseSecretName(esName string) string {
 	return ESNamer.Suffix(esName, licenseSecretSuffix)
 }
@@ -161,6 +183,24 @@ func DefaultPodDisruptionBudget(esName string) string {
 	return ESNamer.Suffix(esName, defaultPodDisruptionBudget)
 }
 
+// TierPodDisruptionBudget returns the name of the PodDisruptionBudget of the given tier of a cluster. The suffix prevents
+// collisions with the default PodDisruptionBudget.
+func TierPodDisruptionBudget(esName string, tierName string) string {
+	return ESNamer.Suffix(esName, tierName, tierPodDisruptionBudgetSuffix)
+}
+
 func RemoteCaSecretName(esName string) string {
 	return ESNamer.Suffix(esName, remoteCaNameSuffix)
 }
+
+func RemoteAPIKeysSecretName(esName string) string {
+	return ESNamer.Suffix(esName, remoteAPIKeysSecretSuffix)
+}
+
+func PolicySecureSettingsSecretName(esName string) string {
+	return ESNamer.Suffix(esName, policySecureSettingsSecretSuffix)
+}
+
+func SnapshotCredentialsSecretName(esName string) string {
+	return ESNamer.Suffix(esName, snapshotCredentialsSecretSuffix)
+}
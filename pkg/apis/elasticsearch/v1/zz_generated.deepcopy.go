@@ -12,6 +12,7 @@ package v1
 import (
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -29,6 +30,13 @@ func (in *Auth) DeepCopyInto(out *Auth) {
 		*out = make([]FileRealmSource, len(*in))
 		copy(*out, *in)
 	}
+	if in.Realms != nil {
+		in, out := &in.Realms, &out.Realms
+		*out = make([]Realm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Auth.
@@ -41,6 +49,57 @@ func (in *Auth) DeepCopy() *Auth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoFollowPattern) DeepCopyInto(out *AutoFollowPattern) {
+	*out = *in
+	if in.LeaderIndexPatterns != nil {
+		in, out := &in.LeaderIndexPatterns, &out.LeaderIndexPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowPattern.
+func (in *AutoFollowPattern) DeepCopy() *AutoFollowPattern {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoFollowPattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerConfig) DeepCopyInto(out *CertManagerConfig) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerConfig.
+func (in *CertManagerConfig) DeepCopy() *CertManagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChangeBudget) DeepCopyInto(out *ChangeBudget) {
 	*out = *in
@@ -54,6 +113,11 @@ func (in *ChangeBudget) DeepCopyInto(out *ChangeBudget) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxUnavailablePerTier != nil {
+		in, out := &in.MaxUnavailablePerTier, &out.MaxUnavailablePerTier
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeBudget.
@@ -86,6 +150,106 @@ func (in *ClusterSettings) DeepCopy() *ClusterSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataTier) DeepCopyInto(out *DataTier) {
+	*out = *in
+	if in.NodeSets != nil {
+		in, out := &in.NodeSets, &out.NodeSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataTier.
+func (in *DataTier) DeepCopy() *DataTier {
+	if in == nil {
+		return nil
+	}
+	out := new(DataTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataTiers) DeepCopyInto(out *DataTiers) {
+	*out = *in
+	if in.Hot != nil {
+		in, out := &in.Hot, &out.Hot
+		*out = new(DataTier)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Warm != nil {
+		in, out := &in.Warm, &out.Warm
+		*out = new(DataTier)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cold != nil {
+		in, out := &in.Cold, &out.Cold
+		*out = new(DataTier)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Frozen != nil {
+		in, out := &in.Frozen, &out.Frozen
+		*out = new(DataTier)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ILMPolicy != nil {
+		in, out := &in.ILMPolicy, &out.ILMPolicy
+		*out = new(DataTiersILMPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataTiers.
+func (in *DataTiers) DeepCopy() *DataTiers {
+	if in == nil {
+		return nil
+	}
+	out := new(DataTiers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataTiersILMPolicy) DeepCopyInto(out *DataTiersILMPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataTiersILMPolicy.
+func (in *DataTiersILMPolicy) DeepCopy() *DataTiersILMPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DataTiersILMPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DownscalePolicy) DeepCopyInto(out *DownscalePolicy) {
+	*out = *in
+	if in.TierOrder != nil {
+		in, out := &in.TierOrder, &out.TierOrder
+		*out = make([]NodeRole, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxRemovalsPerTier != nil {
+		in, out := &in.MaxRemovalsPerTier, &out.MaxRemovalsPerTier
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownscalePolicy.
+func (in *DownscalePolicy) DeepCopy() *DownscalePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DownscalePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Elasticsearch) DeepCopyInto(out *Elasticsearch) {
 	*out = *in
@@ -178,6 +342,11 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 	*out = *in
 	in.HTTP.DeepCopyInto(&out.HTTP)
 	in.Transport.DeepCopyInto(&out.Transport)
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerConfig)
+		**out = **in
+	}
 	if in.NodeSets != nil {
 		in, out := &in.NodeSets, &out.NodeSets
 		*out = make([]NodeSet, len(*in))
@@ -186,11 +355,39 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 		}
 	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	in.DownscalePolicy.DeepCopyInto(&out.DownscalePolicy)
+	if in.InitTasks != nil {
+		in, out := &in.InitTasks, &out.InitTasks
+		*out = make([]InitTask, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneAwareness != nil {
+		in, out := &in.ZoneAwareness, &out.ZoneAwareness
+		*out = new(ZoneAwareness)
+		**out = **in
+	}
+	if in.DataTiers != nil {
+		in, out := &in.DataTiers, &out.DataTiers
+		*out = new(DataTiers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitialSnapshotRestore != nil {
+		in, out := &in.InitialSnapshotRestore, &out.InitialSnapshotRestore
+		*out = new(InitialSnapshotRestore)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PodDisruptionBudget != nil {
 		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
 		*out = new(commonv1.PodDisruptionBudgetTemplate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudgetPerTier != nil {
+		in, out := &in.PodDisruptionBudgetPerTier, &out.PodDisruptionBudgetPerTier
+		*out = new(PodDisruptionBudgetPerTier)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Auth.DeepCopyInto(&out.Auth)
 	if in.SecureSettings != nil {
 		in, out := &in.SecureSettings, &out.SecureSettings
@@ -202,8 +399,11 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 	if in.RemoteClusters != nil {
 		in, out := &in.RemoteClusters, &out.RemoteClusters
 		*out = make([]RemoteCluster, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	out.RemoteClusterServer = in.RemoteClusterServer
 	in.Monitoring.DeepCopyInto(&out.Monitoring)
 }
 
@@ -220,6 +420,10 @@ func (in *ElasticsearchSpec) DeepCopy() *ElasticsearchSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchStatus) DeepCopyInto(out *ElasticsearchStatus) {
 	*out = *in
+	if in.PhaseTransitionTime != nil {
+		in, out := &in.PhaseTransitionTime, &out.PhaseTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.MonitoringAssociationsStatus != nil {
 		in, out := &in.MonitoringAssociationsStatus, &out.MonitoringAssociationsStatus
 		*out = make(commonv1.AssociationStatusMap, len(*in))
@@ -227,6 +431,13 @@ func (in *ElasticsearchStatus) DeepCopyInto(out *ElasticsearchStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStatus.
@@ -260,6 +471,21 @@ func (in *EsMonitoringAssociation) DeepCopy() *EsMonitoringAssociation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileDownload) DeepCopyInto(out *FileDownload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileDownload.
+func (in *FileDownload) DeepCopy() *FileDownload {
+	if in == nil {
+		return nil
+	}
+	out := new(FileDownload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FileRealmSource) DeepCopyInto(out *FileRealmSource) {
 	*out = *in
@@ -276,6 +502,67 @@ func (in *FileRealmSource) DeepCopy() *FileRealmSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowerIndex) DeepCopyInto(out *FollowerIndex) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowerIndex.
+func (in *FollowerIndex) DeepCopy() *FollowerIndex {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowerIndex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitialSnapshotRestore) DeepCopyInto(out *InitialSnapshotRestore) {
+	*out = *in
+	in.Repository.DeepCopyInto(&out.Repository)
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitialSnapshotRestore.
+func (in *InitialSnapshotRestore) DeepCopy() *InitialSnapshotRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(InitialSnapshotRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitTask) DeepCopyInto(out *InitTask) {
+	*out = *in
+	if in.InstallPlugins != nil {
+		in, out := &in.InstallPlugins, &out.InstallPlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DownloadFiles != nil {
+		in, out := &in.DownloadFiles, &out.DownloadFiles
+		*out = make([]FileDownload, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitTask.
+func (in *InitTask) DeepCopy() *InitTask {
+	if in == nil {
+		return nil
+	}
+	out := new(InitTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogsMonitoring) DeepCopyInto(out *LogsMonitoring) {
 	*out = *in
@@ -403,6 +690,16 @@ func (in *NodeSet) DeepCopyInto(out *NodeSet) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(ReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSet.
@@ -415,10 +712,121 @@ func (in *NodeSet) DeepCopy() *NodeSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetPerTier) DeepCopyInto(out *PodDisruptionBudgetPerTier) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetPerTier.
+func (in *PodDisruptionBudgetPerTier) DeepCopy() *PodDisruptionBudgetPerTier {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetPerTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessProbe) DeepCopyInto(out *ReadinessProbe) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessProbe.
+func (in *ReadinessProbe) DeepCopy() *ReadinessProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Realm) DeepCopyInto(out *Realm) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = (*in).DeepCopy()
+	}
+	if in.IdPMetadata != nil {
+		in, out := &in.IdPMetadata, &out.IdPMetadata
+		*out = new(commonv1.SecretKeySelector)
+		**out = **in
+	}
+	if in.ClientSecret != nil {
+		in, out := &in.ClientSecret, &out.ClientSecret
+		*out = new(commonv1.SecretKeySelector)
+		**out = **in
+	}
+	if in.JWKSet != nil {
+		in, out := &in.JWKSet, &out.JWKSet
+		*out = new(commonv1.SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Realm.
+func (in *Realm) DeepCopy() *Realm {
+	if in == nil {
+		return nil
+	}
+	out := new(Realm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteCluster) DeepCopyInto(out *RemoteCluster) {
 	*out = *in
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.FollowerIndices != nil {
+		in, out := &in.FollowerIndices, &out.FollowerIndices
+		*out = make([]FollowerIndex, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoFollowPatterns != nil {
+		in, out := &in.AutoFollowPatterns, &out.AutoFollowPatterns
+		*out = make([]AutoFollowPattern, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(RemoteClusterAPIKey)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteCluster.
@@ -431,6 +839,82 @@ func (in *RemoteCluster) DeepCopy() *RemoteCluster {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterAPIKey) DeepCopyInto(out *RemoteClusterAPIKey) {
+	*out = *in
+	in.Access.DeepCopyInto(&out.Access)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterAPIKey.
+func (in *RemoteClusterAPIKey) DeepCopy() *RemoteClusterAPIKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterAPIKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterAccess) DeepCopyInto(out *RemoteClusterAccess) {
+	*out = *in
+	if in.Search != nil {
+		in, out := &in.Search, &out.Search
+		*out = new(RemoteClusterIndices)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(RemoteClusterIndices)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterAccess.
+func (in *RemoteClusterAccess) DeepCopy() *RemoteClusterAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterIndices) DeepCopyInto(out *RemoteClusterIndices) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterIndices.
+func (in *RemoteClusterIndices) DeepCopy() *RemoteClusterIndices {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterIndices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterServer) DeepCopyInto(out *RemoteClusterServer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterServer.
+func (in *RemoteClusterServer) DeepCopy() *RemoteClusterServer {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleSource) DeepCopyInto(out *RoleSource) {
 	*out = *in
@@ -447,6 +931,25 @@ func (in *RoleSource) DeepCopy() *RoleSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRestoreRepository) DeepCopyInto(out *SnapshotRestoreRepository) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestoreRepository.
+func (in *SnapshotRestoreRepository) DeepCopy() *SnapshotRestoreRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRestoreRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransportConfig) DeepCopyInto(out *TransportConfig) {
 	*out = *in
@@ -473,6 +976,7 @@ func (in *TransportTLSOptions) DeepCopyInto(out *TransportTLSOptions) {
 		copy(*out, *in)
 	}
 	out.Certificate = in.Certificate
+	out.NodeCertificate = in.NodeCertificate
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportTLSOptions.
@@ -515,3 +1019,18 @@ func (in *ZenDiscoveryStatus) DeepCopy() *ZenDiscoveryStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneAwareness) DeepCopyInto(out *ZoneAwareness) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneAwareness.
+func (in *ZoneAwareness) DeepCopy() *ZoneAwareness {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneAwareness)
+	in.DeepCopyInto(out)
+	return out
+}
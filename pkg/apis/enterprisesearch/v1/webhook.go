@@ -27,6 +27,7 @@ var (
 		checkNoUnknownFields,
 		checkNameLength,
 		checkSupportedVersion,
+		checkAssociations,
 	}
 
 	updateChecks = []func(old, curr *EnterpriseSearch) field.ErrorList{
@@ -105,3 +106,7 @@ func checkSupportedVersion(ent *EnterpriseSearch) field.ErrorList {
 func checkNoDowngrade(prev, curr *EnterpriseSearch) field.ErrorList {
 	return commonv1.CheckNoDowngrade(prev.Spec.Version, curr.Spec.Version)
 }
+
+func checkAssociations(ent *EnterpriseSearch) field.ErrorList {
+	return commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), ent.Spec.ElasticsearchRef)
+}
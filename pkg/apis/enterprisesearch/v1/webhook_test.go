@@ -15,6 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	entv1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/test"
 )
@@ -59,6 +60,19 @@ func TestWebhook(t *testing.T) {
 				`metadata.name: Too long: must have at most 36 bytes`,
 			),
 		},
+		{
+			Name:      "elasticsearchRef-with-secretName-and-name",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				ent := mkEnterpriseSearch(uid)
+				ent.Spec.ElasticsearchRef = commonv1.ObjectSelector{SecretName: "external", Name: "managed"}
+				return serialize(t, ent)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.elasticsearchRef: Forbidden: specify secretName or name, namespace and serviceName, not both`,
+			),
+		},
 		{
 			Name:      "invalid-version",
 			Operation: admissionv1beta1.Create,
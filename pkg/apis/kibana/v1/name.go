@@ -8,7 +8,10 @@ import (
 	common_name "github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
 )
 
-const httpServiceSuffix = "http"
+const (
+	httpServiceSuffix          = "http"
+	encryptionKeysSecretSuffix = "encryption-keys"
+)
 
 // KBNamer is a KBNamer that is configured with the defaults for resources related to a Kibana resource.
 var KBNamer = common_name.NewNamer("kb")
@@ -20,3 +23,8 @@ func HTTPService(kbName string) string {
 func Deployment(kbName string) string {
 	return KBNamer.Suffix(kbName)
 }
+
+// EncryptionKeysSecret returns the name of the Secret holding the encryption keys shared by the Kibana instances.
+func EncryptionKeysSecret(kbName string) string {
+	return KBNamer.Suffix(kbName, encryptionKeysSecretSuffix)
+}
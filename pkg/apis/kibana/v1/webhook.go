@@ -30,6 +30,7 @@ var (
 		checkNameLength,
 		checkSupportedVersion,
 		checkMonitoring,
+		checkAssociations,
 	}
 
 	updateChecks = []func(old, curr *Kibana) field.ErrorList{
@@ -118,3 +119,10 @@ func checkMonitoring(k *Kibana) field.ErrorList {
 	}
 	return errs
 }
+
+func checkAssociations(k *Kibana) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), k.Spec.ElasticsearchRef)...)
+	errs = append(errs, commonv1.CheckAssociationRefs(field.NewPath("spec").Child("enterpriseSearchRef"), k.Spec.EnterpriseSearchRef)...)
+	return errs
+}
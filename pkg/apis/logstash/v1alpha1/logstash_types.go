@@ -0,0 +1,198 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	LogstashContainerName = "logstash"
+	// Kind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	Kind = "Logstash"
+)
+
+// LogstashSpec defines the desired state of Logstash
+type LogstashSpec struct {
+	// Version of Logstash.
+	Version string `json:"version"`
+
+	// Image is the Logstash Docker image to deploy.
+	Image string `json:"image,omitempty"`
+
+	// Count of Logstash instances to deploy.
+	Count int32 `json:"count,omitempty"`
+
+	// ElasticsearchRef is a reference to an Elasticsearch cluster running in the same Kubernetes cluster.
+	// Its URL, credentials and CA certificate are exposed to the pipelines through the ELASTICSEARCH_HOSTS,
+	// ELASTICSEARCH_USERNAME, ELASTICSEARCH_PASSWORD and ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY environment variables.
+	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef,omitempty"`
+
+	// Config holds the Logstash configuration, written in the logstash.yml file.
+	// See: https://www.elastic.co/guide/en/logstash/current/logstash-settings-file.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Config *commonv1.Config `json:"config,omitempty"`
+
+	// ConfigRef contains a reference to an existing Kubernetes Secret holding the Logstash configuration.
+	// Logstash settings must be specified as yaml, under a single "logstash.yml" entry. At most one of [`Config`, `ConfigRef`]
+	// can be specified.
+	// +kubebuilder:validation:Optional
+	ConfigRef *commonv1.ConfigSource `json:"configRef,omitempty"`
+
+	// Pipelines holds the Logstash pipelines, written in the pipelines.yml file.
+	// See: https://www.elastic.co/guide/en/logstash/current/multiple-pipelines.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Pipelines []commonv1.Config `json:"pipelines,omitempty"`
+
+	// PipelinesRef contains a reference to an existing Kubernetes Secret holding the Logstash pipelines.
+	// Logstash pipelines must be specified as yaml, under a single "pipelines.yml" entry. At most one of [`Pipelines`, `PipelinesRef`]
+	// can be specified.
+	// +kubebuilder:validation:Optional
+	PipelinesRef *commonv1.ConfigSource `json:"pipelinesRef,omitempty"`
+
+	// Services contains details of the Services exposing the Logstash inputs, such as the Beats input.
+	// +kubebuilder:validation:Optional
+	Services []LogstashService `json:"services,omitempty"`
+
+	// PodTemplate provides customisation options (labels, annotations, affinity rules, resource requests, and so on) for the Logstash pods
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// ServiceAccountName is used to check access from the current resource to a resource (eg. Elasticsearch) in a different namespace.
+	// Can only be used if ECK is enforcing RBAC on references.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// LogstashService defines a Service exposing Logstash inputs.
+type LogstashService struct {
+	// Name of the Service, used as a suffix of the Service resource name.
+	Name string `json:"name"`
+
+	// Service defines the template for the associated Kubernetes Service object. Its ports must match the ports
+	// of the Logstash inputs it exposes.
+	Service commonv1.ServiceTemplate `json:"service,omitempty"`
+}
+
+// LogstashStatus defines the observed state of Logstash
+type LogstashStatus struct {
+	commonv1.DeploymentStatus `json:",inline"`
+	AssociationStatus         commonv1.AssociationStatus `json:"associationStatus,omitempty"`
+}
+
+// IsMarkedForDeletion returns true if the Logstash instance is going to be deleted
+func (l *Logstash) IsMarkedForDeletion() bool {
+	return !l.DeletionTimestamp.IsZero()
+}
+
+func (l *Logstash) Associated() commonv1.Associated {
+	return l
+}
+
+func (l *Logstash) AssociationConfAnnotationName() string {
+	return commonv1.ElasticsearchConfigAnnotationNameBase
+}
+
+func (l *Logstash) AssociationType() commonv1.AssociationType {
+	return commonv1.ElasticsearchAssociationType
+}
+
+func (l *Logstash) AssociationRef() commonv1.ObjectSelector {
+	return l.Spec.ElasticsearchRef.WithDefaultNamespace(l.Namespace)
+}
+
+func (l *Logstash) ServiceAccountName() string {
+	return l.Spec.ServiceAccountName
+}
+
+func (l *Logstash) AssociationConf() *commonv1.AssociationConf {
+	return l.assocConf
+}
+
+func (l *Logstash) SetAssociationConf(assocConf *commonv1.AssociationConf) {
+	l.assocConf = assocConf
+}
+
+// RequiresAssociation returns true if the spec specifies an Elasticsearch reference.
+func (l *Logstash) RequiresAssociation() bool {
+	return l.Spec.ElasticsearchRef.Name != ""
+}
+
+func (l *Logstash) AssociationStatusMap(typ commonv1.AssociationType) commonv1.AssociationStatusMap {
+	if typ == commonv1.ElasticsearchAssociationType && l.Spec.ElasticsearchRef.IsDefined() {
+		return commonv1.NewSingleAssociationStatusMap(l.Status.AssociationStatus)
+	}
+
+	return commonv1.AssociationStatusMap{}
+}
+
+func (l *Logstash) SetAssociationStatusMap(typ commonv1.AssociationType, status commonv1.AssociationStatusMap) error {
+	single, err := status.Single()
+	if err != nil {
+		return err
+	}
+
+	if typ != commonv1.ElasticsearchAssociationType {
+		return fmt.Errorf("association type %s not known", typ)
+	}
+
+	l.Status.AssociationStatus = single
+	return nil
+}
+
+func (l *Logstash) GetAssociations() []commonv1.Association {
+	associations := make([]commonv1.Association, 0)
+	if l.Spec.ElasticsearchRef.IsDefined() {
+		associations = append(associations, l)
+	}
+	return associations
+}
+
+func (l *Logstash) AssociationID() string {
+	return commonv1.SingletonAssociationID
+}
+
+var _ commonv1.Associated = &Logstash{}
+var _ commonv1.Association = &Logstash{}
+
+// +kubebuilder:object:root=true
+
+// Logstash is the Schema for the logstashes API
+// +kubebuilder:resource:categories=elastic,shortName=ls
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="health",type="string",JSONPath=".status.health"
+// +kubebuilder:printcolumn:name="nodes",type="integer",JSONPath=".status.availableNodes",description="Available nodes"
+// +kubebuilder:printcolumn:name="version",type="string",JSONPath=".status.version",description="Logstash version"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:scale:specpath=.spec.count,statuspath=.status.count,selectorpath=.status.selector
+// +kubebuilder:storageversion
+type Logstash struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec      LogstashSpec              `json:"spec,omitempty"`
+	Status    LogstashStatus            `json:"status,omitempty"`
+	assocConf *commonv1.AssociationConf `json:"-"`
+}
+
+// +kubebuilder:object:root=true
+
+// LogstashList contains a list of Logstash
+type LogstashList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Logstash `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Logstash{}, &LogstashList{})
+}
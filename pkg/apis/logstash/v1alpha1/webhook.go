@@ -0,0 +1,137 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+)
+
+const (
+	// APIServiceName is the name of the Service exposing the Logstash API, which cannot be used by user defined Services.
+	APIServiceName = "api"
+)
+
+var (
+	groupKind     = schema.GroupKind{Group: GroupVersion.Group, Kind: Kind}
+	validationLog = ulog.Log.WithName("logstash-v1alpha1-validation")
+
+	defaultChecks = []func(*Logstash) field.ErrorList{
+		checkNoUnknownFields,
+		checkNameLength,
+		checkSupportedVersion,
+		checkSingleConfigSource,
+		checkSinglePipelinesSource,
+		checkServices,
+		checkAssociations,
+	}
+)
+
+// +kubebuilder:webhook:path=/validate-logstash-k8s-elastic-co-v1alpha1-logstash,mutating=false,failurePolicy=ignore,groups=logstash.k8s.elastic.co,resources=logstashes,verbs=create;update,versions=v1alpha1,name=elastic-logstash-validation-v1alpha1.k8s.elastic.co,sideEffects=None,admissionReviewVersions=v1;v1beta1,matchPolicy=Exact
+
+var _ webhook.Validator = &Logstash{}
+
+func (l *Logstash) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(l).
+		Complete()
+}
+
+func (l *Logstash) ValidateCreate() error {
+	validationLog.V(1).Info("Validate create", "name", l.Name)
+	return l.validate()
+}
+
+func (l *Logstash) ValidateDelete() error {
+	validationLog.V(1).Info("Validate delete", "name", l.Name)
+	return nil
+}
+
+func (l *Logstash) ValidateUpdate(_ runtime.Object) error {
+	validationLog.V(1).Info("Validate update", "name", l.Name)
+	return l.validate()
+}
+
+func (l *Logstash) validate() error {
+	var errors field.ErrorList
+
+	for _, dc := range defaultChecks {
+		if err := dc(l); err != nil {
+			errors = append(errors, err...)
+		}
+	}
+
+	if len(errors) > 0 {
+		validationLog.V(1).Info("failed validation", "errors", errors)
+		return apierrors.NewInvalid(groupKind, l.Name, errors)
+	}
+	return nil
+}
+
+func checkNoUnknownFields(l *Logstash) field.ErrorList {
+	return commonv1.NoUnknownFields(l, l.ObjectMeta)
+}
+
+func checkNameLength(l *Logstash) field.ErrorList {
+	return commonv1.CheckNameLength(l)
+}
+
+func checkSupportedVersion(l *Logstash) field.ErrorList {
+	return commonv1.CheckSupportedStackVersion(l.Spec.Version, version.SupportedLogstashVersions)
+}
+
+func checkSingleConfigSource(l *Logstash) field.ErrorList {
+	if l.Spec.Config != nil && l.Spec.ConfigRef != nil {
+		msg := "Specify at most one of [`config`, `configRef`], not both"
+		return field.ErrorList{
+			field.Forbidden(field.NewPath("spec").Child("config"), msg),
+			field.Forbidden(field.NewPath("spec").Child("configRef"), msg),
+		}
+	}
+	return nil
+}
+
+func checkSinglePipelinesSource(l *Logstash) field.ErrorList {
+	if len(l.Spec.Pipelines) > 0 && l.Spec.PipelinesRef != nil {
+		msg := "Specify at most one of [`pipelines`, `pipelinesRef`], not both"
+		return field.ErrorList{
+			field.Forbidden(field.NewPath("spec").Child("pipelines"), msg),
+			field.Forbidden(field.NewPath("spec").Child("pipelinesRef"), msg),
+		}
+	}
+	return nil
+}
+
+func checkServices(l *Logstash) field.ErrorList {
+	var errs field.ErrorList
+	seen := make(map[string]struct{}, len(l.Spec.Services))
+	for i, svc := range l.Spec.Services {
+		path := field.NewPath("spec").Child("services").Index(i).Child("name")
+		switch {
+		case svc.Name == "":
+			errs = append(errs, field.Required(path, "service name is required"))
+		case svc.Name == APIServiceName:
+			errs = append(errs, field.Invalid(path, svc.Name, "service name is reserved for the Logstash API service"))
+		default:
+			if _, exists := seen[svc.Name]; exists {
+				errs = append(errs, field.Duplicate(path, svc.Name))
+			}
+		}
+		seen[svc.Name] = struct{}{}
+	}
+	return errs
+}
+
+func checkAssociations(l *Logstash) field.ErrorList {
+	return commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), l.Spec.ElasticsearchRef)
+}
@@ -0,0 +1,159 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/test"
+)
+
+func TestWebhook(t *testing.T) {
+	testCases := []test.ValidationWebhookTestCase{
+		{
+			Name:      "create-valid",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Pipelines = []commonv1.Config{{Data: map[string]interface{}{"pipeline.id": "main"}}}
+				l.Spec.Services = []lsv1alpha1.LogstashService{{Name: "beats"}}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "long-name",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.SetName(strings.Repeat("x", 100))
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`metadata.name: Too long: must have at most 36 bytes`,
+			),
+		},
+		{
+			Name:      "elasticsearchRef-with-secretName-and-name",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.ElasticsearchRef = commonv1.ObjectSelector{SecretName: "external", Name: "managed"}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.elasticsearchRef: Forbidden: specify secretName or name, namespace and serviceName, not both`,
+			),
+		},
+		{
+			Name:      "unsupported-version-lower",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Version = "6.8.0"
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.version: Invalid value: "6.8.0": Unsupported version: version 6.8.0 is lower than the lowest supported version`,
+			),
+		},
+		{
+			Name:      "config-and-configRef",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Config = &commonv1.Config{Data: map[string]interface{}{"log.level": "debug"}}
+				l.Spec.ConfigRef = &commonv1.ConfigSource{SecretRef: commonv1.SecretRef{SecretName: "config"}}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.config: Forbidden: Specify at most one of \[.config., .configRef.\], not both`,
+			),
+		},
+		{
+			Name:      "pipelines-and-pipelinesRef",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Pipelines = []commonv1.Config{{Data: map[string]interface{}{"pipeline.id": "main"}}}
+				l.Spec.PipelinesRef = &commonv1.ConfigSource{SecretRef: commonv1.SecretRef{SecretName: "pipelines"}}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.pipelines: Forbidden: Specify at most one of \[.pipelines., .pipelinesRef.\], not both`,
+			),
+		},
+		{
+			Name:      "reserved-service-name",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Services = []lsv1alpha1.LogstashService{{Name: "api"}}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.services\[0\].name: Invalid value: "api": service name is reserved for the Logstash API service`,
+			),
+		},
+		{
+			Name:      "duplicate-service-name",
+			Operation: admissionv1beta1.Update,
+			OldObject: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				return serialize(t, mkLogstash(uid))
+			},
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				l := mkLogstash(uid)
+				l.Spec.Services = []lsv1alpha1.LogstashService{{Name: "beats"}, {Name: "beats"}}
+				return serialize(t, l)
+			},
+			Check: test.ValidationWebhookFailed(
+				`spec.services\[1\].name: Duplicate value: "beats"`,
+			),
+		},
+	}
+
+	validator := &lsv1alpha1.Logstash{}
+	gvk := metav1.GroupVersionKind{Group: lsv1alpha1.GroupVersion.Group, Version: lsv1alpha1.GroupVersion.Version, Kind: lsv1alpha1.Kind}
+	test.RunValidationWebhookTests(t, gvk, validator, testCases...)
+}
+
+func mkLogstash(uid string) *lsv1alpha1.Logstash {
+	return &lsv1alpha1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "webhook-test",
+			UID:  types.UID(uid),
+		},
+		Spec: lsv1alpha1.LogstashSpec{
+			Version: "8.0.0",
+		},
+	}
+}
+
+func serialize(t *testing.T, l *lsv1alpha1.Logstash) []byte {
+	t.Helper()
+
+	objBytes, err := json.Marshal(l)
+	require.NoError(t, err)
+
+	return objBytes
+}
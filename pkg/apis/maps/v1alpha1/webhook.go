@@ -25,6 +25,7 @@ var (
 		checkNoUnknownFields,
 		checkNameLength,
 		checkSupportedVersion,
+		checkAssociations,
 	}
 )
 
@@ -80,3 +81,7 @@ func checkNameLength(k *ElasticMapsServer) field.ErrorList {
 func checkSupportedVersion(k *ElasticMapsServer) field.ErrorList {
 	return commonv1.CheckSupportedStackVersion(k.Spec.Version, version.SupportedMapsVersions)
 }
+
+func checkAssociations(k *ElasticMapsServer) field.ErrorList {
+	return commonv1.CheckAssociationRefs(field.NewPath("spec").Child("elasticsearchRef"), k.Spec.ElasticsearchRef)
+}
@@ -0,0 +1,95 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	// ElasticsearchRoleKind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	ElasticsearchRoleKind = "ElasticsearchRole"
+)
+
+// ElasticsearchRoleSpec holds the specification of a role of an Elasticsearch cluster, and optionally of the role
+// mapping granting it.
+type ElasticsearchRoleSpec struct {
+	// ElasticsearchRef is a reference to the Elasticsearch cluster the role is created in.
+	// The Elasticsearch cluster must be in the same namespace as the ElasticsearchRole.
+	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef"`
+	// RoleName is the name of the role. Defaults to the name of the ElasticsearchRole.
+	// +kubebuilder:validation:Optional
+	RoleName string `json:"roleName,omitempty"`
+	// Definition of the role (cluster, indices, applications, run_as, metadata...), as passed to the Elasticsearch
+	// create role API. See: https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-put-role.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Definition *commonv1.Config `json:"definition,omitempty"`
+	// Mapping grants the role to the users matching its rules, through a role mapping of the same name.
+	// +kubebuilder:validation:Optional
+	Mapping *RoleMapping `json:"mapping,omitempty"`
+}
+
+// RoleMapping holds the specification of a role mapping granting a role.
+type RoleMapping struct {
+	// Rules determining which users the role is granted to, as passed to the Elasticsearch create role mapping API.
+	// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/role-mapping-resources.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Rules *commonv1.Config `json:"rules"`
+	// Enabled indicates whether the role mapping is active. Defaults to true.
+	// +kubebuilder:validation:Optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ElasticsearchRef returns the reference to the Elasticsearch cluster, defaulting to the role namespace.
+func (r ElasticsearchRole) ElasticsearchRef() commonv1.ObjectSelector {
+	return r.Spec.ElasticsearchRef.WithDefaultNamespace(r.Namespace)
+}
+
+// RoleNameOrDefault returns the name of the role, and of its role mapping, in Elasticsearch.
+func (r ElasticsearchRole) RoleNameOrDefault() string {
+	if r.Spec.RoleName != "" {
+		return r.Spec.RoleName
+	}
+	return r.Name
+}
+
+// IsEnabled returns true if the role mapping is active.
+func (m RoleMapping) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchRole represents a role of an Elasticsearch cluster, and optionally the role mapping granting it.
+// +kubebuilder:resource:categories=elastic,shortName=esrole
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="role",type="string",JSONPath=".spec.roleName"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type ElasticsearchRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchRoleSpec `json:"spec,omitempty"`
+	Status SyncStatus            `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchRoleList contains a list of ElasticsearchRole
+type ElasticsearchRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchRole{}, &ElasticsearchRoleList{})
+}
@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	// ElasticsearchUserKind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	ElasticsearchUserKind = "ElasticsearchUser"
+)
+
+// Phase is the phase of the synchronization of a security resource with the target Elasticsearch cluster.
+type Phase string
+
+const (
+	// PendingPhase is used while the target Elasticsearch cluster is not ready to be configured.
+	PendingPhase Phase = "Pending"
+	// ReadyPhase is used once the resource is in sync with the target Elasticsearch cluster.
+	ReadyPhase Phase = "Ready"
+	// FailedPhase is used if the resource is invalid, conflicts with another one or was rejected by Elasticsearch.
+	FailedPhase Phase = "Failed"
+)
+
+// ElasticsearchUserSpec holds the specification of a user of the native realm of an Elasticsearch cluster.
+type ElasticsearchUserSpec struct {
+	// ElasticsearchRef is a reference to the Elasticsearch cluster the user is created in.
+	// The Elasticsearch cluster must be in the same namespace as the ElasticsearchUser.
+	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef"`
+	// Username of the user. Defaults to the name of the ElasticsearchUser.
+	// +kubebuilder:validation:Optional
+	Username string `json:"username,omitempty"`
+	// PasswordSecretRef is a reference to the key of a Secret, in the same namespace, holding the password of the user.
+	PasswordSecretRef commonv1.SecretKeySelector `json:"passwordSecretRef"`
+	// Roles of the user.
+	// +kubebuilder:validation:Optional
+	Roles []string `json:"roles,omitempty"`
+	// FullName of the user.
+	// +kubebuilder:validation:Optional
+	FullName string `json:"fullName,omitempty"`
+	// Email of the user.
+	// +kubebuilder:validation:Optional
+	Email string `json:"email,omitempty"`
+	// Metadata holds arbitrary metadata attached to the user.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Metadata *commonv1.Config `json:"metadata,omitempty"`
+}
+
+// SyncStatus defines the observed state of the synchronization of a security resource with Elasticsearch.
+type SyncStatus struct {
+	// Phase of the synchronization of the resource with Elasticsearch.
+	Phase Phase `json:"phase,omitempty"`
+	// Message is a human readable description of the phase, set if the resource is not ready.
+	Message string `json:"message,omitempty"`
+	// LastDriftTime is the last time the resource was found modified in Elasticsearch, and restored to its specification.
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+	// ObservedGeneration is the most recent generation observed for this resource.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ElasticsearchUserStatus defines the observed state of an ElasticsearchUser.
+type ElasticsearchUserStatus struct {
+	SyncStatus `json:",inline"`
+	// PasswordSecretVersion is the resource version of the password Secret last applied to Elasticsearch.
+	PasswordSecretVersion string `json:"passwordSecretVersion,omitempty"`
+}
+
+// ElasticsearchRef returns the reference to the Elasticsearch cluster, defaulting to the user namespace.
+func (u ElasticsearchUser) ElasticsearchRef() commonv1.ObjectSelector {
+	return u.Spec.ElasticsearchRef.WithDefaultNamespace(u.Namespace)
+}
+
+// UsernameOrDefault returns the name of the user in Elasticsearch.
+func (u ElasticsearchUser) UsernameOrDefault() string {
+	if u.Spec.Username != "" {
+		return u.Spec.Username
+	}
+	return u.Name
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchUser represents a user of the native realm of an Elasticsearch cluster.
+// +kubebuilder:resource:categories=elastic,shortName=esuser
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="username",type="string",JSONPath=".spec.username"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type ElasticsearchUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchUserSpec   `json:"spec,omitempty"`
+	Status ElasticsearchUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchUserList contains a list of ElasticsearchUser
+type ElasticsearchUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchUser{}, &ElasticsearchUserList{})
+}
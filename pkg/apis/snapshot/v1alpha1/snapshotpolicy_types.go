@@ -0,0 +1,97 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	// SnapshotPolicyKind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	SnapshotPolicyKind = "SnapshotPolicy"
+)
+
+// SnapshotPolicySpec holds the specification of a snapshot lifecycle management policy.
+type SnapshotPolicySpec struct {
+	// Repository is the name of the SnapshotRepository, in the same namespace, snapshots are stored in.
+	Repository string `json:"repository"`
+
+	// Schedule is the periodic schedule, in cron syntax, at which snapshots are taken.
+	Schedule string `json:"schedule"`
+
+	// SnapshotName is the name automatically assigned to each snapshot. Supports date math.
+	// Defaults to <policy-name-{now/d}>.
+	// +kubebuilder:validation:Optional
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// Config holds the configuration of each snapshot (indices, include_global_state, ...).
+	// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/slm-api-put-policy.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Config *commonv1.Config `json:"config,omitempty"`
+
+	// Retention holds the rules used to delete snapshots taken by this policy.
+	// +kubebuilder:validation:Optional
+	Retention *SnapshotRetention `json:"retention,omitempty"`
+}
+
+// SnapshotRetention holds the retention rules of a snapshot lifecycle management policy.
+type SnapshotRetention struct {
+	// ExpireAfter is the time period after which snapshots are considered expired and eligible for deletion.
+	// +kubebuilder:validation:Optional
+	ExpireAfter string `json:"expireAfter,omitempty"`
+	// MinCount is the minimum number of snapshots to retain, even if the snapshots have expired.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MinCount *int32 `json:"minCount,omitempty"`
+	// MaxCount is the maximum number of snapshots to retain, even if the snapshots have not yet expired.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// SnapshotNameOrDefault returns the name assigned to snapshots taken by this policy.
+func (p SnapshotPolicy) SnapshotNameOrDefault() string {
+	if p.Spec.SnapshotName != "" {
+		return p.Spec.SnapshotName
+	}
+	return fmt.Sprintf("<%s-{now/d}>", p.Name)
+}
+
+// +kubebuilder:object:root=true
+
+// SnapshotPolicy represents a snapshot lifecycle management policy configured in an Elasticsearch cluster.
+// +kubebuilder:resource:categories=elastic,shortName=snappolicy
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="repository",type="string",JSONPath=".spec.repository"
+// +kubebuilder:printcolumn:name="schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type SnapshotPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotPolicySpec `json:"spec,omitempty"`
+	Status SnapshotStatus     `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SnapshotPolicyList contains a list of SnapshotPolicy
+type SnapshotPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotPolicy{}, &SnapshotPolicyList{})
+}
@@ -0,0 +1,105 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	// SnapshotRepositoryKind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	SnapshotRepositoryKind = "SnapshotRepository"
+)
+
+// RepositoryType is the type of storage backing a snapshot repository.
+type RepositoryType string
+
+const (
+	S3RepositoryType    RepositoryType = "s3"
+	GCSRepositoryType   RepositoryType = "gcs"
+	AzureRepositoryType RepositoryType = "azure"
+)
+
+// Phase is the phase of the reconciliation of a snapshot resource in the target Elasticsearch cluster.
+type Phase string
+
+const (
+	// PendingPhase is used while the target Elasticsearch cluster or repository is not ready to be configured.
+	PendingPhase Phase = "Pending"
+	// ReadyPhase is used once the resource has been applied to the target Elasticsearch cluster.
+	ReadyPhase Phase = "Ready"
+	// FailedPhase is used if the resource is invalid or was rejected by Elasticsearch.
+	FailedPhase Phase = "Failed"
+)
+
+// SnapshotRepositorySpec holds the specification of a snapshot repository registered in an Elasticsearch cluster.
+type SnapshotRepositorySpec struct {
+	// ElasticsearchRef is a reference to the Elasticsearch cluster the repository is registered in.
+	// The Elasticsearch cluster must be in the same namespace as the SnapshotRepository.
+	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef"`
+
+	// Type of the repository.
+	// +kubebuilder:validation:Enum=s3;gcs;azure
+	Type RepositoryType `json:"type"`
+
+	// Settings of the repository, as passed to the Elasticsearch snapshot repository API.
+	// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/put-snapshot-repo-api.html
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	Settings *commonv1.Config `json:"settings,omitempty"`
+
+	// SecureSettings is a list of references to Kubernetes secrets containing the repository client credentials.
+	// They are added to the keystore of the referenced Elasticsearch cluster.
+	// +kubebuilder:validation:Optional
+	SecureSettings []commonv1.SecretSource `json:"secureSettings,omitempty"`
+}
+
+// SnapshotStatus defines the observed state of a snapshot resource.
+type SnapshotStatus struct {
+	// Phase of the reconciliation of the resource in Elasticsearch.
+	Phase Phase `json:"phase,omitempty"`
+	// Message is a human readable description of the phase, set if the resource is not ready.
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the most recent generation observed for this resource.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ElasticsearchRef returns the reference to the Elasticsearch cluster, defaulting to the repository namespace.
+func (r SnapshotRepository) ElasticsearchRef() commonv1.ObjectSelector {
+	return r.Spec.ElasticsearchRef.WithDefaultNamespace(r.Namespace)
+}
+
+// +kubebuilder:object:root=true
+
+// SnapshotRepository represents a snapshot repository registered in an Elasticsearch cluster.
+// +kubebuilder:resource:categories=elastic,shortName=snaprepo
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type SnapshotRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotRepositorySpec `json:"spec,omitempty"`
+	Status SnapshotStatus         `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SnapshotRepositoryList contains a list of SnapshotRepository
+type SnapshotRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotRepository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotRepository{}, &SnapshotRepositoryList{})
+}
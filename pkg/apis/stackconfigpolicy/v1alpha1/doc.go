@@ -0,0 +1,9 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+// Package v1alpha1 contains API schema definitions for applying configuration policies to groups of Elastic Stack
+// resources.
+// +kubebuilder:object:generate=true
+// +groupName=stackconfigpolicy.k8s.elastic.co
+package v1alpha1
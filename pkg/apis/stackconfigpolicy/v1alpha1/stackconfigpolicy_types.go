@@ -0,0 +1,131 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	// StackConfigPolicyKind is inferred from the struct name using reflection in SchemeBuilder.Register()
+	// we duplicate it as a constant here for practical purposes.
+	StackConfigPolicyKind = "StackConfigPolicy"
+)
+
+// PolicyPhase is the phase of the application of a StackConfigPolicy.
+type PolicyPhase string
+
+const (
+	// ReadyPhase is used once the policy has been applied to all the selected resources.
+	ReadyPhase PolicyPhase = "Ready"
+	// ApplyingChangesPhase is used while some selected resources are not ready to be configured yet.
+	ApplyingChangesPhase PolicyPhase = "ApplyingChanges"
+	// ConflictPhase is used for resources selected by several policies, which are not configured.
+	ConflictPhase PolicyPhase = "Conflict"
+	// ErrorPhase is used if the policy could not be applied to some selected resources.
+	ErrorPhase PolicyPhase = "Error"
+)
+
+// StackConfigPolicySpec holds the configuration applied to the selected Elasticsearch clusters.
+type StackConfigPolicySpec struct {
+	// ResourceSelector is a label selector for the Elasticsearch clusters the policy applies to. A policy created in the
+	// namespace of the operator applies to the clusters of all the managed namespaces, any other policy to the clusters
+	// of its namespace only. An empty selector selects all the clusters.
+	// +kubebuilder:validation:Optional
+	ResourceSelector metav1.LabelSelector `json:"resourceSelector,omitempty"`
+
+	// SecureSettings is a list of references to Kubernetes secrets in the namespace of the policy, whose entries are
+	// added to the keystore of the selected Elasticsearch clusters.
+	// +kubebuilder:validation:Optional
+	SecureSettings []commonv1.SecretSource `json:"secureSettings,omitempty"`
+
+	// Elasticsearch holds the configuration applied to the selected Elasticsearch clusters.
+	// +kubebuilder:validation:Optional
+	Elasticsearch ElasticsearchConfigPolicySpec `json:"elasticsearch,omitempty"`
+}
+
+// ElasticsearchConfigPolicySpec holds the configuration applied to Elasticsearch clusters through their APIs.
+// The settings and objects are not removed from the clusters when the policy no longer applies to them.
+type ElasticsearchConfigPolicySpec struct {
+	// ClusterSettings are the persistent cluster settings set with the cluster settings API.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	ClusterSettings *commonv1.Config `json:"clusterSettings,omitempty"`
+
+	// SnapshotRepositories maps the names of snapshot repositories to their definition, as accepted by the snapshot
+	// repository API. Their credentials can be provided with the SecureSettings of the policy.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	SnapshotRepositories *commonv1.Config `json:"snapshotRepositories,omitempty"`
+
+	// SecurityRoleMappings maps the names of role mappings to their definition, as accepted by the role mapping API.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Optional
+	SecurityRoleMappings *commonv1.Config `json:"securityRoleMappings,omitempty"`
+}
+
+// ResourcePolicyStatus is the status of the application of a policy to a resource.
+type ResourcePolicyStatus struct {
+	// Phase of the application of the policy to the resource.
+	Phase PolicyPhase `json:"phase,omitempty"`
+	// Message is a human readable description of the phase, set if the policy is not applied.
+	Message string `json:"message,omitempty"`
+}
+
+// StackConfigPolicyStatus defines the observed state of a StackConfigPolicy.
+type StackConfigPolicyStatus struct {
+	// ResourcesStatuses holds the status of the application of the policy to each selected resource, indexed by
+	// <namespace>/<name>.
+	ResourcesStatuses map[string]ResourcePolicyStatus `json:"resourcesStatuses,omitempty"`
+	// Resources is the number of resources the policy applies to.
+	Resources int `json:"resources,omitempty"`
+	// Ready is the number of resources the policy has been applied to.
+	Ready int `json:"ready,omitempty"`
+	// Errors is the number of resources the policy could not be applied to.
+	Errors int `json:"errors,omitempty"`
+	// Phase of the application of the policy, aggregated from the resources statuses.
+	Phase PolicyPhase `json:"phase,omitempty"`
+	// ObservedGeneration is the most recent generation observed for this policy.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ResourceKey returns the key of the given resource in the ResourcesStatuses.
+func ResourceKey(nsn types.NamespacedName) string {
+	return nsn.String()
+}
+
+// +kubebuilder:object:root=true
+
+// StackConfigPolicy applies settings, snapshot repositories, role mappings and keystore entries to a group of
+// Elasticsearch clusters.
+// +kubebuilder:resource:categories=elastic,shortName=scp
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ready",type="integer",JSONPath=".status.ready",description="Resources configured"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type StackConfigPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StackConfigPolicySpec   `json:"spec,omitempty"`
+	Status StackConfigPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StackConfigPolicyList contains a list of StackConfigPolicy
+type StackConfigPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StackConfigPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StackConfigPolicy{}, &StackConfigPolicyList{})
+}
@@ -25,6 +25,8 @@ import (
 
 type connectionSettings struct {
 	host, ca, username, password string
+	// isServiceAccount is true if the password is a service account token, the username can then be ignored.
+	isServiceAccount bool
 }
 
 func reconcileConfig(params Params, configHash hash.Hash) *reconciler.Results {
@@ -47,7 +49,7 @@ func reconcileConfig(params Params, configHash hash.Hash) *reconciler.Results {
 		},
 	}
 
-	if _, err = reconciler.ReconcileSecret(params.Client, expected, &params.Agent); err != nil {
+	if _, err = reconciler.ReconcileSecret(params.Client, expected, &params.Agent, params.OperatorParams.ServerSideApply); err != nil {
 		return results.WithError(err)
 	}
 
@@ -163,9 +165,10 @@ func extractConnectionSettings(
 	}
 
 	return connectionSettings{
-		host:     assoc.AssociationConf().GetURL(),
-		ca:       ca,
-		username: username,
-		password: password,
+		host:             assoc.AssociationConf().GetURL(),
+		ca:               ca,
+		username:         username,
+		password:         password,
+		isServiceAccount: assoc.AssociationConf().GetIsServiceAccount(),
 	}, err
 }
@@ -53,6 +53,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileAg
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		Parameters:     params,
 	}
 }
@@ -114,6 +115,8 @@ type ReconcileAgent struct {
 	k8s.Client
 	recorder       record.EventRecorder
 	dynamicWatches watches.DynamicWatches
+	// statusWriter coalesces successive status updates of the same Agent.
+	statusWriter *common.StatusWriter
 	operator.Parameters
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
@@ -140,6 +143,11 @@ func (r *ReconcileAgent) Reconcile(ctx context.Context, request reconcile.Reques
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&agent) {
+		logconf.FromContext(ctx).Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation")
+		return reconcile.Result{}, nil
+	}
+
 	if agent.IsMarkedForDeletion() {
 		return reconcile.Result{}, nil
 	}
@@ -167,6 +175,7 @@ func (r *ReconcileAgent) doReconcile(ctx context.Context, agent agentv1alpha1.Ag
 		Client:         r.Client,
 		EventRecorder:  r.recorder,
 		Watches:        r.dynamicWatches,
+		StatusWriter:   r.statusWriter,
 		Agent:          agent,
 		OperatorParams: r.Parameters,
 	})
@@ -189,4 +198,5 @@ func (r *ReconcileAgent) validate(ctx context.Context, agent agentv1alpha1.Agent
 func (r *ReconcileAgent) onDelete(obj types.NamespacedName) {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(obj))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(common.ConfigRefWatchName(obj))
+	r.statusWriter.Forget(obj)
 }
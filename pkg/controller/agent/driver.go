@@ -40,6 +40,8 @@ type Params struct {
 	Client        k8s.Client
 	EventRecorder record.EventRecorder
 	Watches       watches.DynamicWatches
+	// StatusWriter coalesces successive status updates of the same Agent.
+	StatusWriter *common.StatusWriter
 
 	Agent agentv1alpha1.Agent
 
@@ -102,6 +104,7 @@ func internalReconcile(params Params) *reconciler.Results {
 			CACertRotation:        params.OperatorParams.CACertRotation,
 			CertRotation:          params.OperatorParams.CertRotation,
 			GarbageCollectSecrets: true,
+			ServerSideApply:       params.OperatorParams.ServerSideApply,
 			ExtraHTTPSANs:         []commonv1.SubjectAlternativeName{{DNS: fmt.Sprintf("*.%s.%s.svc", HTTPServiceName(params.Agent.Name), params.Agent.Namespace)}},
 		}.ReconcileCAAndHTTPCerts(params.Context)
 		if caResults.HasError() {
@@ -141,7 +144,7 @@ func reconcileService(params Params) (*corev1.Service, error) {
 		return nil, nil
 	}
 
-	return common.ReconcileService(params.Context, params.Client, svc, &params.Agent)
+	return common.ReconcileService(params.Context, params.Client, svc, &params.Agent, params.OperatorParams.ServerSideApply)
 }
 
 func newService(agent agentv1alpha1.Agent) *corev1.Service {
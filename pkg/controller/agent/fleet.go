@@ -0,0 +1,237 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+const (
+	// EnrollmentAPIKeysPath is the HTTP path of the Kibana Fleet API managing the enrollment tokens.
+	EnrollmentAPIKeysPath = "/api/fleet/enrollment_api_keys"
+	// LegacyEnrollmentAPIKeysPath is the HTTP path of the Kibana Fleet API managing the enrollment tokens prior to
+	// Kibana 8.0.
+	LegacyEnrollmentAPIKeysPath = "/api/fleet/enrollment-api-keys"
+	// FleetAPIReqTimeout is the duration after which a request to the Kibana Fleet API should be canceled.
+	FleetAPIReqTimeout = 1 * time.Minute
+)
+
+// EnrollmentAPIKey is an enrollment token used by Elastic Agents to enroll into a given Agent policy.
+type EnrollmentAPIKey struct {
+	ID       string `json:"id"`
+	Active   bool   `json:"active"`
+	APIKey   string `json:"api_key"`
+	PolicyID string `json:"policy_id"`
+}
+
+// EnrollmentAPIKeyList is the response of the Kibana Fleet API listing the enrollment tokens. Kibana versions
+// prior to 8.0 return the enrollment tokens in the list field, later versions in the items field.
+type EnrollmentAPIKeyList struct {
+	Items []EnrollmentAPIKey `json:"items"`
+	List  []EnrollmentAPIKey `json:"list"`
+}
+
+// EnrollmentAPIKeyResult is the response of the Kibana Fleet API creating an enrollment token.
+type EnrollmentAPIKeyResult struct {
+	Item EnrollmentAPIKey `json:"item"`
+}
+
+// enrollmentAPIKeysPathVersion is the first Kibana version serving the enrollment tokens on EnrollmentAPIKeysPath.
+var enrollmentAPIKeysPathVersion = version.MustParse("8.0.0")
+
+// fleetAPI is a minimal client of the Kibana Fleet API.
+type fleetAPI struct {
+	client                *http.Client
+	endpoint              string
+	username              string
+	password              string
+	enrollmentAPIKeysPath string
+}
+
+// enrollmentAPIKeysPathFor returns the HTTP path of the Kibana Fleet API managing the enrollment tokens in the given
+// Kibana version.
+func enrollmentAPIKeysPathFor(kibanaVersion version.Version) string {
+	if kibanaVersion.LT(enrollmentAPIKeysPathVersion) {
+		return LegacyEnrollmentAPIKeysPath
+	}
+	return EnrollmentAPIKeysPath
+}
+
+// newFleetAPI builds a client of the Kibana Fleet API from the Kibana association of the Agent.
+func newFleetAPI(params Params) (fleetAPI, error) {
+	assoc, err := association.SingleAssociationOfType(params.Agent.GetAssociations(), commonv1.KibanaAssociationType)
+	if err != nil {
+		return fleetAPI{}, err
+	}
+	if assoc == nil || !assoc.AssociationConf().IsConfigured() {
+		return fleetAPI{}, fmt.Errorf("association to Kibana is not configured for agent %s/%s", params.Agent.Namespace, params.Agent.Name)
+	}
+
+	// the version of Kibana is reported once it is running, assume it matches the version of the Agent until then
+	kibanaVersion := assoc.AssociationConf().GetVersion()
+	if kibanaVersion == "" {
+		kibanaVersion = params.Agent.Spec.Version
+	}
+	ver, err := version.Parse(kibanaVersion)
+	if err != nil {
+		return fleetAPI{}, err
+	}
+
+	username, password, err := association.ElasticsearchAuthSettings(params.Client, assoc)
+	if err != nil {
+		return fleetAPI{}, err
+	}
+
+	var caCerts []*x509.Certificate
+	if assoc.AssociationConf().GetCACertProvided() {
+		var caSecret corev1.Secret
+		nsn := types.NamespacedName{Namespace: params.Agent.Namespace, Name: assoc.AssociationConf().GetCASecretName()}
+		if err := params.Client.Get(params.Context, nsn, &caSecret); err != nil {
+			return fleetAPI{}, err
+		}
+		caCerts, err = certificates.ParsePEMCerts(caSecret.Data[CAFileName])
+		if err != nil {
+			return fleetAPI{}, err
+		}
+	}
+
+	return fleetAPI{
+		client:                common.HTTPClient(params.OperatorParams.Dialer, caCerts, FleetAPIReqTimeout),
+		endpoint:              assoc.AssociationConf().GetURL(),
+		username:              username,
+		password:              password,
+		enrollmentAPIKeysPath: enrollmentAPIKeysPathFor(ver),
+	}, nil
+}
+
+// request performs a request to the Kibana Fleet API and decodes the JSON response into result.
+func (f fleetAPI) request(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, FleetAPIReqTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(timeoutCtx, method, stringsutil.Concat(f.endpoint, path), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	// required by Kibana for all requests that are not GET requests
+	req.Header.Set("kbn-xsrf", "true")
+	req.SetBasicAuth(f.username, f.password)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("invalid Fleet API response (status code %d) for %s %s: %s", resp.StatusCode, method, path, respBody)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// findEnrollmentAPIKey returns an active enrollment token of the given Agent policy, if any.
+func (f fleetAPI) findEnrollmentAPIKey(ctx context.Context, policyID string) (*EnrollmentAPIKey, error) {
+	query := url.Values{}
+	query.Set("perPage", "1000")
+	query.Set("kuery", fmt.Sprintf("policy_id:%q", policyID))
+
+	var result EnrollmentAPIKeyList
+	if err := f.request(ctx, http.MethodGet, f.enrollmentAPIKeysPath+"?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+
+	keys := result.Items
+	if len(keys) == 0 {
+		keys = result.List
+	}
+	for i := range keys {
+		if keys[i].Active && keys[i].PolicyID == policyID {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// createEnrollmentAPIKey creates a new enrollment token for the given Agent policy.
+func (f fleetAPI) createEnrollmentAPIKey(ctx context.Context, policyID string) (EnrollmentAPIKey, error) {
+	var result EnrollmentAPIKeyResult
+	body := map[string]string{"policy_id": policyID}
+	if err := f.request(ctx, http.MethodPost, f.enrollmentAPIKeysPath, body, &result); err != nil {
+		return EnrollmentAPIKey{}, err
+	}
+	return result.Item, nil
+}
+
+// reconcileEnrollmentToken returns the enrollment token of the given Agent policy, creating it if it does not exist yet.
+func (f fleetAPI) reconcileEnrollmentToken(ctx context.Context, policyID string) (string, error) {
+	key, err := f.findEnrollmentAPIKey(ctx, policyID)
+	if err != nil {
+		return "", err
+	}
+	if key != nil {
+		return key.APIKey, nil
+	}
+
+	created, err := f.createEnrollmentAPIKey(ctx, policyID)
+	if err != nil {
+		return "", err
+	}
+	return created.APIKey, nil
+}
+
+// getFleetEnrollmentEnvVars returns the enrollment token the Elastic Agent uses to enroll into the Agent policy
+// specified in its spec. Fleet Server enrolls itself with the policy passed through FLEET_SERVER_POLICY_ID instead.
+func getFleetEnrollmentEnvVars(params Params) (map[string]string, error) {
+	spec := params.Agent.Spec
+	if spec.PolicyID == "" || spec.FleetServerEnabled || !spec.KibanaRef.IsDefined() {
+		return map[string]string{}, nil
+	}
+
+	api, err := newFleetAPI(params)
+	if err != nil {
+		return nil, err
+	}
+	defer api.client.CloseIdleConnections()
+
+	token, err := api.reconcileEnrollmentToken(params.Context, spec.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{FleetEnrollmentToken: token}, nil
+}
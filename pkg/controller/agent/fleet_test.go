@@ -0,0 +1,224 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/agent/v1alpha1"
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// fakeFleetServer returns a Kibana Fleet API test server answering the enrollment tokens requests on the given path
+// with the given list response, and recording the policies enrollment tokens have been created for.
+func fakeFleetServer(t *testing.T, path string, list string, created *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, path, r.URL.Path)
+		require.Equal(t, "true", r.Header.Get("kbn-xsrf"))
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "elastic", username)
+		require.Equal(t, "password", password)
+
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(list))
+		case http.MethodPost:
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*created = append(*created, body["policy_id"])
+			_, _ = w.Write([]byte(`{"item":{"id":"new","active":true,"api_key":"new-token","policy_id":"` + body["policy_id"] + `"}}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func Test_fleetAPI_reconcileEnrollmentToken(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		path        string
+		list        string
+		wantToken   string
+		wantCreated []string
+		wantErr     bool
+	}{
+		{
+			name:        "active token exists",
+			list:        `{"items":[{"id":"1","active":true,"api_key":"other-token","policy_id":"other"},{"id":"2","active":true,"api_key":"token","policy_id":"policy"}]}`,
+			wantToken:   "token",
+			wantCreated: nil,
+		},
+		{
+			name:        "active token exists in the list field of Kibana 7.x",
+			path:        LegacyEnrollmentAPIKeysPath,
+			list:        `{"list":[{"id":"2","active":true,"api_key":"token","policy_id":"policy"}],"total":1,"page":1,"perPage":1000}`,
+			wantToken:   "token",
+			wantCreated: nil,
+		},
+		{
+			name:        "no token exists in Kibana 7.x",
+			path:        LegacyEnrollmentAPIKeysPath,
+			list:        `{"list":[],"total":0,"page":1,"perPage":1000}`,
+			wantToken:   "new-token",
+			wantCreated: []string{"policy"},
+		},
+		{
+			name:        "only inactive tokens exist",
+			list:        `{"items":[{"id":"2","active":false,"api_key":"token","policy_id":"policy"}]}`,
+			wantToken:   "new-token",
+			wantCreated: []string{"policy"},
+		},
+		{
+			name:        "no token exists",
+			list:        `{"items":[]}`,
+			wantToken:   "new-token",
+			wantCreated: []string{"policy"},
+		},
+		{
+			name:    "invalid response",
+			list:    `not json`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = EnrollmentAPIKeysPath
+			}
+			var created []string
+			server := fakeFleetServer(t, path, tt.list, &created)
+			defer server.Close()
+
+			api := fleetAPI{client: server.Client(), endpoint: server.URL, username: "elastic", password: "password", enrollmentAPIKeysPath: path}
+			token, err := api.reconcileEnrollmentToken(context.Background(), "policy")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantToken, token)
+			require.Equal(t, tt.wantCreated, created)
+		})
+	}
+}
+
+func Test_fleetAPI_request_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("forbidden"))
+	}))
+	defer server.Close()
+
+	api := fleetAPI{client: server.Client(), endpoint: server.URL, enrollmentAPIKeysPath: EnrollmentAPIKeysPath}
+	_, err := api.reconcileEnrollmentToken(context.Background(), "policy")
+	require.EqualError(t, err, `invalid Fleet API response (status code 403) for GET /api/fleet/enrollment_api_keys?kuery=policy_id%3A%22policy%22&perPage=1000: forbidden`)
+}
+
+func Test_getFleetEnrollmentEnvVars(t *testing.T) {
+	var created []string
+	server := fakeFleetServer(t, EnrollmentAPIKeysPath, `{"items":[{"id":"1","active":true,"api_key":"token","policy_id":"policy"}]}`, &created)
+	defer server.Close()
+	legacyServer := fakeFleetServer(t, LegacyEnrollmentAPIKeysPath, `{"list":[{"id":"1","active":true,"api_key":"legacy-token","policy_id":"policy"}]}`, &created)
+	defer legacyServer.Close()
+
+	agentFixture := func(policyID string, fleetServerEnabled bool, withKibanaRef bool) agentv1alpha1.Agent {
+		agent := agentv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "ns"},
+			Spec: agentv1alpha1.AgentSpec{
+				Version:            "8.1.0",
+				Mode:               agentv1alpha1.AgentFleetMode,
+				FleetServerEnabled: fleetServerEnabled,
+				PolicyID:           policyID,
+			},
+		}
+		if withKibanaRef {
+			agent.Spec.KibanaRef = commonv1.ObjectSelector{Name: "kibana", Namespace: "ns"}
+			agent.GetAssociations()[0].SetAssociationConf(&commonv1.AssociationConf{
+				AuthSecretName: "agent-kibana-user",
+				AuthSecretKey:  "elastic",
+				URL:            server.URL,
+				Version:        "8.1.0",
+			})
+		}
+		return agent
+	}
+	client := k8s.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-kibana-user", Namespace: "ns"},
+		Data:       map[string][]byte{"elastic": []byte("password")},
+	})
+
+	for _, tt := range []struct {
+		name        string
+		agent       agentv1alpha1.Agent
+		wantEnvVars map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "no policy ID",
+			agent:       agentFixture("", false, true),
+			wantEnvVars: map[string]string{},
+		},
+		{
+			name:        "fleet server enrolls with its policy ID",
+			agent:       agentFixture("policy", true, true),
+			wantEnvVars: map[string]string{},
+		},
+		{
+			name:        "no Kibana reference",
+			agent:       agentFixture("policy", false, false),
+			wantEnvVars: map[string]string{},
+		},
+		{
+			name:        "elastic agent with a policy ID",
+			agent:       agentFixture("policy", false, true),
+			wantEnvVars: map[string]string{FleetEnrollmentToken: "token"},
+		},
+		{
+			name: "Kibana 7.x serves the enrollment tokens on another path",
+			agent: func() agentv1alpha1.Agent {
+				agent := agentFixture("policy", false, true)
+				agent.Spec.Version = "7.17.3"
+				agent.GetAssociations()[0].SetAssociationConf(&commonv1.AssociationConf{
+					AuthSecretName: "agent-kibana-user",
+					AuthSecretKey:  "elastic",
+					URL:            legacyServer.URL,
+					Version:        "7.17.3",
+				})
+				return agent
+			}(),
+			wantEnvVars: map[string]string{FleetEnrollmentToken: "legacy-token"},
+		},
+		{
+			name: "Kibana association not configured yet",
+			agent: func() agentv1alpha1.Agent {
+				agent := agentFixture("policy", false, false)
+				agent.Spec.KibanaRef = commonv1.ObjectSelector{Name: "kibana", Namespace: "ns"}
+				return agent
+			}(),
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getFleetEnrollmentEnvVars(Params{Context: context.Background(), Client: client, Agent: tt.agent})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEnvVars, got)
+		})
+	}
+}
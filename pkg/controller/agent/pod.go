@@ -49,6 +49,12 @@ const (
 	DataMountHostPathTemplate = "/var/lib/%s/%s/agent-data"
 	DataMountPath             = "/usr/share/data"
 
+	// Windows nodes do not support mounting single files nor Linux host paths: the configuration Secret is mounted
+	// as a directory and Agent data is persisted under C:\ProgramData on the host.
+	WindowsConfigMountPath           = `C:\ProgramData\Elastic\Agent\config`
+	WindowsDataMountHostPathTemplate = `C:\ProgramData\Elastic\Agent\%s\%s\agent-data`
+	WindowsDataMountPath             = `C:\ProgramData\Elastic\Agent\data`
+
 	// ConfigChecksumLabel is a label used to store Agent config checksum.
 	ConfigChecksumLabel = "agent.k8s.elastic.co/config-checksum"
 
@@ -63,19 +69,22 @@ const (
 	KibanaFleetCA       = "KIBANA_FLEET_CA"
 
 	// Below are the names of environment variables used to configure Elastic Agent to Fleet connection in Fleet mode.
-	FleetEnroll = "FLEET_ENROLL"
-	FleetCA     = "FLEET_CA"
-	FleetURL    = "FLEET_URL"
+	FleetEnroll          = "FLEET_ENROLL"
+	FleetEnrollmentToken = "FLEET_ENROLLMENT_TOKEN" //nolint:gosec
+	FleetCA              = "FLEET_CA"
+	FleetURL             = "FLEET_URL"
 
 	// Below are the names of environment variables used to configure Fleet Server and its connection to Elasticsearch
 	// in Fleet mode.
 	FleetServerEnable                = "FLEET_SERVER_ENABLE"
 	FleetServerCert                  = "FLEET_SERVER_CERT"
 	FleetServerCertKey               = "FLEET_SERVER_CERT_KEY"
+	FleetServerPolicyID              = "FLEET_SERVER_POLICY_ID"
 	FleetServerElasticsearchHost     = "FLEET_SERVER_ELASTICSEARCH_HOST"
 	FleetServerElasticsearchUsername = "FLEET_SERVER_ELASTICSEARCH_USERNAME"
 	FleetServerElasticsearchPassword = "FLEET_SERVER_ELASTICSEARCH_PASSWORD" //nolint:gosec
 	FleetServerElasticsearchCA       = "FLEET_SERVER_ELASTICSEARCH_CA"
+	FleetServerServiceToken          = "FLEET_SERVER_SERVICE_TOKEN" //nolint:gosec
 )
 
 var (
@@ -107,24 +116,36 @@ var (
 	secretEnvVarNames = map[string]struct{}{
 		KibanaFleetUsername:              {},
 		KibanaFleetPassword:              {},
+		FleetEnrollmentToken:             {},
 		FleetServerElasticsearchUsername: {},
 		FleetServerElasticsearchPassword: {},
+		FleetServerServiceToken:          {},
 	}
 )
 
 func buildPodTemplate(params Params, fleetCerts *certificates.CertificatesSecret, configHash hash.Hash) (corev1.PodTemplateSpec, error) {
 	defer tracing.Span(&params.Context)()
 	spec := &params.Agent.Spec
+	windows := commonv1.IsWindowsPodTemplate(params.GetPodTemplate())
 	builder := defaults.NewPodTemplateBuilder(params.GetPodTemplate(), ContainerName)
-	vols := []volume.VolumeLike{
-		// volume with agent configuration file
-		volume.NewSecretVolume(
+	configPath := path.Join(ConfigMountPath, ConfigFileName)
+	// volume with agent configuration file
+	configVolume := volume.NewSecretVolume(
+		ConfigSecretName(params.Agent.Name),
+		ConfigVolumeName,
+		configPath,
+		ConfigFileName,
+		0440)
+	if windows {
+		configPath = WindowsConfigMountPath + `\` + ConfigFileName
+		configVolume = volume.NewSecretVolume(
 			ConfigSecretName(params.Agent.Name),
 			ConfigVolumeName,
-			path.Join(ConfigMountPath, ConfigFileName),
-			ConfigFileName,
-			0440),
+			WindowsConfigMountPath,
+			"",
+			0440)
 	}
+	vols := []volume.VolumeLike{configVolume}
 
 	// fleet mode requires some special treatment
 	if spec.FleetModeEnabled() {
@@ -140,10 +161,10 @@ func buildPodTemplate(params Params, fleetCerts *certificates.CertificatesSecret
 
 		builder = builder.
 			WithResources(defaultResources).
-			WithArgs("-e", "-c", path.Join(ConfigMountPath, ConfigFileName))
+			WithArgs("-e", "-c", configPath)
 
 		// volume with agent data path
-		vols = append(vols, createDataVolume(params))
+		vols = append(vols, createDataVolume(params, windows))
 	}
 
 	// all volumes with CAs of direct associations
@@ -175,7 +196,7 @@ func amendBuilderForFleetMode(params Params, fleetCerts *certificates.Certificat
 		return nil, err
 	}
 
-	builder, err = applyRelatedEsAssoc(params.Agent, esAssociation, builder)
+	builder, err = applyRelatedEsAssoc(params.Agent, esAssociation, commonv1.IsWindowsPodTemplate(builder.PodTemplate), builder)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +237,12 @@ func applyEnvVars(params Params, builder *defaults.PodTemplateBuilder) (*default
 		return nil, err
 	}
 
+	enrollmentEnvVars, err := getFleetEnrollmentEnvVars(params)
+	if err != nil {
+		return nil, err
+	}
+	fleetModeEnvVars = maps.Merge(fleetModeEnvVars, enrollmentEnvVars)
+
 	type tuple struct{ k, v string }
 	sortedVars := make([]tuple, 0, len(fleetModeEnvVars))
 	for k, v := range fleetModeEnvVars {
@@ -254,7 +281,7 @@ func applyEnvVars(params Params, builder *defaults.PodTemplateBuilder) (*default
 		if err := cleanupEnvVarsSecret(params); err != nil {
 			return nil, err
 		}
-	} else if _, err := reconciler.ReconcileSecret(params.Client, envVarsSecret, &params.Agent); err != nil {
+	} else if _, err := reconciler.ReconcileSecret(params.Client, envVarsSecret, &params.Agent, params.OperatorParams.ServerSideApply); err != nil {
 		return nil, err
 	}
 
@@ -291,7 +318,7 @@ func getRelatedEsAssoc(params Params) (commonv1.Association, error) {
 	return esAssociation, nil
 }
 
-func applyRelatedEsAssoc(agent agentv1alpha1.Agent, esAssociation commonv1.Association, builder *defaults.PodTemplateBuilder) (*defaults.PodTemplateBuilder, error) {
+func applyRelatedEsAssoc(agent agentv1alpha1.Agent, esAssociation commonv1.Association, windows bool, builder *defaults.PodTemplateBuilder) (*defaults.PodTemplateBuilder, error) {
 	if esAssociation == nil {
 		return builder, nil
 	}
@@ -314,7 +341,10 @@ func applyRelatedEsAssoc(agent agentv1alpha1.Agent, esAssociation commonv1.Assoc
 	// to trust. There is currently no way to configure those Beats to trust a particular CA. The intended way to handle
 	// it is to allow Fleet to provide Beat output settings, but due to https://github.com/elastic/kibana/issues/102794
 	// this is not supported outside of UI. To workaround this limitation the Agent is going to update Pod-wide CA store
-	// before starting Elastic Agent.
+	// before starting Elastic Agent. Windows images have no such script, the CA remains available in the mounted volume.
+	if windows {
+		return builder, nil
+	}
 	cmd := trustCAScript(path.Join(certificatesDir(esAssociation), CAFileName))
 	return builder.WithCommand([]string{"/usr/bin/env", "bash", "-c", cmd}), nil
 }
@@ -388,13 +418,18 @@ fi
 `, caPath)
 }
 
-func createDataVolume(params Params) volume.VolumeLike {
+func createDataVolume(params Params, windows bool) volume.VolumeLike {
 	dataMountHostPath := fmt.Sprintf(DataMountHostPathTemplate, params.Agent.Namespace, params.Agent.Name)
+	dataMountPath := DataMountPath
+	if windows {
+		dataMountHostPath = fmt.Sprintf(WindowsDataMountHostPathTemplate, params.Agent.Namespace, params.Agent.Name)
+		dataMountPath = WindowsDataMountPath
+	}
 
 	return volume.NewHostVolume(
 		DataVolumeName,
 		dataMountHostPath,
-		DataMountPath,
+		dataMountPath,
 		false,
 		corev1.HostPathDirectoryOrCreate)
 }
@@ -498,6 +533,10 @@ func getFleetSetupFleetServerEnvVars(agent agentv1alpha1.Agent, client k8s.Clien
 		FleetServerCertKey: path.Join(FleetCertsMountPath, certificates.KeyFileName),
 	}
 
+	if agent.Spec.PolicyID != "" {
+		fleetServerCfg[FleetServerPolicyID] = agent.Spec.PolicyID
+	}
+
 	esExpected := len(agent.Spec.ElasticsearchRefs) > 0 && agent.Spec.ElasticsearchRefs[0].IsDefined()
 	if esExpected {
 		esConnectionSettings, err := extractConnectionSettings(agent, client, commonv1.ElasticsearchAssociationType)
@@ -506,8 +545,12 @@ func getFleetSetupFleetServerEnvVars(agent agentv1alpha1.Agent, client k8s.Clien
 		}
 
 		fleetServerCfg[FleetServerElasticsearchHost] = esConnectionSettings.host
-		fleetServerCfg[FleetServerElasticsearchUsername] = esConnectionSettings.username
-		fleetServerCfg[FleetServerElasticsearchPassword] = esConnectionSettings.password
+		if esConnectionSettings.isServiceAccount {
+			fleetServerCfg[FleetServerServiceToken] = esConnectionSettings.password
+		} else {
+			fleetServerCfg[FleetServerElasticsearchUsername] = esConnectionSettings.username
+			fleetServerCfg[FleetServerElasticsearchPassword] = esConnectionSettings.password
+		}
 
 		// don't set ca key if ca is not available
 		if esConnectionSettings.ca != "" {
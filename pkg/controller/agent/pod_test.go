@@ -613,6 +613,7 @@ func Test_applyRelatedEsAssoc(t *testing.T) {
 		name        string
 		agent       agentv1alpha1.Agent
 		assoc       commonv1.Association
+		windows     bool
 		wantPodSpec corev1.PodSpec
 		wantErr     bool
 	}{
@@ -681,10 +682,45 @@ fi
 			assoc:   assocToOtherNs,
 			wantErr: true,
 		},
+		{
+			name: "windows nodes, CA not added to the trust store",
+			agent: agentv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "agent",
+					Namespace: agentNs,
+				},
+			},
+			assoc:   assocToSameNs,
+			windows: true,
+			wantErr: false,
+			wantPodSpec: generatePodSpec(func(ps corev1.PodSpec) corev1.PodSpec {
+				ps.Volumes = []corev1.Volume{
+					{
+						Name: "elasticsearch-certs",
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{
+								SecretName: "elasticsearch-es-http-certs-public",
+								Optional:   &optional,
+							},
+						},
+					},
+				}
+
+				ps.Containers[0].VolumeMounts = []corev1.VolumeMount{
+					{
+						Name:      "elasticsearch-certs",
+						ReadOnly:  true,
+						MountPath: "/mnt/elastic-internal/elasticsearch-association/agent-ns/elasticsearch/certs",
+					},
+				}
+
+				return ps
+			}),
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			builder := generateBuilder()
-			gotBuilder, gotErr := applyRelatedEsAssoc(tt.agent, tt.assoc, builder)
+			gotBuilder, gotErr := applyRelatedEsAssoc(tt.agent, tt.assoc, tt.windows, builder)
 
 			require.Equal(t, tt.wantErr, gotErr != nil)
 			if !tt.wantErr {
@@ -1118,6 +1154,23 @@ func Test_getFleetSetupFleetServerEnvVars(t *testing.T) {
 			},
 			client: nil,
 		},
+		{
+			name: "fleet server enabled, with policy ID",
+			agent: agentv1alpha1.Agent{
+				Spec: agentv1alpha1.AgentSpec{
+					FleetServerEnabled: true,
+					PolicyID:           "fleet-server-policy",
+				},
+			},
+			wantErr: false,
+			wantEnvVars: map[string]string{
+				"FLEET_SERVER_ENABLE":    "true",
+				"FLEET_SERVER_CERT":      path.Join(FleetCertsMountPath, certificates.CertFileName),
+				"FLEET_SERVER_CERT_KEY":  path.Join(FleetCertsMountPath, certificates.KeyFileName),
+				"FLEET_SERVER_POLICY_ID": "fleet-server-policy",
+			},
+			client: nil,
+		},
 		{
 			name:    "fleet server enabled, elasticsearch ref, no elasticsearch ca",
 			agent:   agentWithoutCa,
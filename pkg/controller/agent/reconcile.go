@@ -6,6 +6,8 @@ package agent
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -59,6 +61,7 @@ func reconcilePodVehicle(params Params, podTemplate corev1.PodTemplateSpec) *rec
 		client:      params.Client,
 		agent:       params.Agent,
 		podTemplate: podTemplate,
+		restricted:  params.OperatorParams.RestrictedSecurityContext,
 	})
 
 	if err != nil {
@@ -75,11 +78,15 @@ func reconcilePodVehicle(params Params, podTemplate corev1.PodTemplateSpec) *rec
 		results.WithError(err)
 	}
 
-	err = updateStatus(params, ready, desired)
+	requeueAfter, err := updateStatus(params, ready, desired)
 	if err != nil && apierrors.IsConflict(err) {
 		params.Logger().V(1).Info("Conflict while updating status")
 		return results.WithResult(reconcile.Result{Requeue: true})
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 
 	return results.WithError(err)
 }
@@ -98,7 +105,7 @@ func reconcileDeployment(rp ReconciliationParams) (int32, int32, error) {
 		return 0, 0, err
 	}
 
-	reconciled, err := deployment.Reconcile(rp.client, d, &rp.agent)
+	reconciled, err := deployment.Reconcile(rp.client, d, &rp.agent, rp.restricted)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -120,7 +127,7 @@ func reconcileDaemonSet(rp ReconciliationParams) (int32, int32, error) {
 		return 0, 0, err
 	}
 
-	reconciled, err := daemonset.Reconcile(rp.client, ds, &rp.agent)
+	reconciled, err := daemonset.Reconcile(rp.client, ds, &rp.agent, rp.restricted)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -132,19 +139,26 @@ type ReconciliationParams struct {
 	client      k8s.Client
 	agent       agentv1alpha1.Agent
 	podTemplate corev1.PodTemplateSpec
+	restricted  bool
 }
 
-func updateStatus(params Params, ready, desired int32) error {
+// updateStatus writes the status of the Agent resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func updateStatus(params Params, ready, desired int32) (time.Duration, error) {
 	agent := params.Agent
 
 	pods, err := k8s.PodsMatchingLabels(params.Client, agent.Namespace, map[string]string{NameLabelName: agent.Name})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	agent.Status.AvailableNodes = ready
 	agent.Status.ExpectedNodes = desired
 	agent.Status.Health = CalculateHealth(agent.GetAssociations(), ready, desired)
 	agent.Status.Version = common.LowestVersionFromPods(agent.Status.Version, pods, VersionLabelName)
 
-	return params.Client.Status().Update(context.Background(), &agent)
+	if reflect.DeepEqual(agent.Status, params.Agent.Status) {
+		return 0, nil // nothing to do
+	}
+	immediate := agent.Status.Health != params.Agent.Status.Health
+	return params.StatusWriter.Write(params.Client, &agent, immediate)
 }
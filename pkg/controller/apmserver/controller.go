@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sync/atomic"
+	"time"
 
 	"go.elastic.co/apm"
 	appsv1 "k8s.io/api/apps/v1"
@@ -92,6 +93,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileAp
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		Parameters:     params,
 	}
 }
@@ -147,6 +149,8 @@ type ReconcileApmServer struct {
 	k8s.Client
 	recorder       record.EventRecorder
 	dynamicWatches watches.DynamicWatches
+	// statusWriter coalesces successive status updates of the same APM Server.
+	statusWriter *common.StatusWriter
 	operator.Parameters
 	// iteration is the number of times this controller has run its reconcile method
 	iteration uint64
@@ -189,6 +193,11 @@ func (r *ReconcileApmServer) Reconcile(ctx context.Context, request reconcile.Re
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&as) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", as.Namespace, "as_name", as.Name)
+		return reconcile.Result{}, nil
+	}
+
 	// Remove any previous finalizer used in ECK v1.0.0-beta1 that we don't need anymore
 	if err := finalizer.RemoveAll(r.Client, &as); err != nil {
 		return reconcile.Result{}, err
@@ -213,7 +222,7 @@ func (r *ReconcileApmServer) doReconcile(ctx context.Context, request reconcile.
 	}
 
 	state := NewState(request, as)
-	svc, err := common.ReconcileService(ctx, r.Client, NewService(*as), as)
+	svc, err := common.ReconcileService(ctx, r.Client, NewService(*as), as, r.ServerSideApply)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -229,6 +238,7 @@ func (r *ReconcileApmServer) doReconcile(ctx context.Context, request reconcile.
 		CACertRotation:        r.CACertRotation,
 		CertRotation:          r.CertRotation,
 		GarbageCollectSecrets: true,
+		ServerSideApply:       r.ServerSideApply,
 	}.ReconcileCAAndHTTPCerts(ctx)
 	if results.HasError() {
 		res, err := results.Aggregate()
@@ -258,11 +268,15 @@ func (r *ReconcileApmServer) doReconcile(ctx context.Context, request reconcile.
 	state.UpdateApmServerExternalService(*svc)
 
 	// update status
-	err = r.updateStatus(ctx, state)
+	requeueAfter, err := r.updateStatus(ctx, state)
 	if err != nil && apierrors.IsConflict(err) {
 		log.V(1).Info("Conflict while updating status", "namespace", as.Namespace, "as", as.Name)
 		return reconcile.Result{Requeue: true}, nil
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 	res, err := results.WithError(err).Aggregate()
 	k8s.EmitErrorEvent(r.recorder, err, as, events.EventReconciliationError, "Reconciliation error: %v", err)
 	return res, err
@@ -286,6 +300,7 @@ func (r *ReconcileApmServer) onDelete(obj types.NamespacedName) error {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(obj))
 	// Clean up watches set on custom http tls certificates
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(certificates.CertificateWatchKey(Namer, obj.Name))
+	r.statusWriter.Forget(obj)
 	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, apmv1.Kind)
 }
 
@@ -317,24 +332,31 @@ func reconcileApmServerToken(c k8s.Client, as *apmv1.ApmServer) (corev1.Secret,
 	return reconciler.ReconcileSecretNoOwnerRef(c, expectedApmServerSecret, as)
 }
 
-func (r *ReconcileApmServer) updateStatus(ctx context.Context, state State) error {
+// updateStatus writes the status of the APM Server resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func (r *ReconcileApmServer) updateStatus(ctx context.Context, state State) (time.Duration, error) {
 	span, _ := apm.StartSpan(ctx, "update_status", tracing.SpanTypeApp)
 	defer span.End()
 
 	current := state.originalApmServer
 	if reflect.DeepEqual(current.Status, state.ApmServer.Status) {
-		return nil
+		return 0, nil
 	}
 	if state.ApmServer.Status.IsDegraded(current.Status.DeploymentStatus) {
 		r.recorder.Event(current, corev1.EventTypeWarning, events.EventReasonUnhealthy, "Apm Server health degraded")
 	}
-	log.V(1).Info("Updating status",
+	immediate := state.ApmServer.Status.Health != current.Status.Health
+	requeueAfter, err := r.statusWriter.Write(r.Client, state.ApmServer, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
 		"iteration", atomic.LoadUint64(&r.iteration),
 		"namespace", state.ApmServer.Namespace,
 		"as_name", state.ApmServer.Name,
 		"status", state.ApmServer.Status,
 	)
-	return common.UpdateStatus(r.Client, state.ApmServer)
+	return 0, nil
 }
 
 func NewService(as apmv1.ApmServer) *corev1.Service {
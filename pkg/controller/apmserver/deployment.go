@@ -37,7 +37,7 @@ func (r *ReconcileApmServer) reconcileApmServerDeployment(
 	if err != nil {
 		return state, err
 	}
-	reconciledConfigSecret, err := reconcileApmServerConfig(r.Client, as)
+	reconciledConfigSecret, err := reconcileApmServerConfig(r.Client, as, r.ServerSideApply)
 	if err != nil {
 		return state, err
 	}
@@ -48,6 +48,7 @@ func (r *ReconcileApmServer) reconcileApmServerDeployment(
 		Namer,
 		NewLabels(as.Name),
 		initContainerParameters,
+		r.ServerSideApply,
 	)
 	if err != nil {
 		return state, err
@@ -70,7 +71,7 @@ func (r *ReconcileApmServer) reconcileApmServerDeployment(
 	}
 
 	deploy := deployment.New(params)
-	result, err := deployment.Reconcile(r.K8sClient(), deploy, as)
+	result, err := deployment.Reconcile(r.K8sClient(), deploy, as, r.RestrictedSecurityContext)
 	if err != nil {
 		return state, err
 	}
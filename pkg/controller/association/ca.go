@@ -57,7 +57,7 @@ func (r *Reconciler) ReconcileCASecret(association commonv1.Association, namer n
 		},
 		Data: associatedPublicHTTPCertificatesSecret.Data,
 	}
-	if _, err := reconciler.ReconcileSecret(r, expectedSecret, association.Associated()); err != nil {
+	if _, err := reconciler.ReconcileSecret(r, expectedSecret, association.Associated(), r.ServerSideApply); err != nil {
 		return CASecret{}, err
 	}
 
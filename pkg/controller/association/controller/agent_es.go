@@ -5,6 +5,7 @@
 package controller
 
 import (
+	pkgerrors "github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -12,9 +13,11 @@ import (
 	agentv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/agent/v1alpha1"
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/agent"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	eslabel "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
 )
@@ -57,9 +60,53 @@ func AddAgentES(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params
 				return true, association.AssociationRef(), nil
 			},
 			UserSecretSuffix: "agent-user",
-			ESUserRole: func(associated commonv1.Associated) (string, error) {
-				return "superuser", nil
+			ESUserRole:       getAgentRoles,
+			ESServiceAccount: getAgentServiceAccount,
+			AssociatedPodLabels: func(associated types.NamespacedName) map[string]string {
+				return map[string]string{agent.NameLabelName: associated.Name}
 			},
 		},
 	})
 }
+
+func toAgent(associated commonv1.Associated) (*agentv1alpha1.Agent, error) {
+	agent, ok := associated.(*agentv1alpha1.Agent)
+	if !ok {
+		return nil, pkgerrors.Errorf(
+			"Agent expected, got %s/%s",
+			associated.GetObjectKind().GroupVersionKind().Group,
+			associated.GetObjectKind().GroupVersionKind().Kind,
+		)
+	}
+	return agent, nil
+}
+
+// getAgentRoles returns the role of the Elasticsearch user created for the Agent. Fleet Server versions which do not
+// authenticate with a service account token require a superuser to set up Fleet.
+func getAgentRoles(associated commonv1.Associated) (string, error) {
+	agent, err := toAgent(associated)
+	if err != nil {
+		return "", err
+	}
+	if agent.Spec.FleetServerEnabled {
+		return user.SuperUserBuiltinRole, nil
+	}
+	return user.AgentUserRole, nil
+}
+
+// getAgentServiceAccount returns the Elasticsearch service account Fleet Server authenticates as, if its version
+// allows it. Other Elastic Agents authenticate with a dedicated user.
+func getAgentServiceAccount(associated commonv1.Associated) (string, error) {
+	agent, err := toAgent(associated)
+	if err != nil {
+		return "", err
+	}
+	if !agent.Spec.FleetServerEnabled {
+		return "", nil
+	}
+	useServiceAccount, err := canUseServiceAccount(agent.Spec.Version, agent.Status.Version)
+	if err != nil || !useServiceAccount {
+		return "", err
+	}
+	return association.FleetServerServiceAccount, nil
+}
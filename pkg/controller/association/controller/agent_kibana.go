@@ -42,6 +42,7 @@ func AddAgentKibana(mgr manager.Manager, accessReviewer rbac.AccessReviewer, par
 		ElasticsearchUserCreation: &association.ElasticsearchUserCreation{
 			ElasticsearchRef: getElasticsearchFromKibana,
 			UserSecretSuffix: "agent-kb-user",
+			// setting up Fleet in Kibana requires a superuser
 			ESUserRole: func(associated commonv1.Associated) (string, error) {
 				return "superuser", nil
 			},
@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func apmKibanaAssociation(kibanaRef commonv1.ObjectSelector) commonv1.Association {
+	return &apmv1.ApmKibanaAssociation{ApmServer: &apmv1.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "apm", Namespace: "ns"},
+		Spec:       apmv1.ApmServerSpec{KibanaRef: kibanaRef},
+	}}
+}
+
+func Test_getKibanaExternalURL(t *testing.T) {
+	kb := kbv1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"}}
+	service := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "https", Port: 5601}}},
+		}
+	}
+	tests := []struct {
+		name      string
+		kibanaRef commonv1.ObjectSelector
+		objs      []runtime.Object
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "no Kibana reference",
+			kibanaRef: commonv1.ObjectSelector{},
+			want:      "",
+		},
+		{
+			name:      "default Kibana service",
+			kibanaRef: commonv1.ObjectSelector{Name: "kb"},
+			objs:      []runtime.Object{&kb, service("kb-kb-http")},
+			want:      "https://kb-kb-http.ns.svc:5601",
+		},
+		{
+			name:      "custom Kibana service",
+			kibanaRef: commonv1.ObjectSelector{Name: "kb", ServiceName: "custom"},
+			objs:      []runtime.Object{&kb, service("kb-kb-http"), service("custom")},
+			want:      "https://custom.ns.svc:5601",
+		},
+		{
+			name:      "Kibana not found",
+			kibanaRef: commonv1.ObjectSelector{Name: "kb"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getKibanaExternalURL(k8s.NewFakeClient(tt.objs...), apmKibanaAssociation(tt.kibanaRef))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_getElasticsearchFromKibana(t *testing.T) {
+	kb := kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Name: "kb", Namespace: "ns"},
+		Spec:       kbv1.KibanaSpec{ElasticsearchRef: commonv1.ObjectSelector{Name: "es"}},
+	}
+	tests := []struct {
+		name      string
+		kibanaRef commonv1.ObjectSelector
+		objs      []runtime.Object
+		wantFound bool
+		wantRef   commonv1.ObjectSelector
+	}{
+		{
+			name:      "no Kibana reference",
+			kibanaRef: commonv1.ObjectSelector{},
+		},
+		{
+			name:      "Kibana not found",
+			kibanaRef: commonv1.ObjectSelector{Name: "kb"},
+		},
+		{
+			name:      "Elasticsearch referenced by Kibana",
+			kibanaRef: commonv1.ObjectSelector{Name: "kb"},
+			objs:      []runtime.Object{&kb},
+			wantFound: true,
+			wantRef:   commonv1.ObjectSelector{Name: "es", Namespace: "ns"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, ref, err := getElasticsearchFromKibana(k8s.NewFakeClient(tt.objs...), apmKibanaAssociation(tt.kibanaRef))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFound, found)
+			require.Equal(t, tt.wantRef, ref)
+		})
+	}
+}
@@ -58,6 +58,7 @@ func AddEntES(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params op
 				return true, association.AssociationRef(), nil
 			},
 			UserSecretSuffix: "ent-user",
+			// Enterprise Search requires a superuser to manage its own indices and roles
 			ESUserRole: func(_ commonv1.Associated) (string, error) {
 				return esuser.SuperUserBuiltinRole, nil
 			},
@@ -7,6 +7,7 @@ package controller
 import (
 	"context"
 
+	pkgerrors "github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -17,6 +18,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	eslabel "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
 )
@@ -65,10 +67,31 @@ func AddKibanaES(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params
 			ESUserRole: func(associated commonv1.Associated) (string, error) {
 				return KibanaSystemUserBuiltinRole, nil
 			},
+			ESServiceAccount: getKibanaServiceAccount,
+			AssociatedPodLabels: func(associated types.NamespacedName) map[string]string {
+				return map[string]string{kibana.KibanaNameLabelName: associated.Name}
+			},
 		},
 	})
 }
 
+// getKibanaServiceAccount returns the Elasticsearch service account Kibana authenticates as, if its version allows it.
+func getKibanaServiceAccount(associated commonv1.Associated) (string, error) {
+	kb, ok := associated.(*kbv1.Kibana)
+	if !ok {
+		return "", pkgerrors.Errorf(
+			"Kibana expected, got %s/%s",
+			associated.GetObjectKind().GroupVersionKind().Group,
+			associated.GetObjectKind().GroupVersionKind().Kind,
+		)
+	}
+	useServiceAccount, err := canUseServiceAccount(kb.Spec.Version, kb.Status.Version)
+	if err != nil || !useServiceAccount {
+		return "", err
+	}
+	return association.KibanaServiceAccount, nil
+}
+
 // referencedElasticsearchStatusVersion returns the currently running version of Elasticsearch
 // reported in its status.
 func referencedElasticsearchStatusVersion(c k8s.Client, esRef types.NamespacedName) (string, error) {
@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package controller
+
+import (
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+)
+
+// serviceAccountMinVersion is the minimum version from which Kibana and Fleet Server authenticate to Elasticsearch
+// with a service account token instead of a dedicated user.
+var serviceAccountMinVersion = version.MinFor(7, 17, 0)
+
+// canUseServiceAccount returns true if both the version in the spec and the lowest running version, if any, of the
+// associated resource support authenticating with a service account token. Pods running a previous version keep
+// authenticating with their user until they are upgraded.
+func canUseServiceAccount(specVersion, runningVersion string) (bool, error) {
+	for _, v := range []string{specVersion, runningVersion} {
+		if v == "" {
+			continue
+		}
+		parsed, err := version.Parse(v)
+		if err != nil {
+			return false, err
+		}
+		if !parsed.GTE(serviceAccountMinVersion) {
+			return false, nil
+		}
+	}
+	return specVersion != "", nil
+}
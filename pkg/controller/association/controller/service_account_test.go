@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_canUseServiceAccount(t *testing.T) {
+	tests := []struct {
+		name           string
+		specVersion    string
+		runningVersion string
+		want           bool
+		wantErr        bool
+	}{
+		{
+			name:        "no version",
+			specVersion: "",
+			want:        false,
+		},
+		{
+			name:        "version too old",
+			specVersion: "7.16.3",
+			want:        false,
+		},
+		{
+			name:        "supported version, nothing running yet",
+			specVersion: "7.17.0",
+			want:        true,
+		},
+		{
+			name:           "upgrade in progress from a version too old",
+			specVersion:    "8.0.0",
+			runningVersion: "7.16.3",
+			want:           false,
+		},
+		{
+			name:           "supported spec and running versions",
+			specVersion:    "8.0.0",
+			runningVersion: "7.17.1",
+			want:           true,
+		},
+		{
+			name:        "invalid version",
+			specVersion: "not-a-version",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canUseServiceAccount(tt.specVersion, tt.runningVersion)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
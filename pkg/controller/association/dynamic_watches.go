@@ -29,6 +29,12 @@ func esUserWatchName(associated types.NamespacedName) string {
 	return fmt.Sprintf("%s-%s-es-user-watch", associated.Namespace, associated.Name)
 }
 
+// unmanagedSecretWatchName returns the name of the watch setup on the Secrets describing the referenced resources not
+// managed by the operator.
+func unmanagedSecretWatchName(associated types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-unmanaged-secret-watch", associated.Namespace, associated.Name)
+}
+
 // serviceWatchName returns the name of the watch setup on the custom service to be used to make requests to the
 // referenced resource.
 func serviceWatchName(associated types.NamespacedName) string {
@@ -40,11 +46,15 @@ func serviceWatchName(associated types.NamespacedName) string {
 // * the CA secret of the referenced resource in the referenced resource namespace
 // * the referenced service to access the referenced resource
 // * if there's an ES user to create, watch the user Secret in ES namespace
+// * the user provided Secrets describing the referenced resources not managed by the operator
 // All watches for all given associations are set under the same watch name and replaced with each reconciliation.
 // The given associations are expected to be of the same type (e.g. Kibana -> Elasticsearch, not Kibana -> Enterprise Search).
 func (r *Reconciler) reconcileWatches(associated types.NamespacedName, associations []commonv1.Association) error {
 	// watch the referenced resource
 	if err := ReconcileWatch(associated, associations, r.watches.ReferencedResources, referencedResourceWatchName(associated), func(association commonv1.Association) types.NamespacedName {
+		if association.AssociationRef().IsExternal() {
+			return types.NamespacedName{}
+		}
 		return association.AssociationRef().NamespacedName()
 	}); err != nil {
 		return err
@@ -53,6 +63,9 @@ func (r *Reconciler) reconcileWatches(associated types.NamespacedName, associati
 	// watch the CA secret of the referenced resource in the referenced resource namespace
 	if err := ReconcileWatch(associated, associations, r.watches.Secrets, referencedResourceCASecretWatchName(associated), func(association commonv1.Association) types.NamespacedName {
 		ref := association.AssociationRef()
+		if ref.IsExternal() {
+			return types.NamespacedName{}
+		}
 		return types.NamespacedName{
 			Name:      certificates.PublicCertsSecretName(r.AssociationInfo.ReferencedResourceNamer, ref.Name),
 			Namespace: ref.Namespace,
@@ -76,12 +89,26 @@ func (r *Reconciler) reconcileWatches(associated types.NamespacedName, associati
 	// watch the Elasticsearch user secret in the Elasticsearch namespace, if needed
 	if r.ElasticsearchUserCreation != nil {
 		if err := ReconcileWatch(associated, associations, r.watches.Secrets, esUserWatchName(associated), func(association commonv1.Association) types.NamespacedName {
+			if association.AssociationRef().IsExternal() {
+				return types.NamespacedName{}
+			}
 			return UserKey(association, association.AssociationRef().Namespace, r.ElasticsearchUserCreation.UserSecretSuffix)
 		}); err != nil {
 			return err
 		}
 	}
 
+	// watch the Secrets describing the referenced resources not managed by the operator to propagate their updates
+	if err := ReconcileWatch(associated, associations, r.watches.Secrets, unmanagedSecretWatchName(associated), func(association commonv1.Association) types.NamespacedName {
+		ref := association.AssociationRef()
+		if !ref.IsExternal() {
+			return types.NamespacedName{}
+		}
+		return types.NamespacedName{Name: ref.SecretName, Namespace: association.GetNamespace()}
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -131,4 +158,6 @@ func (r *Reconciler) removeWatches(associated types.NamespacedName) {
 	RemoveWatch(r.watches.Services, serviceWatchName(associated))
 	// - ES user secret
 	RemoveWatch(r.watches.Secrets, esUserWatchName(associated))
+	// - Secrets describing the referenced resources not managed by the operator
+	RemoveWatch(r.watches.Secrets, unmanagedSecretWatchName(associated))
 }
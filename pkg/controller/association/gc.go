@@ -87,12 +87,17 @@ func (ugc *UsersGarbageCollector) getUserSecrets() ([]v1.Secret, error) {
 }
 
 func getUserSecretsInNamespace(c k8s.Client, namespace string) ([]v1.Secret, error) {
-	userSecrets := v1.SecretList{}
-	matchingLabels := client.MatchingLabels(map[string]string{common.TypeLabelName: esuser.AssociatedUserType})
-	if err := c.List(context.Background(), &userSecrets, client.InNamespace(namespace), matchingLabels); err != nil {
-		return nil, err
+	var userSecrets []v1.Secret
+	// service account tokens are handled as users
+	for _, secretType := range []string{esuser.AssociatedUserType, esuser.ServiceAccountTokenType} {
+		secrets := v1.SecretList{}
+		matchingLabels := client.MatchingLabels(map[string]string{common.TypeLabelName: secretType})
+		if err := c.List(context.Background(), &secrets, client.InNamespace(namespace), matchingLabels); err != nil {
+			return nil, err
+		}
+		userSecrets = append(userSecrets, secrets.Items...)
 	}
-	return userSecrets.Items, nil
+	return userSecrets, nil
 }
 
 // DoGarbageCollection runs the User garbage collector.
@@ -102,7 +107,7 @@ func (ugc *UsersGarbageCollector) DoGarbageCollection() error {
 		return nil
 	}
 
-	// 1. List all secrets of type "user"
+	// 1. List all secrets of type "user" or "service-account-token"
 	secrets, err := ugc.getUserSecrets()
 	if err != nil {
 		return err
@@ -93,6 +93,14 @@ type ElasticsearchUserCreation struct {
 	UserSecretSuffix string
 	// ESUserRole is the role to use for the Elasticsearch user created by the association.
 	ESUserRole func(commonv1.Associated) (string, error)
+	// ESServiceAccount returns the Elasticsearch service account the associated resource authenticates as, or an empty
+	// string if a dedicated user must be created instead. In the former case a service account token is created.
+	// May be nil if the associated resource does not support service accounts.
+	ESServiceAccount func(commonv1.Associated) (string, error)
+	// AssociatedPodLabels returns labels selecting the Pods of the associated resource. When an association is migrated
+	// from a user to a service account token, the user is only deleted once the Pods which may still use it are gone.
+	// May be nil, in which case the user is deleted as soon as the associated resource is configured with the token.
+	AssociatedPodLabels func(associated types.NamespacedName) map[string]string
 }
 
 // AssociationResourceLabels returns all labels required by a resource to allow identifying both its Associated resource
@@ -121,6 +129,18 @@ func (a AssociationInfo) userLabelSelector(
 	)
 }
 
+// serviceAccountTokenLabelSelector returns labels selecting the ES service account token secret, including association
+// labels and service account token type label.
+func (a AssociationInfo) serviceAccountTokenLabelSelector(
+	associated types.NamespacedName,
+	association types.NamespacedName,
+) client.MatchingLabels {
+	return maps.Merge(
+		map[string]string{common.TypeLabelName: user.ServiceAccountTokenType},
+		a.AssociationResourceLabels(associated, association),
+	)
+}
+
 // Reconciler reconciles a generic association for a specific AssociationInfo.
 type Reconciler struct {
 	AssociationInfo
@@ -169,6 +189,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(associated) {
+		r.log(associatedKey).Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation")
+		return reconcile.Result{}, nil
+	}
+
 	if !associated.GetDeletionTimestamp().IsZero() {
 		// Object is being deleted, short-circuit reconciliation
 		return reconcile.Result{}, nil
@@ -200,7 +225,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	results := reconciler.NewResult(ctx)
 	newStatusMap := commonv1.AssociationStatusMap{}
 	for _, association := range associations {
-		newStatus, err := r.reconcileAssociation(ctx, association)
+		newStatus, err := r.reconcileAssociation(ctx, association, results)
 		if err != nil {
 			results.WithError(err)
 		}
@@ -218,7 +243,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		Aggregate()
 }
 
-func (r *Reconciler) reconcileAssociation(ctx context.Context, association commonv1.Association) (commonv1.AssociationStatus, error) {
+func (r *Reconciler) reconcileAssociation(
+	ctx context.Context,
+	association commonv1.Association,
+	results *reconciler.Results,
+) (commonv1.AssociationStatus, error) {
+	if association.AssociationRef().IsExternal() {
+		// the referenced resource is not managed by the operator but described by a user provided Secret
+		return r.reconcileUnmanagedAssociation(ctx, association)
+	}
+
 	exists, err := k8s.ObjectExists(r.Client, association.AssociationRef().NamespacedName(), r.ReferencedObjTemplate())
 	if err != nil {
 		return commonv1.AssociationFailed, err
@@ -276,6 +310,13 @@ func (r *Reconciler) reconcileAssociation(ctx context.Context, association commo
 	if !found {
 		return commonv1.AssociationPending, RemoveAssociationConf(r.Client, association)
 	}
+	// the user cannot be created in an Elasticsearch cluster not managed by the operator
+	if esRef.IsExternal() {
+		r.recorder.Eventf(association, corev1.EventTypeWarning, events.EventAssociationError,
+			"Cannot create a user in the Elasticsearch cluster referenced through secret %s, use secretName to reference the %s resource instead",
+			esRef.SecretName, r.AssociationType)
+		return commonv1.AssociationFailed, RemoveAssociationConf(r.Client, association)
+	}
 
 	es, associationStatus, err := r.getElasticsearch(ctx, association, esRef)
 	if associationStatus != "" || err != nil {
@@ -287,6 +328,52 @@ func (r *Reconciler) reconcileAssociation(ctx context.Context, association commo
 		return commonv1.AssociationPending, err
 	}
 
+	var serviceAccount string
+	if r.ElasticsearchUserCreation.ESServiceAccount != nil {
+		if serviceAccount, err = r.ElasticsearchUserCreation.ESServiceAccount(association.Associated()); err != nil {
+			return commonv1.AssociationFailed, err
+		}
+	}
+
+	authSecretRef := UserSecretKeySelector(association, r.ElasticsearchUserCreation.UserSecretSuffix)
+	expectedAssocConf.AuthSecretName = authSecretRef.Name
+
+	if serviceAccount != "" {
+		token, err := reconcileServiceAccountToken(
+			ctx,
+			r.Client,
+			association,
+			assocLabels,
+			serviceAccount,
+			r.ElasticsearchUserCreation.UserSecretSuffix,
+			es,
+			r.ServerSideApply,
+		)
+		if err != nil {
+			return commonv1.AssociationPending, err
+		}
+		// the associated resource keeps authenticating with its user until all the Elasticsearch nodes can
+		// authenticate the token, it then keeps using the token
+		useToken := association.AssociationConf().GetIsServiceAccount()
+		if !useToken {
+			if useToken, err = serviceAccountTokenReady(ctx, r.Client, es, token); err != nil {
+				return commonv1.AssociationPending, err
+			}
+		}
+		if useToken {
+			return r.reconcileServiceAccountAssociation(ctx, association, expectedAssocConf, es, results)
+		}
+		r.log(k8s.ExtractNamespacedName(association)).V(1).Info(
+			"Service account token not yet available on all Elasticsearch nodes, keeping the user",
+			"es_name", es.Name, "es_namespace", es.Namespace)
+		results.WithResult(defaultRequeue)
+	} else if r.ElasticsearchUserCreation.ESServiceAccount != nil {
+		// the associated resource does not authenticate with a service account token (anymore)
+		if err := deleteServiceAccountToken(r.Client, association, r.ElasticsearchUserCreation.UserSecretSuffix, es); err != nil {
+			return commonv1.AssociationPending, err
+		}
+	}
+
 	userRole, err := r.ElasticsearchUserCreation.ESUserRole(association.Associated())
 	if err != nil {
 		return commonv1.AssociationFailed, err
@@ -300,18 +387,45 @@ func (r *Reconciler) reconcileAssociation(ctx context.Context, association commo
 		userRole,
 		r.ElasticsearchUserCreation.UserSecretSuffix,
 		es,
+		r.ServerSideApply,
 	); err != nil {
 		return commonv1.AssociationPending, err
 	}
 
-	authSecretRef := UserSecretKeySelector(association, r.ElasticsearchUserCreation.UserSecretSuffix)
-	expectedAssocConf.AuthSecretName = authSecretRef.Name
 	expectedAssocConf.AuthSecretKey = authSecretRef.Key
 
 	// update the association configuration if necessary
 	return r.updateAssocConf(ctx, expectedAssocConf, association)
 }
 
+// reconcileServiceAccountAssociation configures the associated resource to authenticate with its service account token,
+// then deletes the user it may have used before.
+func (r *Reconciler) reconcileServiceAccountAssociation(
+	ctx context.Context,
+	association commonv1.Association,
+	expectedAssocConf *commonv1.AssociationConf,
+	es esv1.Elasticsearch,
+	results *reconciler.Results,
+) (commonv1.AssociationStatus, error) {
+	expectedAssocConf.AuthSecretName = serviceAccountTokenKey(association, r.ElasticsearchUserCreation.UserSecretSuffix).Name
+	expectedAssocConf.AuthSecretKey = ServiceAccountTokenField
+	expectedAssocConf.IsServiceAccount = true
+	status, err := r.updateAssocConf(ctx, expectedAssocConf, association)
+	if err != nil || status != commonv1.AssociationEstablished {
+		return status, err
+	}
+
+	deleted, err := deleteReplacedUser(ctx, r.Client, association, *r.ElasticsearchUserCreation, es)
+	if err != nil {
+		return commonv1.AssociationPending, err
+	}
+	if !deleted {
+		// some Pods of the associated resource may still use the user
+		results.WithResult(defaultRequeue)
+	}
+	return status, nil
+}
+
 // getElasticsearch attempts to retrieve the referenced Elasticsearch resource. If not found, it removes
 // any existing association configuration on associated, and returns AssociationPending.
 func (r *Reconciler) getElasticsearch(
@@ -342,14 +456,16 @@ func (r *Reconciler) getElasticsearch(
 
 // Unbind removes the association resources.
 func (r *Reconciler) Unbind(association commonv1.Association) error {
-	// Ensure that user in Elasticsearch is deleted to prevent illegitimate access
-	if err := k8s.DeleteSecretMatching(
-		r.Client,
-		r.userLabelSelector(
-			k8s.ExtractNamespacedName(association),
-			association.AssociationRef().NamespacedName(),
-		)); err != nil {
-		return err
+	associated := k8s.ExtractNamespacedName(association)
+	ref := association.AssociationRef().NamespacedName()
+	// Ensure that user or service account token in Elasticsearch is deleted to prevent illegitimate access
+	for _, selector := range []client.MatchingLabels{
+		r.userLabelSelector(associated, ref),
+		r.serviceAccountTokenLabelSelector(associated, ref),
+	} {
+		if err := k8s.DeleteSecretMatching(r.Client, selector); err != nil {
+			return err
+		}
 	}
 	// Also remove the association configuration
 	return RemoveAssociationConf(r.Client, association)
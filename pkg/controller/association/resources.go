@@ -65,7 +65,7 @@ func isSecretForAssociation(info AssociationInfo, secret corev1.Secret, associat
 
 	// grab name from label (eg. elasticsearch.k8s.elastic.co/cluster-name=elasticsearch1 or kibana.k8s.elastic.co/name=kibana1)
 	resourceName, ok := secret.Labels[info.AssociationResourceNameLabelName]
-	if !ok || resourceName != ref.Name {
+	if !ok || resourceName != ref.NameOrSecretName() {
 		// name points to a resource not involved in this `association`
 		return false
 	}
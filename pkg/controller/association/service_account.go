@@ -0,0 +1,364 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package association
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.elastic.co/apm"
+	"golang.org/x/crypto/pbkdf2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	eslabel "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	esuser "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// KibanaServiceAccount is the Elasticsearch service account used by Kibana.
+	KibanaServiceAccount = "elastic/kibana"
+	// FleetServerServiceAccount is the Elasticsearch service account used by Fleet Server.
+	FleetServerServiceAccount = "elastic/fleet-server"
+
+	// UserReplacedAtAnnotation is set on the secret of a user replaced by a service account token, with the time at
+	// which the associated resource was configured to use the token instead.
+	UserReplacedAtAnnotation = "association.k8s.elastic.co/user-replaced-at"
+
+	// ServiceAccountTokenField is the field in the secret of the associated resource that contains the service
+	// account token.
+	ServiceAccountTokenField = "token"
+
+	// serviceAccountTokenSecretLength is the length of the randomly generated secret part of a service account token.
+	serviceAccountTokenSecretLength = 64
+
+	// Below are the parameters of the PBKDF2_STRETCH algorithm, used by default by Elasticsearch to hash the service
+	// account tokens.
+	serviceAccountTokenHashPrefix     = "{PBKDF2_STRETCH}"
+	serviceAccountTokenHashIterations = 10000
+	serviceAccountTokenHashSaltLength = 32
+	serviceAccountTokenHashKeyLength  = 32
+)
+
+// serviceAccountTokenMagicBytes prefix the serialized service account tokens.
+var serviceAccountTokenMagicBytes = []byte{0, 1, 0, 1}
+
+// serviceAccountTokenName returns the name of the token, derived from the name of the user that would otherwise be
+// created for the association. Dots are allowed in Kubernetes resource names but not in token names.
+func serviceAccountTokenName(userName string) string {
+	return strings.ReplaceAll(userName, ".", "_")
+}
+
+// serviceAccountBearerToken serializes the token identified by its qualified name (eg. elastic/kibana/my-token) and
+// its secret, as expected by Elasticsearch in the Authorization header.
+func serviceAccountBearerToken(qualifiedName string, secret []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(serviceAccountTokenMagicBytes)
+	buf.WriteString(qualifiedName)
+	buf.WriteString(":")
+	buf.Write(secret)
+	return []byte(base64.RawStdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// parseServiceAccountBearerToken returns the qualified name and the secret of a serialized token.
+func parseServiceAccountBearerToken(token []byte) (string, []byte, error) {
+	decoded, err := base64.RawStdEncoding.DecodeString(string(token))
+	if err != nil {
+		return "", nil, err
+	}
+	if !bytes.HasPrefix(decoded, serviceAccountTokenMagicBytes) {
+		return "", nil, errors.New("invalid service account token")
+	}
+	parts := bytes.SplitN(bytes.TrimPrefix(decoded, serviceAccountTokenMagicBytes), []byte(":"), 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return "", nil, errors.New("invalid service account token")
+	}
+	return string(parts[0]), parts[1], nil
+}
+
+// hashServiceAccountTokenSecret hashes the token secret with the PBKDF2_STRETCH algorithm: the hex encoded SHA-512
+// digest of the secret is hashed with PBKDF2 using HMAC-SHA-512.
+func hashServiceAccountTokenSecret(secret []byte, salt []byte, iterations int) []byte {
+	digest := sha512.Sum512(secret)
+	key := pbkdf2.Key([]byte(hex.EncodeToString(digest[:])), salt, iterations, serviceAccountTokenHashKeyLength, sha512.New)
+	return []byte(fmt.Sprintf("%s%d$%s$%s",
+		serviceAccountTokenHashPrefix,
+		iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(key),
+	))
+}
+
+// newServiceAccountTokenHash hashes the token secret with a random salt.
+func newServiceAccountTokenHash(secret []byte) ([]byte, error) {
+	salt := make([]byte, serviceAccountTokenHashSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return hashServiceAccountTokenSecret(secret, salt, serviceAccountTokenHashIterations), nil
+}
+
+// serviceAccountTokenHashMatches returns true if the given hash is the hash of the token secret.
+func serviceAccountTokenHashMatches(hash []byte, secret []byte) bool {
+	if !bytes.HasPrefix(hash, []byte(serviceAccountTokenHashPrefix)) {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(string(hash), serviceAccountTokenHashPrefix), "$")
+	if len(parts) != 3 {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hashServiceAccountTokenSecret(secret, salt, iterations), hash) == 1
+}
+
+// serviceAccountTokenSuffix returns the suffix of the names of the service account token secrets. It differs from the
+// suffix of the user secrets so that both can exist while an association is migrated from a user to a token.
+func serviceAccountTokenSuffix(userSuffix string) string {
+	return userSuffix + "-token"
+}
+
+// serviceAccountTokenKey is the namespaced name of the secret containing the service account token in the
+// associated resource namespace.
+func serviceAccountTokenKey(association commonv1.Association, userSuffix string) types.NamespacedName {
+	return secretKey(association, serviceAccountTokenSuffix(userSuffix))
+}
+
+// reconcileServiceAccountToken creates or updates the secret holding the service account token in the associated
+// resource namespace, and the secret holding its hash in the Elasticsearch namespace. It returns the token as it
+// is expected in the file realm of Elasticsearch.
+func reconcileServiceAccountToken(
+	ctx context.Context,
+	c k8s.Client,
+	association commonv1.Association,
+	labels map[string]string,
+	serviceAccount string,
+	userObjectSuffix string,
+	es esv1.Elasticsearch,
+	serverSideApply bool,
+) (esuser.ServiceAccountToken, error) {
+	span, _ := apm.StartSpan(ctx, "reconcile_service_account_token", tracing.SpanTypeApp)
+	defer span.End()
+
+	// Add the Elasticsearch name, this is only intended to help the user to filter on these resources
+	labels[eslabel.ClusterNameLabelName] = es.Name
+
+	secKey := serviceAccountTokenKey(association, userObjectSuffix)
+	tokenKey := UserKey(association, es.Namespace, serviceAccountTokenSuffix(userObjectSuffix))
+	// the token is named after the user it replaces
+	qualifiedName := serviceAccount + "/" + serviceAccountTokenName(elasticsearchUserName(association, userObjectSuffix))
+
+	// reuse the existing token if there's one for the same service account
+	var existingSecret corev1.Secret
+	if err := c.Get(context.Background(), secKey, &existingSecret); err != nil && !apierrors.IsNotFound(err) {
+		return esuser.ServiceAccountToken{}, err
+	}
+	var secret []byte
+	if name, existingTokenSecret, err := parseServiceAccountBearerToken(existingSecret.Data[ServiceAccountTokenField]); err == nil && name == qualifiedName {
+		secret = existingTokenSecret
+	} else {
+		secret = common.RandomBytes(serviceAccountTokenSecretLength)
+	}
+
+	expectedSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secKey.Name,
+			Namespace: secKey.Namespace,
+			Labels:    common.AddCredentialsLabel(labels),
+		},
+		Data: map[string][]byte{
+			ServiceAccountTokenField: serviceAccountBearerToken(qualifiedName, secret),
+		},
+	}
+	if _, err := reconciler.ReconcileSecret(c, expectedSecret, association.Associated(), serverSideApply); err != nil {
+		return esuser.ServiceAccountToken{}, err
+	}
+
+	// merge the association labels provided by the controller with the one needed for a service account token
+	tokenLabels := esuser.ServiceAccountTokenLabels(es)
+	for key, value := range labels {
+		tokenLabels[key] = value
+	}
+
+	var existingEsToken corev1.Secret
+	if err := c.Get(context.Background(), tokenKey, &existingEsToken); err != nil && !apierrors.IsNotFound(err) {
+		return esuser.ServiceAccountToken{}, err
+	}
+
+	// reuse the existing hash if valid
+	token := esuser.ServiceAccountToken{
+		QualifiedName: qualifiedName,
+		Hash:          existingEsToken.Data[esuser.ServiceAccountHashField],
+	}
+	if !serviceAccountTokenHashMatches(token.Hash, secret) {
+		var err error
+		if token.Hash, err = newServiceAccountTokenHash(secret); err != nil {
+			return esuser.ServiceAccountToken{}, err
+		}
+	}
+
+	expectedEsToken := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tokenKey.Name,
+			Namespace: tokenKey.Namespace,
+			Labels:    tokenLabels,
+		},
+		Data: map[string][]byte{
+			esuser.ServiceAccountTokenNameField: []byte(token.QualifiedName),
+			esuser.ServiceAccountHashField:      token.Hash,
+		},
+	}
+
+	owner := es // token is owned by the es resource in es namespace
+	_, err := reconciler.ReconcileSecret(c, expectedEsToken, &owner, serverSideApply)
+	return token, err
+}
+
+// serviceAccountTokenReady returns true if all the nodes of the Elasticsearch cluster can authenticate the given token:
+// the token is part of the file realm of the cluster, and all the Pods include the service tokens file in their
+// file realm. Pods created by previous versions of the operator do not, until they are restarted.
+func serviceAccountTokenReady(ctx context.Context, c k8s.Client, es esv1.Elasticsearch, token esuser.ServiceAccountToken) (bool, error) {
+	span, _ := apm.StartSpan(ctx, "check_service_account_token", tracing.SpanTypeApp)
+	defer span.End()
+
+	var fileRealm corev1.Secret
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.RolesAndFileRealmSecret(es.Name)}, &fileRealm)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	tokensFile := append([]byte("\n"), fileRealm.Data[esuser.ServiceTokensFile]...)
+	if !bytes.Contains(tokensFile, append([]byte("\n"), esuser.ServiceAccountTokens{token}.FileBytes()...)) {
+		return false, nil
+	}
+
+	var pods corev1.PodList
+	if err := c.List(context.Background(), &pods, client.InNamespace(es.Namespace), eslabel.NewLabelSelectorForElasticsearch(es)); err != nil {
+		return false, err
+	}
+	if len(pods.Items) == 0 {
+		return false, nil
+	}
+	for _, pod := range pods.Items {
+		if !eslabel.HasServiceTokens(pod) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// deleteReplacedUser deletes the user replaced by a service account token, once the Pods of the associated resource
+// which may still authenticate with it are gone. The time at which the user was replaced is recorded in an annotation
+// of its secret in the Elasticsearch namespace, Pods created before are assumed to still use the user.
+// It returns true if the user was deleted.
+func deleteReplacedUser(
+	ctx context.Context,
+	c k8s.Client,
+	association commonv1.Association,
+	userCreation ElasticsearchUserCreation,
+	es esv1.Elasticsearch,
+) (bool, error) {
+	span, _ := apm.StartSpan(ctx, "delete_replaced_user", tracing.SpanTypeApp)
+	defer span.End()
+
+	usrKey := UserKey(association, es.Namespace, userCreation.UserSecretSuffix)
+	var userSecret corev1.Secret
+	err := c.Get(context.Background(), usrKey, &userSecret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if err == nil {
+		replacedAt, recorded := userSecret.Annotations[UserReplacedAtAnnotation]
+		if !recorded {
+			if userSecret.Annotations == nil {
+				userSecret.Annotations = make(map[string]string, 1)
+			}
+			userSecret.Annotations[UserReplacedAtAnnotation] = metav1.Now().UTC().Format(time.RFC3339)
+			return false, c.Update(context.Background(), &userSecret)
+		}
+		if userCreation.AssociatedPodLabels != nil {
+			replacedAtTime, err := time.Parse(time.RFC3339, replacedAt)
+			if err != nil {
+				return false, err
+			}
+			var pods corev1.PodList
+			if err := c.List(context.Background(), &pods,
+				client.InNamespace(association.GetNamespace()),
+				client.MatchingLabels(userCreation.AssociatedPodLabels(k8s.ExtractNamespacedName(association.Associated()))),
+			); err != nil {
+				return false, err
+			}
+			for _, pod := range pods.Items {
+				if pod.CreationTimestamp.Time.Before(replacedAtTime) {
+					// this Pod may still authenticate with the user
+					return false, nil
+				}
+			}
+		}
+		if err := c.Delete(context.Background(), &userSecret); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	// also delete the user password in the associated resource namespace
+	return true, deleteSecretIfExists(c, secretKey(association, userCreation.UserSecretSuffix))
+}
+
+// deleteServiceAccountToken deletes the service account token of an association whose associated resource
+// authenticates with a user.
+func deleteServiceAccountToken(
+	c k8s.Client,
+	association commonv1.Association,
+	userObjectSuffix string,
+	es esv1.Elasticsearch,
+) error {
+	if err := deleteSecretIfExists(c, UserKey(association, es.Namespace, serviceAccountTokenSuffix(userObjectSuffix))); err != nil {
+		return err
+	}
+	return deleteSecretIfExists(c, serviceAccountTokenKey(association, userObjectSuffix))
+}
+
+// deleteSecretIfExists deletes the secret with the given name, if it exists.
+func deleteSecretIfExists(c k8s.Client, key types.NamespacedName) error {
+	var secret corev1.Secret
+	if err := c.Get(context.Background(), key, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := c.Delete(context.Background(), &secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package association
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_serviceAccountBearerToken(t *testing.T) {
+	// magic bytes followed by the qualified name and the secret, base64 encoded without padding
+	token := serviceAccountBearerToken("elastic/fleet-server/token1", []byte("7LWZH6"))
+	require.Equal(t, "AAEAAWVsYXN0aWMvZmxlZXQtc2VydmVyL3Rva2VuMTo3TFdaSDY", string(token))
+
+	name, secret, err := parseServiceAccountBearerToken(token)
+	require.NoError(t, err)
+	require.Equal(t, "elastic/fleet-server/token1", name)
+	require.Equal(t, []byte("7LWZH6"), secret)
+
+	for _, invalid := range []string{"", "not base64!", "ZWxhc3RpYy9raWJhbmEvdG9rZW4xOnNlY3JldA", "AAEAAWVsYXN0aWMva2liYW5hL3Rva2VuMQ"} {
+		_, _, err := parseServiceAccountBearerToken([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func Test_serviceAccountTokenHash(t *testing.T) {
+	// PBKDF2_STRETCH hash computed independently with a fixed salt
+	hash := hashServiceAccountTokenSecret([]byte("7LWZH6"), []byte("0123456789abcdef0123456789abcdef"), 10000)
+	require.Equal(t, "{PBKDF2_STRETCH}10000$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=$zXb8iBbdjHw8/UTIvHGlZL6vkG6uTs6a5FF5PZJaA1Q=", string(hash))
+	require.True(t, serviceAccountTokenHashMatches(hash, []byte("7LWZH6")))
+
+	newHash, err := newServiceAccountTokenHash([]byte("secret"))
+	require.NoError(t, err)
+	require.True(t, serviceAccountTokenHashMatches(newHash, []byte("secret")))
+	require.False(t, serviceAccountTokenHashMatches(newHash, []byte("other")))
+
+	for _, invalid := range []string{"", "$2a$10$7WSe8NagB3MTI/RdP4Gk5uHJJTJ4ZCrPfd0G9DmDsjGCJLRTur6Di", "{PBKDF2_STRETCH}10000$salt", "{PBKDF2_STRETCH}x$MDEy$MDEy"} {
+		require.False(t, serviceAccountTokenHashMatches([]byte(invalid), []byte("secret")), invalid)
+	}
+}
+
+func TestReconciler_Reconcile_ServiceAccount(t *testing.T) {
+	// Kibana is associated to Elasticsearch with a user, which is replaced by a service account token
+	kb := sampleAssociatedKibana()
+	esPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: esNamespace,
+		Name:      "esname-es-default-0",
+		Labels:    map[string]string{"elasticsearch.k8s.elastic.co/cluster-name": "esname"},
+	}}
+	kbPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         kibanaNamespace,
+		Name:              "kbname-kb-0",
+		Labels:            map[string]string{"kibana.k8s.elastic.co/name": "kbname"},
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+	}}
+	r := testReconciler(&kb, &sampleES, &esHTTPPublicCertsSecret, esHTTPService(), &kibanaUserInESNamespace, &kibanaUserInKibanaNamespace, &esPod, &kbPod)
+	esUserCreation := *r.ElasticsearchUserCreation
+	esUserCreation.ESServiceAccount = func(_ commonv1.Associated) (string, error) {
+		return KibanaServiceAccount, nil
+	}
+	esUserCreation.AssociatedPodLabels = func(associated types.NamespacedName) map[string]string {
+		return map[string]string{"kibana.k8s.elastic.co/name": associated.Name}
+	}
+	r.ElasticsearchUserCreation = &esUserCreation
+
+	tokenSecretKey := types.NamespacedName{Namespace: kibanaNamespace, Name: "kbname-kibana-user-token"}
+	esTokenSecretKey := types.NamespacedName{Namespace: esNamespace, Name: "kbns-kbname-kibana-user-token"}
+	kibanaUserKey := k8s.ExtractNamespacedName(&kibanaUserInKibanaNamespace)
+	esUserKey := k8s.ExtractNamespacedName(&kibanaUserInESNamespace)
+
+	reconcileKibana := func() (reconcile.Result, commonv1.AssociationConf) {
+		t.Helper()
+		res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: kbNamespacedName})
+		require.NoError(t, err)
+		var updatedKibana kbv1.Kibana
+		require.NoError(t, r.Get(context.Background(), kbNamespacedName, &updatedKibana))
+		require.Equal(t, commonv1.AssociationEstablished, updatedKibana.Status.AssociationStatus)
+		conf, err := GetAssociationConf(updatedKibana.EsAssociation())
+		require.NoError(t, err)
+		return res, *conf
+	}
+	secretExists := func(key types.NamespacedName) bool {
+		t.Helper()
+		err := r.Get(context.Background(), key, &corev1.Secret{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		require.NoError(t, err)
+		return true
+	}
+	getToken := func() ([]byte, user.ServiceAccountToken) {
+		t.Helper()
+		// the token in the Kibana namespace
+		var tokenSecret corev1.Secret
+		require.NoError(t, r.Get(context.Background(), tokenSecretKey, &tokenSecret))
+		require.Len(t, tokenSecret.Data, 1)
+		token := tokenSecret.Data[ServiceAccountTokenField]
+		name, secret, err := parseServiceAccountBearerToken(token)
+		require.NoError(t, err)
+		require.Equal(t, "elastic/kibana/kbns-kbname-kibana-user", name)
+
+		// its hash in the Elasticsearch namespace
+		var esTokenSecret corev1.Secret
+		require.NoError(t, r.Get(context.Background(), esTokenSecretKey, &esTokenSecret))
+		require.Equal(t, user.ServiceAccountTokenType, esTokenSecret.Labels["common.k8s.elastic.co/type"])
+		require.Equal(t, []byte(name), esTokenSecret.Data[user.ServiceAccountTokenNameField])
+		hash := esTokenSecret.Data[user.ServiceAccountHashField]
+		require.True(t, serviceAccountTokenHashMatches(hash, secret))
+		return token, user.ServiceAccountToken{QualifiedName: name, Hash: hash}
+	}
+
+	// the token is created but Elasticsearch does not know about it yet: Kibana keeps using its user
+	res, conf := reconcileKibana()
+	require.True(t, res.Requeue)
+	require.False(t, conf.IsServiceAccount)
+	require.Equal(t, "kbname-kibana-user", conf.AuthSecretName)
+	require.Equal(t, "kbns-kbname-kibana-user", conf.AuthSecretKey)
+	token, esToken := getToken()
+	require.True(t, secretExists(esUserKey))
+
+	// the token is part of the file realm, but the Elasticsearch Pod was created by a previous version of the operator
+	// and cannot read it
+	fileRealm := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: esNamespace, Name: "esname-es-xpack-file-realm"},
+		Data:       map[string][]byte{user.ServiceTokensFile: user.ServiceAccountTokens{esToken}.FileBytes()},
+	}
+	require.NoError(t, r.Create(context.Background(), &fileRealm))
+	res, conf = reconcileKibana()
+	require.True(t, res.Requeue)
+	require.False(t, conf.IsServiceAccount)
+	require.True(t, secretExists(esUserKey))
+
+	// the Elasticsearch Pod is restarted with the service tokens file: Kibana switches to the token
+	esPod.Labels["elasticsearch.k8s.elastic.co/service-tokens"] = "true"
+	require.NoError(t, r.Update(context.Background(), &esPod))
+	res, conf = reconcileKibana()
+	require.True(t, res.Requeue)
+	require.True(t, conf.IsServiceAccount)
+	require.Equal(t, "kbname-kibana-user-token", conf.AuthSecretName)
+	require.Equal(t, ServiceAccountTokenField, conf.AuthSecretKey)
+	// the token is reused
+	reusedToken, _ := getToken()
+	require.Equal(t, token, reusedToken)
+
+	// the user is kept as long as the Kibana Pod created before the switch is running
+	res, _ = reconcileKibana()
+	require.True(t, res.Requeue)
+	require.True(t, secretExists(esUserKey))
+	require.True(t, secretExists(kibanaUserKey))
+
+	// the Kibana Pod is replaced: the user is deleted
+	require.NoError(t, r.Delete(context.Background(), &kbPod))
+	newKbPod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         kibanaNamespace,
+		Name:              "kbname-kb-1",
+		Labels:            map[string]string{"kibana.k8s.elastic.co/name": "kbname"},
+		CreationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+	}}
+	require.NoError(t, r.Create(context.Background(), &newKbPod))
+	res, conf = reconcileKibana()
+	require.False(t, res.Requeue)
+	require.True(t, conf.IsServiceAccount)
+	require.False(t, secretExists(esUserKey))
+	require.False(t, secretExists(kibanaUserKey))
+
+	// the token is removed when unbinding
+	var updatedKibana kbv1.Kibana
+	require.NoError(t, r.Get(context.Background(), kbNamespacedName, &updatedKibana))
+	require.NoError(t, r.Unbind(updatedKibana.EsAssociation()))
+	require.False(t, secretExists(esTokenSecretKey))
+}
@@ -0,0 +1,241 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package association
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.elastic.co/apm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+const (
+	// Below are the keys of the Secret describing how to connect to a resource not managed by the operator.
+	UnmanagedAssociationURLKey      = "url"
+	UnmanagedAssociationUsernameKey = "username"
+	UnmanagedAssociationPasswordKey = "password" //nolint:gosec
+	UnmanagedAssociationCAKey       = certificates.CAFileName
+
+	// unmanagedVersionReqTimeout is the duration after which a request to retrieve the version of a resource not
+	// managed by the operator should be canceled.
+	unmanagedVersionReqTimeout = 1 * time.Minute
+)
+
+// unmanagedVersionPaths are the HTTP paths of the APIs returning the version of the referenced resources not managed
+// by the operator, for the association types supporting such references.
+var unmanagedVersionPaths = map[commonv1.AssociationType]string{
+	commonv1.ElasticsearchAssociationType: "/",
+	commonv1.EsMonitoringAssociationType:  "/",
+	commonv1.KbMonitoringAssociationType:  "/",
+	commonv1.KibanaAssociationType:        "/api/status",
+}
+
+// UnmanagedAssociationConnectionInfo holds the information, read from a user provided Secret, to connect to a
+// resource not managed by the operator.
+type UnmanagedAssociationConnectionInfo struct {
+	URL      string
+	Username string
+	Password string
+	CACert   []byte
+}
+
+// GetUnmanagedAssociationConnectionInfoFromSecret returns the connection information stored in the Secret referenced
+// by the given association. The Secret is expected in the namespace of the associated resource.
+func GetUnmanagedAssociationConnectionInfoFromSecret(c k8s.Client, association commonv1.Association) (UnmanagedAssociationConnectionInfo, error) {
+	secretNsn := types.NamespacedName{Namespace: association.GetNamespace(), Name: association.AssociationRef().SecretName}
+	var secret corev1.Secret
+	if err := c.Get(context.Background(), secretNsn, &secret); err != nil {
+		return UnmanagedAssociationConnectionInfo{}, err
+	}
+
+	info := UnmanagedAssociationConnectionInfo{CACert: secret.Data[UnmanagedAssociationCAKey]}
+	for key, value := range map[string]*string{
+		UnmanagedAssociationURLKey:      &info.URL,
+		UnmanagedAssociationUsernameKey: &info.Username,
+		UnmanagedAssociationPasswordKey: &info.Password,
+	} {
+		data, exists := secret.Data[key]
+		if !exists || len(data) == 0 {
+			return UnmanagedAssociationConnectionInfo{}, errors.Errorf("%s secret key doesn't exist in secret %s", key, secretNsn.Name)
+		}
+		*value = string(data)
+	}
+	return info, nil
+}
+
+// Version requests the version of the resource not managed by the operator at the given path, from a JSON response
+// holding the version in a version.number field, as returned by both Elasticsearch and Kibana.
+func (i UnmanagedAssociationConnectionInfo) Version(ctx context.Context, dialer net.Dialer, path string) (string, error) {
+	var caCerts []*x509.Certificate
+	if len(i.CACert) > 0 {
+		certs, err := certificates.ParsePEMCerts(i.CACert)
+		if err != nil {
+			return "", err
+		}
+		caCerts = certs
+	}
+	httpClient := common.HTTPClient(dialer, caCerts, unmanagedVersionReqTimeout)
+	defer httpClient.CloseIdleConnections()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, unmanagedVersionReqTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(timeoutCtx, http.MethodGet, stringsutil.Concat(i.URL, path), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(i.Username, i.Password)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("invalid response (status code %d) while retrieving the version from %s: %s", resp.StatusCode, i.URL, respBody)
+	}
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	if info.Version.Number == "" {
+		return "", fmt.Errorf("no version found in the response from %s", i.URL)
+	}
+	return info.Version.Number, nil
+}
+
+// reconcileUnmanagedAssociation reconciles an association to a resource not managed by the operator, described by
+// a user provided Secret. The connection information is copied over the same auth and CA Secrets as for resources
+// managed by the operator, so that the associated resource can rely on the usual association configuration.
+func (r *Reconciler) reconcileUnmanagedAssociation(ctx context.Context, association commonv1.Association) (commonv1.AssociationStatus, error) {
+	span, ctx := apm.StartSpan(ctx, "reconcile_unmanaged_association", tracing.SpanTypeApp)
+	defer span.End()
+
+	versionPath, supported := unmanagedVersionPaths[r.AssociationType]
+	if !supported || r.ElasticsearchUserCreation == nil {
+		r.recorder.Eventf(association, corev1.EventTypeWarning, events.EventAssociationError,
+			"References through secretName are not supported for %s associations", r.AssociationName)
+		return commonv1.AssociationFailed, RemoveAssociationConf(r.Client, association)
+	}
+
+	info, err := GetUnmanagedAssociationConnectionInfoFromSecret(r.Client, association)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, association, events.EventAssociationError,
+			"Failed to read the connection information from secret %s: %v", association.AssociationRef().SecretName, err)
+		if apierrors.IsNotFound(err) {
+			// the Secret does not exist (yet), remove any existing association conf and wait for the Secret to be created
+			return commonv1.AssociationPending, RemoveAssociationConf(r.Client, association)
+		}
+		return commonv1.AssociationFailed, err
+	}
+
+	assocLabels := r.AssociationResourceLabels(k8s.ExtractNamespacedName(association), association.AssociationRef().NamespacedName())
+
+	caSecret, err := r.reconcileUnmanagedCASecret(association, assocLabels, info)
+	if err != nil {
+		return commonv1.AssociationPending, err
+	}
+
+	authSecretRef := UserSecretKeySelector(association, r.ElasticsearchUserCreation.UserSecretSuffix)
+	expectedAuthSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      authSecretRef.Name,
+			Namespace: association.GetNamespace(),
+			Labels:    common.AddCredentialsLabel(assocLabels),
+		},
+		Data: map[string][]byte{
+			info.Username: []byte(info.Password),
+		},
+	}
+	if _, err := reconciler.ReconcileSecret(r.Client, expectedAuthSecret, association.Associated(), r.ServerSideApply); err != nil {
+		return commonv1.AssociationPending, err
+	}
+
+	ver, err := info.Version(ctx, r.Dialer, versionPath)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, association, events.EventAssociationError,
+			"Failed to retrieve the version of the referenced resource from %s: %v", info.URL, err)
+		return commonv1.AssociationPending, err
+	}
+
+	return r.updateAssocConf(ctx, &commonv1.AssociationConf{
+		AuthSecretName: authSecretRef.Name,
+		AuthSecretKey:  info.Username,
+		CACertProvided: caSecret.CACertProvided,
+		CASecretName:   caSecret.Name,
+		URL:            info.URL,
+		Version:        ver,
+	}, association)
+}
+
+// reconcileUnmanagedCASecret copies the CA certificate of the resource not managed by the operator, if any, in a Secret
+// structured as the public HTTP certificates Secret of the resources managed by the operator.
+func (r *Reconciler) reconcileUnmanagedCASecret(
+	association commonv1.Association,
+	labels map[string]string,
+	info UnmanagedAssociationConnectionInfo,
+) (CASecret, error) {
+	caSecretNsn := types.NamespacedName{Namespace: association.GetNamespace(), Name: CACertSecretName(association, r.AssociationName)}
+	if len(info.CACert) == 0 {
+		// no CA provided, the resource is expected to be reachable with publicly trusted certificates or without TLS
+		var existing corev1.Secret
+		if err := r.Get(context.Background(), caSecretNsn, &existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				return CASecret{}, nil
+			}
+			return CASecret{}, err
+		}
+		if err := r.Delete(context.Background(), &existing); err != nil && !apierrors.IsNotFound(err) {
+			return CASecret{}, err
+		}
+		return CASecret{}, nil
+	}
+
+	expectedSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: caSecretNsn.Namespace,
+			Name:      caSecretNsn.Name,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			certificates.CAFileName:   info.CACert,
+			certificates.CertFileName: info.CACert,
+		},
+	}
+	if _, err := reconciler.ReconcileSecret(r, expectedSecret, association.Associated(), r.ServerSideApply); err != nil {
+		return CASecret{}, err
+	}
+	return CASecret{Name: expectedSecret.Name, CACertProvided: true}, nil
+}
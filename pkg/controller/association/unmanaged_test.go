@@ -0,0 +1,208 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package association
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// versionHandler mimics the Elasticsearch root API, requiring the given credentials.
+func versionHandler(t *testing.T, username, password string) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"instance-0000000000","version":{"number":"7.16.2"}}`))
+	}
+}
+
+func unmanagedSecret(data map[string]string) *corev1.Secret {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kibanaNamespace, Name: "external-es"},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return &secret
+}
+
+func sampleKibanaWithUnmanagedESRef() kbv1.Kibana {
+	kb := sampleKibanaNoEsRef()
+	kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{SecretName: "external-es"}
+	return kb
+}
+
+func TestGetUnmanagedAssociationConnectionInfoFromSecret(t *testing.T) {
+	kb := sampleKibanaWithUnmanagedESRef()
+	tests := []struct {
+		name    string
+		secret  *corev1.Secret
+		want    UnmanagedAssociationConnectionInfo
+		wantErr bool
+	}{
+		{
+			name:    "secret does not exist",
+			wantErr: true,
+		},
+		{
+			name:    "missing password",
+			secret:  unmanagedSecret(map[string]string{"url": "https://es.example.com:9243", "username": "elastic"}),
+			wantErr: true,
+		},
+		{
+			name:   "without CA",
+			secret: unmanagedSecret(map[string]string{"url": "https://es.example.com:9243", "username": "elastic", "password": "changeme"}),
+			want:   UnmanagedAssociationConnectionInfo{URL: "https://es.example.com:9243", Username: "elastic", Password: "changeme"},
+		},
+		{
+			name:   "with CA",
+			secret: unmanagedSecret(map[string]string{"url": "https://es.example.com:9243", "username": "elastic", "password": "changeme", "ca.crt": "ca"}),
+			want:   UnmanagedAssociationConnectionInfo{URL: "https://es.example.com:9243", Username: "elastic", Password: "changeme", CACert: []byte("ca")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.NewFakeClient()
+			if tt.secret != nil {
+				c = k8s.NewFakeClient(tt.secret)
+			}
+			got, err := GetUnmanagedAssociationConnectionInfoFromSecret(c, kb.EsAssociation())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnmanagedAssociationConnectionInfo_Version(t *testing.T) {
+	server := httptest.NewTLSServer(versionHandler(t, "elastic", "changeme"))
+	defer server.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	tests := []struct {
+		name    string
+		info    UnmanagedAssociationConnectionInfo
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "trusted CA and valid credentials",
+			info: UnmanagedAssociationConnectionInfo{URL: server.URL, Username: "elastic", Password: "changeme", CACert: ca},
+			want: "7.16.2",
+		},
+		{
+			name:    "invalid credentials",
+			info:    UnmanagedAssociationConnectionInfo{URL: server.URL, Username: "elastic", Password: "wrong", CACert: ca},
+			wantErr: true,
+		},
+		{
+			name:    "untrusted certificate",
+			info:    UnmanagedAssociationConnectionInfo{URL: server.URL, Username: "elastic", Password: "changeme"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.info.Version(context.Background(), nil, "/")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconciler_Reconcile_UnmanagedAssociation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":{"number":"7.16.2"}}`))
+	}))
+	defer server.Close()
+
+	kb := sampleKibanaWithUnmanagedESRef()
+	secret := unmanagedSecret(map[string]string{"url": server.URL, "username": "elastic", "password": "changeme", "ca.crt": "ca"})
+	r := NewTestAssociationReconciler(kbAssociationInfo, &kb, secret)
+
+	reconcileAndGetConf := func() *commonv1.AssociationConf {
+		t.Helper()
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: kbNamespacedName})
+		require.NoError(t, err)
+		var updatedKibana kbv1.Kibana
+		require.NoError(t, r.Get(context.Background(), kbNamespacedName, &updatedKibana))
+		require.Equal(t, commonv1.AssociationEstablished, updatedKibana.Status.AssociationStatus)
+		conf, err := GetAssociationConf(updatedKibana.EsAssociation())
+		require.NoError(t, err)
+		return conf
+	}
+
+	// the association conf points to the connection information copied from the user provided secret
+	conf := reconcileAndGetConf()
+	require.Equal(t, &commonv1.AssociationConf{
+		AuthSecretName: "kbname-kibana-user",
+		AuthSecretKey:  "elastic",
+		CACertProvided: true,
+		CASecretName:   "kbname-kb-es-ca",
+		URL:            server.URL,
+		Version:        "7.16.2",
+	}, conf)
+	var authSecret corev1.Secret
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: kibanaNamespace, Name: conf.AuthSecretName}, &authSecret))
+	require.Equal(t, map[string][]byte{"elastic": []byte("changeme")}, authSecret.Data)
+	var caSecret corev1.Secret
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: kibanaNamespace, Name: conf.CASecretName}, &caSecret))
+	require.Equal(t, []byte("ca"), caSecret.Data["ca.crt"])
+	// no user is created in an Elasticsearch cluster
+	var users corev1.SecretList
+	require.NoError(t, r.List(context.Background(), &users, r.userLabelSelector(kbNamespacedName, kb.EsAssociation().AssociationRef().NamespacedName())))
+	require.Empty(t, users.Items)
+	// the user provided secret is watched
+	require.Contains(t, r.watches.Secrets.Registrations(), unmanagedSecretWatchName(kbNamespacedName))
+
+	// credentials rotation and CA removal are propagated
+	secret.Data = map[string][]byte{"url": []byte(server.URL), "username": []byte("elastic"), "password": []byte("rotated")}
+	require.NoError(t, r.Update(context.Background(), secret))
+	conf = reconcileAndGetConf()
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: kibanaNamespace, Name: conf.AuthSecretName}, &authSecret))
+	require.Equal(t, map[string][]byte{"elastic": []byte("rotated")}, authSecret.Data)
+	require.False(t, conf.CACertProvided)
+	require.Empty(t, conf.CASecretName)
+	err := r.Get(context.Background(), types.NamespacedName{Namespace: kibanaNamespace, Name: "kbname-kb-es-ca"}, &caSecret)
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconciler_Reconcile_UnmanagedAssociation_MissingSecret(t *testing.T) {
+	kb := sampleKibanaWithUnmanagedESRef()
+	r := NewTestAssociationReconciler(kbAssociationInfo, &kb)
+
+	res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: kbNamespacedName})
+	require.NoError(t, err)
+	// requeue while waiting for the secret to be created
+	require.Equal(t, defaultRequeue, res)
+	var updatedKibana kbv1.Kibana
+	require.NoError(t, r.Get(context.Background(), kbNamespacedName, &updatedKibana))
+	require.Equal(t, commonv1.AssociationPending, updatedKibana.Status.AssociationStatus)
+}
@@ -83,6 +83,7 @@ func reconcileEsUserSecret(
 	userRoles string,
 	userObjectSuffix string,
 	es esv1.Elasticsearch,
+	serverSideApply bool,
 ) error {
 	span, _ := apm.StartSpan(ctx, "reconcile_es_user", tracing.SpanTypeApp)
 	defer span.End()
@@ -115,7 +116,7 @@ func reconcileEsUserSecret(
 	}
 	expectedSecret.Data[usrKey.Name] = password
 
-	if _, err := reconciler.ReconcileSecret(c, expectedSecret, association.Associated()); err != nil {
+	if _, err := reconciler.ReconcileSecret(c, expectedSecret, association.Associated(), serverSideApply); err != nil {
 		return err
 	}
 
@@ -164,6 +165,6 @@ func reconcileEsUserSecret(
 	expectedEsUser.Data[esuser.PasswordHashField] = bcryptHash
 
 	owner := es // user is owned by the es resource in es namespace
-	_, err = reconciler.ReconcileSecret(c, expectedEsUser, &owner)
+	_, err = reconciler.ReconcileSecret(c, expectedEsUser, &owner, serverSideApply)
 	return err
 }
@@ -6,11 +6,9 @@ package elasticsearch
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -18,14 +16,11 @@ import (
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/autoscaling/elasticsearch/status"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -67,7 +62,7 @@ func NewReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileEl
 	return &ReconcileElasticsearch{
 		Client:           c,
 		Parameters:       params,
-		esClientProvider: newElasticsearchClient,
+		esClientProvider: user.ControllerUserClientProvider(params.DryRun),
 		recorder:         mgr.GetEventRecorderFor(controllerName),
 		licenseChecker:   license.NewLicenseChecker(c, params.OperatorNamespace),
 	}
@@ -110,6 +105,16 @@ func (r *ReconcileElasticsearch) Reconcile(ctx context.Context, request reconcil
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&es) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if es.IsInMaintenance() {
+		log.Info("Cluster in maintenance mode. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+		return reconcile.Result{}, nil
+	}
+
 	// Get resource policies from the Elasticsearch spec
 	autoscalingSpecification, err := es.GetAutoscalingSpecification()
 	if err != nil {
@@ -166,60 +171,3 @@ func defaultResult(autoscalingSpecification esv1.AutoscalingSpec) reconcile.Resu
 		RequeueAfter: autoscalingSpecification.GetPollingPeriodOrDefault(),
 	}
 }
-
-func newElasticsearchClient(
-	ctx context.Context,
-	c k8s.Client,
-	dialer net.Dialer,
-	es esv1.Elasticsearch,
-) (esclient.Client, error) {
-	defer tracing.Span(&ctx)()
-	url := services.ExternalServiceURL(es)
-	v, err := version.Parse(es.Spec.Version)
-	if err != nil {
-		return nil, err
-	}
-	// Get user Secret
-	var controllerUserSecret corev1.Secret
-	key := types.NamespacedName{
-		Namespace: es.Namespace,
-		Name:      esv1.InternalUsersSecret(es.Name),
-	}
-	if err := c.Get(context.Background(), key, &controllerUserSecret); err != nil {
-		return nil, err
-	}
-	password, ok := controllerUserSecret.Data[user.ControllerUserName]
-	if !ok {
-		return nil, fmt.Errorf("controller user %s not found in Secret %s/%s", user.ControllerUserName, key.Namespace, key.Name)
-	}
-
-	// Get public certs
-	var caSecret corev1.Secret
-	key = types.NamespacedName{
-		Namespace: es.Namespace,
-		Name:      certificates.PublicCertsSecretName(esv1.ESNamer, es.Name),
-	}
-	if err := c.Get(context.Background(), key, &caSecret); err != nil {
-		return nil, err
-	}
-	trustedCerts, ok := caSecret.Data[certificates.CertFileName]
-	if !ok {
-		return nil, fmt.Errorf("%s not found in Secret %s/%s", certificates.CertFileName, key.Namespace, key.Name)
-	}
-	caCerts, err := certificates.ParsePEMCerts(trustedCerts)
-	if err != nil {
-		return nil, err
-	}
-	return esclient.NewElasticsearchClient(
-		dialer,
-		k8s.ExtractNamespacedName(&es),
-		url,
-		esclient.BasicAuth{
-			Name:     user.ControllerUserName,
-			Password: string(password),
-		},
-		v,
-		caCerts,
-		esclient.Timeout(es),
-	), nil
-}
@@ -21,6 +21,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/autoscaling/elasticsearch/status"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
 	logconf "github.com/elastic/cloud-on-k8s/pkg/utils/log"
 )
@@ -127,7 +128,7 @@ func (r *ReconcileElasticsearch) attemptOnlineReconciliation(
 
 	// Update Machine Learning settings
 	mlNodes, maxMemory := autoscalingSpec.GetMLNodesSettings()
-	if err := esClient.UpdateMLNodesSettings(ctx, mlNodes, maxMemory); err != nil {
+	if err := esClient.UpdateMLNodesSettings(ctx, mlNodes, maxMemory); err != nil && !esclient.IsDryRun(err) {
 		log.Error(err, "Error while updating the ML settings")
 		return reconcile.Result{}, err
 	}
@@ -24,14 +24,14 @@ func updatePolicies(
 ) error {
 	span, _ := apm.StartSpan(ctx, "update_autoscaling_policies", tracing.SpanTypeApp)
 	defer span.End()
-	// Cleanup existing autoscaling policies
-	if err := esclient.DeleteAutoscalingPolicies(ctx); err != nil {
+	// Cleanup existing autoscaling policies, the requests are not sent in dry-run mode
+	if err := esclient.DeleteAutoscalingPolicies(ctx); err != nil && !client.IsDryRun(err) {
 		log.Error(err, "Error while deleting policies")
 		return err
 	}
 	// Create the expected autoscaling policies
 	for _, rp := range autoscalingSpec.AutoscalingPolicySpecs {
-		if err := esclient.CreateAutoscalingPolicy(ctx, rp.Name, rp.AutoscalingPolicy); err != nil {
+		if err := esclient.CreateAutoscalingPolicy(ctx, rp.Name, rp.AutoscalingPolicy); err != nil && !client.IsDryRun(err) {
 			log.Error(err, "Error while updating an autoscaling policy", "policy", rp.Name)
 			return err
 		}
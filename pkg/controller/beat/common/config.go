@@ -133,7 +133,7 @@ func reconcileConfig(
 		},
 	}
 
-	if _, err = reconciler.ReconcileSecret(params.Client, expected, &params.Beat); err != nil {
+	if _, err = reconciler.ReconcileSecret(params.Client, expected, &params.Beat, params.OperatorParams.ServerSideApply); err != nil {
 		return err
 	}
 
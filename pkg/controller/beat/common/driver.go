@@ -14,9 +14,11 @@ import (
 
 	beatv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/beat/v1beta1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	commonassociation "github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/container"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
@@ -37,8 +39,12 @@ type DriverParams struct {
 	Client        k8s.Client
 	EventRecorder record.EventRecorder
 	Watches       watches.DynamicWatches
+	// StatusWriter coalesces successive status updates of the same Beat.
+	StatusWriter *common.StatusWriter
 
 	Beat beatv1beta1.Beat
+
+	OperatorParams operator.Parameters
 }
 
 func (dp DriverParams) K8sClient() k8s.Client {
@@ -30,6 +30,12 @@ const (
 	DataMountPathTemplate = "/var/lib/%s/%s/%s-data"
 	DataPathTemplate      = "/usr/share/%s/data"
 
+	// Windows nodes do not support mounting single files nor Linux host paths: the configuration Secret is mounted
+	// as a directory and Beat data is persisted under C:\ProgramData on the host.
+	WindowsConfigMountPath       = `C:\ProgramData\Elastic\Beats\config`
+	WindowsDataMountPathTemplate = `C:\ProgramData\Elastic\Beats\%s\%s\%s-data`
+	WindowsDataPathTemplate      = `C:\Program Files\Elastic\Beats\%s\data`
+
 	// ConfigChecksumLabel is a label used to store a Beat config checksum.
 	ConfigChecksumLabel = "beat.k8s.elastic.co/config-checksum"
 
@@ -80,21 +86,37 @@ func buildPodTemplate(
 		namer,
 		NewLabels(params.Beat),
 		initContainerParameters(params.Beat.Spec.Type),
+		params.OperatorParams.ServerSideApply,
 	)
 	if err != nil {
 		return podTemplate, err
 	}
 
 	spec := &params.Beat.Spec
-	dataVolume := createDataVolume(params)
-	vols := []volume.VolumeLike{
-		volume.NewSecretVolume(
+	windows := commonv1.IsWindowsPodTemplate(podTemplate)
+	configVolume := volume.NewSecretVolume(
+		ConfigSecretName(spec.Type, params.Beat.Name),
+		ConfigVolumeName,
+		ConfigMountPath,
+		ConfigFileName,
+		0444)
+	args := []string{"-e", "-c", ConfigMountPath}
+	if windows {
+		configVolume = volume.NewSecretVolume(
 			ConfigSecretName(spec.Type, params.Beat.Name),
 			ConfigVolumeName,
-			ConfigMountPath,
-			ConfigFileName,
-			0444),
-		dataVolume,
+			WindowsConfigMountPath,
+			"",
+			0444)
+		args = []string{
+			"-e",
+			"-c", WindowsConfigMountPath + `\` + ConfigFileName,
+			"--path.data", fmt.Sprintf(WindowsDataPathTemplate, spec.Type),
+		}
+	}
+	vols := []volume.VolumeLike{
+		configVolume,
+		createDataVolume(params, windows),
 	}
 
 	for _, association := range params.Beat.GetAssociations() {
@@ -132,7 +154,7 @@ func buildPodTemplate(
 		WithLabels(labels).
 		WithResources(defaultResources).
 		WithDockerImage(spec.Image, container.ImageRepository(defaultImage, spec.Version)).
-		WithArgs("-e", "-c", ConfigMountPath).
+		WithArgs(args...).
 		WithVolumes(volumes...).
 		WithVolumeMounts(volumeMounts...).
 		WithInitContainers(initContainers...).
@@ -141,9 +163,13 @@ func buildPodTemplate(
 	return builder.PodTemplate, nil
 }
 
-func createDataVolume(dp DriverParams) volume.VolumeLike {
+func createDataVolume(dp DriverParams, windows bool) volume.VolumeLike {
 	dataMountPath := fmt.Sprintf(DataPathTemplate, dp.Beat.Spec.Type)
 	hostDataPath := fmt.Sprintf(DataMountPathTemplate, dp.Beat.Namespace, dp.Beat.Name, dp.Beat.Spec.Type)
+	if windows {
+		dataMountPath = fmt.Sprintf(WindowsDataPathTemplate, dp.Beat.Spec.Type)
+		hostDataPath = fmt.Sprintf(WindowsDataMountPathTemplate, dp.Beat.Namespace, dp.Beat.Name, dp.Beat.Spec.Type)
+	}
 
 	return volume.NewHostVolume(
 		DataVolumeName,
@@ -165,6 +165,50 @@ func Test_buildPodTemplate(t *testing.T) {
 	}
 }
 
+func Test_buildPodTemplate_windows(t *testing.T) {
+	params := DriverParams{
+		Watches: watches.NewDynamicWatches(),
+		Client:  k8s.NewFakeClient(),
+		Beat: v1beta1.Beat{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "beat-name",
+				Namespace: "beat-namespace",
+			},
+			Spec: v1beta1.BeatSpec{
+				Type:    "filebeat",
+				Version: "7.15.0",
+				Image:   "my-registry/filebeat-windows:7.15.0",
+				DaemonSet: &v1beta1.DaemonSetSpec{
+					PodTemplate: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							NodeSelector: map[string]string{corev1.LabelOSStable: commonv1.WindowsOS},
+						},
+					},
+				},
+			},
+		},
+	}
+	podTemplateSpec, err := buildPodTemplate(params, "beats/filebeat", newHash("foobar"))
+	assert.NoError(t, err)
+	assertConfiguration(t, podTemplateSpec)
+
+	beatContainer := podTemplateSpec.Spec.Containers[0]
+	assert.Equal(t, []string{
+		"-e",
+		"-c", `C:\ProgramData\Elastic\Beats\config\beat.yml`,
+		"--path.data", `C:\Program Files\Elastic\Beats\filebeat\data`,
+	}, beatContainer.Args)
+	for _, mount := range beatContainer.VolumeMounts {
+		// Windows nodes cannot mount single files
+		assert.Empty(t, mount.SubPath)
+	}
+	for _, vol := range podTemplateSpec.Spec.Volumes {
+		if vol.Name == DataVolumeName {
+			assert.Equal(t, `C:\ProgramData\Elastic\Beats\beat-namespace\beat-name\filebeat-data`, vol.HostPath.Path)
+		}
+	}
+}
+
 // decimal value of '0444' in octal is 292
 var expectedConfigVolumeMode int32 = 292
 
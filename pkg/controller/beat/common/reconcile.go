@@ -6,6 +6,8 @@ package common
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -56,6 +58,7 @@ func reconcilePodVehicle(podTemplate corev1.PodTemplateSpec, params DriverParams
 		client:      params.Client,
 		beat:        params.Beat,
 		podTemplate: podTemplate,
+		restricted:  params.OperatorParams.RestrictedSecurityContext,
 	})
 	if err != nil {
 		return results.WithError(err)
@@ -71,7 +74,7 @@ func reconcilePodVehicle(podTemplate corev1.PodTemplateSpec, params DriverParams
 		results.WithError(err)
 	}
 
-	err = updateStatus(params, ready, desired)
+	requeueAfter, err := updateStatus(params, ready, desired)
 	if err != nil && apierrors.IsConflict(err) {
 		params.Logger.V(1).Info(
 			"Conflict while updating status",
@@ -79,6 +82,10 @@ func reconcilePodVehicle(podTemplate corev1.PodTemplateSpec, params DriverParams
 			"beat_name", params.Beat.Name)
 		return results.WithResult(reconcile.Result{Requeue: true})
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 
 	return results.WithError(err)
 }
@@ -87,6 +94,7 @@ type ReconciliationParams struct {
 	client      k8s.Client
 	beat        beatv1beta1.Beat
 	podTemplate corev1.PodTemplateSpec
+	restricted  bool
 }
 
 func reconcileDeployment(rp ReconciliationParams) (int32, int32, error) {
@@ -103,7 +111,7 @@ func reconcileDeployment(rp ReconciliationParams) (int32, int32, error) {
 		return 0, 0, err
 	}
 
-	reconciled, err := deployment.Reconcile(rp.client, d, &rp.beat)
+	reconciled, err := deployment.Reconcile(rp.client, d, &rp.beat, rp.restricted)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -125,7 +133,7 @@ func reconcileDaemonSet(rp ReconciliationParams) (int32, int32, error) {
 		return 0, 0, err
 	}
 
-	reconciled, err := daemonset.Reconcile(rp.client, ds, &rp.beat)
+	reconciled, err := daemonset.Reconcile(rp.client, ds, &rp.beat, rp.restricted)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -133,17 +141,23 @@ func reconcileDaemonSet(rp ReconciliationParams) (int32, int32, error) {
 	return reconciled.Status.NumberReady, reconciled.Status.DesiredNumberScheduled, nil
 }
 
-func updateStatus(params DriverParams, ready, desired int32) error {
+// updateStatus writes the status of the Beat resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func updateStatus(params DriverParams, ready, desired int32) (time.Duration, error) {
 	beat := params.Beat
 
 	pods, err := k8s.PodsMatchingLabels(params.K8sClient(), beat.Namespace, map[string]string{NameLabelName: beat.Name})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	beat.Status.AvailableNodes = ready
 	beat.Status.ExpectedNodes = desired
 	beat.Status.Health = CalculateHealth(beat.GetAssociations(), ready, desired)
 	beat.Status.Version = common.LowestVersionFromPods(beat.Status.Version, pods, VersionLabelName)
 
-	return params.Client.Status().Update(context.Background(), &beat)
+	if reflect.DeepEqual(beat.Status, params.Beat.Status) {
+		return 0, nil // nothing to do
+	}
+	immediate := beat.Status.Health != params.Beat.Status.Health
+	return params.StatusWriter.Write(params.Client, &beat, immediate)
 }
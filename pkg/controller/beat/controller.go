@@ -64,6 +64,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileBe
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		Parameters:     params,
 	}
 }
@@ -119,6 +120,8 @@ type ReconcileBeat struct {
 	k8s.Client
 	recorder       record.EventRecorder
 	dynamicWatches watches.DynamicWatches
+	// statusWriter coalesces successive status updates of the same Beat.
+	statusWriter *common.StatusWriter
 	operator.Parameters
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
@@ -144,6 +147,11 @@ func (r *ReconcileBeat) Reconcile(ctx context.Context, request reconcile.Request
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&beat) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", beat.Namespace, "beat_name", beat.Name)
+		return reconcile.Result{}, nil
+	}
+
 	if beat.IsMarkedForDeletion() {
 		return reconcile.Result{}, nil
 	}
@@ -165,7 +173,7 @@ func (r *ReconcileBeat) doReconcile(ctx context.Context, beat beatv1beta1.Beat)
 		return results.WithError(err)
 	}
 
-	driverResults := newDriver(ctx, r.recorder, r.Client, r.dynamicWatches, beat).Reconcile()
+	driverResults := newDriver(ctx, r.recorder, r.Client, r.dynamicWatches, r.statusWriter, beat, r.Parameters).Reconcile()
 	results.WithResults(driverResults)
 
 	return results
@@ -187,6 +195,7 @@ func (r *ReconcileBeat) validate(ctx context.Context, beat *beatv1beta1.Beat) er
 func (r *ReconcileBeat) onDelete(obj types.NamespacedName) error {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(obj))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(common.ConfigRefWatchName(obj))
+	r.statusWriter.Forget(obj)
 	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, beatv1beta1.Kind)
 }
 
@@ -195,15 +204,19 @@ func newDriver(
 	recorder record.EventRecorder,
 	client k8s.Client,
 	dynamicWatches watches.DynamicWatches,
+	statusWriter *common.StatusWriter,
 	beat beatv1beta1.Beat,
+	params operator.Parameters,
 ) beatcommon.Driver {
 	dp := beatcommon.DriverParams{
-		Client:        client,
-		Context:       ctx,
-		Logger:        log,
-		Watches:       dynamicWatches,
-		EventRecorder: recorder,
-		Beat:          beat,
+		Client:         client,
+		Context:        ctx,
+		Logger:         log,
+		Watches:        dynamicWatches,
+		StatusWriter:   statusWriter,
+		EventRecorder:  recorder,
+		Beat:           beat,
+		OperatorParams: params,
 	}
 
 	switch beat.Spec.Type {
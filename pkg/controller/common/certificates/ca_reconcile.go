@@ -57,6 +57,7 @@ func ReconcileCAForOwner(
 	labels map[string]string,
 	caType CAType,
 	rotationParams RotationParams,
+	serverSideApply bool,
 ) (*CA, error) {
 	// retrieve current CA secret
 	caInternalSecret := corev1.Secret{}
@@ -70,24 +71,24 @@ func ReconcileCAForOwner(
 	}
 	if apierrors.IsNotFound(err) {
 		log.Info("No internal CA certificate Secret found, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType, serverSideApply)
 	}
 
 	// build CA
 	ca := BuildCAFromSecret(caInternalSecret)
 	if ca == nil {
 		log.Info("Cannot build CA from secret, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType, serverSideApply)
 	}
 
 	// renew or recreate from private key if cannot reuse
 	if !CanReuseCA(ca, rotationParams.RotateBefore) {
 		if ca.PrivateKey != nil && certExpiring(time.Now(), *ca.Cert, rotationParams.RotateBefore) {
 			log.Info("Existing CA is expiring, creating a new one from existing private key", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-			return renewCAFromExisting(cl, namer, owner, labels, rotationParams.Validity, caType, ca.PrivateKey)
+			return renewCAFromExisting(cl, namer, owner, labels, rotationParams.Validity, caType, ca.PrivateKey, serverSideApply)
 		}
 		log.Info("Cannot reuse existing CA, creating a new one", "owner_namespace", owner.GetNamespace(), "owner_name", owner.GetName(), "ca_type", caType)
-		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType)
+		return renewCA(cl, namer, owner, labels, rotationParams.Validity, caType, serverSideApply)
 	}
 
 	// reuse existing CA
@@ -108,6 +109,7 @@ func renewCAFromExisting(
 	expireIn time.Duration,
 	caType CAType,
 	signer crypto.Signer,
+	serverSideApply bool,
 ) (*CA, error) {
 	privateKey, ok := signer.(*rsa.PrivateKey)
 	if !ok {
@@ -118,7 +120,7 @@ func renewCAFromExisting(
 			"name", owner.GetName(),
 			"type", fmt.Sprintf("%T", signer),
 		)
-		return renewCA(client, namer, owner, labels, expireIn, caType)
+		return renewCA(client, namer, owner, labels, expireIn, caType, serverSideApply)
 	}
 
 	log.Info(
@@ -133,7 +135,7 @@ func renewCAFromExisting(
 		},
 		ExpireIn:   &expireIn,
 		PrivateKey: privateKey,
-	})
+	}, serverSideApply)
 }
 
 // renewCA creates and stores a new CA to replace one that might exist using a set of default builder options.
@@ -144,6 +146,7 @@ func renewCA(
 	labels map[string]string,
 	expireIn time.Duration,
 	caType CAType,
+	serverSideApply bool,
 ) (*CA, error) {
 	return renewCAWithOptions(client, namer, owner, labels, caType, CABuilderOptions{
 		Subject: pkix.Name{
@@ -151,7 +154,7 @@ func renewCA(
 			OrganizationalUnit: []string{owner.GetName()},
 		},
 		ExpireIn: &expireIn,
-	})
+	}, serverSideApply)
 }
 
 // renewCAWithOptions will create and store a new CA to replace one that might exist using a set of given builder options
@@ -163,6 +166,7 @@ func renewCAWithOptions(
 	labels map[string]string,
 	caType CAType,
 	options CABuilderOptions,
+	serverSideApply bool,
 ) (*CA, error) {
 	ca, err := NewSelfSignedCA(options)
 	if err != nil {
@@ -174,7 +178,7 @@ func renewCAWithOptions(
 	}
 
 	// create or update internal secret
-	if _, err := reconciler.ReconcileSecret(client, caInternalSecret, owner); err != nil {
+	if _, err := reconciler.ReconcileSecret(client, caInternalSecret, owner, serverSideApply); err != nil {
 		return nil, err
 	}
 
@@ -45,6 +45,7 @@ type Reconciler struct {
 	CertRotation   RotationParams // to requeue a reconciliation before cert expiration
 
 	GarbageCollectSecrets bool // if true, delete secrets if TLS is disabled
+	ServerSideApply       bool // if true, write the CA secret through server-side apply
 }
 
 // ReconcileCAAndHTTPCerts reconciles 3 TLS-related secrets for the given object:
@@ -81,6 +82,7 @@ func (r Reconciler) ReconcileCAAndHTTPCerts(ctx context.Context) (*CertificatesS
 			r.Labels,
 			HTTPCAType,
 			r.CACertRotation,
+			r.ServerSideApply,
 		)
 		if err != nil {
 			return nil, results.WithError(err)
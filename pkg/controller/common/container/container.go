@@ -6,6 +6,7 @@ package container
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -14,6 +15,8 @@ const DefaultContainerRegistry = "docker.elastic.co"
 var (
 	containerRegistry = DefaultContainerRegistry
 	containerSuffix   = ""
+	// imageRepositories overrides the repository of some images, regardless of the container registry and suffix.
+	imageRepositories = map[Image]string{}
 )
 
 // SetContainerRegistry sets the global container registry used to download Elastic stack images.
@@ -25,6 +28,12 @@ func SetContainerSuffix(suffix string) {
 	containerSuffix = suffix
 }
 
+// SetImageRepositories overrides the repository of the given images, for example to pull them from a private mirror
+// which does not follow the repository layout of the Elastic container registry.
+func SetImageRepositories(repositories map[Image]string) {
+	imageRepositories = repositories
+}
+
 type Image string
 
 const (
@@ -40,10 +49,60 @@ const (
 	PacketbeatImage       Image = "beats/packetbeat"
 	AgentImage            Image = "beats/elastic-agent"
 	MapsImage             Image = "elastic-maps-service/elastic-maps-server-ubi8"
+	LogstashImage         Image = "logstash/logstash"
 )
 
+// imagesByName indexes the images by the name used to override their repository.
+var imagesByName = map[string]Image{
+	"apm-server":          APMServerImage,
+	"elasticsearch":       ElasticsearchImage,
+	"kibana":              KibanaImage,
+	"enterprise-search":   EnterpriseSearchImage,
+	"filebeat":            FilebeatImage,
+	"metricbeat":          MetricbeatImage,
+	"heartbeat":           HeartbeatImage,
+	"auditbeat":           AuditbeatImage,
+	"journalbeat":         JournalbeatImage,
+	"packetbeat":          PacketbeatImage,
+	"elastic-agent":       AgentImage,
+	"elastic-maps-server": MapsImage,
+	"logstash":            LogstashImage,
+}
+
+// ParseImageRepositories parses <image>=<repository> pairs, such as elasticsearch=registry.example.com/elastic/es,
+// where image is one of elasticsearch, kibana, apm-server, enterprise-search, elastic-agent, elastic-maps-server,
+// logstash or the name of a Beat.
+func ParseImageRepositories(pairs []string) (map[Image]string, error) {
+	repositories := make(map[Image]string, len(pairs))
+	for _, pair := range pairs {
+		name, repository := pair, ""
+		if i := strings.Index(pair, "="); i >= 0 {
+			name, repository = strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		}
+		img, exists := imagesByName[name]
+		if !exists || repository == "" {
+			return nil, fmt.Errorf("invalid image repository %s, expected <image>=<repository> with image one of %s", pair, imageNames())
+		}
+		repositories[img] = repository
+	}
+	return repositories, nil
+}
+
+func imageNames() string {
+	names := make([]string, 0, len(imagesByName))
+	for name := range imagesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
 // ImageRepository returns the full container image name by concatenating the current container registry and the image path with the given version.
+// The repository of the image can be overridden with SetImageRepositories.
 func ImageRepository(img Image, version string) string {
+	if repository, exists := imageRepositories[img]; exists {
+		return fmt.Sprintf("%s:%s", repository, version)
+	}
 	// don't double append suffix if already contained as e.g. the case for maps
 	if strings.HasSuffix(string(img), containerSuffix) {
 		return fmt.Sprintf("%s/%s:%s", containerRegistry, img, version)
@@ -8,16 +8,18 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestImageRepository(t *testing.T) {
 	testRegistry := "my.docker.registry.com:8080"
 	testCases := []struct {
-		name    string
-		image   Image
-		suffix  string
-		version string
-		want    string
+		name         string
+		image        Image
+		suffix       string
+		repositories map[Image]string
+		version      string
+		want         string
 	}{
 		{
 			name:    "APM server image",
@@ -50,6 +52,21 @@ func TestImageRepository(t *testing.T) {
 			suffix:  "-ubi8",
 			want:    testRegistry + "/elastic-maps-service/elastic-maps-server-ubi8:7.12.0",
 		},
+		{
+			name:         "Elasticsearch image with repository override",
+			image:        ElasticsearchImage,
+			version:      "7.16.0",
+			suffix:       "-ubi8",
+			repositories: map[Image]string{ElasticsearchImage: "mirror.example.com/elastic/es"},
+			want:         "mirror.example.com/elastic/es:7.16.0",
+		},
+		{
+			name:         "Kibana image with another image repository override",
+			image:        KibanaImage,
+			version:      "7.16.0",
+			repositories: map[Image]string{ElasticsearchImage: "mirror.example.com/elastic/es"},
+			want:         testRegistry + "/kibana/kibana:7.16.0",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -57,15 +74,32 @@ func TestImageRepository(t *testing.T) {
 			// save and restore the current registry setting in case it has been modified
 			currentRegistry := containerRegistry
 			currentSuffix := containerSuffix
+			currentRepositories := imageRepositories
 			defer func() {
 				SetContainerRegistry(currentRegistry)
 				SetContainerSuffix(currentSuffix)
+				SetImageRepositories(currentRepositories)
 			}()
 
 			SetContainerRegistry(testRegistry)
 			SetContainerSuffix(tc.suffix)
+			SetImageRepositories(tc.repositories)
 			have := ImageRepository(tc.image, tc.version)
 			assert.Equal(t, tc.want, have)
 		})
 	}
 }
+
+func TestParseImageRepositories(t *testing.T) {
+	got, err := ParseImageRepositories([]string{"elasticsearch=mirror.example.com/elastic/es", " kibana = mirror.example.com/elastic/kb"})
+	require.NoError(t, err)
+	require.Equal(t, map[Image]string{
+		ElasticsearchImage: "mirror.example.com/elastic/es",
+		KibanaImage:        "mirror.example.com/elastic/kb",
+	}, got)
+
+	for _, invalid := range []string{"elasticsearch", "elasticsearch=", "unknown=mirror.example.com/elastic/unknown"} {
+		_, err := ParseImageRepositories([]string{invalid})
+		require.Error(t, err)
+	}
+}
@@ -20,8 +20,39 @@ import (
 )
 
 // NewController creates a new controller with the given name, reconciler and parameters and registers it with the manager.
+// If reconciliation is sharded, requests for resources not owned by the shard of this operator replica are ignored.
 func NewController(mgr manager.Manager, name string, r reconcile.Reconciler, p operator.Parameters) (controller.Controller, error) {
-	return controller.New(name, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: p.MaxConcurrentReconciles})
+	if p.Shard.Enabled() {
+		r = &shardedReconciler{Reconciler: r, shard: p.Shard}
+	}
+	if p.Liveness != nil {
+		r = &trackedReconciler{Reconciler: r, liveness: p.Liveness}
+	}
+	return controller.New(name, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: p.MaxConcurrentReconcilesFor(name)})
+}
+
+// shardedReconciler only reconciles the resources owned by a shard.
+type shardedReconciler struct {
+	reconcile.Reconciler
+	shard operator.Shard
+}
+
+func (r *shardedReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if !r.shard.Owns(request.NamespacedName) {
+		return reconcile.Result{}, nil
+	}
+	return r.Reconciler.Reconcile(ctx, request)
+}
+
+// trackedReconciler records its reconciliations in flight, so that the operator is restarted if they get stuck.
+type trackedReconciler struct {
+	reconcile.Reconciler
+	liveness *operator.Liveness
+}
+
+func (r *trackedReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer r.liveness.ReconcileStarted()()
+	return r.Reconciler.Reconcile(ctx, request)
 }
 
 // NewReconciliationContext increments iteration, creates an apm transaction and initiates the logger. Returns context
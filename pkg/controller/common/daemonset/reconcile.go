@@ -12,6 +12,7 @@ import (
 
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/securitycontext"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
@@ -46,12 +47,20 @@ func Reconcile(
 	k8sClient k8s.Client,
 	expected appsv1.DaemonSet,
 	owner client.Object,
+	restricted bool,
 ) (appsv1.DaemonSet, error) {
+	// render the Pods with restricted security contexts if the operator runs in restricted mode
+	template, err := securitycontext.Harden(expected.Spec.Template, restricted)
+	if err != nil {
+		return appsv1.DaemonSet{}, err
+	}
+	expected.Spec.Template = template
+
 	// label the daemon set with a hash of itself
 	expected = WithTemplateHash(expected)
 
 	reconciled := &appsv1.DaemonSet{}
-	err := reconciler.ReconcileResource(reconciler.Params{
+	err = reconciler.ReconcileResource(reconciler.Params{
 		Client:     k8sClient,
 		Owner:      owner,
 		Expected:   &expected,
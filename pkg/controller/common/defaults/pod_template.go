@@ -130,6 +130,14 @@ func (b *PodTemplateBuilder) WithAffinity(affinity *corev1.Affinity) *PodTemplat
 	return b
 }
 
+// WithTopologySpreadConstraints sets the given topology spread constraints, unless some are already provided in the template.
+func (b *PodTemplateBuilder) WithTopologySpreadConstraints(constraints ...corev1.TopologySpreadConstraint) *PodTemplateBuilder {
+	if len(b.PodTemplate.Spec.TopologySpreadConstraints) == 0 {
+		b.PodTemplate.Spec.TopologySpreadConstraints = constraints
+	}
+	return b
+}
+
 // WithPorts appends the given ports to the Container ports, unless already provided in the template.
 func (b *PodTemplateBuilder) WithPorts(ports []corev1.ContainerPort) *PodTemplateBuilder {
 	b.containerDefaulter.WithPorts(ports)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
@@ -66,7 +67,7 @@ func TestReconcile(t *testing.T) {
 	owner := esv1.Elasticsearch{} // can be any type
 
 	// should create a new deployment
-	reconciled, err := Reconcile(k8sClient, expected, &owner)
+	reconciled, err := Reconcile(k8sClient, expected, &owner, false)
 	require.NoError(t, err)
 	// reconciled should match expected spec, and have the hash label set
 	require.Equal(t, pointer.Int32(2), reconciled.Spec.Replicas)
@@ -79,13 +80,13 @@ func TestReconcile(t *testing.T) {
 	comparison.RequireEqual(t, &reconciled, &retrieved)
 
 	// reconciling the same should be a no-op
-	reconciledAgain, err := Reconcile(k8sClient, expected, &owner)
+	reconciledAgain, err := Reconcile(k8sClient, expected, &owner, false)
 	require.NoError(t, err)
 	comparison.RequireEqual(t, &reconciled, &reconciledAgain)
 
 	// update with a new spec
 	expected.Spec.Replicas = pointer.Int32(3)
-	reconciled, err = Reconcile(k8sClient, expected, &owner)
+	reconciled, err = Reconcile(k8sClient, expected, &owner, false)
 	require.NoError(t, err)
 	// both returned and retrieved should match that new spec
 	require.Equal(t, 3, int(*reconciled.Spec.Replicas))
@@ -94,3 +95,26 @@ func TestReconcile(t *testing.T) {
 	require.NoError(t, err)
 	comparison.RequireEqual(t, &reconciled, &retrieved)
 }
+
+func TestReconcile_restricted(t *testing.T) {
+	controllerscheme.SetupScheme()
+	k8sClient := k8s.NewFakeClient()
+	expected := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dep", Namespace: "ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}}},
+		},
+	}
+	owner := esv1.Elasticsearch{} // can be any type
+
+	// the Pods are rendered with restricted security contexts
+	reconciled, err := Reconcile(k8sClient, expected, &owner, true)
+	require.NoError(t, err)
+	require.True(t, *reconciled.Spec.Template.Spec.SecurityContext.RunAsNonRoot)
+	require.True(t, *reconciled.Spec.Template.Spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem)
+
+	// and the Deployment is not reconciled if the template weakens them
+	expected.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}}}
+	_, err = Reconcile(k8sClient, expected, &owner, true)
+	require.Error(t, err)
+}
@@ -30,6 +30,8 @@ const (
 	EventReasonStateChange = "StateChange"
 	// EventReasonRestart describes events where one or multiple Elasticsearch nodes are scheduled for a restart.
 	EventReasonRestart = "Restart"
+	// EventReasonRotated describes events where credentials were regenerated.
+	EventReasonRotated = "Rotated"
 )
 
 // Event reasons for Association controllers
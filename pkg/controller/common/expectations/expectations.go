@@ -74,7 +74,7 @@ technically less efficient.
 
 */
 
-// Expectations stores expectations for a single cluster. It is not thread-safe.
+// Expectations stores expectations for a single cluster. Only its ExpectedStatefulSetUpdates are thread-safe.
 type Expectations struct {
 	*ExpectedStatefulSetUpdates
 	*ExpectedPodDeletions
@@ -6,6 +6,7 @@ package expectations
 
 import (
 	"context"
+	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,8 +18,10 @@ import (
 // ExpectedStatefulSetUpdates stores StatefulSets generations that are expected in the cache,
 // following a StatefulSet update. It allows making sure we're not working with an
 // out-of-date version of the StatefulSet resource we previously updated.
+// It is safe for concurrent use, so that StatefulSets of the same cluster can be updated in parallel.
 type ExpectedStatefulSetUpdates struct {
 	client      k8s.Client
+	mutex       sync.Mutex
 	generations map[types.NamespacedName]ResourceGeneration // per StatefulSet
 }
 
@@ -41,6 +44,8 @@ func NewExpectedStatefulSetUpdates(client k8s.Client) *ExpectedStatefulSetUpdate
 // We expect to see its generation (at least) in GenerationsSatisfied().
 func (e *ExpectedStatefulSetUpdates) ExpectGeneration(statefulSet appsv1.StatefulSet) {
 	resource := types.NamespacedName{Namespace: statefulSet.Namespace, Name: statefulSet.Name}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 	e.generations[resource] = ResourceGeneration{
 		UID:        statefulSet.UID,
 		Generation: statefulSet.Generation,
@@ -51,6 +56,8 @@ func (e *ExpectedStatefulSetUpdates) ExpectGeneration(statefulSet appsv1.Statefu
 // and returns true if they all match.
 // Expectations are cleared once they are matched.
 func (e *ExpectedStatefulSetUpdates) GenerationsSatisfied() (bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 	allSatisfied := true
 	for statefulSet, expectedGen := range e.generations {
 		satisfied, err := e.generationSatisfied(statefulSet, expectedGen)
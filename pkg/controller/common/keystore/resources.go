@@ -5,13 +5,18 @@
 package keystore
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
 )
 
@@ -53,9 +58,10 @@ func NewResources(
 	namer name.Namer,
 	labels map[string]string,
 	initContainerParams InitContainerParameters,
+	serverSideApply bool,
 ) (*Resources, error) {
 	// setup a volume from the user-provided secure settings secret
-	secretVolume, version, err := secureSettingsVolume(r, hasKeystore, labels, namer)
+	secretVolume, version, err := secureSettingsVolume(r, hasKeystore, labels, namer, serverSideApply)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +82,21 @@ func NewResources(
 		Version:       version,
 	}, nil
 }
+
+// ExistingSecretSources returns the sources of the given Secrets which exist, for secure settings copied by other
+// controllers in Secrets which may not have been created yet.
+func ExistingSecretSources(c k8s.Client, namespace string, secretNames ...string) ([]commonv1.SecretSource, error) {
+	var sources []commonv1.SecretSource
+	for _, secretName := range secretNames {
+		var secret corev1.Secret
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: secretName}, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, commonv1.SecretSource{SecretName: secretName})
+	}
+	return sources, nil
+}
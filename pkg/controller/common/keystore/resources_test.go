@@ -226,7 +226,7 @@ echo "Keystore initialization successful."
 				Watches:      watches2.NewDynamicWatches(),
 				FakeRecorder: record.NewFakeRecorder(1000),
 			}
-			resources, err := NewResources(testDriver, &tt.kb, kbNamer, nil, tt.initContainerParameters)
+			resources, err := NewResources(testDriver, &tt.kb, kbNamer, nil, tt.initContainerParameters, false)
 			require.NoError(t, err)
 			if tt.wantNil {
 				require.Nil(t, resources)
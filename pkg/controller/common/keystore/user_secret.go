@@ -40,6 +40,7 @@ func secureSettingsVolume(
 	hasKeystore HasKeystore,
 	labels map[string]string,
 	namer name.Namer,
+	serverSideApply bool,
 ) (*volume.SecretVolume, string, error) {
 	// setup (or remove) watches for the user-provided secret to reconcile on any change
 	watcher := k8s.ExtractNamespacedName(hasKeystore)
@@ -56,7 +57,7 @@ func secureSettingsVolume(
 	if err != nil {
 		return nil, "", err
 	}
-	secret, err := reconcileSecureSettings(r.K8sClient(), hasKeystore, secrets, namer, labels)
+	secret, err := reconcileSecureSettings(r.K8sClient(), hasKeystore, secrets, namer, labels, serverSideApply)
 	if err != nil {
 		return nil, "", err
 	}
@@ -83,7 +84,9 @@ func reconcileSecureSettings(
 	hasKeystore HasKeystore,
 	userSecrets []corev1.Secret,
 	namer name.Namer,
-	labels map[string]string) (*corev1.Secret, error) {
+	labels map[string]string,
+	serverSideApply bool,
+) (*corev1.Secret, error) {
 	aggregatedData := map[string][]byte{}
 
 	for _, s := range userSecrets {
@@ -111,7 +114,7 @@ func reconcileSecureSettings(
 		return nil, err
 	}
 
-	secret, err := reconciler.ReconcileSecret(c, expected, hasKeystore)
+	secret, err := reconciler.ReconcileSecret(c, expected, hasKeystore, serverSideApply)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// controllerNames are the names of the controllers whose concurrency can be configured.
+var controllerNames = map[string]struct{}{
+	"agent-controller":                         {},
+	"apmserver-controller":                     {},
+	"beat-controller":                          {},
+	"elasticsearch-autoscaling":                {},
+	"elasticsearch-controller":                 {},
+	"enterprisesearch-controller":              {},
+	"kibana-controller":                        {},
+	"license-controller":                       {},
+	"logstash-controller":                      {},
+	"maps-controller":                          {},
+	"remoteca-controller":                      {},
+	"security-controller":                      {},
+	"snapshot-controller":                      {},
+	"stackconfigpolicy-controller":             {},
+	"trial-controller":                         {},
+	"agent-es-association-controller":          {},
+	"agent-fleetserver-association-controller": {},
+	"agent-kibana-association-controller":      {},
+	"apm-es-association-controller":            {},
+	"apm-kibana-association-controller":        {},
+	"beat-es-association-controller":           {},
+	"beat-kibana-association-controller":       {},
+	"ems-es-association-controller":            {},
+	"ent-es-association-controller":            {},
+	"es-monitoring-association-controller":     {},
+	"kb-ent-association-controller":            {},
+	"kb-es-association-controller":             {},
+	"kb-monitoring-association-controller":     {},
+	"ls-es-association-controller":             {},
+}
+
+// ParseControllerConcurrency parses <controller>=<count> pairs, such as elasticsearch-controller=10, into the maximum
+// number of concurrent reconciles of each controller. Unknown controller names are rejected.
+func ParseControllerConcurrency(pairs []string) (map[string]int, error) {
+	concurrency := make(map[string]int, len(pairs))
+	for _, pair := range pairs {
+		name, value := pair, ""
+		if i := strings.LastIndex(pair, "="); i >= 0 {
+			name, value = strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		}
+		count, err := strconv.Atoi(value)
+		if name == "" || err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid controller concurrency %s, expected <controller>=<count> with a positive count", pair)
+		}
+		if _, exists := controllerNames[name]; !exists {
+			return nil, fmt.Errorf("unknown controller %s in controller concurrency %s, expected one of %s", name, pair, knownControllerNames())
+		}
+		concurrency[name] = count
+	}
+	return concurrency, nil
+}
+
+func knownControllerNames() string {
+	names := make([]string, 0, len(controllerNames))
+	for name := range controllerNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// MaxConcurrentReconcilesFor returns the maximum number of concurrent reconciles of the controller with the given name.
+func (p Parameters) MaxConcurrentReconcilesFor(controllerName string) int {
+	if count, exists := p.ControllerConcurrency[controllerName]; exists {
+		return count
+	}
+	return p.MaxConcurrentReconciles
+}
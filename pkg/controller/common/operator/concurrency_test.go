@@ -0,0 +1,71 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseControllerConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]int
+		wantErr bool
+	}{
+		{
+			name:  "no pairs",
+			pairs: nil,
+			want:  map[string]int{},
+		},
+		{
+			name:  "several controllers",
+			pairs: []string{"elasticsearch-controller=10", " kibana-controller = 2"},
+			want:  map[string]int{"elasticsearch-controller": 10, "kibana-controller": 2},
+		},
+		{
+			name:    "missing count",
+			pairs:   []string{"elasticsearch-controller"},
+			wantErr: true,
+		},
+		{
+			name:    "missing controller",
+			pairs:   []string{"=10"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown controller",
+			pairs:   []string{"elasticsearch=10"},
+			wantErr: true,
+		},
+		{
+			name:    "count not positive",
+			pairs:   []string{"elasticsearch-controller=0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseControllerConcurrency(tt.pairs)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParameters_MaxConcurrentReconcilesFor(t *testing.T) {
+	p := Parameters{
+		MaxConcurrentReconciles: 3,
+		ControllerConcurrency:   map[string]int{"elasticsearch-controller": 10},
+	}
+	require.Equal(t, 10, p.MaxConcurrentReconcilesFor("elasticsearch-controller"))
+	require.Equal(t, 3, p.MaxConcurrentReconcilesFor("kibana-controller"))
+}
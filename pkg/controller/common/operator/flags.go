@@ -5,35 +5,67 @@
 package operator
 
 const (
-	AutoPortForwardFlag           = "auto-port-forward"
-	CACertRotateBeforeFlag        = "ca-cert-rotate-before"
-	CACertValidityFlag            = "ca-cert-validity"
-	CertRotateBeforeFlag          = "cert-rotate-before"
-	CertValidityFlag              = "cert-validity"
-	ConfigFlag                    = "config"
-	ContainerRegistryFlag         = "container-registry"
-	DebugHTTPListenFlag           = "debug-http-listen"
-	DisableConfigWatch            = "disable-config-watch"
-	DisableTelemetryFlag          = "disable-telemetry"
-	DistributionChannelFlag       = "distribution-channel"
-	ElasticsearchClientTimeout    = "elasticsearch-client-timeout"
-	EnableLeaderElection          = "enable-leader-election"
-	EnableTracingFlag             = "enable-tracing"
-	EnableWebhookFlag             = "enable-webhook"
-	EnforceRBACOnRefsFlag         = "enforce-rbac-on-refs"
-	ExposedNodeLabels             = "exposed-node-labels"
-	IPFamilyFlag                  = "ip-family"
-	KubeClientTimeout             = "kube-client-timeout"
-	ManageWebhookCertsFlag        = "manage-webhook-certs"
-	MaxConcurrentReconcilesFlag   = "max-concurrent-reconciles"
-	MetricsPortFlag               = "metrics-port"
-	NamespacesFlag                = "namespaces"
-	OperatorNamespaceFlag         = "operator-namespace"
-	SetDefaultSecurityContextFlag = "set-default-security-context"
-	TelemetryIntervalFlag         = "telemetry-interval"
-	UBIOnlyFlag                   = "ubi-only"
-	ValidateStorageClassFlag      = "validate-storage-class"
-	WebhookCertDirFlag            = "webhook-cert-dir"
-	WebhookNameFlag               = "webhook-name"
-	WebhookSecretFlag             = "webhook-secret"
+	AllocationExplainDelayFlag      = "allocation-explain-delay"
+	AutoPortForwardFlag             = "auto-port-forward"
+	CACertRotateBeforeFlag          = "ca-cert-rotate-before"
+	CACertValidityFlag              = "ca-cert-validity"
+	CertRotateBeforeFlag            = "cert-rotate-before"
+	CertValidityFlag                = "cert-validity"
+	ConfigFlag                      = "config"
+	ControllerConcurrencyFlag       = "controller-concurrency"
+	ContainerRegistryFlag           = "container-registry"
+	ContainerRepositoriesFlag       = "container-repositories"
+	DebugHTTPListenFlag             = "debug-http-listen"
+	DefaultTopologySpreadFlag       = "default-topology-spread"
+	DisableConfigWatch              = "disable-config-watch"
+	DisableTelemetryFlag            = "disable-telemetry"
+	DryRunFlag                      = "dry-run"
+	DistributionChannelFlag         = "distribution-channel"
+	ElasticsearchClientTimeout      = "elasticsearch-client-timeout"
+	ElasticsearchObserverInterval   = "elasticsearch-observer-interval"
+	ElasticsearchPluginsMirror      = "elasticsearch-plugins-mirror"
+	ElasticsearchResyncInterval     = "elasticsearch-resync-interval"
+	ElasticsearchSlowPollAfter      = "elasticsearch-slow-poll-after"
+	ElasticsearchSkipUnchanged      = "elasticsearch-skip-unchanged"
+	ElasticsearchSlowPollInterval   = "elasticsearch-slow-poll-interval"
+	ElasticsearchStateCacheTTL      = "elasticsearch-state-cache-ttl"
+	EnableDebugEndpointFlag         = "enable-debug-endpoint"
+	EnableLeaderElection            = "enable-leader-election"
+	EnableTracingFlag               = "enable-tracing"
+	EnableWebhookFlag               = "enable-webhook"
+	EnforceRBACOnRefsFlag           = "enforce-rbac-on-refs"
+	ExposedNodeLabels               = "exposed-node-labels"
+	HealthProbePortFlag             = "health-probe-port"
+	IPFamilyFlag                    = "ip-family"
+	KubeClientTimeout               = "kube-client-timeout"
+	LeaderElectionLeaseDurationFlag = "leader-election-lease-duration"
+	LeaderElectionNamespaceFlag     = "leader-election-namespace"
+	LeaderElectionRenewDeadlineFlag = "leader-election-renew-deadline"
+	LeaderElectionRetryPeriodFlag   = "leader-election-retry-period"
+	ManageWebhookCertsFlag          = "manage-webhook-certs"
+	MaxConcurrentReconcilesFlag     = "max-concurrent-reconciles"
+	MetricsCertDirFlag              = "metrics-cert-dir"
+	MetricsPortFlag                 = "metrics-port"
+	MetricsSecureFlag               = "metrics-secure"
+	NamespacesFlag                  = "namespaces"
+	OperatorNamespaceFlag           = "operator-namespace"
+	OperatorRolesFlag               = "operator-roles"
+	OTLPEndpointFlag                = "otlp-endpoint"
+	ResourceLabelSelectorFlag       = "resource-label-selector"
+	RestrictedSecurityContextFlag   = "restricted-security-context"
+	ServerSideApplyFlag             = "server-side-apply"
+	SetDefaultSecurityContextFlag   = "set-default-security-context"
+	ShardCountFlag                  = "shard-count"
+	ShardIndexFlag                  = "shard-index"
+	ShutdownGracePeriodFlag         = "shutdown-grace-period"
+	StatusFlushIntervalFlag         = "status-flush-interval"
+	TelemetryConfigMapFlag          = "telemetry-config-map"
+	TelemetryIntervalFlag           = "telemetry-interval"
+	UBIOnlyFlag                     = "ubi-only"
+	ValidateStorageClassFlag        = "validate-storage-class"
+	WebhookCertDirFlag              = "webhook-cert-dir"
+	WebhookNameFlag                 = "webhook-name"
+	WebhookSecretFlag               = "webhook-secret"
+	WebhookTunnelFlag               = "webhook-tunnel"
+	WebhookTunnelImageFlag          = "webhook-tunnel-image"
 )
@@ -0,0 +1,88 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// HeartbeatInterval is the interval at which the manager records a heartbeat once it has started its runnables.
+	HeartbeatInterval = 10 * time.Second
+	// heartbeatTimeout is the duration after which the operator is not live if the manager did not record a heartbeat.
+	heartbeatTimeout = 1 * time.Minute
+	// startupTimeout is the duration after which the operator is not live if the manager did not start its runnables.
+	startupTimeout = 10 * time.Minute
+	// maxReconcileDuration is the duration after which the operator is not live if a reconciliation is still in flight.
+	maxReconcileDuration = 10 * time.Minute
+)
+
+// Liveness tracks the progress of the manager and of the reconciliations of the controllers, so that a wedged operator
+// can be detected and restarted. A nil Liveness tracks nothing.
+type Liveness struct {
+	mutex     sync.Mutex
+	createdAt time.Time
+	lastBeat  time.Time
+	// inFlight holds the start time of the reconciliations in flight, by reconciliation ID.
+	inFlight map[uint64]time.Time
+	nextID   uint64
+}
+
+// NewLiveness returns a new Liveness expecting the manager to start its runnables within the startup timeout.
+func NewLiveness() *Liveness {
+	return &Liveness{createdAt: time.Now(), inFlight: make(map[uint64]time.Time)}
+}
+
+// Beat records a heartbeat of the manager.
+func (l *Liveness) Beat() {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.lastBeat = time.Now()
+}
+
+// ReconcileStarted records the start of a reconciliation, and returns a function to call once it is complete.
+func (l *Liveness) ReconcileStarted() func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	id := l.nextID
+	l.nextID++
+	l.inFlight[id] = time.Now()
+	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		delete(l.inFlight, id)
+	}
+}
+
+// Check returns an error if, at the given time, the manager did not start its runnables within the startup timeout,
+// stopped recording heartbeats, or if a reconciliation is stuck.
+func (l *Liveness) Check(now time.Time) error {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.lastBeat.IsZero() {
+		if now.Sub(l.createdAt) > startupTimeout {
+			return fmt.Errorf("manager not started after %s", startupTimeout)
+		}
+	} else if now.Sub(l.lastBeat) > heartbeatTimeout {
+		return fmt.Errorf("no manager heartbeat since %s", l.lastBeat.Format(time.RFC3339))
+	}
+	for _, startedAt := range l.inFlight {
+		if now.Sub(startedAt) > maxReconcileDuration {
+			return fmt.Errorf("reconciliation in flight since %s", startedAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveness_Check(t *testing.T) {
+	now := time.Now()
+
+	// the manager has some time to start
+	l := NewLiveness()
+	require.NoError(t, l.Check(now))
+	require.Error(t, l.Check(now.Add(startupTimeout+time.Second)))
+
+	// then records heartbeats
+	l.Beat()
+	require.NoError(t, l.Check(now.Add(time.Second)))
+	require.Error(t, l.Check(now.Add(heartbeatTimeout+time.Minute)))
+
+	// reconciliations in flight for too long are stuck
+	done := l.ReconcileStarted()
+	later := now.Add(maxReconcileDuration + time.Minute)
+	l.lastBeat = later
+	require.NoError(t, l.Check(now.Add(time.Second)))
+	require.Error(t, l.Check(later))
+	done()
+	require.NoError(t, l.Check(later))
+
+	// a nil Liveness tracks nothing
+	var none *Liveness
+	none.Beat()
+	none.ReconcileStarted()()
+	require.NoError(t, none.Check(now.Add(startupTimeout+time.Second)))
+}
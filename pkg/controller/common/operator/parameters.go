@@ -5,6 +5,8 @@
 package operator
 
 import (
+	"time"
+
 	"go.elastic.co/apm"
 	corev1 "k8s.io/api/core/v1"
 
@@ -26,18 +28,57 @@ type Parameters struct {
 	Dialer net.Dialer
 	// IPFamily represents the IP family to use when creating configuration and services.
 	IPFamily corev1.IPFamily
+	// AllocationExplainDelay is the duration after which the allocation of unassigned shards of Elasticsearch clusters
+	// that are not green is explained, 0 to disable it.
+	AllocationExplainDelay time.Duration
 	// CACertRotation defines the rotation params for CA certificates.
 	CACertRotation certificates.RotationParams
 	// CertRotation defines the rotation params for non-CA certificates.
 	CertRotation certificates.RotationParams
 	// MaxConcurrentReconciles controls the number of goroutines per controller.
 	MaxConcurrentReconciles int
+	// ControllerConcurrency overrides MaxConcurrentReconciles for the controllers with the given names.
+	ControllerConcurrency map[string]int
+	// ObservationInterval is the default interval at which the health of Elasticsearch clusters is observed.
+	ObservationInterval time.Duration
+	// ResyncInterval is the default interval at which Elasticsearch clusters are reconciled even if nothing changed,
+	// 0 to only reconcile them on changes.
+	ResyncInterval time.Duration
+	// SkipUnchanged skips the reconciliations of the Elasticsearch clusters whose inputs did not change since the last
+	// reconciliation which left nothing to do.
+	SkipUnchanged bool
+	// SlowPollAfter is the duration after which Elasticsearch clusters green and unchanged are observed and resynced
+	// at most every SlowPollInterval, 0 to disable it.
+	SlowPollAfter time.Duration
+	// SlowPollInterval is the minimum observation and resync interval of the Elasticsearch clusters in slow-poll mode.
+	SlowPollInterval time.Duration
+	// StatusFlushInterval is the minimum duration between two status updates of a resource, unless its health changes.
+	StatusFlushInterval time.Duration
+	// RestrictedSecurityContext enables restricted security contexts on all the Pods created by the operator.
+	RestrictedSecurityContext bool
 	// SetDefaultSecurityContext enables setting the default security context
 	// with fsGroup=1000 for Elasticsearch 8.0+ Pods. Ignored pre-8.0
 	SetDefaultSecurityContext bool
+	// DefaultTopologySpread enables setting default topology spread constraints on Elasticsearch Pods whose template
+	// does not specify any, spreading the Pods of each NodeSet across zones and hosts.
+	DefaultTopologySpread bool
+	// PluginsMirror is the base URL of the mirror the Elasticsearch plugins installed by name are downloaded from,
+	// instead of the Elastic download service, if not empty.
+	PluginsMirror string
 	// ValidateStorageClass specifies whether the operator should retrieve storage classes to verify volume expansion support.
 	// Can be disabled if cluster-wide storage class RBAC access is not available.
 	ValidateStorageClass bool
 	// Tracer is a shared APM tracer instance or nil
 	Tracer *apm.Tracer
+	// Shard is the subset of resources reconciled by this operator replica.
+	Shard Shard
+	// ResourceSelector restricts the resources managed by this operator instance to the ones matching a label selector.
+	ResourceSelector ResourceSelector
+	// ServerSideApply creates and updates the Secrets, Services, StatefulSets and ConfigMaps of the operator through
+	// server-side apply instead of create and update requests.
+	ServerSideApply bool
+	// DryRun is true if the changes to Kubernetes resources and Elasticsearch clusters are logged instead of applied.
+	DryRun bool
+	// Liveness tracks the reconciliations of the controllers for the liveness probe of the operator, or is nil.
+	Liveness *Liveness
 }
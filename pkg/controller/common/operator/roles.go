@@ -0,0 +1,53 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AllRoles runs all the components of the operator.
+	AllRoles = "all"
+	// ControllersRole runs the controllers reconciling the resources.
+	ControllersRole = "controllers"
+	// WebhookRole runs the validating webhook server and the management of its certificate.
+	WebhookRole = "webhook"
+)
+
+// Roles are the components run by an operator process, so that the webhook server can be scaled independently of the
+// controllers.
+type Roles map[string]bool
+
+// ParseRoles parses the given roles. No roles means all roles.
+func ParseRoles(roles []string) (Roles, error) {
+	parsed := Roles{}
+	for _, role := range roles {
+		switch role = strings.TrimSpace(role); role {
+		case AllRoles:
+			parsed[ControllersRole] = true
+			parsed[WebhookRole] = true
+		case ControllersRole, WebhookRole:
+			parsed[role] = true
+		default:
+			return nil, fmt.Errorf("unknown operator role %s, expected one of %s, %s, %s", role, AllRoles, ControllersRole, WebhookRole)
+		}
+	}
+	if len(parsed) == 0 {
+		return ParseRoles([]string{AllRoles})
+	}
+	return parsed, nil
+}
+
+// Has returns true if the given role is run by the operator process.
+func (r Roles) Has(role string) bool {
+	return r[role]
+}
+
+// WebhookOnly returns true if the operator process only runs the webhook server.
+func (r Roles) WebhookOnly() bool {
+	return r.Has(WebhookRole) && !r.Has(ControllersRole)
+}
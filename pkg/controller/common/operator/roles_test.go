@@ -0,0 +1,60 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoles(t *testing.T) {
+	tests := []struct {
+		name            string
+		roles           []string
+		wantControllers bool
+		wantWebhookOnly bool
+		wantErr         bool
+	}{
+		{
+			name:            "no roles",
+			roles:           nil,
+			wantControllers: true,
+		},
+		{
+			name:            "all roles",
+			roles:           []string{AllRoles},
+			wantControllers: true,
+		},
+		{
+			name:            "controllers and webhook",
+			roles:           []string{ControllersRole, " webhook"},
+			wantControllers: true,
+		},
+		{
+			name:            "webhook only",
+			roles:           []string{WebhookRole},
+			wantWebhookOnly: true,
+		},
+		{
+			name:    "unknown role",
+			roles:   []string{"global"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRoles(tt.roles)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, got.Has(WebhookRole))
+			require.Equal(t, tt.wantControllers, got.Has(ControllersRole))
+			require.Equal(t, tt.wantWebhookOnly, got.WebhookOnly())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ResourceSelector restricts the resources managed by an operator instance to the ones whose labels match a label
+// selector, so that several operator instances can manage disjoint subsets of the resources of a Kubernetes cluster.
+// The zero value selects all resources.
+type ResourceSelector struct {
+	selector labels.Selector
+}
+
+// NewResourceSelector parses the given label selector, such as eck.k8s.elastic.co/operator=team-a. An empty selector
+// selects all resources.
+func NewResourceSelector(selector string) (ResourceSelector, error) {
+	if selector == "" {
+		return ResourceSelector{}, nil
+	}
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return ResourceSelector{}, fmt.Errorf("invalid resource label selector %s: %w", selector, err)
+	}
+	if parsed.Empty() {
+		return ResourceSelector{}, nil
+	}
+	return ResourceSelector{selector: parsed}, nil
+}
+
+// Enabled returns true if the resources are filtered by a label selector.
+func (s ResourceSelector) Enabled() bool {
+	return s.selector != nil
+}
+
+// Matches returns true if the given resource is managed by this operator instance.
+func (s ResourceSelector) Matches(object metav1.Object) bool {
+	if !s.Enabled() {
+		return true
+	}
+	return s.selector.Matches(labels.Set(object.GetLabels()))
+}
+
+// String returns the label selector, empty if all resources are selected.
+func (s ResourceSelector) String() string {
+	if !s.Enabled() {
+		return ""
+	}
+	return s.selector.String()
+}
@@ -0,0 +1,43 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewResourceSelector(t *testing.T) {
+	_, err := NewResourceSelector("eck.k8s.elastic.co/operator in (a")
+	require.Error(t, err)
+
+	for _, selector := range []string{"", " "} {
+		s, err := NewResourceSelector(selector)
+		require.NoError(t, err)
+		require.False(t, s.Enabled())
+	}
+
+	s, err := NewResourceSelector("eck.k8s.elastic.co/operator in (a,b)")
+	require.NoError(t, err)
+	require.True(t, s.Enabled())
+	require.Equal(t, "eck.k8s.elastic.co/operator in (a,b)", s.String())
+}
+
+func TestResourceSelector_Matches(t *testing.T) {
+	withLabels := func(labels map[string]string) metav1.Object {
+		return &metav1.ObjectMeta{Namespace: "ns", Name: "es", Labels: labels}
+	}
+
+	// every resource is selected if no selector is set
+	require.True(t, ResourceSelector{}.Matches(withLabels(nil)))
+
+	s, err := NewResourceSelector("eck.k8s.elastic.co/operator=a")
+	require.NoError(t, err)
+	require.True(t, s.Matches(withLabels(map[string]string{"eck.k8s.elastic.co/operator": "a", "other": "label"})))
+	require.False(t, s.Matches(withLabels(map[string]string{"eck.k8s.elastic.co/operator": "b"})))
+	require.False(t, s.Matches(withLabels(nil)))
+}
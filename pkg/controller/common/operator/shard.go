@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Shard identifies the subset of resources reconciled by an operator replica when reconciliation is sharded across
+// several replicas. Each resource is owned by exactly one shard, based on a hash of its namespace and name.
+// The zero value disables sharding: all resources are owned by the replica.
+type Shard struct {
+	// Index is the index of the shard, between 0 and Count-1.
+	Index int
+	// Count is the total number of shards. Sharding is disabled if lower than 2.
+	Count int
+}
+
+// NewShard returns the Shard with the given index and count. A negative index is derived from the ordinal suffix of
+// hostname, as set for Pods of the operator StatefulSet (e.g. elastic-operator-2).
+func NewShard(index, count int, hostname string) (Shard, error) {
+	if count < 2 {
+		return Shard{}, nil
+	}
+	if index < 0 {
+		ordinal, err := strconv.Atoi(hostname[strings.LastIndex(hostname, "-")+1:])
+		if err != nil {
+			return Shard{}, fmt.Errorf("cannot derive shard index from hostname %s: %w", hostname, err)
+		}
+		index = ordinal
+	}
+	if index >= count {
+		return Shard{}, fmt.Errorf("shard index %d must be lower than the shard count %d", index, count)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+// Enabled returns true if reconciliation is sharded.
+func (s Shard) Enabled() bool {
+	return s.Count > 1
+}
+
+// IsFirst returns true if this is the first shard, responsible for tasks that are not specific to a resource.
+// It is always true if sharding is disabled.
+func (s Shard) IsFirst() bool {
+	return s.Index == 0
+}
+
+// Owns returns true if the resource with the given namespace and name is reconciled by this shard.
+func (s Shard) Owns(resource types.NamespacedName) bool {
+	if !s.Enabled() {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(resource.String()))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// String returns a human-readable representation of the shard, such as 1/3.
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
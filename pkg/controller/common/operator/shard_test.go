@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package operator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNewShard(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    int
+		count    int
+		hostname string
+		want     Shard
+		wantErr  bool
+	}{
+		{
+			name:  "sharding disabled",
+			index: 2,
+			count: 1,
+			want:  Shard{},
+		},
+		{
+			name:  "explicit index",
+			index: 1,
+			count: 3,
+			want:  Shard{Index: 1, Count: 3},
+		},
+		{
+			name:     "index derived from the hostname",
+			index:    -1,
+			count:    3,
+			hostname: "elastic-operator-2",
+			want:     Shard{Index: 2, Count: 3},
+		},
+		{
+			name:     "hostname without ordinal",
+			index:    -1,
+			count:    3,
+			hostname: "laptop",
+			wantErr:  true,
+		},
+		{
+			name:    "index out of range",
+			index:   3,
+			count:   3,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewShard(tt.index, tt.count, tt.hostname)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShard_Owns(t *testing.T) {
+	// every resource is owned by all replicas if sharding is disabled
+	require.True(t, Shard{}.Owns(types.NamespacedName{Namespace: "ns", Name: "es"}))
+
+	// every resource is owned by exactly one shard, and resources are spread across shards
+	count := 3
+	owned := make([]int, count)
+	for i := 0; i < 300; i++ {
+		resource := types.NamespacedName{Namespace: "ns", Name: fmt.Sprintf("es-%d", i)}
+		owners := 0
+		for index := 0; index < count; index++ {
+			if (Shard{Index: index, Count: count}).Owns(resource) {
+				owners++
+				owned[index]++
+			}
+		}
+		require.Equal(t, 1, owners)
+	}
+	for index := range owned {
+		require.Greater(t, owned[index], 50)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package common
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// LowPriorityDelay is the delay after which the requests of low priority resources are added to a backed up queue.
+const LowPriorityDelay = 10 * time.Second
+
+// PriorityFunc returns true if the reconciliation of the given resource should happen ahead of the others.
+type PriorityFunc func(resource types.NamespacedName) bool
+
+// WithPriority returns a controller whose watches delay the requests of low priority resources by LowPriorityDelay while
+// more than backlog requests are queued. High priority resources therefore get reconciled first when the queue backs up,
+// for example when all the resources are enqueued after an operator restart.
+func WithPriority(c controller.Controller, isHighPriority PriorityFunc, backlog int) controller.Controller {
+	return &priorityController{Controller: c, isHighPriority: isHighPriority, backlog: backlog}
+}
+
+type priorityController struct {
+	controller.Controller
+	isHighPriority PriorityFunc
+	backlog        int
+}
+
+func (c *priorityController) Watch(src source.Source, eventHandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	return c.Controller.Watch(src, &priorityHandler{EventHandler: eventHandler, controller: c}, predicates...)
+}
+
+func (c *priorityController) queue(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	return &priorityQueue{RateLimitingInterface: q, isHighPriority: c.isHighPriority, backlog: c.backlog}
+}
+
+// priorityHandler hands a priorityQueue over to the wrapped handler.
+type priorityHandler struct {
+	handler.EventHandler
+	controller *priorityController
+}
+
+func (h *priorityHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Create(evt, h.controller.queue(q))
+}
+
+func (h *priorityHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Update(evt, h.controller.queue(q))
+}
+
+func (h *priorityHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(evt, h.controller.queue(q))
+}
+
+func (h *priorityHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Generic(evt, h.controller.queue(q))
+}
+
+// priorityQueue delays the requests of low priority resources added while the queue is backed up.
+type priorityQueue struct {
+	workqueue.RateLimitingInterface
+	isHighPriority PriorityFunc
+	backlog        int
+}
+
+func (q *priorityQueue) Add(item interface{}) {
+	if request, ok := item.(reconcile.Request); ok && q.Len() > q.backlog && !q.isHighPriority(request.NamespacedName) {
+		q.RateLimitingInterface.AddAfter(item, LowPriorityDelay)
+		return
+	}
+	q.RateLimitingInterface.Add(item)
+}
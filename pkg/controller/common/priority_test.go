@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_priorityQueue_Add(t *testing.T) {
+	high := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "high"}}
+	low := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "low"}}
+	first := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "first"}}
+	second := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "second"}}
+	isHighPriority := func(resource types.NamespacedName) bool { return resource == high.NamespacedName }
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	pq := &priorityQueue{RateLimitingInterface: q, isHighPriority: isHighPriority, backlog: 1}
+
+	// low priority requests are added immediately as long as the queue is not backed up
+	pq.Add(first)
+	pq.Add(second)
+	require.Equal(t, 2, q.Len())
+
+	// but delayed once it is
+	pq.Add(low)
+	require.Equal(t, 2, q.Len())
+
+	// high priority requests are always added immediately
+	pq.Add(high)
+	require.Equal(t, 3, q.Len())
+	for _, expected := range []reconcile.Request{first, second, high} {
+		item, _ := q.Get()
+		require.Equal(t, expected, item)
+		q.Done(item)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,9 +24,10 @@ import (
 	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
 )
 
-var (
-	log = ulog.Log.WithName("generic-reconciler")
-)
+var log = ulog.Log.WithName("generic-reconciler")
+
+// FieldManager is the field manager owning the fields set by the operator through server-side apply.
+const FieldManager = "elastic-operator"
 
 // Params is a parameter object for the ReconcileResources function
 type Params struct {
@@ -48,6 +51,10 @@ type Params struct {
 	PreUpdate func() error
 	// PostUpdate is called immediately after the resource is successfully updated.
 	PostUpdate func()
+	// ServerSideApply creates and updates the resource through server-side apply instead of create and update requests.
+	// Fields set by other managers are then preserved by the API server, and UpdateReconciled is not used.
+	// NeedsUpdate is still used to avoid sending an apply request when the resource is up-to-date.
+	ServerSideApply bool
 }
 
 func (p Params) CheckNilValues() error {
@@ -101,6 +108,9 @@ func ReconcileResource(params Params) error {
 		// This will panic if params.Expected and params.Reconciled don't have the same underlying type.
 		expectedCopyValue := reflect.ValueOf(params.Expected.DeepCopyObject()).Elem()
 		reflect.ValueOf(params.Reconciled).Elem().Set(expectedCopyValue)
+		if params.ServerSideApply {
+			return apply(params.Client, params.Reconciled, gvk)
+		}
 		// Create the object, which modifies params.Reconciled in-place
 		err = params.Client.Create(context.Background(), params.Reconciled)
 		if err != nil {
@@ -150,6 +160,21 @@ func ReconcileResource(params Params) error {
 				return err
 			}
 		}
+		if params.ServerSideApply {
+			if err := takeOverUpdatedFields(params.Client, params.Reconciled, gvk); err != nil {
+				return err
+			}
+			expectedCopyValue := reflect.ValueOf(params.Expected.DeepCopyObject()).Elem()
+			reflect.ValueOf(params.Reconciled).Elem().Set(expectedCopyValue)
+			if err := apply(params.Client, params.Reconciled, gvk); err != nil {
+				return err
+			}
+			if params.PostUpdate != nil {
+				params.PostUpdate()
+			}
+			return nil
+		}
+
 		reconciledMeta, err := meta.Accessor(params.Reconciled)
 		if err != nil {
 			return err
@@ -182,3 +207,53 @@ func ReconcileResource(params Params) error {
 	}
 	return nil
 }
+
+// apply creates or updates obj through server-side apply, forcing the ownership of the fields it sets.
+// obj is modified in-place to reflect the state of the resource returned by the API server.
+func apply(c k8s.Client, obj client.Object, gvk schema.GroupVersionKind) error {
+	// apply requests must specify the type of the resource and must not be based on a given version
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	return c.Patch(context.Background(), obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+}
+
+// takeOverUpdatedFields transfers the ownership of the fields set by the operator through update requests, before it
+// started to use server-side apply, to its apply field manager. Otherwise, the fields the operator does not set
+// anymore would still be owned by its update field manager, and would not be removed by the next apply requests.
+func takeOverUpdatedFields(c k8s.Client, obj client.Object, gvk schema.GroupVersionKind) error {
+	managedFields, takenOver := upgradeManagedFields(obj.GetManagedFields(), gvk.GroupVersion().String())
+	if !takenOver {
+		return nil
+	}
+	original := obj.DeepCopyObject().(client.Object) //nolint:forcetypeassert
+	obj.SetManagedFields(managedFields)
+	log.V(1).Info("Taking over fields set by update requests",
+		"kind", gvk.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return c.Patch(context.Background(), obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// upgradeManagedFields turns the entry of the fields set by the operator through update requests into an entry of
+// its apply field manager. It returns false if there is no such entry, or if the operator already uses server-side
+// apply for this resource.
+func upgradeManagedFields(entries []metav1.ManagedFieldsEntry, apiVersion string) ([]metav1.ManagedFieldsEntry, bool) {
+	updateEntry := -1
+	for i, entry := range entries {
+		if entry.Manager != FieldManager || entry.Subresource != "" {
+			continue
+		}
+		if entry.Operation == metav1.ManagedFieldsOperationApply {
+			return nil, false
+		}
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate && entry.APIVersion == apiVersion {
+			updateEntry = i
+		}
+	}
+	if updateEntry < 0 {
+		return nil, false
+	}
+	upgraded := make([]metav1.ManagedFieldsEntry, len(entries))
+	copy(upgraded, entries)
+	upgraded[updateEntry].Operation = metav1.ManagedFieldsOperationApply
+	return upgraded, true
+}
@@ -6,6 +6,7 @@ package reconciler
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -291,3 +293,146 @@ func TestReconcileResource(t *testing.T) {
 		})
 	}
 }
+
+// applyClient emulates server-side apply on top of the fake client, which does not support apply patches.
+type applyClient struct {
+	k8s.Client
+	applied                  []client.Object
+	managedFieldsBeforeApply []metav1.ManagedFieldsEntry
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	if patchOpts.FieldManager != FieldManager || patchOpts.Force == nil || !*patchOpts.Force {
+		return errors.New("apply patches must force the ownership of the operator field manager")
+	}
+	if obj.GetObjectKind().GroupVersionKind().Kind == "" {
+		return errors.New("apply patches must specify the kind of the resource")
+	}
+	c.applied = append(c.applied, obj.DeepCopyObject().(client.Object))
+
+	var existing corev1.Secret
+	err := c.Client.Get(ctx, k8s.ExtractNamespacedName(obj), &existing)
+	if apierrors.IsNotFound(err) {
+		return c.Client.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	c.managedFieldsBeforeApply = existing.ManagedFields
+	obj.SetResourceVersion(existing.ResourceVersion)
+	return c.Client.Update(ctx, obj)
+}
+
+func TestReconcileResource_ServerSideApply(t *testing.T) {
+	c := &applyClient{Client: k8s.NewFakeClient()}
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+	reconcile := func() {
+		var reconciled corev1.Secret
+		require.NoError(t, ReconcileResource(Params{
+			Client:     c,
+			Expected:   expected.DeepCopy(),
+			Reconciled: &reconciled,
+			NeedsUpdate: func() bool {
+				return !reflect.DeepEqual(expected.Data, reconciled.Data)
+			},
+			UpdateReconciled: func() {
+				t.Errorf("UpdateReconciled must not be called with server-side apply")
+			},
+			ServerSideApply: true,
+		}))
+		require.Equal(t, expected.Data, reconciled.Data)
+	}
+
+	// the resource is created through an apply patch
+	reconcile()
+	require.Len(t, c.applied, 1)
+
+	// nothing is applied if the resource is up-to-date
+	reconcile()
+	require.Len(t, c.applied, 1)
+
+	// the resource is updated through an apply patch
+	expected.Data = map[string][]byte{"key": []byte("updated")}
+	reconcile()
+	require.Len(t, c.applied, 2)
+	var actual corev1.Secret
+	require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&expected), &actual))
+	require.Equal(t, expected.Data, actual.Data)
+}
+
+func TestReconcileResource_ServerSideApplyTakesOverUpdatedFields(t *testing.T) {
+	existing := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "secret",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1"},
+				{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1"},
+			},
+		},
+		Data: map[string][]byte{"key": []byte("value")},
+	}
+	c := &applyClient{Client: k8s.NewFakeClient(&existing)}
+	var reconciled corev1.Secret
+	require.NoError(t, ReconcileResource(Params{
+		Client:           c,
+		Expected:         &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "secret"}, Data: map[string][]byte{"key": []byte("updated")}},
+		Reconciled:       &reconciled,
+		NeedsUpdate:      func() bool { return true },
+		UpdateReconciled: func() {},
+		ServerSideApply:  true,
+	}))
+	require.Len(t, c.applied, 1)
+	// the ownership of the fields set by the operator was transferred to its apply field manager before applying
+	require.Equal(t, []metav1.ManagedFieldsEntry{
+		{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply, APIVersion: "v1"},
+		{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1"},
+	}, c.managedFieldsBeforeApply)
+}
+
+func Test_upgradeManagedFields(t *testing.T) {
+	update := metav1.ManagedFieldsEntry{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1"}
+	apply := metav1.ManagedFieldsEntry{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply, APIVersion: "v1"}
+	other := metav1.ManagedFieldsEntry{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1"}
+	status := metav1.ManagedFieldsEntry{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1", Subresource: "status"}
+	tests := []struct {
+		name          string
+		entries       []metav1.ManagedFieldsEntry
+		want          []metav1.ManagedFieldsEntry
+		wantTakenOver bool
+	}{
+		{
+			name:    "no field set by the operator",
+			entries: []metav1.ManagedFieldsEntry{other},
+		},
+		{
+			name:          "fields set by operator update requests",
+			entries:       []metav1.ManagedFieldsEntry{other, update, status},
+			want:          []metav1.ManagedFieldsEntry{other, apply, status},
+			wantTakenOver: true,
+		},
+		{
+			name:    "fields already taken over",
+			entries: []metav1.ManagedFieldsEntry{apply, update},
+		},
+		{
+			name:    "fields set through another API version",
+			entries: []metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1beta1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, takenOver := upgradeManagedFields(tt.entries, "v1")
+			require.Equal(t, tt.wantTakenOver, takenOver)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -27,9 +27,9 @@ const (
 	SoftOwnerKindLabel      = "eck.k8s.elastic.co/owner-kind"
 )
 
-// ReconcileSecret creates or updates the actual secret to match the expected one.
-// Existing annotations or labels that are not expected are preserved.
-func ReconcileSecret(c k8s.Client, expected corev1.Secret, owner client.Object) (corev1.Secret, error) {
+// ReconcileSecret creates or updates the actual secret to match the expected one, through server-side apply if
+// serverSideApply is true. Existing annotations or labels that are not expected are preserved.
+func ReconcileSecret(c k8s.Client, expected corev1.Secret, owner client.Object, serverSideApply bool) (corev1.Secret, error) {
 	var reconciled corev1.Secret
 	if err := ReconcileResource(Params{
 		Client:     c,
@@ -50,6 +50,7 @@ func ReconcileSecret(c k8s.Client, expected corev1.Secret, owner client.Object)
 			reconciled.Annotations = maps.Merge(reconciled.Annotations, expected.Annotations)
 			reconciled.Data = expected.Data
 		},
+		ServerSideApply: serverSideApply,
 	}); err != nil {
 		return corev1.Secret{}, err
 	}
@@ -105,6 +106,7 @@ func ReconcileSecretNoOwnerRef(c k8s.Client, expected corev1.Secret, softOwner r
 	expected.Labels[SoftOwnerNameLabel] = ownerMeta.GetName()
 	expected.Labels[SoftOwnerKindLabel] = softOwner.GetObjectKind().GroupVersionKind().Kind
 
+	// server-side apply is not used here: it would not remove the owner references set by previous operator versions
 	var reconciled corev1.Secret
 	if err := ReconcileResource(Params{
 		Client:     c,
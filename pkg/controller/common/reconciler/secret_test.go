@@ -107,7 +107,7 @@ func TestReconcileSecret(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ReconcileSecret(tt.c, *tt.expected, owner)
+			got, err := ReconcileSecret(tt.c, *tt.expected, owner, false)
 			require.NoError(t, err)
 
 			var retrieved corev1.Secret
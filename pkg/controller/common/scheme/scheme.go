@@ -22,7 +22,11 @@ import (
 	entv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1beta1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	kbv1beta1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1beta1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
 	emsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/maps/v1alpha1"
+	securityv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/security/v1alpha1"
+	snapshotv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/snapshot/v1alpha1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
 )
 
 var addToScheme sync.Once
@@ -51,6 +55,10 @@ func SetupScheme() {
 		beatv1beta1.AddToScheme,
 		agentv1alpha1.AddToScheme,
 		emsv1alpha1.AddToScheme,
+		snapshotv1alpha1.AddToScheme,
+		securityv1alpha1.AddToScheme,
+		policyv1alpha1.AddToScheme,
+		lsv1alpha1.AddToScheme,
 	}
 	mustAddSchemeOnce(&addToScheme, schemes)
 }
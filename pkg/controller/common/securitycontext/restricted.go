@@ -0,0 +1,164 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package securitycontext
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultUID is the UID of the user running the processes in the Elastic container images.
+	DefaultUID int64 = 1000
+
+	// TmpVolumeName is the name of the volume providing a writable /tmp directory to the containers running with a
+	// read-only root filesystem.
+	TmpVolumeName = "elastic-internal-tmp"
+	TmpMountPath  = "/tmp"
+
+	weakenedMsg = "weakens the restricted security context enforced by the operator"
+)
+
+// Harden returns the given Pod template with restricted security contexts on the Pod and on all its containers:
+// non-root user, RuntimeDefault seccomp profile, read-only root filesystem, no privilege escalation and all capabilities
+// dropped. An empty /tmp volume is mounted in the containers, which cannot write in their root filesystem.
+// The template is returned unchanged if restricted is false, which is the case when the operator does not run in
+// restricted mode, and an error is returned if the template sets a security context weaker than the restricted one.
+func Harden(podTemplate corev1.PodTemplateSpec, restricted bool) (corev1.PodTemplateSpec, error) {
+	if !restricted {
+		return podTemplate, nil
+	}
+	if errs := Validate(podTemplate.Spec, field.NewPath("spec")); len(errs) > 0 {
+		return podTemplate, fmt.Errorf("invalid pod template in restricted mode: %w", errs.ToAggregate())
+	}
+
+	hardened := podTemplate.DeepCopy()
+	spec := &hardened.Spec
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if spec.SecurityContext.RunAsNonRoot == nil {
+		spec.SecurityContext.RunAsNonRoot = pointer.BoolPtr(true)
+	}
+	if spec.SecurityContext.RunAsUser == nil {
+		spec.SecurityContext.RunAsUser = pointer.Int64(DefaultUID)
+	}
+	if spec.SecurityContext.FSGroup == nil {
+		spec.SecurityContext.FSGroup = pointer.Int64(DefaultUID)
+	}
+	if spec.SecurityContext.SeccompProfile == nil {
+		spec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	hasTmpVolume := false
+	for _, v := range spec.Volumes {
+		if v.Name == TmpVolumeName {
+			hasTmpVolume = true
+		}
+	}
+	if !hasTmpVolume {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name:         TmpVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	for i := range spec.InitContainers {
+		hardenContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		hardenContainer(&spec.Containers[i])
+	}
+	return *hardened, nil
+}
+
+func hardenContainer(c *corev1.Container) {
+	if c.SecurityContext == nil {
+		c.SecurityContext = &corev1.SecurityContext{}
+	}
+	sc := c.SecurityContext
+	if sc.Privileged == nil {
+		sc.Privileged = pointer.BoolPtr(false)
+	}
+	if sc.AllowPrivilegeEscalation == nil {
+		sc.AllowPrivilegeEscalation = pointer.BoolPtr(false)
+	}
+	if sc.ReadOnlyRootFilesystem == nil {
+		sc.ReadOnlyRootFilesystem = pointer.BoolPtr(true)
+	}
+	if sc.RunAsNonRoot == nil {
+		sc.RunAsNonRoot = pointer.BoolPtr(true)
+	}
+	if sc.Capabilities == nil {
+		sc.Capabilities = &corev1.Capabilities{}
+	}
+	if len(sc.Capabilities.Drop) == 0 {
+		sc.Capabilities.Drop = []corev1.Capability{"ALL"}
+	}
+	for _, m := range c.VolumeMounts {
+		if m.MountPath == TmpMountPath {
+			return
+		}
+	}
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: TmpVolumeName, MountPath: TmpMountPath})
+}
+
+// Validate returns an error for each field of the given Pod spec which weakens the restricted security contexts.
+func Validate(spec corev1.PodSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if psc := spec.SecurityContext; psc != nil {
+		scPath := path.Child("securityContext")
+		if psc.RunAsNonRoot != nil && !*psc.RunAsNonRoot {
+			errs = append(errs, field.Invalid(scPath.Child("runAsNonRoot"), *psc.RunAsNonRoot, weakenedMsg))
+		}
+		if psc.RunAsUser != nil && *psc.RunAsUser == 0 {
+			errs = append(errs, field.Invalid(scPath.Child("runAsUser"), *psc.RunAsUser, weakenedMsg))
+		}
+		if psc.SeccompProfile != nil && psc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			errs = append(errs, field.Invalid(scPath.Child("seccompProfile", "type"), psc.SeccompProfile.Type, weakenedMsg))
+		}
+	}
+	for i, c := range spec.InitContainers {
+		errs = append(errs, validateContainer(c, path.Child("initContainers").Index(i))...)
+	}
+	for i, c := range spec.Containers {
+		errs = append(errs, validateContainer(c, path.Child("containers").Index(i))...)
+	}
+	return errs
+}
+
+func validateContainer(c corev1.Container, path *field.Path) field.ErrorList {
+	sc := c.SecurityContext
+	if sc == nil {
+		return nil
+	}
+	path = path.Child("securityContext")
+	var errs field.ErrorList
+	if sc.Privileged != nil && *sc.Privileged {
+		errs = append(errs, field.Invalid(path.Child("privileged"), *sc.Privileged, weakenedMsg))
+	}
+	if sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation {
+		errs = append(errs, field.Invalid(path.Child("allowPrivilegeEscalation"), *sc.AllowPrivilegeEscalation, weakenedMsg))
+	}
+	if sc.ReadOnlyRootFilesystem != nil && !*sc.ReadOnlyRootFilesystem {
+		errs = append(errs, field.Invalid(path.Child("readOnlyRootFilesystem"), *sc.ReadOnlyRootFilesystem, weakenedMsg))
+	}
+	if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+		errs = append(errs, field.Invalid(path.Child("runAsNonRoot"), *sc.RunAsNonRoot, weakenedMsg))
+	}
+	if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		errs = append(errs, field.Invalid(path.Child("runAsUser"), *sc.RunAsUser, weakenedMsg))
+	}
+	if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+		errs = append(errs, field.Invalid(path.Child("capabilities", "add"), sc.Capabilities.Add, weakenedMsg))
+	}
+	if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		errs = append(errs, field.Invalid(path.Child("seccompProfile", "type"), sc.SeccompProfile.Type, weakenedMsg))
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package securitycontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func podTemplate(podSC *corev1.PodSecurityContext, containerSC *corev1.SecurityContext) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			SecurityContext: podSC,
+			InitContainers:  []corev1.Container{{Name: "init"}},
+			Containers:      []corev1.Container{{Name: "main", SecurityContext: containerSC}},
+		},
+	}
+}
+
+func TestHarden(t *testing.T) {
+	tests := []struct {
+		name       string
+		restricted bool
+		template   corev1.PodTemplateSpec
+		wantErr    bool
+	}{
+		{
+			name:     "restricted mode disabled: no change",
+			template: podTemplate(nil, &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)}),
+		},
+		{
+			name:       "restricted mode: harden the pod and all the containers",
+			restricted: true,
+			template:   podTemplate(nil, nil),
+		},
+		{
+			name:       "restricted mode: stricter user settings are preserved",
+			restricted: true,
+			template: podTemplate(
+				&corev1.PodSecurityContext{RunAsUser: pointer.Int64(2000)},
+				&corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}}},
+			),
+		},
+		{
+			name:       "restricted mode: privileged container",
+			restricted: true,
+			template:   podTemplate(nil, &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)}),
+			wantErr:    true,
+		},
+		{
+			name:       "restricted mode: root user",
+			restricted: true,
+			template:   podTemplate(&corev1.PodSecurityContext{RunAsUser: pointer.Int64(0)}, nil),
+			wantErr:    true,
+		},
+		{
+			name:       "restricted mode: writable root filesystem",
+			restricted: true,
+			template:   podTemplate(nil, &corev1.SecurityContext{ReadOnlyRootFilesystem: pointer.BoolPtr(false)}),
+			wantErr:    true,
+		},
+		{
+			name:       "restricted mode: added capabilities",
+			restricted: true,
+			template:   podTemplate(nil, &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}}}),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Harden(tt.template, tt.restricted)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.restricted {
+				require.Equal(t, tt.template, got)
+				return
+			}
+
+			psc := got.Spec.SecurityContext
+			require.True(t, *psc.RunAsNonRoot)
+			require.NotZero(t, *psc.RunAsUser)
+			require.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, psc.SeccompProfile.Type)
+			if tt.template.Spec.SecurityContext != nil {
+				require.Equal(t, *tt.template.Spec.SecurityContext.RunAsUser, *psc.RunAsUser)
+			}
+			require.Contains(t, got.Spec.Volumes, corev1.Volume{
+				Name:         TmpVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+			for _, c := range append(got.Spec.InitContainers, got.Spec.Containers...) {
+				require.False(t, *c.SecurityContext.Privileged)
+				require.False(t, *c.SecurityContext.AllowPrivilegeEscalation)
+				require.True(t, *c.SecurityContext.ReadOnlyRootFilesystem)
+				require.True(t, *c.SecurityContext.RunAsNonRoot)
+				require.NotEmpty(t, c.SecurityContext.Capabilities.Drop)
+				require.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: TmpVolumeName, MountPath: TmpMountPath})
+			}
+			// the hardened template is still valid
+			require.Empty(t, Validate(got.Spec, nil))
+			// the given template is not modified
+			require.Nil(t, tt.template.Spec.Containers[0].VolumeMounts)
+		})
+	}
+}
@@ -23,11 +23,13 @@ import (
 
 var log = ulog.Log.WithName("common")
 
+// ReconcileService creates or updates the expected Service, through server-side apply if serverSideApply is true.
 func ReconcileService(
 	ctx context.Context,
 	c k8s.Client,
 	expected *corev1.Service,
 	owner client.Object,
+	serverSideApply bool,
 ) (*corev1.Service, error) {
 	span, _ := apm.StartSpan(ctx, "reconcile_service", tracing.SpanTypeApp)
 	defer span.End()
@@ -49,6 +51,7 @@ func ReconcileService(
 			reconciled.Labels = expected.Labels
 			reconciled.Spec = expected.Spec
 		},
+		ServerSideApply: serverSideApply,
 	})
 	return reconciled, err
 }
@@ -40,7 +40,7 @@ func TestReconcileService(t *testing.T) {
 	wantSvc.Labels["lbl3"] = "lblval3"
 	wantSvc.Annotations["ann3"] = "annval3"
 
-	haveSvc, err := ReconcileService(context.Background(), client, expectedSvc, owner)
+	haveSvc, err := ReconcileService(context.Background(), client, expectedSvc, owner, false)
 	require.NoError(t, err)
 	comparison.AssertEqual(t, wantSvc, haveSvc)
 }
@@ -13,6 +13,7 @@ import (
 	"text/template"
 
 	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -29,6 +30,20 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
+const (
+	// metricbeatModulesFile is the name of the file holding the configuration of the Metricbeat modules, which includes
+	// the credentials used to collect the metrics. Metricbeat reloads it periodically: it is not part of the configuration
+	// hash, so that rotating the credentials does not restart the Pods.
+	metricbeatModulesFile = "modules.yml"
+	// metricbeatModulesReloadPeriod is the period at which Metricbeat reloads the configuration of its modules.
+	metricbeatModulesReloadPeriod = "10s"
+)
+
+// configDirPath returns the path of the directory holding the configuration files of the given beat.
+func configDirPath(beatName string) string {
+	return fmt.Sprintf("/etc/%s-config", beatName)
+}
+
 // beatConfig helps to create a beat configuration
 type beatConfig struct {
 	filepath string
@@ -37,7 +52,16 @@ type beatConfig struct {
 	volumes  []volume.VolumeLike
 }
 
-func newBeatConfig(client k8s.Client, beatName string, resource monitoring.HasMonitoring, associations []commonv1.Association, baseConfig string) (beatConfig, error) {
+// newBeatConfig builds the configuration of the given beat. The reloaded files are added to the configuration secret
+// without being part of the configuration hash, since the beat reloads them without restarting.
+func newBeatConfig(
+	client k8s.Client,
+	beatName string,
+	resource monitoring.HasMonitoring,
+	associations []commonv1.Association,
+	baseConfig string,
+	reloadedFiles map[string][]byte,
+) (beatConfig, error) {
 	if len(associations) != 1 {
 		// should never happen because of the pre-creation validation
 		return beatConfig{}, errors.New("only one Elasticsearch reference is supported for Stack Monitoring")
@@ -59,11 +83,11 @@ func newBeatConfig(client k8s.Client, beatName string, resource monitoring.HasMo
 	configSecretName := fmt.Sprintf("%s-%s-%s-config", resource.GetName(), string(assoc.AssociationType()), beatName)
 	configName := configVolumeName(resource.GetName(), beatName)
 	configFilename := fmt.Sprintf("%s.yml", beatName)
-	configDirPath := fmt.Sprintf("/etc/%s-config", beatName)
+	configDir := configDirPath(beatName)
 
 	// add the config volume
-	configVolume := volume.NewSecretVolumeWithMountPath(configSecretName, configName, configDirPath)
-	configFilepath := filepath.Join(configDirPath, configFilename)
+	configVolume := volume.NewSecretVolumeWithMountPath(configSecretName, configName, configDir)
+	configFilepath := filepath.Join(configDir, configFilename)
 	volumes := []volume.VolumeLike{configVolume}
 
 	// add the CA volume
@@ -90,6 +114,9 @@ func newBeatConfig(client k8s.Client, beatName string, resource monitoring.HasMo
 			configFilename: configBytes,
 		},
 	}
+	for filename, content := range reloadedFiles {
+		configSecret.Data[filename] = content
+	}
 
 	return beatConfig{
 		filepath: configFilepath,
@@ -209,3 +236,46 @@ func buildMetricbeatBaseConfig(
 
 	return metricbeatConfig.String(), caVolume, nil
 }
+
+// withReloadedModules moves the modules of the given Metricbeat configuration to a separate file, that Metricbeat
+// reloads periodically. It returns the updated configuration, and the content of the modules file or nil if the
+// configuration does not define any module.
+func withReloadedModules(baseConfig string) (string, []byte, error) {
+	cfg, err := settings.ParseConfig([]byte(baseConfig))
+	if err != nil {
+		return "", nil, err
+	}
+	var untyped map[string]interface{}
+	if err := cfg.Unpack(&untyped); err != nil {
+		return "", nil, err
+	}
+	metricbeatCfg, ok := untyped["metricbeat"].(map[string]interface{})
+	if !ok || metricbeatCfg["modules"] == nil {
+		return baseConfig, nil, nil
+	}
+
+	modulesBytes, err := yaml.Marshal(metricbeatCfg["modules"])
+	if err != nil {
+		return "", nil, err
+	}
+	delete(metricbeatCfg, "modules")
+	metricbeatCfg["config"] = map[string]interface{}{
+		"modules": map[string]interface{}{
+			"path": filepath.Join(configDirPath("metricbeat"), metricbeatModulesFile),
+			"reload": map[string]interface{}{
+				"enabled": true,
+				"period":  metricbeatModulesReloadPeriod,
+			},
+		},
+	}
+
+	reloadingCfg, err := settings.NewCanonicalConfigFrom(untyped)
+	if err != nil {
+		return "", nil, err
+	}
+	reloadingCfgBytes, err := reloadingCfg.Render()
+	if err != nil {
+		return "", nil, err
+	}
+	return string(reloadingCfgBytes), modulesBytes, nil
+}
@@ -5,6 +5,7 @@
 package stackmon
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -74,3 +75,44 @@ func TestBuildMetricbeatBaseConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestWithReloadedModules(t *testing.T) {
+	baseConfig := `metricbeat.modules:
+  - module: elasticsearch
+    hosts: ["https://localhost:9200"]
+    username: elastic-internal-monitoring
+    password: secret
+processors:
+  - add_cloud_metadata: {}
+`
+	config, modules, err := withReloadedModules(baseConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, `metricbeat:
+  config:
+    modules:
+      path: /etc/metricbeat-config/modules.yml
+      reload:
+        enabled: true
+        period: 10s
+processors:
+- add_cloud_metadata: null
+`, config)
+	assert.Equal(t, `- hosts:
+  - https://localhost:9200
+  module: elasticsearch
+  password: secret
+  username: elastic-internal-monitoring
+`, string(modules))
+
+	// rotating the credentials does not change the configuration
+	rotatedConfig, rotatedModules, err := withReloadedModules(strings.Replace(baseConfig, "secret", "rotated", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, config, rotatedConfig)
+	assert.NotEqual(t, modules, rotatedModules)
+
+	// no modules
+	config, modules, err = withReloadedModules("processors: []\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "processors: []\n", config)
+	assert.Nil(t, modules)
+}
@@ -43,8 +43,17 @@ func NewMetricBeatSidecar(
 	if err != nil {
 		return BeatSidecar{}, err
 	}
+	// the modules configuration includes the credentials of the monitoring user, Metricbeat reloads it when they are rotated
+	baseConfig, modulesConfig, err := withReloadedModules(baseConfig)
+	if err != nil {
+		return BeatSidecar{}, err
+	}
+	var reloadedFiles map[string][]byte
+	if modulesConfig != nil {
+		reloadedFiles = map[string][]byte{metricbeatModulesFile: modulesConfig}
+	}
 	image := container.ImageRepository(container.MetricbeatImage, version)
-	return NewBeatSidecar(client, "metricbeat", image, resource, monitoring.GetMetricsAssociation(resource), baseConfig, sourceCaVolume)
+	return newBeatSidecar(client, "metricbeat", image, resource, monitoring.GetMetricsAssociation(resource), baseConfig, sourceCaVolume, reloadedFiles)
 }
 
 func NewFileBeatSidecar(client k8s.Client, resource monitoring.HasMonitoring, version string, baseConfig string, additionalVolume volume.VolumeLike) (BeatSidecar, error) {
@@ -63,9 +72,15 @@ type BeatSidecar struct {
 
 func NewBeatSidecar(client k8s.Client, beatName string, image string, resource monitoring.HasMonitoring,
 	associations []commonv1.Association, baseConfig string, additionalVolume volume.VolumeLike,
+) (BeatSidecar, error) {
+	return newBeatSidecar(client, beatName, image, resource, associations, baseConfig, additionalVolume, nil)
+}
+
+func newBeatSidecar(client k8s.Client, beatName string, image string, resource monitoring.HasMonitoring,
+	associations []commonv1.Association, baseConfig string, additionalVolume volume.VolumeLike, reloadedFiles map[string][]byte,
 ) (BeatSidecar, error) {
 	// build the beat config
-	config, err := newBeatConfig(client, beatName, resource, associations, baseConfig)
+	config, err := newBeatConfig(client, beatName, resource, associations, baseConfig, reloadedFiles)
 	if err != nil {
 		return BeatSidecar{}, err
 	}
@@ -0,0 +1,104 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package statefulset
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/securitycontext"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
+)
+
+var (
+	defaultRevisionHistoryLimit int32
+)
+
+// Params to specify a StatefulSet specification.
+type Params struct {
+	Name            string
+	Namespace       string
+	ServiceName     string
+	Selector        map[string]string
+	Labels          map[string]string
+	PodTemplateSpec corev1.PodTemplateSpec
+	Replicas        int32
+	// PodManagementPolicy defaults to Parallel: the Pods of the StatefulSet do not depend on each other.
+	PodManagementPolicy appsv1.PodManagementPolicyType
+}
+
+// New creates a StatefulSet from the given params.
+func New(params Params) appsv1.StatefulSet {
+	podManagementPolicy := params.PodManagementPolicy
+	if podManagementPolicy == "" {
+		podManagementPolicy = appsv1.ParallelPodManagement
+	}
+	return appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      params.Name,
+			Namespace: params.Namespace,
+			Labels:    params.Labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			RevisionHistoryLimit: pointer.Int32(defaultRevisionHistoryLimit),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: params.Selector,
+			},
+			ServiceName:         params.ServiceName,
+			Template:            params.PodTemplateSpec,
+			Replicas:            &params.Replicas,
+			PodManagementPolicy: podManagementPolicy,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+	}
+}
+
+// Reconcile creates or updates the given StatefulSet for the specified owner.
+func Reconcile(
+	k8sClient k8s.Client,
+	expected appsv1.StatefulSet,
+	owner client.Object,
+	restricted bool,
+) (appsv1.StatefulSet, error) {
+	// render the Pods with restricted security contexts if the operator runs in restricted mode
+	template, err := securitycontext.Harden(expected.Spec.Template, restricted)
+	if err != nil {
+		return appsv1.StatefulSet{}, err
+	}
+	expected.Spec.Template = template
+
+	// label the StatefulSet with a hash of itself
+	expected = WithTemplateHash(expected)
+
+	reconciled := &appsv1.StatefulSet{}
+	err = reconciler.ReconcileResource(reconciler.Params{
+		Client:     k8sClient,
+		Owner:      owner,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			// compare hash of the StatefulSet at the time it was built
+			return hash.GetTemplateHashLabel(reconciled.Labels) != hash.GetTemplateHashLabel(expected.Labels)
+		},
+		UpdateReconciled: func() {
+			expected.DeepCopyInto(reconciled)
+		},
+	})
+	return *reconciled, err
+}
+
+// WithTemplateHash returns a new StatefulSet with a hash of its template to ease comparisons.
+func WithTemplateHash(s appsv1.StatefulSet) appsv1.StatefulSet {
+	sCopy := *s.DeepCopy()
+	sCopy.Labels = hash.SetTemplateHashLabel(sCopy.Labels, sCopy)
+	return sCopy
+}
@@ -42,6 +42,27 @@ func DeploymentStatus(current commonv1.DeploymentStatus, dep appsv1.Deployment,
 	return status, nil
 }
 
+// StatefulSetStatus returns a DeploymentStatus computed from the given StatefulSet and args.
+// The health is green once all the expected replicas are ready. Unknown fields are inherited from current.
+func StatefulSetStatus(current commonv1.DeploymentStatus, sset appsv1.StatefulSet, pods []corev1.Pod, versionLabel string) (commonv1.DeploymentStatus, error) {
+	status := *current.DeepCopy()
+	if sset.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(sset.Spec.Selector)
+		if err != nil {
+			return commonv1.DeploymentStatus{}, err
+		}
+		status.Selector = selector.String()
+	}
+	status.Count = sset.Status.Replicas
+	status.AvailableNodes = sset.Status.ReadyReplicas
+	status.Version = LowestVersionFromPods(status.Version, pods, versionLabel)
+	status.Health = commonv1.RedHealth
+	if sset.Spec.Replicas != nil && sset.Status.ReadyReplicas >= *sset.Spec.Replicas {
+		status.Health = commonv1.GreenHealth
+	}
+	return status, nil
+}
+
 // LowestVersionFromPods parses versions from the given pods based on the given label,
 // and returns the lowest one.
 func LowestVersionFromPods(currentVersion string, pods []corev1.Pod, versionLabel string) string {
@@ -19,6 +19,7 @@ import (
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 )
 
 func Test_workaroundStatusUpdateError(t *testing.T) {
@@ -215,3 +216,62 @@ func TestDeploymentStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestStatefulSetStatus(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"version-label": "7.7.0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"version-label": "7.7.0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"version-label": "7.7.0"}}},
+	}
+	tests := []struct {
+		name string
+		sset appsv1.StatefulSet
+		want commonv1.DeploymentStatus
+	}{
+		{
+			name: "all replicas ready: green health",
+			sset: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: pointer.Int32(3),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}},
+				},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:      3,
+					ReadyReplicas: 3,
+				},
+			},
+			want: commonv1.DeploymentStatus{
+				Selector:       "a=b",
+				Count:          3,
+				AvailableNodes: 3,
+				Version:        "7.7.0",
+				Health:         commonv1.GreenHealth,
+			},
+		},
+		{
+			name: "some replicas not ready: red health",
+			sset: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: pointer.Int32(3),
+				},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:      3,
+					ReadyReplicas: 2,
+				},
+			},
+			want: commonv1.DeploymentStatus{
+				Count:          3,
+				AvailableNodes: 2,
+				Version:        "7.7.0",
+				Health:         commonv1.RedHealth,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StatefulSetStatus(commonv1.DeploymentStatus{}, tt.sset, pods, "version-label")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package common
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// DefaultStatusFlushInterval is the default minimum duration between two coalesced status updates of a resource.
+const DefaultStatusFlushInterval = 5 * time.Second
+
+// StatusWriter coalesces the status updates of the resources reconciled by a controller: an update happening less than
+// the flush interval after the previous update of the same resource is deferred, unless it is flagged as immediate.
+// Deferred updates are not retained: the caller is expected to requeue the resource after the returned delay, and to
+// write the status computed by the next reconciliation.
+// A nil StatusWriter or a zero flush interval writes all updates immediately.
+type StatusWriter struct {
+	mutex      sync.Mutex
+	interval   time.Duration
+	lastWrites map[types.NamespacedName]time.Time
+	now        func() time.Time
+}
+
+// NewStatusWriter returns a StatusWriter coalescing the status updates happening within the given flush interval.
+func NewStatusWriter(interval time.Duration) *StatusWriter {
+	return &StatusWriter{
+		interval:   interval,
+		lastWrites: make(map[types.NamespacedName]time.Time),
+		now:        time.Now,
+	}
+}
+
+// Write updates the status sub-resource of obj, either immediately if requested or if the flush interval elapsed since
+// the previous update, or not at all in which case it returns the delay after which the update should be retried.
+func (w *StatusWriter) Write(c k8s.Client, obj client.Object, immediate bool) (time.Duration, error) {
+	if w == nil || w.interval <= 0 {
+		return 0, UpdateStatus(c, obj)
+	}
+
+	key := k8s.ExtractNamespacedName(obj)
+	w.mutex.Lock()
+	lastWrite, exists := w.lastWrites[key]
+	w.mutex.Unlock()
+	if !immediate && exists {
+		if remaining := w.interval - w.now().Sub(lastWrite); remaining > 0 {
+			return remaining, nil
+		}
+	}
+
+	if err := UpdateStatus(c, obj); err != nil {
+		return 0, err
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.lastWrites[key] = w.now()
+	return 0, nil
+}
+
+// Forget removes the state kept for the given resource. It should be called once the resource is deleted.
+func (w *StatusWriter) Forget(resource types.NamespacedName) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.lastWrites, resource)
+}
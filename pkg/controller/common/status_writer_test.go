@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestStatusWriter_Write(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}
+	c := k8s.NewFakeClient(&pod)
+	now := time.Now()
+	w := NewStatusWriter(5 * time.Second)
+	w.now = func() time.Time { return now }
+
+	write := func(message string, immediate bool) time.Duration {
+		var current corev1.Pod
+		require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&pod), &current))
+		current.Status.Message = message
+		requeueAfter, err := w.Write(c, &current, immediate)
+		require.NoError(t, err)
+		return requeueAfter
+	}
+	requireMessage := func(message string) {
+		var current corev1.Pod
+		require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&pod), &current))
+		require.Equal(t, message, current.Status.Message)
+	}
+
+	// the first update is written
+	require.Equal(t, time.Duration(0), write("first", false))
+	requireMessage("first")
+
+	// the next one is deferred until the end of the flush interval
+	now = now.Add(2 * time.Second)
+	require.Equal(t, 3*time.Second, write("second", false))
+	requireMessage("first")
+
+	// unless it is immediate
+	require.Equal(t, time.Duration(0), write("immediate", true))
+	requireMessage("immediate")
+
+	// updates are written once the flush interval elapsed
+	now = now.Add(5 * time.Second)
+	require.Equal(t, time.Duration(0), write("third", false))
+	requireMessage("third")
+
+	// forgotten resources are written immediately
+	w.Forget(k8s.ExtractNamespacedName(&pod))
+	require.Equal(t, time.Duration(0), write("fourth", false))
+	requireMessage("fourth")
+}
+
+func TestStatusWriter_Disabled(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}
+	c := k8s.NewFakeClient(&pod)
+	for _, w := range []*StatusWriter{nil, NewStatusWriter(0)} {
+		for _, message := range []string{"first", "second"} {
+			pod.Status.Message = message
+			requeueAfter, err := w.Write(c, &pod, false)
+			require.NoError(t, err)
+			require.Equal(t, time.Duration(0), requeueAfter)
+			var current corev1.Pod
+			require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&pod), &current))
+			require.Equal(t, message, current.Status.Message)
+		}
+	}
+}
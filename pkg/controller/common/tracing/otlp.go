@@ -0,0 +1,267 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tracing
+
+import (
+	"bufio"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.elastic.co/apm"
+	"go.elastic.co/apm/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/elastic/cloud-on-k8s/pkg/about"
+)
+
+const (
+	// otlpTracesPath is the path of the OTLP/HTTP traces endpoint of OpenTelemetry collectors.
+	otlpTracesPath = "/v1/traces"
+	// otlpScopeName identifies the instrumentation producing the exported spans.
+	otlpScopeName = "go.elastic.co/apm"
+	// otlpRequestTimeout is the maximum duration of a request to the OTLP endpoint.
+	otlpRequestTimeout = 30 * time.Second
+	// maxEventSize is the maximum size of a single event of the APM intake stream.
+	maxEventSize = 10 * 1024 * 1024
+)
+
+// NewOTLPTracer returns a new APM tracer exporting transactions and spans as OpenTelemetry spans to the OTLP/HTTP
+// endpoint at the given URL, for example http://otel-collector:4318, instead of sending them to an APM server.
+func NewOTLPTracer(serviceName string, endpoint string) *apm.Tracer {
+	otlpTransport, err := NewOTLPTransport(endpoint)
+	if err != nil {
+		// don't fail the application because tracing fails
+		log.Error(err, "failed to create OTLP transport for "+serviceName)
+		return nil
+	}
+	build := about.GetBuildInfo()
+	tracer, err := apm.NewTracerOptions(apm.TracerOptions{
+		ServiceName:    serviceName,
+		ServiceVersion: build.Version + "-" + build.Hash,
+		Transport:      otlpTransport,
+	})
+	if err != nil {
+		log.Error(err, "failed to create tracer for "+serviceName)
+		return nil
+	}
+	// metrics cannot be converted to spans, don't collect them
+	tracer.SetMetricsInterval(0)
+	tracer.SetLogger(NewLogAdapter(log))
+	return tracer
+}
+
+// otlpTransport is an APM transport converting the transactions and spans of the APM intake stream into
+// OpenTelemetry spans, which are sent by the OTLP/HTTP exporter of the OpenTelemetry SDK.
+type otlpTransport struct {
+	exporter *otlptrace.Exporter
+}
+
+var _ transport.Transport = &otlpTransport{}
+
+// NewOTLPTransport returns an APM transport sending traces to the OTLP/HTTP endpoint at the given URL.
+func NewOTLPTransport(endpoint string) (transport.Transport, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s, expected an URL such as http://otel-collector:4318", endpoint)
+	}
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + otlpTracesPath),
+		otlptracehttp.WithTimeout(otlpRequestTimeout),
+	}
+	if u.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	// the HTTP client of the exporter does not connect before the first export
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &otlpTransport{exporter: exporter}, nil
+}
+
+// SendStream implements transport.Transport.
+func (t *otlpTransport) SendStream(ctx context.Context, stream io.Reader) error {
+	spans, err := readSpans(stream)
+	if err != nil {
+		return err
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.exporter.ExportSpans(ctx, spans)
+}
+
+// intakeEvent partially models the transactions and spans of the APM intake stream.
+type intakeEvent struct {
+	ID       string `json:"id"`
+	TraceID  string `json:"trace_id"`
+	ParentID string `json:"parent_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+	Action   string `json:"action"`
+	Result   string `json:"result"`
+	Outcome  string `json:"outcome"`
+
+	// Timestamp is the start of the event in microseconds since the epoch.
+	Timestamp int64 `json:"timestamp"`
+	// Duration is the duration of the event in milliseconds.
+	Duration float64 `json:"duration"`
+
+	Context struct {
+		Tags map[string]interface{} `json:"tags"`
+	} `json:"context"`
+}
+
+// intakeMetadata partially models the metadata of the APM intake stream.
+type intakeMetadata struct {
+	Service struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"service"`
+}
+
+// readSpans reads the given zlib-compressed APM intake stream and returns the corresponding OpenTelemetry spans.
+// Events other than transactions and spans are ignored.
+func readSpans(stream io.Reader) ([]sdktrace.ReadOnlySpan, error) {
+	var metadata intakeMetadata
+	var events []intakeEvent
+
+	reader, err := zlib.NewReader(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxEventSize)
+	for scanner.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, err
+		}
+		for kind, raw := range line {
+			switch kind {
+			case "metadata":
+				if err := json.Unmarshal(raw, &metadata); err != nil {
+					return nil, err
+				}
+			case "transaction", "span":
+				var event intakeEvent
+				if err := json.Unmarshal(raw, &event); err != nil {
+					return nil, err
+				}
+				events = append(events, event)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	resourceAttributes := []attribute.KeyValue{semconv.ServiceNameKey.String(metadata.Service.Name)}
+	if metadata.Service.Version != "" {
+		resourceAttributes = append(resourceAttributes, semconv.ServiceVersionKey.String(metadata.Service.Version))
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL, resourceAttributes...)
+
+	spans := make([]sdktrace.ReadOnlySpan, 0, len(events))
+	for _, event := range events {
+		span, err := newSpan(event, res)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+// newSpan converts the given APM transaction or span into an OpenTelemetry span. The spans are built from stubs, as
+// the SDK only creates spans with its own identifiers and timestamps.
+func newSpan(event intakeEvent, res *resource.Resource) (sdktrace.ReadOnlySpan, error) {
+	traceID, err := trace.TraceIDFromHex(event.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace id %s: %w", event.TraceID, err)
+	}
+	spanID, err := trace.SpanIDFromHex(event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span id %s: %w", event.ID, err)
+	}
+	var parent trace.SpanContext
+	if event.ParentID != "" {
+		parentID, err := trace.SpanIDFromHex(event.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent id %s: %w", event.ParentID, err)
+		}
+		parent = trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: parentID, TraceFlags: trace.FlagsSampled})
+	}
+
+	start := time.Unix(0, event.Timestamp*int64(time.Microsecond))
+	end := start.Add(time.Duration(event.Duration * float64(time.Millisecond)))
+
+	kind := trace.SpanKindInternal
+	if event.Type == "db" || event.Type == "external" {
+		// requests to Elasticsearch or to other HTTP services
+		kind = trace.SpanKindClient
+	}
+
+	var attributes []attribute.KeyValue
+	for _, attr := range []struct{ key, value string }{
+		{"type", event.Type},
+		{"subtype", event.Subtype},
+		{"action", event.Action},
+		{"result", event.Result},
+		{"outcome", event.Outcome},
+	} {
+		if attr.value != "" {
+			attributes = append(attributes, attribute.String(attr.key, attr.value))
+		}
+	}
+	labels := make([]string, 0, len(event.Context.Tags))
+	for label := range event.Context.Tags {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		attributes = append(attributes, attribute.String("labels."+label, fmt.Sprint(event.Context.Tags[label])))
+	}
+
+	var status sdktrace.Status
+	if event.Outcome == "failure" {
+		status.Code = codes.Error
+	}
+
+	return tracetest.SpanStub{
+		Name:                   event.Name,
+		SpanContext:            trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}),
+		Parent:                 parent,
+		SpanKind:               kind,
+		StartTime:              start,
+		EndTime:                end,
+		Attributes:             attributes,
+		Status:                 status,
+		Resource:               res,
+		InstrumentationLibrary: instrumentation.Library{Name: otlpScopeName},
+	}.Snapshot(), nil
+}
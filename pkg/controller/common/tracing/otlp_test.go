@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tracing
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.elastic.co/apm"
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPTransport(t *testing.T) {
+	requests := make(chan *collectortracev1.ExportTraceServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var request collectortracev1.ExportTraceServiceRequest
+		assert.NoError(t, proto.Unmarshal(body, &request))
+		requests <- &request
+	}))
+	defer server.Close()
+
+	otlpTransport, err := NewOTLPTransport(server.URL + "/")
+	require.NoError(t, err)
+	tracer, err := apm.NewTracerOptions(apm.TracerOptions{
+		ServiceName:    "elastic-operator",
+		ServiceVersion: "1.0.0",
+		Transport:      otlpTransport,
+	})
+	require.NoError(t, err)
+	defer tracer.Close()
+	tracer.SetMetricsInterval(0)
+
+	tx := tracer.StartTransaction("ns/es", "elasticsearch")
+	tx.Context.SetLabel("reconcile", "1")
+	span, _ := apm.StartSpan(apm.ContextWithTransaction(context.Background(), tx), "reconcile_es", "app")
+	span.End()
+	tx.End()
+	tracer.Flush(nil)
+
+	request := <-requests
+	require.Len(t, request.ResourceSpans, 1)
+	resourceAttributes := stringAttributes(request.ResourceSpans[0].Resource.Attributes)
+	assert.Equal(t, "elastic-operator", resourceAttributes["service.name"])
+	assert.Equal(t, "1.0.0", resourceAttributes["service.version"])
+	require.Len(t, request.ResourceSpans[0].InstrumentationLibrarySpans, 1)
+	spans := request.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans
+	require.Len(t, spans, 2)
+	// the span is sent first, as it ends first
+	assert.Equal(t, "reconcile_es", spans[0].Name)
+	assert.Equal(t, "ns/es", spans[1].Name)
+	assert.Equal(t, spans[1].TraceId, spans[0].TraceId)
+	assert.Equal(t, spans[1].SpanId, spans[0].ParentSpanId)
+	assert.Empty(t, spans[1].ParentSpanId)
+	assert.Equal(t, "1", stringAttributes(spans[1].Attributes)["labels.reconcile"])
+	assert.Len(t, spans[1].TraceId, 16)
+	assert.Len(t, spans[1].SpanId, 8)
+	assert.LessOrEqual(t, spans[1].StartTimeUnixNano, spans[0].StartTimeUnixNano)
+}
+
+func stringAttributes(attributes []*commonv1.KeyValue) map[string]string {
+	values := make(map[string]string, len(attributes))
+	for _, attr := range attributes {
+		values[attr.Key] = attr.Value.GetStringValue()
+	}
+	return values
+}
+
+func TestNewOTLPTransport_InvalidEndpoint(t *testing.T) {
+	_, err := NewOTLPTransport("otel-collector")
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -99,6 +100,26 @@ func (d *DynamicEnqueueRequest) Registrations() []string {
 	return keys
 }
 
+// WatchedBy returns the resources watched on behalf of the given watcher by the registered named watches.
+func (d *DynamicEnqueueRequest) WatchedBy(watcher types.NamespacedName) []types.NamespacedName {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	var watched []types.NamespacedName
+	for _, registration := range d.registrations {
+		switch w := registration.(type) {
+		case NamedWatch:
+			if w.Watcher == watcher {
+				watched = append(watched, w.Watched...)
+			}
+		case *NamedWatch:
+			if w.Watcher == watcher {
+				watched = append(watched, w.Watched...)
+			}
+		}
+	}
+	return watched
+}
+
 // DynamicEnqueueRequest implements EventHandler
 var _ handler.EventHandler = &DynamicEnqueueRequest{}
 
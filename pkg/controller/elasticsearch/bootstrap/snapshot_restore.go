@@ -0,0 +1,120 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package bootstrap
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+	"go.elastic.co/apm"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// initialSnapshotRestoreAnnotationName is used to track the progress of the restore of the initial snapshot.
+const initialSnapshotRestoreAnnotationName = "elasticsearch.k8s.elastic.co/initial-snapshot-restore"
+
+// SnapshotRestoreStatus is the progress of the restore of the initial snapshot of a cluster.
+type SnapshotRestoreStatus string
+
+const (
+	// SnapshotRestoreNotRequired is returned if there is no snapshot to restore, or if the cluster had already been
+	// bootstrapped when the restore was requested.
+	SnapshotRestoreNotRequired SnapshotRestoreStatus = ""
+	// SnapshotRestorePending is returned until the cluster is bootstrapped and the restore can be started.
+	SnapshotRestorePending SnapshotRestoreStatus = "pending"
+	// SnapshotRestoreStarted is returned while the restored shards are recovered.
+	SnapshotRestoreStarted SnapshotRestoreStatus = "started"
+	// SnapshotRestoreCompleted is returned once all the restored shards have been recovered.
+	SnapshotRestoreCompleted SnapshotRestoreStatus = "completed"
+)
+
+// ReconcileInitialSnapshotRestore restores the initial snapshot specified in the Elasticsearch resource, if any.
+// The restore is only performed for clusters requesting it before being bootstrapped: they are marked as pending
+// until the cluster UUID is known, then the snapshot repository is registered and the restore started. The restore
+// is complete once the cluster health is not red anymore and no shard is initializing. The progress is stored in an
+// annotation of the Elasticsearch resource, updated immediately.
+func ReconcileInitialSnapshotRestore(
+	ctx context.Context,
+	k8sClient k8s.Client,
+	cluster *esv1.Elasticsearch,
+	esClient client.Client,
+	esReachable bool,
+) (SnapshotRestoreStatus, error) {
+	restore := cluster.Spec.InitialSnapshotRestore
+	if restore == nil {
+		return SnapshotRestoreNotRequired, nil
+	}
+
+	switch SnapshotRestoreStatus(cluster.Annotations[initialSnapshotRestoreAnnotationName]) {
+	case SnapshotRestoreCompleted:
+		return SnapshotRestoreCompleted, nil
+	case SnapshotRestoreStarted:
+		if !esReachable {
+			return SnapshotRestoreStarted, nil
+		}
+		health, err := esClient.GetClusterHealth(ctx)
+		if err != nil {
+			return SnapshotRestoreStarted, err
+		}
+		if health.Status == esv1.ElasticsearchRedHealth || health.InitializingShards > 0 {
+			return SnapshotRestoreStarted, nil
+		}
+		log.Info("Initial snapshot restored", "namespace", cluster.Namespace, "es_name", cluster.Name, "snapshot", restore.Snapshot)
+		return SnapshotRestoreCompleted, annotateWithSnapshotRestoreStatus(ctx, k8sClient, cluster, SnapshotRestoreCompleted)
+	case SnapshotRestorePending:
+		if !AnnotatedForBootstrap(*cluster) || !esReachable {
+			// retry later
+			return SnapshotRestorePending, nil
+		}
+		if err := startSnapshotRestore(ctx, esClient, *restore); err != nil {
+			return SnapshotRestorePending, err
+		}
+		log.Info("Initial snapshot restore started", "namespace", cluster.Namespace, "es_name", cluster.Name, "snapshot", restore.Snapshot)
+		return SnapshotRestoreStarted, annotateWithSnapshotRestoreStatus(ctx, k8sClient, cluster, SnapshotRestoreStarted)
+	default:
+		if AnnotatedForBootstrap(*cluster) {
+			// the restore was requested after the cluster had been bootstrapped: there may be data already
+			return SnapshotRestoreNotRequired, nil
+		}
+		return SnapshotRestorePending, annotateWithSnapshotRestoreStatus(ctx, k8sClient, cluster, SnapshotRestorePending)
+	}
+}
+
+// startSnapshotRestore registers the snapshot repository and starts the restore of the snapshot.
+func startSnapshotRestore(ctx context.Context, esClient client.Client, restore esv1.InitialSnapshotRestore) error {
+	span, ctx := apm.StartSpan(ctx, "restore_initial_snapshot", tracing.SpanTypeApp)
+	defer span.End()
+
+	var settings map[string]interface{}
+	if restore.Repository.Settings != nil {
+		settings = restore.Repository.Settings.Data
+	}
+	if err := esClient.PutSnapshotRepository(ctx, restore.Repository.Name, client.SnapshotRepository{
+		Type:     restore.Repository.Type,
+		Settings: settings,
+	}); err != nil {
+		return pkgerrors.Wrapf(err, "while registering snapshot repository %s", restore.Repository.Name)
+	}
+	if err := esClient.RestoreSnapshot(ctx, restore.Repository.Name, restore.Snapshot, client.SnapshotRestoreRequest{
+		Indices:            restore.Indices,
+		IncludeGlobalState: restore.IncludeGlobalState,
+	}); err != nil {
+		return pkgerrors.Wrapf(err, "while restoring snapshot %s", restore.Snapshot)
+	}
+	return nil
+}
+
+// annotateWithSnapshotRestoreStatus stores the progress of the restore of the initial snapshot in the cluster annotations.
+func annotateWithSnapshotRestoreStatus(ctx context.Context, k8sClient k8s.Client, cluster *esv1.Elasticsearch, status SnapshotRestoreStatus) error {
+	if cluster.Annotations == nil {
+		cluster.Annotations = make(map[string]string)
+	}
+	cluster.Annotations[initialSnapshotRestoreAnnotationName] = string(status)
+	return k8sClient.Update(ctx, cluster)
+}
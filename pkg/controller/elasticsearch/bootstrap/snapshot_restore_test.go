@@ -0,0 +1,174 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+type fakeRestoreESClient struct {
+	esclient.Client
+	health       esclient.Health
+	restoreErr   error
+	repositories map[string]esclient.SnapshotRepository
+	restored     []string
+}
+
+func (f *fakeRestoreESClient) PutSnapshotRepository(_ context.Context, name string, repository esclient.SnapshotRepository) error {
+	if f.repositories == nil {
+		f.repositories = map[string]esclient.SnapshotRepository{}
+	}
+	f.repositories[name] = repository
+	return nil
+}
+
+func (f *fakeRestoreESClient) RestoreSnapshot(_ context.Context, repository string, snapshot string, _ esclient.SnapshotRestoreRequest) error {
+	if f.restoreErr != nil {
+		return f.restoreErr
+	}
+	f.restored = append(f.restored, repository+"/"+snapshot)
+	return nil
+}
+
+func (f *fakeRestoreESClient) GetClusterHealth(_ context.Context) (esclient.Health, error) {
+	return f.health, nil
+}
+
+func withSnapshotRestore(es *esv1.Elasticsearch, status SnapshotRestoreStatus) *esv1.Elasticsearch {
+	es.Spec.InitialSnapshotRestore = &esv1.InitialSnapshotRestore{
+		Repository: esv1.SnapshotRestoreRepository{
+			Name:     "backups",
+			Type:     "s3",
+			Settings: &commonv1.Config{Data: map[string]interface{}{"bucket": "my-bucket"}},
+		},
+		Snapshot: "snap-1",
+	}
+	if status != SnapshotRestoreNotRequired {
+		if es.Annotations == nil {
+			es.Annotations = map[string]string{}
+		}
+		es.Annotations[initialSnapshotRestoreAnnotationName] = string(status)
+	}
+	return es
+}
+
+func TestReconcileInitialSnapshotRestore(t *testing.T) {
+	tests := []struct {
+		name           string
+		cluster        *esv1.Elasticsearch
+		esClient       *fakeRestoreESClient
+		esReachable    bool
+		wantStatus     SnapshotRestoreStatus
+		wantErr        bool
+		wantRestored   []string
+		wantAnnotation string
+	}{
+		{
+			name:        "no restore requested",
+			cluster:     notBootstrappedES(),
+			esClient:    &fakeRestoreESClient{},
+			esReachable: true,
+			wantStatus:  SnapshotRestoreNotRequired,
+		},
+		{
+			name:           "restore requested on a new cluster",
+			cluster:        withSnapshotRestore(notBootstrappedES(), SnapshotRestoreNotRequired),
+			esClient:       &fakeRestoreESClient{},
+			esReachable:    true,
+			wantStatus:     SnapshotRestorePending,
+			wantAnnotation: string(SnapshotRestorePending),
+		},
+		{
+			name:        "restore requested on an existing cluster",
+			cluster:     withSnapshotRestore(bootstrappedES(), SnapshotRestoreNotRequired),
+			esClient:    &fakeRestoreESClient{},
+			esReachable: true,
+			wantStatus:  SnapshotRestoreNotRequired,
+		},
+		{
+			name:           "pending restore, cluster not bootstrapped yet",
+			cluster:        withSnapshotRestore(notBootstrappedES(), SnapshotRestorePending),
+			esClient:       &fakeRestoreESClient{},
+			esReachable:    true,
+			wantStatus:     SnapshotRestorePending,
+			wantAnnotation: string(SnapshotRestorePending),
+		},
+		{
+			name:           "pending restore, cluster not reachable",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestorePending),
+			esClient:       &fakeRestoreESClient{},
+			esReachable:    false,
+			wantStatus:     SnapshotRestorePending,
+			wantAnnotation: string(SnapshotRestorePending),
+		},
+		{
+			name:           "pending restore, cluster bootstrapped",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestorePending),
+			esClient:       &fakeRestoreESClient{},
+			esReachable:    true,
+			wantStatus:     SnapshotRestoreStarted,
+			wantRestored:   []string{"backups/snap-1"},
+			wantAnnotation: string(SnapshotRestoreStarted),
+		},
+		{
+			name:           "restore cannot be started",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestorePending),
+			esClient:       &fakeRestoreESClient{restoreErr: errors.New("snapshot missing")},
+			esReachable:    true,
+			wantStatus:     SnapshotRestorePending,
+			wantErr:        true,
+			wantAnnotation: string(SnapshotRestorePending),
+		},
+		{
+			name:           "restore in progress",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestoreStarted),
+			esClient:       &fakeRestoreESClient{health: esclient.Health{Status: esv1.ElasticsearchYellowHealth, InitializingShards: 2}},
+			esReachable:    true,
+			wantStatus:     SnapshotRestoreStarted,
+			wantAnnotation: string(SnapshotRestoreStarted),
+		},
+		{
+			name:           "restore complete",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestoreStarted),
+			esClient:       &fakeRestoreESClient{health: esclient.Health{Status: esv1.ElasticsearchGreenHealth}},
+			esReachable:    true,
+			wantStatus:     SnapshotRestoreCompleted,
+			wantAnnotation: string(SnapshotRestoreCompleted),
+		},
+		{
+			name:           "restore already completed",
+			cluster:        withSnapshotRestore(bootstrappedES(), SnapshotRestoreCompleted),
+			esClient:       &fakeRestoreESClient{},
+			esReachable:    false,
+			wantStatus:     SnapshotRestoreCompleted,
+			wantAnnotation: string(SnapshotRestoreCompleted),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := k8s.NewFakeClient(tt.cluster)
+			status, err := ReconcileInitialSnapshotRestore(context.Background(), k8sClient, tt.cluster, tt.esClient, tt.esReachable)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.wantStatus, status)
+			require.Equal(t, tt.wantRestored, tt.esClient.restored)
+			if tt.wantRestored != nil {
+				require.Equal(t, "my-bucket", tt.esClient.repositories["backups"].Settings["bucket"])
+			}
+
+			var updated esv1.Elasticsearch
+			require.NoError(t, k8sClient.Get(context.Background(), k8s.ExtractNamespacedName(tt.cluster), &updated))
+			require.Equal(t, tt.wantAnnotation, updated.Annotations[initialSnapshotRestoreAnnotationName])
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package certificates
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
+)
+
+// CertManagerCertificateGVK is the GroupVersionKind of the cert-manager Certificate resources. They are handled as
+// unstructured objects so that cert-manager is only required for the clusters using it.
+var CertManagerCertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// CertManagerHTTPCertsSecretName returns the name of the Secret storing the HTTP certificate issued by cert-manager.
+func CertManagerHTTPCertsSecretName(esName string) string {
+	return esv1.ESNamer.Suffix(esName, "cm-http-certs")
+}
+
+// CertManagerTransportCertsSecretName returns the name of the Secret storing the transport certificate issued by
+// cert-manager.
+func CertManagerTransportCertsSecretName(esName string) string {
+	return esv1.ESNamer.Suffix(esName, "cm-transport-certs")
+}
+
+// withCertManagerCertificates returns the given cluster configured to use the certificates issued by cert-manager, as if
+// they were provided by the user.
+func withCertManagerCertificates(es esv1.Elasticsearch) esv1.Elasticsearch {
+	if es.Spec.CertManager == nil {
+		return es
+	}
+	es = *es.DeepCopy()
+	es.Spec.HTTP.TLS.Certificate = commonv1.SecretRef{SecretName: CertManagerHTTPCertsSecretName(es.Name)}
+	es.Spec.Transport.TLS.NodeCertificate = commonv1.SecretRef{SecretName: CertManagerTransportCertsSecretName(es.Name)}
+	return es
+}
+
+// reconcileCertManagerCertificates requests the HTTP and transport certificates of the given cluster to cert-manager.
+func reconcileCertManagerCertificates(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	services []corev1.Service,
+	extraHTTPSANs []commonv1.SubjectAlternativeName,
+	labels map[string]string,
+) error {
+	if es.Spec.CertManager == nil {
+		return nil
+	}
+
+	httpDNSNames := []string{esv1.HTTPService(es.Name)}
+	var httpIPAddresses []string
+	for _, svc := range services {
+		httpDNSNames = append(httpDNSNames, k8s.GetServiceDNSName(svc)...)
+		for _, ip := range k8s.GetServiceIPAddresses(svc) {
+			httpIPAddresses = append(httpIPAddresses, ip.String())
+		}
+	}
+	httpSANs := append([]commonv1.SubjectAlternativeName{}, extraHTTPSANs...)
+	if es.Spec.HTTP.TLS.SelfSignedCertificate != nil {
+		httpSANs = append(httpSANs, es.Spec.HTTP.TLS.SelfSignedCertificate.SubjectAlternativeNames...)
+	}
+	httpDNSNames, httpIPAddresses = appendSANs(httpDNSNames, httpIPAddresses, httpSANs)
+	if err := reconcileCertManagerCertificate(c, es, labels, CertManagerHTTPCertsSecretName(es.Name), map[string]interface{}{
+		"commonName":  esv1.HTTPService(es.Name),
+		"dnsNames":    httpDNSNames,
+		"ipAddresses": httpIPAddresses,
+		"usages":      []string{"server auth"},
+	}); err != nil {
+		return err
+	}
+
+	var transportDNSNames, transportIPAddresses []string
+	for _, nodeSet := range es.Spec.NodeSets {
+		transportDNSNames = append(transportDNSNames,
+			"*."+nodespec.HeadlessServiceName(esv1.StatefulSet(es.Name, nodeSet.Name))+"."+es.Namespace+".svc")
+	}
+	transportDNSNames, transportIPAddresses = appendSANs(transportDNSNames, transportIPAddresses, es.Spec.Transport.TLS.SubjectAlternativeNames)
+	return reconcileCertManagerCertificate(c, es, labels, CertManagerTransportCertsSecretName(es.Name), map[string]interface{}{
+		"commonName":  esv1.ESNamer.Suffix(es.Name, "transport"),
+		"dnsNames":    transportDNSNames,
+		"ipAddresses": transportIPAddresses,
+		// the nodes present the same certificate to each other as clients and servers
+		"usages": []string{"server auth", "client auth"},
+	})
+}
+
+func appendSANs(dnsNames, ipAddresses []string, sans []commonv1.SubjectAlternativeName) ([]string, []string) {
+	for _, san := range sans {
+		if san.DNS != "" {
+			dnsNames = append(dnsNames, san.DNS)
+		}
+		if san.IP != "" {
+			ipAddresses = append(ipAddresses, san.IP)
+		}
+	}
+	return sortedUnique(dnsNames), sortedUnique(ipAddresses)
+}
+
+func sortedUnique(values []string) []string {
+	unique := set.Make(values...).AsSlice()
+	unique.Sort()
+	return unique
+}
+
+// reconcileCertManagerCertificate reconciles a cert-manager Certificate issuing a certificate with the given spec
+// into the Secret with the given name, whose name is also used for the Certificate.
+func reconcileCertManagerCertificate(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	labels map[string]string,
+	secretName string,
+	spec map[string]interface{},
+) error {
+	issuerRef := map[string]interface{}{"name": es.Spec.CertManager.IssuerRef.Name}
+	if kind := es.Spec.CertManager.IssuerRef.Kind; kind != "" {
+		issuerRef["kind"] = kind
+	}
+	if group := es.Spec.CertManager.IssuerRef.Group; group != "" {
+		issuerRef["group"] = group
+	}
+	spec["issuerRef"] = issuerRef
+	spec["secretName"] = secretName
+	// store string slices as []interface{}, as in the objects read from the API server, so that they can be compared
+	expectedSpec, err := toUnstructuredSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	expected := &unstructured.Unstructured{}
+	expected.SetGroupVersionKind(CertManagerCertificateGVK)
+	expected.SetNamespace(es.Namespace)
+	expected.SetName(secretName)
+	expected.SetLabels(labels)
+	expected.Object["spec"] = expectedSpec
+
+	reconciled := &unstructured.Unstructured{}
+	reconciled.SetGroupVersionKind(CertManagerCertificateGVK)
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Owner:      &es,
+		Expected:   expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			return !maps.IsSubset(expected.GetLabels(), reconciled.GetLabels()) ||
+				!reflect.DeepEqual(expected.Object["spec"], reconciled.Object["spec"])
+		},
+		UpdateReconciled: func() {
+			reconciled.SetLabels(maps.Merge(reconciled.GetLabels(), expected.GetLabels()))
+			reconciled.Object["spec"] = expected.Object["spec"]
+		},
+	})
+}
+
+func toUnstructuredSpec(spec map[string]interface{}) (map[string]interface{}, error) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for k, v := range spec {
+		switch value := v.(type) {
+		case []string:
+			if len(value) == 0 {
+				continue
+			}
+			if err := unstructured.SetNestedStringSlice(u.Object, value, k); err != nil {
+				return nil, err
+			}
+		default:
+			u.Object[k] = v
+		}
+	}
+	return u.Object, nil
+}
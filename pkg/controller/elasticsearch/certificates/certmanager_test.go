@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package certificates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_reconcileCertManagerCertificates(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec: esv1.ElasticsearchSpec{
+			CertManager: &esv1.CertManagerConfig{IssuerRef: esv1.CertManagerIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"}},
+			Transport: esv1.TransportConfig{TLS: esv1.TransportTLSOptions{
+				SubjectAlternativeNames: []commonv1.SubjectAlternativeName{{IP: "10.0.0.1"}},
+			}},
+			NodeSets: []esv1.NodeSet{{Name: "default"}},
+		},
+	}
+	services := []corev1.Service{{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es-es-http"}}}
+	extraHTTPSANs := []commonv1.SubjectAlternativeName{{DNS: "*.es-es-default.ns.svc"}}
+	c := k8s.NewFakeClient(&es)
+
+	getSpec := func(name string) map[string]interface{} {
+		t.Helper()
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(CertManagerCertificateGVK)
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: name}, certificate))
+		require.Equal(t, "es", certificate.GetOwnerReferences()[0].Name)
+		return certificate.Object["spec"].(map[string]interface{})
+	}
+
+	require.NoError(t, reconcileCertManagerCertificates(c, es, services, extraHTTPSANs, map[string]string{"a": "b"}))
+	require.Equal(t, map[string]interface{}{
+		"commonName": "es-es-http",
+		"dnsNames":   []interface{}{"*.es-es-default.ns.svc", "es-es-http", "es-es-http.ns", "es-es-http.ns.svc"},
+		"issuerRef":  map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer"},
+		"secretName": "es-es-cm-http-certs",
+		"usages":     []interface{}{"server auth"},
+	}, getSpec("es-es-cm-http-certs"))
+	require.Equal(t, map[string]interface{}{
+		"commonName":  "es-es-transport",
+		"dnsNames":    []interface{}{"*.es-es-default.ns.svc"},
+		"ipAddresses": []interface{}{"10.0.0.1"},
+		"issuerRef":   map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer"},
+		"secretName":  "es-es-cm-transport-certs",
+		"usages":      []interface{}{"server auth", "client auth"},
+	}, getSpec("es-es-cm-transport-certs"))
+
+	// the issuer changes
+	es.Spec.CertManager.IssuerRef = esv1.CertManagerIssuerRef{Name: "vault-issuer"}
+	require.NoError(t, reconcileCertManagerCertificates(c, es, services, extraHTTPSANs, map[string]string{"a": "b"}))
+	require.Equal(t, map[string]interface{}{"name": "vault-issuer"}, getSpec("es-es-cm-transport-certs")["issuerRef"])
+
+	// the certificates are used as user-provided certificates
+	withCertificates := withCertManagerCertificates(es)
+	require.Equal(t, "es-es-cm-http-certs", withCertificates.Spec.HTTP.TLS.Certificate.SecretName)
+	require.Equal(t, "es-es-cm-transport-certs", withCertificates.Spec.Transport.TLS.NodeCertificate.SecretName)
+	require.Empty(t, es.Spec.HTTP.TLS.Certificate.SecretName)
+}
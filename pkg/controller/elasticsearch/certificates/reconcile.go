@@ -43,6 +43,7 @@ func Reconcile(
 	services []corev1.Service,
 	caRotation certificates.RotationParams,
 	certRotation certificates.RotationParams,
+	serverSideApply bool,
 ) (*CertificateResources, *reconciler.Results) {
 	span, _ := apm.StartSpan(ctx, "reconcile_certs", tracing.SpanTypeApp)
 	defer span.End()
@@ -59,6 +60,13 @@ func Reconcile(
 			commonv1.SubjectAlternativeName{DNS: "*." + nodespec.HeadlessServiceName(esv1.StatefulSet(es.Name, nodeSet.Name)) + "." + es.Namespace + ".svc"}
 	}
 
+	// request the certificates to cert-manager if configured, then use them as user-provided certificates
+	if err := reconcileCertManagerCertificates(driver.K8sClient(), es, services, extraHTTPSANs, certsLabels); err != nil {
+		k8s.EmitErrorEvent(driver.Recorder(), err, &es, events.EventReconciliationError, "cert-manager certificate reconciliation error: %v", err)
+		return nil, results.WithError(err)
+	}
+	es = withCertManagerCertificates(es)
+
 	// reconcile HTTP CA and cert
 	var httpCerts *certificates.CertificatesSecret
 	httpCerts, results = certificates.Reconciler{
@@ -75,6 +83,7 @@ func Reconcile(
 		// ES is able to hot-reload TLS certificates: let's keep secrets around even though TLS is disabled.
 		// In case TLS is toggled on/off/on quickly enough, removing the secret would prevent future certs to be available.
 		GarbageCollectSecrets: false,
+		ServerSideApply:       serverSideApply,
 	}.ReconcileCAAndHTTPCerts(ctx)
 	if results.HasError() {
 		_, err := results.Aggregate()
@@ -82,20 +91,32 @@ func Reconcile(
 		return nil, results
 	}
 
-	// reconcile transport CA and certs
-	transportCA, err := transport.ReconcileOrRetrieveCA(
-		driver,
-		es,
-		certsLabels,
-		caRotation,
-	)
+	// retrieve the transport certificate provided by the user, if any
+	externalTransportCert, err := transport.RetrieveExternalCertificate(driver, es)
 	if err != nil {
 		return nil, results.WithError(err)
 	}
-	// make sure to requeue before the CA cert expires
-	results.WithResult(reconcile.Result{
-		RequeueAfter: certificates.ShouldRotateIn(time.Now(), transportCA.Cert.NotAfter, caRotation.RotateBefore),
-	})
+
+	// reconcile transport CA and certs
+	var transportCA *certificates.CA
+	if externalTransportCert != nil {
+		transportCA = externalTransportCert.CA
+	} else {
+		transportCA, err = transport.ReconcileOrRetrieveCA(
+			driver,
+			es,
+			certsLabels,
+			caRotation,
+			serverSideApply,
+		)
+		if err != nil {
+			return nil, results.WithError(err)
+		}
+		// make sure to requeue before the CA cert expires
+		results.WithResult(reconcile.Result{
+			RequeueAfter: certificates.ShouldRotateIn(time.Now(), transportCA.Cert.NotAfter, caRotation.RotateBefore),
+		})
+	}
 
 	// reconcile transport public certs secret
 	if err := transport.ReconcileTransportCertsPublicSecret(driver.K8sClient(), es, transportCA); err != nil {
@@ -103,15 +124,20 @@ func Reconcile(
 	}
 
 	// reconcile transport certificates
-	transportResults := transport.ReconcileTransportCertificatesSecrets(
-		driver.K8sClient(),
-		transportCA,
-		es,
-		certRotation,
-	)
+	var transportResults *reconciler.Results
+	if externalTransportCert != nil {
+		transportResults = transport.ReconcileExternalTransportCertificatesSecrets(driver.K8sClient(), es, *externalTransportCert)
+	} else {
+		transportResults = transport.ReconcileTransportCertificatesSecrets(
+			driver.K8sClient(),
+			transportCA,
+			es,
+			certRotation,
+		)
+	}
 
 	// reconcile remote clusters certificate authorities
-	if err := remoteca.Reconcile(driver.K8sClient(), es, *transportCA); err != nil {
+	if err := remoteca.Reconcile(driver.K8sClient(), es, *transportCA, serverSideApply); err != nil {
 		results.WithError(err)
 	}
 
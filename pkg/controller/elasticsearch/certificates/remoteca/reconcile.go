@@ -38,6 +38,7 @@ func Reconcile(
 	c k8s.Client,
 	es esv1.Elasticsearch,
 	transportCA certificates.CA,
+	serverSideApply bool,
 ) error {
 	// Get all the remote certificate authorities
 	var remoteCAList v1.SecretList
@@ -77,6 +78,6 @@ func Reconcile(
 			certificates.CAFileName: bytes.Join(remoteCertificateAuthorities, nil),
 		},
 	}
-	_, err := reconciler.ReconcileSecret(c, expected, &es)
+	_, err := reconciler.ReconcileSecret(c, expected, &es, serverSideApply)
 	return err
 }
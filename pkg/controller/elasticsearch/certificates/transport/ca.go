@@ -30,6 +30,7 @@ func ReconcileOrRetrieveCA(
 	es esv1.Elasticsearch,
 	labels map[string]string,
 	rotationParams certificates.RotationParams,
+	serverSideApply bool,
 ) (*certificates.CA, error) {
 	esNSN := k8s.ExtractNamespacedName(&es)
 
@@ -59,6 +60,7 @@ func ReconcileOrRetrieveCA(
 			labels,
 			certificates.TransportCAType,
 			rotationParams,
+			serverSideApply,
 		)
 	}
 
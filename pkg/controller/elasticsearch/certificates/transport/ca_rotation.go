@@ -0,0 +1,160 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"bytes"
+	"crypto/x509"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+)
+
+const (
+	// CARotationStartAnnotation records on a transport certificates Secret the time at which a new CA was added to the
+	// CAs trusted by the nodes, while their certificates are still issued by the previous CA.
+	CARotationStartAnnotation = "elasticsearch.k8s.elastic.co/transport-ca-rotation-start"
+
+	// caTrustPropagationDelay is the time given to the kubelet to propagate an updated Secret to the Pods, after which
+	// Elasticsearch reloads the certificates and CAs from disk.
+	caTrustPropagationDelay = 2 * time.Minute
+)
+
+// caRotation is a rotation of the CA of the transport certificates of a StatefulSet, rolled out in two phases so that
+// the nodes can keep connecting to each other:
+// 1. the new CA is trusted along with the previous ones, the nodes keep their certificates issued by the previous CA
+// 2. once the trusted CAs are propagated, the nodes get certificates issued by the new CA
+// The previous CAs are no longer trusted once the new certificates are propagated as well.
+type caRotation struct {
+	ca *certificates.CA
+	// trustedCAs are the CAs trusted once the rotation is over: the CA of the operator, or the CAs provided along with
+	// a user-provided certificate.
+	trustedCAs  []*x509.Certificate
+	previousCAs []*x509.Certificate
+	start       time.Time
+	now         time.Time
+}
+
+// newCARotation returns the state of the rotation of the CA of the given transport certificates Secret, starting the
+// rotation if the given CA is not trusted yet.
+func newCARotation(secret corev1.Secret, ca *certificates.CA, now time.Time) caRotation {
+	return newTrustedCAsRotation(secret, ca, []*x509.Certificate{ca.Cert}, now)
+}
+
+// newTrustedCAsRotation returns the state of the rotation of the CAs trusted in the given transport certificates Secret,
+// starting the rotation if some of the given trusted CAs are not trusted yet.
+func newTrustedCAsRotation(secret corev1.Secret, ca *certificates.CA, trustedCAs []*x509.Certificate, now time.Time) caRotation {
+	rotation := caRotation{ca: ca, trustedCAs: trustedCAs, now: now}
+	trusted, err := certificates.ParsePEMCerts(secret.Data[certificates.CAFileName])
+	if err != nil {
+		log.Error(err, "Cannot parse trusted transport CAs, trusting the current CA only", "namespace", secret.Namespace, "secret_name", secret.Name)
+		return rotation
+	}
+	for _, cert := range trusted {
+		if !containsCert(trustedCAs, cert) {
+			rotation.previousCAs = append(rotation.previousCAs, cert)
+		}
+	}
+	if len(rotation.previousCAs) == 0 {
+		return rotation
+	}
+	started := true
+	for _, cert := range trustedCAs {
+		if !containsCert(trusted, cert) {
+			started = false
+		}
+	}
+	if !started {
+		log.Info("Transport CA changed, trusting both the previous and the new CA", "namespace", secret.Namespace, "secret_name", secret.Name)
+		rotation.start = now
+		return rotation
+	}
+	rotation.start, err = time.Parse(time.RFC3339, secret.Annotations[CARotationStartAnnotation])
+	if err != nil {
+		// the rotation start is unknown, consider that the trusted CAs were propagated a long time ago
+		rotation.start = time.Time{}
+	}
+	return rotation
+}
+
+func containsCert(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r caRotation) inProgress() bool {
+	return len(r.previousCAs) > 0
+}
+
+// keepPreviousCertificates returns true while the nodes may not trust the new CA yet.
+func (r caRotation) keepPreviousCertificates() bool {
+	return r.inProgress() && r.now.Before(r.start.Add(caTrustPropagationDelay))
+}
+
+// keepPreviousCAs returns true while the nodes may still present certificates issued by the previous CAs.
+func (r caRotation) keepPreviousCAs() bool {
+	return r.inProgress() && r.now.Before(r.start.Add(2*caTrustPropagationDelay))
+}
+
+// requeueAfter returns when the rotation should move on to its next phase, 0 if it is over.
+func (r caRotation) requeueAfter() time.Duration {
+	switch {
+	case r.keepPreviousCertificates():
+		return r.start.Add(caTrustPropagationDelay).Sub(r.now)
+	case r.keepPreviousCAs():
+		return r.start.Add(2 * caTrustPropagationDelay).Sub(r.now)
+	default:
+		return 0
+	}
+}
+
+// isPreviousCertificateValid returns true if the given Pod has a valid certificate issued by one of the previous CAs.
+func (r caRotation) isPreviousCertificateValid(
+	es esv1.Elasticsearch,
+	secret corev1.Secret,
+	pod corev1.Pod,
+	certReconcileBefore time.Duration,
+) bool {
+	privateKey := certificates.GetCompatiblePrivateKey(r.ca.PrivateKey, &secret, PodKeyFileName(pod.Name))
+	if privateKey == nil {
+		return false
+	}
+	for _, previousCA := range r.previousCAs {
+		if !shouldIssueNewCertificate(es, secret, pod, privateKey, &certificates.CA{Cert: previousCA}, certReconcileBefore) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileSecret sets the trusted CAs and the rotation start of the given transport certificates Secret.
+func (r caRotation) reconcileSecret(secret *corev1.Secret) {
+	trusted := make([][]byte, 0, len(r.trustedCAs)+len(r.previousCAs))
+	for _, trustedCA := range r.trustedCAs {
+		trusted = append(trusted, trustedCA.Raw)
+	}
+	if r.keepPreviousCAs() {
+		for _, previousCA := range r.previousCAs {
+			trusted = append(trusted, previousCA.Raw)
+		}
+	}
+	secret.Data[certificates.CAFileName] = certificates.EncodePEMCert(trusted...)
+
+	if !r.keepPreviousCAs() {
+		delete(secret.Annotations, CARotationStartAnnotation)
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[CARotationStartAnnotation] = r.start.Format(time.RFC3339)
+}
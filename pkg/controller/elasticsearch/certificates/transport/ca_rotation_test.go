@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_reconcileNodeSetTransportCertificatesSecrets_CARotation(t *testing.T) {
+	es := newEsBuilder().addNodeSet("sset1", 1).build()
+	pod := newPodBuilder().forEs(testEsName).inNodeSet("sset1").withIndex(0).withIP("1.1.1.2").build()
+	secret := newtransportCertsSecretBuilder(testEsName, "sset1").build()
+	require.NoError(t, ensureTransportCertificatesSecretContentsForPod(*es, secret, *pod, testRSACA, certificates.RotationParams{
+		Validity:     certificates.DefaultCertValidity,
+		RotateBefore: certificates.DefaultRotateBefore,
+	}))
+	previousCert := secret.Data[PodCertFileName(pod.Name)]
+
+	newCA, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{Subject: pkix.Name{CommonName: "new-common-name"}})
+	require.NoError(t, err)
+
+	c := k8s.NewFakeClient(es, pod, secret)
+	reconcileWith := func(ca *certificates.CA) (time.Duration, corev1.Secret) {
+		t.Helper()
+		res, err := reconcileNodeSetTransportCertificatesSecrets(c, ca, *es, "test-es-name-es-sset1", certificates.RotationParams{
+			Validity:     certificates.DefaultCertValidity,
+			RotateBefore: certificates.DefaultRotateBefore,
+		}, map[string]verifiedCertificate{})
+		require.NoError(t, err)
+		var reconciled corev1.Secret
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &reconciled))
+		return res.RequeueAfter, reconciled
+	}
+	// shifts the start of the rotation back in time
+	elapse := func(reconciled corev1.Secret, d time.Duration) {
+		t.Helper()
+		start, err := time.Parse(time.RFC3339, reconciled.Annotations[CARotationStartAnnotation])
+		require.NoError(t, err)
+		reconciled.Annotations[CARotationStartAnnotation] = start.Add(-d).Format(time.RFC3339)
+		require.NoError(t, c.Update(context.Background(), &reconciled))
+	}
+
+	// no rotation
+	requeueAfter, reconciled := reconcileWith(testRSACA)
+	require.Zero(t, requeueAfter)
+	require.Equal(t, testRSACABytes, reconciled.Data[certificates.CAFileName])
+	require.Equal(t, previousCert, reconciled.Data[PodCertFileName(pod.Name)])
+
+	// phase 1: both CAs are trusted, the certificate issued by the previous CA is kept
+	requeueAfter, reconciled = reconcileWith(newCA)
+	require.True(t, requeueAfter > 0 && requeueAfter <= caTrustPropagationDelay)
+	require.Equal(t, certificates.EncodePEMCert(newCA.Cert.Raw, testRSACA.Cert.Raw), reconciled.Data[certificates.CAFileName])
+	require.Equal(t, previousCert, reconciled.Data[PodCertFileName(pod.Name)])
+	require.Contains(t, reconciled.Annotations, CARotationStartAnnotation)
+
+	// phase 2: both CAs are still trusted, the certificate is issued by the new CA
+	elapse(reconciled, caTrustPropagationDelay)
+	requeueAfter, reconciled = reconcileWith(newCA)
+	require.True(t, requeueAfter > 0 && requeueAfter <= caTrustPropagationDelay)
+	require.Equal(t, certificates.EncodePEMCert(newCA.Cert.Raw, testRSACA.Cert.Raw), reconciled.Data[certificates.CAFileName])
+	require.NotEqual(t, previousCert, reconciled.Data[PodCertFileName(pod.Name)])
+	certs, err := certificates.ParsePEMCerts(reconciled.Data[PodCertFileName(pod.Name)])
+	require.NoError(t, err)
+	require.NoError(t, certs[0].CheckSignatureFrom(newCA.Cert))
+
+	// the rotation is over: only the new CA is trusted
+	elapse(reconciled, caTrustPropagationDelay)
+	requeueAfter, reconciled = reconcileWith(newCA)
+	require.Zero(t, requeueAfter)
+	require.Equal(t, certificates.EncodePEMCert(newCA.Cert.Raw), reconciled.Data[certificates.CAFileName])
+	require.NotContains(t, reconciled.Annotations, CARotationStartAnnotation)
+}
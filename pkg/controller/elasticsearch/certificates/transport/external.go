@@ -0,0 +1,217 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"context"
+	"crypto/x509"
+	"reflect"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
+)
+
+// ExternalCertificate is a transport certificate issued outside of the operator, used by all the nodes of a cluster.
+type ExternalCertificate struct {
+	// CA is the CA which issued the certificate, without its private key.
+	CA *certificates.CA
+	// TrustedCAs are the PEM encoded certificates of the CAs trusted by the nodes.
+	TrustedCAs []byte
+	// Certificate is the PEM encoded certificate of the nodes.
+	Certificate []byte
+	// PrivateKey is the PEM encoded private key of the certificate.
+	PrivateKey []byte
+}
+
+func CustomNodeCertsWatchKey(es types.NamespacedName) string {
+	return esv1.ESNamer.Suffix(es.Name, "custom-transport-node-certs")
+}
+
+// RetrieveExternalCertificate retrieves the transport certificate provided by the user, and sets up a watch to
+// reconcile the cluster when it changes. It returns nil if the certificates are issued by the operator.
+func RetrieveExternalCertificate(driver driver.Interface, es esv1.Elasticsearch) (*ExternalCertificate, error) {
+	esNSN := k8s.ExtractNamespacedName(&es)
+	if err := certificates.ReconcileCustomCertWatch(
+		driver.DynamicWatches(),
+		CustomNodeCertsWatchKey(esNSN),
+		esNSN,
+		es.Spec.Transport.TLS.NodeCertificate,
+	); err != nil {
+		return nil, err
+	}
+
+	secret, err := certificates.GetSecretFromRef(driver.K8sClient(), esNSN, es.Spec.Transport.TLS.NodeCertificate)
+	if err != nil {
+		driver.Recorder().Eventf(&es, corev1.EventTypeWarning, events.EventReasonUnexpected, err.Error())
+		return nil, err
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	// some issuers, such as the ACME issuers of cert-manager, do not provide the CA: reuse the current one if it
+	// issued the certificate
+	var currentCA corev1.Secret
+	if err := driver.K8sClient().Get(context.Background(), PublicCertsSecretRef(esNSN), &currentCA); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	external, err := parseExternalCertificate(*secret, currentCA.Data[certificates.CAFileName])
+	if err != nil {
+		driver.Recorder().Eventf(&es, corev1.EventTypeWarning, events.EventReasonValidation, err.Error())
+		return nil, err
+	}
+	return external, nil
+}
+
+// parseExternalCertificate checks that the given Secret contains a certificate, its private key and the CA which
+// issued it. If the Secret does not contain the CA, the given current CA is used, as long as it issued the certificate.
+func parseExternalCertificate(secret corev1.Secret, currentCA []byte) (*ExternalCertificate, error) {
+	for _, key := range []string{certificates.CertFileName, certificates.KeyFileName} {
+		if len(secret.Data[key]) == 0 {
+			return nil, pkgerrors.Errorf("can't find %s in %s/%s", key, secret.Namespace, secret.Name)
+		}
+	}
+	trustedCAs := secret.Data[certificates.CAFileName]
+	if len(trustedCAs) == 0 {
+		trustedCAs = currentCA
+	}
+	certs, err := certificates.ParsePEMCerts(secret.Data[certificates.CertFileName])
+	if err != nil || len(certs) == 0 {
+		return nil, pkgerrors.Errorf("can't parse certificate %s in %s/%s", certificates.CertFileName, secret.Namespace, secret.Name)
+	}
+	privateKey, err := certificates.ParsePEMPrivateKey(secret.Data[certificates.KeyFileName])
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "can't parse private key %s in %s/%s", certificates.KeyFileName, secret.Namespace, secret.Name)
+	}
+	if !certificates.PrivateMatchesPublicKey(certs[0].PublicKey, privateKey) {
+		return nil, pkgerrors.Errorf("private key %s does not match certificate %s in %s/%s", certificates.KeyFileName, certificates.CertFileName, secret.Namespace, secret.Name)
+	}
+	cas, err := certificates.ParsePEMCerts(trustedCAs)
+	if err != nil || len(cas) == 0 {
+		return nil, pkgerrors.Errorf("can't parse CA certificate %s in %s/%s", certificates.CAFileName, secret.Namespace, secret.Name)
+	}
+	var issuer *x509.Certificate
+	for _, ca := range cas {
+		if certs[0].CheckSignatureFrom(ca) == nil {
+			issuer = ca
+			break
+		}
+	}
+	if issuer == nil {
+		if len(secret.Data[certificates.CAFileName]) == 0 {
+			return nil, pkgerrors.Errorf("can't find %s in %s/%s: the issuer of the certificate must provide its CA", certificates.CAFileName, secret.Namespace, secret.Name)
+		}
+		// the certificate may be issued by an intermediate CA of the chain in tls.crt
+		issuer = cas[0]
+	}
+	return &ExternalCertificate{
+		CA:          certificates.NewCA(nil, issuer),
+		TrustedCAs:  trustedCAs,
+		Certificate: secret.Data[certificates.CertFileName],
+		PrivateKey:  secret.Data[certificates.KeyFileName],
+	}, nil
+}
+
+// ReconcileExternalTransportCertificatesSecrets copies the given external certificate into the transport certificates
+// secret of each StatefulSet, for each of its Pods. Elasticsearch reloads the certificates when the Secrets change.
+// A change of the CAs is rolled out in two phases, as for the CA of the operator, so that the nodes keep trusting each
+// other.
+func ReconcileExternalTransportCertificatesSecrets(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	external ExternalCertificate,
+) *reconciler.Results {
+	results := &reconciler.Results{}
+	actualStatefulSets, err := sset.RetrieveActualStatefulSets(c, k8s.ExtractNamespacedName(&es))
+	if err != nil {
+		return results.WithError(err)
+	}
+	ssets := set.Make()
+	for _, actualStatefulSet := range actualStatefulSets {
+		ssets.Add(actualStatefulSet.Name)
+	}
+	for _, nodeSet := range es.Spec.NodeSets {
+		ssets.Add(esv1.StatefulSet(es.Name, nodeSet.Name))
+	}
+	for ssetName := range ssets {
+		requeue, err := reconcileNodeSetExternalTransportCertificatesSecret(c, es, ssetName, external)
+		if err != nil {
+			results.WithError(err)
+		}
+		results.WithResult(requeue)
+	}
+	// certificates issued by the operator are not verified anymore
+	verifiedCertificates.forget(k8s.ExtractNamespacedName(&es))
+	return results
+}
+
+// reconcileNodeSetExternalTransportCertificatesSecret reconciles the transport certificates secret of the given
+// StatefulSet with the given external certificate. The returned result requeues the reconciliation to move on to the
+// next phase of an in-progress CA rotation.
+func reconcileNodeSetExternalTransportCertificatesSecret(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	ssetName string,
+	external ExternalCertificate,
+) (reconcile.Result, error) {
+	var pods corev1.PodList
+	matchLabels := label.NewLabelSelectorForStatefulSetName(es.Name, ssetName)
+	if err := c.List(context.Background(), &pods, matchLabels, client.InNamespace(es.Namespace)); err != nil {
+		return reconcile.Result{}, pkgerrors.WithStack(err)
+	}
+	secret, err := ensureTransportCertificatesSecretExists(c, es, ssetName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	trustedCAs, err := certificates.ParsePEMCerts(external.TrustedCAs)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	rotation := newTrustedCAsRotation(*secret, external.CA, trustedCAs, time.Now())
+
+	expected := secret.DeepCopy()
+	expected.Data = map[string][]byte{}
+	for _, pod := range pods.Items {
+		keyFileName, certFileName := PodKeyFileName(pod.Name), PodCertFileName(pod.Name)
+		// keep the previous certificates until the new CAs are trusted by all the nodes
+		if rotation.keepPreviousCertificates() && len(secret.Data[keyFileName]) > 0 && len(secret.Data[certFileName]) > 0 {
+			expected.Data[keyFileName] = secret.Data[keyFileName]
+			expected.Data[certFileName] = secret.Data[certFileName]
+			continue
+		}
+		expected.Data[keyFileName] = external.PrivateKey
+		expected.Data[certFileName] = external.Certificate
+	}
+	// trust the new CAs, along with the previous ones while they are being rotated
+	rotation.reconcileSecret(expected)
+	requeue := reconcile.Result{RequeueAfter: rotation.requeueAfter()}
+
+	if reflect.DeepEqual(expected.Data, secret.Data) && reflect.DeepEqual(expected.Annotations, secret.Annotations) {
+		return requeue, nil
+	}
+	log.Info("Updating transport certificates with the user-provided certificate", "namespace", es.Namespace, "secret_name", secret.Name)
+	if err := c.Update(context.Background(), expected); err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, pod := range pods.Items {
+		annotation.MarkPodAsUpdated(c, pod)
+	}
+	return requeue, nil
+}
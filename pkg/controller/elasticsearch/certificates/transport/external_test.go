@@ -0,0 +1,173 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func externalCertSecret(data map[string][]byte) corev1.Secret {
+	return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "external"}, Data: data}
+}
+
+func Test_parseExternalCertificate(t *testing.T) {
+	otherCA, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{Subject: pkix.Name{CommonName: "other"}})
+	require.NoError(t, err)
+	tests := []struct {
+		name      string
+		data      map[string][]byte
+		currentCA []byte
+		wantErr   bool
+	}{
+		{
+			name: "valid certificate",
+			data: map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey, "ca.crt": testRSACABytes},
+		},
+		{
+			name:      "CA provided along with the certificate",
+			data:      map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey, "ca.crt": testRSACABytes},
+			currentCA: certificates.EncodePEMCert(otherCA.Cert.Raw),
+		},
+		{
+			name:    "missing CA",
+			data:    map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey},
+			wantErr: true,
+		},
+		{
+			name:      "missing CA: reuse the current CA which issued the certificate",
+			data:      map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey},
+			currentCA: testRSACABytes,
+		},
+		{
+			name:      "missing CA: the current CA did not issue the certificate",
+			data:      map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey},
+			currentCA: certificates.EncodePEMCert(otherCA.Cert.Raw),
+			wantErr:   true,
+		},
+		{
+			name:    "private key not matching the certificate",
+			data:    map[string][]byte{"tls.crt": rsaCert, "tls.key": testECDSAPEMPrivateKey, "ca.crt": testRSACABytes},
+			wantErr: true,
+		},
+		{
+			name:    "invalid certificate",
+			data:    map[string][]byte{"tls.crt": []byte("invalid"), "tls.key": testRSAPEMPrivateKey, "ca.crt": testRSACABytes},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExternalCertificate(externalCertSecret(tt.data), tt.currentCA)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testRSACA.Cert.Raw, got.CA.Cert.Raw)
+			require.Nil(t, got.CA.PrivateKey)
+			require.Equal(t, rsaCert, got.Certificate)
+			require.Equal(t, testRSACABytes, got.TrustedCAs)
+		})
+	}
+}
+
+func TestReconcileExternalTransportCertificatesSecrets(t *testing.T) {
+	es := newEsBuilder().addNodeSet("sset1", 2).build()
+	pod0 := newPodBuilder().forEs(testEsName).inNodeSet("sset1").withIndex(0).withIP("1.1.1.2").build()
+	pod1 := newPodBuilder().forEs(testEsName).inNodeSet("sset1").withIndex(1).build()
+	// certificates previously issued by the operator
+	secret := newtransportCertsSecretBuilder(testEsName, "sset1").forPodIndices(0, 1, 2).build()
+	secret.Annotations = map[string]string{CARotationStartAnnotation: "2021-01-01T00:00:00Z"}
+	c := k8s.NewFakeClient(es, pod0, pod1, secret)
+
+	external, err := parseExternalCertificate(externalCertSecret(
+		map[string][]byte{"tls.crt": rsaCert, "tls.key": testRSAPEMPrivateKey, "ca.crt": testRSACABytes},
+	), nil)
+	require.NoError(t, err)
+	_, err = ReconcileExternalTransportCertificatesSecrets(c, *es, *external).Aggregate()
+	require.NoError(t, err)
+
+	var reconciled corev1.Secret
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: secret.Name}, &reconciled))
+	require.Equal(t, map[string][]byte{
+		certificates.CAFileName:    testRSACABytes,
+		PodKeyFileName(pod0.Name):  testRSAPEMPrivateKey,
+		PodCertFileName(pod0.Name): rsaCert,
+		PodKeyFileName(pod1.Name):  testRSAPEMPrivateKey,
+		PodCertFileName(pod1.Name): rsaCert,
+	}, reconciled.Data)
+	require.NotContains(t, reconciled.Annotations, CARotationStartAnnotation)
+}
+
+func TestReconcileExternalTransportCertificatesSecrets_CARotation(t *testing.T) {
+	es := newEsBuilder().addNodeSet("sset1", 1).build()
+	pod := newPodBuilder().forEs(testEsName).inNodeSet("sset1").withIndex(0).withIP("1.1.1.2").build()
+	// certificates previously issued by the operator
+	secret := newtransportCertsSecretBuilder(testEsName, "sset1").forPodIndices(0).build()
+	c := k8s.NewFakeClient(es, pod, secret)
+
+	// certificate issued by another CA
+	newCA, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{Subject: pkix.Name{CommonName: "new-common-name"}})
+	require.NoError(t, err)
+	template, err := createValidatedCertificateTemplate(*pod, *es, testRSACSR, certificates.DefaultCertValidity)
+	require.NoError(t, err)
+	certData, err := newCA.CreateCertificate(*template)
+	require.NoError(t, err)
+	newCert := certificates.EncodePEMCert(certData)
+	newCABytes := certificates.EncodePEMCert(newCA.Cert.Raw)
+	external, err := parseExternalCertificate(externalCertSecret(
+		map[string][]byte{"tls.crt": newCert, "tls.key": testRSAPEMPrivateKey, "ca.crt": newCABytes},
+	), nil)
+	require.NoError(t, err)
+
+	reconcile := func() (time.Duration, corev1.Secret) {
+		t.Helper()
+		res, err := reconcileNodeSetExternalTransportCertificatesSecret(c, *es, "test-es-name-es-sset1", *external)
+		require.NoError(t, err)
+		var reconciled corev1.Secret
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &reconciled))
+		return res.RequeueAfter, reconciled
+	}
+	// shifts the start of the rotation back in time
+	elapse := func(reconciled corev1.Secret, d time.Duration) {
+		t.Helper()
+		start, err := time.Parse(time.RFC3339, reconciled.Annotations[CARotationStartAnnotation])
+		require.NoError(t, err)
+		reconciled.Annotations[CARotationStartAnnotation] = start.Add(-d).Format(time.RFC3339)
+		require.NoError(t, c.Update(context.Background(), &reconciled))
+	}
+
+	// phase 1: both CAs are trusted, the certificate issued by the previous CA is kept
+	requeueAfter, reconciled := reconcile()
+	require.True(t, requeueAfter > 0 && requeueAfter <= caTrustPropagationDelay)
+	require.Equal(t, certificates.EncodePEMCert(newCA.Cert.Raw, testRSACA.Cert.Raw), reconciled.Data[certificates.CAFileName])
+	require.Equal(t, rsaCert, reconciled.Data[PodCertFileName(pod.Name)])
+
+	// phase 2: both CAs are still trusted, the user-provided certificate is used
+	elapse(reconciled, caTrustPropagationDelay)
+	requeueAfter, reconciled = reconcile()
+	require.True(t, requeueAfter > 0 && requeueAfter <= caTrustPropagationDelay)
+	require.Equal(t, certificates.EncodePEMCert(newCA.Cert.Raw, testRSACA.Cert.Raw), reconciled.Data[certificates.CAFileName])
+	require.Equal(t, newCert, reconciled.Data[PodCertFileName(pod.Name)])
+
+	// the rotation is over: only the new CA is trusted
+	elapse(reconciled, caTrustPropagationDelay)
+	requeueAfter, reconciled = reconcile()
+	require.Zero(t, requeueAfter)
+	require.Equal(t, newCABytes, reconciled.Data[certificates.CAFileName])
+	require.Equal(t, newCert, reconciled.Data[PodCertFileName(pod.Name)])
+	require.NotContains(t, reconciled.Annotations, CARotationStartAnnotation)
+}
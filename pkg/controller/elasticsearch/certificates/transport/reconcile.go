@@ -5,7 +5,6 @@
 package transport
 
 import (
-	"bytes"
 	"context"
 	"reflect"
 	"strings"
@@ -57,11 +56,15 @@ func ReconcileTransportCertificatesSecrets(
 		ssets.Add(esv1.StatefulSet(es.Name, nodeSet.Name))
 	}
 
+	verified := make(map[string]verifiedCertificate)
 	for ssetName := range ssets {
-		if err := reconcileNodeSetTransportCertificatesSecrets(c, ca, es, ssetName, rotationParams); err != nil {
+		requeue, err := reconcileNodeSetTransportCertificatesSecrets(c, ca, es, ssetName, rotationParams, verified)
+		if err != nil {
 			results.WithError(err)
 		}
+		results.WithResult(requeue)
 	}
+	verifiedCertificates.set(k8s.ExtractNamespacedName(&es), verified)
 	return results
 }
 
@@ -88,44 +91,68 @@ func DeleteLegacyTransportCertificate(client k8s.Client, es esv1.Elasticsearch)
 }
 
 // reconcileNodeSetTransportCertificatesSecrets reconciles the secret which contains the transport certificates for
-// a given StatefulSet.
+// a given StatefulSet. The certificates of the pods which are valid once the secret is reconciled are added to verified.
+// The returned result requeues the reconciliation to move on to the next phase of an in-progress CA rotation.
 func reconcileNodeSetTransportCertificatesSecrets(
 	c k8s.Client,
 	ca *certificates.CA,
 	es esv1.Elasticsearch,
 	ssetName string,
 	rotationParams certificates.RotationParams,
-) error {
+	verified map[string]verifiedCertificate,
+) (reconcile.Result, error) {
 	results := &reconciler.Results{}
 	// List all the existing Pods in the nodeSet
 	var pods corev1.PodList
 	matchLabels := label.NewLabelSelectorForStatefulSetName(es.Name, ssetName)
 	ns := client.InNamespace(es.Namespace)
 	if err := c.List(context.Background(), &pods, matchLabels, ns); err != nil {
-		return errors.WithStack(err)
+		return reconcile.Result{}, errors.WithStack(err)
 	}
 
 	secret, err := ensureTransportCertificatesSecretExists(c, es, ssetName)
 	if err != nil {
-		return err
+		return reconcile.Result{}, err
 	}
 	// defensive copy of the current secret so we can check whether we need to update later on
 	currentTransportCertificatesSecret := secret.DeepCopy()
+	cluster := k8s.ExtractNamespacedName(&es)
+	rotation := newCARotation(*secret, ca, time.Now())
+	podsVerified := make(map[string]verifiedCertificate, len(pods.Items))
 	for _, pod := range pods.Items {
 		if pod.Status.PodIP == "" {
 			log.Info("Skipping pod because it has no IP yet", "namespace", pod.Namespace, "pod_name", pod.Name)
 			continue
 		}
 
+		// keep the certificates issued by the previous CA until the new CA is trusted by all the nodes
+		if rotation.keepPreviousCertificates() && rotation.isPreviousCertificateValid(es, *secret, pod, rotationParams.RotateBefore) {
+			continue
+		}
+
+		// skip the verification of certificates whose pod, certificate and CA did not change since the last one
+		fingerprint := certificateFingerprint(es, pod, ca, *secret)
+		if notAfter, ok := verifiedCertificates.get(cluster, pod.Name, fingerprint, rotationParams.RotateBefore); ok {
+			podsVerified[pod.Name] = verifiedCertificate{fingerprint: fingerprint, notAfter: notAfter}
+			results.WithResult(reconcile.Result{
+				RequeueAfter: certificates.ShouldRotateIn(time.Now(), notAfter, rotationParams.RotateBefore),
+			})
+			continue
+		}
+
 		if err := ensureTransportCertificatesSecretContentsForPod(
 			es, secret, pod, ca, rotationParams,
 		); err != nil {
-			return err
+			return reconcile.Result{}, err
 		}
 		certCommonName := buildCertificateCommonName(pod, es.Name, es.Namespace)
 		cert := extractTransportCert(*secret, pod, certCommonName)
 		if cert == nil {
-			return errors.New("no certificate found for pod")
+			return reconcile.Result{}, errors.New("no certificate found for pod")
+		}
+		podsVerified[pod.Name] = verifiedCertificate{
+			fingerprint: certificateFingerprint(es, pod, ca, *secret),
+			notAfter:    cert.NotAfter,
 		}
 		// handle cert expiry via requeue
 		results.WithResult(reconcile.Result{
@@ -158,23 +185,22 @@ func reconcileNodeSetTransportCertificatesSecrets(
 		}
 	}
 
-	caBytes := certificates.EncodePEMCert(ca.Cert.Raw)
-
-	// compare with current trusted CA certs.
-	if !bytes.Equal(caBytes, secret.Data[certificates.CAFileName]) {
-		secret.Data[certificates.CAFileName] = caBytes
-	}
+	// trust the current CA, along with the previous ones while they are being rotated
+	rotation.reconcileSecret(secret)
 
 	if !reflect.DeepEqual(secret, currentTransportCertificatesSecret) {
 		if err := c.Update(context.Background(), secret); err != nil {
-			return err
+			return reconcile.Result{}, err
 		}
 		for _, pod := range pods.Items {
 			annotation.MarkPodAsUpdated(c, pod)
 		}
 	}
 
-	return nil
+	for podName, certificate := range podsVerified {
+		verified[podName] = certificate
+	}
+	return reconcile.Result{RequeueAfter: rotation.requeueAfter()}, nil
 }
 
 // ensureTransportCertificatesSecretExists ensures the existence and labels of the Secret that at a later point
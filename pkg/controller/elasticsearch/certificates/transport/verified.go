@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+)
+
+// verifiedCertificates records, per cluster and pod, the fingerprint of the inputs of the last successful verification
+// of the transport certificate of the pod, so that the certificates of pods which did not change since the last
+// reconciliation are not parsed and verified again. In large clusters, these verifications dominate the reconciliation
+// CPU usage.
+var verifiedCertificates = newVerifiedCertificatesCache()
+
+// ForgetVerifiedCertificates removes the verified certificates of the given cluster. It should be called once the
+// cluster is deleted.
+func ForgetVerifiedCertificates(cluster types.NamespacedName) {
+	verifiedCertificates.forget(cluster)
+}
+
+type verifiedCertificate struct {
+	fingerprint string
+	notAfter    time.Time
+}
+
+type verifiedCertificatesCache struct {
+	mutex   sync.Mutex
+	entries map[types.NamespacedName]map[string]verifiedCertificate // per cluster, per pod name
+}
+
+func newVerifiedCertificatesCache() *verifiedCertificatesCache {
+	return &verifiedCertificatesCache{entries: make(map[types.NamespacedName]map[string]verifiedCertificate)}
+}
+
+// get returns the expiry date of the certificate of the given pod if it was verified with the same fingerprint and does
+// not need to be rotated yet.
+func (v *verifiedCertificatesCache) get(cluster types.NamespacedName, podName, fingerprint string, rotateBefore time.Duration) (time.Time, bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	entry, exists := v.entries[cluster][podName]
+	if !exists || entry.fingerprint != fingerprint || time.Now().After(entry.notAfter.Add(-rotateBefore)) {
+		return time.Time{}, false
+	}
+	return entry.notAfter, true
+}
+
+// set records the verified certificates of the given cluster pods, forgetting the pods not included.
+func (v *verifiedCertificatesCache) set(cluster types.NamespacedName, verified map[string]verifiedCertificate) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.entries[cluster] = verified
+}
+
+func (v *verifiedCertificatesCache) forget(cluster types.NamespacedName) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	delete(v.entries, cluster)
+}
+
+// certificateFingerprint returns a fingerprint of the inputs of the verification of the transport certificate of the
+// given pod: the pod itself, the Elasticsearch spec, the CA, and the certificate and private key of the pod.
+func certificateFingerprint(es esv1.Elasticsearch, pod corev1.Pod, ca *certificates.CA, secret corev1.Secret) string {
+	h := sha256.New()
+	for _, data := range [][]byte{
+		[]byte(pod.UID),
+		[]byte(pod.ResourceVersion),
+		[]byte(strconv.FormatInt(es.Generation, 10)),
+		ca.Cert.Raw,
+		secret.Data[PodKeyFileName(pod.Name)],
+		secret.Data[PodCertFileName(pod.Name)],
+	} {
+		_, _ = h.Write(data)
+		// separate the inputs so that they cannot be confused with each other
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,68 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileTransportCertificatesSecrets_verifiedCertificates(t *testing.T) {
+	es := newEsBuilder().addNodeSet("sset1", 2).build()
+	es.Name = "verified"
+	pod := newPodBuilder().forEs(es.Name).inNodeSet("sset1").withIndex(0).withIP("1.1.1.2").build()
+	otherPod := newPodBuilder().forEs(es.Name).inNodeSet("sset1").withIndex(1).withIP("1.1.1.3").build()
+	c := k8s.NewFakeClient(pod, otherPod)
+	rotationParams := certificates.RotationParams{
+		Validity:     certificates.DefaultCertValidity,
+		RotateBefore: certificates.DefaultRotateBefore,
+	}
+	defer ForgetVerifiedCertificates(k8s.ExtractNamespacedName(es))
+
+	reconcileCert := func() []byte {
+		_, err := ReconcileTransportCertificatesSecrets(c, testRSACA, *es, rotationParams).Aggregate()
+		require.NoError(t, err)
+		var secret corev1.Secret
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{
+			Namespace: es.Namespace,
+			Name:      esv1.StatefulSetTransportCertificatesSecret(esv1.StatefulSet(es.Name, "sset1")),
+		}, &secret))
+		return secret.Data[PodCertFileName(pod.Name)]
+	}
+
+	initialCert := reconcileCert()
+	// pods are annotated once their certificates are issued, which changes their resource version
+	require.Equal(t, initialCert, reconcileCert())
+	require.Len(t, verifiedCertificates.entries[k8s.ExtractNamespacedName(es)], 2)
+
+	// the certificate is not verified again as long as the pod, the CA, the secret and the spec generation do not change
+	es.Spec.Transport.TLS.SubjectAlternativeNames = []commonv1.SubjectAlternativeName{{DNS: "my-node.example.com"}}
+	require.Equal(t, initialCert, reconcileCert())
+
+	// a new certificate is issued once the spec generation changes
+	es.Generation++
+	updatedCert := reconcileCert()
+	require.NotEqual(t, initialCert, updatedCert)
+
+	// or once the pod changes
+	require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(pod), pod))
+	pod.Status.PodIP = "1.1.1.4"
+	require.NoError(t, c.Update(context.Background(), pod))
+	require.NotEqual(t, updatedCert, reconcileCert())
+
+	// deleted pods are forgotten
+	require.NoError(t, c.Delete(context.Background(), otherPod))
+	reconcileCert()
+	require.Len(t, verifiedCertificates.entries[k8s.ExtractNamespacedName(es)], 1)
+}
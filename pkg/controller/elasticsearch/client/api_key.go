@@ -0,0 +1,74 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type CrossClusterAPIKeyClient interface {
+	// CreateCrossClusterAPIKey creates a cross-cluster API key.
+	// Introduced in: Elasticsearch 8.10.0
+	CreateCrossClusterAPIKey(ctx context.Context, request CrossClusterAPIKeyCreateRequest) (CrossClusterAPIKeyCreateResponse, error)
+	// UpdateCrossClusterAPIKey updates the access and the metadata of an existing cross-cluster API key.
+	// Introduced in: Elasticsearch 8.10.0
+	UpdateCrossClusterAPIKey(ctx context.Context, id string, request CrossClusterAPIKeyUpdateRequest) error
+	// InvalidateAPIKey invalidates the API key with the given ID.
+	InvalidateAPIKey(ctx context.Context, id string) error
+}
+
+// CrossClusterAPIKeyAccess models the access granted by a cross-cluster API key.
+type CrossClusterAPIKeyAccess struct {
+	Search      []CrossClusterAPIKeyIndices `json:"search,omitempty"`
+	Replication []CrossClusterAPIKeyIndices `json:"replication,omitempty"`
+}
+
+// CrossClusterAPIKeyIndices models the indices of a cross-cluster API key access.
+type CrossClusterAPIKeyIndices struct {
+	Names []string `json:"names"`
+}
+
+// CrossClusterAPIKeyCreateRequest models a request to the _security/cross_cluster/api_key API.
+type CrossClusterAPIKeyCreateRequest struct {
+	Name       string                   `json:"name"`
+	Expiration string                   `json:"expiration,omitempty"`
+	Access     CrossClusterAPIKeyAccess `json:"access"`
+	Metadata   map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+// CrossClusterAPIKeyCreateResponse partially models the response of the _security/cross_cluster/api_key API.
+type CrossClusterAPIKeyCreateResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Expiration int64  `json:"expiration,omitempty"`
+	Encoded    string `json:"encoded"`
+}
+
+// CrossClusterAPIKeyUpdateRequest models a request to the _security/cross_cluster/api_key/<id> API.
+type CrossClusterAPIKeyUpdateRequest struct {
+	Access   CrossClusterAPIKeyAccess `json:"access"`
+	Metadata map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+// InvalidateAPIKeyRequest models a request to invalidate API keys with the _security/api_key API.
+type InvalidateAPIKeyRequest struct {
+	IDs []string `json:"ids"`
+}
+
+func (c *baseClient) CreateCrossClusterAPIKey(ctx context.Context, request CrossClusterAPIKeyCreateRequest) (CrossClusterAPIKeyCreateResponse, error) {
+	var response CrossClusterAPIKeyCreateResponse
+	err := c.post(ctx, "/_security/cross_cluster/api_key", request, &response)
+	return response, err
+}
+
+func (c *baseClient) UpdateCrossClusterAPIKey(ctx context.Context, id string, request CrossClusterAPIKeyUpdateRequest) error {
+	return c.put(ctx, fmt.Sprintf("/_security/cross_cluster/api_key/%s", id), request, nil)
+}
+
+func (c *baseClient) InvalidateAPIKey(ctx context.Context, id string) error {
+	return c.request(ctx, http.MethodDelete, "/_security/api_key", InvalidateAPIKeyRequest{IDs: []string{id}}, nil, nil)
+}
@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func TestClient_CreateCrossClusterAPIKey(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("8.10.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/cross_cluster/api_key", req.URL.Path)
+		require.Equal(t, http.MethodPost, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"key","expiration":"60d","access":{"search":[{"names":["logs-*"]}]}}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(strings.NewReader(
+				`{"id":"id1","name":"key","expiration":1700000000000,"api_key":"secret","encoded":"aWQxOnNlY3JldA=="}`)),
+			Header:  make(http.Header),
+			Request: req,
+		}
+	})
+	response, err := testClient.CreateCrossClusterAPIKey(context.Background(), CrossClusterAPIKeyCreateRequest{
+		Name:       "key",
+		Expiration: "60d",
+		Access:     CrossClusterAPIKeyAccess{Search: []CrossClusterAPIKeyIndices{{Names: []string{"logs-*"}}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, CrossClusterAPIKeyCreateResponse{
+		ID:         "id1",
+		Name:       "key",
+		Expiration: 1700000000000,
+		Encoded:    "aWQxOnNlY3JldA==",
+	}, response)
+}
+
+func TestClient_InvalidateAPIKey(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("8.10.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		require.Equal(t, http.MethodDelete, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"ids":["id1"]}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"invalidated_api_keys":["id1"]}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	assert.NoError(t, testClient.InvalidateAPIKey(context.Background(), "id1"))
+}
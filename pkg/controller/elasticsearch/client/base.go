@@ -30,12 +30,18 @@ type baseClient struct {
 	es       types.NamespacedName
 	caCerts  []*x509.Certificate
 	version  version.Version
+	// pooled is true if HTTP is shared with other clients, in which case its connections are kept alive on Close.
+	pooled bool
+	// breaker fails requests fast while the cluster is unreachable, nil if disabled.
+	breaker *circuitBreaker
+	// dryRun prevents the requests changing the state of the cluster from being sent.
+	dryRun bool
 }
 
 // Close idle connections in the underlying http client.
 // Should be called once this client is not used anymore.
 func (c *baseClient) Close() {
-	if c.HTTP != nil {
+	if c.HTTP != nil && !c.pooled {
 		// When the http transport goes out of scope, the underlying goroutines responsible
 		// for handling keep-alive connections are not closed automatically.
 		// Since this client gets recreated frequently we would effectively be leaking goroutines.
@@ -78,7 +84,15 @@ func (c *baseClient) doRequest(context context.Context, request *http.Request) (
 		"namespace", c.es.Namespace,
 		"es_name", c.es.Name,
 	)
+	if err := c.breaker.allow(); err != nil {
+		return nil, newDecoratedHTTPError(request, err)
+	}
 	response, err := c.HTTP.Do(withContext)
+	var statusCode int
+	if response != nil {
+		statusCode = response.StatusCode
+	}
+	c.breaker.record(statusCode, err)
 	if err != nil {
 		return response, newDecoratedHTTPError(request, err)
 	}
@@ -120,6 +134,17 @@ func (c *baseClient) request(
 	responseObj interface{},
 	skipErrFunc func(error) bool,
 ) error {
+	if c.dryRun && method != http.MethodGet {
+		log.Info(
+			"Dry run: Elasticsearch request not sent",
+			"method", method,
+			"path", pathWithQuery,
+			"namespace", c.es.Namespace,
+			"es_name", c.es.Name,
+		)
+		return &DryRunError{Method: method, Path: pathWithQuery}
+	}
+
 	var body io.Reader = http.NoBody
 	if requestObj != nil {
 		outData, err := json.Marshal(requestObj)
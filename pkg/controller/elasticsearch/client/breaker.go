@@ -0,0 +1,156 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// circuitBreakerThreshold is the number of consecutive failed requests after which the circuit breaker of a
+	// cluster opens.
+	circuitBreakerThreshold = 3
+	// minCircuitBreakerBackoff is the duration the circuit breaker of a cluster stays open the first time it opens.
+	minCircuitBreakerBackoff = 5 * time.Second
+	// maxCircuitBreakerBackoff is the maximum duration the circuit breaker of a cluster stays open.
+	maxCircuitBreakerBackoff = 2 * time.Minute
+)
+
+// ErrCircuitBreakerOpen is returned instead of performing requests to a cluster whose circuit breaker is open.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker open: Elasticsearch requests failing fast after consecutive failures")
+
+// IsCircuitBreakerOpen checks whether the error was returned because the circuit breaker of the cluster is open.
+func IsCircuitBreakerOpen(err error) bool {
+	return errors.Is(err, ErrCircuitBreakerOpen)
+}
+
+// sharedCircuitBreakers holds the circuit breakers of all the clusters.
+var sharedCircuitBreakers = newCircuitBreakers()
+
+// CircuitBreakerOpen returns true if requests to the given cluster currently fail fast.
+func CircuitBreakerOpen(cluster types.NamespacedName) bool {
+	return sharedCircuitBreakers.get(cluster).isOpen()
+}
+
+// ResetCircuitBreaker removes the circuit breaker of the given cluster. It should be called once the cluster is deleted.
+func ResetCircuitBreaker(cluster types.NamespacedName) {
+	sharedCircuitBreakers.release(cluster)
+}
+
+type circuitBreakers struct {
+	mutex    sync.Mutex
+	breakers map[types.NamespacedName]*circuitBreaker
+}
+
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{breakers: make(map[types.NamespacedName]*circuitBreaker)}
+}
+
+func (b *circuitBreakers) get(cluster types.NamespacedName) *circuitBreaker {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	breaker, exists := b.breakers[cluster]
+	if !exists {
+		breaker = newCircuitBreaker()
+		b.breakers[cluster] = breaker
+	}
+	return breaker
+}
+
+func (b *circuitBreakers) release(cluster types.NamespacedName) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.breakers, cluster)
+}
+
+// circuitBreaker prevents requests to an unreachable cluster from each waiting for the client timeout.
+// It opens after circuitBreakerThreshold consecutive failures, then lets a single probe request through once its
+// backoff elapsed: the breaker closes if the probe succeeds, or stays open for twice as long otherwise.
+// A nil circuitBreaker lets all requests through.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{now: time.Now}
+}
+
+func (b *circuitBreaker) isOpen() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.failures >= circuitBreakerThreshold
+}
+
+// allow returns ErrCircuitBreakerOpen if the request must not be performed.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.failures < circuitBreakerThreshold {
+		return nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return ErrCircuitBreakerOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a request, given the HTTP status code of the response if any and
+// the error returned by the HTTP client.
+func (b *circuitBreaker) record(statusCode int, err error) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+	if !isUnavailable(statusCode, err) {
+		b.failures = 0
+		b.backoff = 0
+		return
+	}
+	b.failures++
+	if b.failures < circuitBreakerThreshold {
+		return
+	}
+	switch {
+	case b.backoff == 0:
+		b.backoff = minCircuitBreakerBackoff
+	case b.backoff < maxCircuitBreakerBackoff:
+		b.backoff *= 2
+		if b.backoff > maxCircuitBreakerBackoff {
+			b.backoff = maxCircuitBreakerBackoff
+		}
+	}
+	b.openUntil = b.now().Add(b.backoff)
+}
+
+// isUnavailable returns true if the outcome of a request indicates that the cluster cannot serve requests. Requests
+// cancelled by the caller and error responses from a reachable cluster do not count.
+func isUnavailable(statusCode int, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
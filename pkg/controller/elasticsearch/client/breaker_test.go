@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+)
+
+func Test_circuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker()
+	b.now = func() time.Time { return now }
+	unreachable := errors.New("connection refused")
+
+	// requests are performed until the threshold is reached
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		require.NoError(t, b.allow())
+		require.False(t, b.isOpen())
+		b.record(0, unreachable)
+	}
+	require.True(t, b.isOpen())
+	require.ErrorIs(t, b.allow(), ErrCircuitBreakerOpen)
+
+	// a single probe is allowed once the backoff elapsed
+	now = now.Add(minCircuitBreakerBackoff)
+	require.NoError(t, b.allow())
+	require.ErrorIs(t, b.allow(), ErrCircuitBreakerOpen)
+	// the backoff doubles if the probe fails
+	b.record(http.StatusServiceUnavailable, nil)
+	now = now.Add(minCircuitBreakerBackoff)
+	require.ErrorIs(t, b.allow(), ErrCircuitBreakerOpen)
+	now = now.Add(minCircuitBreakerBackoff)
+	require.NoError(t, b.allow())
+
+	// up to the maximum backoff
+	for i := 0; i < 10; i++ {
+		b.record(0, unreachable)
+	}
+	require.Equal(t, maxCircuitBreakerBackoff, b.backoff)
+
+	// the breaker closes once a probe succeeds
+	now = now.Add(maxCircuitBreakerBackoff)
+	require.NoError(t, b.allow())
+	b.record(http.StatusNotFound, nil)
+	require.False(t, b.isOpen())
+	require.NoError(t, b.allow())
+	require.NoError(t, b.allow())
+
+	// cancelled requests do not count as failures
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		b.record(0, context.Canceled)
+	}
+	require.False(t, b.isOpen())
+}
+
+func Test_baseClient_circuitBreaker(t *testing.T) {
+	requests := 0
+	c := NewMockClient(version.MustParse("7.16.0"), func(req *http.Request) *http.Response {
+		requests++
+		return NewMockResponse(http.StatusServiceUnavailable, req, "")
+	})
+	c.(*clientV7).breaker = newCircuitBreaker()
+
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_, err := c.GetClusterHealth(context.Background())
+		require.Error(t, err)
+		require.False(t, IsCircuitBreakerOpen(err))
+	}
+	_, err := c.GetClusterHealth(context.Background())
+	require.True(t, IsCircuitBreakerOpen(err))
+	require.Equal(t, circuitBreakerThreshold, requests)
+}
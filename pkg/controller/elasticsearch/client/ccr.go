@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+type CCRClient interface {
+	// GetFollowerIndices returns the names of the follower indices of the cluster.
+	GetFollowerIndices(ctx context.Context) ([]string, error)
+	// Follow creates a follower index replicating an index of a remote cluster.
+	Follow(ctx context.Context, followerIndex string, request FollowRequest) error
+	// GetAutoFollowPatterns returns the names of the auto-follow patterns of the cluster.
+	GetAutoFollowPatterns(ctx context.Context) ([]string, error)
+	// PutAutoFollowPattern creates or updates an auto-follow pattern.
+	PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error
+	// DeleteAutoFollowPattern deletes an auto-follow pattern.
+	DeleteAutoFollowPattern(ctx context.Context, name string) error
+}
+
+// FollowRequest models a request to the <index>/_ccr/follow API.
+type FollowRequest struct {
+	RemoteCluster string `json:"remote_cluster"`
+	LeaderIndex   string `json:"leader_index"`
+}
+
+// AutoFollowPattern models an auto-follow pattern as accepted by the _ccr/auto_follow API.
+type AutoFollowPattern struct {
+	RemoteCluster       string   `json:"remote_cluster"`
+	LeaderIndexPatterns []string `json:"leader_index_patterns"`
+	FollowIndexPattern  string   `json:"follow_index_pattern,omitempty"`
+}
+
+// FollowerIndicesResponse partially models the response of the _ccr/info API.
+type FollowerIndicesResponse struct {
+	FollowerIndices []struct {
+		FollowerIndex string `json:"follower_index"`
+	} `json:"follower_indices"`
+}
+
+// AutoFollowPatternsResponse partially models the response of the _ccr/auto_follow API.
+type AutoFollowPatternsResponse struct {
+	Patterns []struct {
+		Name string `json:"name"`
+	} `json:"patterns"`
+}
+
+func (c *baseClient) GetFollowerIndices(ctx context.Context) ([]string, error) {
+	var response FollowerIndicesResponse
+	if err := c.get(ctx, "/_all/_ccr/info", &response); err != nil {
+		return nil, err
+	}
+	indices := make([]string, 0, len(response.FollowerIndices))
+	for _, index := range response.FollowerIndices {
+		indices = append(indices, index.FollowerIndex)
+	}
+	return indices, nil
+}
+
+func (c *baseClient) Follow(ctx context.Context, followerIndex string, request FollowRequest) error {
+	return c.put(ctx, fmt.Sprintf("/%s/_ccr/follow", followerIndex), request, nil)
+}
+
+func (c *baseClient) GetAutoFollowPatterns(ctx context.Context) ([]string, error) {
+	var response AutoFollowPatternsResponse
+	if err := c.get(ctx, "/_ccr/auto_follow", &response); err != nil {
+		if IsNotFound(err) {
+			// no auto-follow pattern
+			return nil, nil
+		}
+		return nil, err
+	}
+	patterns := make([]string, 0, len(response.Patterns))
+	for _, pattern := range response.Patterns {
+		patterns = append(patterns, pattern.Name)
+	}
+	return patterns, nil
+}
+
+func (c *baseClient) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	return c.put(ctx, fmt.Sprintf("/_ccr/auto_follow/%s", name), pattern, nil)
+}
+
+func (c *baseClient) DeleteAutoFollowPattern(ctx context.Context, name string) error {
+	return c.delete(ctx, fmt.Sprintf("/_ccr/auto_follow/%s", name))
+}
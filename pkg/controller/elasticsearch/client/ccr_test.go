@@ -0,0 +1,109 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func TestClient_GetFollowerIndices(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_all/_ccr/info", req.URL.Path)
+		require.Equal(t, http.MethodGet, req.Method)
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(strings.NewReader(`{"follower_indices":[
+				{"follower_index":"logs","remote_cluster":"leader","leader_index":"logs","status":"active"},
+				{"follower_index":"metrics-copy","remote_cluster":"leader","leader_index":"metrics","status":"paused"}]}`)),
+			Header:  make(http.Header),
+			Request: req,
+		}
+	})
+	indices, err := testClient.GetFollowerIndices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs", "metrics-copy"}, indices)
+}
+
+func TestClient_Follow(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/logs/_ccr/follow", req.URL.Path)
+		require.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"remote_cluster":"leader","leader_index":"logs"}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"follow_index_created":true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	assert.NoError(t, testClient.Follow(context.Background(), "logs", FollowRequest{RemoteCluster: "leader", LeaderIndex: "logs"}))
+}
+
+func TestClient_GetAutoFollowPatterns(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       []string
+	}{
+		{
+			name:       "patterns",
+			statusCode: 200,
+			body:       `{"patterns":[{"name":"logs","pattern":{"remote_cluster":"leader","leader_index_patterns":["logs-*"]}}]}`,
+			want:       []string{"logs"},
+		},
+		{
+			name:       "no pattern",
+			statusCode: 404,
+			body:       `{"error":{"type":"resource_not_found_exception"},"status":404}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+				require.Equal(t, "/_ccr/auto_follow", req.URL.Path)
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+					Header:     make(http.Header),
+					Request:    req,
+				}
+			})
+			patterns, err := testClient.GetAutoFollowPatterns(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, patterns)
+		})
+	}
+}
+
+func TestClient_PutAutoFollowPattern(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_ccr/auto_follow/logs", req.URL.Path)
+		require.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"remote_cluster":"leader","leader_index_patterns":["logs-*"],"follow_index_pattern":"{{leader_index}}-copy"}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"acknowledged":true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	pattern := AutoFollowPattern{RemoteCluster: "leader", LeaderIndexPatterns: []string{"logs-*"}, FollowIndexPattern: "{{leader_index}}-copy"}
+	assert.NoError(t, testClient.PutAutoFollowPattern(context.Background(), "logs", pattern))
+}
@@ -15,7 +15,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
@@ -57,8 +56,15 @@ type Role struct {
 type Client interface {
 	AllocationSetter
 	AutoscalingClient
+	DeprecationClient
 	ShardLister
+	AllocationExplainer
 	LicenseClient
+	SnapshotClient
+	ILMClient
+	CCRClient
+	CrossClusterAPIKeyClient
+	SecurityClient
 	// Close idle connections in the underlying http client.
 	Close()
 	// Equal returns true if other can be considered as the same client.
@@ -98,9 +104,16 @@ type Client interface {
 	UpdateRemoteClusterSettings(ctx context.Context, settings RemoteClustersSettings) error
 	// GetRemoteClusterSettings retrieves the remote clusters of a cluster.
 	GetRemoteClusterSettings(ctx context.Context) (RemoteClustersSettings, error)
+	// GetPersistentClusterSettings returns the persistent cluster settings, indexed by flattened key.
+	GetPersistentClusterSettings(ctx context.Context) (map[string]interface{}, error)
+	// UpdatePersistentClusterSettings sets the given persistent cluster settings, leaving the others untouched.
+	UpdatePersistentClusterSettings(ctx context.Context, settings map[string]interface{}) error
 	// AddVotingConfigExclusions sets the transient and persistent setting of the same name in cluster settings.
 	// Introduced in: Elasticsearch 7.0.0
 	AddVotingConfigExclusions(ctx context.Context, nodeNames []string) error
+	// GetVotingConfigExclusions returns the nodes currently excluded from the voting configuration.
+	// Introduced in: Elasticsearch 7.0.0
+	GetVotingConfigExclusions(ctx context.Context) ([]VotingConfigExclusion, error)
 	// DeleteVotingConfigExclusions sets the transient and persistent setting of the same name in cluster settings.
 	//
 	// Introduced in: Elasticsearch 7.0.0
@@ -133,8 +146,12 @@ func formatAsSeconds(d time.Duration) string {
 }
 
 // NewElasticsearchClient creates a new client for the target cluster.
+// The underlying HTTP client is shared with other clients of the same cluster and user, see ReleaseHTTPClients.
+// Requests fail fast with ErrCircuitBreakerOpen while the cluster is unreachable, see CircuitBreakerOpen.
 //
-// If dialer is not nil, it will be used to create new TCP connections
+// If dialer is not nil, it will be used to create new TCP connections.
+// If dryRun is true, the requests changing the state of the cluster are logged instead of being sent, and a DryRunError
+// is returned.
 func NewElasticsearchClient(
 	dialer net.Dialer,
 	es types.NamespacedName,
@@ -143,13 +160,17 @@ func NewElasticsearchClient(
 	v version.Version,
 	caCerts []*x509.Certificate,
 	timeout time.Duration,
+	dryRun bool,
 ) Client {
 	base := &baseClient{
 		Endpoint: esURL,
 		User:     esUser,
 		caCerts:  caCerts,
-		HTTP:     common.HTTPClient(dialer, caCerts, timeout),
+		HTTP:     sharedHTTPClients.get(dialer, es, esURL, esUser, caCerts, timeout),
 		es:       es,
+		pooled:   true,
+		breaker:  sharedCircuitBreakers.get(es),
+		dryRun:   dryRun,
 	}
 	return versioned(base, v)
 }
@@ -183,6 +183,24 @@ func TestClientUsesJsonContentType(t *testing.T) {
 	assert.NoError(t, testClient.SetMinimumMasterNodes(context.Background(), 0))
 }
 
+func TestClientDryRun(t *testing.T) {
+	var methods []string
+	testClient := versioned(&baseClient{
+		HTTP: &http.Client{Transport: requestAssertion(func(req *http.Request) {
+			methods = append(methods, req.Method)
+		})},
+		Endpoint: "http://example.com",
+		dryRun:   true,
+	}, version.MustParse("7.15.0"))
+
+	_, err := testClient.GetClusterInfo(context.Background())
+	require.NoError(t, err)
+	err = testClient.ExcludeFromShardAllocation(context.Background(), "node-1")
+	require.True(t, IsDryRun(err))
+	// only the read request is sent
+	require.Equal(t, []string{http.MethodGet}, methods)
+}
+
 func TestClientSupportsBasicAuth(t *testing.T) {
 	type expected struct {
 		user        BasicAuth
@@ -374,62 +392,62 @@ func TestClient_Equal(t *testing.T) {
 	}{
 		{
 			name: "c1 and c2 equals",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: true,
 		},
 		{
 			name: "c2 nil",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			c2:   nil,
 			want: false,
 		},
 		{
 			name: "different endpoint",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, "another-endpoint", dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, "another-endpoint", dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 		{
 			name: "different user",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, BasicAuth{Name: "user", Password: "another-password"}, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, BasicAuth{Name: "user", Password: "another-password"}, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 		{
 			name: "different CA cert",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, []*x509.Certificate{createCert()}, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, []*x509.Certificate{createCert()}, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 		{
 			name: "different CA certs length",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, []*x509.Certificate{createCert(), createCert()}, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, []*x509.Certificate{createCert(), createCert()}, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 		{
 			name: "different dialers are not taken into consideration",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(portforward.NewForwardingDialer(), dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(portforward.NewForwardingDialer(), dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: true,
 		},
 		{
 			name: "different versions",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v6, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 		{
 			name: "same versions",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: true,
 		},
 		{
 			name: "one has a version",
-			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{})),
-			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, version.Version{}, dummyCACerts, Timeout(esv1.Elasticsearch{})),
+			c1:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, v7, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
+			c2:   NewElasticsearchClient(nil, dummyNamespaceName, dummyEndpoint, dummyUser, version.Version{}, dummyCACerts, Timeout(esv1.Elasticsearch{}), false),
 			want: false,
 		},
 	}
@@ -487,6 +505,106 @@ func TestClient_AddVotingConfigExclusions(t *testing.T) {
 	}
 }
 
+func TestClient_GetVotingConfigExclusions(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  version.Version
+		body     string
+		expected []VotingConfigExclusion
+		wantErr  bool
+	}{
+		{
+			name:    "not supported in 6.x",
+			version: version.MustParse("6.8.0"),
+			wantErr: true,
+		},
+		{
+			name:    "no exclusions",
+			version: version.MustParse("7.15.0"),
+			body:    `{}`,
+		},
+		{
+			name:     "exclusions",
+			version:  version.MustParse("7.15.0"),
+			body:     `{"metadata":{"cluster_coordination":{"voting_config_exclusions":[{"node_id":"abc","node_name":"es-master-2"}]}}}`,
+			expected: []VotingConfigExclusion{{NodeID: "abc", NodeName: "es-master-2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+				require.Equal(t, "/_cluster/state/metadata", req.URL.Path)
+				require.Equal(t, "metadata.cluster_coordination.voting_config_exclusions", req.URL.Query().Get("filter_path"))
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+				}
+			})
+			exclusions, err := client.GetVotingConfigExclusions(context.Background())
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.expected, exclusions)
+		})
+	}
+}
+
+func TestClient_ExplainAllocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   *AllocationExplanation
+		wantErr    bool
+	}{
+		{
+			name:       "no unassigned shard",
+			statusCode: 400,
+			body:       `{"error":{"type":"illegal_argument_exception","reason":"unable to find any unassigned shards to explain"},"status":400}`,
+		},
+		{
+			name:       "unassigned shard",
+			statusCode: 200,
+			body: `{"index":"my-index","shard":0,"primary":false,"current_state":"unassigned",
+				"unassigned_info":{"reason":"NODE_LEFT"},"can_allocate":"no",
+				"allocate_explanation":"cannot allocate because allocation is not permitted to any of the nodes",
+				"node_allocation_decisions":[{"node_name":"es-0","node_decision":"no",
+				"deciders":[{"decider":"same_shard","decision":"NO","explanation":"a copy of this shard is already allocated to this node"}]}]}`,
+			expected: &AllocationExplanation{
+				Index:               "my-index",
+				CurrentState:        "unassigned",
+				UnassignedInfo:      UnassignedInfo{Reason: "NODE_LEFT"},
+				AllocateExplanation: "cannot allocate because allocation is not permitted to any of the nodes",
+				NodeAllocationDecisions: []NodeAllocationDecision{{
+					NodeName:     "es-0",
+					NodeDecision: "no",
+					Deciders:     []AllocationDecider{{Decider: "same_shard", Decision: "NO", Explanation: "a copy of this shard is already allocated to this node"}},
+				}},
+			},
+		},
+		{
+			name:       "error",
+			statusCode: 500,
+			body:       `{}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+				require.Equal(t, "/_cluster/allocation/explain", req.URL.Path)
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+				}
+			})
+			explanation, err := client.ExplainAllocation(context.Background())
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.expected, explanation)
+		})
+	}
+}
+
 func TestClient_DeleteVotingConfigExclusions(t *testing.T) {
 	tests := []struct {
 		expectedPath string
@@ -556,6 +674,39 @@ func TestClient_SetMinimumMasterNodes(t *testing.T) {
 	}
 }
 
+func TestClient_UpdatePersistentClusterSettings(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.17.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/_cluster/settings", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"persistent":{"indices.recovery.max_bytes_per_sec":"100mb"}}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+	})
+	err := client.UpdatePersistentClusterSettings(context.Background(), map[string]interface{}{"indices.recovery.max_bytes_per_sec": "100mb"})
+	require.NoError(t, err)
+}
+
+func TestClient_GetPersistentClusterSettings(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.17.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/_cluster/settings", req.URL.Path)
+		require.Equal(t, "true", req.URL.Query().Get("flat_settings"))
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(strings.NewReader(
+				`{"persistent":{"indices.recovery.max_bytes_per_sec":"100mb"},"transient":{"cluster.routing.allocation.enable":"all"}}`,
+			)),
+		}
+	})
+	settings, err := client.GetPersistentClusterSettings(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"indices.recovery.max_bytes_per_sec": "100mb"}, settings)
+}
+
 func TestAPIError_Types(t *testing.T) {
 	type args struct {
 		err error
@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type DeprecationClient interface {
+	// GetDeprecations returns the deprecated settings and features in use in the cluster, which may prevent an
+	// upgrade to the next major version.
+	GetDeprecations(ctx context.Context) (Deprecations, error)
+}
+
+// DeprecationLevel is the severity of a deprecation issue.
+type DeprecationLevel string
+
+// CriticalDeprecationLevel is the level of the deprecation issues which must be resolved before upgrading.
+const CriticalDeprecationLevel DeprecationLevel = "critical"
+
+// Deprecation is a deprecation issue as returned by the deprecation info API.
+type Deprecation struct {
+	Level   DeprecationLevel `json:"level"`
+	Message string           `json:"message"`
+	URL     string           `json:"url"`
+	Details string           `json:"details,omitempty"`
+}
+
+// Deprecations is the response of the deprecation info API.
+type Deprecations struct {
+	ClusterSettings []Deprecation            `json:"cluster_settings"`
+	NodeSettings    []Deprecation            `json:"node_settings"`
+	IndexSettings   map[string][]Deprecation `json:"index_settings"`
+	MLSettings      []Deprecation            `json:"ml_settings"`
+}
+
+// CriticalMessages returns the messages of the critical deprecation issues, prefixed by the index name for index
+// settings, in a stable order.
+func (d Deprecations) CriticalMessages() []string {
+	var messages []string
+	for _, group := range [][]Deprecation{d.ClusterSettings, d.NodeSettings, d.MLSettings} {
+		for _, deprecation := range group {
+			if deprecation.Level == CriticalDeprecationLevel {
+				messages = append(messages, deprecation.Message)
+			}
+		}
+	}
+	indices := make([]string, 0, len(d.IndexSettings))
+	for index := range d.IndexSettings {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	for _, index := range indices {
+		for _, deprecation := range d.IndexSettings[index] {
+			if deprecation.Level == CriticalDeprecationLevel {
+				messages = append(messages, fmt.Sprintf("%s: %s", index, deprecation.Message))
+			}
+		}
+	}
+	return messages
+}
+
+func (c *clientV6) GetDeprecations(ctx context.Context) (Deprecations, error) {
+	var deprecations Deprecations
+	err := c.get(ctx, "/_xpack/migration/deprecations", &deprecations)
+	return deprecations, err
+}
+
+func (c *clientV7) GetDeprecations(ctx context.Context) (Deprecations, error) {
+	var deprecations Deprecations
+	err := c.get(ctx, "/_migration/deprecations", &deprecations)
+	return deprecations, err
+}
@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+const deprecationsResponse = `{
+  "cluster_settings": [
+    {"level": "critical", "message": "Cluster name cannot contain ':'", "url": "https://example.com/1"}
+  ],
+  "node_settings": [
+    {"level": "warning", "message": "Node setting deprecated", "url": "https://example.com/2"}
+  ],
+  "index_settings": {
+    "logs": [{"level": "critical", "message": "Index created before 7.0", "url": "https://example.com/3"}],
+    "metrics": [{"level": "warning", "message": "Deprecated mapping", "url": "https://example.com/4"}]
+  },
+  "ml_settings": []
+}`
+
+func TestClient_GetDeprecations(t *testing.T) {
+	tests := []struct {
+		version      version.Version
+		expectedPath string
+	}{
+		{
+			version:      version.MustParse("6.8.0"),
+			expectedPath: "/_xpack/migration/deprecations",
+		},
+		{
+			version:      version.MustParse("7.17.0"),
+			expectedPath: "/_migration/deprecations",
+		},
+	}
+	for _, tt := range tests {
+		testClient := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, tt.expectedPath, req.URL.Path)
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(deprecationsResponse)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		})
+		deprecations, err := testClient.GetDeprecations(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"Cluster name cannot contain ':'", "logs: Index created before 7.0"}, deprecations.CriticalMessages())
+	}
+}
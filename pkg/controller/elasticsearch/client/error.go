@@ -80,6 +80,23 @@ func IsConflict(err error) bool {
 	return isHTTPError(err, http.StatusConflict)
 }
 
+// DryRunError is returned instead of sending the requests changing the state of the Elasticsearch cluster in dry-run
+// mode. Callers should skip the steps depending on the outcome of the request.
+type DryRunError struct {
+	Method string
+	Path   string
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry run: %s %s not sent", e.Method, e.Path)
+}
+
+// IsDryRun checks whether the error was returned instead of sending a request in dry-run mode.
+func IsDryRun(err error) bool {
+	dryRunErr := new(DryRunError)
+	return errors.As(err, &dryRunErr)
+}
+
 func Is4xx(err error) bool {
 	apiErr := new(APIError)
 	if errors.As(err, &apiErr) {
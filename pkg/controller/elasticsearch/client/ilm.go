@@ -0,0 +1,33 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+type ILMClient interface {
+	// PutLifecyclePolicy creates or updates an index lifecycle management policy.
+	PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error
+}
+
+// LifecyclePolicy models an index lifecycle management policy as accepted by the _ilm/policy API.
+type LifecyclePolicy struct {
+	Phases map[string]LifecyclePhase `json:"phases"`
+}
+
+// LifecyclePhase models a phase of an index lifecycle management policy.
+type LifecyclePhase struct {
+	MinAge  string                 `json:"min_age,omitempty"`
+	Actions map[string]interface{} `json:"actions"`
+}
+
+func (c *baseClient) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	request := struct {
+		Policy LifecyclePolicy `json:"policy"`
+	}{Policy: policy}
+	return c.put(ctx, fmt.Sprintf("/_ilm/policy/%s", name), request, nil)
+}
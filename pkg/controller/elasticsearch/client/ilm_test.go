@@ -0,0 +1,42 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func TestClient_PutLifecyclePolicy(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_ilm/policy/tiers", req.URL.Path)
+		require.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"policy":{"phases":{
+			"hot":{"actions":{"rollover":{"max_age":"30d"}}},
+			"warm":{"min_age":"7d","actions":{"set_priority":{"priority":50}}}}}}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"acknowledged": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	policy := LifecyclePolicy{Phases: map[string]LifecyclePhase{
+		"hot":  {Actions: map[string]interface{}{"rollover": map[string]interface{}{"max_age": "30d"}}},
+		"warm": {MinAge: "7d", Actions: map[string]interface{}{"set_priority": map[string]interface{}{"priority": 50}}},
+	}}
+	assert.NoError(t, testClient.PutLifecyclePolicy(context.Background(), "tiers", policy))
+}
@@ -245,6 +245,95 @@ type DiscoveryZenSettings struct {
 	Persistent DiscoveryZen `json:"persistent"`
 }
 
+// VotingConfigExclusionsResponse partially models the response from a request to /_cluster/state/metadata
+// filtered on the voting config exclusions.
+type VotingConfigExclusionsResponse struct {
+	Metadata struct {
+		ClusterCoordination struct {
+			VotingConfigExclusions []VotingConfigExclusion `json:"voting_config_exclusions"`
+		} `json:"cluster_coordination"`
+	} `json:"metadata"`
+}
+
+// VotingConfigExclusion is a node excluded from the voting configuration.
+type VotingConfigExclusion struct {
+	NodeID   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+}
+
+// AllocationExplanation partially models the response from a request to /_cluster/allocation/explain, explaining why
+// a shard is unassigned.
+type AllocationExplanation struct {
+	Index                   string                   `json:"index"`
+	Shard                   int                      `json:"shard"`
+	Primary                 bool                     `json:"primary"`
+	CurrentState            string                   `json:"current_state"`
+	UnassignedInfo          UnassignedInfo           `json:"unassigned_info"`
+	AllocateExplanation     string                   `json:"allocate_explanation"`
+	NodeAllocationDecisions []NodeAllocationDecision `json:"node_allocation_decisions"`
+}
+
+// UnassignedInfo explains why a shard became unassigned.
+type UnassignedInfo struct {
+	Reason string `json:"reason"`
+}
+
+// NodeAllocationDecision is the decision to allocate a shard to a node.
+type NodeAllocationDecision struct {
+	NodeName     string              `json:"node_name"`
+	NodeDecision string              `json:"node_decision"`
+	Deciders     []AllocationDecider `json:"deciders"`
+}
+
+// AllocationDecider is the decision of an allocation decider to allocate a shard to a node.
+type AllocationDecider struct {
+	Decider     string `json:"decider"`
+	Decision    string `json:"decision"`
+	Explanation string `json:"explanation"`
+}
+
+// Summary returns a short human-readable explanation of why the shard is unassigned, along with the decider which
+// prevents its allocation to most nodes, if any.
+func (e AllocationExplanation) Summary() string {
+	kind := "Replica"
+	if e.Primary {
+		kind = "Primary"
+	}
+	summary := fmt.Sprintf("%s shard %d of index %s is %s", kind, e.Shard, e.Index, e.CurrentState)
+	if e.UnassignedInfo.Reason != "" {
+		summary += fmt.Sprintf(" (%s)", e.UnassignedInfo.Reason)
+	}
+	if e.AllocateExplanation != "" {
+		summary += ": " + e.AllocateExplanation
+	}
+
+	// find the decider which rejects the allocation to the most nodes, in a stable way
+	rejections := make(map[string]int)
+	explanations := make(map[string]string)
+	for _, node := range e.NodeAllocationDecisions {
+		for _, decider := range node.Deciders {
+			if decider.Decision != "NO" {
+				continue
+			}
+			rejections[decider.Decider]++
+			if _, exists := explanations[decider.Decider]; !exists {
+				explanations[decider.Decider] = decider.Explanation
+			}
+		}
+	}
+	var topDecider string
+	for decider, count := range rejections {
+		if count > rejections[topDecider] || (count == rejections[topDecider] && decider < topDecider) {
+			topDecider = decider
+		}
+	}
+	if topDecider != "" {
+		summary += fmt.Sprintf(". Decider %s rejects %d of %d nodes: %s",
+			topDecider, rejections[topDecider], len(e.NodeAllocationDecisions), explanations[topDecider])
+	}
+	return summary
+}
+
 // ErrorResponse is an Elasticsearch error response.
 type ErrorResponse struct {
 	Status int `json:"status"`
@@ -161,3 +161,43 @@ func TestLicenseUpdateResponse_IsSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestAllocationExplanation_Summary(t *testing.T) {
+	noSameShard := AllocationDecider{Decider: "same_shard", Decision: "NO", Explanation: "a copy of this shard is already allocated to this node"}
+	noDiskThreshold := AllocationDecider{Decider: "disk_threshold", Decision: "NO", Explanation: "the node is above the high watermark"}
+	tests := []struct {
+		name        string
+		explanation AllocationExplanation
+		want        string
+	}{
+		{
+			name: "no node decision",
+			explanation: AllocationExplanation{
+				Index: "my-index", Shard: 1, Primary: true, CurrentState: "unassigned",
+				AllocateExplanation: "cannot allocate because a previous copy of the primary shard existed but can no longer be found",
+			},
+			want: "Primary shard 1 of index my-index is unassigned: cannot allocate because a previous copy of the primary shard existed but can no longer be found",
+		},
+		{
+			name: "most frequent rejection",
+			explanation: AllocationExplanation{
+				Index: "my-index", CurrentState: "unassigned",
+				UnassignedInfo:      UnassignedInfo{Reason: "NODE_LEFT"},
+				AllocateExplanation: "cannot allocate because allocation is not permitted to any of the nodes",
+				NodeAllocationDecisions: []NodeAllocationDecision{
+					{NodeName: "es-0", Deciders: []AllocationDecider{noSameShard}},
+					{NodeName: "es-1", Deciders: []AllocationDecider{noDiskThreshold}},
+					{NodeName: "es-2", Deciders: []AllocationDecider{noDiskThreshold, {Decider: "awareness", Decision: "YES"}}},
+				},
+			},
+			want: "Replica shard 0 of index my-index is unassigned (NODE_LEFT): " +
+				"cannot allocate because allocation is not permitted to any of the nodes. " +
+				"Decider disk_threshold rejects 2 of 3 nodes: the node is above the high watermark",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.explanation.Summary())
+		})
+	}
+}
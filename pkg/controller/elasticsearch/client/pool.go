@@ -0,0 +1,126 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"crypto/x509"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	poolHit     = "hit"
+	poolMiss    = "miss"
+	poolRebuild = "rebuild"
+)
+
+// sharedHTTPClients is the pool of HTTP clients used by all Elasticsearch clients.
+var sharedHTTPClients = newHTTPClientPool()
+
+// ReleaseHTTPClients closes and removes from the pool the HTTP clients of the given cluster.
+// It should be called once the cluster is deleted.
+func ReleaseHTTPClients(cluster types.NamespacedName) {
+	sharedHTTPClients.release(cluster)
+}
+
+type httpClientKey struct {
+	cluster types.NamespacedName
+	user    string
+}
+
+// pooledHTTPClient is an HTTP client along with the parameters it was built for.
+type pooledHTTPClient struct {
+	client   *http.Client
+	dialer   net.Dialer
+	endpoint string
+	password string
+	caCerts  []*x509.Certificate
+	timeout  time.Duration
+}
+
+func (p pooledHTTPClient) matches(dialer net.Dialer, endpoint string, password string, caCerts []*x509.Certificate, timeout time.Duration) bool {
+	if p.dialer != dialer || p.endpoint != endpoint || p.password != password || p.timeout != timeout {
+		return false
+	}
+	if len(p.caCerts) != len(caCerts) {
+		return false
+	}
+	for i := range caCerts {
+		if !p.caCerts[i].Equal(caCerts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// httpClientPool keeps one HTTP client per cluster and user, so that connections to Elasticsearch are kept alive and
+// reused across reconciliations instead of paying the TLS handshake cost every time. A client is rebuilt when the
+// endpoint, CA certificates, credentials, timeout or dialer of the cluster change.
+type httpClientPool struct {
+	mutex   sync.Mutex
+	clients map[httpClientKey]pooledHTTPClient
+}
+
+func newHTTPClientPool() *httpClientPool {
+	return &httpClientPool{clients: make(map[httpClientKey]pooledHTTPClient)}
+}
+
+func (p *httpClientPool) get(
+	dialer net.Dialer,
+	cluster types.NamespacedName,
+	endpoint string,
+	user BasicAuth,
+	caCerts []*x509.Certificate,
+	timeout time.Duration,
+) *http.Client {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := httpClientKey{cluster: cluster, user: user.Name}
+	pooled, exists := p.clients[key]
+	switch {
+	case exists && pooled.matches(dialer, endpoint, user.Password, caCerts, timeout):
+		metrics.ESClientPoolRequests.WithLabelValues(poolHit).Inc()
+		return pooled.client
+	case exists:
+		// connections established with outdated parameters must not be reused
+		pooled.client.CloseIdleConnections()
+		metrics.ESClientPoolRequests.WithLabelValues(poolRebuild).Inc()
+	default:
+		metrics.ESClientPoolRequests.WithLabelValues(poolMiss).Inc()
+	}
+
+	pooled = pooledHTTPClient{
+		client:   common.HTTPClient(dialer, caCerts, timeout),
+		dialer:   dialer,
+		endpoint: endpoint,
+		password: user.Password,
+		caCerts:  caCerts,
+		timeout:  timeout,
+	}
+	p.clients[key] = pooled
+	metrics.ESClientPoolSize.WithLabelValues().Set(float64(len(p.clients)))
+	return pooled.client
+}
+
+func (p *httpClientPool) release(cluster types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for key, pooled := range p.clients {
+		if key.cluster == cluster {
+			pooled.client.CloseIdleConnections()
+			delete(p.clients, key)
+		}
+	}
+	metrics.ESClientPoolSize.WithLabelValues().Set(float64(len(p.clients)))
+}
@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/dev/portforward"
+)
+
+func newTestCACert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	ca, err := certificates.NewSelfSignedCA(certificates.CABuilderOptions{})
+	require.NoError(t, err)
+	return ca.Cert
+}
+
+func Test_httpClientPool(t *testing.T) {
+	pool := newHTTPClientPool()
+	cluster := types.NamespacedName{Namespace: "ns", Name: "es"}
+	user := BasicAuth{Name: "user", Password: "password"}
+	caCerts := []*x509.Certificate{newTestCACert(t)}
+	timeout := time.Minute
+
+	client := pool.get(nil, cluster, "https://es:9200", user, caCerts, timeout)
+	// the same client is returned as long as parameters do not change
+	require.Same(t, client, pool.get(nil, cluster, "https://es:9200", user, caCerts, timeout))
+	// each user gets its own client
+	otherUser := pool.get(nil, cluster, "https://es:9200", BasicAuth{Name: "other", Password: "password"}, caCerts, timeout)
+	require.NotSame(t, client, otherUser)
+	// each cluster gets its own client
+	otherCluster := pool.get(nil, types.NamespacedName{Namespace: "ns", Name: "other"}, "https://es:9200", user, caCerts, timeout)
+	require.NotSame(t, client, otherCluster)
+	require.Len(t, pool.clients, 3)
+
+	// the client is rebuilt when parameters change
+	for name, get := range map[string]func() interface{}{
+		"endpoint": func() interface{} {
+			return pool.get(nil, cluster, "https://other:9200", user, caCerts, timeout)
+		},
+		"password": func() interface{} {
+			return pool.get(nil, cluster, "https://other:9200", BasicAuth{Name: "user", Password: "changed"}, caCerts, timeout)
+		},
+		"CA certificates": func() interface{} {
+			return pool.get(nil, cluster, "https://other:9200", BasicAuth{Name: "user", Password: "changed"}, []*x509.Certificate{newTestCACert(t)}, timeout)
+		},
+		"dialer": func() interface{} {
+			return pool.get(portforward.NewForwardingDialer(), cluster, "https://other:9200", BasicAuth{Name: "user", Password: "changed"}, caCerts, timeout)
+		},
+	} {
+		rebuilt := get()
+		require.NotSame(t, client, rebuilt, name)
+		require.Len(t, pool.clients, 3, name)
+	}
+
+	// releasing a cluster removes all its clients
+	pool.release(cluster)
+	require.Len(t, pool.clients, 1)
+	require.NotSame(t, client, pool.get(nil, cluster, "https://es:9200", user, caCerts, timeout))
+}
+
+func TestNewElasticsearchClient_Pooled(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "ns", Name: "pooled"}
+	defer ReleaseHTTPClients(cluster)
+	user := BasicAuth{Name: "user", Password: "password"}
+	caCerts := []*x509.Certificate{newTestCACert(t)}
+	c1 := NewElasticsearchClient(nil, cluster, "https://es:9200", user, version.MustParse("7.15.2"), caCerts, time.Minute, false)
+	c2 := NewElasticsearchClient(nil, cluster, "https://es:9200", user, version.MustParse("7.15.2"), caCerts, time.Minute, false)
+	c1.Close()
+	require.Same(t, c1.(*clientV7).HTTP, c2.(*clientV7).HTTP)
+}
@@ -0,0 +1,88 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type SecurityClient interface {
+	// Authenticate returns an error if Elasticsearch does not accept the credentials of the client.
+	Authenticate(ctx context.Context) error
+	// GetUsers returns the users of the native realm and the built-in users, indexed by username.
+	GetUsers(ctx context.Context) (map[string]SecurityObject, error)
+	// PutUser creates or updates a user of the native realm.
+	PutUser(ctx context.Context, username string, user SecurityObject) error
+	// DeleteUser deletes a user of the native realm.
+	DeleteUser(ctx context.Context, username string) error
+	// GetRoles returns the roles of the native realm and the built-in roles, indexed by name.
+	GetRoles(ctx context.Context) (map[string]SecurityObject, error)
+	// PutRole creates or updates a role of the native realm.
+	PutRole(ctx context.Context, name string, role SecurityObject) error
+	// DeleteRole deletes a role of the native realm.
+	DeleteRole(ctx context.Context, name string) error
+	// GetRoleMappings returns the role mappings, indexed by name.
+	GetRoleMappings(ctx context.Context) (map[string]SecurityObject, error)
+	// PutRoleMapping creates or updates a role mapping.
+	PutRoleMapping(ctx context.Context, name string, mapping SecurityObject) error
+	// DeleteRoleMapping deletes a role mapping.
+	DeleteRoleMapping(ctx context.Context, name string) error
+}
+
+// SecurityObject is a user, a role or a role mapping, as represented by the Elasticsearch security APIs.
+type SecurityObject map[string]interface{}
+
+func (c *baseClient) getSecurityObjects(ctx context.Context, path string) (map[string]SecurityObject, error) {
+	objects := map[string]SecurityObject{}
+	// some versions of Elasticsearch return a 404 instead of an empty response if there is no object
+	err := c.request(ctx, http.MethodGet, path, nil, &objects, IsNotFound)
+	if IsNotFound(err) {
+		return map[string]SecurityObject{}, nil
+	}
+	return objects, err
+}
+
+func (c *baseClient) Authenticate(ctx context.Context) error {
+	return c.get(ctx, "/_security/_authenticate", nil)
+}
+
+func (c *baseClient) GetUsers(ctx context.Context) (map[string]SecurityObject, error) {
+	return c.getSecurityObjects(ctx, "/_security/user")
+}
+
+func (c *baseClient) PutUser(ctx context.Context, username string, user SecurityObject) error {
+	return c.put(ctx, fmt.Sprintf("/_security/user/%s", url.PathEscape(username)), user, nil)
+}
+
+func (c *baseClient) DeleteUser(ctx context.Context, username string) error {
+	return c.delete(ctx, fmt.Sprintf("/_security/user/%s", url.PathEscape(username)))
+}
+
+func (c *baseClient) GetRoles(ctx context.Context) (map[string]SecurityObject, error) {
+	return c.getSecurityObjects(ctx, "/_security/role")
+}
+
+func (c *baseClient) PutRole(ctx context.Context, name string, role SecurityObject) error {
+	return c.put(ctx, fmt.Sprintf("/_security/role/%s", url.PathEscape(name)), role, nil)
+}
+
+func (c *baseClient) DeleteRole(ctx context.Context, name string) error {
+	return c.delete(ctx, fmt.Sprintf("/_security/role/%s", url.PathEscape(name)))
+}
+
+func (c *baseClient) GetRoleMappings(ctx context.Context) (map[string]SecurityObject, error) {
+	return c.getSecurityObjects(ctx, "/_security/role_mapping")
+}
+
+func (c *baseClient) PutRoleMapping(ctx context.Context, name string, mapping SecurityObject) error {
+	return c.put(ctx, fmt.Sprintf("/_security/role_mapping/%s", url.PathEscape(name)), mapping, nil)
+}
+
+func (c *baseClient) DeleteRoleMapping(ctx context.Context, name string) error {
+	return c.delete(ctx, fmt.Sprintf("/_security/role_mapping/%s", url.PathEscape(name)))
+}
@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func TestClient_Authenticate(t *testing.T) {
+	for _, statusCode := range []int{200, 401} {
+		testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+			require.Equal(t, "/_security/_authenticate", req.URL.Path)
+			require.Equal(t, http.MethodGet, req.Method)
+			return &http.Response{
+				StatusCode: statusCode,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"username":"elastic-internal"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		})
+		err := testClient.Authenticate(context.Background())
+		require.Equal(t, statusCode != 200, err != nil, statusCode)
+	}
+}
+
+func TestClient_PutUser(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/user/my-user", req.URL.Path)
+		require.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"password":"changeme","roles":["viewer"]}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"created": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	user := SecurityObject{"password": "changeme", "roles": []string{"viewer"}}
+	require.NoError(t, testClient.PutUser(context.Background(), "my-user", user))
+}
+
+func TestClient_GetRoleMappings(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       map[string]SecurityObject
+	}{
+		{
+			name:       "role mappings",
+			statusCode: 200,
+			body:       `{"my-mapping":{"enabled":true,"roles":["viewer"]}}`,
+			want:       map[string]SecurityObject{"my-mapping": {"enabled": true, "roles": []interface{}{"viewer"}}},
+		},
+		{
+			name:       "no role mapping",
+			statusCode: 404,
+			body:       `{}`,
+			want:       map[string]SecurityObject{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+				require.Equal(t, "/_security/role_mapping", req.URL.Path)
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+					Header:     make(http.Header),
+					Request:    req,
+				}
+			})
+			got, err := testClient.GetRoleMappings(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
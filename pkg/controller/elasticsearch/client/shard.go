@@ -6,6 +6,7 @@ package client
 
 import (
 	"context"
+	"net/http"
 )
 
 // AllocationSetter captures Elasticsearch API calls around allocation filtering.
@@ -15,6 +16,12 @@ type AllocationSetter interface {
 	ExcludeFromShardAllocation(ctx context.Context, nodes string) error
 }
 
+// AllocationExplainer captures Elasticsearch API calls around the explanation of shard allocations.
+type AllocationExplainer interface {
+	// ExplainAllocation explains why a shard of the cluster is unassigned, or returns nil if all shards are assigned.
+	ExplainAllocation(ctx context.Context) (*AllocationExplanation, error)
+}
+
 // ShardLister captures Elasticsearch API calls around shards retrieval.
 type ShardLister interface {
 	GetShards(ctx context.Context) (Shards, error)
@@ -44,3 +51,16 @@ func (c *clientV6) GetShards(ctx context.Context) (Shards, error) {
 	}
 	return shards, nil
 }
+
+func (c *clientV6) ExplainAllocation(ctx context.Context) (*AllocationExplanation, error) {
+	var explanation AllocationExplanation
+	err := c.get(ctx, "/_cluster/allocation/explain", &explanation)
+	if isHTTPError(err, http.StatusBadRequest) {
+		// there is no unassigned shard to explain
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
@@ -0,0 +1,74 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+type SnapshotClient interface {
+	// GetSnapshotRepositories returns the registered snapshot repositories, indexed by name.
+	GetSnapshotRepositories(ctx context.Context) (map[string]SnapshotRepository, error)
+	// PutSnapshotRepository registers or updates a snapshot repository.
+	PutSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error
+	// DeleteSnapshotRepository unregisters a snapshot repository, leaving its snapshots untouched.
+	DeleteSnapshotRepository(ctx context.Context, name string) error
+	// RestoreSnapshot starts restoring the given snapshot of the given repository, without waiting for completion.
+	RestoreSnapshot(ctx context.Context, repository string, snapshot string, request SnapshotRestoreRequest) error
+	// PutSnapshotLifecyclePolicy creates or updates a snapshot lifecycle management policy.
+	// Introduced in: Elasticsearch 7.4.0
+	PutSnapshotLifecyclePolicy(ctx context.Context, name string, policy SnapshotLifecyclePolicy) error
+}
+
+// SnapshotRepository models a snapshot repository as accepted by the _snapshot API.
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// SnapshotRestoreRequest models a request to the _snapshot/<repository>/<snapshot>/_restore API.
+type SnapshotRestoreRequest struct {
+	Indices            []string `json:"indices,omitempty"`
+	IncludeGlobalState bool     `json:"include_global_state"`
+}
+
+// SnapshotLifecyclePolicy models a policy as accepted by the _slm/policy API.
+type SnapshotLifecyclePolicy struct {
+	Schedule   string                            `json:"schedule"`
+	Name       string                            `json:"name"`
+	Repository string                            `json:"repository"`
+	Config     map[string]interface{}            `json:"config,omitempty"`
+	Retention  *SnapshotLifecyclePolicyRetention `json:"retention,omitempty"`
+}
+
+// SnapshotLifecyclePolicyRetention models the retention rules of a snapshot lifecycle management policy.
+type SnapshotLifecyclePolicyRetention struct {
+	ExpireAfter string `json:"expire_after,omitempty"`
+	MinCount    *int32 `json:"min_count,omitempty"`
+	MaxCount    *int32 `json:"max_count,omitempty"`
+}
+
+func (c *baseClient) GetSnapshotRepositories(ctx context.Context) (map[string]SnapshotRepository, error) {
+	repositories := map[string]SnapshotRepository{}
+	err := c.get(ctx, "/_snapshot", &repositories)
+	return repositories, err
+}
+
+func (c *baseClient) PutSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error {
+	return c.put(ctx, fmt.Sprintf("/_snapshot/%s", name), repository, nil)
+}
+
+func (c *baseClient) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	return c.delete(ctx, fmt.Sprintf("/_snapshot/%s", name))
+}
+
+func (c *baseClient) RestoreSnapshot(ctx context.Context, repository string, snapshot string, request SnapshotRestoreRequest) error {
+	return c.post(ctx, fmt.Sprintf("/_snapshot/%s/%s/_restore", repository, snapshot), request, nil)
+}
+
+func (c *clientV7) PutSnapshotLifecyclePolicy(ctx context.Context, name string, policy SnapshotLifecyclePolicy) error {
+	return c.put(ctx, fmt.Sprintf("/_slm/policy/%s", name), policy, nil)
+}
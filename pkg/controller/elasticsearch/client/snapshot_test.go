@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	. "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func TestClient_PutSnapshotRepository(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_snapshot/my-repo", req.URL.Path)
+		require.Equal(t, http.MethodPut, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"s3","settings":{"bucket":"my-bucket"}}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"acknowledged": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	repository := SnapshotRepository{Type: "s3", Settings: map[string]interface{}{"bucket": "my-bucket"}}
+	assert.NoError(t, testClient.PutSnapshotRepository(context.Background(), "my-repo", repository))
+}
+
+func TestClient_GetSnapshotRepositories(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_snapshot", req.URL.Path)
+		require.Equal(t, http.MethodGet, req.Method)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"my-repo":{"type":"s3","settings":{"bucket":"my-bucket"}}}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	repositories, err := testClient.GetSnapshotRepositories(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]SnapshotRepository{
+		"my-repo": {Type: "s3", Settings: map[string]interface{}{"bucket": "my-bucket"}},
+	}, repositories)
+}
+
+func TestClient_DeleteSnapshotRepository(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_snapshot/my-repo", req.URL.Path)
+		require.Equal(t, http.MethodDelete, req.Method)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"acknowledged": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	assert.NoError(t, testClient.DeleteSnapshotRepository(context.Background(), "my-repo"))
+}
+
+func TestClient_RestoreSnapshot(t *testing.T) {
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_snapshot/my-repo/snap-1/_restore", req.URL.Path)
+		require.Equal(t, http.MethodPost, req.Method)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"indices":["logs-*"],"include_global_state":true}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"accepted": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	request := SnapshotRestoreRequest{Indices: []string{"logs-*"}, IncludeGlobalState: true}
+	assert.NoError(t, testClient.RestoreSnapshot(context.Background(), "my-repo", "snap-1", request))
+}
+
+func TestClient_PutSnapshotLifecyclePolicy(t *testing.T) {
+	maxCount := int32(50)
+	policy := SnapshotLifecyclePolicy{
+		Schedule:   "0 30 1 * * ?",
+		Name:       "<nightly-snap-{now/d}>",
+		Repository: "my-repo",
+		Retention:  &SnapshotLifecyclePolicyRetention{ExpireAfter: "30d", MaxCount: &maxCount},
+	}
+	testClient := NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_slm/policy/nightly", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"schedule":"0 30 1 * * ?","name":"<nightly-snap-{now/d}>","repository":"my-repo","retention":{"expire_after":"30d","max_count":50}}`, string(body))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"acknowledged": true}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	assert.NoError(t, testClient.PutSnapshotLifecyclePolicy(context.Background(), "nightly", policy))
+
+	v6Client := NewMockClient(version.MustParse("6.8.0"), func(req *http.Request) *http.Response {
+		t.Fatalf("unexpected request to %s", req.URL.Path)
+		return nil
+	})
+	assert.Error(t, v6Client.PutSnapshotLifecyclePolicy(context.Background(), "nightly", policy))
+}
@@ -0,0 +1,214 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultStateCacheTTL is the default duration during which the state of a cluster is served from the StateCache.
+const DefaultStateCacheTTL = 10 * time.Second
+
+// SharedStateCache is the StateCache shared by all the controllers interacting with Elasticsearch clusters. The clients
+// of the Elasticsearch driver and the ones returned by user.NewControllerUserClient, used by all the other controllers
+// requesting managed clusters, go through it. The license controller does not request Elasticsearch, and the
+// association controllers only request the version of resources not managed by the operator, which is not cached.
+var SharedStateCache = NewStateCache(DefaultStateCacheTTL)
+
+// StateCache caches, per cluster, the responses of the Elasticsearch APIs describing the cluster state (health,
+// version, nodes and license), so that controllers interacting with the same cluster do not each request them.
+// Entries expire after a TTL, and are invalidated when the Elasticsearch resource is reconciled or when a request
+// going through the cache modifies the corresponding state.
+type StateCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[types.NamespacedName]cachedState
+	now     func() time.Time
+}
+
+// NewStateCache returns a StateCache whose entries expire after the given TTL. A zero TTL disables caching.
+func NewStateCache(ttl time.Duration) *StateCache {
+	return &StateCache{
+		ttl:     ttl,
+		entries: make(map[types.NamespacedName]cachedState),
+		now:     time.Now,
+	}
+}
+
+type cachedValue struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// cachedState holds the cached API responses of a single cluster, indexed by API.
+type cachedState map[string]cachedValue
+
+const (
+	healthKey  = "health"
+	infoKey    = "info"
+	nodesKey   = "nodes"
+	licenseKey = "license"
+)
+
+// Wrap returns a Client serving the state of the given cluster from the cache, and relying on c for any other request
+// or when the cache does not hold a fresh response.
+func (s *StateCache) Wrap(cluster types.NamespacedName, c Client) Client {
+	if s == nil || s.ttl <= 0 {
+		return c
+	}
+	return &cachingClient{Client: c, cluster: cluster, cache: s}
+}
+
+// Invalidate removes all the cached responses of the given cluster.
+func (s *StateCache) Invalidate(cluster types.NamespacedName) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, cluster)
+}
+
+func (s *StateCache) invalidate(cluster types.NamespacedName, key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if state, exists := s.entries[cluster]; exists {
+		delete(state, key)
+	}
+}
+
+func (s *StateCache) get(cluster types.NamespacedName, key string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	state, exists := s.entries[cluster]
+	if !exists {
+		return nil, false
+	}
+	cached, exists := state[key]
+	if !exists || !s.now().Before(cached.expiresAt) {
+		return nil, false
+	}
+	return cached.value, true
+}
+
+func (s *StateCache) set(cluster types.NamespacedName, key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	state, exists := s.entries[cluster]
+	if !exists {
+		state = cachedState{}
+		s.entries[cluster] = state
+	}
+	state[key] = cachedValue{value: value, expiresAt: s.now().Add(s.ttl)}
+}
+
+// cachingClient is a Client serving the cluster state from a StateCache.
+type cachingClient struct {
+	Client
+	cluster types.NamespacedName
+	cache   *StateCache
+}
+
+var _ Client = &cachingClient{}
+
+// cached returns the cached value for key, or the result of fetch which is then cached if successful.
+func (c *cachingClient) cached(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if value, exists := c.cache.get(c.cluster, key); exists {
+		return value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.cache.set(c.cluster, key, value)
+	return value, nil
+}
+
+func (c *cachingClient) GetClusterHealth(ctx context.Context) (Health, error) {
+	health, err := c.cached(healthKey, func() (interface{}, error) {
+		return c.Client.GetClusterHealth(ctx)
+	})
+	if err != nil {
+		return Health{}, err
+	}
+	return health.(Health), nil
+}
+
+func (c *cachingClient) GetClusterInfo(ctx context.Context) (Info, error) {
+	info, err := c.cached(infoKey, func() (interface{}, error) {
+		return c.Client.GetClusterInfo(ctx)
+	})
+	if err != nil {
+		return Info{}, err
+	}
+	return info.(Info), nil
+}
+
+func (c *cachingClient) GetNodes(ctx context.Context) (Nodes, error) {
+	nodes, err := c.cached(nodesKey, func() (interface{}, error) {
+		return c.Client.GetNodes(ctx)
+	})
+	if err != nil {
+		return Nodes{}, err
+	}
+	return nodes.(Nodes), nil
+}
+
+func (c *cachingClient) GetLicense(ctx context.Context) (License, error) {
+	license, err := c.cached(licenseKey, func() (interface{}, error) {
+		return c.Client.GetLicense(ctx)
+	})
+	if err != nil {
+		return License{}, err
+	}
+	return license.(License), nil
+}
+
+func (c *cachingClient) UpdateLicense(ctx context.Context, licenses LicenseUpdateRequest) (LicenseUpdateResponse, error) {
+	defer c.cache.invalidate(c.cluster, licenseKey)
+	return c.Client.UpdateLicense(ctx, licenses)
+}
+
+func (c *cachingClient) StartBasic(ctx context.Context) (StartBasicResponse, error) {
+	defer c.cache.invalidate(c.cluster, licenseKey)
+	return c.Client.StartBasic(ctx)
+}
+
+func (c *cachingClient) StartTrial(ctx context.Context) (StartTrialResponse, error) {
+	defer c.cache.invalidate(c.cluster, licenseKey)
+	return c.Client.StartTrial(ctx)
+}
+
+func (c *cachingClient) ExcludeFromShardAllocation(ctx context.Context, nodes string) error {
+	defer c.cache.invalidate(c.cluster, healthKey)
+	return c.Client.ExcludeFromShardAllocation(ctx, nodes)
+}
+
+func (c *cachingClient) DisableReplicaShardsAllocation(ctx context.Context) error {
+	defer c.cache.invalidate(c.cluster, healthKey)
+	return c.Client.DisableReplicaShardsAllocation(ctx)
+}
+
+func (c *cachingClient) EnableShardAllocation(ctx context.Context) error {
+	defer c.cache.invalidate(c.cluster, healthKey)
+	return c.Client.EnableShardAllocation(ctx)
+}
+
+func (c *cachingClient) RemoveTransientAllocationSettings(ctx context.Context) error {
+	defer c.cache.invalidate(c.cluster, healthKey)
+	return c.Client.RemoveTransientAllocationSettings(ctx)
+}
+
+// Equal returns true if other can be considered as the same client, cached or not.
+func (c *cachingClient) Equal(other Client) bool {
+	if otherCaching, ok := other.(*cachingClient); ok {
+		other = otherCaching.Client
+	}
+	return c.Client.Equal(other)
+}
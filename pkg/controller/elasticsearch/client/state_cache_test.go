@@ -0,0 +1,113 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// countingClient counts the requests made to Elasticsearch.
+type countingClient struct {
+	Client
+	requests map[string]int
+	err      error
+}
+
+func (c *countingClient) GetClusterHealth(_ context.Context) (Health, error) {
+	c.requests["health"]++
+	return Health{Status: "green", NumberOfNodes: c.requests["health"]}, c.err
+}
+
+func (c *countingClient) GetNodes(_ context.Context) (Nodes, error) {
+	c.requests["nodes"]++
+	return Nodes{Nodes: map[string]Node{"a": {Name: "a"}}}, c.err
+}
+
+func (c *countingClient) GetLicense(_ context.Context) (License, error) {
+	c.requests["license"]++
+	return License{Type: "basic"}, c.err
+}
+
+func (c *countingClient) StartTrial(_ context.Context) (StartTrialResponse, error) {
+	return StartTrialResponse{Acknowledged: true}, nil
+}
+
+func TestStateCache(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "ns", Name: "es"}
+	now := time.Now()
+	cache := NewStateCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	counting := &countingClient{requests: map[string]int{}}
+	c1 := cache.Wrap(cluster, counting)
+	c2 := cache.Wrap(cluster, counting)
+	ctx := context.Background()
+
+	// the state is requested once, then shared between clients of the same cluster
+	health, err := c1.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, health.NumberOfNodes)
+	health, err = c2.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, health.NumberOfNodes)
+	_, err = c1.GetNodes(ctx)
+	require.NoError(t, err)
+	nodes, err := c2.GetNodes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a", nodes.Nodes["a"].Name)
+	require.Equal(t, map[string]int{"health": 1, "nodes": 1}, counting.requests)
+
+	// other clusters are not affected
+	other := cache.Wrap(types.NamespacedName{Namespace: "ns", Name: "other"}, counting)
+	_, err = other.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, counting.requests["health"])
+
+	// entries expire after the TTL
+	now = now.Add(10 * time.Second)
+	health, err = c1.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, health.NumberOfNodes)
+
+	// changing the license invalidates the cached license only
+	_, err = c1.GetLicense(ctx)
+	require.NoError(t, err)
+	_, err = c2.StartTrial(ctx)
+	require.NoError(t, err)
+	_, err = c1.GetLicense(ctx)
+	require.NoError(t, err)
+	_, err = c1.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"health": 3, "nodes": 1, "license": 2}, counting.requests)
+
+	// invalidating the cluster invalidates everything
+	cache.Invalidate(cluster)
+	_, err = c1.GetClusterHealth(ctx)
+	require.NoError(t, err)
+	_, err = c1.GetLicense(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"health": 4, "nodes": 1, "license": 3}, counting.requests)
+
+	// errors are not cached
+	counting.err = errors.New("boom")
+	cache.Invalidate(cluster)
+	_, err = c1.GetNodes(ctx)
+	require.Error(t, err)
+	counting.err = nil
+	_, err = c1.GetNodes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, counting.requests["nodes"])
+}
+
+func TestStateCache_Disabled(t *testing.T) {
+	counting := &countingClient{requests: map[string]int{}}
+	require.Same(t, counting, NewStateCache(0).Wrap(types.NamespacedName{Namespace: "ns", Name: "es"}, counting))
+}
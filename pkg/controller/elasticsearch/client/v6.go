@@ -126,6 +126,18 @@ func (c *clientV6) UpdateRemoteClusterSettings(ctx context.Context, settings Rem
 	return c.put(ctx, "/_cluster/settings", &settings, nil)
 }
 
+func (c *clientV6) GetPersistentClusterSettings(ctx context.Context) (map[string]interface{}, error) {
+	var settings struct {
+		Persistent map[string]interface{} `json:"persistent"`
+	}
+	err := c.get(ctx, "/_cluster/settings?flat_settings=true", &settings)
+	return settings.Persistent, err
+}
+
+func (c *clientV6) UpdatePersistentClusterSettings(ctx context.Context, settings map[string]interface{}) error {
+	return c.put(ctx, "/_cluster/settings", map[string]interface{}{"persistent": settings}, nil)
+}
+
 func (c *clientV6) GetRemoteClusterSettings(ctx context.Context) (RemoteClustersSettings, error) {
 	remoteClustersSettings := RemoteClustersSettings{}
 	err := c.get(ctx, "/_cluster/settings", &remoteClustersSettings)
@@ -160,6 +172,10 @@ func (c *clientV6) AddVotingConfigExclusions(ctx context.Context, nodeNames []st
 	return errNotSupportedInEs6x
 }
 
+func (c *clientV6) GetVotingConfigExclusions(_ context.Context) ([]VotingConfigExclusion, error) {
+	return nil, errNotSupportedInEs6x
+}
+
 func (c *clientV6) DeleteVotingConfigExclusions(ctx context.Context, waitForRemoval bool) error {
 	return errNotSupportedInEs6x
 }
@@ -180,6 +196,10 @@ func (c *clientV6) UpdateMLNodesSettings(_ context.Context, _ int32, _ string) e
 	return errNotSupportedInEs6x
 }
 
+func (c *clientV6) PutSnapshotLifecyclePolicy(context.Context, string, SnapshotLifecyclePolicy) error {
+	return errNotSupportedInEs6x
+}
+
 func (c *clientV6) GetShutdown(context.Context, *string) (ShutdownResponse, error) {
 	return ShutdownResponse{}, errNotSupportedInEs6x
 }
@@ -80,6 +80,15 @@ func (c *clientV7) DeleteShutdown(ctx context.Context, nodeID string) error {
 	return c.delete(ctx, fmt.Sprintf("/_nodes/%s/shutdown", nodeID))
 }
 
+func (c *clientV7) GetVotingConfigExclusions(ctx context.Context) ([]VotingConfigExclusion, error) {
+	var response VotingConfigExclusionsResponse
+	path := "/_cluster/state/metadata?filter_path=metadata.cluster_coordination.voting_config_exclusions"
+	if err := c.get(ctx, path, &response); err != nil {
+		return nil, errors.Wrap(err, "unable to get voting_config_exclusions")
+	}
+	return response.Metadata.ClusterCoordination.VotingConfigExclusions, nil
+}
+
 func (c *clientV7) DeleteVotingConfigExclusions(ctx context.Context, waitForRemoval bool) error {
 	path := fmt.Sprintf(
 		"/_cluster/voting_config_exclusions?wait_for_removal=%s",
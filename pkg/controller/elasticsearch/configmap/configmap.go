@@ -34,7 +34,7 @@ func NewConfigMapWithData(es types.NamespacedName, data map[string]string) corev
 
 // ReconcileScriptsConfigMap reconciles a configmap containing scripts and related configuration used by
 // init containers and readiness probe.
-func ReconcileScriptsConfigMap(ctx context.Context, c k8s.Client, es esv1.Elasticsearch) error {
+func ReconcileScriptsConfigMap(ctx context.Context, c k8s.Client, es esv1.Elasticsearch, serverSideApply bool) error {
 	span, _ := apm.StartSpan(ctx, "reconcile_scripts", tracing.SpanTypeApp)
 	defer span.End()
 
@@ -54,5 +54,5 @@ func ReconcileScriptsConfigMap(ctx context.Context, c k8s.Client, es esv1.Elasti
 		},
 	)
 
-	return ReconcileConfigMap(c, es, scriptsConfigMap)
+	return ReconcileConfigMap(c, es, scriptsConfigMap, serverSideApply)
 }
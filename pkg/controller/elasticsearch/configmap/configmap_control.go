@@ -14,11 +14,13 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
-// ReconcileConfigMap checks for an existing config map and updates it or creates one if it does not exist.
+// ReconcileConfigMap checks for an existing config map and updates it or creates one if it does not exist, through
+// server-side apply if serverSideApply is true.
 func ReconcileConfigMap(
 	c k8s.Client,
 	es esv1.Elasticsearch,
 	expected corev1.ConfigMap,
+	serverSideApply bool,
 ) error {
 	reconciled := &corev1.ConfigMap{}
 	return reconciler.ReconcileResource(
@@ -33,6 +35,7 @@ func ReconcileConfigMap(
 			UpdateReconciled: func() {
 				reconciled.Data = expected.Data
 			},
+			ServerSideApply: serverSideApply,
 		},
 	)
 }
@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+
+	"go.elastic.co/apm"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+const (
+	// rolloverMaxPrimaryShardSize and rolloverMaxAge are the conditions for the rollover of the indices in the hot tier.
+	rolloverMaxPrimaryShardSize = "50gb"
+	rolloverMaxAge              = "30d"
+)
+
+// reconcileDataTiersILMPolicy publishes the ILM policy of the data tiers, if any. Like other settings managed through
+// the Elasticsearch API, the policy is published at each reconciliation to revert manual changes.
+func reconcileDataTiersILMPolicy(ctx context.Context, esClient esclient.Client, es esv1.Elasticsearch, ver version.Version) error {
+	if es.Spec.DataTiers == nil || es.Spec.DataTiers.ILMPolicy == nil {
+		return nil
+	}
+	span, ctx := apm.StartSpan(ctx, "reconcile_data_tiers_ilm_policy", tracing.SpanTypeApp)
+	defer span.End()
+
+	return esClient.PutLifecyclePolicy(ctx, es.Spec.DataTiers.ILMPolicy.Name, dataTiersLifecyclePolicy(*es.Spec.DataTiers, ver))
+}
+
+// dataTiersLifecyclePolicy returns an ILM policy rolling indices over in the hot tier and moving them through the
+// other configured tiers once they reach the minimum age of each tier. Indices are moved to the nodes of each tier
+// by the migrate action ILM implicitly adds to the warm and cold phases, and are mounted as searchable snapshots in
+// the frozen phase.
+func dataTiersLifecyclePolicy(tiers esv1.DataTiers, ver version.Version) esclient.LifecyclePolicy {
+	policy := esclient.LifecyclePolicy{Phases: make(map[string]esclient.LifecyclePhase)}
+	if tiers.Hot != nil {
+		rollover := map[string]interface{}{"max_age": rolloverMaxAge}
+		// max_primary_shard_size was introduced in 7.13.0
+		if ver.GTE(version.From(7, 13, 0)) {
+			rollover["max_primary_shard_size"] = rolloverMaxPrimaryShardSize
+		} else {
+			rollover["max_size"] = rolloverMaxPrimaryShardSize
+		}
+		policy.Phases["hot"] = esclient.LifecyclePhase{
+			Actions: map[string]interface{}{
+				"rollover":     rollover,
+				"set_priority": map[string]interface{}{"priority": 100},
+			},
+		}
+	}
+	if tiers.Warm != nil {
+		policy.Phases["warm"] = esclient.LifecyclePhase{
+			MinAge:  tiers.Warm.MinAge,
+			Actions: map[string]interface{}{"set_priority": map[string]interface{}{"priority": 50}},
+		}
+	}
+	if tiers.Cold != nil {
+		policy.Phases["cold"] = esclient.LifecyclePhase{
+			MinAge:  tiers.Cold.MinAge,
+			Actions: map[string]interface{}{"set_priority": map[string]interface{}{"priority": 0}},
+		}
+	}
+	if tiers.Frozen != nil {
+		policy.Phases["frozen"] = esclient.LifecyclePhase{
+			MinAge: tiers.Frozen.MinAge,
+			Actions: map[string]interface{}{
+				"searchable_snapshot": map[string]interface{}{"snapshot_repository": tiers.ILMPolicy.SnapshotRepository},
+			},
+		}
+	}
+	if tiers.ILMPolicy.DeleteAfter != "" {
+		policy.Phases["delete"] = esclient.LifecyclePhase{
+			MinAge:  tiers.ILMPolicy.DeleteAfter,
+			Actions: map[string]interface{}{"delete": map[string]interface{}{}},
+		}
+	}
+	return policy
+}
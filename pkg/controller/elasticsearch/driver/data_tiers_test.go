@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func Test_dataTiersLifecyclePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   esv1.DataTiers
+		version string
+		want    esclient.LifecyclePolicy
+	}{
+		{
+			name: "hot and warm tiers",
+			tiers: esv1.DataTiers{
+				Hot:       &esv1.DataTier{NodeSets: []string{"hot"}},
+				Warm:      &esv1.DataTier{NodeSets: []string{"warm"}, MinAge: "7d"},
+				ILMPolicy: &esv1.DataTiersILMPolicy{Name: "tiers"},
+			},
+			version: "7.15.0",
+			want: esclient.LifecyclePolicy{Phases: map[string]esclient.LifecyclePhase{
+				"hot": {Actions: map[string]interface{}{
+					"rollover":     map[string]interface{}{"max_age": "30d", "max_primary_shard_size": "50gb"},
+					"set_priority": map[string]interface{}{"priority": 100},
+				}},
+				"warm": {MinAge: "7d", Actions: map[string]interface{}{"set_priority": map[string]interface{}{"priority": 50}}},
+			}},
+		},
+		{
+			name: "all tiers with deletion before 7.13",
+			tiers: esv1.DataTiers{
+				Hot:       &esv1.DataTier{NodeSets: []string{"hot"}},
+				Cold:      &esv1.DataTier{NodeSets: []string{"cold"}, MinAge: "30d"},
+				Frozen:    &esv1.DataTier{NodeSets: []string{"frozen"}, MinAge: "90d"},
+				ILMPolicy: &esv1.DataTiersILMPolicy{Name: "tiers", SnapshotRepository: "repo", DeleteAfter: "365d"},
+			},
+			version: "7.12.1",
+			want: esclient.LifecyclePolicy{Phases: map[string]esclient.LifecyclePhase{
+				"hot": {Actions: map[string]interface{}{
+					"rollover":     map[string]interface{}{"max_age": "30d", "max_size": "50gb"},
+					"set_priority": map[string]interface{}{"priority": 100},
+				}},
+				"cold": {MinAge: "30d", Actions: map[string]interface{}{"set_priority": map[string]interface{}{"priority": 0}}},
+				"frozen": {MinAge: "90d", Actions: map[string]interface{}{
+					"searchable_snapshot": map[string]interface{}{"snapshot_repository": "repo"},
+				}},
+				"delete": {MinAge: "365d", Actions: map[string]interface{}{"delete": map[string]interface{}{}}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, dataTiersLifecyclePolicy(tt.tiers, version.MustParse(tt.version)))
+		})
+	}
+}
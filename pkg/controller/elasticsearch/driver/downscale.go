@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -55,14 +56,14 @@ func HandleDownscale(
 	// if leaving nodes is empty this should cancel any ongoing shutdowns
 	leavingNodes := leavingNodeNames(downscales)
 	if err := downscaleCtx.nodeShutdown.ReconcileShutdowns(downscaleCtx.parentCtx, leavingNodes); err != nil {
-		return results.WithError(err)
+		return withESError(results, err)
 	}
 
 	for _, downscale := range downscales {
 		// attempt the StatefulSet downscale (may or may not remove nodes)
 		requeue, err := attemptDownscale(downscaleCtx, downscale, actualStatefulSets)
 		if err != nil {
-			return results.WithError(err)
+			return withESError(results, err)
 		}
 		if requeue {
 			// retry downscaling this statefulset later
@@ -91,6 +92,11 @@ func calculateDownscales(
 	actualStatefulSets sset.StatefulSetList,
 ) (downscales []ssetDownscale, deletions sset.StatefulSetList) {
 	expectedStatefulSetsNames := expectedStatefulSets.Names()
+	// consider StatefulSets in the tier order of the downscale policy, if any
+	actualStatefulSets = actualStatefulSets.DeepCopy()
+	sort.SliceStable(actualStatefulSets, func(i, j int) bool {
+		return state.tierRank(actualStatefulSets[i]) < state.tierRank(actualStatefulSets[j])
+	})
 	for _, actualSset := range actualStatefulSets {
 		actualReplicas := sset.GetReplicas(actualSset)
 		expectedSset, shouldExist := expectedStatefulSets.GetByName(actualSset.Name)
@@ -109,6 +115,7 @@ func calculateDownscales(
 			// the StatefulSet should be downscaled
 			requestedDeletes := actualReplicas - expectedReplicas
 			allowedDeletes, reason := checkDownscaleInvariants(state, actualSset, requestedDeletes)
+			state.recordPendingRemovals(actualSset)
 			if allowedDeletes == 0 {
 				ssetLogger(actualSset).V(1).Info("Cannot downscale StatefulSet", "reason", reason)
 				continue
@@ -200,7 +207,7 @@ func calculatePerformableDownscale(
 			performableDownscale.targetReplicas--
 		case esclient.ShutdownStalled:
 			// shutdown stalled this can require user interaction: bubble up via event
-			ctx.reconcileState.UpdateElasticsearchShutdownStalled(ctx.resourcesState, ctx.observedState, response.Explanation)
+			ctx.reconcileState.UpdateElasticsearchShutdownStalled(ctx.resourcesState, ctx.observedState, node, response.Explanation)
 			// no need to check other nodes since we remove them in order and this one isn't ready anyway
 			return performableDownscale, nil
 		case esclient.ShutdownStarted:
@@ -15,14 +15,19 @@ import (
 )
 
 const (
-	OneMasterAtATimeInvariant        = "A master node is already in the process of being removed"
-	AtLeastOneRunningMasterInvariant = "Cannot remove the last running master node"
-	RespectMaxUnavailableInvariant   = "Not removing node to respect maxUnavailable setting"
+	OneMasterAtATimeInvariant          = "A master node is already in the process of being removed"
+	AtLeastOneRunningMasterInvariant   = "Cannot remove the last running master node"
+	RespectMaxUnavailableInvariant     = "Not removing node to respect maxUnavailable setting"
+	EarlierTiersFirstInvariant         = "Waiting for the removal of nodes of a tier earlier in the downscale policy tier order"
+	RespectMaxRemovalsPerTierInvariant = "Not removing node to respect the downscale policy maxRemovalsPerTier setting"
 )
 
 // checkDownscaleInvariants returns the number of nodes that can be removed if the given state state allows downscaling
 // the given StatefulSet. If that number is 0, it also returns the reason why.
 func checkDownscaleInvariants(state downscaleState, statefulSet appsv1.StatefulSet, requestedDeletes int32) (int32, string) {
+	if state.pendingTierRank != nil && state.tierRank(statefulSet) > *state.pendingTierRank {
+		return 0, EarlierTiersFirstInvariant
+	}
 	if label.IsMasterNodeSet(statefulSet) {
 		if state.masterRemovalInProgress {
 			return 0, OneMasterAtATimeInvariant
@@ -38,6 +43,16 @@ func checkDownscaleInvariants(state downscaleState, statefulSet appsv1.StatefulS
 		return 0, RespectMaxUnavailableInvariant
 	}
 
+	if state.maxRemovalsPerTier != nil {
+		tierRemovalsAllowed := *state.maxRemovalsPerTier - state.removalsPerTier[label.StatefulSetNodeTier(statefulSet)]
+		if tierRemovalsAllowed <= 0 {
+			return 0, RespectMaxRemovalsPerTierInvariant
+		}
+		if allowedDeletes > tierRemovalsAllowed {
+			allowedDeletes = tierRemovalsAllowed
+		}
+	}
+
 	return allowedDeletes, ""
 }
 
@@ -50,6 +65,14 @@ type downscaleState struct {
 	removalsAllowed *int32
 	// masterRemovalInProgress indicates whether a master node is in the process of being removed already.
 	masterRemovalInProgress bool
+	// tierOrder is the order in which nodes with the given roles must be removed.
+	tierOrder []esv1.NodeRole
+	// pendingTierRank is the rank in tierOrder of the first tier with nodes to remove, nil if there are none so far.
+	pendingTierRank *int
+	// maxRemovalsPerTier is the maximum number of nodes of a single tier to remove at the same time, nil if unlimited.
+	maxRemovalsPerTier *int32
+	// removalsPerTier counts the nodes to be removed per tier.
+	removalsPerTier map[string]int32
 }
 
 // newDownscaleState creates a new downscaleState.
@@ -69,6 +92,8 @@ func newDownscaleState(c k8s.Client, es esv1.Elasticsearch) (*downscaleState, er
 			int32(len(nodesReady)),
 			es.Spec.NodeCount(),
 			es.Spec.UpdateStrategy.ChangeBudget.GetMaxUnavailableOrDefault()),
+		tierOrder:          es.Spec.DownscalePolicy.TierOrder,
+		maxRemovalsPerTier: es.Spec.DownscalePolicy.MaxRemovalsPerTier,
 	}, nil
 }
 
@@ -97,6 +122,27 @@ func (s *downscaleState) getMaxNodesToRemove(noMoreThan int32) int32 {
 	return noMoreThan
 }
 
+// tierRank returns the rank of the given StatefulSet in the tier order: the index of the first role of its nodes in the
+// tier order, or the length of the tier order if its nodes have none of the listed roles.
+func (s *downscaleState) tierRank(statefulSet appsv1.StatefulSet) int {
+	for i, role := range s.tierOrder {
+		if label.HasNodeRole(role, statefulSet.Spec.Template.Labels) {
+			return i
+		}
+	}
+	return len(s.tierOrder)
+}
+
+// recordPendingRemovals updates the state to consider that nodes of the given StatefulSet must be removed, even if
+// they cannot be removed yet. This prevents the removal of nodes of later tiers.
+func (s *downscaleState) recordPendingRemovals(statefulSet appsv1.StatefulSet) {
+	if len(s.tierOrder) == 0 || s.pendingTierRank != nil {
+		return
+	}
+	rank := s.tierRank(statefulSet)
+	s.pendingTierRank = &rank
+}
+
 // recordNodeRemoval updates the state to consider n-replica downscale of the given statefulSet.
 func (s *downscaleState) recordNodeRemoval(statefulSet appsv1.StatefulSet, accountedRemovals int32) {
 	if accountedRemovals == 0 {
@@ -111,4 +157,11 @@ func (s *downscaleState) recordNodeRemoval(statefulSet appsv1.StatefulSet, accou
 	if s.removalsAllowed != nil {
 		*s.removalsAllowed -= accountedRemovals
 	}
+
+	if s.maxRemovalsPerTier != nil {
+		if s.removalsPerTier == nil {
+			s.removalsPerTier = map[string]int32{}
+		}
+		s.removalsPerTier[label.StatefulSetNodeTier(statefulSet)] += accountedRemovals
+	}
 }
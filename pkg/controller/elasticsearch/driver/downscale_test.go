@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/comparison"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/expectations"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
@@ -585,6 +586,77 @@ func Test_calculateDownscales(t *testing.T) {
 	}
 }
 
+func Test_calculateDownscales_downscalePolicy(t *testing.T) {
+	tierSset := func(name string, replicas int32, tierLabel common.TrueFalseLabel) appsv1.StatefulSet {
+		statefulSet := sset.TestSset{Namespace: "ns", Name: name, Replicas: replicas, Data: true}.Build()
+		tierLabel.Set(true, statefulSet.Spec.Template.Labels)
+		return statefulSet
+	}
+	actual := sset.StatefulSetList{
+		tierSset("hot", 3, label.NodeTypesDataHotLabelName),
+		tierSset("warm", 3, label.NodeTypesDataWarmLabelName),
+		tierSset("cold", 4, label.NodeTypesDataColdLabelName),
+		tierSset("frozen", 2, label.NodeTypesDataFrozenLabelName),
+	}
+	expected := sset.StatefulSetList{
+		tierSset("hot", 2, label.NodeTypesDataHotLabelName),
+		tierSset("warm", 2, label.NodeTypesDataWarmLabelName),
+		tierSset("cold", 1, label.NodeTypesDataColdLabelName),
+		tierSset("frozen", 1, label.NodeTypesDataFrozenLabelName),
+	}
+	tests := []struct {
+		name  string
+		state downscaleState
+		want  map[string]int32
+	}{
+		{
+			name:  "no downscale policy: remove nodes of all NodeSets at once",
+			state: downscaleState{},
+			want:  map[string]int32{"hot": 2, "warm": 2, "cold": 1, "frozen": 1},
+		},
+		{
+			name:  "tier order: remove cold nodes first",
+			state: downscaleState{tierOrder: []esv1.NodeRole{esv1.DataColdRole, esv1.DataWarmRole, esv1.DataHotRole}},
+			want:  map[string]int32{"cold": 1},
+		},
+		{
+			name:  "tier order: remove frozen nodes first",
+			state: downscaleState{tierOrder: []esv1.NodeRole{esv1.DataFrozenRole, esv1.DataColdRole}},
+			want:  map[string]int32{"frozen": 1},
+		},
+		{
+			name: "tier order and max removals per tier",
+			state: downscaleState{
+				tierOrder:          []esv1.NodeRole{esv1.DataColdRole, esv1.DataWarmRole, esv1.DataHotRole},
+				maxRemovalsPerTier: pointer.Int32(2),
+			},
+			want: map[string]int32{"cold": 2},
+		},
+		{
+			name:  "max removals per tier only",
+			state: downscaleState{maxRemovalsPerTier: pointer.Int32(1)},
+			want:  map[string]int32{"hot": 2, "warm": 2, "cold": 3, "frozen": 1},
+		},
+		{
+			name:  "unlisted tiers are removed last",
+			state: downscaleState{tierOrder: []esv1.NodeRole{esv1.DataWarmRole}},
+			want:  map[string]int32{"warm": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downscales, _ := calculateDownscales(tt.state, expected, actual)
+			got := map[string]int32{}
+			for _, downscale := range downscales {
+				got[downscale.statefulSet.Name] = downscale.targetReplicas
+			}
+			require.Equal(t, tt.want, got)
+			// the actual StatefulSets are not reordered
+			require.Equal(t, "hot", actual[0].Name)
+		})
+	}
+}
+
 func Test_calculatePerformableDownscale(t *testing.T) {
 	type args struct {
 		ctx       downscaleContext
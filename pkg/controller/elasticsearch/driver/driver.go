@@ -16,6 +16,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
@@ -48,8 +49,40 @@ import (
 
 var (
 	defaultRequeue = controller.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+	// rotationPendingRequeue is the requeue while Elasticsearch does not accept the pending passwords of a staged
+	// rotation yet. It is short since the current passwords stop working as soon as the file realm is reloaded.
+	rotationPendingRequeue = controller.Result{Requeue: true, RequeueAfter: 2 * time.Second}
 )
 
+// withESError adds the error of a step sending requests changing the state of Elasticsearch to the results. These
+// requests are not sent in dry-run mode: the steps depending on them are skipped and the reconciliation is requeued.
+func withESError(results *reconciler.Results, err error) *reconciler.Results {
+	if esclient.IsDryRun(err) {
+		return results.WithResult(defaultRequeue)
+	}
+	return results.WithError(err)
+}
+
+// promotePendingPasswords switches to the internal users passwords generated by a staged rotation once Elasticsearch
+// accepts them, and returns the credentials of the controller user. The file realm only holds the hashes of the
+// pending passwords: the reconciliation is requeued shortly until they are promoted, to limit the time during which
+// the operator keeps using the current passwords after Elasticsearch reloaded the file realm.
+func promotePendingPasswords(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	authenticate func(esclient.BasicAuth) error,
+	results *reconciler.Results,
+) (esclient.BasicAuth, error) {
+	controllerUser, pending, err := user.PromotePendingPasswords(c, es, authenticate)
+	if err != nil {
+		return esclient.BasicAuth{}, err
+	}
+	if pending {
+		results.WithResult(rotationPendingRequeue)
+	}
+	return controllerUser, nil
+}
+
 // Driver orchestrates the reconciliation of an Elasticsearch resource.
 // Its lifecycle is bound to a single reconciliation attempt.
 type Driver interface {
@@ -115,21 +148,26 @@ var _ commondriver.Interface = &defaultDriver{}
 func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	results := reconciler.NewResult(ctx)
 
+	// in maintenance mode, keep observing the cluster but do not apply any change to it
+	if d.ES.IsInMaintenance() {
+		return results.WithResults(d.observeInMaintenance(ctx))
+	}
+
 	// garbage collect secrets attached to this cluster that we don't need anymore
 	if err := cleanup.DeleteOrphanedSecrets(ctx, d.Client, d.ES); err != nil {
 		return results.WithError(err)
 	}
 
-	if err := configmap.ReconcileScriptsConfigMap(ctx, d.Client, d.ES); err != nil {
+	if err := configmap.ReconcileScriptsConfigMap(ctx, d.Client, d.ES, d.OperatorParameters.ServerSideApply); err != nil {
 		return results.WithError(err)
 	}
 
-	_, err := common.ReconcileService(ctx, d.Client, services.NewTransportService(d.ES), &d.ES)
+	_, err := common.ReconcileService(ctx, d.Client, services.NewTransportService(d.ES), &d.ES, d.OperatorParameters.ServerSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
 
-	externalService, err := common.ReconcileService(ctx, d.Client, services.NewExternalService(d.ES), &d.ES)
+	externalService, err := common.ReconcileService(ctx, d.Client, services.NewExternalService(d.ES), &d.ES, d.OperatorParameters.ServerSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -141,14 +179,17 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 		[]corev1.Service{*externalService},
 		d.OperatorParameters.CACertRotation,
 		d.OperatorParameters.CertRotation,
+		d.OperatorParameters.ServerSideApply,
 	)
+	_, certificatesErr := res.Aggregate()
+	d.ReconcileState.UpdateCertificatesReady(certificatesErr)
 	if results.WithResults(res).HasError() {
 		return results
 	}
 
-	controllerUser, err := user.ReconcileUsersAndRoles(ctx, d.Client, d.ES, d.DynamicWatches(), d.Recorder())
-	if err != nil {
-		return results.WithError(err)
+	controllerUser, res := user.ReconcileUsersAndRoles(ctx, d.Client, d.ES, d.DynamicWatches(), d.Recorder(), d.OperatorParameters.ServerSideApply)
+	if results.WithResults(res).HasError() {
+		return results
 	}
 
 	// Patch the Pods to add the expected node labels as annotations. Record the error, if any, but do not stop the
@@ -169,6 +210,16 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 
 	warnUnsupportedDistro(resourcesState.AllPods, d.ReconcileState.Recorder)
 
+	// switch to the internal users passwords generated by a rotation once Elasticsearch accepts them
+	controllerUser, err = promotePendingPasswords(d.Client, d.ES, func(u esclient.BasicAuth) error {
+		esClient := d.newElasticsearchClient(resourcesState, u, *min, certificateResources.TrustedHTTPCertificates)
+		defer esClient.Close()
+		return esClient.Authenticate(ctx)
+	}, results)
+	if err != nil {
+		return results.WithError(err)
+	}
+
 	observedState := d.Observers.ObservedStateResolver(
 		d.ES,
 		d.newElasticsearchClient(
@@ -187,7 +238,7 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	}
 
 	// TODO: support user-supplied certificate (non-ca)
-	esClient := d.newElasticsearchClient(
+	esClient := d.newCachingElasticsearchClient(
 		resourcesState,
 		controllerUser,
 		*min,
@@ -239,7 +290,7 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	// reconcile remote clusters
 	if esReachable {
 		requeue, err := remotecluster.UpdateSettings(ctx, d.Client, esClient, d.Recorder(), d.LicenseChecker, d.ES)
-		if err != nil {
+		if err != nil && !esclient.IsDryRun(err) {
 			msg := "Could not update remote clusters in Elasticsearch settings, re-queuing"
 			log.Info(msg, "err", err, "namespace", d.ES.Namespace, "es_name", d.ES.Name)
 			d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
@@ -250,23 +301,55 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 		}
 	}
 
+	// reconcile the cross-cluster API keys used to connect to the remote clusters, created in the remote clusters
+	apiKeysRequeueIn, err := remotecluster.ReconcileAPIKeys(ctx, d.Client, d.OperatorParameters.Dialer, d.OperatorParameters.DryRun, d.OperatorParameters.ServerSideApply, d.LicenseChecker, d.ES)
+	if err != nil && !esclient.IsDryRun(err) {
+		msg := "Could not reconcile remote cluster API keys, re-queuing"
+		log.Info(msg, "err", err, "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+		d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, fmt.Sprintf("%s: %s", msg, err.Error()))
+		results.WithResult(defaultRequeue)
+	} else if apiKeysRequeueIn > 0 {
+		// rotate the API keys before they expire
+		results.WithResult(controller.Result{RequeueAfter: apiKeysRequeueIn})
+	}
+
+	// reconcile the follower indices and auto-follow patterns of the remote clusters
+	if esReachable {
+		if err := remotecluster.UpdateCrossClusterReplication(ctx, d.Client, esClient, d.LicenseChecker, &d.ES); err != nil && !esclient.IsDryRun(err) {
+			msg := "Could not update cross-cluster replication, re-queuing"
+			log.Info(msg, "err", err, "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+			d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, fmt.Sprintf("%s: %s", msg, err.Error()))
+			results.WithResult(defaultRequeue)
+		}
+	}
+
+	// publish the ILM policy of the data tiers
+	if esReachable {
+		if err := reconcileDataTiersILMPolicy(ctx, esClient, d.ES, *min); err != nil && !esclient.IsDryRun(err) {
+			msg := "Could not publish the ILM policy of the data tiers, re-queuing"
+			log.Info(msg, "err", err, "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+			d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, fmt.Sprintf("%s: %s", msg, err.Error()))
+			results.WithResult(defaultRequeue)
+		}
+	}
+
 	// Compute seed hosts based on current masters with a podIP
 	if err := settings.UpdateSeedHostsConfigMap(ctx, d.Client, d.ES, resourcesState.AllPods); err != nil {
 		return results.WithError(err)
 	}
 
 	// setup a keystore with secure settings in an init container, if specified by the user
-	keystoreResources, err := keystore.NewResources(
-		d,
-		&d.ES,
-		esv1.ESNamer,
-		label.NewLabels(k8s.ExtractNamespacedName(&d.ES)),
-		initcontainer.KeystoreParams,
-	)
+	keystoreResources, err := newKeystoreResources(d, d.ES, d.OperatorParameters.ServerSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
 
+	// restore the initial snapshot of a new cluster, once bootstrapped
+	restoringSnapshot := d.reconcileInitialSnapshotRestore(ctx, esClient, esReachable)
+	if restoringSnapshot {
+		results = results.WithResult(defaultRequeue)
+	}
+
 	// set an annotation with the ClusterUUID, if bootstrapped
 	requeue, err := bootstrap.ReconcileClusterUUID(ctx, d.Client, &d.ES, esClient, esReachable)
 	if err != nil {
@@ -277,7 +360,7 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	}
 
 	// reconcile beats config secrets if Stack Monitoring is defined
-	err = stackmon.ReconcileConfigSecrets(d.Client, d.ES)
+	err = stackmon.ReconcileConfigSecrets(d.Client, d.ES, d.OperatorParameters.ServerSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -302,10 +385,38 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 		return results
 	}
 
+	if restoringSnapshot && d.ReconcileState.IsElasticsearchReady(observedState()) {
+		// the cluster is only reported Ready once the initial snapshot is restored
+		d.ReconcileState.UpdateElasticsearchStatusPhase(esv1.ElasticsearchRestoringSnapshotPhase)
+	}
+
 	d.ReconcileState.UpdateElasticsearchState(*resourcesState, observedState())
 	return results
 }
 
+// observeInMaintenance updates the state of a cluster in maintenance mode from its existing resources, without
+// reconciling them. The cluster is observed with the existing credentials and certificates, which are neither rotated
+// nor updated until the maintenance mode ends.
+func (d *defaultDriver) observeInMaintenance(ctx context.Context) *reconciler.Results {
+	results := reconciler.NewResult(ctx)
+	log.Info("Cluster in maintenance mode, skipping changes", "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+
+	resourcesState, err := reconcile.NewResourcesStateFromAPI(d.Client, d.ES)
+	if err != nil {
+		return results.WithError(err)
+	}
+	observedState, _ := d.Observers.LastState(k8s.ExtractNamespacedName(&d.ES))
+	esClient, err := user.NewControllerUserClient(ctx, d.Client, d.OperatorParameters.Dialer, d.ES, d.OperatorParameters.DryRun)
+	if err != nil {
+		// the credentials or the certificates may not exist yet, report the last observed state
+		log.Info("Could not create an Elasticsearch client for a cluster in maintenance mode", "err", err, "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+	} else {
+		observedState = d.Observers.ObservedStateResolver(d.ES, esClient)()
+	}
+	d.ReconcileState.UpdateElasticsearchState(*resourcesState, observedState)
+	return results
+}
+
 // newElasticsearchClient creates a new Elasticsearch HTTP client for this cluster using the provided user
 func (d *defaultDriver) newElasticsearchClient(
 	state *reconcile.ResourcesState,
@@ -322,9 +433,21 @@ func (d *defaultDriver) newElasticsearchClient(
 		v,
 		caCerts,
 		esclient.Timeout(d.ES),
+		d.OperatorParameters.DryRun,
 	)
 }
 
+// newCachingElasticsearchClient creates a new Elasticsearch HTTP client for this cluster using the provided user,
+// serving the cluster state from the cache shared with other controllers.
+func (d *defaultDriver) newCachingElasticsearchClient(
+	state *reconcile.ResourcesState,
+	user esclient.BasicAuth,
+	v version.Version,
+	caCerts []*x509.Certificate,
+) esclient.Client {
+	return esclient.SharedStateCache.Wrap(k8s.ExtractNamespacedName(&d.ES), d.newElasticsearchClient(state, user, v, caCerts))
+}
+
 // warnUnsupportedDistro sends an event of type warning if the Elasticsearch Docker image is not a supported
 // distribution by looking at if the prepare fs init container terminated with the UnsupportedDistro exit code.
 func warnUnsupportedDistro(pods []corev1.Pod, recorder *events.Recorder) {
@@ -339,3 +462,47 @@ func warnUnsupportedDistro(pods []corev1.Pod, recorder *events.Recorder) {
 		}
 	}
 }
+
+// newKeystoreResources returns the resources needed to create the keystore of the cluster, populated from the secure
+// settings of the Elasticsearch resource, of the SnapshotRepositories referencing it, of the StackConfigPolicy applied
+// to it, and from the API keys of its remote clusters. The keystore Secret is written through server-side apply if
+// serverSideApply is true.
+func newKeystoreResources(r commondriver.Interface, es esv1.Elasticsearch, serverSideApply bool) (*keystore.Resources, error) {
+	repositoriesSecureSettings, err := keystore.ExistingSecretSources(r.K8sClient(), es.Namespace, esv1.SnapshotCredentialsSecretName(es.Name))
+	if err != nil {
+		return nil, err
+	}
+	remoteClustersSecureSettings, err := remotecluster.SecureSettings(r.K8sClient(), es)
+	if err != nil {
+		return nil, err
+	}
+	policySecureSettings, err := keystore.ExistingSecretSources(r.K8sClient(), es.Namespace, esv1.PolicySecureSettingsSecretName(es.Name))
+	if err != nil {
+		return nil, err
+	}
+	if len(repositoriesSecureSettings) > 0 || len(remoteClustersSecureSettings) > 0 || len(policySecureSettings) > 0 {
+		secureSettings := make([]commonv1.SecretSource, 0,
+			len(es.Spec.SecureSettings)+len(repositoriesSecureSettings)+len(remoteClustersSecureSettings)+len(policySecureSettings))
+		secureSettings = append(secureSettings, es.Spec.SecureSettings...)
+		secureSettings = append(secureSettings, repositoriesSecureSettings...)
+		secureSettings = append(secureSettings, remoteClustersSecureSettings...)
+		es.Spec.SecureSettings = append(secureSettings, policySecureSettings...)
+	}
+	resources, err := keystore.NewResources(
+		r,
+		&es,
+		esv1.ESNamer,
+		label.NewLabels(k8s.ExtractNamespacedName(&es)),
+		initcontainer.KeystoreParams,
+		serverSideApply,
+	)
+	if err != nil || resources == nil || len(remoteClustersSecureSettings) == 0 {
+		return resources, err
+	}
+	// rotated remote cluster API keys are picked up by the nodes when they restart, rather than restarting them
+	resources.Version, err = remotecluster.SecureSettingsVersion(r.K8sClient(), es)
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
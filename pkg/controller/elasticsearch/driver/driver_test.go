@@ -0,0 +1,119 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_defaultDriver_Reconcile_maintenance(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "es",
+			Namespace:   "ns",
+			Annotations: map[string]string{esv1.MaintenanceAnnotation: "true"},
+		},
+		Spec: esv1.ElasticsearchSpec{Version: "8.6.0"},
+	}
+	// the cluster is already running
+	c := k8s.NewFakeClient(&es, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: esv1.HTTPService("es")}})
+	d := &defaultDriver{DefaultDriverParameters: DefaultDriverParameters{
+		ES:             es,
+		Client:         c,
+		Version:        version.MustParse(es.Spec.Version),
+		ReconcileState: reconcile.MustNewState(es),
+		Observers:      observer.NewManager(observer.Settings{}),
+	}}
+
+	results := d.Reconcile(context.Background())
+	_, err := results.Aggregate()
+	require.NoError(t, err)
+
+	// neither the certificates, the users nor the services are reconciled
+	var secrets corev1.SecretList
+	require.NoError(t, c.List(context.Background(), &secrets))
+	require.Empty(t, secrets.Items)
+	var services corev1.ServiceList
+	require.NoError(t, c.List(context.Background(), &services))
+	require.Len(t, services.Items, 1)
+}
+
+func Test_withESError(t *testing.T) {
+	// requests not sent in dry-run mode requeue the reconciliation without failing it
+	results := withESError(&reconciler.Results{}, fmt.Errorf("while updating: %w", &esclient.DryRunError{Method: "PUT", Path: "/_cluster/settings"}))
+	result, err := results.Aggregate()
+	require.NoError(t, err)
+	require.True(t, result.Requeue)
+
+	// other errors are reported
+	results = withESError(&reconciler.Results{}, errors.New("connection refused"))
+	require.True(t, results.HasError())
+}
+
+func Test_promotePendingPasswords(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	secretKey := types.NamespacedName{Namespace: "ns", Name: esv1.InternalUsersSecret("es")}
+	c := k8s.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+		Data: map[string][]byte{
+			user.ControllerUserName:              []byte("current"),
+			user.ControllerUserName + ".pending": []byte("pending"),
+		},
+	})
+	// the file realm loaded by Elasticsearch
+	realm := map[string]string{user.ControllerUserName: "current"}
+	authenticate := func(u esclient.BasicAuth) error {
+		if realm[u.Name] != u.Password {
+			return errors.New("401 Unauthorized")
+		}
+		return nil
+	}
+
+	// the realm holding the pending password is not reloaded yet: the current password is used and the
+	// reconciliation is requeued shortly
+	results := &reconciler.Results{}
+	controllerUser, err := promotePendingPasswords(c, es, authenticate, results)
+	require.NoError(t, err)
+	require.Equal(t, esclient.BasicAuth{Name: user.ControllerUserName, Password: "current"}, controllerUser)
+	require.NoError(t, authenticate(controllerUser))
+	result, err := results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, rotationPendingRequeue, result)
+	require.Less(t, int64(result.RequeueAfter), int64(defaultRequeue.RequeueAfter))
+
+	// Elasticsearch reloads the realm: the current password stops working until the requeued reconciliation
+	realm[user.ControllerUserName] = "pending"
+	require.Error(t, authenticate(controllerUser))
+
+	// the requeued reconciliation promotes the pending password and uses it
+	results = &reconciler.Results{}
+	controllerUser, err = promotePendingPasswords(c, es, authenticate, results)
+	require.NoError(t, err)
+	require.Equal(t, esclient.BasicAuth{Name: user.ControllerUserName, Password: "pending"}, controllerUser)
+	require.NoError(t, authenticate(controllerUser))
+	result, err = results.Aggregate()
+	require.NoError(t, err)
+	require.False(t, result.Requeue)
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	require.Equal(t, map[string][]byte{user.ControllerUserName: []byte("pending")}, secret.Data)
+}
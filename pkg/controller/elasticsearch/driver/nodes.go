@@ -8,11 +8,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"go.elastic.co/apm"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
@@ -59,6 +61,16 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithResult(defaultRequeue)
 	}
 
+	// Do not update the StatefulSets to a new major version if the cluster is not ready for it: Pods created or
+	// restarted in the meantime would otherwise already run the new major version.
+	proceed, err := d.preUpgradeChecksPass(ctx, esClient, resourcesState.CurrentPods)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if !proceed {
+		return results.WithResult(defaultRequeue)
+	}
+
 	// recreate any StatefulSet that needs to account for PVC expansion
 	recreations, err := recreateStatefulSets(d.K8sClient(), d.ES)
 	if err != nil {
@@ -79,7 +91,13 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithError(err)
 	}
 
-	expectedResources, err := nodespec.BuildExpectedResources(d.Client, d.ES, keystoreResources, actualStatefulSets, d.OperatorParameters.IPFamily, d.OperatorParameters.SetDefaultSecurityContext)
+	expectedResources, err := nodespec.BuildExpectedResources(d.Client, d.ES, keystoreResources, actualStatefulSets, d.OperatorParameters.IPFamily,
+		nodespec.Options{
+			SetDefaultSecurityContext: d.OperatorParameters.SetDefaultSecurityContext,
+			DefaultTopologySpread:     d.OperatorParameters.DefaultTopologySpread,
+			PluginsMirror:             d.OperatorParameters.PluginsMirror,
+			RestrictedSecurityContext: d.OperatorParameters.RestrictedSecurityContext,
+		})
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -94,6 +112,7 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		esState:              esState,
 		expectations:         d.Expectations,
 		validateStorageClass: d.OperatorParameters.ValidateStorageClass,
+		serverSideApply:      d.OperatorParameters.ServerSideApply,
 	}
 	upscaleResults, err := HandleUpscaleAndSpecChanges(upscaleCtx, actualStatefulSets, expectedResources)
 	if err != nil {
@@ -103,8 +122,16 @@ func (d *defaultDriver) reconcileNodeSpecs(
 			// An error has been detected in one of the pod templates, let's update the phase to "invalid"
 			reconcileState.UpdateElasticsearchInvalid(err)
 		}
+		var volumeExpansionErr *VolumeExpansionError
+		if errors.As(err, &volumeExpansionErr) {
+			reconcileState.UpdateVolumeExpansion(esv1.VolumeExpansionErrorReason, volumeExpansionErr.Error())
+		}
 		return results.WithError(err)
 	}
+	if updateVolumeExpansion(reconcileState, upscaleResults.VolumeExpansion) {
+		// check again later for the volumes to be resized
+		results.WithResult(defaultRequeue)
+	}
 	if upscaleResults.Requeue {
 		return results.WithResult(defaultRequeue)
 	}
@@ -148,7 +175,7 @@ func (d *defaultDriver) reconcileNodeSpecs(
 	// Maybe update Zen1 minimum master nodes through the API, corresponding to the current nodes we have.
 	requeue, err := zen1.UpdateMinimumMasterNodes(ctx, d.Client, d.ES, esClient, actualStatefulSets)
 	if err != nil {
-		return results.WithError(err)
+		return withESError(results, err)
 	}
 	if requeue {
 		results.WithResult(defaultRequeue)
@@ -162,10 +189,11 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		results.WithResult(defaultRequeue)
 	}
 	// Maybe clear zen2 voting config exclusions.
-	requeue, err = zen2.ClearVotingConfigExclusions(ctx, d.ES, d.Client, esClient, actualStatefulSets)
+	excludedNodes, requeue, err := zen2.ClearVotingConfigExclusions(ctx, d.ES, d.Client, esClient, actualStatefulSets)
 	if err != nil {
-		return results.WithError(fmt.Errorf("when clearing voting exclusions: %w", err))
+		return withESError(results, fmt.Errorf("when clearing voting exclusions: %w", err))
 	}
+	reconcileState.UpdateVotingConfigExclusions(excludedNodes)
 	if requeue {
 		results.WithResult(defaultRequeue)
 	}
@@ -215,7 +243,7 @@ func (d *defaultDriver) reconcileNodeSpecs(
 	// as of 7.15.2 with node shutdown we do not need transient settings anymore and in fact want to remove any left-overs.
 	if reconciled {
 		if err := d.maybeRemoveTransientSettings(ctx, esClient); err != nil {
-			return results.WithError(err)
+			return withESError(results, err)
 		}
 	}
 
@@ -254,3 +282,25 @@ func Reconciled(expectedStatefulSets, actualStatefulSets sset.StatefulSetList, c
 
 	return true
 }
+
+// updateVolumeExpansion reports the PVCs still being resized in the VolumeExpansionCondition. It returns true if
+// there are some.
+func updateVolumeExpansion(reconcileState *reconcile.State, progress volumeExpansionProgress) bool {
+	switch {
+	case len(progress.FileSystemResizePending) > 0:
+		reconcileState.UpdateVolumeExpansion(
+			esv1.FileSystemResizePendingReason,
+			fmt.Sprintf("Waiting for the file system of volumes to be resized, restarting their Pods after %s: %s",
+				fileSystemResizeGracePeriod, strings.Join(progress.FileSystemResizePending, ", ")),
+		)
+	case len(progress.Resizing) > 0:
+		reconcileState.UpdateVolumeExpansion(
+			esv1.VolumeExpansionInProgressReason,
+			fmt.Sprintf("Resizing volumes: %s", strings.Join(progress.Resizing, ", ")),
+		)
+	default:
+		reconcileState.UpdateVolumeExpansion("", "")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	commondriver "github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/expectations"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// ChangeType is the type of a change the driver would apply to a StatefulSet.
+type ChangeType string
+
+const (
+	ChangeCreate    ChangeType = "create"
+	ChangeUpdate    ChangeType = "update"
+	ChangeUpscale   ChangeType = "upscale"
+	ChangeDownscale ChangeType = "downscale"
+	ChangeDelete    ChangeType = "delete"
+)
+
+// PlannedChange is a change the driver would apply to a StatefulSet of the Elasticsearch cluster.
+type PlannedChange struct {
+	Type        ChangeType
+	StatefulSet string
+	// Details is a human-readable description of the change, including a diff of the Pod template for updates.
+	Details string
+}
+
+// PlanNodesChanges runs the calculation phase of the nodes reconciliation against the resources visible through
+// the given client, and returns the changes the driver would apply to the StatefulSets of the cluster.
+// Nothing is written: the expected resources are computed and compared to the actual ones, in the same way
+// HandleUpscaleAndSpecChanges and the downscale phase would do.
+// Elasticsearch is not reachable in that context: all Running and Ready Pods are considered part of the cluster.
+func PlanNodesChanges(
+	ctx context.Context,
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	ipFamily corev1.IPFamily,
+	opts nodespec.Options,
+) ([]PlannedChange, error) {
+	keystoreResources, err := newKeystoreResources(offlineDriver{client: c}, es, false)
+	if err != nil {
+		return nil, err
+	}
+
+	actualStatefulSets, err := sset.RetrieveActualStatefulSets(c, k8s.ExtractNamespacedName(&es))
+	if err != nil {
+		return nil, err
+	}
+
+	expectedResources, err := nodespec.BuildExpectedResources(c, es, keystoreResources, actualStatefulSets, ipFamily, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	upscaleCtx := upscaleCtx{
+		parentCtx:    ctx,
+		k8sClient:    c,
+		es:           es,
+		esState:      offlineESState{},
+		expectations: expectations.NewExpectations(c),
+	}
+	adjusted, err := adjustResources(upscaleCtx, actualStatefulSets, expectedResources)
+	if err != nil {
+		return nil, fmt.Errorf("adjust resources: %w", err)
+	}
+
+	var changes []PlannedChange
+	for i, res := range adjusted {
+		expected := res.StatefulSet
+		wantedReplicas := sset.GetReplicas(expectedResources[i].StatefulSet)
+		actual, exists := actualStatefulSets.GetByName(expected.Name)
+		if !exists {
+			changes = append(changes, PlannedChange{
+				Type:        ChangeCreate,
+				StatefulSet: expected.Name,
+				Details:     replicasDetails(0, sset.GetReplicas(expected), wantedReplicas),
+			})
+			continue
+		}
+		if specChanged(expected, actual) {
+			changes = append(changes, PlannedChange{
+				Type:        ChangeUpdate,
+				StatefulSet: expected.Name,
+				Details:     templateDiff(expected, actual),
+			})
+		}
+		actualReplicas := sset.GetReplicas(actual)
+		switch {
+		case sset.GetReplicas(expected) > actualReplicas:
+			changes = append(changes, PlannedChange{
+				Type:        ChangeUpscale,
+				StatefulSet: expected.Name,
+				Details:     replicasDetails(actualReplicas, sset.GetReplicas(expected), wantedReplicas),
+			})
+		case wantedReplicas < actualReplicas:
+			changes = append(changes, PlannedChange{
+				Type:        ChangeDownscale,
+				StatefulSet: expected.Name,
+				Details:     fmt.Sprintf("replicas %d -> %d, once data is migrated away from the removed nodes", actualReplicas, wantedReplicas),
+			})
+		}
+	}
+
+	expectedNames := expectedResources.StatefulSets().Names()
+	for _, actual := range actualStatefulSets {
+		if !expectedNames.Has(actual.Name) {
+			changes = append(changes, PlannedChange{
+				Type:        ChangeDelete,
+				StatefulSet: actual.Name,
+				Details:     fmt.Sprintf("replicas %d -> 0, once data is migrated away from the removed nodes", sset.GetReplicas(actual)),
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].StatefulSet < changes[j].StatefulSet
+	})
+	return changes, nil
+}
+
+func replicasDetails(actual, adjusted, wanted int32) string {
+	details := fmt.Sprintf("replicas %d -> %d", actual, adjusted)
+	if adjusted < wanted {
+		details += fmt.Sprintf(" (limited, %d eventually)", wanted)
+	}
+	return details
+}
+
+// specChanged returns true if the spec of the expected StatefulSet differs from the actual one, replicas aside.
+func specChanged(expected, actual appsv1.StatefulSet) bool {
+	withActualReplicas := expected.DeepCopy()
+	nodespec.UpdateReplicas(withActualReplicas, actual.Spec.Replicas)
+	return !sset.EqualTemplateHashLabels(*withActualReplicas, actual)
+}
+
+func templateDiff(expected, actual appsv1.StatefulSet) string {
+	if templateDiff := cmp.Diff(actual.Spec.Template, expected.Spec.Template); templateDiff != "" {
+		return "Pod template changed (-actual +expected):\n" + templateDiff
+	}
+	return "StatefulSet spec changed"
+}
+
+// offlineDriver implements the common driver interface for computations that must not have any side effect.
+type offlineDriver struct {
+	client k8s.Client
+}
+
+var _ commondriver.Interface = offlineDriver{}
+
+func (d offlineDriver) K8sClient() k8s.Client {
+	return d.client
+}
+
+func (d offlineDriver) DynamicWatches() watches.DynamicWatches {
+	return watches.NewDynamicWatches()
+}
+
+func (d offlineDriver) Recorder() record.EventRecorder {
+	return record.NewFakeRecorder(100)
+}
+
+var errOffline = errors.New("Elasticsearch is not reachable in offline mode")
+
+// offlineESState is an ESState for computations that cannot reach Elasticsearch.
+type offlineESState struct{}
+
+var _ ESState = offlineESState{}
+
+func (offlineESState) NodesInCluster(_ []string) (bool, error) {
+	return true, nil
+}
+
+func (offlineESState) NodeNameToID() (map[string]string, error) {
+	return nil, errOffline
+}
+
+func (offlineESState) ShardAllocationsEnabled() (bool, error) {
+	return false, errOffline
+}
+
+func (offlineESState) Health() (esclient.Health, error) {
+	return esclient.Health{}, errOffline
+}
@@ -0,0 +1,153 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/bootstrap"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func planTestES(nodeSets ...esv1.NodeSet) esv1.Elasticsearch {
+	return esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec: esv1.ElasticsearchSpec{
+			Version:  "7.15.2",
+			NodeSets: nodeSets,
+		},
+	}
+}
+
+func planTestNodeSet(name string, count int32) esv1.NodeSet {
+	return esv1.NodeSet{Name: name, Count: count, Config: &commonv1.Config{Data: map[string]interface{}{}}}
+}
+
+// expectedStatefulSet returns the StatefulSet the operator would create for the given NodeSet.
+func expectedStatefulSet(t *testing.T, es esv1.Elasticsearch, name string) appsv1.StatefulSet {
+	t.Helper()
+	c := k8s.NewFakeClient(&es)
+	resources, err := nodespec.BuildExpectedResources(c, es, nil, nil, corev1.IPv4Protocol, nodespec.Options{SetDefaultSecurityContext: true})
+	require.NoError(t, err)
+	for _, res := range resources {
+		if res.StatefulSet.Name == esv1.StatefulSet(es.Name, name) {
+			return res.StatefulSet
+		}
+	}
+	t.Fatalf("no expected StatefulSet for NodeSet %s", name)
+	return appsv1.StatefulSet{}
+}
+
+func TestPlanNodesChanges(t *testing.T) {
+	es := planTestES(planTestNodeSet("default", 3))
+	existing := expectedStatefulSet(t, es, "default")
+
+	orphan := existing.DeepCopy()
+	orphan.Name = esv1.StatefulSet(es.Name, "removed")
+	orphan.Labels = hash.SetTemplateHashLabel(orphan.Labels, orphan.Spec)
+
+	outdated := existing.DeepCopy()
+	outdated.Spec.Template.Labels["outdated"] = "true"
+	outdated.Labels = hash.SetTemplateHashLabel(outdated.Labels, outdated.Spec)
+
+	tests := []struct {
+		name     string
+		es       esv1.Elasticsearch
+		existing []runtime.Object
+		want     []PlannedChange
+	}{
+		{
+			name: "no change",
+			es:   es,
+			existing: []runtime.Object{
+				&existing,
+			},
+			want: nil,
+		},
+		{
+			name:     "create a new StatefulSet",
+			es:       es,
+			existing: nil,
+			want: []PlannedChange{
+				{Type: ChangeCreate, StatefulSet: "es-es-default", Details: "replicas 0 -> 3"},
+			},
+		},
+		{
+			name: "upscale a StatefulSet",
+			es:   planTestES(planTestNodeSet("default", 5)),
+			existing: []runtime.Object{
+				&existing,
+			},
+			want: []PlannedChange{
+				{Type: ChangeUpscale, StatefulSet: "es-es-default", Details: "replicas 3 -> 5"},
+			},
+		},
+		{
+			name: "delete a StatefulSet",
+			es:   es,
+			existing: []runtime.Object{
+				&existing, orphan,
+			},
+			want: []PlannedChange{
+				{Type: ChangeDelete, StatefulSet: "es-es-removed", Details: "replicas 3 -> 0, once data is migrated away from the removed nodes"},
+			},
+		},
+		{
+			name: "downscale a StatefulSet",
+			es:   planTestES(planTestNodeSet("default", 1)),
+			existing: []runtime.Object{
+				&existing,
+			},
+			want: []PlannedChange{
+				{Type: ChangeDownscale, StatefulSet: "es-es-default", Details: "replicas 3 -> 1, once data is migrated away from the removed nodes"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := tt.es
+			c := k8s.NewFakeClient(append(tt.existing, &es)...)
+			got, err := PlanNodesChanges(context.Background(), c, es, corev1.IPv4Protocol, nodespec.Options{SetDefaultSecurityContext: true})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("update a StatefulSet", func(t *testing.T) {
+		es := es
+		c := k8s.NewFakeClient(outdated, &es)
+		got, err := PlanNodesChanges(context.Background(), c, es, corev1.IPv4Protocol, nodespec.Options{SetDefaultSecurityContext: true})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.Equal(t, ChangeUpdate, got[0].Type)
+		require.Equal(t, "es-es-default", got[0].StatefulSet)
+		require.Contains(t, got[0].Details, "outdated")
+	})
+
+	t.Run("master nodes creation is limited", func(t *testing.T) {
+		es := planTestES(planTestNodeSet("default", 3), planTestNodeSet("masters", 3))
+		es.Annotations = map[string]string{bootstrap.ClusterUUIDAnnotationName: "uuid"}
+		masters := existing.DeepCopy()
+		require.Equal(t, "true", masters.Spec.Template.Labels[string(label.NodeTypesMasterLabelName)])
+		c := k8s.NewFakeClient(masters, &es)
+		got, err := PlanNodesChanges(context.Background(), c, es, corev1.IPv4Protocol, nodespec.Options{SetDefaultSecurityContext: true})
+		require.NoError(t, err)
+		require.Equal(t, []PlannedChange{
+			{Type: ChangeCreate, StatefulSet: "es-es-masters", Details: "replicas 0 -> 1 (limited, 3 eventually)"},
+		}, got)
+	})
+}
@@ -7,12 +7,16 @@ package driver
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -21,49 +25,86 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/validation"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
 )
 
 const (
 	// RecreateStatefulSetAnnotationPrefix is used to annotate the Elasticsearch resource
 	// with StatefulSets to recreate. The StatefulSet name is appended to this name.
 	RecreateStatefulSetAnnotationPrefix = "elasticsearch.k8s.elastic.co/recreate-"
+	// fileSystemResizeGracePeriod is the time given to the storage driver to resize the file system of an expanded
+	// volume in use, before the Pod using the volume is restarted for the file system to be resized when mounted again.
+	fileSystemResizeGracePeriod = 5 * time.Minute
 )
 
+// VolumeExpansionError is returned when the storage requests of the volume claims of a StatefulSet cannot be updated.
+type VolumeExpansionError struct {
+	StatefulSet string
+	Err         error
+}
+
+func (e *VolumeExpansionError) Error() string {
+	return fmt.Sprintf("cannot resize the volumes of StatefulSet %s: %v", e.StatefulSet, e.Err)
+}
+
+func (e *VolumeExpansionError) Unwrap() error {
+	return e.Err
+}
+
 // handleVolumeExpansion works around the immutability of VolumeClaimTemplates in StatefulSets by:
 // 1. updating storage requests in PVCs whose storage class supports volume expansion
-// 2. scheduling the StatefulSet for recreation with the new storage spec
-// It returns a boolean indicating whether the StatefulSet needs to be recreated.
+// 2. scheduling the StatefulSets for recreation with the new storage spec
+// It returns the names of the StatefulSets that need to be recreated.
+// The expected StatefulSets are handled sequentially and all the recreations are scheduled with a single update of
+// the Elasticsearch resource, since each of them is stored in an annotation of that resource.
 // Note that some storage drivers also require Pods to be deleted/recreated for the filesystem to be resized
-// (as opposed to a hot resize while the Pod is running). This is left to the responsibility of the user.
+// (as opposed to a hot resize while the Pod is running). Those Pods are restarted by the rolling upgrade.
 // This should be handled differently once supported by the StatefulSet controller: https://github.com/kubernetes/kubernetes/issues/68737.
 func handleVolumeExpansion(
 	k8sClient k8s.Client,
 	es esv1.Elasticsearch,
-	expectedSset appsv1.StatefulSet,
-	actualSset appsv1.StatefulSet,
+	expectedSsets sset.StatefulSetList,
+	actualSsets sset.StatefulSetList,
 	validateStorageClass bool,
-) (bool, error) {
-	// ensure there are no incompatible storage size modification
-	if err := validation.ValidateClaimsStorageUpdate(
-		k8sClient,
-		actualSset.Spec.VolumeClaimTemplates,
-		expectedSset.Spec.VolumeClaimTemplates,
-		validateStorageClass); err != nil {
-		return false, err
-	}
+) (set.StringSet, error) {
+	toRecreate := make([]appsv1.StatefulSet, 0)
+	for _, expectedSset := range expectedSsets {
+		actualSset, exists := actualSsets.GetByName(expectedSset.Name)
+		if !exists {
+			continue
+		}
+		// ensure there are no incompatible storage size modification
+		if err := validation.ValidateClaimsStorageUpdate(
+			k8sClient,
+			actualSset.Spec.VolumeClaimTemplates,
+			expectedSset.Spec.VolumeClaimTemplates,
+			validateStorageClass); err != nil {
+			return nil, &VolumeExpansionError{StatefulSet: actualSset.Name, Err: err}
+		}
 
-	// resize all PVCs that can be resized
-	err := resizePVCs(k8sClient, es, expectedSset, actualSset)
-	if err != nil {
-		return false, err
-	}
+		// resize all PVCs that can be resized
+		if err := resizePVCs(k8sClient, es, expectedSset, actualSset); err != nil {
+			return nil, err
+		}
 
-	// schedule the StatefulSet for recreation if needed
-	if needsRecreate(expectedSset, actualSset) {
-		return true, annotateForRecreation(k8sClient, es, actualSset, expectedSset.Spec.VolumeClaimTemplates)
+		// the StatefulSet must be recreated with the new storage spec
+		if needsRecreate(expectedSset, actualSset) {
+			actualSset.Spec.VolumeClaimTemplates = expectedSset.Spec.VolumeClaimTemplates
+			toRecreate = append(toRecreate, actualSset)
+		}
 	}
 
-	return false, nil
+	if len(toRecreate) == 0 {
+		return nil, nil
+	}
+	if err := annotateForRecreation(k8sClient, es, toRecreate); err != nil {
+		return nil, err
+	}
+	recreate := set.Make()
+	for _, s := range toRecreate {
+		recreate.Add(s.Name)
+	}
+	return recreate, nil
 }
 
 // resizePVCs updates the spec of all existing PVCs whose storage requests can be expanded,
@@ -108,28 +149,122 @@ func resizePVCs(
 	return nil
 }
 
-// annotateForRecreation stores the StatefulSet spec with updated storage requirements
-// in an annotation of the Elasticsearch resource, to be recreated at the next reconciliation.
+// volumeExpansionProgress lists the PVCs of a StatefulSet which are still being resized.
+type volumeExpansionProgress struct {
+	// Resizing PVCs have not reached their requested capacity yet.
+	Resizing []string
+	// FileSystemResizePending PVCs wait for their Pod to be restarted for the file system to be resized.
+	FileSystemResizePending []string
+}
+
+// retrieveVolumeExpansionProgress lists the PVCs of the actual StatefulSet which are still being resized.
+func retrieveVolumeExpansionProgress(k8sClient k8s.Client, actualSset appsv1.StatefulSet) (volumeExpansionProgress, error) {
+	var progress volumeExpansionProgress
+	actualPVCs, err := sset.RetrieveActualPVCs(k8sClient, actualSset)
+	if err != nil {
+		return progress, err
+	}
+	for _, pvcs := range actualPVCs {
+		for _, pvc := range pvcs {
+			_, pending := fileSystemResizePendingSince(pvc)
+			switch {
+			case pending:
+				progress.FileSystemResizePending = append(progress.FileSystemResizePending, pvc.Name)
+			case resizing(pvc):
+				progress.Resizing = append(progress.Resizing, pvc.Name)
+			}
+		}
+	}
+	sort.Strings(progress.Resizing)
+	sort.Strings(progress.FileSystemResizePending)
+	return progress, nil
+}
+
+// podsWaitingForFileSystemResize returns the Pods of the given StatefulSet that must be restarted for the file system
+// of their volumes to be resized, as required by the storage drivers that cannot resize a volume in use. Only the Pods
+// created before the volume was expanded, and for which the file system resize is pending for longer than
+// fileSystemResizeGracePeriod, are returned.
+func podsWaitingForFileSystemResize(k8sClient k8s.Client, statefulSet appsv1.StatefulSet, now time.Time) ([]corev1.Pod, error) {
+	actualPVCs, err := sset.RetrieveActualPVCs(k8sClient, statefulSet)
+	if err != nil {
+		return nil, err
+	}
+	var pods []corev1.Pod
+	for _, podName := range sset.PodNames(statefulSet) {
+		var pendingSince time.Time
+		for claimName, pvcs := range actualPVCs {
+			for _, pvc := range pvcs {
+				if pvc.Name != fmt.Sprintf("%s-%s", claimName, podName) {
+					continue
+				}
+				if since, pending := fileSystemResizePendingSince(pvc); pending && (pendingSince.IsZero() || since.Before(pendingSince)) {
+					pendingSince = since
+				}
+			}
+		}
+		if pendingSince.IsZero() || now.Sub(pendingSince) < fileSystemResizeGracePeriod {
+			continue
+		}
+		var pod corev1.Pod
+		err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: statefulSet.Namespace, Name: podName}, &pod)
+		if apierrors.IsNotFound(err) {
+			// the Pod is being recreated and will mount the volumes again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !pod.CreationTimestamp.Time.Before(pendingSince) {
+			// the Pod was created after the volume expansion, the file system is resized when the volume is mounted
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// fileSystemResizePendingSince returns true and the time of the volume expansion if the volume of the given PVC was
+// expanded but its file system waits for the Pod to be restarted to be resized.
+func fileSystemResizePendingSince(pvc corev1.PersistentVolumeClaim) (time.Time, bool) {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// resizing returns true if the capacity of the given PVC is lower than its storage request.
+func resizing(pvc corev1.PersistentVolumeClaim) bool {
+	capacity, bound := pvc.Status.Capacity[corev1.ResourceStorage]
+	request, requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return bound && requested && capacity.Cmp(request) < 0
+}
+
+// annotateForRecreation stores the StatefulSets specs with updated storage requirements
+// in annotations of the Elasticsearch resource, to be recreated at the next reconciliation.
 func annotateForRecreation(
 	k8sClient k8s.Client,
 	es esv1.Elasticsearch,
-	actualSset appsv1.StatefulSet,
-	expectedClaims []corev1.PersistentVolumeClaim,
+	toRecreate []appsv1.StatefulSet,
 ) error {
-	log.Info("Preparing StatefulSet re-creation to account for PVC resize",
-		"namespace", es.Namespace, "es_name", es.Name, "statefulset_name", actualSset.Name)
-
-	actualSset.Spec.VolumeClaimTemplates = expectedClaims
-	asJSON, err := json.Marshal(actualSset)
-	if err != nil {
-		return err
+	// do not modify the annotations of the given Elasticsearch resource, which may be shared with the caller
+	updated := es.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string, len(toRecreate))
 	}
-	if es.Annotations == nil {
-		es.Annotations = make(map[string]string, 1)
+	for _, statefulSet := range toRecreate {
+		log.Info("Preparing StatefulSet re-creation to account for PVC resize",
+			"namespace", es.Namespace, "es_name", es.Name, "statefulset_name", statefulSet.Name)
+
+		asJSON, err := json.Marshal(statefulSet)
+		if err != nil {
+			return err
+		}
+		updated.Annotations[RecreateStatefulSetAnnotationPrefix+statefulSet.Name] = string(asJSON)
 	}
-	es.Annotations[RecreateStatefulSetAnnotationPrefix+actualSset.Name] = string(asJSON)
 
-	return k8sClient.Update(context.Background(), &es)
+	return k8sClient.Update(context.Background(), updated)
 }
 
 // needsRecreate returns true if the StatefulSet needs to be re-created to account for volume expansion.
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
@@ -192,11 +193,17 @@ func Test_handleVolumeExpansion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			k8sClient := k8s.NewFakeClient(append(tt.runtimeObjs, &es)...)
-			recreate, err := handleVolumeExpansion(k8sClient, es, tt.args.expectedSset, tt.args.actualSset, tt.args.validateStorageClass)
+			recreate, err := handleVolumeExpansion(
+				k8sClient,
+				es,
+				[]appsv1.StatefulSet{tt.args.expectedSset},
+				[]appsv1.StatefulSet{tt.args.actualSset},
+				tt.args.validateStorageClass,
+			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("handleVolumeExpansion() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			require.Equal(t, tt.wantRecreate, recreate)
+			require.Equal(t, tt.wantRecreate, recreate.Has(tt.args.actualSset.Name))
 
 			// all expected PVCs should exist in the apiserver
 			var pvcs corev1.PersistentVolumeClaimList
@@ -276,6 +283,120 @@ func Test_needsRecreate(t *testing.T) {
 	}
 }
 
+func Test_retrieveVolumeExpansionProgress(t *testing.T) {
+	ssetWithReplicas := withClaims(sampleSset, withStorageReq(sampleClaim, "3Gi"))
+	ssetWithReplicas.Spec.Replicas = pointer.Int32Ptr(2)
+	tests := []struct {
+		name         string
+		pvcs         []runtime.Object
+		wantProgress volumeExpansionProgress
+	}{
+		{
+			name: "volumes resized",
+			pvcs: []runtime.Object{resizedPVC(0, "3Gi", time.Time{}), resizedPVC(1, "3Gi", time.Time{})},
+		},
+		{
+			name:         "volumes being resized",
+			pvcs:         []runtime.Object{resizedPVC(0, "1Gi", time.Time{}), resizedPVC(1, "3Gi", time.Time{})},
+			wantProgress: volumeExpansionProgress{Resizing: []string{"sample-claim-sample-sset-0"}},
+		},
+		{
+			name: "file system resize pending",
+			pvcs: []runtime.Object{resizedPVC(0, "3Gi", time.Now()), resizedPVC(1, "1Gi", time.Time{})},
+			wantProgress: volumeExpansionProgress{
+				Resizing:                []string{"sample-claim-sample-sset-1"},
+				FileSystemResizePending: []string{"sample-claim-sample-sset-0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress, err := retrieveVolumeExpansionProgress(k8s.NewFakeClient(tt.pvcs...), ssetWithReplicas)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantProgress, progress)
+		})
+	}
+}
+
+func Test_podsWaitingForFileSystemResize(t *testing.T) {
+	ssetWithReplicas := withClaims(sampleSset, withStorageReq(sampleClaim, "3Gi"))
+	ssetWithReplicas.Spec.Replicas = pointer.Int32Ptr(3)
+	now := time.Now()
+	expandedAt := now.Add(-2 * fileSystemResizeGracePeriod)
+	pod := func(ordinal int, createdAt time.Time) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              fmt.Sprintf("sample-sset-%d", ordinal),
+			CreationTimestamp: metav1.NewTime(createdAt),
+		}}
+	}
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		wantPods []string
+	}{
+		{
+			name: "no file system resize pending",
+			objects: []runtime.Object{
+				pod(0, expandedAt.Add(-time.Hour)), resizedPVC(0, "3Gi", time.Time{}),
+			},
+		},
+		{
+			name: "file system resize pending for less than the grace period: no restart",
+			objects: []runtime.Object{
+				pod(0, expandedAt.Add(-time.Hour)), resizedPVC(0, "3Gi", now.Add(-fileSystemResizeGracePeriod/2)),
+			},
+		},
+		{
+			name: "file system resize pending for longer than the grace period: restart the affected Pods only",
+			objects: []runtime.Object{
+				pod(0, expandedAt.Add(-time.Hour)), resizedPVC(0, "3Gi", expandedAt),
+				pod(1, expandedAt.Add(-time.Hour)), resizedPVC(1, "3Gi", time.Time{}),
+				pod(2, expandedAt.Add(-time.Hour)), resizedPVC(2, "3Gi", expandedAt),
+			},
+			wantPods: []string{"sample-sset-0", "sample-sset-2"},
+		},
+		{
+			name: "Pod already restarted since the expansion: no restart",
+			objects: []runtime.Object{
+				pod(0, expandedAt.Add(time.Minute)), resizedPVC(0, "3Gi", expandedAt),
+			},
+		},
+		{
+			name:    "Pod being recreated: no restart",
+			objects: []runtime.Object{resizedPVC(0, "3Gi", expandedAt)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods, err := podsWaitingForFileSystemResize(k8s.NewFakeClient(tt.objects...), ssetWithReplicas, now)
+			require.NoError(t, err)
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			require.Equal(t, tt.wantPods, names)
+		})
+	}
+}
+
+// resizedPVC returns the PVC of the Pod with the given ordinal in the sample StatefulSet, with a FileSystemResizePending
+// condition since the given time if not zero.
+func resizedPVC(ordinal int, capacity string, fileSystemResizePendingSince time.Time) *corev1.PersistentVolumeClaim {
+	claim := withStorageReq(sampleClaim, "3Gi")
+	claim.Namespace = "ns"
+	claim.Name = fmt.Sprintf("sample-claim-sample-sset-%d", ordinal)
+	claim.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)}
+	if !fileSystemResizePendingSince.IsZero() {
+		claim.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+			Type:               corev1.PersistentVolumeClaimFileSystemResizePending,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(fileSystemResizePendingSince),
+		}}
+	}
+	return &claim
+}
+
 func Test_recreateStatefulSets(t *testing.T) {
 	controllerscheme.SetupScheme()
 	es := func() *esv1.Elasticsearch {
@@ -0,0 +1,54 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/bootstrap"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+// reconcileInitialSnapshotRestore restores the initial snapshot of a new cluster and reports its progress in the
+// SnapshotRestoredCondition. It returns true as long as the restore is not complete, in which case the cluster must
+// not be reported Ready.
+func (d *defaultDriver) reconcileInitialSnapshotRestore(ctx context.Context, esClient esclient.Client, esReachable bool) bool {
+	status, err := bootstrap.ReconcileInitialSnapshotRestore(ctx, d.Client, &d.ES, esClient, esReachable)
+	if esclient.IsDryRun(err) {
+		// the restore was not requested in dry-run mode
+		return true
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Cannot restore snapshot %s: %s", d.ES.Spec.InitialSnapshotRestore.Snapshot, err.Error())
+		// retry later: repository credentials may not be in the keystore yet
+		log.Info("Cannot restore initial snapshot", "namespace", d.ES.Namespace, "es_name", d.ES.Name, "error", err.Error())
+		d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
+		d.ReconcileState.UpdateSnapshotRestored(esv1.SnapshotRestoreErrorReason, msg)
+		return true
+	}
+
+	switch status {
+	case bootstrap.SnapshotRestoreNotRequired:
+		d.ReconcileState.UpdateSnapshotRestored("", "")
+		return false
+	case bootstrap.SnapshotRestoreCompleted:
+		d.ReconcileState.UpdateSnapshotRestored(
+			esv1.SnapshotRestoredReason,
+			fmt.Sprintf("Snapshot %s is restored", d.ES.Spec.InitialSnapshotRestore.Snapshot),
+		)
+		return false
+	default:
+		d.ReconcileState.UpdateSnapshotRestored(
+			esv1.SnapshotRestoreInProgressReason,
+			fmt.Sprintf("Snapshot %s is being restored", d.ES.Spec.InitialSnapshotRestore.Snapshot),
+		)
+		return true
+	}
+}
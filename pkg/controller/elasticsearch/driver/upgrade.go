@@ -6,6 +6,7 @@ package driver
 
 import (
 	"context"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -20,6 +21,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/shutdown"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
 )
 
 func (d *defaultDriver) handleRollingUpgrades(
@@ -50,6 +52,7 @@ func (d *defaultDriver) handleRollingUpgrades(
 	if err != nil {
 		return results.WithError(err)
 	}
+	d.ReconcileState.UpdateUpgradeInProgress(len(podsToUpgrade))
 	// Get the healthy Pods (from a K8S point of view + in the ES cluster)
 	healthyPods, err := healthyPods(d.Client, statefulSets, esState)
 	if err != nil {
@@ -74,6 +77,7 @@ func (d *defaultDriver) handleRollingUpgrades(
 		return results.WithError(err)
 	}
 	numberOfPods := len(currentPods)
+
 	// Maybe upgrade some of the nodes.
 	deletedPods, err := newRollingUpgrade(
 		ctx,
@@ -89,7 +93,7 @@ func (d *defaultDriver) handleRollingUpgrades(
 		numberOfPods,
 	).run()
 	if err != nil {
-		return results.WithError(err)
+		return withESError(results, err)
 	}
 	if len(deletedPods) > 0 {
 		// Some Pods have just been deleted, we don't need to try to enable shards allocation.
@@ -197,33 +201,45 @@ func healthyPods(
 	return healthyPods, nil
 }
 
+// podsToUpgrade returns the Pods that do not run the update revision of their StatefulSet, and the Pods that must be
+// restarted for the file system of their volumes to be resized.
 func podsToUpgrade(
 	client k8s.Client,
 	statefulSets sset.StatefulSetList,
 ) ([]corev1.Pod, error) {
 	var toUpgrade []corev1.Pod
 	for _, statefulSet := range statefulSets {
-		if statefulSet.Status.UpdateRevision == "" {
-			// no upgrade scheduled
-			continue
-		}
-		// Inspect each pod, starting from the highest ordinal, and decrement the idx to allow
-		// pod upgrades to go through, controlled by the StatefulSet controller.
-		for idx := sset.GetReplicas(statefulSet) - 1; idx >= 0; idx-- {
-			// Do we need to upgrade that pod?
-			podName := sset.PodName(statefulSet.Name, idx)
-			podRef := types.NamespacedName{Namespace: statefulSet.Namespace, Name: podName}
-			// retrieve pod to inspect its revision label
-			var pod corev1.Pod
-			err := client.Get(context.Background(), podRef, &pod)
-			if err != nil && !apierrors.IsNotFound(err) {
-				return toUpgrade, err
-			}
-			if apierrors.IsNotFound(err) {
-				// Pod does not exist, continue the loop as the absence will be accounted by the deletion driver
-				continue
+		toUpgradeInSset := set.Make()
+		if statefulSet.Status.UpdateRevision != "" {
+			// Inspect each pod, starting from the highest ordinal, and decrement the idx to allow
+			// pod upgrades to go through, controlled by the StatefulSet controller.
+			for idx := sset.GetReplicas(statefulSet) - 1; idx >= 0; idx-- {
+				// Do we need to upgrade that pod?
+				podName := sset.PodName(statefulSet.Name, idx)
+				podRef := types.NamespacedName{Namespace: statefulSet.Namespace, Name: podName}
+				// retrieve pod to inspect its revision label
+				var pod corev1.Pod
+				err := client.Get(context.Background(), podRef, &pod)
+				if err != nil && !apierrors.IsNotFound(err) {
+					return toUpgrade, err
+				}
+				if apierrors.IsNotFound(err) {
+					// Pod does not exist, continue the loop as the absence will be accounted by the deletion driver
+					continue
+				}
+				if sset.PodRevision(pod) != statefulSet.Status.UpdateRevision {
+					toUpgrade = append(toUpgrade, pod)
+					toUpgradeInSset.Add(pod.Name)
+				}
 			}
-			if sset.PodRevision(pod) != statefulSet.Status.UpdateRevision {
+		}
+		// restart the Pods whose volumes wait for their file system to be resized
+		waitingForResize, err := podsWaitingForFileSystemResize(client, statefulSet, time.Now())
+		if err != nil {
+			return toUpgrade, err
+		}
+		for _, pod := range waitingForResize {
+			if !toUpgradeInSset.Has(pod.Name) {
 				toUpgrade = append(toUpgrade, pod)
 			}
 		}
@@ -273,7 +289,7 @@ func (d *defaultDriver) maybeCompleteNodeUpgrades(
 		// this relies on the fact the maybeEnableShardsAllocation checks expectations
 		err := nodeShutdown.Clear(ctx, &esclient.ShutdownComplete)
 		if err != nil {
-			results = results.WithError(err)
+			results = withESError(results, err)
 		}
 	}
 	return results
@@ -328,7 +344,7 @@ func (d *defaultDriver) maybeEnableShardsAllocation(
 
 	log.Info("Enabling shards allocation", "namespace", d.ES.Namespace, "es_name", d.ES.Name)
 	if err := esClient.EnableShardAllocation(ctx); err != nil {
-		return results.WithError(err)
+		return withESError(results, err)
 	}
 	return results
 }
@@ -345,6 +361,10 @@ func (ctx *rollingUpgradeCtx) readyToDelete(pod corev1.Pod) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if response.Status == esclient.ShutdownStalled {
+		// a restart can stall too, for example if a node holds the only copy of a shard: bubble up via status
+		ctx.reconcileState.UpdateNodeShutdownStalled(pod.Name, response.Explanation)
+	}
 	return response.Status == esclient.ShutdownComplete, nil
 }
 
@@ -0,0 +1,74 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+)
+
+// preUpgradeChecksPass returns true if the StatefulSets can be updated to the expected version.
+// Before the StatefulSets of a cluster whose Pods all run a lower major version are updated to the new major version,
+// the deprecation info API is queried and the upgrade is blocked as long as critical issues are reported, unless the
+// checks are skipped through the SkipPreUpgradeChecksAnnotation. The outcome is reported in the UpgradeBlockedCondition.
+func (d *defaultDriver) preUpgradeChecksPass(ctx context.Context, esClient esclient.Client, currentPods []corev1.Pod) (bool, error) {
+	starting, err := majorVersionUpgradeStarting(currentPods, d.Version)
+	if err != nil {
+		return false, err
+	}
+	if !starting || d.ES.SkipPreUpgradeChecks() {
+		d.ReconcileState.UpdateUpgradeBlocked("", "")
+		return true, nil
+	}
+
+	deprecations, err := esClient.GetDeprecations(ctx)
+	if err != nil {
+		d.ReconcileState.UpdateUpgradeBlocked(
+			esv1.PreUpgradeChecksErrorReason,
+			fmt.Sprintf("Cannot retrieve deprecation info to upgrade to %s: %s", d.Version, err.Error()),
+		)
+		// retry later: the cluster may not be reachable yet
+		log.Info("Cannot run pre-upgrade checks", "namespace", d.ES.Namespace, "es_name", d.ES.Name, "error", err.Error())
+		return false, nil
+	}
+	blockers := deprecations.CriticalMessages()
+	if len(blockers) == 0 {
+		d.ReconcileState.UpdateUpgradeBlocked("", "")
+		return true, nil
+	}
+
+	msg := fmt.Sprintf(
+		"Upgrade to %s blocked by %d critical deprecation issues, resolve them or set the %s annotation to true: %s",
+		d.Version, len(blockers), esv1.SkipPreUpgradeChecksAnnotation, strings.Join(blockers, "; "),
+	)
+	log.Info("Upgrade blocked by pre-upgrade checks", "namespace", d.ES.Namespace, "es_name", d.ES.Name, "critical_issues", len(blockers))
+	d.ReconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
+	d.ReconcileState.UpdateUpgradeBlocked(esv1.CriticalDeprecationsReason, msg)
+	return false, nil
+}
+
+// majorVersionUpgradeStarting returns true if all the given Pods run a major version lower than the expected one,
+// meaning that no node has been upgraded to the expected major version yet.
+func majorVersionUpgradeStarting(currentPods []corev1.Pod, expected version.Version) (bool, error) {
+	for _, pod := range currentPods {
+		v, err := version.FromLabels(pod.Labels, label.VersionLabelName)
+		if err != nil {
+			return false, err
+		}
+		if v.Major >= expected.Major {
+			return false, nil
+		}
+	}
+	return len(currentPods) > 0, nil
+}
@@ -0,0 +1,139 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+)
+
+type fakeDeprecationsClient struct {
+	esclient.Client
+	deprecations esclient.Deprecations
+	err          error
+	called       bool
+}
+
+func (f *fakeDeprecationsClient) GetDeprecations(_ context.Context) (esclient.Deprecations, error) {
+	f.called = true
+	return f.deprecations, f.err
+}
+
+func Test_defaultDriver_preUpgradeChecksPass(t *testing.T) {
+	v7Pods := []corev1.Pod{
+		sset.TestPod{Name: "pod1", Version: "7.17.0"}.Build(),
+		sset.TestPod{Name: "pod2", Version: "7.17.0"}.Build(),
+	}
+	critical := esclient.Deprecations{
+		ClusterSettings: []esclient.Deprecation{{Level: esclient.CriticalDeprecationLevel, Message: "deprecated setting"}},
+	}
+	warning := esclient.Deprecations{
+		ClusterSettings: []esclient.Deprecation{{Level: "warning", Message: "deprecated setting"}},
+	}
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		targetVersion   string
+		currentPods     []corev1.Pod
+		esClient        *fakeDeprecationsClient
+		wantPass        bool
+		wantCalled      bool
+		wantBlockReason string
+	}{
+		{
+			name:          "cluster creation",
+			targetVersion: "8.0.0",
+			esClient:      &fakeDeprecationsClient{deprecations: critical},
+			wantPass:      true,
+		},
+		{
+			name:          "minor version upgrade",
+			targetVersion: "7.17.1",
+			currentPods:   v7Pods,
+			esClient:      &fakeDeprecationsClient{deprecations: critical},
+			wantPass:      true,
+		},
+		{
+			name:          "major version upgrade already started",
+			targetVersion: "8.0.0",
+			currentPods:   []corev1.Pod{v7Pods[0], sset.TestPod{Name: "pod2", Version: "8.0.0"}.Build()},
+			esClient:      &fakeDeprecationsClient{deprecations: critical},
+			wantPass:      true,
+		},
+		{
+			name:          "major version upgrade without critical issues",
+			targetVersion: "8.0.0",
+			currentPods:   v7Pods,
+			esClient:      &fakeDeprecationsClient{deprecations: warning},
+			wantPass:      true,
+			wantCalled:    true,
+		},
+		{
+			name:            "major version upgrade with critical issues",
+			targetVersion:   "8.0.0",
+			currentPods:     v7Pods,
+			esClient:        &fakeDeprecationsClient{deprecations: critical},
+			wantPass:        false,
+			wantCalled:      true,
+			wantBlockReason: esv1.CriticalDeprecationsReason,
+		},
+		{
+			name:          "major version upgrade with critical issues, checks skipped",
+			annotations:   map[string]string{esv1.SkipPreUpgradeChecksAnnotation: "true"},
+			targetVersion: "8.0.0",
+			currentPods:   v7Pods,
+			esClient:      &fakeDeprecationsClient{deprecations: critical},
+			wantPass:      true,
+		},
+		{
+			name:            "deprecation info cannot be retrieved",
+			targetVersion:   "8.0.0",
+			currentPods:     v7Pods,
+			esClient:        &fakeDeprecationsClient{err: errors.New("timeout")},
+			wantPass:        false,
+			wantCalled:      true,
+			wantBlockReason: esv1.PreUpgradeChecksErrorReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns", Annotations: tt.annotations}}
+			d := &defaultDriver{DefaultDriverParameters: DefaultDriverParameters{
+				ES:             es,
+				Version:        version.MustParse(tt.targetVersion),
+				ReconcileState: reconcile.MustNewState(es),
+			}}
+			pass, err := d.preUpgradeChecksPass(context.Background(), tt.esClient, tt.currentPods)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPass, pass)
+			require.Equal(t, tt.wantCalled, tt.esClient.called)
+
+			_, updated := d.ReconcileState.Apply()
+			var conditions []metav1.Condition
+			if updated != nil {
+				conditions = updated.Status.Conditions
+			}
+			condition := meta.FindStatusCondition(conditions, esv1.UpgradeBlockedCondition)
+			if tt.wantBlockReason == "" {
+				require.Nil(t, condition)
+				return
+			}
+			require.NotNil(t, condition)
+			require.Equal(t, tt.wantBlockReason, condition.Reason)
+		})
+	}
+}
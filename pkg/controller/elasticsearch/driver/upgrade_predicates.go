@@ -385,6 +385,28 @@ var predicates = [...]Predicate{
 			return true, nil
 		},
 	},
+	{
+		// If a maximum number of unavailable Pods per tier is set, only restart nodes of the same tier in parallel
+		name: "restart_nodes_of_a_single_tier_in_parallel",
+		fn: func(
+			context PredicateContext,
+			candidate corev1.Pod,
+			deletedPods []corev1.Pod,
+			maxUnavailableReached bool,
+		) (b bool, e error) {
+			maxUnavailablePerTier := context.es.Spec.UpdateStrategy.ChangeBudget.MaxUnavailablePerTier
+			if maxUnavailablePerTier == nil || len(deletedPods) == 0 {
+				return true, nil
+			}
+			tier := label.NodeTier(candidate)
+			for _, deletedPod := range deletedPods {
+				if label.NodeTier(deletedPod) != tier {
+					return false, nil
+				}
+			}
+			return len(deletedPods) < int(*maxUnavailablePerTier), nil
+		},
+	},
 	{
 		// We should not delete 2 Pods with the same shards
 		name: "do_not_delete_pods_with_same_shards",
@@ -20,6 +20,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/shutdown"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 )
 
 // These tests are focused on "type changes", i.e. when the type of a nodeSet is changed.
@@ -183,8 +184,10 @@ func TestUpgradePodsDeletion_Delete(t *testing.T) {
 		ES              esv1.Elasticsearch
 		health          client.Health
 		maxUnavailable  int
-		podFilter       filter
-		esVersion       string
+		// maxUnavailablePerTier is optional
+		maxUnavailablePerTier *int32
+		podFilter             filter
+		esVersion             string
 	}
 	tests := []struct {
 		name                         string
@@ -212,6 +215,46 @@ func TestUpgradePodsDeletion_Delete(t *testing.T) {
 			wantErr:                      false,
 			wantShardsAllocationDisabled: true,
 		},
+		{
+			name: "maxUnavailablePerTier is set, only restart nodes of the same tier in parallel",
+			fields: fields{
+				esVersion: "7.5.0",
+				upgradeTestPods: newUpgradeTestPods(
+					newTestPod("coord-0").isMaster(false).isData(false).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("coord-1").isMaster(false).isData(false).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("data-0").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("data-1").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("data-2").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+				),
+				maxUnavailable:        5,
+				maxUnavailablePerTier: pointer.Int32(5),
+				shardLister:           migration.NewFakeShardLister(client.Shards{}),
+				health:                client.Health{Status: esv1.ElasticsearchGreenHealth},
+				podFilter:             nothing,
+			},
+			deleted:                      []string{"coord-1", "coord-0"},
+			wantErr:                      false,
+			wantShardsAllocationDisabled: true,
+		},
+		{
+			name: "maxUnavailablePerTier is set, restart at most maxUnavailablePerTier nodes of a tier in parallel",
+			fields: fields{
+				esVersion: "7.5.0",
+				upgradeTestPods: newUpgradeTestPods(
+					newTestPod("data-0").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("data-1").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+					newTestPod("data-2").isMaster(false).isData(true).isHealthy(true).needsUpgrade(true).isInCluster(true),
+				),
+				maxUnavailable:        3,
+				maxUnavailablePerTier: pointer.Int32(2),
+				shardLister:           migration.NewFakeShardLister(client.Shards{}),
+				health:                client.Health{Status: esv1.ElasticsearchGreenHealth},
+				podFilter:             nothing,
+			},
+			deleted:                      []string{"data-2", "data-1"},
+			wantErr:                      false,
+			wantShardsAllocationDisabled: true,
+		},
 		{
 			name: "All Pods are upgraded",
 			fields: fields{
@@ -845,10 +888,12 @@ func TestUpgradePodsDeletion_Delete(t *testing.T) {
 			esClient := &fakeESClient{version: version.MustParse(tt.fields.esVersion), Shutdowns: tt.fields.shutdowns}
 			k8sClient := k8s.NewFakeClient(tt.fields.upgradeTestPods.toRuntimeObjects(tt.fields.esVersion, tt.fields.maxUnavailable, tt.fields.podFilter)...)
 			nodeShutdown := shutdown.NewNodeShutdown(esClient, tt.fields.upgradeTestPods.podNamesToESNodeID(), client.Restart, "", log)
+			es := tt.fields.upgradeTestPods.toES(tt.fields.esVersion, tt.fields.maxUnavailable)
+			es.Spec.UpdateStrategy.ChangeBudget.MaxUnavailablePerTier = tt.fields.maxUnavailablePerTier
 			ctx := rollingUpgradeCtx{
 				parentCtx:       context.Background(),
 				client:          k8sClient,
-				ES:              tt.fields.upgradeTestPods.toES(tt.fields.esVersion, tt.fields.maxUnavailable),
+				ES:              es,
 				statefulSets:    tt.fields.upgradeTestPods.toStatefulSetList(),
 				esClient:        esClient,
 				shardLister:     tt.fields.shardLister,
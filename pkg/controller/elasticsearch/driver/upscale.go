@@ -19,6 +19,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen2"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/parallel"
 )
 
 type upscaleCtx struct {
@@ -28,11 +29,14 @@ type upscaleCtx struct {
 	esState              ESState
 	expectations         *expectations.Expectations
 	validateStorageClass bool
+	serverSideApply      bool
 }
 
 type UpscaleResults struct {
 	ActualStatefulSets sset.StatefulSetList
 	Requeue            bool
+	// VolumeExpansion lists the PVCs still being resized.
+	VolumeExpansion volumeExpansionProgress
 }
 
 // HandleUpscaleAndSpecChanges reconciles expected NodeSet resources.
@@ -56,31 +60,57 @@ func HandleUpscaleAndSpecChanges(
 	if err != nil {
 		return results, fmt.Errorf("adjust resources: %w", err)
 	}
-	// reconcile all resources
-	for _, res := range adjusted {
-		if err := settings.ReconcileConfig(ctx.k8sClient, ctx.es, res.StatefulSet.Name, res.Config); err != nil {
-			return results, fmt.Errorf("reconcile config: %w", err)
+	// resize the volumes and schedule the StatefulSets recreation sequentially, since the recreations are
+	// stored in annotations of the Elasticsearch resource
+	expectedStatefulSets := adjusted.StatefulSets()
+	recreateSsets, err := handleVolumeExpansion(ctx.k8sClient, ctx.es, expectedStatefulSets, actualStatefulSets, ctx.validateStorageClass)
+	if err != nil {
+		return results, fmt.Errorf("handle volume expansion: %w", err)
+	}
+	// reconcile all resources, in parallel since each nodeSet has its own config, service, PVCs and StatefulSet
+	reconciled := make([]*appsv1.StatefulSet, len(adjusted))
+	requeue := make([]bool, len(adjusted))
+	volumeExpansion := make([]volumeExpansionProgress, len(adjusted))
+	err = parallel.ForEach(len(adjusted), nodespec.MaxConcurrentNodeSets, func(i int) error {
+		res := adjusted[i]
+		if err := settings.ReconcileConfig(ctx.k8sClient, ctx.es, res.StatefulSet.Name, res.Config, ctx.serverSideApply); err != nil {
+			return fmt.Errorf("reconcile config: %w", err)
 		}
-		if _, err := common.ReconcileService(ctx.parentCtx, ctx.k8sClient, &res.HeadlessService, &ctx.es); err != nil {
-			return results, fmt.Errorf("reconcile service: %w", err)
+		if _, err := common.ReconcileService(ctx.parentCtx, ctx.k8sClient, &res.HeadlessService, &ctx.es, ctx.serverSideApply); err != nil {
+			return fmt.Errorf("reconcile service: %w", err)
 		}
 		if actualSset, exists := actualStatefulSets.GetByName(res.StatefulSet.Name); exists {
-			recreateSset, err := handleVolumeExpansion(ctx.k8sClient, ctx.es, res.StatefulSet, actualSset, ctx.validateStorageClass)
-			if err != nil {
-				return results, fmt.Errorf("handle volume expansion: %w", err)
-			}
-			if recreateSset {
+			if recreateSsets.Has(res.StatefulSet.Name) {
 				// The StatefulSet is scheduled for recreation: let's requeue before attempting any further spec change.
-				results.Requeue = true
-				continue
+				requeue[i] = true
+				return nil
+			}
+			var err error
+			volumeExpansion[i], err = retrieveVolumeExpansionProgress(ctx.k8sClient, actualSset)
+			if err != nil {
+				return fmt.Errorf("retrieve volume expansion progress: %w", err)
 			}
 		}
-		reconciled, err := sset.ReconcileStatefulSet(ctx.k8sClient, ctx.es, res.StatefulSet, ctx.expectations)
+		reconciledSset, err := sset.ReconcileStatefulSet(ctx.k8sClient, ctx.es, res.StatefulSet, ctx.expectations, ctx.serverSideApply)
 		if err != nil {
-			return results, fmt.Errorf("reconcile StatefulSet: %w", err)
+			return fmt.Errorf("reconcile StatefulSet: %w", err)
+		}
+		reconciled[i] = &reconciledSset
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	for i := range adjusted {
+		results.Requeue = results.Requeue || requeue[i]
+		results.VolumeExpansion.Resizing = append(results.VolumeExpansion.Resizing, volumeExpansion[i].Resizing...)
+		results.VolumeExpansion.FileSystemResizePending = append(
+			results.VolumeExpansion.FileSystemResizePending, volumeExpansion[i].FileSystemResizePending...,
+		)
+		if reconciled[i] != nil {
+			// update actual with the reconciled ones for next steps to work with up-to-date information
+			actualStatefulSets = actualStatefulSets.WithStatefulSet(*reconciled[i])
 		}
-		// update actual with the reconciled ones for next steps to work with up-to-date information
-		actualStatefulSets = actualStatefulSets.WithStatefulSet(reconciled)
 	}
 	results.ActualStatefulSets = actualStatefulSets
 	return results, nil
@@ -262,6 +262,94 @@ func TestHandleUpscaleAndSpecChanges_PVCResize(t *testing.T) {
 	require.Len(t, es.Annotations, 2) // initial master nodes + sset to recreate
 }
 
+func TestHandleUpscaleAndSpecChanges_PVCResizeSeveralNodeSets(t *testing.T) {
+	// the storage of several nodeSets is resized at once
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Annotations: map[string]string{
+			// simulate annotation already set otherwise we get a conflict when es is updated twice
+			// (first for initial master nodes, then for sset recreation)
+			"elasticsearch.k8s.elastic.co/initial-master-nodes": "sset1-0,sset1-1,sset1-2",
+		}},
+		Spec: esv1.ElasticsearchSpec{Version: "7.5.0"},
+	}
+
+	truePtr := true
+	storageClass := storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: "resizeable"},
+		AllowVolumeExpansion: &truePtr,
+	}
+
+	withStorage := func(name string, replicas int32, size string) appsv1.StatefulSet {
+		return appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: pointer.Int32(replicas),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							string(label.NodeTypesMasterLabelName): "true",
+						},
+					},
+				},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-data"},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceStorage: resource.MustParse(size),
+								},
+							},
+							StorageClassName: &storageClass.Name,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// 3 nodeSets with 1Gi storage, all of them resized to 3Gi
+	actualStatefulSets := sset.StatefulSetList{withStorage("sset1", 3, "1Gi"), withStorage("sset2", 2, "1Gi"), withStorage("sset3", 2, "1Gi")}
+	expectedResources := make(nodespec.ResourcesList, 0, len(actualStatefulSets))
+	for _, actual := range actualStatefulSets {
+		expectedResources = append(expectedResources, nodespec.Resources{
+			StatefulSet: withStorage(actual.Name, *actual.Spec.Replicas, "3Gi"),
+			HeadlessService: corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: actual.Name},
+			},
+			Config: settings.CanonicalConfig{},
+		})
+	}
+
+	k8sClient := k8s.NewFakeClient(&es, &storageClass, &actualStatefulSets[0], &actualStatefulSets[1], &actualStatefulSets[2])
+	// retrieve the created es with its resource version set
+	require.NoError(t, k8sClient.Get(context.Background(), k8s.ExtractNamespacedName(&es.ObjectMeta), &es))
+	ctx := upscaleCtx{
+		k8sClient:    k8sClient,
+		es:           es,
+		esState:      nil,
+		expectations: expectations.NewExpectations(k8sClient),
+		parentCtx:    context.Background(),
+	}
+
+	// all StatefulSets should be marked for recreation with a single update, we should requeue
+	res, err := HandleUpscaleAndSpecChanges(ctx, actualStatefulSets, expectedResources)
+	require.NoError(t, err)
+	require.True(t, res.Requeue)
+	// the Elasticsearch resource given to the upscale is not modified
+	require.Len(t, ctx.es.Annotations, 1)
+
+	var updatedES esv1.Elasticsearch
+	require.NoError(t, k8sClient.Get(context.Background(), k8s.ExtractNamespacedName(&es.ObjectMeta), &updatedES))
+	require.Len(t, updatedES.Annotations, 4) // initial master nodes + 3 ssets to recreate
+	toRecreate, err := ssetsToRecreate(updatedES)
+	require.NoError(t, err)
+	for _, expected := range expectedResources {
+		recreated, exists := toRecreate[RecreateStatefulSetAnnotationPrefix+expected.StatefulSet.Name]
+		require.True(t, exists)
+		require.Equal(t, expected.StatefulSet.Spec.VolumeClaimTemplates, recreated.Spec.VolumeClaimTemplates)
+	}
+}
+
 func Test_isReplicaIncrease(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -8,6 +8,7 @@ import (
 	"context"
 	"reflect"
 	"sync/atomic"
+	"time"
 
 	pkgerrors "github.com/pkg/errors"
 	"go.elastic.co/apm"
@@ -37,7 +38,9 @@ import (
 	commonversion "github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/certificates/transport"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/hints"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	esreconcile "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
@@ -46,7 +49,6 @@ import (
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
-	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
 )
 
 const name = "elasticsearch-controller"
@@ -62,6 +64,8 @@ func Add(mgr manager.Manager, params operator.Parameters) error {
 	if err != nil {
 		return err
 	}
+	// reconcile unhealthy and changing clusters first when the queue backs up
+	c = common.WithPriority(c, reconciler.isHighPriority, params.MaxConcurrentReconcilesFor(name))
 	return addWatches(c, reconciler)
 }
 
@@ -72,10 +76,18 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileEl
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(name),
 		licenseChecker: license.NewLicenseChecker(client, params.OperatorNamespace),
-		esObservers:    observer.NewManager(params.Tracer),
+		esObservers: observer.NewManager(observer.Settings{
+			AllocationExplainDelay: params.AllocationExplainDelay,
+			ObservationInterval:    params.ObservationInterval,
+			SlowPollAfter:          params.SlowPollAfter,
+			SlowPollInterval:       params.SlowPollInterval,
+			Tracer:                 params.Tracer,
+		}),
 
 		dynamicWatches: watches.NewDynamicWatches(),
 		expectations:   expectations.NewClustersExpectations(client),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
+		upToDate:       newUpToDateClusters(),
 
 		Parameters: params,
 	}
@@ -149,6 +161,12 @@ type ReconcileElasticsearch struct {
 	// by marking resources updates as expected, and skipping some operations if the cache is not up-to-date.
 	expectations *expectations.ClustersExpectation
 
+	// statusWriter coalesces successive status updates of the same cluster.
+	statusWriter *common.StatusWriter
+
+	// upToDate records the clusters whose last reconciliation left nothing to do.
+	upToDate *upToDateClusters
+
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
 }
@@ -172,6 +190,37 @@ func (r *ReconcileElasticsearch) Reconcile(ctx context.Context, request reconcil
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&es) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+		return reconcile.Result{}, nil
+	}
+
+	// the inputs of the reconciliation are only tracked to skip the unchanged ones, or to detect quiet clusters
+	cluster := k8s.ExtractNamespacedName(&es)
+	trackInputs := r.SkipUnchanged || r.SlowPollAfter > 0
+	var inputsHash string
+	if trackInputs {
+		inputsHash, err = r.reconcileInputsHash(es)
+		if err != nil {
+			return reconcile.Result{}, tracing.CaptureError(ctx, err)
+		}
+	}
+	// skip the reconciliation if nothing changed since the last one, which left nothing to do
+	if r.SkipUnchanged && es.Status.ObservedGeneration == es.Generation && !es.IsMarkedForDeletion() {
+		if upToDate, requeueAfter := r.upToDate.IsUpToDate(cluster, inputsHash); upToDate {
+			log.V(1).Info("Cluster up-to-date, skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+	if trackInputs && r.upToDate.InputsChanged(cluster, inputsHash) {
+		// the cluster is not quiet anymore
+		r.esObservers.MarkChanged(cluster)
+	}
+	r.upToDate.Forget(cluster)
+
+	// Elasticsearch state cached by other controllers may be outdated by the changes that triggered this reconciliation
+	esclient.SharedStateCache.Invalidate(cluster)
+
 	// Remove any previous Finalizers
 	if err := finalizer.RemoveAll(r.Client, &es); err != nil {
 		return reconcile.Result{}, tracing.CaptureError(ctx, err)
@@ -181,18 +230,28 @@ func (r *ReconcileElasticsearch) Reconcile(ctx context.Context, request reconcil
 	if err != nil {
 		return reconcile.Result{}, tracing.CaptureError(ctx, err)
 	}
+	persistedHints, err := hints.Load(ctx, r.Client, es)
+	if err != nil {
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+	state.UpdateOrchestrationHints(persistedHints)
+	start := time.Now()
 	results := r.internalReconcile(ctx, es, state)
 
-	if err := r.annotateResource(ctx, es, state); err != nil {
-		if apierrors.IsConflict(err) {
-			log.V(1).Info("Conflict while updating annotations", "namespace", es.Namespace, "es_name", es.Name)
+	if err := r.persistOrchestrationHints(ctx, es, state); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+			log.V(1).Info("Conflict while persisting orchestration hints", "namespace", es.Namespace, "es_name", es.Name)
 			return reconcile.Result{Requeue: true}, nil
 		}
 		k8s.EmitErrorEvent(r.recorder, err, &es, events.EventReconciliationError, "Reconciliation error: %v", err)
 		return results.WithError(err).Aggregate()
 	}
 
-	err = r.updateStatus(ctx, es, state)
+	state.UpdateElasticsearchReachable(esclient.CircuitBreakerOpen(cluster))
+	state.UpdateMaintenanceMode(es.IsInMaintenance())
+	_, reconcileErr := results.Aggregate()
+	state.UpdateReconciliationComplete(reconcileErr)
+	requeueAfter, err := r.updateStatus(ctx, es, state)
 	if err != nil {
 		if apierrors.IsConflict(err) {
 			log.V(1).Info("Conflict while updating status", "namespace", es.Namespace, "es_name", es.Name)
@@ -200,7 +259,21 @@ func (r *ReconcileElasticsearch) Reconcile(ctx context.Context, request reconcil
 		}
 		k8s.EmitErrorEvent(r.recorder, err, &es, events.EventReconciliationError, "Reconciliation error: %v", err)
 	}
-	return results.WithError(err).Aggregate()
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
+	if resync := r.resyncInterval(es); resync > 0 {
+		results.WithResult(reconcile.Result{RequeueAfter: resync})
+	}
+	result, err := results.WithError(err).Aggregate()
+	recordReconcileMetrics(r.Client, cluster, state.Status(), time.Since(start), err)
+	if trackInputs && err == nil && !result.Requeue {
+		r.upToDate.Set(cluster, inputsHash, result.RequeueAfter)
+	} else {
+		r.upToDate.Forget(cluster)
+	}
+	return result, err
 }
 
 func (r *ReconcileElasticsearch) fetchElasticsearchWithAssociations(ctx context.Context, request reconcile.Request, es *esv1.Elasticsearch) (bool, error) {
@@ -289,7 +362,7 @@ func (r *ReconcileElasticsearch) updateStatus(
 	ctx context.Context,
 	es esv1.Elasticsearch,
 	reconcileState *esreconcile.State,
-) error {
+) (time.Duration, error) {
 	span, _ := apm.StartSpan(ctx, "update_status", tracing.SpanTypeApp)
 	defer span.End()
 
@@ -299,48 +372,51 @@ func (r *ReconcileElasticsearch) updateStatus(
 		r.recorder.Event(&es, evt.EventType, evt.Reason, evt.Message)
 	}
 	if cluster == nil {
-		return nil
+		return 0, nil
 	}
-	log.V(1).Info("Updating status",
+	// phase, health, conditions and observed generation changes are written immediately, other changes can be coalesced
+	immediate := cluster.Status.Phase != es.Status.Phase || cluster.Status.Health != es.Status.Health ||
+		cluster.Status.ObservedGeneration != es.Status.ObservedGeneration ||
+		!reflect.DeepEqual(cluster.Status.Conditions, es.Status.Conditions)
+	requeueAfter, err := r.statusWriter.Write(r.Client, cluster, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
 		"iteration", atomic.LoadUint64(&r.iteration),
 		"namespace", es.Namespace,
 		"es_name", es.Name,
 		"status", cluster.Status,
 	)
-	return common.UpdateStatus(r.Client, cluster)
+	return 0, nil
 }
 
-// annotateResource adds the orchestration hints annotation to the Elasticsearch resource. The purpose of this annotation
-// is to capture additional state about aspects of the operator's orchestration of Elasticsearch resources. Currently,
-// it captures whether transient settings are in use.  Future expansion is possible if deemed necessary.
-func (r *ReconcileElasticsearch) annotateResource(
+// persistOrchestrationHints stores the orchestration hints in a ConfigMap dedicated to the cluster. The purpose of these
+// hints is to capture additional state about aspects of the operator's orchestration of Elasticsearch resources.
+// Currently, they capture whether transient settings are in use. Hints stored by previous operator versions in an
+// annotation of the Elasticsearch resource are migrated to the ConfigMap.
+func (r *ReconcileElasticsearch) persistOrchestrationHints(
 	ctx context.Context,
 	es esv1.Elasticsearch,
 	reconcileState *esreconcile.State,
 ) error {
-	span, _ := apm.StartSpan(ctx, "update_hints_annotations", tracing.SpanTypeApp)
+	span, _ := apm.StartSpan(ctx, "persist_orchestration_hints", tracing.SpanTypeApp)
 	defer span.End()
 
-	// cluster-uuid is a special case of an annotation on the Elasticsearch resource that is not treated here but through
-	// an immediate update due to the risk of data loss. See bootstrap package.
-	newAnnotations, err := reconcileState.OrchestrationHints().AsAnnotation()
-	if err != nil {
-		return err
-	}
-
-	expected := maps.Merge(es.ObjectMeta.DeepCopy().Annotations, newAnnotations)
-	if reflect.DeepEqual(expected, es.Annotations) {
-		log.V(1).Info("Skipping annotation update", "es_name", es.Name, "namespace", es.Namespace)
-		return nil
-	}
-	es.SetAnnotations(expected)
-	return r.Update(ctx, &es)
+	return hints.Persist(ctx, r.Client, es, reconcileState.OrchestrationHints())
 }
 
 // onDelete garbage collect resources when an Elasticsearch cluster is deleted
 func (r *ReconcileElasticsearch) onDelete(es types.NamespacedName) error {
 	r.expectations.RemoveCluster(es)
 	r.esObservers.StopObserving(es)
+	esclient.SharedStateCache.Invalidate(es)
+	esclient.ReleaseHTTPClients(es)
+	esclient.ResetCircuitBreaker(es)
+	r.statusWriter.Forget(es)
+	r.upToDate.Forget(es)
+	transport.ForgetVerifiedCertificates(es)
+	forgetMetrics(es)
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(es))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(certificates.CertificateWatchKey(esv1.ESNamer, es.Name))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(transport.CustomTransportCertsWatchKey(es))
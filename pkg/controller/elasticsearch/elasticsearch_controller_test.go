@@ -0,0 +1,47 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	esreconcile "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileElasticsearch_updateStatus_observedGeneration(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Generation: 1},
+		Status:     esv1.ElasticsearchStatus{ObservedGeneration: 1, Health: esv1.ElasticsearchGreenHealth},
+	}
+	c := k8s.NewFakeClient(&es)
+	r := &ReconcileElasticsearch{
+		Client:       c,
+		recorder:     record.NewFakeRecorder(10),
+		statusWriter: common.NewStatusWriter(time.Hour),
+	}
+	// record a previous status write, so that further updates are coalesced unless they are immediate
+	_, err := r.statusWriter.Write(c, &es, true)
+	require.NoError(t, err)
+
+	// the spec is updated: the new observed generation must be written right away
+	require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&es), &es))
+	es.Generation = 2
+	requeueAfter, err := r.updateStatus(context.Background(), es, esreconcile.MustNewState(es))
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), requeueAfter)
+
+	var updated esv1.Elasticsearch
+	require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&es), &updated))
+	require.Equal(t, int64(2), updated.Status.ObservedGeneration)
+}
@@ -6,6 +6,8 @@ package hints
 
 import "encoding/json"
 
+// OrchestrationsHintsAnnotation is the legacy annotation in which orchestration hints used to be stored on the
+// Elasticsearch resource. Hints are now persisted in a dedicated ConfigMap, see Persist.
 const OrchestrationsHintsAnnotation string = "eck.k8s.elastic.co/orchestration-hints"
 
 // OrchestrationsHints represent hints to the reconciler about use or non-use of certain Elasticsearch feature for
@@ -21,18 +23,6 @@ func (oh OrchestrationsHints) Merge(other OrchestrationsHints) OrchestrationsHin
 	}
 }
 
-// AsAnnotation returns a representation of orchestration hints that can be used as an annotation on the
-// Elasticsearch resource.
-func (oh OrchestrationsHints) AsAnnotation() (map[string]string, error) {
-	bytes, err := json.Marshal(oh)
-	if err != nil {
-		return nil, err
-	}
-	return map[string]string{
-		OrchestrationsHintsAnnotation: string(bytes),
-	}, nil
-}
-
 // NewFromAnnotations creates new orchestration hints from annotation metadata coming from the Elasticsearch resource.
 func NewFromAnnotations(ann map[string]string) (OrchestrationsHints, error) {
 	jsonStr, exists := ann[OrchestrationsHintsAnnotation]
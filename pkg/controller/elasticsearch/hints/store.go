@@ -0,0 +1,129 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package hints
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// ConfigMapKey is the key under which the orchestration hints are stored, as JSON, in the dedicated ConfigMap.
+const ConfigMapKey = "hints.json"
+
+func configMapName(es esv1.Elasticsearch) types.NamespacedName {
+	return types.NamespacedName{Namespace: es.Namespace, Name: esv1.OrchestrationHintsConfigMap(es.Name)}
+}
+
+func (oh OrchestrationsHints) asConfigMapData() (map[string]string, error) {
+	bytes, err := json.Marshal(oh)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{ConfigMapKey: string(bytes)}, nil
+}
+
+func newFromConfigMap(cm corev1.ConfigMap) (OrchestrationsHints, error) {
+	jsonStr, exists := cm.Data[ConfigMapKey]
+	if !exists {
+		return OrchestrationsHints{}, nil
+	}
+	var hs OrchestrationsHints
+	if err := json.Unmarshal([]byte(jsonStr), &hs); err != nil {
+		return OrchestrationsHints{}, err
+	}
+	return hs, nil
+}
+
+// Load returns the orchestration hints of the given cluster. Hints persisted in the dedicated ConfigMap are merged with
+// the hints still stored in the legacy annotation of resources managed by previous versions of the operator.
+func Load(ctx context.Context, c k8s.Client, es esv1.Elasticsearch) (OrchestrationsHints, error) {
+	legacy, err := NewFromAnnotations(es.Annotations)
+	if err != nil {
+		return OrchestrationsHints{}, err
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, configMapName(es), &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return legacy, nil
+		}
+		return OrchestrationsHints{}, err
+	}
+	persisted, err := newFromConfigMap(cm)
+	if err != nil {
+		return OrchestrationsHints{}, err
+	}
+	return persisted.Merge(legacy), nil
+}
+
+// Persist stores the given hints in the ConfigMap dedicated to the cluster, then removes the legacy annotation from the
+// Elasticsearch resource. Hints are only ever added: the ones already persisted are merged with the given ones, and the
+// ConfigMap is updated against the resource version it was read at. A conflict is returned if it has been modified in
+// the meantime, in which case the caller is expected to retry.
+func Persist(ctx context.Context, c k8s.Client, es esv1.Elasticsearch, hs OrchestrationsHints) error {
+	var cm corev1.ConfigMap
+	err := c.Get(ctx, configMapName(es), &cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		data, err := hs.asConfigMapData()
+		if err != nil {
+			return err
+		}
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName(es).Name,
+				Namespace: es.Namespace,
+				Labels:    label.NewLabels(k8s.ExtractNamespacedName(&es)),
+			},
+			Data: data,
+		}
+		if err := controllerutil.SetControllerReference(&es, &cm, scheme.Scheme); err != nil {
+			return err
+		}
+		if err := c.Create(ctx, &cm); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		persisted, err := newFromConfigMap(cm)
+		if err != nil {
+			return err
+		}
+		data, err := persisted.Merge(hs).asConfigMapData()
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(data, cm.Data) {
+			cm.Data = data
+			if err := c.Update(ctx, &cm); err != nil {
+				return err
+			}
+		}
+	}
+	return removeLegacyAnnotation(ctx, c, es)
+}
+
+// removeLegacyAnnotation removes the orchestration hints annotation from the Elasticsearch resource, once the hints it
+// holds have been persisted in the dedicated ConfigMap.
+func removeLegacyAnnotation(ctx context.Context, c k8s.Client, es esv1.Elasticsearch) error {
+	if _, exists := es.Annotations[OrchestrationsHintsAnnotation]; !exists {
+		return nil
+	}
+	updated := es.DeepCopy()
+	delete(updated.Annotations, OrchestrationsHintsAnnotation)
+	return c.Update(ctx, updated)
+}
@@ -0,0 +1,157 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package hints
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func hintsConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es-es-orchestration-hints"},
+		Data:       map[string]string{ConfigMapKey: data},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		objs        []runtime.Object
+		want        OrchestrationsHints
+		wantErr     bool
+	}{
+		{
+			name: "no hints",
+			want: OrchestrationsHints{},
+		},
+		{
+			name:        "legacy annotation only",
+			annotations: map[string]string{OrchestrationsHintsAnnotation: `{"no_transient_settings":true}`},
+			want:        OrchestrationsHints{NoTransientSettings: true},
+		},
+		{
+			name: "ConfigMap only",
+			objs: []runtime.Object{hintsConfigMap(`{"no_transient_settings":true}`)},
+			want: OrchestrationsHints{NoTransientSettings: true},
+		},
+		{
+			name:        "ConfigMap and legacy annotation are merged",
+			annotations: map[string]string{OrchestrationsHintsAnnotation: `{"no_transient_settings":true}`},
+			objs:        []runtime.Object{hintsConfigMap(`{"no_transient_settings":false}`)},
+			want:        OrchestrationsHints{NoTransientSettings: true},
+		},
+		{
+			name:    "invalid ConfigMap content",
+			objs:    []runtime.Object{hintsConfigMap(`{`)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Annotations: tt.annotations}}
+			got, err := Load(context.Background(), k8s.NewFakeClient(tt.objs...), es)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPersist(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		existing    *corev1.ConfigMap
+		hints       OrchestrationsHints
+		want        string
+	}{
+		{
+			name:  "create the ConfigMap",
+			hints: OrchestrationsHints{NoTransientSettings: true},
+			want:  `{"no_transient_settings":true}`,
+		},
+		{
+			name:     "update the ConfigMap",
+			existing: hintsConfigMap(`{"no_transient_settings":false}`),
+			hints:    OrchestrationsHints{NoTransientSettings: true},
+			want:     `{"no_transient_settings":true}`,
+		},
+		{
+			name:     "do not drop persisted hints",
+			existing: hintsConfigMap(`{"no_transient_settings":true}`),
+			hints:    OrchestrationsHints{NoTransientSettings: false},
+			want:     `{"no_transient_settings":true}`,
+		},
+		{
+			name:        "migrate the legacy annotation",
+			annotations: map[string]string{OrchestrationsHintsAnnotation: `{"no_transient_settings":true}`, "other": "value"},
+			hints:       OrchestrationsHints{NoTransientSettings: true},
+			want:        `{"no_transient_settings":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Annotations: tt.annotations}}
+			objs := []runtime.Object{&es}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			c := k8s.NewFakeClient(objs...)
+			// refresh the resource version set by the fake client
+			require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&es), &es))
+
+			require.NoError(t, Persist(context.Background(), c, es, tt.hints))
+
+			var cm corev1.ConfigMap
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "es-es-orchestration-hints"}, &cm))
+			require.Equal(t, tt.want, cm.Data[ConfigMapKey])
+
+			var updated esv1.Elasticsearch
+			require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(&es), &updated))
+			require.NotContains(t, updated.Annotations, OrchestrationsHintsAnnotation)
+			if tt.annotations != nil {
+				require.Equal(t, "value", updated.Annotations["other"])
+			}
+		})
+	}
+}
+
+func TestPersist_Conflict(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	c := k8s.NewFakeClient(&es, hintsConfigMap(`{"no_transient_settings":false}`))
+	// simulate a stale cache: the ConfigMap is updated against an outdated resource version
+	conflicting := conflictingClient{Client: c}
+	err := Persist(context.Background(), conflicting, es, OrchestrationsHints{NoTransientSettings: true})
+	require.True(t, apierrors.IsConflict(err))
+}
+
+// conflictingClient returns a stale copy of the resources it reads.
+type conflictingClient struct {
+	k8s.Client
+}
+
+func (c conflictingClient) Get(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	obj.SetResourceVersion("0")
+	return nil
+}
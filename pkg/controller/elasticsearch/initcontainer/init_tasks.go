@@ -0,0 +1,97 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package initcontainer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+)
+
+const (
+	// InitTaskContainerNamePrefix is the prefix of the names of the containers running the user-defined init tasks.
+	InitTaskContainerNamePrefix = "elastic-internal-task-"
+
+	elasticsearchPluginBin = "/usr/share/elasticsearch/bin/elasticsearch-plugin"
+)
+
+// Task parameters are passed as positional arguments to the scripts below rather than being rendered into them, so
+// that they do not have to be escaped.
+var (
+	installPluginsScript = `set -eu
+for plugin in "$@"; do
+  ` + elasticsearchPluginBin + ` install --batch "$plugin"
+done
+`
+
+	downloadFilesScript = `set -eu
+while [ $# -gt 0 ]; do
+  mkdir -p "$(dirname "$2")"
+  curl --fail --silent --show-error --location --retry 3 --output "$2" "$1"
+  shift 2
+done
+`
+)
+
+// InitTaskContainerName returns the name of the init container running the given task.
+func InitTaskContainerName(task esv1.InitTask) string {
+	return InitTaskContainerNamePrefix + task.Name
+}
+
+// PluginsSource is where the plugins installed by name are downloaded from.
+type PluginsSource struct {
+	// Mirror is the base URL of a mirror serving the plugins with the same layout as
+	// https://artifacts.elastic.co/downloads/elasticsearch-plugins. Plugins are installed by name, from the Elastic
+	// download service, if empty.
+	Mirror string
+	// Version is the Elasticsearch version the plugins are downloaded for.
+	Version string
+}
+
+// location returns the location the given plugin is installed from. Plugin names are resolved against the mirror, if
+// any, while URLs are kept as is.
+func (s PluginsSource) location(plugin string) string {
+	mirror := strings.TrimSuffix(s.Mirror, "/")
+	if mirror == "" || strings.Contains(plugin, "://") {
+		return plugin
+	}
+	return fmt.Sprintf("%s/%s/%s-%s.zip", mirror, plugin, plugin, s.Version)
+}
+
+// NewInitTaskContainers creates an init container for each of the given tasks, in order. The containers inherit the
+// image and volume mounts of the Elasticsearch container, which share the plugins and config directories with it.
+// They are rendered deterministically from the tasks so that Pods are only rotated when the tasks change.
+func NewInitTaskContainers(tasks []esv1.InitTask, plugins PluginsSource) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(tasks))
+	for _, task := range tasks {
+		containers = append(containers, corev1.Container{
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Name:            InitTaskContainerName(task),
+			Command:         initTaskCommand(task, plugins),
+		})
+	}
+	return containers
+}
+
+func initTaskCommand(task esv1.InitTask, plugins PluginsSource) []string {
+	// the first argument following the script is $0
+	if len(task.InstallPlugins) > 0 {
+		command := []string{"bash", "-c", installPluginsScript, task.Name}
+		for _, plugin := range task.InstallPlugins {
+			command = append(command, plugins.location(plugin))
+		}
+		return command
+	}
+	command := []string{"bash", "-c", downloadFilesScript, task.Name}
+	for _, file := range task.DownloadFiles {
+		command = append(command, file.URL, path.Join(esvolume.ConfigVolumeMountPath, file.Path))
+	}
+	return command
+}
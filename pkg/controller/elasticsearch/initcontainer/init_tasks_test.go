@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package initcontainer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+)
+
+func TestNewInitTaskContainers(t *testing.T) {
+	tasks := []esv1.InitTask{
+		{
+			Name:           "plugins",
+			InstallPlugins: []string{"analysis-icu", "https://example.com/my plugin.zip"},
+		},
+		{
+			Name: "synonyms",
+			DownloadFiles: []esv1.FileDownload{
+				{URL: "https://example.com/synonyms.txt", Path: "analysis/synonyms.txt"},
+				{URL: "https://example.com/stopwords.txt", Path: "stopwords.txt"},
+			},
+		},
+	}
+	containers := NewInitTaskContainers(tasks, PluginsSource{})
+	require.Len(t, containers, 2)
+
+	require.Equal(t, "elastic-internal-task-plugins", containers[0].Name)
+	require.Equal(t,
+		[]string{"bash", "-c", installPluginsScript, "plugins", "analysis-icu", "https://example.com/my plugin.zip"},
+		containers[0].Command,
+	)
+
+	require.Equal(t, "elastic-internal-task-synonyms", containers[1].Name)
+	require.Equal(t,
+		[]string{
+			"bash", "-c", downloadFilesScript, "synonyms",
+			"https://example.com/synonyms.txt", "/usr/share/elasticsearch/config/analysis/synonyms.txt",
+			"https://example.com/stopwords.txt", "/usr/share/elasticsearch/config/stopwords.txt",
+		},
+		containers[1].Command,
+	)
+
+	// the same tasks render the same containers, so that Pods are not rotated needlessly
+	require.Equal(t, containers, NewInitTaskContainers(tasks, PluginsSource{}))
+}
+
+func TestNewInitTaskContainers_pluginsMirror(t *testing.T) {
+	tasks := []esv1.InitTask{
+		{
+			Name:           "plugins",
+			InstallPlugins: []string{"analysis-icu", "https://example.com/my-plugin.zip"},
+		},
+	}
+	containers := NewInitTaskContainers(tasks, PluginsSource{Mirror: "https://mirror.example.com/plugins/", Version: "8.6.0"})
+	require.Len(t, containers, 1)
+	// plugin names are resolved against the mirror, URLs are kept as is
+	require.Equal(t,
+		[]string{
+			"bash", "-c", installPluginsScript, "plugins",
+			"https://mirror.example.com/plugins/analysis-icu/analysis-icu-8.6.0.zip", "https://example.com/my-plugin.zip",
+		},
+		containers[0].Command,
+	)
+}
@@ -7,6 +7,7 @@ package initcontainer
 import (
 	corev1 "k8s.io/api/core/v1"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
 )
@@ -23,6 +24,8 @@ func NewInitContainers(
 	transportCertificatesVolume volume.SecretVolume,
 	keystoreResources *keystore.Resources,
 	nodeLabelsAsAnnotations []string,
+	initTasks []esv1.InitTask,
+	plugins PluginsSource,
 ) ([]corev1.Container, error) {
 	var containers []corev1.Container
 	prepareFsContainer, err := NewPrepareFSInitContainer(transportCertificatesVolume, nodeLabelsAsAnnotations)
@@ -35,6 +38,8 @@ func NewInitContainers(
 		containers = append(containers, keystoreResources.InitContainer)
 	}
 
+	containers = append(containers, NewInitTaskContainers(initTasks, plugins)...)
+
 	containers = append(containers, NewSuspendInitContainer())
 
 	return containers, nil
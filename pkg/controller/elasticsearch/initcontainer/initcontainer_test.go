@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
 )
@@ -16,6 +17,7 @@ import (
 func TestNewInitContainers(t *testing.T) {
 	type args struct {
 		keystoreResources *keystore.Resources
+		initTasks         []esv1.InitTask
 	}
 	tests := []struct {
 		name                       string
@@ -36,10 +38,17 @@ func TestNewInitContainers(t *testing.T) {
 			},
 			expectedNumberOfContainers: 2,
 		},
+		{
+			name: "with init tasks",
+			args: args{
+				initTasks: []esv1.InitTask{{Name: "plugins", InstallPlugins: []string{"analysis-icu"}}},
+			},
+			expectedNumberOfContainers: 3,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			containers, err := NewInitContainers(volume.SecretVolume{}, tt.args.keystoreResources, []string{})
+			containers, err := NewInitContainers(volume.SecretVolume{}, tt.args.keystoreResources, []string{}, tt.args.initTasks, PluginsSource{})
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedNumberOfContainers, len(containers))
 		})
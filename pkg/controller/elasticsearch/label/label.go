@@ -5,6 +5,8 @@
 package label
 
 import (
+	"strings"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,6 +15,7 @@ import (
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 )
 
@@ -32,6 +35,9 @@ const (
 	ConfigHashLabelName = "elasticsearch.k8s.elastic.co/config-hash"
 	// SecureSettingsHashLabelName is a label used to store a hash of the Elasticsearch secure settings secret.
 	SecureSettingsHashLabelName = "elasticsearch.k8s.elastic.co/secure-settings-hash"
+	// RestartTriggerHashLabelName is a label used to store a hash of the restart annotations of the Elasticsearch
+	// resource that apply to the Pods of a NodeSet.
+	RestartTriggerHashLabelName = "elasticsearch.k8s.elastic.co/restart-trigger-hash"
 
 	// NodeTypesMasterLabelName is a label set to true on nodes with the master role
 	NodeTypesMasterLabelName common.TrueFalseLabel = "elasticsearch.k8s.elastic.co/node-master"
@@ -51,6 +57,8 @@ const (
 	NodeTypesDataColdLabelName common.TrueFalseLabel = "elasticsearch.k8s.elastic.co/node-data_cold"
 	// NodeTypesDataContentLabelName is a label set to true on nodes with the data_content role.
 	NodeTypesDataContentLabelName common.TrueFalseLabel = "elasticsearch.k8s.elastic.co/node-data_content"
+	// NodeTypesDataFrozenLabelName is a label set to true on nodes with the data_frozen role.
+	NodeTypesDataFrozenLabelName common.TrueFalseLabel = "elasticsearch.k8s.elastic.co/node-data_frozen"
 	// NodeTypesDataHotLabelName is a label set to true on nodes with the data_hot role.
 	NodeTypesDataHotLabelName common.TrueFalseLabel = "elasticsearch.k8s.elastic.co/node-data_hot"
 	// NodeTypesDataWarmLabelName is a label set to true on nodes with the data_warm role.
@@ -58,6 +66,11 @@ const (
 
 	HTTPSchemeLabelName = "elasticsearch.k8s.elastic.co/http-scheme"
 
+	// ServiceTokensLabelName is a label set to true on Pods whose file realm includes the service tokens file, and can
+	// therefore authenticate service account tokens. It is only set once an association relies on service account
+	// tokens. Pods created by previous versions of the operator do not have it.
+	ServiceTokensLabelName = "elasticsearch.k8s.elastic.co/service-tokens"
+
 	// Type represents the Elasticsearch type
 	Type = "elasticsearch"
 )
@@ -67,6 +80,11 @@ func IsMasterNode(pod corev1.Pod) bool {
 	return NodeTypesMasterLabelName.HasValue(true, pod.Labels)
 }
 
+// HasServiceTokens returns true if the pod can authenticate the service account tokens of the file realm.
+func HasServiceTokens(pod corev1.Pod) bool {
+	return pod.Labels[ServiceTokensLabelName] == "true"
+}
+
 // IsMasterNodeSet returns true if the given StatefulSet specifies master nodes.
 func IsMasterNodeSet(statefulSet appsv1.StatefulSet) bool {
 	return NodeTypesMasterLabelName.HasValue(true, statefulSet.Spec.Template.Labels)
@@ -102,6 +120,79 @@ func ExtractVersion(labels map[string]string) (version.Version, error) {
 	return version.FromLabels(labels, VersionLabelName)
 }
 
+// nodeTypesLabelNames are the labels describing the roles of a node.
+var nodeTypesLabelNames = []common.TrueFalseLabel{
+	NodeTypesMasterLabelName,
+	NodeTypesDataLabelName,
+	NodeTypesIngestLabelName,
+	NodeTypesMLLabelName,
+	NodeTypesTransformLabelName,
+	NodeTypesRemoteClusterClientLabelName,
+	NodeTypesVotingOnlyLabelName,
+	NodeTypesDataColdLabelName,
+	NodeTypesDataContentLabelName,
+	NodeTypesDataFrozenLabelName,
+	NodeTypesDataHotLabelName,
+	NodeTypesDataWarmLabelName,
+}
+
+// nodeTypesLabelPrefix is the prefix of the labels describing the roles of a node.
+const nodeTypesLabelPrefix = "elasticsearch.k8s.elastic.co/node-"
+
+// NodeTier returns a key identifying the tier of the given Pod, made of all the nodes with the same roles.
+func NodeTier(pod corev1.Pod) string {
+	return nodeTier(pod.Labels)
+}
+
+// StatefulSetNodeTier returns a key identifying the tier of the Pods of the given StatefulSet.
+func StatefulSetNodeTier(statefulSet appsv1.StatefulSet) string {
+	return nodeTier(statefulSet.Spec.Template.Labels)
+}
+
+func nodeTier(labels map[string]string) string {
+	var roles []string
+	for _, l := range nodeTypesLabelNames {
+		if l.HasValue(true, labels) {
+			roles = append(roles, strings.TrimPrefix(string(l), nodeTypesLabelPrefix))
+		}
+	}
+	return strings.Join(roles, ",")
+}
+
+// HasNodeRole returns true if the given labels of a Pod, or of a StatefulSet Pod template, specify the given role.
+func HasNodeRole(role esv1.NodeRole, labels map[string]string) bool {
+	return common.TrueFalseLabel(nodeTypesLabelPrefix+string(role)).HasValue(true, labels)
+}
+
+// noRestartTrigger is the part of the value of the RestartTriggerHashLabelName label standing for a restart annotation
+// that has never been set.
+const noRestartTrigger = "none"
+
+// RestartTriggerHash returns the value of the RestartTriggerHashLabelName label of the Pods of the given NodeSet, given
+// its previous value, or an empty string if no restart annotation of the Elasticsearch resource ever applied to them.
+// The value is made of a hash of the cluster restart annotation and a hash of the NodeSet restart annotation. Each hash
+// only changes when its annotation is set to a new value: removing the annotations does not restart the Pods.
+func RestartTriggerHash(es esv1.Elasticsearch, nodeSetName string, previous string) string {
+	previousHashes := []string{noRestartTrigger, noRestartTrigger}
+	if parts := strings.Split(previous, "."); len(parts) == 2 {
+		previousHashes = parts
+	}
+	clusterHash := restartTriggerHash(es.Annotations[esv1.RestartAnnotation], previousHashes[0])
+	nodeSetHash := restartTriggerHash(es.Annotations[esv1.NodeSetRestartAnnotationPrefix+nodeSetName], previousHashes[1])
+	if clusterHash == noRestartTrigger && nodeSetHash == noRestartTrigger {
+		return ""
+	}
+	return clusterHash + "." + nodeSetHash
+}
+
+// restartTriggerHash returns a hash of the given restart annotation value, or the previous hash if it is empty.
+func restartTriggerHash(trigger string, previous string) string {
+	if trigger == "" {
+		return previous
+	}
+	return hash.HashObject(trigger)
+}
+
 // NewLabels constructs a new set of labels from an Elasticsearch definition.
 func NewLabels(es types.NamespacedName) map[string]string {
 	return map[string]string{
@@ -145,6 +236,11 @@ func NewPodLabels(
 		NodeTypesDataHotLabelName.Set(nodeRoles.HasRole(esv1.DataHotRole), labels)
 		NodeTypesDataWarmLabelName.Set(nodeRoles.HasRole(esv1.DataWarmRole), labels)
 	}
+	// the data_frozen role was added in 7.12.0. The label is only set on the Pods of NodeSets which list the role in
+	// node.roles, so that the Pod templates of the other NodeSets, and therefore their Pods, are left unchanged.
+	if ver.GTE(version.From(7, 12, 0)) && nodeRoles != nil && nodeRoles.Roles != nil && nodeRoles.HasRole(esv1.DataFrozenRole) {
+		NodeTypesDataFrozenLabelName.Set(true, labels)
+	}
 
 	// config hash label, to rotate pods on config changes
 	labels[ConfigHashLabelName] = configHash
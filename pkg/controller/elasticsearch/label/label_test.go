@@ -200,6 +200,106 @@ func TestNewPodLabels(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "labels post-7.13",
+			args: args{
+				es:       nameFixture,
+				ssetName: "sset",
+				ver:      version.From(7, 13, 0),
+				nodeRoles: &v1.Node{
+					Master:    pointer.BoolPtr(false),
+					Data:      pointer.BoolPtr(true),
+					Ingest:    pointer.BoolPtr(false),
+					ML:        pointer.BoolPtr(false),
+					Transform: pointer.BoolPtr(true),
+				},
+				configHash: "hash",
+				scheme:     "https",
+			},
+			want: map[string]string{
+				ClusterNameLabelName:                          "name",
+				common.TypeLabelName:                          "elasticsearch",
+				VersionLabelName:                              "7.13.0",
+				string(NodeTypesMasterLabelName):              "false",
+				string(NodeTypesDataLabelName):                "true",
+				string(NodeTypesIngestLabelName):              "false",
+				string(NodeTypesMLLabelName):                  "false",
+				string(NodeTypesTransformLabelName):           "true",
+				string(NodeTypesRemoteClusterClientLabelName): "true",
+				string(NodeTypesVotingOnlyLabelName):          "false",
+				string(NodeTypesDataContentLabelName):         "true",
+				string(NodeTypesDataColdLabelName):            "true",
+				string(NodeTypesDataHotLabelName):             "true",
+				string(NodeTypesDataWarmLabelName):            "true",
+				ConfigHashLabelName:                           "hash",
+				HTTPSchemeLabelName:                           "https",
+				StatefulSetNameLabelName:                      "sset",
+			},
+			wantErr: false,
+		},
+		{
+			name: "no frozen tier: no data_frozen label",
+			args: args{
+				es:         nameFixture,
+				ssetName:   "sset",
+				ver:        version.From(7, 12, 0),
+				nodeRoles:  &v1.Node{Roles: []string{"master", "data_hot"}},
+				configHash: "hash",
+				scheme:     "https",
+			},
+			want: map[string]string{
+				ClusterNameLabelName:                          "name",
+				common.TypeLabelName:                          "elasticsearch",
+				VersionLabelName:                              "7.12.0",
+				string(NodeTypesMasterLabelName):              "true",
+				string(NodeTypesDataLabelName):                "false",
+				string(NodeTypesIngestLabelName):              "false",
+				string(NodeTypesMLLabelName):                  "false",
+				string(NodeTypesTransformLabelName):           "false",
+				string(NodeTypesRemoteClusterClientLabelName): "false",
+				string(NodeTypesVotingOnlyLabelName):          "false",
+				string(NodeTypesDataContentLabelName):         "false",
+				string(NodeTypesDataColdLabelName):            "false",
+				string(NodeTypesDataHotLabelName):             "true",
+				string(NodeTypesDataWarmLabelName):            "false",
+				ConfigHashLabelName:                           "hash",
+				HTTPSchemeLabelName:                           "https",
+				StatefulSetNameLabelName:                      "sset",
+			},
+			wantErr: false,
+		},
+		{
+			name: "frozen tier",
+			args: args{
+				es:         nameFixture,
+				ssetName:   "sset",
+				ver:        version.From(7, 12, 0),
+				nodeRoles:  &v1.Node{Roles: []string{"data_frozen"}},
+				configHash: "hash",
+				scheme:     "https",
+			},
+			want: map[string]string{
+				ClusterNameLabelName:                          "name",
+				common.TypeLabelName:                          "elasticsearch",
+				VersionLabelName:                              "7.12.0",
+				string(NodeTypesMasterLabelName):              "false",
+				string(NodeTypesDataLabelName):                "false",
+				string(NodeTypesIngestLabelName):              "false",
+				string(NodeTypesMLLabelName):                  "false",
+				string(NodeTypesTransformLabelName):           "false",
+				string(NodeTypesRemoteClusterClientLabelName): "false",
+				string(NodeTypesVotingOnlyLabelName):          "false",
+				string(NodeTypesDataContentLabelName):         "false",
+				string(NodeTypesDataColdLabelName):            "false",
+				string(NodeTypesDataFrozenLabelName):          "true",
+				string(NodeTypesDataHotLabelName):             "false",
+				string(NodeTypesDataWarmLabelName):            "false",
+				ConfigHashLabelName:                           "hash",
+				HTTPSchemeLabelName:                           "https",
+				StatefulSetNameLabelName:                      "sset",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
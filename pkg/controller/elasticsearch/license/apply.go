@@ -100,6 +100,9 @@ func applyLinkedLicense(
 
 func startBasic(ctx context.Context, updater esclient.LicenseClient) error {
 	_, err := updater.StartBasic(ctx)
+	if esclient.IsDryRun(err) {
+		return nil
+	}
 	if err != nil && esclient.IsForbidden(err) {
 		// ES returns 403 + acknowledged: true (which we don't parse in case of error) if we are already in basic mode
 		return nil
@@ -130,6 +133,9 @@ func updateLicense(
 	}
 
 	response, err := updater.UpdateLicense(ctx, request)
+	if esclient.IsDryRun(err) {
+		return nil
+	}
 	if err != nil {
 		return pkgerrors.Wrap(err, fmt.Sprintf("failed to update license to %s", desired.Type))
 	}
@@ -143,6 +149,9 @@ func updateLicense(
 // Elasticsearch API.
 func startTrial(ctx context.Context, c esclient.LicenseClient, esCluster types.NamespacedName) error {
 	response, err := c.StartTrial(ctx)
+	if esclient.IsDryRun(err) {
+		return nil
+	}
 	if err != nil && esclient.IsForbidden(err) {
 		log.Info("failed to start trial most likely because trial was activated previously",
 			"err", err.Error(),
@@ -0,0 +1,105 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
+)
+
+var (
+	phases = []esv1.ElasticsearchOrchestrationPhase{
+		esv1.ElasticsearchReadyPhase,
+		esv1.ElasticsearchApplyingChangesPhase,
+		esv1.ElasticsearchMigratingDataPhase,
+		esv1.ElasticsearchNodeShutdownStalledPhase,
+		esv1.ElasticsearchResourceInvalid,
+	}
+	healths = []esv1.ElasticsearchHealth{
+		esv1.ElasticsearchRedHealth,
+		esv1.ElasticsearchYellowHealth,
+		esv1.ElasticsearchGreenHealth,
+		esv1.ElasticsearchUnknownHealth,
+	}
+)
+
+func clusterLabels(cluster types.NamespacedName) prometheus.Labels {
+	return prometheus.Labels{metrics.NamespaceLabel: cluster.Namespace, metrics.ESNameLabel: cluster.Name}
+}
+
+// recordReconcileMetrics reports the outcome of a reconciliation of the given cluster, along with its updated status.
+func recordReconcileMetrics(c k8s.Client, cluster types.NamespacedName, status esv1.ElasticsearchStatus, duration time.Duration, reconcileErr error) {
+	labels := clusterLabels(cluster)
+	metrics.ESReconcileDuration.With(labels).Observe(duration.Seconds())
+	metrics.ESAvailableNodes.With(labels).Set(float64(status.AvailableNodes))
+	if reconcileErr != nil {
+		metrics.ESReconcileError.With(labels).Set(1)
+		metrics.ESLastReconcileErrorTimestamp.With(labels).SetToCurrentTime()
+	} else {
+		metrics.ESReconcileError.With(labels).Set(0)
+	}
+
+	for _, phase := range phases {
+		metrics.ESPhase.WithLabelValues(cluster.Namespace, cluster.Name, string(phase)).Set(boolToFloat(status.Phase == phase))
+	}
+	if status.PhaseTransitionTime != nil {
+		metrics.ESPhaseTransitionTimestamp.With(labels).Set(float64(status.PhaseTransitionTime.Unix()))
+	}
+	for _, health := range healths {
+		metrics.ESHealth.WithLabelValues(cluster.Namespace, cluster.Name, string(health)).Set(boolToFloat(status.Health == health))
+	}
+
+	statefulSets, err := sset.RetrieveActualStatefulSets(c, cluster)
+	if err != nil {
+		log.V(1).Info("Failed to retrieve StatefulSets, skipping pending Pod changes metric", "namespace", cluster.Namespace, "es_name", cluster.Name, "error", err)
+		return
+	}
+	metrics.ESPendingPodChanges.With(labels).Set(float64(pendingPodChanges(statefulSets)))
+}
+
+// forgetMetrics removes the metrics reported for a deleted cluster.
+func forgetMetrics(cluster types.NamespacedName) {
+	labels := clusterLabels(cluster)
+	metrics.ESReconcileDuration.Delete(labels)
+	metrics.ESAvailableNodes.Delete(labels)
+	metrics.ESReconcileError.Delete(labels)
+	metrics.ESLastReconcileErrorTimestamp.Delete(labels)
+	metrics.ESPendingPodChanges.Delete(labels)
+	metrics.ESPhaseTransitionTimestamp.Delete(labels)
+	for _, phase := range phases {
+		metrics.ESPhase.DeleteLabelValues(cluster.Namespace, cluster.Name, string(phase))
+	}
+	for _, health := range healths {
+		metrics.ESHealth.DeleteLabelValues(cluster.Namespace, cluster.Name, string(health))
+	}
+}
+
+// pendingPodChanges returns the number of Pods to be created or deleted to match the expected replicas, plus the
+// number of existing Pods not running the latest revision of their StatefulSet.
+func pendingPodChanges(statefulSets sset.StatefulSetList) int32 {
+	var pending int32
+	for _, s := range statefulSets {
+		replicasDiff := sset.GetReplicas(s) - s.Status.Replicas
+		if replicasDiff < 0 {
+			replicasDiff = -replicasDiff
+		}
+		pending += replicasDiff + s.Status.Replicas - s.Status.UpdatedReplicas
+	}
+	return pending
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
+)
+
+func statefulSet(name string, replicas, statusReplicas, updatedReplicas int32) appsv1.StatefulSet {
+	return appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			Labels:    map[string]string{label.ClusterNameLabelName: "es"},
+		},
+		Spec:   appsv1.StatefulSetSpec{Replicas: pointer.Int32(replicas)},
+		Status: appsv1.StatefulSetStatus{Replicas: statusReplicas, UpdatedReplicas: updatedReplicas},
+	}
+}
+
+func Test_pendingPodChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		statefulSets sset.StatefulSetList
+		want         int32
+	}{
+		{
+			name: "no StatefulSet",
+		},
+		{
+			name:         "up-to-date StatefulSets",
+			statefulSets: sset.StatefulSetList{statefulSet("a", 3, 3, 3), statefulSet("b", 1, 1, 1)},
+		},
+		{
+			name:         "scale up, scale down and rolling upgrade",
+			statefulSets: sset.StatefulSetList{statefulSet("a", 5, 3, 3), statefulSet("b", 1, 2, 2), statefulSet("c", 3, 3, 1)},
+			want:         2 + 1 + 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, pendingPodChanges(tt.statefulSets))
+		})
+	}
+}
+
+func Test_recordReconcileMetrics(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "ns", Name: "es"}
+	sset := statefulSet("a", 3, 3, 2)
+	c := k8s.NewFakeClient(&sset)
+	defer forgetMetrics(cluster)
+
+	transitionTime := metav1.Unix(1635933600, 0)
+	status := esv1.ElasticsearchStatus{
+		AvailableNodes:      2,
+		Phase:               esv1.ElasticsearchApplyingChangesPhase,
+		PhaseTransitionTime: &transitionTime,
+		Health:              esv1.ElasticsearchYellowHealth,
+	}
+	recordReconcileMetrics(c, cluster, status, time.Second, errors.New("boom"))
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.ESAvailableNodes.WithLabelValues("ns", "es")))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ESPendingPodChanges.WithLabelValues("ns", "es")))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ESReconcileError.WithLabelValues("ns", "es")))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ESPhase.WithLabelValues("ns", "es", "ApplyingChanges")))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ESPhase.WithLabelValues("ns", "es", "Ready")))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ESHealth.WithLabelValues("ns", "es", "yellow")))
+	require.Equal(t, float64(1635933600), testutil.ToFloat64(metrics.ESPhaseTransitionTimestamp.WithLabelValues("ns", "es")))
+
+	status.Phase = esv1.ElasticsearchReadyPhase
+	recordReconcileMetrics(c, cluster, status, time.Second, nil)
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ESReconcileError.WithLabelValues("ns", "es")))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ESPhase.WithLabelValues("ns", "es", "ApplyingChanges")))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ESPhase.WithLabelValues("ns", "es", "Ready")))
+
+	forgetMetrics(cluster)
+	require.Equal(t, 0, testutil.CollectAndCount(metrics.ESPhase))
+	require.Equal(t, 0, testutil.CollectAndCount(metrics.ESAvailableNodes))
+	require.Equal(t, 0, testutil.CollectAndCount(metrics.ESPhaseTransitionTimestamp))
+}
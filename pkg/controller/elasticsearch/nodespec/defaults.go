@@ -5,6 +5,7 @@
 package nodespec
 
 import (
+	"fmt"
 	"path"
 
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
@@ -72,6 +74,52 @@ func DefaultEnvVars(httpCfg commonv1.HTTPConfig, headlessServiceName string) []c
 	)
 }
 
+// zoneAwarenessEnvVars returns the environment variable holding the zone of the k8s node running the Pod if zone
+// awareness is enabled. The zone is read from the Pod annotation copied from the node label by the operator, which is
+// set before the Elasticsearch container starts.
+func zoneAwarenessEnvVars(es esv1.Elasticsearch) []corev1.EnvVar {
+	if es.Spec.ZoneAwareness == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name: settings.EnvZone,
+		ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{
+			APIVersion: "v1",
+			FieldPath:  fmt.Sprintf("metadata.annotations['%s']", es.Spec.ZoneAwareness.TopologyKeyOrDefault()),
+		}},
+	}}
+}
+
+// DefaultTopologySpreadConstraints returns the default topology spread constraints for the pods of a NodeSet: they are
+// preferably spread across zones, then across hosts, so that the nodes of a tier, especially master nodes, do not end
+// up in the same zone.
+func DefaultTopologySpreadConstraints(es esv1.Elasticsearch, statefulSetName string) []corev1.TopologySpreadConstraint {
+	zoneTopologyKey := esv1.DefaultZoneAwarenessTopologyKey
+	if es.Spec.ZoneAwareness != nil {
+		zoneTopologyKey = es.Spec.ZoneAwareness.TopologyKeyOrDefault()
+	}
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			label.ClusterNameLabelName:     es.Name,
+			label.StatefulSetNameLabelName: statefulSetName,
+		},
+	}
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       zoneTopologyKey,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		},
+		{
+			MaxSkew:           1,
+			TopologyKey:       "kubernetes.io/hostname",
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector.DeepCopy(),
+		},
+	}
+}
+
 // DefaultAffinity returns the default affinity for pods in a cluster.
 func DefaultAffinity(esName string) *corev1.Affinity {
 	return &corev1.Affinity{
@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package nodespec
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/jvm"
+)
+
+const (
+	// maxHeapMB is the maximum heap size set by the operator, to stay below the compressed ordinary object pointers
+	// threshold of the JVM.
+	maxHeapMB = 31 * 1024
+)
+
+// minAutoHeapVersion is the first version of Elasticsearch sizing its heap from the memory limits of its container.
+var minAutoHeapVersion = version.MinFor(7, 11, 0)
+
+// heapFromMemoryLimit returns the heap size in megabytes derived from the given container memory limit: half of the
+// limit, up to maxHeapMB.
+func heapFromMemoryLimit(memoryLimitBytes int64) int64 {
+	heapMB := memoryLimitBytes / 2 / 1024 / 1024
+	if heapMB > maxHeapMB {
+		return maxHeapMB
+	}
+	return heapMB
+}
+
+// withJVMHeapFromMemoryLimits sets the heap size of the Elasticsearch container according to its memory limit, unless
+// the user sets it or Elasticsearch sizes it on its own.
+func withJVMHeapFromMemoryLimits(builder *defaults.PodTemplateBuilder, ver version.Version) {
+	if ver.GTE(minAutoHeapVersion) {
+		// rely on Elasticsearch automatic heap sizing
+		return
+	}
+	for c, esContainer := range builder.PodTemplate.Spec.Containers {
+		if esContainer.Name != esv1.ElasticsearchContainerName {
+			continue
+		}
+		memoryLimit, exists := esContainer.Resources.Limits[corev1.ResourceMemory]
+		if !exists || memoryLimit.IsZero() {
+			return
+		}
+		heapOpts := jvm.HeapSizeJavaOpts(heapFromMemoryLimit(memoryLimit.Value()))
+		for e, envVar := range esContainer.Env {
+			if envVar.Name != settings.EnvEsJavaOpts {
+				continue
+			}
+			if strings.Contains(envVar.Value, "-Xmx") || strings.Contains(envVar.Value, "-Xms") {
+				// heap size set by the user
+				return
+			}
+			builder.PodTemplate.Spec.Containers[c].Env[e].Value = strings.TrimSpace(envVar.Value + " " + heapOpts)
+			return
+		}
+		builder.PodTemplate.Spec.Containers[c].Env = append(
+			builder.PodTemplate.Spec.Containers[c].Env,
+			corev1.EnvVar{Name: settings.EnvEsJavaOpts, Value: heapOpts},
+		)
+	}
+}
@@ -5,12 +5,16 @@
 package nodespec
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"hash/fnv"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/container"
@@ -43,6 +47,18 @@ const (
 // podTemplate securityContext to an empty value.
 var minDefaultSecurityContextVersion = version.MinFor(8, 0, 0)
 
+// Options are the operator settings applying to the Pods of all the Elasticsearch clusters.
+type Options struct {
+	// SetDefaultSecurityContext sets a default Pod security context with fsGroup=1000 on Elasticsearch 8.0+ Pods.
+	SetDefaultSecurityContext bool
+	// DefaultTopologySpread sets default topology spread constraints on the Pods whose template does not specify any.
+	DefaultTopologySpread bool
+	// RestrictedSecurityContext renders the Pods with restricted security contexts.
+	RestrictedSecurityContext bool
+	// PluginsMirror is the base URL of the mirror the plugins installed by name are downloaded from, if not empty.
+	PluginsMirror string
+}
+
 // BuildPodTemplateSpec builds a new PodTemplateSpec for an Elasticsearch node.
 func BuildPodTemplateSpec(
 	client k8s.Client,
@@ -50,12 +66,16 @@ func BuildPodTemplateSpec(
 	nodeSet esv1.NodeSet,
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
-	setDefaultSecurityContext bool,
+	opts Options,
 ) (corev1.PodTemplateSpec, error) {
 	downwardAPIVolume := volume.DownwardAPI{}.WithAnnotations(es.HasDownwardNodeLabels())
-	volumes, volumeMounts := buildVolumes(es.Name, nodeSet, keystoreResources, downwardAPIVolume)
+	volumes, volumeMounts := buildVolumes(es.Name, nodeSet, keystoreResources, downwardAPIVolume, es.Spec.Auth.Realms)
 
-	labels, err := buildLabels(es, cfg, nodeSet, keystoreResources)
+	previousRestartTriggerHash, err := actualRestartTriggerHash(client, es, nodeSet)
+	if err != nil {
+		return corev1.PodTemplateSpec{}, err
+	}
+	labels, err := buildLabels(es, cfg, nodeSet, keystoreResources, previousRestartTriggerHash)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
 	}
@@ -67,6 +87,8 @@ func BuildPodTemplateSpec(
 		transportCertificatesVolume(esv1.StatefulSet(es.Name, nodeSet.Name)),
 		keystoreResources,
 		es.DownwardNodeLabels(),
+		es.InitTasksFor(nodeSet),
+		initcontainer.PluginsSource{Mirror: opts.PluginsMirror, Version: es.Spec.Version},
 	)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
@@ -78,13 +100,19 @@ func BuildPodTemplateSpec(
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
 	}
-	if ver.GTE(minDefaultSecurityContextVersion) && setDefaultSecurityContext {
+	if ver.GTE(minDefaultSecurityContextVersion) && opts.SetDefaultSecurityContext {
 		builder = builder.WithPodSecurityContext(corev1.PodSecurityContext{
 			FSGroup: pointer.Int64(defaultFsGroup),
 		})
 	}
 
+	if opts.DefaultTopologySpread {
+		builder = builder.WithTopologySpreadConstraints(DefaultTopologySpreadConstraints(es, esv1.StatefulSet(es.Name, nodeSet.Name))...)
+	}
+
 	headlessServiceName := HeadlessServiceName(esv1.StatefulSet(es.Name, nodeSet.Name))
+	envVars := append(DefaultEnvVars(es.Spec.HTTP, headlessServiceName), zoneAwarenessEnvVars(es)...)
+	envVars = append(envVars, readinessProbeEnvVars(nodeSet)...)
 
 	// build the podTemplate until we have the effective resources configured
 	builder = builder.
@@ -94,9 +122,9 @@ func BuildPodTemplateSpec(
 		WithResources(DefaultResources).
 		WithTerminationGracePeriod(DefaultTerminationGracePeriodSeconds).
 		WithPorts(defaultContainerPorts).
-		WithReadinessProbe(*NewReadinessProbe()).
+		WithReadinessProbe(*NewNodeSetReadinessProbe(nodeSet)).
 		WithAffinity(DefaultAffinity(es.Name)).
-		WithEnv(DefaultEnvVars(es.Spec.HTTP, headlessServiceName)...).
+		WithEnv(envVars...).
 		WithVolumes(volumes...).
 		WithVolumeMounts(volumeMounts...).
 		WithInitContainers(initContainers...).
@@ -108,6 +136,10 @@ func BuildPodTemplateSpec(
 		return corev1.PodTemplateSpec{}, err
 	}
 
+	if es.IsJVMHeapFromMemoryLimits() {
+		withJVMHeapFromMemoryLimits(builder, ver)
+	}
+
 	if ver.LT(version.From(7, 2, 0)) {
 		// mitigate CVE-2021-44228
 		enableLog4JFormatMsgNoLookups(builder)
@@ -117,10 +149,14 @@ func BuildPodTemplateSpec(
 }
 
 func getDefaultContainerPorts(es esv1.Elasticsearch) []corev1.ContainerPort {
-	return []corev1.ContainerPort{
+	ports := []corev1.ContainerPort{
 		{Name: es.Spec.HTTP.Protocol(), ContainerPort: network.HTTPPort, Protocol: corev1.ProtocolTCP},
 		{Name: "transport", ContainerPort: network.TransportPort, Protocol: corev1.ProtocolTCP},
 	}
+	if es.Spec.RemoteClusterServer.Enabled {
+		ports = append(ports, corev1.ContainerPort{Name: "remote-cluster", ContainerPort: network.RemoteClusterPort, Protocol: corev1.ProtocolTCP})
+	}
+	return ports
 }
 
 func transportCertificatesVolume(ssetName string) volume.SecretVolume {
@@ -131,11 +167,26 @@ func transportCertificatesVolume(ssetName string) volume.SecretVolume {
 	)
 }
 
+// actualRestartTriggerHash returns the restart trigger hash of the Pod template of the existing StatefulSet of the given
+// NodeSet, or an empty string if it does not exist.
+func actualRestartTriggerHash(client k8s.Client, es esv1.Elasticsearch, nodeSet esv1.NodeSet) (string, error) {
+	var statefulSet appsv1.StatefulSet
+	err := client.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.StatefulSet(es.Name, nodeSet.Name)}, &statefulSet)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return statefulSet.Spec.Template.Labels[label.RestartTriggerHashLabelName], nil
+}
+
 func buildLabels(
 	es esv1.Elasticsearch,
 	cfg settings.CanonicalConfig,
 	nodeSet esv1.NodeSet,
 	keystoreResources *keystore.Resources,
+	previousRestartTriggerHash string,
 ) (map[string]string, error) {
 	// label with version
 	ver, err := version.Parse(es.Spec.Version)
@@ -172,6 +223,12 @@ func buildLabels(
 		podLabels[label.SecureSettingsHashLabelName] = fmt.Sprintf("%x", configChecksum.Sum(nil))
 	}
 
+	if restartTriggerHash := label.RestartTriggerHash(es, nodeSet.Name, previousRestartTriggerHash); restartTriggerHash != "" {
+		// label with a hash of the restart annotations to rotate the pod when a restart is requested, kept once the
+		// annotations are removed
+		podLabels[label.RestartTriggerHashLabelName] = restartTriggerHash
+	}
+
 	return podLabels, nil
 }
 
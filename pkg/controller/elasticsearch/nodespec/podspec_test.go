@@ -11,12 +11,15 @@ import (
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
@@ -205,10 +208,10 @@ func TestBuildPodTemplateSpecWithDefaultSecurityContext(t *testing.T) {
 			es.Spec.Version = tt.version.String()
 			es.Spec.NodeSets[0].PodTemplate.Spec.SecurityContext = tt.userSecurityContext
 
-			cfg, err := settings.NewMergedESConfig(es.Name, tt.version, corev1.IPv4Protocol, es.Spec.HTTP, *es.Spec.NodeSets[0].Config)
+			cfg, err := settings.NewMergedESConfig(es.Name, tt.version, corev1.IPv4Protocol, es.Spec.HTTP, *es.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
 			require.NoError(t, err)
 
-			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), es, es.Spec.NodeSets[0], cfg, nil, tt.setDefaultFSGroup)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), es, es.Spec.NodeSets[0], cfg, nil, Options{SetDefaultSecurityContext: tt.setDefaultFSGroup})
 			require.NoError(t, err)
 			require.Equal(t, tt.wantSecurityContext, actual.Spec.SecurityContext)
 		})
@@ -220,10 +223,10 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	nodeSet := sampleES.Spec.NodeSets[0]
 	ver, err := version.Parse(sampleES.Spec.Version)
 	require.NoError(t, err)
-	cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *nodeSet.Config)
+	cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *nodeSet.Config, false, settings.RemoteClusterSecurity{})
 	require.NoError(t, err)
 
-	actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, false)
+	actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{})
 	require.NoError(t, err)
 
 	// build expected PodTemplateSpec
@@ -231,12 +234,12 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	terminationGracePeriodSeconds := DefaultTerminationGracePeriodSeconds
 	varFalse := false
 
-	volumes, volumeMounts := buildVolumes(sampleES.Name, nodeSet, nil, volume.DownwardAPI{})
+	volumes, volumeMounts := buildVolumes(sampleES.Name, nodeSet, nil, volume.DownwardAPI{}, nil)
 	// should be sorted
 	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
 	sort.Slice(volumeMounts, func(i, j int) bool { return volumeMounts[i].Name < volumeMounts[j].Name })
 
-	initContainers, err := initcontainer.NewInitContainers(transportCertificatesVolume(sampleES.Name), nil, nil)
+	initContainers, err := initcontainer.NewInitContainers(transportCertificatesVolume(sampleES.Name), nil, nil, nil, initcontainer.PluginsSource{})
 	require.NoError(t, err)
 	// init containers should be patched with volume and inherited env vars and image
 	headlessSvcEnvVar := corev1.EnvVar{Name: "HEADLESS_SERVICE_NAME", Value: "name-es-nodeset-1"}
@@ -322,11 +325,35 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	require.Nil(t, deep.Equal(expected, actual))
 }
 
+func TestBuildPodTemplateSpec_RestartAnnotationRemoved(t *testing.T) {
+	es := newEsSampleBuilder().addEsAnnotations(map[string]string{"eck.k8s.elastic.co/restart": "2023-10-01T00:00:00Z"}).build()
+	ver, err := version.Parse(es.Spec.Version)
+	require.NoError(t, err)
+	cfg, err := settings.NewMergedESConfig(es.Name, ver, corev1.IPv4Protocol, es.Spec.HTTP, *es.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
+	require.NoError(t, err)
+
+	restarted, err := BuildPodTemplateSpec(k8s.NewFakeClient(), es, es.Spec.NodeSets[0], cfg, nil, Options{})
+	require.NoError(t, err)
+	require.NotEmpty(t, restarted.Labels[label.RestartTriggerHashLabelName])
+
+	// the restart is complete, the annotation is removed
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: es.Namespace, Name: esv1.StatefulSet(es.Name, es.Spec.NodeSets[0].Name)},
+		Spec:       appsv1.StatefulSetSpec{Template: restarted},
+	}
+	delete(es.Annotations, "eck.k8s.elastic.co/restart")
+	actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(&statefulSet), es, es.Spec.NodeSets[0], cfg, nil, Options{})
+	require.NoError(t, err)
+	// the Pod template does not change, the Pods are not restarted again
+	require.Equal(t, hash.HashObject(restarted), hash.HashObject(actual))
+}
+
 func Test_buildLabels(t *testing.T) {
 	type args struct {
-		cfg               map[string]interface{}
-		esAnnotations     map[string]string
-		keystoreResources *keystore.Resources
+		cfg                        map[string]interface{}
+		esAnnotations              map[string]string
+		keystoreResources          *keystore.Resources
+		previousRestartTriggerHash string
 	}
 	tests := []struct {
 		name             string
@@ -376,6 +403,57 @@ func Test_buildLabels(t *testing.T) {
 			},
 			unexpectedLabels: []string{label.SecureSettingsHashLabelName},
 		},
+		{
+			name: "With a restart of all the NodeSets",
+			args: args{
+				esAnnotations: map[string]string{"eck.k8s.elastic.co/restart": "2023-10-01T00:00:00Z"},
+			},
+			expectedLabels: map[string]string{
+				"elasticsearch.k8s.elastic.co/config-hash":          "3415561705",
+				"elasticsearch.k8s.elastic.co/restart-trigger-hash": "3826855982.none",
+			},
+		},
+		{
+			name: "With a restart of the NodeSet",
+			args: args{
+				esAnnotations: map[string]string{"eck.k8s.elastic.co/restart.nodeset-1": "2023-10-01T00:00:00Z"},
+			},
+			expectedLabels: map[string]string{
+				"elasticsearch.k8s.elastic.co/config-hash":          "3415561705",
+				"elasticsearch.k8s.elastic.co/restart-trigger-hash": "none.3826855982",
+			},
+		},
+		{
+			name: "With a restart of another NodeSet",
+			args: args{
+				esAnnotations: map[string]string{"eck.k8s.elastic.co/restart.nodeset-2": "2023-10-01T00:00:00Z"},
+			},
+			expectedLabels: map[string]string{
+				"elasticsearch.k8s.elastic.co/config-hash": "3415561705",
+			},
+			unexpectedLabels: []string{label.RestartTriggerHashLabelName},
+		},
+		{
+			name: "With a restart of the NodeSet after a restart of all the NodeSets",
+			args: args{
+				esAnnotations:              map[string]string{"eck.k8s.elastic.co/restart.nodeset-1": "2023-10-01T00:00:00Z"},
+				previousRestartTriggerHash: "3826855982.none",
+			},
+			expectedLabels: map[string]string{
+				"elasticsearch.k8s.elastic.co/config-hash":          "3415561705",
+				"elasticsearch.k8s.elastic.co/restart-trigger-hash": "3826855982.3826855982",
+			},
+		},
+		{
+			name: "With restart annotations removed",
+			args: args{
+				previousRestartTriggerHash: "3826855982.3826855982",
+			},
+			expectedLabels: map[string]string{
+				"elasticsearch.k8s.elastic.co/config-hash":          "3415561705",
+				"elasticsearch.k8s.elastic.co/restart-trigger-hash": "3826855982.3826855982",
+			},
+		},
 		{
 			name: "With keystore",
 			args: args{
@@ -406,9 +484,9 @@ func Test_buildLabels(t *testing.T) {
 			es := newEsSampleBuilder().withKeystoreResources(tt.args.keystoreResources).withUserConfig(tt.args.cfg).addEsAnnotations(tt.args.esAnnotations).build()
 			ver, err := version.Parse(sampleES.Spec.Version)
 			require.NoError(t, err)
-			cfg, err := settings.NewMergedESConfig(es.Name, ver, corev1.IPv4Protocol, es.Spec.HTTP, *es.Spec.NodeSets[0].Config)
+			cfg, err := settings.NewMergedESConfig(es.Name, ver, corev1.IPv4Protocol, es.Spec.HTTP, *es.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
 			require.NoError(t, err)
-			got, err := buildLabels(es, cfg, es.Spec.NodeSets[0], tt.args.keystoreResources)
+			got, err := buildLabels(es, cfg, es.Spec.NodeSets[0], tt.args.keystoreResources, tt.args.previousRestartTriggerHash)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildLabels() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -512,9 +590,9 @@ func Test_enableLog4JFormatMsgNoLookups(t *testing.T) {
 
 			ver, err := version.Parse(sampleES.Spec.Version)
 			require.NoError(t, err)
-			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config)
+			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
 			require.NoError(t, err)
-			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, false)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{})
 			require.NoError(t, err)
 
 			env := actual.Spec.Containers[1].Env
@@ -527,3 +605,262 @@ func Test_enableLog4JFormatMsgNoLookups(t *testing.T) {
 		})
 	}
 }
+
+func Test_withJVMHeapFromMemoryLimits(t *testing.T) {
+	tt := []struct {
+		name                       string
+		version                    string
+		memoryLimit                string
+		userEnv                    []corev1.EnvVar
+		expectedEsJavaOptsEnvValue string
+	}{
+		{
+			name:                       "heap set to half of the default memory limit",
+			version:                    "7.10.0",
+			expectedEsJavaOptsEnvValue: "-Xms1024m -Xmx1024m",
+		},
+		{
+			name:                       "heap set to half of the memory limit, merged with user-provided JVM parameters",
+			version:                    "7.10.0",
+			memoryLimit:                "6Gi",
+			userEnv:                    []corev1.EnvVar{{Name: "ES_JAVA_OPTS", Value: "-Dfoo=bar"}},
+			expectedEsJavaOptsEnvValue: "-Dfoo=bar -Xms3072m -Xmx3072m",
+		},
+		{
+			name:                       "heap capped below the compressed oops threshold",
+			version:                    "7.10.0",
+			memoryLimit:                "128Gi",
+			expectedEsJavaOptsEnvValue: "-Xms31744m -Xmx31744m",
+		},
+		{
+			name:                       "user-provided heap is not overridden",
+			version:                    "7.10.0",
+			memoryLimit:                "6Gi",
+			userEnv:                    []corev1.EnvVar{{Name: "ES_JAVA_OPTS", Value: "-Xms2g -Xmx2g"}},
+			expectedEsJavaOptsEnvValue: "-Xms2g -Xmx2g",
+		},
+		{
+			name:        "since 7.11.0, Elasticsearch sizes its heap on its own",
+			version:     "7.11.0",
+			memoryLimit: "6Gi",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sampleES := newEsSampleBuilder().addEsAnnotations(map[string]string{esv1.JVMHeapFromMemoryLimitsAnnotation: "true"}).build()
+			sampleES.Spec.Version = tc.version
+			esContainer := &sampleES.Spec.NodeSets[0].PodTemplate.Spec.Containers[1]
+			esContainer.Env = tc.userEnv
+			if tc.memoryLimit != "" {
+				esContainer.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(tc.memoryLimit)}
+			}
+
+			ver, err := version.Parse(sampleES.Spec.Version)
+			require.NoError(t, err)
+			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
+			require.NoError(t, err)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{})
+			require.NoError(t, err)
+
+			envMap := make(map[string]string)
+			for _, e := range actual.Spec.Containers[1].Env {
+				envMap[e.Name] = e.Value
+			}
+			assert.Equal(t, tc.expectedEsJavaOptsEnvValue, envMap[settings.EnvEsJavaOpts])
+		})
+	}
+}
+
+func TestBuildPodTemplateSpec_ZoneAwareness(t *testing.T) {
+	tt := []struct {
+		name                string
+		zoneAwareness       *esv1.ZoneAwareness
+		expectedZoneEnvPath string
+	}{
+		{
+			name: "zone awareness disabled",
+		},
+		{
+			name:                "default topology key",
+			zoneAwareness:       &esv1.ZoneAwareness{},
+			expectedZoneEnvPath: "metadata.annotations['topology.kubernetes.io/zone']",
+		},
+		{
+			name:                "custom topology key",
+			zoneAwareness:       &esv1.ZoneAwareness{TopologyKey: "example.com/rack"},
+			expectedZoneEnvPath: "metadata.annotations['example.com/rack']",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sampleES := newEsSampleBuilder().build()
+			sampleES.Spec.ZoneAwareness = tc.zoneAwareness
+
+			ver, err := version.Parse(sampleES.Spec.Version)
+			require.NoError(t, err)
+			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config, tc.zoneAwareness != nil, settings.RemoteClusterSecurity{})
+			require.NoError(t, err)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{})
+			require.NoError(t, err)
+
+			var zoneEnvPath string
+			for _, e := range actual.Spec.Containers[1].Env {
+				if e.Name == settings.EnvZone {
+					zoneEnvPath = e.ValueFrom.FieldRef.FieldPath
+				}
+			}
+			assert.Equal(t, tc.expectedZoneEnvPath, zoneEnvPath)
+			// the zone label is expected to be copied as an annotation on the Pods
+			assert.Equal(t, tc.zoneAwareness != nil, sampleES.HasDownwardNodeLabels())
+		})
+	}
+}
+
+func TestBuildPodTemplateSpec_DefaultTopologySpread(t *testing.T) {
+	userConstraints := []corev1.TopologySpreadConstraint{
+		{MaxSkew: 2, TopologyKey: "example.com/rack", WhenUnsatisfiable: corev1.DoNotSchedule},
+	}
+	tt := []struct {
+		name                  string
+		defaultTopologySpread bool
+		zoneAwareness         *esv1.ZoneAwareness
+		userConstraints       []corev1.TopologySpreadConstraint
+		expectedTopologyKeys  []string
+	}{
+		{
+			name: "default topology spread disabled",
+		},
+		{
+			name:                  "default topology spread enabled",
+			defaultTopologySpread: true,
+			expectedTopologyKeys:  []string{"topology.kubernetes.io/zone", "kubernetes.io/hostname"},
+		},
+		{
+			name:                  "zone topology key from the zone awareness",
+			defaultTopologySpread: true,
+			zoneAwareness:         &esv1.ZoneAwareness{TopologyKey: "example.com/zone"},
+			expectedTopologyKeys:  []string{"example.com/zone", "kubernetes.io/hostname"},
+		},
+		{
+			name:                  "user-provided constraints are kept",
+			defaultTopologySpread: true,
+			userConstraints:       userConstraints,
+			expectedTopologyKeys:  []string{"example.com/rack"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sampleES := newEsSampleBuilder().build()
+			sampleES.Spec.ZoneAwareness = tc.zoneAwareness
+			sampleES.Spec.NodeSets[0].PodTemplate.Spec.TopologySpreadConstraints = tc.userConstraints
+
+			ver, err := version.Parse(sampleES.Spec.Version)
+			require.NoError(t, err)
+			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config, tc.zoneAwareness != nil, settings.RemoteClusterSecurity{})
+			require.NoError(t, err)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{DefaultTopologySpread: tc.defaultTopologySpread})
+			require.NoError(t, err)
+
+			var topologyKeys []string
+			for _, constraint := range actual.Spec.TopologySpreadConstraints {
+				topologyKeys = append(topologyKeys, constraint.TopologyKey)
+				if tc.userConstraints == nil {
+					assert.Equal(t, corev1.ScheduleAnyway, constraint.WhenUnsatisfiable)
+					assert.Equal(t, map[string]string{
+						label.ClusterNameLabelName:     "name",
+						label.StatefulSetNameLabelName: "name-es-nodeset-1",
+					}, constraint.LabelSelector.MatchLabels)
+				}
+			}
+			assert.Equal(t, tc.expectedTopologyKeys, topologyKeys)
+		})
+	}
+}
+
+func TestBuildPodTemplateSpec_ReadinessProbe(t *testing.T) {
+	tt := []struct {
+		name           string
+		readinessProbe *esv1.ReadinessProbe
+		expectedProbe  func() *corev1.Probe
+		expectedEnv    map[string]string
+		expectedVolume bool
+	}{
+		{
+			name:          "default readiness probe",
+			expectedProbe: NewReadinessProbe,
+		},
+		{
+			name: "custom port, path and timeouts",
+			readinessProbe: &esv1.ReadinessProbe{
+				Port:             pointer.Int32(9201),
+				Path:             "/_cluster/health?local=true",
+				TimeoutSeconds:   pointer.Int32(10),
+				FailureThreshold: pointer.Int32(5),
+			},
+			expectedProbe: func() *corev1.Probe {
+				probe := NewReadinessProbe()
+				probe.TimeoutSeconds = 10
+				probe.FailureThreshold = 5
+				return probe
+			},
+			expectedEnv: map[string]string{
+				settings.EnvReadinessProbePort:    "9201",
+				settings.EnvReadinessProbePath:    "/_cluster/health?local=true",
+				settings.EnvReadinessProbeTimeout: "8",
+			},
+		},
+		{
+			name: "custom script",
+			readinessProbe: &esv1.ReadinessProbe{
+				Script: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "probes"}, Key: "probe.sh"},
+			},
+			expectedProbe: func() *corev1.Probe {
+				probe := NewReadinessProbe()
+				probe.Exec.Command = []string{"bash", "-c", "/mnt/elastic-internal/readiness-probe/probe.sh"}
+				return probe
+			},
+			expectedVolume: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sampleES := newEsSampleBuilder().build()
+			sampleES.Spec.NodeSets[0].ReadinessProbe = tc.readinessProbe
+
+			ver, err := version.Parse(sampleES.Spec.Version)
+			require.NoError(t, err)
+			cfg, err := settings.NewMergedESConfig(sampleES.Name, ver, corev1.IPv4Protocol, sampleES.Spec.HTTP, *sampleES.Spec.NodeSets[0].Config, false, settings.RemoteClusterSecurity{})
+			require.NoError(t, err)
+			actual, err := BuildPodTemplateSpec(k8s.NewFakeClient(), sampleES, sampleES.Spec.NodeSets[0], cfg, nil, Options{})
+			require.NoError(t, err)
+
+			esContainer := actual.Spec.Containers[1]
+			assert.Equal(t, tc.expectedProbe(), esContainer.ReadinessProbe)
+
+			env := map[string]string{}
+			for _, e := range esContainer.Env {
+				switch e.Name {
+				case settings.EnvReadinessProbePort, settings.EnvReadinessProbePath, settings.EnvReadinessProbeTimeout:
+					env[e.Name] = e.Value
+				}
+			}
+			if tc.expectedEnv == nil {
+				tc.expectedEnv = map[string]string{}
+			}
+			assert.Equal(t, tc.expectedEnv, env)
+
+			hasVolume := false
+			for _, v := range actual.Spec.Volumes {
+				if v.Name == readinessProbeScriptVolumeName {
+					hasVolume = true
+					assert.Equal(t, "probes", v.ConfigMap.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedVolume, hasVolume)
+		})
+	}
+}
@@ -6,11 +6,16 @@ package nodespec
 
 import (
 	"path"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	commonvolume "github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
 )
 
@@ -29,6 +34,74 @@ func NewReadinessProbe() *corev1.Probe {
 	}
 }
 
+const (
+	readinessProbeScriptVolumeName = "elastic-internal-readiness-probe"
+	readinessProbeScriptMountPath  = "/mnt/elastic-internal/readiness-probe"
+	// curl is given less time than the probe itself to report its outcome
+	readinessProbeCurlTimeoutMargin = 2
+)
+
+// NewNodeSetReadinessProbe returns the readiness probe of the Elasticsearch containers of the given NodeSet, with
+// the overrides of its ReadinessProbe specification applied to the default probe.
+func NewNodeSetReadinessProbe(nodeSet esv1.NodeSet) *corev1.Probe {
+	probe := NewReadinessProbe()
+	spec := nodeSet.ReadinessProbe
+	if spec == nil {
+		return probe
+	}
+	if spec.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *spec.TimeoutSeconds
+	}
+	if spec.PeriodSeconds != nil {
+		probe.PeriodSeconds = *spec.PeriodSeconds
+	}
+	if spec.FailureThreshold != nil {
+		probe.FailureThreshold = *spec.FailureThreshold
+	}
+	if spec.Script != nil {
+		probe.Exec.Command = []string{"bash", "-c", path.Join(readinessProbeScriptMountPath, spec.Script.Key)}
+	}
+	return probe
+}
+
+// readinessProbeEnvVars returns the environment variables passing the ReadinessProbe specification of the given
+// NodeSet to the probe script. They are only set if customized, to leave the Pods of other NodeSets untouched.
+func readinessProbeEnvVars(nodeSet esv1.NodeSet) []corev1.EnvVar {
+	spec := nodeSet.ReadinessProbe
+	if spec == nil {
+		return nil
+	}
+	var envVars []corev1.EnvVar
+	if spec.Port != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: settings.EnvReadinessProbePort, Value: strconv.Itoa(int(*spec.Port))})
+	}
+	if spec.Path != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: settings.EnvReadinessProbePath, Value: spec.Path})
+	}
+	if spec.TimeoutSeconds != nil {
+		timeout := *spec.TimeoutSeconds - readinessProbeCurlTimeoutMargin
+		if timeout < 1 {
+			timeout = 1
+		}
+		envVars = append(envVars, corev1.EnvVar{Name: settings.EnvReadinessProbeTimeout, Value: strconv.Itoa(int(timeout))})
+	}
+	return envVars
+}
+
+// readinessProbeScriptVolume returns the volume holding the custom readiness probe script of the given NodeSet, if any.
+func readinessProbeScriptVolume(nodeSet esv1.NodeSet) *commonvolume.ConfigMapVolume {
+	if nodeSet.ReadinessProbe == nil || nodeSet.ReadinessProbe.Script == nil {
+		return nil
+	}
+	v := commonvolume.NewConfigMapVolumeWithMode(
+		nodeSet.ReadinessProbe.Script.Name,
+		readinessProbeScriptVolumeName,
+		readinessProbeScriptMountPath,
+		0755,
+	)
+	return &v
+}
+
 const ReadinessProbeScriptConfigKey = "readiness-probe-script.sh"
 const ReadinessProbeScript = `#!/usr/bin/env bash
 
@@ -51,8 +124,12 @@ fi
 
 READINESS_PROBE_TIMEOUT=${READINESS_PROBE_TIMEOUT:=3}
 
-# Check if PROBE_PASSWORD_PATH is set, otherwise fall back to its former name in 1.0.0.beta-1: PROBE_PASSWORD_FILE
-if [[ -z "${PROBE_PASSWORD_PATH}" ]]; then
+# Prefer the probe password stored along with the file realm, which is updated at the same time when it is rotated.
+# Otherwise check if PROBE_PASSWORD_PATH is set, or fall back to its former name in 1.0.0.beta-1: PROBE_PASSWORD_FILE
+file_realm_probe_password_path="` + volume.XPackFileRealmVolumeMountPath + "/" + user.ProbeUserName + `"
+if [[ -f "${file_realm_probe_password_path}" ]]; then
+  probe_password_path="${file_realm_probe_password_path}"
+elif [[ -z "${PROBE_PASSWORD_PATH}" ]]; then
   probe_password_path="${PROBE_PASSWORD_FILE}"
 else
   probe_password_path="${PROBE_PASSWORD_PATH}"
@@ -73,9 +150,9 @@ else
   LOOPBACK=127.0.0.1
 fi
 
-# request Elasticsearch on /
+# request Elasticsearch on / unless configured otherwise
 # we are turning globbing off to allow for unescaped [] in case of IPv6
-ENDPOINT="${READINESS_PROBE_PROTOCOL:-https}://${LOOPBACK}:9200/"
+ENDPOINT="${READINESS_PROBE_PROTOCOL:-https}://${LOOPBACK}:${READINESS_PROBE_PORT:-9200}${READINESS_PROBE_PATH:-/}"
 ORIGIN_HEADER="` + common.InternalProductRequestHeaderString + `"
 status=$(curl -o /dev/null -w "%{http_code}" --max-time ${READINESS_PROBE_TIMEOUT} -H "${ORIGIN_HEADER}" -XGET -g -s -k ${BASIC_AUTH} $ENDPOINT)
 curl_rc=$?
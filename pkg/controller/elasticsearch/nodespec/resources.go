@@ -16,8 +16,13 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/parallel"
 )
 
+// MaxConcurrentNodeSets is the maximum number of nodeSets of a cluster whose resources are built or reconciled
+// concurrently.
+const MaxConcurrentNodeSets = 4
+
 // Resources contain per-NodeSet resources to be created.
 type Resources struct {
 	StatefulSet     appsv1.StatefulSet
@@ -41,38 +46,53 @@ func BuildExpectedResources(
 	keystoreResources *keystore.Resources,
 	existingStatefulSets sset.StatefulSetList,
 	ipFamily corev1.IPFamily,
-	setDefaultSecurityContext bool,
+	opts Options,
 ) (ResourcesList, error) {
-	nodesResources := make(ResourcesList, 0, len(es.Spec.NodeSets))
-
 	ver, err := version.Parse(es.Spec.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, nodeSpec := range es.Spec.NodeSets {
+	// the resources of each nodeSet are independent from each other, build them in parallel
+	nodesResources := make(ResourcesList, len(es.Spec.NodeSets))
+	err = parallel.ForEach(len(es.Spec.NodeSets), MaxConcurrentNodeSets, func(i int) error {
+		nodeSpec := es.Spec.NodeSets[i]
 		// build es config
 		userCfg := commonv1.Config{}
 		if nodeSpec.Config != nil {
 			userCfg = *nodeSpec.Config
 		}
-		cfg, err := settings.NewMergedESConfig(es.Name, ver, ipFamily, es.Spec.HTTP, userCfg)
+		userCfg, err := settings.WithDataTierRoles(userCfg, ver, es.Spec.DataTiers.DataTierRoles(nodeSpec.Name))
 		if err != nil {
-			return nil, err
+			return err
+		}
+		userCfg, err = settings.WithRealms(userCfg, es.Spec.Auth.Realms)
+		if err != nil {
+			return err
+		}
+		cfg, err := settings.NewMergedESConfig(
+			es.Name, ver, ipFamily, es.Spec.HTTP, userCfg, es.Spec.ZoneAwareness != nil, settings.NewRemoteClusterSecurity(es),
+		)
+		if err != nil {
+			return err
 		}
 
 		// build stateful set and associated headless service
-		statefulSet, err := BuildStatefulSet(client, es, nodeSpec, cfg, keystoreResources, existingStatefulSets, setDefaultSecurityContext)
+		statefulSet, err := BuildStatefulSet(client, es, nodeSpec, cfg, keystoreResources, existingStatefulSets, opts)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		headlessSvc := HeadlessService(&es, statefulSet.Name)
 
-		nodesResources = append(nodesResources, Resources{
+		nodesResources[i] = Resources{
 			StatefulSet:     statefulSet,
 			HeadlessService: headlessSvc,
 			Config:          cfg,
-		})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return nodesResources, nil
@@ -5,18 +5,24 @@
 package nodespec
 
 import (
+	"context"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/keystore"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/securitycontext"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
@@ -61,7 +67,7 @@ func BuildStatefulSet(
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
 	existingStatefulSets sset.StatefulSetList,
-	setDefaultSecurityContext bool,
+	opts Options,
 ) (appsv1.StatefulSet, error) {
 	statefulSetName := esv1.StatefulSet(es.Name, nodeSet.Name)
 
@@ -76,10 +82,22 @@ func BuildStatefulSet(
 	)
 
 	// build pod template
-	podTemplate, err := BuildPodTemplateSpec(client, es, nodeSet, cfg, keystoreResources, setDefaultSecurityContext)
+	podTemplate, err := BuildPodTemplateSpec(client, es, nodeSet, cfg, keystoreResources, opts)
+	if err != nil {
+		return appsv1.StatefulSet{}, err
+	}
+	podTemplate, err = securitycontext.Harden(podTemplate, opts.RestrictedSecurityContext)
 	if err != nil {
 		return appsv1.StatefulSet{}, err
 	}
+	existingSset, exists := existingStatefulSets.GetByName(statefulSetName)
+	serviceTokens, err := serviceTokensLabelRequired(client, es, existingSset, exists)
+	if err != nil {
+		return appsv1.StatefulSet{}, err
+	}
+	if serviceTokens {
+		podTemplate.Labels[label.ServiceTokensLabelName] = "true"
+	}
 
 	// build sset labels on top of the selector
 	// TODO: inherit user-provided labels and annotations from the CRD?
@@ -90,7 +108,7 @@ func BuildStatefulSet(
 
 	// maybe inherit volumeClaimTemplates ownerRefs from the existing StatefulSet
 	var existingClaims []corev1.PersistentVolumeClaim
-	if existingSset, exists := existingStatefulSets.GetByName(statefulSetName); exists {
+	if exists {
 		existingClaims = existingSset.Spec.VolumeClaimTemplates
 	}
 	claims := preserveExistingVolumeClaimsOwnerRefs(nodeSet.VolumeClaimTemplates, existingClaims)
@@ -128,6 +146,32 @@ func BuildStatefulSet(
 	return sset, nil
 }
 
+// serviceTokensLabelRequired returns true if the Pods of the StatefulSet must carry the label marking them as able to
+// authenticate service account tokens. Changing the Pod template restarts the Pods, the label is therefore only added
+// once an association authenticates with a service account token, and kept afterwards.
+func serviceTokensLabelRequired(c k8s.Client, es esv1.Elasticsearch, existing appsv1.StatefulSet, exists bool) (bool, error) {
+	ver, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return false, err
+	}
+	// file based service account tokens were added in 7.13.0
+	if ver.LT(version.From(7, 13, 0)) {
+		return false, nil
+	}
+	if exists && existing.Spec.Template.Labels[label.ServiceTokensLabelName] == "true" {
+		return true, nil
+	}
+	var tokens corev1.SecretList
+	if err := c.List(context.Background(),
+		&tokens,
+		client.InNamespace(es.Namespace),
+		client.MatchingLabels(user.ServiceAccountTokenLabels(es)),
+	); err != nil {
+		return false, err
+	}
+	return len(tokens.Items) > 0, nil
+}
+
 func preserveExistingVolumeClaimsOwnerRefs(
 	persistentVolumeClaims []corev1.PersistentVolumeClaim,
 	existingClaims []corev1.PersistentVolumeClaim,
@@ -8,11 +8,16 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	controllerscheme "github.com/elastic/cloud-on-k8s/pkg/controller/common/scheme"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
 func Test_setVolumeClaimsControllerReference(t *testing.T) {
@@ -133,3 +138,62 @@ func Test_setVolumeClaimsControllerReference(t *testing.T) {
 		})
 	}
 }
+
+func Test_serviceTokensLabelRequired(t *testing.T) {
+	es := func(version string) esv1.Elasticsearch {
+		return esv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+			Spec:       esv1.ElasticsearchSpec{Version: version},
+		}
+	}
+	token := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "kibana-kibana-user-token",
+		Namespace: "ns",
+		Labels:    user.ServiceAccountTokenLabels(es("7.17.0")),
+	}}
+	labelled := appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{label.ServiceTokensLabelName: "true"}},
+	}}}
+	tests := []struct {
+		name      string
+		es        esv1.Elasticsearch
+		resources []runtime.Object
+		existing  *appsv1.StatefulSet
+		want      bool
+	}{
+		{
+			name: "no service account token: no label, the Pod template is unchanged",
+			es:   es("7.17.0"),
+			want: false,
+		},
+		{
+			name:      "service account token",
+			es:        es("7.17.0"),
+			resources: []runtime.Object{token},
+			want:      true,
+		},
+		{
+			name:      "version without file based service account tokens",
+			es:        es("7.12.0"),
+			resources: []runtime.Object{token},
+			want:      false,
+		},
+		{
+			name:     "label kept once set",
+			es:       es("7.17.0"),
+			existing: &labelled,
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var existing appsv1.StatefulSet
+			if tt.existing != nil {
+				existing = *tt.existing
+			}
+			got, err := serviceTokensLabelRequired(k8s.NewFakeClient(tt.resources...), tt.es, existing, tt.existing != nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -22,6 +22,7 @@ func buildVolumes(
 	nodeSpec esv1.NodeSet,
 	keystoreResources *keystore.Resources,
 	downwardAPIVolume volume.DownwardAPI,
+	realms []esv1.Realm,
 ) ([]corev1.Volume, []corev1.VolumeMount) {
 	configVolume := settings.ConfigSecretVolume(esv1.StatefulSet(esName, nodeSpec.Name))
 	probeSecret := volume.NewSelectiveSecretVolumeWithMountPath(
@@ -100,6 +101,16 @@ func buildVolumes(
 		downwardAPIVolume.VolumeMount(),
 	)
 
+	for _, realmVolume := range settings.RealmVolumes(realms) {
+		volumes = append(volumes, realmVolume.Volume())
+		volumeMounts = append(volumeMounts, realmVolume.VolumeMount())
+	}
+
+	if probeScriptVolume := readinessProbeScriptVolume(nodeSpec); probeScriptVolume != nil {
+		volumes = append(volumes, probeScriptVolume.Volume())
+		volumeMounts = append(volumeMounts, probeScriptVolume.VolumeMount())
+	}
+
 	volumeMounts = esvolume.AppendDefaultDataVolumeMount(volumeMounts, volumes)
 
 	return volumes, volumeMounts
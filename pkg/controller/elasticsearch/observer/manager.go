@@ -7,7 +7,6 @@ package observer
 import (
 	"sync"
 
-	"go.elastic.co/apm"
 	"k8s.io/apimachinery/pkg/types"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
@@ -27,14 +26,19 @@ type Manager struct {
 	observers    map[types.NamespacedName]*Observer
 	listenerLock sync.RWMutex
 	listeners    []OnObservation // invoked on each observation event
-	tracer       *apm.Tracer
+	// defaults are the settings of the observers of clusters which do not override them
+	defaults Settings
 }
 
-// NewManager returns a new manager
-func NewManager(tracer *apm.Tracer) *Manager {
+// NewManager returns a new manager creating observers with the given default settings. The observation interval
+// defaults to DefaultObservationInterval if not set.
+func NewManager(defaults Settings) *Manager {
+	if defaults.ObservationInterval <= 0 {
+		defaults.ObservationInterval = DefaultObservationInterval
+	}
 	return &Manager{
 		observers: make(map[types.NamespacedName]*Observer),
-		tracer:    tracer,
+		defaults:  defaults,
 	}
 }
 
@@ -76,10 +80,9 @@ func (m *Manager) Observe(cluster esv1.Elasticsearch, esClient client.Client) *O
 
 // extractObserverSettings extracts observer settings from the annotations on the Elasticsearch resource.
 func (m *Manager) extractObserverSettings(cluster esv1.Elasticsearch) Settings {
-	return Settings{
-		ObservationInterval: annotation.ExtractTimeout(cluster.ObjectMeta, ObserverIntervalAnnotation, defaultObservationInterval),
-		Tracer:              m.tracer,
-	}
+	settings := m.defaults
+	settings.ObservationInterval = annotation.ExtractTimeout(cluster.ObjectMeta, ObserverIntervalAnnotation, m.defaults.ObservationInterval)
+	return settings
 }
 
 // createOrReplaceObserver creates a new observer and adds it to the observers map, replacing existing observers if necessary.
@@ -102,6 +105,30 @@ func (m *Manager) createOrReplaceObserver(cluster types.NamespacedName, settings
 	return observer
 }
 
+// LastState returns the last state observed for the given cluster, and whether the cluster is observed.
+func (m *Manager) LastState(cluster types.NamespacedName) (State, bool) {
+	observer, exists := m.getObserver(cluster)
+	if !exists {
+		return State{}, false
+	}
+	return observer.LastState(), true
+}
+
+// Quiet returns true if the given cluster is observed and has been green and unchanged for long enough to be in
+// slow-poll mode.
+func (m *Manager) Quiet(cluster types.NamespacedName) bool {
+	observer, exists := m.getObserver(cluster)
+	return exists && observer.Quiet()
+}
+
+// MarkChanged ends the slow-poll mode of the given cluster, if observed, following a change to the cluster or its
+// resources.
+func (m *Manager) MarkChanged(cluster types.NamespacedName) {
+	if observer, exists := m.getObserver(cluster); exists {
+		observer.MarkChanged()
+	}
+}
+
 // List returns the names of clusters currently observed
 func (m *Manager) List() []types.NamespacedName {
 	m.observerLock.RLock()
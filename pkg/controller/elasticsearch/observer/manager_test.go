@@ -39,7 +39,7 @@ func TestManager_List(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := NewManager(nil)
+			m := NewManager(Settings{})
 			m.observers = tt.observers
 			require.ElementsMatch(t, tt.want, m.List())
 		})
@@ -54,7 +54,7 @@ func TestManager_Observe(t *testing.T) {
 	fakeClient := fakeEsClient200(client.BasicAuth{})
 	fakeClientWithDifferentUser := fakeEsClient200(client.BasicAuth{Name: "name", Password: "another-one"})
 	defaultSettings := Settings{
-		ObservationInterval: defaultObservationInterval,
+		ObservationInterval: DefaultObservationInterval,
 	}
 
 	tests := []struct {
@@ -100,7 +100,7 @@ func TestManager_Observe(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := NewManager(nil)
+			m := NewManager(Settings{})
 			m.observers = tt.initiallyObserved
 			var initialCreationTime time.Time
 			if initial, exists := tt.initiallyObserved[tt.clusterToObserve]; exists {
@@ -163,7 +163,7 @@ func TestManager_StopObserving(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := NewManager(nil)
+			m := NewManager(Settings{})
 			m.observers = tt.observed
 			for _, name := range tt.stopObserving {
 				m.StopObserving(name)
@@ -174,7 +174,7 @@ func TestManager_StopObserving(t *testing.T) {
 }
 
 func TestManager_AddObservationListener(t *testing.T) {
-	m := NewManager(nil)
+	m := NewManager(Settings{})
 
 	cluster1 := esObject(cluster("cluster1"))
 	cluster1.ObjectMeta.Annotations = map[string]string{ObserverIntervalAnnotation: "0.000001s"}
@@ -223,24 +223,36 @@ func esObject(n types.NamespacedName) esv1.Elasticsearch {
 func TestExtractSettings(t *testing.T) {
 	testCases := []struct {
 		name        string
+		defaults    Settings
 		annotations map[string]string
 		want        Settings
 	}{
 		{
 			name: "no annotations",
-			want: Settings{ObservationInterval: defaultObservationInterval},
+			want: Settings{ObservationInterval: DefaultObservationInterval},
 		},
 		{
 			name:        "with annotations",
 			annotations: map[string]string{ObserverIntervalAnnotation: "42s"},
 			want:        Settings{ObservationInterval: 42 * time.Second},
 		},
+		{
+			name:     "operator defaults",
+			defaults: Settings{ObservationInterval: 30 * time.Second, SlowPollAfter: time.Hour, SlowPollInterval: 5 * time.Minute},
+			want:     Settings{ObservationInterval: 30 * time.Second, SlowPollAfter: time.Hour, SlowPollInterval: 5 * time.Minute},
+		},
+		{
+			name:        "operator defaults with annotations",
+			defaults:    Settings{ObservationInterval: 30 * time.Second, SlowPollAfter: time.Hour, SlowPollInterval: 5 * time.Minute},
+			annotations: map[string]string{ObserverIntervalAnnotation: "42s"},
+			want:        Settings{ObservationInterval: 42 * time.Second, SlowPollAfter: time.Hour, SlowPollInterval: 5 * time.Minute},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: tc.annotations}}
-			m := NewManager(nil)
+			m := NewManager(tc.defaults)
 			have := m.extractObserverSettings(es)
 			require.Equal(t, tc.want, have)
 		})
@@ -6,12 +6,14 @@ package observer
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 	"time"
 
 	"go.elastic.co/apm"
 	"k8s.io/apimachinery/pkg/types"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
 )
@@ -21,18 +23,29 @@ var log = ulog.Log.WithName("observer")
 // Settings for the Observer configuration
 type Settings struct {
 	ObservationInterval time.Duration
-	Tracer              *apm.Tracer
+	// SlowPollAfter is the duration after which a cluster green and unchanged is observed at most every
+	// SlowPollInterval, 0 to disable the slow-poll mode.
+	SlowPollAfter    time.Duration
+	SlowPollInterval time.Duration
+	// AllocationExplainDelay is the duration after which the allocation of the unassigned shards of a cluster which
+	// is not green is explained, 0 to disable it.
+	AllocationExplainDelay time.Duration
+	Tracer                 *apm.Tracer
 }
 
-// defaultObservationInterval is the default interval of observation.
-// if the Elasticsearch cluster is unavailable, the actual interval would be observationInterval + requestTimeout.
-const defaultObservationInterval = 10 * time.Second
+// DefaultObservationInterval is the default interval of observation.
+// The actual interval varies by up to jitterFactor of it, and increases while the Elasticsearch cluster is unreachable
+// or in slow-poll mode.
+const DefaultObservationInterval = 10 * time.Second
+
+// allocationExplainInterval is the minimum interval between two requests to explain the allocation of unassigned shards.
+const allocationExplainInterval = 1 * time.Minute
 
 // OnObservation is a function that gets executed when a new state is observed
 type OnObservation func(cluster types.NamespacedName, previousState State, newState State)
 
 // Observer regularly requests an ES endpoint for cluster state,
-// in a thread-safe way
+// in a thread-safe way. Observations are scheduled by a scheduler shared by all observers.
 type Observer struct {
 	cluster       types.NamespacedName
 	esClient      client.Client
@@ -42,7 +55,16 @@ type Observer struct {
 	stopOnce      sync.Once
 	onObservation OnObservation
 	lastState     State
-	mutex         sync.RWMutex
+	// unreachable is the number of consecutive observations that failed to retrieve the cluster state
+	unreachable int
+	// quietSince is the time since which the cluster has been observed green and unchanged, zero if it is not green
+	quietSince time.Time
+	// unhealthySince is the time since which the cluster has been observed yellow or red, zero if it is not
+	unhealthySince time.Time
+	// allocationExplanation is the last explanation of the allocation of an unassigned shard, retrieved at explainedAt
+	allocationExplanation *client.AllocationExplanation
+	explainedAt           time.Time
+	mutex                 sync.RWMutex
 }
 
 // NewObserver creates and starts an Observer
@@ -62,19 +84,30 @@ func NewObserver(cluster types.NamespacedName, esClient client.Client, settings
 	return &observer
 }
 
-// Start the observer in a separate goroutine
+// Start schedules the observations. The first one happens after a random delay of up to jitterFactor of the
+// observation interval, to spread the observations of clusters observed since the same time.
 func (o *Observer) Start() {
-	go o.runPeriodically()
+	sharedScheduler.schedule(o, time.Duration(rand.Float64()*jitterFactor*float64(o.settings.ObservationInterval))) //nolint:gosec
 }
 
 // Stop the observer loop
 func (o *Observer) Stop() {
 	o.stopOnce.Do(func() {
+		log.Info("Stopping observer for cluster", "namespace", o.cluster.Namespace, "es_name", o.cluster.Name)
 		close(o.stopChan)
 		o.esClient.Close()
 	})
 }
 
+func (o *Observer) stopped() bool {
+	select {
+	case <-o.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
 // LastState returns the last observed state
 func (o *Observer) LastState() State {
 	o.mutex.RLock()
@@ -82,23 +115,46 @@ func (o *Observer) LastState() State {
 	return o.lastState
 }
 
-// runPeriodically triggers a state retrieval every tick,
-// until the given context is cancelled
-func (o *Observer) runPeriodically() {
-	o.retrieveState()
-
-	ticker := time.NewTicker(o.settings.ObservationInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			o.retrieveState()
-		case <-o.stopChan:
-			log.Info("Stopping observer for cluster", "namespace", o.cluster.Namespace, "es_name", o.cluster.Name)
-			return
+// Quiet returns true if the cluster has been observed green and unchanged for at least the SlowPollAfter duration.
+func (o *Observer) Quiet() bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.quiet(time.Now())
+}
+
+func (o *Observer) quiet(now time.Time) bool {
+	return o.settings.SlowPollAfter > 0 && !o.quietSince.IsZero() && now.Sub(o.quietSince) >= o.settings.SlowPollAfter
+}
+
+// MarkChanged records that the cluster or its resources changed, which ends the slow-poll mode.
+func (o *Observer) MarkChanged() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if !o.quietSince.IsZero() {
+		o.quietSince = time.Now()
+	}
+}
+
+// nextObservationDelay returns the delay before the next observation: the observation interval, doubled for each
+// consecutive observation of an unreachable cluster up to maxUnreachableInterval, or raised to the slow-poll
+// interval if the cluster is quiet, with some jitter.
+func (o *Observer) nextObservationDelay() time.Duration {
+	o.mutex.RLock()
+	unreachable := o.unreachable
+	quiet := o.quiet(time.Now())
+	o.mutex.RUnlock()
+
+	interval := o.settings.ObservationInterval
+	if quiet && o.settings.SlowPollInterval > interval {
+		interval = o.settings.SlowPollInterval
+	}
+	for i := 0; i < unreachable && interval < maxUnreachableInterval; i++ {
+		interval *= 2
+		if interval > maxUnreachableInterval {
+			interval = maxUnreachableInterval
 		}
 	}
+	return withJitter(interval)
 }
 
 // retrieveState retrieves the current ES state, executes onObservation,
@@ -116,6 +172,7 @@ func (o *Observer) retrieveState() {
 	}
 
 	newState := RetrieveState(ctx, o.cluster, o.esClient)
+	newState.AllocationExplanation = o.explainAllocation(ctx, newState, time.Now())
 
 	if o.onObservation != nil {
 		o.onObservation(o.cluster, o.LastState(), newState)
@@ -123,5 +180,51 @@ func (o *Observer) retrieveState() {
 
 	o.mutex.Lock()
 	o.lastState = newState
+	if newState.ClusterHealth == nil {
+		o.unreachable++
+	} else {
+		o.unreachable = 0
+	}
+	switch {
+	case newState.ClusterHealth == nil || newState.ClusterHealth.Status != esv1.ElasticsearchGreenHealth:
+		o.quietSince = time.Time{}
+	case o.quietSince.IsZero():
+		o.quietSince = time.Now()
+	}
+	o.mutex.Unlock()
+}
+
+// explainAllocation returns the explanation of the allocation of an unassigned shard if the cluster has been yellow
+// or red for at least AllocationExplainDelay. The explanation is requested again at most every
+// allocationExplainInterval, the previous one being returned in the meantime.
+func (o *Observer) explainAllocation(ctx context.Context, state State, now time.Time) *client.AllocationExplanation {
+	o.mutex.Lock()
+	unhealthy := state.ClusterHealth != nil && state.ClusterHealth.Status != esv1.ElasticsearchGreenHealth
+	if !unhealthy || o.settings.AllocationExplainDelay <= 0 {
+		o.unhealthySince = time.Time{}
+		o.allocationExplanation = nil
+		o.explainedAt = time.Time{}
+		o.mutex.Unlock()
+		return nil
+	}
+	if o.unhealthySince.IsZero() {
+		o.unhealthySince = now
+	}
+	previous := o.allocationExplanation
+	due := now.Sub(o.unhealthySince) >= o.settings.AllocationExplainDelay && now.Sub(o.explainedAt) >= allocationExplainInterval
 	o.mutex.Unlock()
+	if !due {
+		return previous
+	}
+
+	explanation, err := o.esClient.ExplainAllocation(ctx)
+	if err != nil {
+		log.V(1).Info("Unable to explain shard allocation", "error", err, "namespace", o.cluster.Namespace, "es_name", o.cluster.Name)
+		return previous
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.allocationExplanation = explanation
+	o.explainedAt = now
+	return explanation
 }
@@ -6,6 +6,7 @@ package observer
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/types"
 
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	fixtures "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client/test_fixtures"
@@ -69,6 +71,51 @@ func TestObserver_retrieveState_nilFunction(t *testing.T) {
 	observer.retrieveState()
 }
 
+func TestObserver_explainAllocation(t *testing.T) {
+	explanations := int32(0)
+	esClient := client.NewMockClient(version.MustParse("7.15.0"), func(req *http.Request) *http.Response {
+		atomic.AddInt32(&explanations, 1)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"index":"my-index","shard":0,"primary":false,"current_state":"unassigned","allocate_explanation":"cannot allocate"}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	})
+	observer := Observer{esClient: esClient, settings: Settings{AllocationExplainDelay: 5 * time.Minute}}
+	yellow := State{ClusterHealth: &client.Health{Status: esv1.ElasticsearchYellowHealth}}
+	green := State{ClusterHealth: &client.Health{Status: esv1.ElasticsearchGreenHealth}}
+	start := time.Now()
+
+	// not explained before the delay
+	require.Nil(t, observer.explainAllocation(context.Background(), yellow, start))
+	require.Nil(t, observer.explainAllocation(context.Background(), yellow, start.Add(4*time.Minute)))
+	require.Equal(t, int32(0), atomic.LoadInt32(&explanations))
+
+	// explained once the cluster has been unhealthy for the delay
+	explanation := observer.explainAllocation(context.Background(), yellow, start.Add(5*time.Minute))
+	require.NotNil(t, explanation)
+	require.Equal(t, "my-index", explanation.Index)
+	require.Equal(t, int32(1), atomic.LoadInt32(&explanations))
+
+	// the last explanation is reused until the explain interval is elapsed
+	require.Equal(t, explanation, observer.explainAllocation(context.Background(), yellow, start.Add(5*time.Minute+30*time.Second)))
+	require.Equal(t, int32(1), atomic.LoadInt32(&explanations))
+	require.NotNil(t, observer.explainAllocation(context.Background(), yellow, start.Add(6*time.Minute)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&explanations))
+
+	// reset once the cluster is green again
+	require.Nil(t, observer.explainAllocation(context.Background(), green, start.Add(7*time.Minute)))
+	require.Nil(t, observer.explainAllocation(context.Background(), yellow, start.Add(8*time.Minute)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&explanations))
+
+	// disabled with a zero delay
+	observer = Observer{esClient: esClient}
+	require.Nil(t, observer.explainAllocation(context.Background(), yellow, start))
+	require.Nil(t, observer.explainAllocation(context.Background(), yellow, start.Add(time.Hour)))
+	require.Equal(t, int32(2), atomic.LoadInt32(&explanations))
+}
+
 func TestNewObserver(t *testing.T) {
 	events := make(chan types.NamespacedName)
 	onObservation := func(cluster types.NamespacedName, previousState State, newState State) {
@@ -0,0 +1,126 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package observer
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// jitterFactor is the maximum fraction of the observation interval randomly added to or removed from the delay
+	// between two observations, so that clusters observed since the same time are not polled in lockstep.
+	jitterFactor = 0.2
+	// maxUnreachableInterval is the maximum interval the observation interval of an unreachable cluster is increased to.
+	maxUnreachableInterval = 1 * time.Minute
+)
+
+// sharedScheduler schedules the observations of all the observers.
+var sharedScheduler = newScheduler()
+
+// scheduledObservation is an observation due at a given time.
+type scheduledObservation struct {
+	observer *Observer
+	at       time.Time
+}
+
+// observationQueue is a heap of observations, ordered by due time.
+type observationQueue []scheduledObservation
+
+func (q observationQueue) Len() int            { return len(q) }
+func (q observationQueue) Less(i, j int) bool  { return q[i].at.Before(q[j].at) }
+func (q observationQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *observationQueue) Push(x interface{}) { *q = append(*q, x.(scheduledObservation)) }
+func (q *observationQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// scheduler runs the observations of many observers from a single goroutine, each observation being retrieved in its
+// own short-lived goroutine once due, and the next observation of the same observer scheduled once it completes.
+type scheduler struct {
+	mutex  sync.Mutex
+	queue  observationQueue
+	wakeup chan struct{}
+	start  sync.Once
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{wakeup: make(chan struct{}, 1)}
+}
+
+// schedule schedules an observation for the given observer after the given delay.
+func (s *scheduler) schedule(o *Observer, delay time.Duration) {
+	s.mutex.Lock()
+	heap.Push(&s.queue, scheduledObservation{observer: o, at: time.Now().Add(delay)})
+	s.mutex.Unlock()
+
+	s.start.Do(func() { go s.run() })
+	// wake the scheduler up in case this observation is due before the ones already scheduled
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// popDue removes and returns the observations due at the given time, along with the due time of the next observation
+// if any.
+func (s *scheduler) popDue(now time.Time) ([]*Observer, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var due []*Observer
+	for s.queue.Len() > 0 {
+		if next := s.queue[0]; next.at.After(now) {
+			return due, next.at
+		}
+		due = append(due, heap.Pop(&s.queue).(scheduledObservation).observer)
+	}
+	return due, time.Time{}
+}
+
+func (s *scheduler) run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-s.wakeup:
+		}
+		due, next := s.popDue(time.Now())
+		for _, o := range due {
+			go s.observe(o)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// observe retrieves the state of the cluster of the given observer, then schedules its next observation.
+func (s *scheduler) observe(o *Observer) {
+	if o.stopped() {
+		return
+	}
+	o.retrieveState()
+	if o.stopped() {
+		return
+	}
+	s.schedule(o, o.nextObservationDelay())
+}
+
+// withJitter randomly adds or removes up to jitterFactor of the given duration.
+func withJitter(d time.Duration) time.Duration {
+	return d + time.Duration((rand.Float64()*2-1)*jitterFactor*float64(d)) //nolint:gosec
+}
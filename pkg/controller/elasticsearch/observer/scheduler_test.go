@@ -0,0 +1,142 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package observer
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func requireWithinJitter(t *testing.T, expected, actual time.Duration) {
+	t.Helper()
+	margin := time.Duration(jitterFactor * float64(expected))
+	require.GreaterOrEqual(t, actual, expected-margin)
+	require.LessOrEqual(t, actual, expected+margin)
+}
+
+func TestObserver_nextObservationDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    time.Duration
+		unreachable int
+		quietSince  time.Time
+		want        time.Duration
+	}{
+		{
+			name:     "reachable cluster",
+			interval: 10 * time.Second,
+			want:     10 * time.Second,
+		},
+		{
+			name:        "interval doubled for each unreachable observation",
+			interval:    10 * time.Second,
+			unreachable: 2,
+			want:        40 * time.Second,
+		},
+		{
+			name:        "interval capped while unreachable",
+			interval:    10 * time.Second,
+			unreachable: 10,
+			want:        maxUnreachableInterval,
+		},
+		{
+			name:        "configured interval larger than the cap",
+			interval:    5 * time.Minute,
+			unreachable: 3,
+			want:        5 * time.Minute,
+		},
+		{
+			name:       "quiet cluster not in slow-poll mode yet",
+			interval:   10 * time.Second,
+			quietSince: time.Now().Add(-30 * time.Minute),
+			want:       10 * time.Second,
+		},
+		{
+			name:       "quiet cluster in slow-poll mode",
+			interval:   10 * time.Second,
+			quietSince: time.Now().Add(-2 * time.Hour),
+			want:       5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Observer{
+				settings: Settings{
+					ObservationInterval: tt.interval,
+					SlowPollAfter:       1 * time.Hour,
+					SlowPollInterval:    5 * time.Minute,
+				},
+				unreachable: tt.unreachable,
+				quietSince:  tt.quietSince,
+			}
+			for i := 0; i < 10; i++ {
+				requireWithinJitter(t, tt.want, o.nextObservationDelay())
+			}
+		})
+	}
+}
+
+func TestObserver_unreachable(t *testing.T) {
+	observer := Observer{esClient: fakeEsClient(true)}
+	observer.retrieveState()
+	observer.retrieveState()
+	require.Equal(t, 2, observer.unreachable)
+
+	observer.esClient = fakeEsClient(false)
+	observer.retrieveState()
+	require.Equal(t, 0, observer.unreachable)
+}
+
+func TestObserver_Quiet(t *testing.T) {
+	observer := Observer{esClient: fakeEsClient(false), settings: Settings{SlowPollAfter: 1 * time.Hour}}
+	observer.retrieveState()
+	require.False(t, observer.quietSince.IsZero())
+	require.False(t, observer.Quiet())
+
+	// green for long enough
+	observer.quietSince = time.Now().Add(-2 * time.Hour)
+	observer.retrieveState()
+	require.True(t, observer.Quiet())
+
+	// a change ends the slow-poll mode
+	observer.MarkChanged()
+	require.False(t, observer.Quiet())
+
+	// as well as an unreachable cluster
+	observer.quietSince = time.Now().Add(-2 * time.Hour)
+	observer.esClient = fakeEsClient(true)
+	observer.retrieveState()
+	require.True(t, observer.quietSince.IsZero())
+	require.False(t, observer.Quiet())
+}
+
+func TestScheduler_popDue(t *testing.T) {
+	s := newScheduler()
+	now := time.Now()
+	observers := make([]*Observer, 3)
+	for i := range observers {
+		observers[i] = &Observer{cluster: types.NamespacedName{Name: string(rune('a' + i))}}
+	}
+	s.mutex.Lock()
+	s.queue = observationQueue{
+		{observer: observers[2], at: now.Add(time.Minute)},
+		{observer: observers[0], at: now.Add(-time.Second)},
+		{observer: observers[1], at: now},
+	}
+	heap.Init(&s.queue)
+	s.mutex.Unlock()
+
+	due, next := s.popDue(now)
+	require.Equal(t, []*Observer{observers[0], observers[1]}, due)
+	require.Equal(t, now.Add(time.Minute), next)
+
+	due, next = s.popDue(now.Add(time.Minute))
+	require.Equal(t, []*Observer{observers[2]}, due)
+	require.True(t, next.IsZero())
+}
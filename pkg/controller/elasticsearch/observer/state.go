@@ -17,6 +17,8 @@ type State struct {
 	// TODO: verify usages of the below never assume they are set (check for nil)
 	// ClusterHealth is the current traffic light health as reported by Elasticsearch.
 	ClusterHealth *esclient.Health
+	// AllocationExplanation explains why a shard is unassigned, once the cluster has not been green for long enough.
+	AllocationExplanation *esclient.AllocationExplanation
 }
 
 // RetrieveState returns the current Elasticsearch cluster state
@@ -33,8 +33,8 @@ func WatchClusterHealthChange(m *Manager) *source.Channel {
 // event when a cluster's observed health has changed.
 func healthChangeListener(reconciliation chan event.GenericEvent) OnObservation {
 	return func(cluster types.NamespacedName, previous State, current State) {
-		// no-op if health hasn't change
-		if !hasHealthChanged(previous, current) {
+		// no-op if neither health nor the explanation of unassigned shards changed
+		if !hasHealthChanged(previous, current) && !hasAllocationExplanationChanged(previous, current) {
 			return
 		}
 
@@ -64,3 +64,13 @@ func hasHealthChanged(previous State, current State) bool {
 		return true
 	}
 }
+
+// hasAllocationExplanationChanged returns true if previous and new explain unassigned shards differently.
+func hasAllocationExplanationChanged(previous State, current State) bool {
+	switch {
+	case previous.AllocationExplanation == nil || current.AllocationExplanation == nil:
+		return previous.AllocationExplanation != current.AllocationExplanation
+	default:
+		return previous.AllocationExplanation.Summary() != current.AllocationExplanation.Summary()
+	}
+}
@@ -56,3 +56,51 @@ func Test_hasHealthChanged(t *testing.T) {
 		})
 	}
 }
+
+func Test_hasAllocationExplanationChanged(t *testing.T) {
+	explanation := &client.AllocationExplanation{Index: "my-index", CurrentState: "unassigned", AllocateExplanation: "cannot allocate"}
+	tests := []struct {
+		name     string
+		previous State
+		new      State
+		want     bool
+	}{
+		{
+			name:     "both nil",
+			previous: State{},
+			new:      State{},
+			want:     false,
+		},
+		{
+			name:     "previous nil",
+			previous: State{},
+			new:      State{AllocationExplanation: explanation},
+			want:     true,
+		},
+		{
+			name:     "new nil",
+			previous: State{AllocationExplanation: explanation},
+			new:      State{},
+			want:     true,
+		},
+		{
+			name:     "different summaries",
+			previous: State{AllocationExplanation: explanation},
+			new:      State{AllocationExplanation: &client.AllocationExplanation{Index: "other-index", CurrentState: "unassigned"}},
+			want:     true,
+		},
+		{
+			name:     "same summaries",
+			previous: State{AllocationExplanation: explanation},
+			new:      State{AllocationExplanation: &client.AllocationExplanation{Index: "my-index", CurrentState: "unassigned", AllocateExplanation: "cannot allocate"}},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasAllocationExplanationChanged(tt.previous, tt.new); got != tt.want {
+				t.Errorf("hasAllocationExplanationChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
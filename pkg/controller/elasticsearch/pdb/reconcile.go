@@ -6,56 +6,99 @@ package pdb
 
 import (
 	"context"
+	"sort"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	commonname "github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
 )
 
 // Reconcile ensures that a PodDisruptionBudget exists for this cluster, inheriting the spec content.
 // The default PDB we setup dynamically adapts MinAvailable to the number of nodes in the cluster.
+// If the spec requests budgets per tier, one PDB is setup per tier instead of the default one.
 // If the spec has disabled the default PDB, it will ensure none exist.
 func Reconcile(k8sClient k8s.Client, es esv1.Elasticsearch, statefulSets sset.StatefulSetList) error {
-	expected, err := expectedPDB(es, statefulSets)
+	expected, err := expectedPDBs(es, statefulSets)
 	if err != nil {
 		return err
 	}
-	if expected == nil {
-		return deleteDefaultPDB(k8sClient, es)
+	for _, pdb := range expected {
+		if err := reconcilePDB(k8sClient, pdb); err != nil {
+			return err
+		}
 	}
+	return deleteUnexpectedPDBs(k8sClient, es, expected)
+}
 
+// reconcilePDB creates or updates in place the given PodDisruptionBudget, so that the Pods it selects are never left
+// without a budget.
+func reconcilePDB(k8sClient k8s.Client, expected v1beta1.PodDisruptionBudget) error {
 	// label the PDB with a hash of its content, for comparison purposes
-	expected.Labels = hash.SetTemplateHashLabel(expected.Labels, expected)
+	expected.Labels = hash.SetTemplateHashLabel(expected.Labels, &expected)
 
-	// reconcile actual vs. expected
-	var actual v1beta1.PodDisruptionBudget
-	err = k8sClient.Get(context.Background(), k8s.ExtractNamespacedName(expected), &actual)
-	if err != nil && apierrors.IsNotFound(err) {
-		return k8sClient.Create(context.Background(), expected)
-	}
+	reconciled := &v1beta1.PodDisruptionBudget{}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     k8sClient,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			return hash.GetTemplateHashLabel(expected.Labels) != hash.GetTemplateHashLabel(reconciled.Labels)
+		},
+		UpdateReconciled: func() {
+			reconciled.Labels = expected.Labels
+			reconciled.Annotations = expected.Annotations
+			reconciled.Spec = expected.Spec
+		},
+	})
+}
 
-	if hash.GetTemplateHashLabel(expected.Labels) != hash.GetTemplateHashLabel(actual.Labels) {
-		// Actual does not match expected, let's update the PDB.
-		// PDB Spec cannot be updated, we'll have to delete then recreate.
-		// Which means there is a time window in between where we don't have a PDB anymore.
-		// TODO: this is not true anymore starting k8s 1.15+ and this PR https://github.com/kubernetes/kubernetes/pull/69867
+// deleteUnexpectedPDBs deletes the default PDB and the PDBs of former tiers if they are not expected anymore.
+func deleteUnexpectedPDBs(k8sClient k8s.Client, es esv1.Elasticsearch, expected []v1beta1.PodDisruptionBudget) error {
+	expectedNames := set.Make()
+	for _, pdb := range expected {
+		expectedNames.Add(pdb.Name)
+	}
+	if !expectedNames.Has(esv1.DefaultPodDisruptionBudget(es.Name)) {
 		if err := deleteDefaultPDB(k8sClient, es); err != nil {
 			return err
 		}
-		return k8sClient.Create(context.Background(), expected)
 	}
 
+	var actual v1beta1.PodDisruptionBudgetList
+	if err := k8sClient.List(
+		context.Background(),
+		&actual,
+		client.InNamespace(es.Namespace),
+		client.MatchingLabels(label.NewLabels(k8s.ExtractNamespacedName(&es))),
+	); err != nil {
+		return err
+	}
+	for i := range actual.Items {
+		pdb := actual.Items[i]
+		if expectedNames.Has(pdb.Name) || !metav1.IsControlledBy(&pdb, &es) {
+			continue
+		}
+		if err := k8sClient.Delete(context.Background(), &pdb); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -81,9 +124,9 @@ func deleteDefaultPDB(k8sClient k8s.Client, es esv1.Elasticsearch) error {
 	return nil
 }
 
-// expectedPDB returns a PDB according to the given ES spec.
-// It may return nil if the PDB has been explicitly disabled in the ES spec.
-func expectedPDB(es esv1.Elasticsearch, statefulSets sset.StatefulSetList) (*v1beta1.PodDisruptionBudget, error) {
+// expectedPDBs returns the PDBs according to the given ES spec.
+// It may return no PDB if the PDB has been explicitly disabled in the ES spec.
+func expectedPDBs(es esv1.Elasticsearch, statefulSets sset.StatefulSetList) ([]v1beta1.PodDisruptionBudget, error) {
 	template := es.Spec.PodDisruptionBudget.DeepCopy()
 	if template.IsDisabled() {
 		return nil, nil
@@ -92,29 +135,177 @@ func expectedPDB(es esv1.Elasticsearch, statefulSets sset.StatefulSetList) (*v1b
 		template = &commonv1.PodDisruptionBudgetTemplate{}
 	}
 
+	if template.Spec.Selector != nil || template.Spec.MaxUnavailable != nil || template.Spec.MinAvailable != nil {
+		// use the user-defined spec
+		expected, err := newPDB(es, *template, esv1.DefaultPodDisruptionBudget(es.Name), template.Spec)
+		if err != nil {
+			return nil, err
+		}
+		return []v1beta1.PodDisruptionBudget{expected}, nil
+	}
+
+	if es.Spec.PodDisruptionBudgetPerTier == nil {
+		// set our default spec
+		expected, err := newPDB(es, *template, esv1.DefaultPodDisruptionBudget(es.Name), buildPDBSpec(es, statefulSets))
+		if err != nil {
+			return nil, err
+		}
+		return []v1beta1.PodDisruptionBudget{expected}, nil
+	}
+
+	tiers := tierStatefulSets(statefulSets)
+	expected := make([]v1beta1.PodDisruptionBudget, 0, len(tiers))
+	for _, tier := range tiers {
+		pdb, err := newPDB(es, *template, esv1.TierPodDisruptionBudget(es.Name, tierName(tier)), buildTierPDBSpec(es, tier, statefulSets))
+		if err != nil {
+			return nil, err
+		}
+		expected = append(expected, pdb)
+	}
+	return expected, nil
+}
+
+// newPDB returns a PDB with the given name and spec, inheriting the ObjectMeta of the given template.
+func newPDB(
+	es esv1.Elasticsearch,
+	template commonv1.PodDisruptionBudgetTemplate,
+	name string,
+	spec v1beta1.PodDisruptionBudgetSpec,
+) (v1beta1.PodDisruptionBudget, error) {
 	expected := v1beta1.PodDisruptionBudget{
-		ObjectMeta: template.ObjectMeta,
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       spec,
 	}
 
 	// inherit user-provided ObjectMeta, but set our own name & namespace
-	expected.Name = esv1.DefaultPodDisruptionBudget(es.Name)
+	expected.Name = name
 	expected.Namespace = es.Namespace
 	// and append our labels
 	expected.Labels = maps.MergePreservingExistingKeys(expected.Labels, label.NewLabels(k8s.ExtractNamespacedName(&es)))
 	// set owner reference for deletion upon ES resource deletion
 	if err := controllerutil.SetControllerReference(&es, &expected, scheme.Scheme); err != nil {
-		return nil, err
+		return v1beta1.PodDisruptionBudget{}, err
 	}
+	return expected, nil
+}
 
-	if template.Spec.Selector != nil || template.Spec.MaxUnavailable != nil || template.Spec.MinAvailable != nil {
-		// use the user-defined spec
-		expected.Spec = template.Spec
-	} else {
-		// set our default spec
-		expected.Spec = buildPDBSpec(es, statefulSets)
+// dataTierRoles are the roles of the nodes holding the shards of a data tier.
+var dataTierRoles = []esv1.NodeRole{
+	esv1.DataContentRole,
+	esv1.DataHotRole,
+	esv1.DataWarmRole,
+	esv1.DataColdRole,
+	esv1.DataFrozenRole,
+}
+
+// tierKeys returns the keys identifying the tiers the Pods of the given StatefulSet belong to. StatefulSets sharing a
+// key must share a budget: they hold master votes or shards of the same data tier.
+func tierKeys(statefulSet appsv1.StatefulSet) []string {
+	labels := statefulSet.Spec.Template.Labels
+	var keys []string
+	if label.HasNodeRole(esv1.MasterRole, labels) {
+		keys = append(keys, string(esv1.MasterRole))
+	}
+	// generic data nodes hold the shards of all the data tiers
+	genericData := label.HasNodeRole(esv1.DataRole, labels)
+	if genericData {
+		keys = append(keys, string(esv1.DataRole))
+	}
+	for _, role := range dataTierRoles {
+		if genericData || label.HasNodeRole(role, labels) {
+			keys = append(keys, string(role))
+		}
+	}
+	if len(keys) == 0 {
+		// other nodes hold no shard and no vote, group them by roles
+		keys = append(keys, "roles:"+label.StatefulSetNodeTier(statefulSet))
+	}
+	return keys
+}
+
+// maxTierNameLength is the maximum length of a tier name keeping the "-es-<tier>-pdb" suffix of the name of its PDB
+// within the limits of the names of the resources of a cluster.
+const maxTierNameLength = commonname.MaxSuffixLength - len("-es--pdb")
+
+// tierNameRoles are the roles naming the tiers holding master votes or shards, in order of precedence.
+var tierNameRoles = []esv1.NodeRole{
+	esv1.MasterRole,
+	esv1.DataRole,
+	esv1.DataHotRole,
+	esv1.DataWarmRole,
+	esv1.DataColdRole,
+	esv1.DataFrozenRole,
+	esv1.DataContentRole,
+}
+
+// tierName returns a name identifying the given tier, which does not change as long as the roles of its nodes do not.
+// Tiers holding master votes or shards are named after the first of their keys in tierNameRoles, since a key belongs
+// to a single tier. Other tiers are named after the roles of their nodes.
+func tierName(tier sset.StatefulSetList) string {
+	keys := set.Make()
+	for _, statefulSet := range tier {
+		for _, key := range tierKeys(statefulSet) {
+			keys.Add(key)
+		}
+	}
+	name := ""
+	for _, role := range tierNameRoles {
+		if keys.Has(string(role)) {
+			name = string(role)
+			break
+		}
+	}
+	if name == "" {
+		// nodes holding no master vote and no shard share a tier only if they have the same roles
+		name = strings.TrimPrefix(keys.AsSlice()[0], "roles:")
+	}
+	if name == "" {
+		name = "coordinating"
+	}
+	name = strings.NewReplacer("_", "-", ",", "-").Replace(name)
+	if len(name) > maxTierNameLength {
+		return hash.HashObject(name)
+	}
+	return name
+}
+
+// tierStatefulSets groups the given StatefulSets by tier, made of the nodes that hold master votes or shards of the
+// same data tier, or of the nodes with the same roles otherwise. Tiers and the StatefulSets of each tier are sorted
+// by name.
+func tierStatefulSets(statefulSets sset.StatefulSetList) []sset.StatefulSetList {
+	// merge the tiers of the StatefulSets sharing a key
+	tierOfKey := make(map[string]int)
+	tierOf := make([]int, len(statefulSets))
+	var find func(i int) int
+	find = func(i int) int {
+		if tierOf[i] != i {
+			tierOf[i] = find(tierOf[i])
+		}
+		return tierOf[i]
+	}
+	for i, statefulSet := range statefulSets {
+		tierOf[i] = i
+		for _, key := range tierKeys(statefulSet) {
+			if j, exists := tierOfKey[key]; exists {
+				tierOf[find(i)] = find(j)
+			} else {
+				tierOfKey[key] = i
+			}
+		}
 	}
 
-	return &expected, nil
+	byTier := make(map[int]sset.StatefulSetList)
+	for i, statefulSet := range statefulSets {
+		tier := find(i)
+		byTier[tier] = append(byTier[tier], statefulSet)
+	}
+	tiers := make([]sset.StatefulSetList, 0, len(byTier))
+	for _, tier := range byTier {
+		sort.Slice(tier, func(i, j int) bool { return tier[i].Name < tier[j].Name })
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i][0].Name < tiers[j][0].Name })
+	return tiers
 }
 
 // buildPDBSpec returns a PDBSpec computed from the current StatefulSets,
@@ -141,6 +332,67 @@ func buildPDBSpec(es esv1.Elasticsearch, statefulSets sset.StatefulSetList) v1be
 	}
 }
 
+// buildTierPDBSpec returns a PDBSpec selecting the Pods of the given tier, computed from its StatefulSets and the
+// StatefulSets of the whole cluster.
+func buildTierPDBSpec(es esv1.Elasticsearch, tier sset.StatefulSetList, statefulSets sset.StatefulSetList) v1beta1.PodDisruptionBudgetSpec {
+	minAvailable := tier.ExpectedNodeCount() - allowedTierDisruptions(es, tier, statefulSets)
+	if minAvailable < 0 {
+		minAvailable = 0
+	}
+	minAvailableIntStr := intstr.IntOrString{Type: intstr.Int, IntVal: minAvailable}
+
+	statefulSetNames := make([]string, 0, len(tier))
+	for _, statefulSet := range tier {
+		statefulSetNames = append(statefulSetNames, statefulSet.Name)
+	}
+
+	return v1beta1.PodDisruptionBudgetSpec{
+		// match all pods of the StatefulSets of this tier
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				label.ClusterNameLabelName: es.Name,
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      label.StatefulSetNameLabelName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   statefulSetNames,
+			}},
+		},
+		MinAvailable: &minAvailableIntStr,
+	}
+}
+
+// allowedTierDisruptions returns the number of Pods of the given tier that we allow to be disrupted while keeping the
+// cluster healthy.
+func allowedTierDisruptions(es esv1.Elasticsearch, tier sset.StatefulSetList, statefulSets sset.StatefulSetList) int32 {
+	if statefulSets.ExpectedNodeCount() == 1 {
+		// single node cluster (not highly-available)
+		// allow the node to be disrupted to ensure K8s nodes operations can be performed
+		return 1
+	}
+	if es.Status.Health != esv1.ElasticsearchGreenHealth {
+		// A non-green cluster may become red if we disrupt one node, don't allow it.
+		return 0
+	}
+	if tier.ExpectedMasterNodesCount() > 0 && statefulSets.ExpectedMasterNodesCount() == 1 {
+		// The tier holds the single master of the cluster, don't allow it to be removed.
+		return 0
+	}
+	if tier.ExpectedDataNodesCount() > 0 && statefulSets.ExpectedDataNodesCount() == 1 {
+		// The tier holds the single data node of the cluster, don't allow it to be removed.
+		return 0
+	}
+	if tier.ExpectedIngestNodesCount() > 0 && statefulSets.ExpectedIngestNodesCount() == 1 {
+		// The tier holds the single ingest node of the cluster, don't allow it to be removed.
+		return 0
+	}
+	if tier.ExpectedMasterNodesCount() > 0 {
+		// Disrupting more than one master at a time may cause the loss of the quorum.
+		return 1
+	}
+	return es.Spec.PodDisruptionBudgetPerTier.GetMaxUnavailableOrDefault()
+}
+
 // allowedDisruptions returns the number of Pods that we allow to be disrupted while keeping the cluster healthy.
 func allowedDisruptions(es esv1.Elasticsearch, actualSsets sset.StatefulSetList) int32 {
 	if actualSsets.ExpectedNodeCount() == 1 {
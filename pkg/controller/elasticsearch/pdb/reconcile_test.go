@@ -7,15 +7,19 @@ package pdb
 import (
 	"context"
 	"reflect"
+	"sort"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
@@ -148,7 +152,7 @@ func intStrPtr(intStr intstr.IntOrString) *intstr.IntOrString {
 	return &intStr
 }
 
-func Test_expectedPDB(t *testing.T) {
+func Test_expectedPDBs(t *testing.T) {
 	type args struct {
 		es           esv1.Elasticsearch
 		statefulSets sset.StatefulSetList
@@ -246,14 +250,15 @@ func Test_expectedPDB(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var want []v1beta1.PodDisruptionBudget
 			if tt.want != nil {
 				// set owner ref
-				tt.want = withOwnerRef(tt.want, tt.args.es)
+				want = []v1beta1.PodDisruptionBudget{*withOwnerRef(tt.want, tt.args.es)}
 			}
-			got, err := expectedPDB(tt.args.es, tt.args.statefulSets)
+			got, err := expectedPDBs(tt.args.es, tt.args.statefulSets)
 			require.NoError(t, err)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("expectedPDB() got = %v, want %v", got, tt.want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expectedPDBs() got = %v, want %v", got, want)
 			}
 		})
 	}
@@ -359,3 +364,288 @@ func Test_allowedDisruptions(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcile_PerTier(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "ns"},
+		Spec:       esv1.ElasticsearchSpec{PodDisruptionBudgetPerTier: &esv1.PodDisruptionBudgetPerTier{}},
+		Status:     esv1.ElasticsearchStatus{Health: esv1.ElasticsearchGreenHealth},
+	}
+	statefulSets := sset.StatefulSetList{
+		sset.TestSset{Name: "cluster-es-masters", Replicas: 3, Master: true}.Build(),
+		sset.TestSset{Name: "cluster-es-hot-b", Replicas: 2, Data: true, Ingest: true}.Build(),
+		sset.TestSset{Name: "cluster-es-hot-a", Replicas: 3, Data: true, Ingest: true}.Build(),
+	}
+	tierPDB := func(name string, minAvailable int, statefulSetNames ...string) *v1beta1.PodDisruptionBudget {
+		return withOwnerRef(&v1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+				Labels:    map[string]string{label.ClusterNameLabelName: "cluster", common.TypeLabelName: label.Type},
+			},
+			Spec: v1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: intStrPtr(intstr.FromInt(minAvailable)),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{label.ClusterNameLabelName: "cluster"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      label.StatefulSetNameLabelName,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   statefulSetNames,
+					}},
+				},
+			},
+		}, es)
+	}
+	defaultPDB := withOwnerRef(&v1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esv1.DefaultPodDisruptionBudget("cluster"),
+			Namespace: "ns",
+			Labels:    map[string]string{label.ClusterNameLabelName: "cluster", common.TypeLabelName: label.Type},
+		},
+	}, es)
+	k8sClient := k8s.NewFakeClient(defaultPDB)
+	getPDBs := func() map[string]v1beta1.PodDisruptionBudget {
+		var pdbs v1beta1.PodDisruptionBudgetList
+		require.NoError(t, k8sClient.List(context.Background(), &pdbs))
+		byName := make(map[string]v1beta1.PodDisruptionBudget, len(pdbs.Items))
+		for _, pdb := range pdbs.Items {
+			byName[pdb.Name] = pdb
+		}
+		return byName
+	}
+
+	// one PDB per tier replaces the default PDB, the tier of master nodes allows a single disruption
+	require.NoError(t, Reconcile(k8sClient, es, statefulSets))
+	pdbs := getPDBs()
+	require.Len(t, pdbs, 2)
+	hot := pdbs["cluster-es-data-pdb"]
+	comparison.RequireEqual(t, withHashLabel(tierPDB("cluster-es-data-pdb", 4, "cluster-es-hot-a", "cluster-es-hot-b")), &hot)
+	hotVersion := hot.ResourceVersion
+	masters := pdbs["cluster-es-master-pdb"]
+	comparison.RequireEqual(t, withHashLabel(tierPDB("cluster-es-master-pdb", 2, "cluster-es-masters")), &masters)
+
+	// the maximum number of disruptions per tier does not apply to the tier of master nodes
+	es.Spec.PodDisruptionBudgetPerTier.MaxUnavailable = pointer.Int32(2)
+	// a StatefulSet sorted before the others joins the tier, whose PDB keeps its name and is updated in place
+	statefulSets = append(statefulSets, sset.TestSset{Name: "cluster-es-data", Replicas: 0, Data: true}.Build())
+	require.NoError(t, Reconcile(k8sClient, es, statefulSets))
+	pdbs = getPDBs()
+	require.Len(t, pdbs, 2)
+	hot = pdbs["cluster-es-data-pdb"]
+	require.NotEqual(t, hotVersion, hot.ResourceVersion)
+	comparison.RequireEqual(t, withHashLabel(tierPDB("cluster-es-data-pdb", 3, "cluster-es-data", "cluster-es-hot-a", "cluster-es-hot-b")), &hot)
+	masters = pdbs["cluster-es-master-pdb"]
+	comparison.RequireEqual(t, withHashLabel(tierPDB("cluster-es-master-pdb", 2, "cluster-es-masters")), &masters)
+
+	// the PDBs of the tiers are replaced by the default PDB once budgets per tier are not requested anymore
+	es.Spec.PodDisruptionBudgetPerTier = nil
+	require.NoError(t, Reconcile(k8sClient, es, statefulSets))
+	pdbs = getPDBs()
+	require.Len(t, pdbs, 1)
+	assert.Contains(t, pdbs, esv1.DefaultPodDisruptionBudget("cluster"))
+}
+
+func Test_tierStatefulSets(t *testing.T) {
+	withRoles := func(name string, roles ...esv1.NodeRole) appsv1.StatefulSet {
+		statefulSet := sset.TestSset{Name: name}.Build()
+		for _, role := range roles {
+			statefulSet.Spec.Template.Labels["elasticsearch.k8s.elastic.co/node-"+string(role)] = "true"
+		}
+		return statefulSet
+	}
+	names := func(tiers []sset.StatefulSetList) [][]string {
+		result := make([][]string, 0, len(tiers))
+		for _, tier := range tiers {
+			result = append(result, tier.Names().AsSlice())
+		}
+		return result
+	}
+	tests := []struct {
+		name         string
+		statefulSets sset.StatefulSetList
+		want         [][]string
+	}{
+		{
+			name: "generic data nodes with different roles share a tier",
+			statefulSets: sset.StatefulSetList{
+				withRoles("data", esv1.DataRole),
+				withRoles("data-ingest", esv1.DataRole, esv1.IngestRole),
+				withRoles("ml", esv1.MLRole),
+			},
+			want: [][]string{{"data", "data-ingest"}, {"ml"}},
+		},
+		{
+			name: "master nodes with different roles share a tier",
+			statefulSets: sset.StatefulSetList{
+				withRoles("masters", esv1.MasterRole),
+				withRoles("masters-voting-only", esv1.MasterRole, esv1.VotingOnlyRole),
+			},
+			want: [][]string{{"masters", "masters-voting-only"}},
+		},
+		{
+			name: "dedicated data tiers have their own tier",
+			statefulSets: sset.StatefulSetList{
+				withRoles("hot", esv1.DataHotRole, esv1.DataContentRole),
+				withRoles("hot-ingest", esv1.DataHotRole, esv1.IngestRole),
+				withRoles("warm", esv1.DataWarmRole),
+				withRoles("coordinating"),
+			},
+			want: [][]string{{"coordinating"}, {"hot", "hot-ingest"}, {"warm"}},
+		},
+		{
+			name: "generic data nodes share the tier of the dedicated data tiers",
+			statefulSets: sset.StatefulSetList{
+				withRoles("hot", esv1.DataHotRole),
+				withRoles("warm", esv1.DataWarmRole),
+				withRoles("data", esv1.DataRole),
+			},
+			want: [][]string{{"data", "hot", "warm"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(tierStatefulSets(tt.statefulSets))
+			for _, tier := range got {
+				sort.Strings(tier)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_tierName(t *testing.T) {
+	withRoles := func(name string, roles ...esv1.NodeRole) appsv1.StatefulSet {
+		statefulSet := sset.TestSset{Name: name}.Build()
+		for _, role := range roles {
+			statefulSet.Spec.Template.Labels["elasticsearch.k8s.elastic.co/node-"+string(role)] = "true"
+		}
+		return statefulSet
+	}
+	tests := []struct {
+		name string
+		tier sset.StatefulSetList
+		want string
+	}{
+		{
+			name: "master nodes",
+			tier: sset.StatefulSetList{withRoles("masters", esv1.MasterRole), withRoles("masters-data", esv1.MasterRole, esv1.DataHotRole)},
+			want: "master",
+		},
+		{
+			name: "generic data nodes",
+			tier: sset.StatefulSetList{withRoles("a", esv1.DataRole, esv1.IngestRole), withRoles("b", esv1.DataWarmRole)},
+			want: "data",
+		},
+		{
+			name: "hot data tier",
+			tier: sset.StatefulSetList{withRoles("z", esv1.DataContentRole), withRoles("hot", esv1.DataHotRole, esv1.DataContentRole)},
+			want: "data-hot",
+		},
+		{
+			name: "content data tier",
+			tier: sset.StatefulSetList{withRoles("content", esv1.DataContentRole)},
+			want: "data-content",
+		},
+		{
+			name: "other nodes",
+			tier: sset.StatefulSetList{withRoles("ml", esv1.IngestRole, esv1.MLRole)},
+			want: "ingest-ml",
+		},
+		{
+			name: "coordinating nodes",
+			tier: sset.StatefulSetList{withRoles("coordinating")},
+			want: "coordinating",
+		},
+		{
+			name: "too many roles",
+			tier: sset.StatefulSetList{withRoles("other", esv1.IngestRole, esv1.MLRole, esv1.TransformRole, esv1.RemoteClusterClientRole)},
+			want: hash.HashObject("ingest-ml-transform-remote-cluster-client"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tierName(tt.tier))
+		})
+	}
+}
+
+func Test_allowedTierDisruptions(t *testing.T) {
+	green := esv1.ElasticsearchStatus{Health: esv1.ElasticsearchGreenHealth}
+	masters := sset.TestSset{Name: "masters", Replicas: 3, Master: true}.Build()
+	data := sset.TestSset{Name: "data", Replicas: 3, Data: true, Ingest: true}.Build()
+	tests := []struct {
+		name         string
+		es           esv1.Elasticsearch
+		tier         sset.StatefulSetList
+		statefulSets sset.StatefulSetList
+		want         int32
+	}{
+		{
+			name:         "single-node cluster: 1 disruption allowed",
+			es:           esv1.Elasticsearch{},
+			tier:         sset.StatefulSetList{sset.TestSset{Replicas: 1, Master: true, Data: true}.Build()},
+			statefulSets: sset.StatefulSetList{sset.TestSset{Replicas: 1, Master: true, Data: true}.Build()},
+			want:         1,
+		},
+		{
+			name:         "yellow health: no disruption allowed",
+			es:           esv1.Elasticsearch{Status: esv1.ElasticsearchStatus{Health: esv1.ElasticsearchYellowHealth}},
+			tier:         sset.StatefulSetList{data},
+			statefulSets: sset.StatefulSetList{masters, data},
+			want:         0,
+		},
+		{
+			name: "single master: no disruption allowed in its tier",
+			es:   esv1.Elasticsearch{Status: green},
+			tier: sset.StatefulSetList{sset.TestSset{Name: "masters", Replicas: 1, Master: true}.Build()},
+			statefulSets: sset.StatefulSetList{
+				sset.TestSset{Name: "masters", Replicas: 1, Master: true}.Build(),
+				data,
+			},
+			want: 0,
+		},
+		{
+			name: "single master: disruptions allowed in other tiers",
+			es:   esv1.Elasticsearch{Status: green, Spec: esv1.ElasticsearchSpec{PodDisruptionBudgetPerTier: &esv1.PodDisruptionBudgetPerTier{}}},
+			tier: sset.StatefulSetList{data},
+			statefulSets: sset.StatefulSetList{
+				sset.TestSset{Name: "masters", Replicas: 1, Master: true}.Build(),
+				data,
+			},
+			want: 1,
+		},
+		{
+			name: "single data node: no disruption allowed in its tier",
+			es:   esv1.Elasticsearch{Status: green},
+			tier: sset.StatefulSetList{sset.TestSset{Name: "data", Replicas: 1, Data: true}.Build()},
+			statefulSets: sset.StatefulSetList{
+				masters,
+				sset.TestSset{Name: "data", Replicas: 1, Data: true}.Build(),
+			},
+			want: 0,
+		},
+		{
+			name: "tier of master nodes: 1 disruption allowed at most",
+			es: esv1.Elasticsearch{Status: green, Spec: esv1.ElasticsearchSpec{
+				PodDisruptionBudgetPerTier: &esv1.PodDisruptionBudgetPerTier{MaxUnavailable: pointer.Int32(2)},
+			}},
+			tier:         sset.StatefulSetList{masters},
+			statefulSets: sset.StatefulSetList{masters, data},
+			want:         1,
+		},
+		{
+			name: "tier of data nodes: max unavailable allowed",
+			es: esv1.Elasticsearch{Status: green, Spec: esv1.ElasticsearchSpec{
+				PodDisruptionBudgetPerTier: &esv1.PodDisruptionBudgetPerTier{MaxUnavailable: pointer.Int32(2)},
+			}},
+			tier:         sset.StatefulSetList{data},
+			statefulSets: sset.StatefulSetList{masters, data},
+			want:         2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, allowedTierDisruptions(tt.es, tt.tier, tt.statefulSets))
+		})
+	}
+}
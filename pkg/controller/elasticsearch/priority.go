@@ -0,0 +1,29 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+)
+
+// isHighPriority returns true if the given cluster should be reconciled ahead of healthy clusters in a steady state:
+// clusters that are red, unreachable, or in the middle of applying changes.
+func (r *ReconcileElasticsearch) isHighPriority(cluster types.NamespacedName) bool {
+	var es esv1.Elasticsearch
+	if err := r.Client.Get(context.Background(), cluster, &es); err != nil {
+		// let the reconciliation deal with deleted clusters or errors
+		return true
+	}
+	return es.Generation != es.Status.ObservedGeneration ||
+		es.Status.Phase != esv1.ElasticsearchReadyPhase ||
+		es.Status.Health == esv1.ElasticsearchRedHealth ||
+		es.Status.Health == esv1.ElasticsearchUnknownHealth ||
+		meta.IsStatusConditionFalse(es.Status.Conditions, esv1.ElasticsearchReachableCondition)
+}
@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileElasticsearch_isHighPriority(t *testing.T) {
+	steadyState := func() esv1.Elasticsearch {
+		return esv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Generation: 2},
+			Status: esv1.ElasticsearchStatus{
+				Phase:              esv1.ElasticsearchReadyPhase,
+				Health:             esv1.ElasticsearchGreenHealth,
+				ObservedGeneration: 2,
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		mutate func(es *esv1.Elasticsearch)
+		want   bool
+	}{
+		{
+			name:   "healthy cluster in a steady state",
+			mutate: func(es *esv1.Elasticsearch) {},
+			want:   false,
+		},
+		{
+			name:   "yellow cluster in a steady state",
+			mutate: func(es *esv1.Elasticsearch) { es.Status.Health = esv1.ElasticsearchYellowHealth },
+			want:   false,
+		},
+		{
+			name:   "red cluster",
+			mutate: func(es *esv1.Elasticsearch) { es.Status.Health = esv1.ElasticsearchRedHealth },
+			want:   true,
+		},
+		{
+			name: "unreachable cluster",
+			mutate: func(es *esv1.Elasticsearch) {
+				es.Status.Conditions = []metav1.Condition{{Type: esv1.ElasticsearchReachableCondition, Status: metav1.ConditionFalse}}
+			},
+			want: true,
+		},
+		{
+			name:   "cluster applying changes",
+			mutate: func(es *esv1.Elasticsearch) { es.Status.Phase = esv1.ElasticsearchApplyingChangesPhase },
+			want:   true,
+		},
+		{
+			name:   "cluster spec updated",
+			mutate: func(es *esv1.Elasticsearch) { es.Generation = 3 },
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := steadyState()
+			tt.mutate(&es)
+			r := &ReconcileElasticsearch{Client: k8s.NewFakeClient(&es)}
+			require.Equal(t, tt.want, r.isHighPriority(k8s.ExtractNamespacedName(&es)))
+		})
+	}
+
+	r := &ReconcileElasticsearch{Client: k8s.NewFakeClient()}
+	require.True(t, r.isHighPriority(types.NamespacedName{Namespace: "ns", Name: "deleted"}))
+}
@@ -7,12 +7,17 @@ package reconcile
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/hints"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
@@ -22,6 +27,9 @@ import (
 
 var log = ulog.Log.WithName("elasticsearch-controller")
 
+// now returns the current time, overridden in tests.
+var now = time.Now
+
 // State holds the accumulated state during the reconcile loop including the response and a pointer to an
 // Elasticsearch resource for status updates.
 type State struct {
@@ -37,7 +45,9 @@ func NewState(c esv1.Elasticsearch) (*State, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &State{Recorder: events.NewRecorder(), cluster: c, status: *c.Status.DeepCopy(), hints: hints}, nil
+	status := *c.Status.DeepCopy()
+	status.ObservedGeneration = c.Generation
+	return &State{Recorder: events.NewRecorder(), cluster: c, status: status, hints: hints}, nil
 }
 
 // MustNewState like NewState but panics on error. Use recommended only in test code.
@@ -86,13 +96,51 @@ func (s *State) fetchMinRunningVersion(resourcesState ResourcesState) (*version.
 	return minPodVersion, nil
 }
 
+// setPhase sets the orchestration phase, the time at which the cluster entered it, and the conditions that derive
+// from it.
+func (s *State) setPhase(phase esv1.ElasticsearchOrchestrationPhase) {
+	s.status.Phase = phase
+	s.status.PhaseTransitionTime = s.cluster.Status.PhaseTransitionTime
+	switch {
+	case phase == "":
+		s.status.PhaseTransitionTime = nil
+	case phase != s.cluster.Status.Phase || s.status.PhaseTransitionTime == nil:
+		transitionTime := metav1.NewTime(now().Truncate(time.Second))
+		s.status.PhaseTransitionTime = &transitionTime
+	}
+	if phase == esv1.ElasticsearchMigratingDataPhase {
+		s.setCondition(esv1.DataMigratingCondition, true, esv1.DataMigrationInProgressReason, "Data is migrated away from the nodes to remove")
+	} else {
+		s.setCondition(esv1.DataMigratingCondition, false, esv1.NoDataMigrationReason, "No data migration is in progress")
+	}
+	if phase == esv1.ElasticsearchReadyPhase || phase == esv1.ElasticsearchMigratingDataPhase {
+		// node shutdowns are either over or making progress again
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.NodeShutdownStalledCondition)
+	}
+}
+
+// setCondition sets the condition of the given type. Its last transition time only changes along with its status.
+func (s *State) setCondition(conditionType string, status bool, reason string, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&s.status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: s.cluster.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
 func (s *State) updateWithPhase(
 	phase esv1.ElasticsearchOrchestrationPhase,
 	resourcesState ResourcesState,
 	observedState observer.State,
 ) *State {
 	s.status.AvailableNodes = int32(len(AvailableElasticsearchNodes(resourcesState.CurrentPods)))
-	s.status.Phase = phase
+	s.setPhase(phase)
 
 	lowestVersion, err := s.fetchMinRunningVersion(resourcesState)
 	if err != nil {
@@ -105,6 +153,7 @@ func (s *State) updateWithPhase(
 	if observedState.ClusterHealth != nil && observedState.ClusterHealth.Status != "" {
 		s.status.Health = observedState.ClusterHealth.Status
 	}
+	s.UpdateShardsUnassigned(observedState.AllocationExplanation)
 	return s
 }
 
@@ -132,7 +181,7 @@ func (s *State) IsElasticsearchReady(observedState observer.State) bool {
 // UpdateElasticsearchApplyingChanges marks Elasticsearch as being the applying changes phase in the resource status.
 func (s *State) UpdateElasticsearchApplyingChanges(pods []corev1.Pod) *State {
 	s.status.AvailableNodes = int32(len(AvailableElasticsearchNodes(pods)))
-	s.status.Phase = esv1.ElasticsearchApplyingChangesPhase
+	s.setPhase(esv1.ElasticsearchApplyingChangesPhase)
 	s.status.Health = esv1.ElasticsearchRedHealth
 	return s
 }
@@ -150,17 +199,32 @@ func (s *State) UpdateElasticsearchMigrating(
 	return s.updateWithPhase(esv1.ElasticsearchMigratingDataPhase, resourcesState, observedState)
 }
 
+// UpdateElasticsearchShutdownStalled marks Elasticsearch as being in the node shutdown stalled phase in the resource
+// status, and reports the stalled shutdown of the given node.
 func (s *State) UpdateElasticsearchShutdownStalled(
 	resourcesState ResourcesState,
 	observedState observer.State,
+	nodeName string,
 	reasonDetail string,
 ) *State {
+	s.UpdateNodeShutdownStalled(nodeName, reasonDetail)
+	return s.updateWithPhase(esv1.ElasticsearchNodeShutdownStalledPhase, resourcesState, observedState)
+}
+
+// UpdateNodeShutdownStalled reports that the shutdown of the given node cannot make progress, through an event and
+// the NodeShutdownStalledCondition. The condition is removed once the cluster is Ready or migrating data again.
+func (s *State) UpdateNodeShutdownStalled(nodeName string, reasonDetail string) {
 	s.AddEvent(
 		corev1.EventTypeWarning,
 		events.EventReasonStalled,
 		fmt.Sprintf("Requested topology change is stalled. User intervention maybe required if this condition persists. %s", reasonDetail),
 	)
-	return s.updateWithPhase(esv1.ElasticsearchNodeShutdownStalledPhase, resourcesState, observedState)
+	s.setCondition(
+		esv1.NodeShutdownStalledCondition,
+		true,
+		esv1.ShardMigrationStalledReason,
+		fmt.Sprintf("Shutdown of node %s is stalled: %s", nodeName, reasonDetail),
+	)
 }
 
 // Apply takes the current Elasticsearch status, compares it to the previous status, and updates the status accordingly.
@@ -179,13 +243,155 @@ func (s *State) Apply() ([]events.Event, *esv1.Elasticsearch) {
 	return s.Events(), &s.cluster
 }
 
+// Status returns the status of the cluster as updated so far.
+func (s *State) Status() esv1.ElasticsearchStatus {
+	return s.status
+}
+
 func (s *State) UpdateElasticsearchInvalid(err error) {
-	s.status.Phase = esv1.ElasticsearchResourceInvalid
+	s.setPhase(esv1.ElasticsearchResourceInvalid)
 	s.AddEvent(corev1.EventTypeWarning, events.EventReasonValidation, err.Error())
 }
 
 func (s *State) UpdateElasticsearchStatusPhase(orchPhase esv1.ElasticsearchOrchestrationPhase) {
-	s.status.Phase = orchPhase
+	s.setPhase(orchPhase)
+}
+
+// UpdateElasticsearchReachable sets the ElasticsearchReachableCondition according to the state of the circuit breaker
+// of the Elasticsearch client.
+func (s *State) UpdateElasticsearchReachable(circuitBreakerOpen bool) {
+	condition := metav1.Condition{
+		Type:               esv1.ElasticsearchReachableCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: s.cluster.Generation,
+		Reason:             esv1.CircuitBreakerClosedReason,
+		Message:            "Elasticsearch requests are performed",
+	}
+	if circuitBreakerOpen {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = esv1.CircuitBreakerOpenReason
+		condition.Message = "Elasticsearch requests fail fast after consecutive failures, until a periodic probe succeeds"
+	}
+	meta.SetStatusCondition(&s.status.Conditions, condition)
+}
+
+// UpdateMaintenanceMode sets the MaintenanceModeCondition while the cluster is in maintenance mode, and removes it
+// otherwise.
+func (s *State) UpdateMaintenanceMode(inMaintenance bool) {
+	if !inMaintenance {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.MaintenanceModeCondition)
+		return
+	}
+	meta.SetStatusCondition(&s.status.Conditions, metav1.Condition{
+		Type:               esv1.MaintenanceModeCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: s.cluster.Generation,
+		Reason:             esv1.MaintenanceAnnotationReason,
+		Message:            fmt.Sprintf("Changes to the cluster are paused, remove the %s annotation to resume them", esv1.MaintenanceAnnotation),
+	})
+}
+
+// UpdateReconciliationComplete sets the ReconciliationCompleteCondition according to the outcome of the
+// reconciliation and to the orchestration phase.
+func (s *State) UpdateReconciliationComplete(reconcileErr error) {
+	switch {
+	case reconcileErr != nil:
+		s.setCondition(esv1.ReconciliationCompleteCondition, false, esv1.ReconciliationErrorReason, reconcileErr.Error())
+	case s.status.Phase == esv1.ElasticsearchReadyPhase:
+		s.setCondition(esv1.ReconciliationCompleteCondition, true, esv1.ReconciledReason, "The cluster matches its specification")
+	default:
+		s.setCondition(
+			esv1.ReconciliationCompleteCondition,
+			false,
+			esv1.ReconciliationInProgressReason,
+			fmt.Sprintf("Changes are being applied, current phase is %s", s.status.Phase),
+		)
+	}
+}
+
+// UpdateUpgradeInProgress sets the UpgradeInProgressCondition according to the number of Pods to be restarted by a
+// rolling upgrade.
+func (s *State) UpdateUpgradeInProgress(podsToUpgrade int) {
+	if podsToUpgrade == 0 {
+		s.setCondition(esv1.UpgradeInProgressCondition, false, esv1.PodsUpToDateReason, "All Pods run their expected specification")
+		return
+	}
+	s.setCondition(
+		esv1.UpgradeInProgressCondition,
+		true,
+		esv1.PodsToUpgradeReason,
+		fmt.Sprintf("%d Pods must be restarted to run their expected specification", podsToUpgrade),
+	)
+}
+
+// UpdateUpgradeBlocked sets the UpgradeBlockedCondition with the given reason and message while a major version
+// upgrade is blocked by the pre-upgrade checks, and removes it if reason is empty.
+func (s *State) UpdateUpgradeBlocked(reason, message string) {
+	if reason == "" {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.UpgradeBlockedCondition)
+		return
+	}
+	s.setCondition(esv1.UpgradeBlockedCondition, true, reason, message)
+}
+
+// UpdateVotingConfigExclusions sets the VotingConfigExclusionsCondition while the given master nodes are excluded from
+// the voting configuration, and removes it if there are none.
+func (s *State) UpdateVotingConfigExclusions(excludedNodes []string) {
+	if len(excludedNodes) == 0 {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.VotingConfigExclusionsCondition)
+		return
+	}
+	s.setCondition(
+		esv1.VotingConfigExclusionsCondition,
+		true,
+		esv1.NodesExcludedFromVotingReason,
+		fmt.Sprintf("Nodes excluded from voting until they are removed: %s", strings.Join(excludedNodes, ", ")),
+	)
+}
+
+// UpdateSnapshotRestored sets the SnapshotRestoredCondition with the given reason and message while the initial
+// snapshot of the cluster is restored, and removes it if reason is empty.
+func (s *State) UpdateSnapshotRestored(reason, message string) {
+	if reason == "" {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.SnapshotRestoredCondition)
+		return
+	}
+	s.setCondition(esv1.SnapshotRestoredCondition, reason == esv1.SnapshotRestoredReason, reason, message)
+}
+
+// UpdateVolumeExpansion sets the VolumeExpansionCondition with the given reason and message while volumes are resized,
+// and removes it if reason is empty.
+func (s *State) UpdateVolumeExpansion(reason, message string) {
+	if reason == "" {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.VolumeExpansionCondition)
+		return
+	}
+	s.setCondition(esv1.VolumeExpansionCondition, reason != esv1.VolumeExpansionErrorReason, reason, message)
+}
+
+// UpdateShardsUnassigned sets the ShardsUnassignedCondition with a summary of the given explanation of the allocation
+// of unassigned shards, and emits an event each time that summary changes. The condition is removed if there is no
+// explanation.
+func (s *State) UpdateShardsUnassigned(explanation *esclient.AllocationExplanation) {
+	if explanation == nil {
+		meta.RemoveStatusCondition(&s.status.Conditions, esv1.ShardsUnassignedCondition)
+		return
+	}
+	summary := explanation.Summary()
+	if existing := meta.FindStatusCondition(s.status.Conditions, esv1.ShardsUnassignedCondition); existing == nil || existing.Message != summary {
+		s.AddEvent(corev1.EventTypeWarning, events.EventReasonUnhealthy, summary)
+	}
+	s.setCondition(esv1.ShardsUnassignedCondition, true, esv1.AllocationExplanationReason, summary)
+}
+
+// UpdateCertificatesReady sets the CertificatesReadyCondition according to the outcome of the certificates
+// reconciliation.
+func (s *State) UpdateCertificatesReady(err error) {
+	if err != nil {
+		s.setCondition(esv1.CertificatesReadyCondition, false, esv1.CertificatesErrorReason, err.Error())
+		return
+	}
+	s.setCondition(esv1.CertificatesReadyCondition, true, esv1.CertificatesReconciledReason, "Transport and HTTP certificates are reconciled")
 }
 
 // UpdateOrchestrationHints updates the orchestration hints collected so far with the hints in hint.
@@ -5,13 +5,16 @@
 package reconcile
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -135,6 +138,18 @@ func TestNodesAvailable(t *testing.T) {
 }
 
 func TestState_Apply(t *testing.T) {
+	fixedNow := time.Date(2021, 11, 3, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixedNow }
+	defer func() { now = time.Now }()
+	transitionTime := metav1.NewTime(fixedNow)
+	enteredAt := metav1.NewTime(fixedNow.Add(-3 * time.Hour))
+
+	noDataMigration := []metav1.Condition{{
+		Type:    esv1.DataMigratingCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  esv1.NoDataMigrationReason,
+		Message: "No data migration is in progress",
+	}}
 	tests := []struct {
 		name       string
 		cluster    esv1.Elasticsearch
@@ -156,9 +171,11 @@ func TestState_Apply(t *testing.T) {
 			},
 			wantEvents: []events.Event{},
 			wantStatus: &esv1.ElasticsearchStatus{
-				AvailableNodes: 0,
-				Health:         esv1.ElasticsearchRedHealth,
-				Phase:          esv1.ElasticsearchApplyingChangesPhase,
+				AvailableNodes:      0,
+				Health:              esv1.ElasticsearchRedHealth,
+				Phase:               esv1.ElasticsearchApplyingChangesPhase,
+				PhaseTransitionTime: &transitionTime,
+				Conditions:          noDataMigration,
 			},
 		},
 		{
@@ -178,6 +195,7 @@ func TestState_Apply(t *testing.T) {
 				AvailableNodes: 0,
 				Health:         esv1.ElasticsearchUnknownHealth,
 				Phase:          "",
+				Conditions:     noDataMigration,
 			},
 		},
 		{
@@ -192,11 +210,29 @@ func TestState_Apply(t *testing.T) {
 			},
 			wantEvents: []events.Event{{EventType: corev1.EventTypeWarning, Reason: events.EventReasonUnhealthy, Message: "Elasticsearch cluster health degraded"}},
 			wantStatus: &esv1.ElasticsearchStatus{
-				AvailableNodes: 0,
-				Health:         esv1.ElasticsearchRedHealth,
-				Phase:          esv1.ElasticsearchApplyingChangesPhase,
+				AvailableNodes:      0,
+				Health:              esv1.ElasticsearchRedHealth,
+				Phase:               esv1.ElasticsearchApplyingChangesPhase,
+				PhaseTransitionTime: &transitionTime,
+				Conditions:          noDataMigration,
 			},
 		},
+		{
+			name: "no status update while still applying changes",
+			cluster: esv1.Elasticsearch{
+				Status: esv1.ElasticsearchStatus{
+					Health:              esv1.ElasticsearchRedHealth,
+					Phase:               esv1.ElasticsearchApplyingChangesPhase,
+					PhaseTransitionTime: &enteredAt,
+					Conditions:          noDataMigration,
+				},
+			},
+			effects: func(s *State) {
+				s.UpdateElasticsearchApplyingChanges([]corev1.Pod{})
+			},
+			wantEvents: []events.Event{},
+			wantStatus: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -211,6 +247,9 @@ func TestState_Apply(t *testing.T) {
 			var actual *esv1.ElasticsearchStatus
 			if cluster != nil {
 				actual = &cluster.Status
+				for i := range actual.Conditions {
+					actual.Conditions[i].LastTransitionTime = metav1.Time{}
+				}
 			}
 			if !reflect.DeepEqual(actual, tt.wantStatus) {
 				t.Errorf("State.Apply() cluster = %v, wantStatus %v", cluster.Status, tt.wantStatus)
@@ -219,6 +258,61 @@ func TestState_Apply(t *testing.T) {
 	}
 }
 
+func TestState_setPhase(t *testing.T) {
+	fixedNow := time.Date(2021, 11, 3, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixedNow }
+	defer func() { now = time.Now }()
+	enteredAt := metav1.NewTime(fixedNow.Add(-3 * time.Hour))
+	justNow := metav1.NewTime(fixedNow)
+
+	tests := []struct {
+		name               string
+		status             esv1.ElasticsearchStatus
+		phase              esv1.ElasticsearchOrchestrationPhase
+		wantTransitionTime *metav1.Time
+	}{
+		{
+			name:               "entering a new phase",
+			status:             esv1.ElasticsearchStatus{Phase: esv1.ElasticsearchReadyPhase, PhaseTransitionTime: &enteredAt},
+			phase:              esv1.ElasticsearchApplyingChangesPhase,
+			wantTransitionTime: &justNow,
+		},
+		{
+			name:               "still applying changes",
+			status:             esv1.ElasticsearchStatus{Phase: esv1.ElasticsearchApplyingChangesPhase, PhaseTransitionTime: &enteredAt},
+			phase:              esv1.ElasticsearchApplyingChangesPhase,
+			wantTransitionTime: &enteredAt,
+		},
+		{
+			name:               "still migrating data",
+			status:             esv1.ElasticsearchStatus{Phase: esv1.ElasticsearchMigratingDataPhase, PhaseTransitionTime: &enteredAt},
+			phase:              esv1.ElasticsearchMigratingDataPhase,
+			wantTransitionTime: &enteredAt,
+		},
+		{
+			name:               "still ready",
+			status:             esv1.ElasticsearchStatus{Phase: esv1.ElasticsearchReadyPhase, PhaseTransitionTime: &enteredAt},
+			phase:              esv1.ElasticsearchReadyPhase,
+			wantTransitionTime: &enteredAt,
+		},
+		{
+			name:               "phase without transition time yet",
+			status:             esv1.ElasticsearchStatus{Phase: esv1.ElasticsearchReadyPhase},
+			phase:              esv1.ElasticsearchReadyPhase,
+			wantTransitionTime: &justNow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := MustNewState(esv1.Elasticsearch{Status: tt.status})
+			s.setPhase(tt.phase)
+			// setting the same phase again within the same reconciliation does not change the transition time
+			s.setPhase(tt.phase)
+			assert.Equal(t, tt.wantTransitionTime, s.status.PhaseTransitionTime)
+		})
+	}
+}
+
 func TestState_UpdateElasticsearchState(t *testing.T) {
 	type args struct {
 		resourcesState ResourcesState
@@ -353,6 +447,38 @@ func TestState_UpdateElasticsearchMigrating(t *testing.T) {
 	}
 }
 
+func TestState_UpdateElasticsearchReachable(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+	s.UpdateElasticsearchReachable(true)
+	assert.Len(t, s.status.Conditions, 1)
+	condition := s.status.Conditions[0]
+	assert.Equal(t, esv1.ElasticsearchReachableCondition, condition.Type)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, esv1.CircuitBreakerOpenReason, condition.Reason)
+	assert.Equal(t, int64(2), condition.ObservedGeneration)
+
+	s.UpdateElasticsearchReachable(false)
+	assert.Len(t, s.status.Conditions, 1)
+	condition = s.status.Conditions[0]
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, esv1.CircuitBreakerClosedReason, condition.Reason)
+}
+
+func TestState_UpdateMaintenanceMode(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+	s.UpdateElasticsearchReachable(false)
+	s.UpdateMaintenanceMode(true)
+	assert.Len(t, s.status.Conditions, 2)
+	condition := s.status.Conditions[1]
+	assert.Equal(t, esv1.MaintenanceModeCondition, condition.Type)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, esv1.MaintenanceAnnotationReason, condition.Reason)
+
+	s.UpdateMaintenanceMode(false)
+	assert.Len(t, s.status.Conditions, 1)
+	assert.Equal(t, esv1.ElasticsearchReachableCondition, s.status.Conditions[0].Type)
+}
+
 func TestState_fetchMinRunningVersion(t *testing.T) {
 	v770 := version.MustParse("7.7.0")
 	ssetWithVersion := func(value string) appsv1.StatefulSet {
@@ -416,3 +542,86 @@ func TestState_fetchMinRunningVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestState_UpdateElasticsearchShutdownStalled(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+	s.UpdateElasticsearchShutdownStalled(ResourcesState{}, observer.State{}, "es-default-2", "shard [0] of index [logs] cannot move")
+	assert.EqualValues(t, esv1.ElasticsearchNodeShutdownStalledPhase, s.status.Phase)
+	assert.Len(t, s.Recorder.Events(), 1)
+	condition := meta.FindStatusCondition(s.status.Conditions, esv1.NodeShutdownStalledCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, esv1.NodeShutdownStalledCondition, condition.Type)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, esv1.ShardMigrationStalledReason, condition.Reason)
+	assert.Equal(t, "Shutdown of node es-default-2 is stalled: shard [0] of index [logs] cannot move", condition.Message)
+
+	// the condition is kept while changes are applied, and removed once data migrates again
+	s.UpdateElasticsearchApplyingChanges(nil)
+	assert.True(t, meta.IsStatusConditionTrue(s.status.Conditions, esv1.NodeShutdownStalledCondition))
+	s.UpdateElasticsearchMigrating(ResourcesState{}, observer.State{})
+	assert.Nil(t, meta.FindStatusCondition(s.status.Conditions, esv1.NodeShutdownStalledCondition))
+	assert.True(t, meta.IsStatusConditionTrue(s.status.Conditions, esv1.DataMigratingCondition))
+}
+
+func TestState_UpdateReconciliationComplete(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+	s.UpdateElasticsearchApplyingChanges(nil)
+	s.UpdateReconciliationComplete(nil)
+	condition := meta.FindStatusCondition(s.status.Conditions, esv1.ReconciliationCompleteCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, esv1.ReconciliationInProgressReason, condition.Reason)
+
+	s.UpdateReconciliationComplete(errors.New("boom"))
+	condition = meta.FindStatusCondition(s.status.Conditions, esv1.ReconciliationCompleteCondition)
+	assert.Equal(t, esv1.ReconciliationErrorReason, condition.Reason)
+	assert.Equal(t, "boom", condition.Message)
+
+	s.UpdateElasticsearchReady(ResourcesState{}, observer.State{})
+	s.UpdateReconciliationComplete(nil)
+	assert.True(t, meta.IsStatusConditionTrue(s.status.Conditions, esv1.ReconciliationCompleteCondition))
+}
+
+func TestState_UpdateUpgradeInProgress(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{})
+	s.UpdateUpgradeInProgress(2)
+	condition := meta.FindStatusCondition(s.status.Conditions, esv1.UpgradeInProgressCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "2 Pods must be restarted to run their expected specification", condition.Message)
+
+	s.UpdateUpgradeInProgress(0)
+	assert.True(t, meta.IsStatusConditionFalse(s.status.Conditions, esv1.UpgradeInProgressCondition))
+}
+
+func TestState_UpdateCertificatesReady(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{})
+	s.UpdateCertificatesReady(errors.New("invalid CA"))
+	condition := meta.FindStatusCondition(s.status.Conditions, esv1.CertificatesReadyCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, esv1.CertificatesErrorReason, condition.Reason)
+
+	s.UpdateCertificatesReady(nil)
+	assert.True(t, meta.IsStatusConditionTrue(s.status.Conditions, esv1.CertificatesReadyCondition))
+}
+
+func TestState_UpdateShardsUnassigned(t *testing.T) {
+	s := MustNewState(esv1.Elasticsearch{})
+	explanation := &client.AllocationExplanation{Index: "my-index", CurrentState: "unassigned", AllocateExplanation: "cannot allocate"}
+	s.UpdateShardsUnassigned(explanation)
+	condition := meta.FindStatusCondition(s.status.Conditions, esv1.ShardsUnassignedCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, esv1.AllocationExplanationReason, condition.Reason)
+	assert.Equal(t, explanation.Summary(), condition.Message)
+	require.Len(t, s.Events(), 1)
+	assert.Equal(t, explanation.Summary(), s.Events()[0].Message)
+
+	// no new event for the same explanation
+	s.UpdateShardsUnassigned(explanation)
+	assert.Len(t, s.Events(), 1)
+
+	s.UpdateShardsUnassigned(nil)
+	assert.Nil(t, meta.FindStatusCondition(s.status.Conditions, esv1.ShardsUnassignedCondition))
+}
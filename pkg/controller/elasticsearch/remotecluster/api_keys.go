@@ -0,0 +1,495 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package remotecluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"go.elastic.co/apm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	// APIKeysAnnotationName holds the state of the cross-cluster API keys stored in the remote API keys Secret.
+	APIKeysAnnotationName = "elasticsearch.k8s.elastic.co/remote-cluster-api-keys"
+
+	// apiKeyValidity is the validity of the cross-cluster API keys. They are rotated once half of their validity has
+	// elapsed, the previous key remaining valid while the new one is rolled out to the keystore of the nodes.
+	apiKeyValidity = 90 * 24 * time.Hour
+	// apiKeyRestartBefore is the delay before the expiration of a rotated API key at which the nodes still using it
+	// are restarted, if they have not been restarted for another reason in the meantime.
+	apiKeyRestartBefore = 7 * 24 * time.Hour
+)
+
+// apiKeyState tracks a cross-cluster API key created in a remote cluster.
+type apiKeyState struct {
+	// ID of the API key in the remote cluster.
+	ID string `json:"id"`
+	// Namespace and Name of the remote cluster in which the API key has been created.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Expiration of the API key, in milliseconds since epoch.
+	Expiration int64 `json:"expiration"`
+	// AccessHash is a hash of the access granted to the API key.
+	AccessHash string `json:"accessHash"`
+	// RestartID is the ID of the last API key rolled out by restarting the nodes. Rotated API keys are not, they are
+	// picked up by the nodes when they restart for another reason.
+	RestartID string `json:"restartID,omitempty"`
+	// PreviousID and PreviousExpiration identify the API key replaced by the last rotation, still used by the nodes
+	// created before RotatedAt. Both are in milliseconds since epoch.
+	PreviousID         string `json:"previousID,omitempty"`
+	PreviousExpiration int64  `json:"previousExpiration,omitempty"`
+	RotatedAt          int64  `json:"rotatedAt,omitempty"`
+}
+
+func (s apiKeyState) remoteCluster() types.NamespacedName {
+	return types.NamespacedName{Namespace: s.Namespace, Name: s.Name}
+}
+
+// restartID returns the ID of the last API key rolled out by restarting the nodes, the API key itself for states
+// recorded before rotated API keys stopped restarting the nodes.
+func (s apiKeyState) restartID() string {
+	if s.RestartID == "" {
+		return s.ID
+	}
+	return s.RestartID
+}
+
+// requeueIn returns the delay after which the API key must be rotated, or the nodes using the previous API key
+// restarted.
+func (s apiKeyState) requeueIn(now time.Time) time.Duration {
+	requeueIn := certificates.ShouldRotateIn(now, fromMillis(s.Expiration), apiKeyValidity/2)
+	if s.PreviousID != "" && s.restartID() != s.ID {
+		if restartIn := certificates.ShouldRotateIn(now, fromMillis(s.PreviousExpiration), apiKeyRestartBefore); restartIn < requeueIn {
+			requeueIn = restartIn
+		}
+	}
+	return requeueIn
+}
+
+func fromMillis(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+// remoteClientProvider returns a client to the given remote cluster.
+type remoteClientProvider func(ctx context.Context, es esv1.Elasticsearch) (esclient.Client, error)
+
+// credentialsSetting returns the name of the secure setting holding the API key of the given remote cluster.
+func credentialsSetting(remoteCluster string) string {
+	return fmt.Sprintf("cluster.remote.%s.credentials", remoteCluster)
+}
+
+// ReconcileAPIKeys creates the cross-cluster API keys of the remote clusters of the given cluster in the remote clusters,
+// and stores them in a Secret added to its keystore. API keys are updated when their access changes, rotated before
+// they expire, and invalidated once they are not used anymore. It returns the delay after which the API keys must be
+// reconciled again, zero if there is none.
+func ReconcileAPIKeys(
+	ctx context.Context,
+	c k8s.Client,
+	dialer net.Dialer,
+	dryRun bool,
+	serverSideApply bool,
+	licenseChecker license.Checker,
+	es esv1.Elasticsearch,
+) (time.Duration, error) {
+	newRemoteClient := func(ctx context.Context, remoteES esv1.Elasticsearch) (esclient.Client, error) {
+		return user.NewControllerUserClient(ctx, c, dialer, remoteES, dryRun)
+	}
+	return reconcileAPIKeys(ctx, c, newRemoteClient, licenseChecker, es, time.Now(), serverSideApply)
+}
+
+func reconcileAPIKeys(
+	ctx context.Context,
+	c k8s.Client,
+	newRemoteClient remoteClientProvider,
+	licenseChecker license.Checker,
+	es esv1.Elasticsearch,
+	now time.Time,
+	serverSideApply bool,
+) (time.Duration, error) {
+	var secret corev1.Secret
+	err := c.Get(ctx, types.NamespacedName{Namespace: es.Namespace, Name: esv1.RemoteAPIKeysSecretName(es.Name)}, &secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return 0, err
+	}
+	secretExists := err == nil
+
+	remoteClusters := make(map[string]esv1.RemoteCluster)
+	for name, remoteCluster := range getRemoteClustersInSpec(es) {
+		if remoteCluster.APIKey != nil {
+			remoteClusters[name] = remoteCluster
+		}
+	}
+	if len(remoteClusters) == 0 && !secretExists {
+		// nothing to do, skip
+		return 0, nil
+	}
+
+	enabled, err := licenseChecker.EnterpriseFeaturesEnabled()
+	if err != nil {
+		return 0, err
+	}
+	if !enabled {
+		// already reported when updating the remote clusters settings
+		return 0, nil
+	}
+
+	span, ctx := apm.StartSpan(ctx, "reconcile_remote_cluster_api_keys", tracing.SpanTypeApp)
+	defer span.End()
+
+	states, err := parseAPIKeyStates(secret)
+	if err != nil {
+		return 0, err
+	}
+	credentials := make(map[string][]byte)
+	for name, state := range states {
+		if value, ok := secret.Data[credentialsSetting(name)]; ok {
+			credentials[credentialsSetting(name)] = value
+		} else {
+			// the credentials have been lost, the API key cannot be used anymore
+			delete(states, name)
+			if err := invalidateAPIKeys(ctx, c, newRemoteClient, state); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// invalidate the API keys of the remote clusters which do not use an API key anymore, or which now refer to
+	// another Elasticsearch cluster
+	for name, state := range states {
+		remoteCluster, inSpec := remoteClusters[name]
+		if inSpec && remoteCluster.ElasticsearchRef.NamespacedName() == state.remoteCluster() {
+			continue
+		}
+		log.Info("Invalidating remote cluster API key", "namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+		if err := invalidateAPIKeys(ctx, c, newRemoteClient, state); err != nil {
+			return 0, err
+		}
+		delete(states, name)
+		delete(credentials, credentialsSetting(name))
+	}
+
+	for name, remoteCluster := range remoteClusters {
+		state, err := reconcileAPIKey(ctx, c, newRemoteClient, es, name, remoteCluster, states[name], credentials, now)
+		if err != nil {
+			return 0, err
+		}
+		if state != nil {
+			states[name] = *state
+		}
+	}
+
+	var requeueIn time.Duration
+	for name, state := range states {
+		state, err := reconcilePreviousAPIKey(ctx, c, newRemoteClient, es, name, state, now)
+		if err != nil {
+			return 0, err
+		}
+		states[name] = state
+		if stateRequeueIn := state.requeueIn(now); requeueIn == 0 || stateRequeueIn < requeueIn {
+			requeueIn = stateRequeueIn
+		}
+	}
+
+	return requeueIn, reconcileAPIKeysSecret(c, es, states, credentials, serverSideApply)
+}
+
+func parseAPIKeyStates(secret corev1.Secret) (map[string]apiKeyState, error) {
+	states := make(map[string]apiKeyState)
+	if serialized, ok := secret.Annotations[APIKeysAnnotationName]; ok {
+		if err := json.Unmarshal([]byte(serialized), &states); err != nil {
+			return nil, pkgerrors.Wrapf(err, "while parsing annotation %s", APIKeysAnnotationName)
+		}
+	}
+	return states, nil
+}
+
+// reconcileAPIKey creates or rotates the API key of the given remote cluster if needed, or updates its access.
+// The new state of the API key is returned, nil if the remote cluster does not exist.
+func reconcileAPIKey(
+	ctx context.Context,
+	c k8s.Client,
+	newRemoteClient remoteClientProvider,
+	es esv1.Elasticsearch,
+	name string,
+	remoteCluster esv1.RemoteCluster,
+	state apiKeyState,
+	credentials map[string][]byte,
+	now time.Time,
+) (*apiKeyState, error) {
+	accessHash := hash.HashObject(remoteCluster.APIKey.Access)
+	exists := state.ID != ""
+	rotate := exists && now.Add(apiKeyValidity/2).After(fromMillis(state.Expiration))
+	if exists && !rotate && state.AccessHash == accessHash {
+		// nothing to do
+		return &state, nil
+	}
+
+	var remoteES esv1.Elasticsearch
+	if err := c.Get(ctx, remoteCluster.ElasticsearchRef.NamespacedName(), &remoteES); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Remote cluster not found, skipping the reconciliation of its API key",
+				"namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+			if exists {
+				return &state, nil
+			}
+			return nil, nil
+		}
+		return nil, err
+	}
+	if remoteES.IsInMaintenance() {
+		log.Info("Remote cluster in maintenance mode, skipping the reconciliation of its API key",
+			"namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+		if exists {
+			return &state, nil
+		}
+		return nil, nil
+	}
+	remoteClient, err := newRemoteClient(ctx, remoteES)
+	if err != nil {
+		return nil, err
+	}
+	defer remoteClient.Close()
+
+	access := toAPIKeyAccess(remoteCluster.APIKey.Access)
+	metadata := map[string]interface{}{"elasticsearch.k8s.elastic.co/cluster": k8s.ExtractNamespacedName(&es).String()}
+	if exists && !rotate {
+		log.Info("Updating remote cluster API key access", "namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+		for _, id := range []string{state.ID, state.PreviousID} {
+			if id == "" {
+				continue
+			}
+			if err := remoteClient.UpdateCrossClusterAPIKey(ctx, id, esclient.CrossClusterAPIKeyUpdateRequest{
+				Access:   access,
+				Metadata: metadata,
+			}); err != nil {
+				return nil, pkgerrors.Wrapf(err, "while updating the API key of remote cluster %s", name)
+			}
+		}
+		state.AccessHash = accessHash
+		return &state, nil
+	}
+
+	if rotate && state.PreviousID != "" {
+		// the nodes have been restarted with the current API key well before it is rotated, the previous one cannot
+		// still be in use
+		if err := remoteClient.InvalidateAPIKey(ctx, state.PreviousID); err != nil && !esclient.IsNotFound(err) {
+			return nil, pkgerrors.Wrapf(err, "while invalidating API key %s", state.PreviousID)
+		}
+	}
+
+	// the previous API key, if any, remains valid while the new one is rolled out to the nodes
+	log.Info("Creating remote cluster API key", "namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+	response, err := remoteClient.CreateCrossClusterAPIKey(ctx, esclient.CrossClusterAPIKeyCreateRequest{
+		Name:       fmt.Sprintf("eck-%s-%s-%s", es.Namespace, es.Name, name),
+		Expiration: fmt.Sprintf("%dh", int64(apiKeyValidity.Hours())),
+		Access:     access,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "while creating the API key of remote cluster %s", name)
+	}
+	credentials[credentialsSetting(name)] = []byte(response.Encoded)
+	newState := apiKeyState{
+		ID:         response.ID,
+		Namespace:  remoteES.Namespace,
+		Name:       remoteES.Name,
+		Expiration: response.Expiration,
+		AccessHash: accessHash,
+		RestartID:  response.ID,
+	}
+	if rotate {
+		// do not restart the nodes to roll out the rotated API key, they keep using the previous one until they
+		// restart for another reason
+		newState.RestartID = state.restartID()
+		newState.PreviousID = state.ID
+		newState.PreviousExpiration = state.Expiration
+		newState.RotatedAt = now.UnixNano() / int64(time.Millisecond)
+	}
+	return &newState, nil
+}
+
+// reconcilePreviousAPIKey invalidates the API key replaced by the last rotation once all the nodes have been created
+// after it, or requests the nodes to be restarted if the previous API key is about to expire.
+func reconcilePreviousAPIKey(
+	ctx context.Context,
+	c k8s.Client,
+	newRemoteClient remoteClientProvider,
+	es esv1.Elasticsearch,
+	name string,
+	state apiKeyState,
+	now time.Time,
+) (apiKeyState, error) {
+	if state.PreviousID == "" {
+		return state, nil
+	}
+	rolledOut, err := nodesCreatedAfter(ctx, c, es, fromMillis(state.RotatedAt))
+	if err != nil {
+		return state, err
+	}
+	if rolledOut {
+		log.Info("Invalidating rotated remote cluster API key", "namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+		if err := invalidateAPIKey(ctx, c, newRemoteClient, state.remoteCluster(), state.PreviousID); err != nil {
+			return state, err
+		}
+		state.PreviousID = ""
+		state.PreviousExpiration = 0
+		state.RotatedAt = 0
+		return state, nil
+	}
+	if state.restartID() != state.ID && now.Add(apiKeyRestartBefore).After(fromMillis(state.PreviousExpiration)) {
+		log.Info("Restarting nodes to roll out the rotated remote cluster API key before the previous one expires",
+			"namespace", es.Namespace, "es_name", es.Name, "remote_cluster", name)
+		state.RestartID = state.ID
+	}
+	return state, nil
+}
+
+// nodesCreatedAfter returns true if all the Pods of the given cluster have been created after the given time.
+func nodesCreatedAfter(ctx context.Context, c k8s.Client, es esv1.Elasticsearch, t time.Time) (bool, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(es.Namespace), label.NewLabelSelectorForElasticsearch(es)); err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if pod.CreationTimestamp.Time.Before(t) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// invalidateAPIKeys invalidates the given API key and the one it replaced, if any.
+func invalidateAPIKeys(ctx context.Context, c k8s.Client, newRemoteClient remoteClientProvider, state apiKeyState) error {
+	for _, id := range []string{state.ID, state.PreviousID} {
+		if id == "" {
+			continue
+		}
+		if err := invalidateAPIKey(ctx, c, newRemoteClient, state.remoteCluster(), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidateAPIKey invalidates the given API key, unless its remote cluster does not exist anymore.
+func invalidateAPIKey(ctx context.Context, c k8s.Client, newRemoteClient remoteClientProvider, remoteCluster types.NamespacedName, id string) error {
+	var remoteES esv1.Elasticsearch
+	if err := c.Get(ctx, remoteCluster, &remoteES); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if remoteES.IsInMaintenance() {
+		// retry once the maintenance mode ends
+		return fmt.Errorf("cannot invalidate API key %s: remote cluster %s is in maintenance mode", id, remoteCluster)
+	}
+	remoteClient, err := newRemoteClient(ctx, remoteES)
+	if err != nil {
+		return err
+	}
+	defer remoteClient.Close()
+	if err := remoteClient.InvalidateAPIKey(ctx, id); err != nil && !esclient.IsNotFound(err) {
+		return pkgerrors.Wrapf(err, "while invalidating API key %s", id)
+	}
+	return nil
+}
+
+// reconcileAPIKeysSecret stores the API keys in the remote API keys Secret, deleted if there is none.
+func reconcileAPIKeysSecret(c k8s.Client, es esv1.Elasticsearch, states map[string]apiKeyState, credentials map[string][]byte, serverSideApply bool) error {
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esv1.RemoteAPIKeysSecretName(es.Name),
+			Namespace: es.Namespace,
+			Labels:    label.NewLabels(k8s.ExtractNamespacedName(&es)),
+		},
+		Data: credentials,
+	}
+	if len(credentials) == 0 {
+		if err := c.Delete(context.Background(), &expected); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	serialized, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	expected.Annotations = map[string]string{APIKeysAnnotationName: string(serialized)}
+	_, err = reconciler.ReconcileSecret(c, expected, &es, serverSideApply)
+	return err
+}
+
+// SecureSettings returns the remote API keys Secret of the given cluster, to be added to its keystore, if it exists.
+func SecureSettings(c k8s.Client, es esv1.Elasticsearch) ([]commonv1.SecretSource, error) {
+	var secret corev1.Secret
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.RemoteAPIKeysSecretName(es.Name)}, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []commonv1.SecretSource{{SecretName: secret.Name}}, nil
+}
+
+// SecureSettingsVersion returns the version of the secure settings of the given cluster, to be used instead of the
+// resourceVersion of its aggregated secure settings Secret if it includes remote cluster API keys. The credentials of
+// rotated API keys are left out, so that rotating an API key does not restart the nodes.
+func SecureSettingsVersion(c k8s.Client, es esv1.Elasticsearch) (string, error) {
+	var apiKeysSecret corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.RemoteAPIKeysSecretName(es.Name)}, &apiKeysSecret); err != nil {
+		return "", err
+	}
+	states, err := parseAPIKeyStates(apiKeysSecret)
+	if err != nil {
+		return "", err
+	}
+	var secureSettings corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.SecureSettingsSecret(es.Name)}, &secureSettings); err != nil {
+		return "", err
+	}
+	data := make(map[string][]byte, len(secureSettings.Data))
+	for k, v := range secureSettings.Data {
+		data[k] = v
+	}
+	for name, state := range states {
+		data[credentialsSetting(name)] = []byte(state.restartID())
+	}
+	return hash.HashObject(data), nil
+}
+
+func toAPIKeyAccess(access esv1.RemoteClusterAccess) esclient.CrossClusterAPIKeyAccess {
+	var apiKeyAccess esclient.CrossClusterAPIKeyAccess
+	if access.Search != nil {
+		apiKeyAccess.Search = []esclient.CrossClusterAPIKeyIndices{{Names: access.Search.Names}}
+	}
+	if access.Replication != nil {
+		apiKeyAccess.Replication = []esclient.CrossClusterAPIKeyIndices{{Names: access.Replication.Names}}
+	}
+	return apiKeyAccess
+}
@@ -0,0 +1,184 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package remotecluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+type fakeAPIKeyClient struct {
+	esclient.Client
+	created     []esclient.CrossClusterAPIKeyCreateRequest
+	updated     map[string]esclient.CrossClusterAPIKeyUpdateRequest
+	invalidated []string
+	now         time.Time
+}
+
+func (f *fakeAPIKeyClient) CreateCrossClusterAPIKey(_ context.Context, request esclient.CrossClusterAPIKeyCreateRequest) (esclient.CrossClusterAPIKeyCreateResponse, error) {
+	f.created = append(f.created, request)
+	id := fmt.Sprintf("id%d", len(f.created))
+	return esclient.CrossClusterAPIKeyCreateResponse{
+		ID:         id,
+		Name:       request.Name,
+		Expiration: f.now.Add(apiKeyValidity).UnixNano() / int64(time.Millisecond),
+		Encoded:    "encoded-" + id,
+	}, nil
+}
+
+func (f *fakeAPIKeyClient) UpdateCrossClusterAPIKey(_ context.Context, id string, request esclient.CrossClusterAPIKeyUpdateRequest) error {
+	f.updated[id] = request
+	return nil
+}
+
+func (f *fakeAPIKeyClient) InvalidateAPIKey(_ context.Context, id string) error {
+	f.invalidated = append(f.invalidated, id)
+	return nil
+}
+
+func (f *fakeAPIKeyClient) Close() {}
+
+func Test_reconcileAPIKeys(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	remote := &esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "es2"}}
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "es1"},
+		Spec: esv1.ElasticsearchSpec{RemoteClusters: []esv1.RemoteCluster{{
+			Name:             "remote",
+			ElasticsearchRef: commonv1.ObjectSelector{Namespace: "ns2", Name: "es2"},
+			APIKey: &esv1.RemoteClusterAPIKey{Access: esv1.RemoteClusterAccess{
+				Search: &esv1.RemoteClusterIndices{Names: []string{"logs-*"}},
+			}},
+		}}},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "ns1",
+		Name:              "es1-es-default-0",
+		Labels:            map[string]string{label.ClusterNameLabelName: "es1"},
+		CreationTimestamp: metav1.NewTime(now),
+	}}
+	c := k8s.NewFakeClient(es.DeepCopy(), remote, pod)
+	remoteClient := &fakeAPIKeyClient{updated: make(map[string]esclient.CrossClusterAPIKeyUpdateRequest), now: now}
+	newRemoteClient := func(_ context.Context, remoteES esv1.Elasticsearch) (esclient.Client, error) {
+		assert.Equal(t, "es2", remoteES.Name)
+		return remoteClient, nil
+	}
+	licenseChecker := license.MockLicenseChecker{EnterpriseEnabled: true}
+	secretName := types.NamespacedName{Namespace: "ns1", Name: "es1-es-remote-api-keys"}
+	getSecret := func() (corev1.Secret, map[string]apiKeyState) {
+		var secret corev1.Secret
+		require.NoError(t, c.Get(context.Background(), secretName, &secret))
+		states := make(map[string]apiKeyState)
+		require.NoError(t, json.Unmarshal([]byte(secret.Annotations[APIKeysAnnotationName]), &states))
+		return secret, states
+	}
+	// the aggregated secure settings Secret of the keystore is maintained by the driver
+	secureSettings := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "es1-es-secure-settings"}}
+	require.NoError(t, c.Create(context.Background(), secureSettings))
+	secureSettingsVersion := func() string {
+		apiKeys, _ := getSecret()
+		secureSettings.Data = map[string][]byte{"other.setting": []byte("value")}
+		for k, v := range apiKeys.Data {
+			secureSettings.Data[k] = v
+		}
+		require.NoError(t, c.Update(context.Background(), secureSettings))
+		version, err := SecureSettingsVersion(c, es)
+		require.NoError(t, err)
+		return version
+	}
+
+	// the API key is created in the remote cluster and stored in the Secret, to be rotated once half of its validity
+	// has elapsed
+	requeueIn, err := reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, apiKeyValidity/2+time.Second, requeueIn)
+	require.Len(t, remoteClient.created, 1)
+	assert.Equal(t, "eck-ns1-es1-remote", remoteClient.created[0].Name)
+	assert.Equal(t, []esclient.CrossClusterAPIKeyIndices{{Names: []string{"logs-*"}}}, remoteClient.created[0].Access.Search)
+	secret, states := getSecret()
+	assert.Equal(t, map[string][]byte{"cluster.remote.remote.credentials": []byte("encoded-id1")}, secret.Data)
+	assert.Equal(t, "id1", states["remote"].ID)
+	assert.Equal(t, "id1", states["remote"].RestartID)
+	initialVersion := secureSettingsVersion()
+
+	// nothing to do while the API key is up to date
+	requeueIn, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, now.Add(24*time.Hour), false)
+	require.NoError(t, err)
+	assert.Equal(t, apiKeyValidity/2-24*time.Hour+time.Second, requeueIn)
+	assert.Len(t, remoteClient.created, 1)
+	assert.Empty(t, remoteClient.updated)
+
+	// the access of the API key is updated in place
+	es.Spec.RemoteClusters[0].APIKey.Access.Replication = &esv1.RemoteClusterIndices{Names: []string{"metrics"}}
+	_, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, now.Add(24*time.Hour), false)
+	require.NoError(t, err)
+	assert.Len(t, remoteClient.created, 1)
+	assert.Equal(t, []esclient.CrossClusterAPIKeyIndices{{Names: []string{"metrics"}}}, remoteClient.updated["id1"].Access.Replication)
+
+	// the API key is rotated once half of its validity has elapsed, without restarting the nodes: the previous one
+	// remains valid until the nodes are restarted, at the latest shortly before it expires
+	rotatedAt := now.Add(apiKeyValidity/2 + time.Hour)
+	remoteClient.now = rotatedAt
+	requeueIn, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, rotatedAt, false)
+	require.NoError(t, err)
+	assert.Equal(t, apiKeyValidity/2-time.Hour-apiKeyRestartBefore+time.Second, requeueIn)
+	require.Len(t, remoteClient.created, 2)
+	assert.Empty(t, remoteClient.invalidated)
+	secret, states = getSecret()
+	assert.Equal(t, map[string][]byte{"cluster.remote.remote.credentials": []byte("encoded-id2")}, secret.Data)
+	assert.Equal(t, "id2", states["remote"].ID)
+	assert.Equal(t, "id1", states["remote"].PreviousID)
+	assert.Equal(t, "id1", states["remote"].RestartID)
+	assert.Equal(t, initialVersion, secureSettingsVersion())
+
+	// the nodes are restarted shortly before the previous API key expires
+	restartAt := now.Add(apiKeyValidity - apiKeyRestartBefore + time.Hour)
+	requeueIn, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, restartAt, false)
+	require.NoError(t, err)
+	assert.Equal(t, apiKeyValidity/2-(restartAt.Sub(rotatedAt))+time.Second, requeueIn)
+	assert.Empty(t, remoteClient.invalidated)
+	_, states = getSecret()
+	assert.Equal(t, "id2", states["remote"].RestartID)
+	assert.NotEqual(t, initialVersion, secureSettingsVersion())
+
+	// the previous API key is invalidated once all the nodes have been created after the rotation
+	require.NoError(t, c.Delete(context.Background(), pod))
+	pod = pod.DeepCopy()
+	pod.ResourceVersion = ""
+	pod.CreationTimestamp = metav1.NewTime(restartAt.Add(time.Minute))
+	require.NoError(t, c.Create(context.Background(), pod))
+	_, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, restartAt.Add(time.Hour), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id1"}, remoteClient.invalidated)
+	_, states = getSecret()
+	assert.Equal(t, "id2", states["remote"].ID)
+	assert.Empty(t, states["remote"].PreviousID)
+
+	// the API key is invalidated and the Secret deleted once the remote cluster does not use an API key anymore
+	es.Spec.RemoteClusters[0].APIKey = nil
+	requeueIn, err = reconcileAPIKeys(context.Background(), c, newRemoteClient, licenseChecker, es, restartAt.Add(time.Hour), false)
+	require.NoError(t, err)
+	assert.Zero(t, requeueIn)
+	assert.Equal(t, []string{"id1", "id2"}, remoteClient.invalidated)
+	err = c.Get(context.Background(), secretName, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
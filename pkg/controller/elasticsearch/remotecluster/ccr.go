@@ -0,0 +1,200 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package remotecluster
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	"go.elastic.co/apm"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+// ManagedAutoFollowPatternsAnnotationName holds the list of the auto-follow patterns which have been created.
+const ManagedAutoFollowPatternsAnnotationName = "elasticsearch.k8s.elastic.co/managed-auto-follow-patterns"
+
+// UpdateCrossClusterReplication creates the follower indices and the auto-follow patterns declared for the remote
+// clusters of the given cluster, and deletes the auto-follow patterns it created which are not declared anymore.
+// Cross-cluster replication requests are made with the operator user, which exists with the same roles in the
+// remote clusters, whose CA is already trusted for the remote cluster connections.
+func UpdateCrossClusterReplication(
+	ctx context.Context,
+	c k8s.Client,
+	esClient esclient.Client,
+	licenseChecker license.Checker,
+	es *esv1.Elasticsearch,
+) error {
+	remoteClustersInSpec := getRemoteClustersInSpec(*es)
+	_, isAutoFollowPatternsAnnotation := es.Annotations[ManagedAutoFollowPatternsAnnotationName]
+	if !hasCrossClusterReplication(remoteClustersInSpec) && !isAutoFollowPatternsAnnotation {
+		// nothing to do, skip
+		return nil
+	}
+
+	enabled, err := licenseChecker.EnterpriseFeaturesEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		// already reported when updating the remote clusters settings
+		return nil
+	}
+
+	span, ctx := apm.StartSpan(ctx, "update_cross_cluster_replication", tracing.SpanTypeApp)
+	defer span.End()
+
+	if err := updateAutoFollowPatterns(ctx, c, esClient, es, remoteClustersInSpec); err != nil {
+		return err
+	}
+	return createFollowerIndices(ctx, esClient, *es, remoteClustersInSpec)
+}
+
+func hasCrossClusterReplication(remoteClusters map[string]esv1.RemoteCluster) bool {
+	for _, remoteCluster := range remoteClusters {
+		if len(remoteCluster.FollowerIndices) > 0 || len(remoteCluster.AutoFollowPatterns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// updateAutoFollowPatterns deletes the auto-follow patterns in the annotation which are not in the spec anymore,
+// creates or updates the ones in the spec, then tracks them in the annotation.
+func updateAutoFollowPatterns(
+	ctx context.Context,
+	c k8s.Client,
+	esClient esclient.Client,
+	es *esv1.Elasticsearch,
+	remoteClustersInSpec map[string]esv1.RemoteCluster,
+) error {
+	patternsInSpec := make(map[string]struct{})
+	for name, remoteCluster := range remoteClustersInSpec {
+		for _, pattern := range remoteCluster.AutoFollowPatterns {
+			patternsInSpec[pattern.Name] = struct{}{}
+			if err := esClient.PutAutoFollowPattern(ctx, pattern.Name, esclient.AutoFollowPattern{
+				RemoteCluster:       name,
+				LeaderIndexPatterns: pattern.LeaderIndexPatterns,
+				FollowIndexPattern:  pattern.FollowIndexPattern,
+			}); err != nil {
+				return pkgerrors.Wrapf(err, "while creating auto-follow pattern %s", pattern.Name)
+			}
+		}
+	}
+
+	patternsInAnnotation := getAutoFollowPatternsInAnnotation(*es)
+	var patternsToDelete []string
+	for pattern := range patternsInAnnotation {
+		if _, inSpec := patternsInSpec[pattern]; !inSpec {
+			patternsToDelete = append(patternsToDelete, pattern)
+		}
+	}
+	if len(patternsToDelete) > 0 {
+		patternsInEs, err := esClient.GetAutoFollowPatterns(ctx)
+		if err != nil {
+			return err
+		}
+		sort.Strings(patternsToDelete)
+		for _, pattern := range patternsToDelete {
+			if !stringsutil.StringInSlice(pattern, patternsInEs) {
+				continue
+			}
+			log.Info("Deleting auto-follow pattern", "namespace", es.Namespace, "es_name", es.Name, "pattern", pattern)
+			if err := esClient.DeleteAutoFollowPattern(ctx, pattern); err != nil {
+				return pkgerrors.Wrapf(err, "while deleting auto-follow pattern %s", pattern)
+			}
+		}
+	}
+
+	return annotateWithAutoFollowPatterns(ctx, c, es, patternsInSpec)
+}
+
+// createFollowerIndices creates the follower indices in the spec which do not exist yet.
+func createFollowerIndices(
+	ctx context.Context,
+	esClient esclient.Client,
+	es esv1.Elasticsearch,
+	remoteClustersInSpec map[string]esv1.RemoteCluster,
+) error {
+	if !hasFollowerIndices(remoteClustersInSpec) {
+		return nil
+	}
+	followerIndices, err := esClient.GetFollowerIndices(ctx)
+	if err != nil {
+		return err
+	}
+	for name, remoteCluster := range remoteClustersInSpec {
+		for _, index := range remoteCluster.FollowerIndices {
+			if stringsutil.StringInSlice(index.Name, followerIndices) {
+				continue
+			}
+			log.Info("Creating follower index", "namespace", es.Namespace, "es_name", es.Name,
+				"index", index.Name, "remote_cluster", name, "leader_index", index.LeaderIndexOrDefault())
+			if err := esClient.Follow(ctx, index.Name, esclient.FollowRequest{
+				RemoteCluster: name,
+				LeaderIndex:   index.LeaderIndexOrDefault(),
+			}); err != nil {
+				return pkgerrors.Wrapf(err, "while creating follower index %s", index.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func hasFollowerIndices(remoteClusters map[string]esv1.RemoteCluster) bool {
+	for _, remoteCluster := range remoteClusters {
+		if len(remoteCluster.FollowerIndices) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getAutoFollowPatternsInAnnotation returns the auto-follow patterns tracked in the annotation, as a set.
+func getAutoFollowPatternsInAnnotation(es esv1.Elasticsearch) map[string]struct{} {
+	patterns := make(map[string]struct{})
+	serializedPatterns, ok := es.Annotations[ManagedAutoFollowPatternsAnnotationName]
+	if !ok || strings.TrimSpace(serializedPatterns) == "" {
+		return patterns
+	}
+	for _, pattern := range strings.Split(serializedPatterns, ",") {
+		patterns[pattern] = struct{}{}
+	}
+	return patterns
+}
+
+// annotateWithAutoFollowPatterns tracks the given auto-follow patterns in the annotation, removed if there is none.
+func annotateWithAutoFollowPatterns(ctx context.Context, c k8s.Client, es *esv1.Elasticsearch, patterns map[string]struct{}) error {
+	current, exists := es.Annotations[ManagedAutoFollowPatternsAnnotationName]
+	if len(patterns) == 0 {
+		if !exists {
+			return nil
+		}
+		delete(es.Annotations, ManagedAutoFollowPatternsAnnotationName)
+		return c.Update(ctx, es)
+	}
+
+	annotation := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		annotation = append(annotation, pattern)
+	}
+	sort.Strings(annotation)
+	expected := strings.Join(annotation, ",")
+	if exists && current == expected {
+		return nil
+	}
+	if es.Annotations == nil {
+		es.Annotations = make(map[string]string)
+	}
+	es.Annotations[ManagedAutoFollowPatternsAnnotationName] = expected
+	return c.Update(ctx, es)
+}
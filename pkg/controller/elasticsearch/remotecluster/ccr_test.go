@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package remotecluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+type fakeCCRClient struct {
+	esclient.Client
+	followerIndices    []string
+	autoFollowPatterns map[string]esclient.AutoFollowPattern
+	followed           map[string]esclient.FollowRequest
+	deletedPatterns    []string
+}
+
+func (f *fakeCCRClient) GetFollowerIndices(_ context.Context) ([]string, error) {
+	return f.followerIndices, nil
+}
+
+func (f *fakeCCRClient) Follow(_ context.Context, followerIndex string, request esclient.FollowRequest) error {
+	f.followed[followerIndex] = request
+	f.followerIndices = append(f.followerIndices, followerIndex)
+	return nil
+}
+
+func (f *fakeCCRClient) GetAutoFollowPatterns(_ context.Context) ([]string, error) {
+	patterns := make([]string, 0, len(f.autoFollowPatterns))
+	for name := range f.autoFollowPatterns {
+		patterns = append(patterns, name)
+	}
+	return patterns, nil
+}
+
+func (f *fakeCCRClient) PutAutoFollowPattern(_ context.Context, name string, pattern esclient.AutoFollowPattern) error {
+	f.autoFollowPatterns[name] = pattern
+	return nil
+}
+
+func (f *fakeCCRClient) DeleteAutoFollowPattern(_ context.Context, name string) error {
+	delete(f.autoFollowPatterns, name)
+	f.deletedPatterns = append(f.deletedPatterns, name)
+	return nil
+}
+
+func TestUpdateCrossClusterReplication(t *testing.T) {
+	leader := esv1.RemoteCluster{
+		Name:             "leader",
+		ElasticsearchRef: commonv1.ObjectSelector{Name: "es2"},
+		FollowerIndices:  []esv1.FollowerIndex{{Name: "logs"}, {Name: "metrics-copy", LeaderIndex: "metrics"}},
+		AutoFollowPatterns: []esv1.AutoFollowPattern{
+			{Name: "traces", LeaderIndexPatterns: []string{"traces-*"}, FollowIndexPattern: "{{leader_index}}-copy"},
+		},
+	}
+	es := newEsWithRemoteClusters("ns1", "es1", map[string]string{ManagedAutoFollowPatternsAnnotationName: "old,traces"}, leader)
+	esClient := &fakeCCRClient{
+		followerIndices: []string{"logs"},
+		autoFollowPatterns: map[string]esclient.AutoFollowPattern{
+			"old":          {RemoteCluster: "leader", LeaderIndexPatterns: []string{"old-*"}},
+			"user-defined": {RemoteCluster: "leader", LeaderIndexPatterns: []string{"user-*"}},
+		},
+		followed: make(map[string]esclient.FollowRequest),
+	}
+	client := k8s.NewFakeClient(es)
+	licenseChecker := &license.MockLicenseChecker{EnterpriseEnabled: true}
+
+	require.NoError(t, UpdateCrossClusterReplication(context.Background(), client, esClient, licenseChecker, es))
+	// only the missing follower index is created
+	assert.Equal(t, map[string]esclient.FollowRequest{"metrics-copy": {RemoteCluster: "leader", LeaderIndex: "metrics"}}, esClient.followed)
+	// the auto-follow pattern removed from the spec is deleted, the one created by the user is kept
+	assert.Equal(t, []string{"old"}, esClient.deletedPatterns)
+	assert.Equal(t, map[string]esclient.AutoFollowPattern{
+		"traces":       {RemoteCluster: "leader", LeaderIndexPatterns: []string{"traces-*"}, FollowIndexPattern: "{{leader_index}}-copy"},
+		"user-defined": {RemoteCluster: "leader", LeaderIndexPatterns: []string{"user-*"}},
+	}, esClient.autoFollowPatterns)
+	var updated esv1.Elasticsearch
+	require.NoError(t, client.Get(context.Background(), k8s.ExtractNamespacedName(es), &updated))
+	assert.Equal(t, "traces", updated.Annotations[ManagedAutoFollowPatternsAnnotationName])
+
+	// once cross-cluster replication is removed from the spec, the remaining auto-follow pattern is deleted
+	updated.Spec.RemoteClusters = []esv1.RemoteCluster{{Name: "leader", ElasticsearchRef: leader.ElasticsearchRef}}
+	require.NoError(t, UpdateCrossClusterReplication(context.Background(), client, esClient, licenseChecker, &updated))
+	assert.Equal(t, []string{"old", "traces"}, esClient.deletedPatterns)
+	require.NoError(t, client.Get(context.Background(), k8s.ExtractNamespacedName(es), &updated))
+	assert.NotContains(t, updated.Annotations, ManagedAutoFollowPatternsAnnotationName)
+
+	// nothing is done once the annotation is removed
+	require.NoError(t, UpdateCrossClusterReplication(context.Background(), client, nil, licenseChecker, &updated))
+}
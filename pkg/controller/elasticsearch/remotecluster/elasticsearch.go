@@ -116,6 +116,10 @@ func updateSettingsInternal(
 		remoteClustersToUpdate = append(remoteClustersToUpdate, name)
 		// Declare remote cluster in ES
 		seedHosts := []string{services.ExternalTransportServiceHost(remoteCluster.ElasticsearchRef.NamespacedName())}
+		if remoteCluster.APIKey != nil {
+			// connections secured with a cross-cluster API key go through the remote cluster server
+			seedHosts = []string{services.RemoteClusterServerServiceHost(remoteCluster.ElasticsearchRef.NamespacedName())}
+		}
 		remoteClustersToApply[name] = esclient.RemoteCluster{Seeds: seedHosts}
 		// Ensure this cluster is tracked in the annotation
 		remoteClustersInAnnotation[name] = struct{}{}
@@ -167,7 +171,8 @@ func getRemoteClustersInElasticsearch(esClient esclient.Client) (map[string]stru
 func getRemoteClustersInSpec(es esv1.Elasticsearch) map[string]esv1.RemoteCluster {
 	remoteClusters := make(map[string]esv1.RemoteCluster)
 	for _, remoteCluster := range es.Spec.RemoteClusters {
-		if !remoteCluster.ElasticsearchRef.IsDefined() {
+		// remote clusters not managed by the operator are rejected by the validation
+		if !remoteCluster.ElasticsearchRef.IsDefined() || remoteCluster.ElasticsearchRef.IsExternal() {
 			continue
 		}
 		remoteCluster.ElasticsearchRef = remoteCluster.ElasticsearchRef.WithDefaultNamespace(es.Namespace)
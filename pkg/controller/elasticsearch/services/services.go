@@ -57,6 +57,13 @@ func NewTransportService(es esv1.Elasticsearch) *corev1.Service {
 			Port:     network.TransportPort,
 		},
 	}
+	if es.Spec.RemoteClusterServer.Enabled {
+		ports = append(ports, corev1.ServicePort{
+			Name:     "tls-remote-cluster",
+			Protocol: corev1.ProtocolTCP,
+			Port:     network.RemoteClusterPort,
+		})
+	}
 
 	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
 }
@@ -72,6 +79,11 @@ func ExternalTransportServiceHost(es types.NamespacedName) string {
 	return stringsutil.Concat(TransportServiceName(es.Name), ".", es.Namespace, globalServiceSuffix, ":", strconv.Itoa(network.TransportPort))
 }
 
+// RemoteClusterServerServiceHost returns the hostname and the port used to reach the Elasticsearch remote cluster server.
+func RemoteClusterServerServiceHost(es types.NamespacedName) string {
+	return stringsutil.Concat(TransportServiceName(es.Name), ".", es.Namespace, globalServiceSuffix, ":", strconv.Itoa(network.RemoteClusterPort))
+}
+
 // ExternalServiceURL returns the URL used to reach Elasticsearch's external endpoint
 func ExternalServiceURL(es esv1.Elasticsearch) string {
 	return stringsutil.Concat(es.Spec.HTTP.Protocol(), "://", ExternalServiceName(es.Name), ".", es.Namespace, globalServiceSuffix, ":", strconv.Itoa(network.HTTPPort))
@@ -88,14 +88,15 @@ func ConfigSecret(es esv1.Elasticsearch, ssetName string, configData []byte) cor
 	}
 }
 
-// ReconcileConfig ensures the ES config for the pod is set in the apiserver.
-func ReconcileConfig(client k8s.Client, es esv1.Elasticsearch, ssetName string, config CanonicalConfig) error {
+// ReconcileConfig ensures the ES config for the pod is set in the apiserver, through server-side apply if
+// serverSideApply is true.
+func ReconcileConfig(client k8s.Client, es esv1.Elasticsearch, ssetName string, config CanonicalConfig, serverSideApply bool) error {
 	rendered, err := config.Render()
 	if err != nil {
 		return err
 	}
 	expected := ConfigSecret(es, ssetName, rendered)
-	_, err = reconciler.ReconcileSecret(client, expected, &es)
+	_, err = reconciler.ReconcileSecret(client, expected, &es, serverSideApply)
 	return err
 }
 
@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package settings
+
+import (
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+)
+
+// dataRoles are the roles replaced by the data tier roles of a NodeSet.
+var dataRoles = []esv1.NodeRole{
+	esv1.DataRole,
+	esv1.DataContentRole,
+	esv1.DataHotRole,
+	esv1.DataWarmRole,
+	esv1.DataColdRole,
+	esv1.DataFrozenRole,
+}
+
+// defaultNonDataRoles are the roles other than data roles that nodes have when node.roles is not set.
+var defaultNonDataRoles = []esv1.NodeRole{
+	esv1.MasterRole,
+	esv1.IngestRole,
+	esv1.MLRole,
+	esv1.RemoteClusterClientRole,
+	esv1.TransformRole,
+}
+
+// WithDataTierRoles returns a copy of the given user provided NodeSet configuration in which the data roles of
+// node.roles are replaced by the given data tier roles. The other roles are kept as is, or default to all the roles
+// other than data roles if node.roles is not set.
+// The data tier roles must be applied to the user configuration before merging it with the operator configuration,
+// since merging appends to the node.roles list instead of replacing it.
+func WithDataTierRoles(userConfig commonv1.Config, ver version.Version, tierRoles []esv1.NodeRole) (commonv1.Config, error) {
+	if len(tierRoles) == 0 {
+		return userConfig, nil
+	}
+	var cfg esv1.ElasticsearchSettings
+	if err := esv1.UnpackConfig(&userConfig, ver, &cfg); err != nil {
+		return commonv1.Config{}, err
+	}
+
+	var roles []string
+	if cfg.Node != nil && cfg.Node.Roles != nil {
+		for _, role := range cfg.Node.Roles {
+			if !isDataRole(esv1.NodeRole(role)) {
+				roles = append(roles, role)
+			}
+		}
+	} else {
+		for _, role := range defaultNonDataRoles {
+			roles = append(roles, string(role))
+		}
+	}
+	for _, role := range tierRoles {
+		roles = append(roles, string(role))
+	}
+
+	withRoles := userConfig.DeepCopy()
+	if withRoles.Data == nil {
+		withRoles.Data = make(map[string]interface{})
+	}
+	// node.roles may be set either with a dotted key or in a nested node object
+	delete(withRoles.Data, esv1.NodeRoles)
+	if node, isMap := withRoles.Data["node"].(map[string]interface{}); isMap {
+		delete(node, "roles")
+	}
+	withRoles.Data[esv1.NodeRoles] = roles
+	return *withRoles, nil
+}
+
+func isDataRole(role esv1.NodeRole) bool {
+	for _, dataRole := range dataRoles {
+		if role == dataRole {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+)
+
+func TestWithDataTierRoles(t *testing.T) {
+	ver := version.MustParse("7.15.0")
+	tests := []struct {
+		name      string
+		config    commonv1.Config
+		tierRoles []esv1.NodeRole
+		wantRoles []string
+	}{
+		{
+			name:      "no data tier",
+			config:    commonv1.Config{Data: map[string]interface{}{esv1.NodeRoles: []interface{}{"master", "data"}}},
+			wantRoles: []string{"master", "data"},
+		},
+		{
+			name:      "default roles",
+			tierRoles: []esv1.NodeRole{esv1.DataHotRole, esv1.DataContentRole},
+			wantRoles: []string{"master", "ingest", "ml", "remote_cluster_client", "transform", "data_hot", "data_content"},
+		},
+		{
+			name:      "data roles replaced",
+			config:    commonv1.Config{Data: map[string]interface{}{esv1.NodeRoles: []interface{}{"data", "ingest", "data_content"}}},
+			tierRoles: []esv1.NodeRole{esv1.DataWarmRole},
+			wantRoles: []string{"ingest", "data_warm"},
+		},
+		{
+			name: "nested node roles replaced",
+			config: commonv1.Config{Data: map[string]interface{}{
+				"node": map[string]interface{}{"roles": []interface{}{"data", "ml"}, "store.allow_mmap": false},
+			}},
+			tierRoles: []esv1.NodeRole{esv1.DataColdRole, esv1.DataFrozenRole},
+			wantRoles: []string{"ml", "data_cold", "data_frozen"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.config.DeepCopy()
+			withRoles, err := WithDataTierRoles(tt.config, ver, tt.tierRoles)
+			require.NoError(t, err)
+			// the user config must not be modified
+			require.Equal(t, original, &tt.config)
+
+			// node.roles must be replaced, not appended to, once merged with the operator config
+			merged, err := NewMergedESConfig("cluster", ver, corev1.IPv4Protocol, commonv1.HTTPConfig{}, withRoles, false, RemoteClusterSecurity{})
+			require.NoError(t, err)
+			cfg, err := merged.Unpack(ver)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRoles, cfg.Node.Roles)
+		})
+	}
+}
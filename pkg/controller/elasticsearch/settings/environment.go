@@ -11,6 +11,9 @@ const (
 	EnvProbePasswordPath      = "PROBE_PASSWORD_PATH"
 	EnvProbeUsername          = "PROBE_USERNAME"
 	EnvReadinessProbeProtocol = "READINESS_PROBE_PROTOCOL"
+	EnvReadinessProbePort     = "READINESS_PROBE_PORT"
+	EnvReadinessProbePath     = "READINESS_PROBE_PATH"
+	EnvReadinessProbeTimeout  = "READINESS_PROBE_TIMEOUT"
 	HeadlessServiceName       = "HEADLESS_SERVICE_NAME"
 
 	// These are injected as env var into the ES pod at runtime,
@@ -19,4 +22,6 @@ const (
 	EnvPodIP     = "POD_IP"
 	EnvNodeName  = "NODE_NAME"
 	EnvNamespace = "NAMESPACE"
+	// EnvZone holds the zone of the k8s node running the pod, when zone awareness is enabled
+	EnvZone = "ZONE"
 )
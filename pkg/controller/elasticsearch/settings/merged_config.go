@@ -20,10 +20,37 @@ import (
 	netutil "github.com/elastic/cloud-on-k8s/pkg/utils/net"
 )
 
-// the name of the ES attribute indicating the pod's current k8s node
-const nodeAttrK8sNodeName = "k8s_node_name"
+const (
+	// the name of the ES attribute indicating the pod's current k8s node
+	nodeAttrK8sNodeName = "k8s_node_name"
+	// the name of the ES attribute indicating the zone of the pod's current k8s node, when zone awareness is enabled
+	nodeAttrZone = "zone"
+)
+
+var (
+	nodeAttrNodeName = fmt.Sprintf("%s.%s", esv1.NodeAttr, nodeAttrK8sNodeName)
+	nodeAttrZoneName = fmt.Sprintf("%s.%s", esv1.NodeAttr, nodeAttrZone)
+)
 
-var nodeAttrNodeName = fmt.Sprintf("%s.%s", esv1.NodeAttr, nodeAttrK8sNodeName)
+// RemoteClusterSecurity describes how a cluster takes part in remote cluster connections secured with cross-cluster
+// API keys.
+type RemoteClusterSecurity struct {
+	// Server is true if the remote cluster server accepts connections from other clusters.
+	Server bool
+	// Client is true if the cluster connects to remote clusters with cross-cluster API keys.
+	Client bool
+}
+
+// NewRemoteClusterSecurity returns the RemoteClusterSecurity of the given cluster.
+func NewRemoteClusterSecurity(es esv1.Elasticsearch) RemoteClusterSecurity {
+	security := RemoteClusterSecurity{Server: es.Spec.RemoteClusterServer.Enabled}
+	for _, remoteCluster := range es.Spec.RemoteClusters {
+		if remoteCluster.APIKey != nil {
+			security.Client = true
+		}
+	}
+	return security
+}
 
 // NewMergedESConfig merges user provided Elasticsearch configuration with configuration derived from the given
 // parameters. The user provided config overrides have precedence over the ECK config.
@@ -33,14 +60,16 @@ func NewMergedESConfig(
 	ipFamily corev1.IPFamily,
 	httpConfig commonv1.HTTPConfig,
 	userConfig commonv1.Config,
+	zoneAwareness bool,
+	remoteClusterSecurity RemoteClusterSecurity,
 ) (CanonicalConfig, error) {
 	userCfg, err := common.NewCanonicalConfigFrom(userConfig.Data)
 	if err != nil {
 		return CanonicalConfig{}, err
 	}
-	config := baseConfig(clusterName, ver, ipFamily).CanonicalConfig
+	config := baseConfig(clusterName, ver, ipFamily, zoneAwareness).CanonicalConfig
 	err = config.MergeWith(
-		xpackConfig(ver, httpConfig).CanonicalConfig,
+		xpackConfig(ver, httpConfig, remoteClusterSecurity).CanonicalConfig,
 		userCfg,
 	)
 	if err != nil {
@@ -50,7 +79,7 @@ func NewMergedESConfig(
 }
 
 // baseConfig returns the base ES configuration to apply for the given cluster
-func baseConfig(clusterName string, ver version.Version, ipFamily corev1.IPFamily) *CanonicalConfig {
+func baseConfig(clusterName string, ver version.Version, ipFamily corev1.IPFamily, zoneAwareness bool) *CanonicalConfig {
 	cfg := map[string]interface{}{
 		// derive node name dynamically from the pod name, injected as env var
 		esv1.NodeName:    "${" + EnvPodName + "}",
@@ -69,6 +98,12 @@ func baseConfig(clusterName string, ver version.Version, ipFamily corev1.IPFamil
 		esv1.PathLogs: volume.ElasticsearchLogsMountPath,
 	}
 
+	if zoneAwareness {
+		// also spread shard copies across the zones of the k8s nodes, the zone is injected as env var
+		cfg[esv1.ShardAwarenessAttributes] = nodeAttrK8sNodeName + "," + nodeAttrZone
+		cfg[nodeAttrZoneName] = "${" + EnvZone + "}"
+	}
+
 	// seed hosts setting name changed starting ES 7.X
 	fileProvider := "file"
 	if ver.Major < 7 {
@@ -81,7 +116,7 @@ func baseConfig(clusterName string, ver version.Version, ipFamily corev1.IPFamil
 }
 
 // xpackConfig returns the configuration bit related to XPack settings
-func xpackConfig(ver version.Version, httpCfg commonv1.HTTPConfig) *CanonicalConfig {
+func xpackConfig(ver version.Version, httpCfg commonv1.HTTPConfig, remoteClusterSecurity RemoteClusterSecurity) *CanonicalConfig {
 	// enable x-pack security, including TLS
 	cfg := map[string]interface{}{
 		// x-pack security general settings
@@ -132,5 +167,30 @@ func xpackConfig(ver version.Version, httpCfg commonv1.HTTPConfig) *CanonicalCon
 		}
 	}
 
+	if ver.GTE(version.MustParse("8.10.0")) {
+		if remoteClusterSecurity.Server {
+			// the remote cluster server is secured with the transport certificates, which are also valid for the
+			// transport service used as a seed by the remote cluster clients
+			cfg[esv1.RemoteClusterServerEnabled] = "true"
+			cfg[esv1.XPackSecurityRemoteClusterServerSslKey] = path.Join(
+				volume.ConfigVolumeMountPath,
+				volume.NodeTransportCertificatePathSegment,
+				volume.NodeTransportCertificateKeyFile,
+			)
+			cfg[esv1.XPackSecurityRemoteClusterServerSslCertificate] = path.Join(
+				volume.ConfigVolumeMountPath,
+				volume.NodeTransportCertificatePathSegment,
+				volume.NodeTransportCertificateCertFile,
+			)
+		}
+		if remoteClusterSecurity.Client {
+			// trust the transport CAs of the remote clusters, which secure their remote cluster server
+			cfg[esv1.XPackSecurityRemoteClusterClientSslCertificateAuthorities] = []string{
+				path.Join(volume.TransportCertificatesSecretVolumeMountPath, certificates.CAFileName),
+				path.Join(volume.RemoteCertificateAuthoritiesSecretVolumeMountPath, certificates.CAFileName),
+			}
+		}
+	}
+
 	return &CanonicalConfig{common.MustCanonicalConfig(cfg)}
 }
@@ -34,6 +34,20 @@ func TestNewMergedESConfig(t *testing.T) {
 		Network struct {
 			PublishHost string `yaml:"publish_host"`
 		} `yaml:"network"`
+		Cluster struct {
+			Routing struct {
+				Allocation struct {
+					Awareness struct {
+						Attributes string `yaml:"attributes"`
+					} `yaml:"awareness"`
+				} `yaml:"allocation"`
+			} `yaml:"routing"`
+		} `yaml:"cluster"`
+		Node struct {
+			Attr struct {
+				Zone string `yaml:"zone"`
+			} `yaml:"attr"`
+		} `yaml:"node"`
 	}
 
 	tests := []struct {
@@ -41,7 +55,11 @@ func TestNewMergedESConfig(t *testing.T) {
 		version  string
 		ipFamily corev1.IPFamily
 		cfgData  map[string]interface{}
-		assert   func(cfg CanonicalConfig)
+		// zoneAwareness enables zone-aware shard allocation
+		zoneAwareness bool
+		assert        func(cfg CanonicalConfig)
+
+		remoteClusterSecurity RemoteClusterSecurity
 	}{
 		{
 			name:     "in 6.x, empty config should have the default file and native realm settings configured",
@@ -209,6 +227,65 @@ func TestNewMergedESConfig(t *testing.T) {
 				require.Equal(t, "[${POD_IP}]", esCfg.Network.PublishHost)
 			},
 		},
+		{
+			name:          "zone awareness adds the zone attribute",
+			version:       "7.6.0",
+			ipFamily:      corev1.IPv4Protocol,
+			cfgData:       map[string]interface{}{},
+			zoneAwareness: true,
+			assert: func(cfg CanonicalConfig) {
+				cfgBytes, err := cfg.Render()
+				require.NoError(t, err)
+				esCfg := &elasticsearchCfg{}
+				require.NoError(t, yaml.Unmarshal(cfgBytes, &esCfg))
+				require.Equal(t, "k8s_node_name,zone", esCfg.Cluster.Routing.Allocation.Awareness.Attributes)
+				require.Equal(t, "${ZONE}", esCfg.Node.Attr.Zone)
+			},
+		},
+		{
+			name:     "zone awareness attributes can be overridden",
+			version:  "7.6.0",
+			ipFamily: corev1.IPv4Protocol,
+			cfgData: map[string]interface{}{
+				esv1.ShardAwarenessAttributes: "zone",
+			},
+			zoneAwareness: true,
+			assert: func(cfg CanonicalConfig) {
+				cfgBytes, err := cfg.Render()
+				require.NoError(t, err)
+				esCfg := &elasticsearchCfg{}
+				require.NoError(t, yaml.Unmarshal(cfgBytes, &esCfg))
+				require.Equal(t, "zone", esCfg.Cluster.Routing.Allocation.Awareness.Attributes)
+			},
+		},
+		{
+			name:                  "remote cluster server and client with cross-cluster API keys",
+			version:               "8.10.0",
+			ipFamily:              corev1.IPv4Protocol,
+			cfgData:               map[string]interface{}{},
+			remoteClusterSecurity: RemoteClusterSecurity{Server: true, Client: true},
+			assert: func(cfg CanonicalConfig) {
+				require.Equal(t, 4, len(cfg.HasKeys([]string{
+					esv1.RemoteClusterServerEnabled,
+					esv1.XPackSecurityRemoteClusterServerSslKey,
+					esv1.XPackSecurityRemoteClusterServerSslCertificate,
+					esv1.XPackSecurityRemoteClusterClientSslCertificateAuthorities,
+				})))
+			},
+		},
+		{
+			name:                  "no remote cluster server before 8.10",
+			version:               "8.9.0",
+			ipFamily:              corev1.IPv4Protocol,
+			cfgData:               map[string]interface{}{},
+			remoteClusterSecurity: RemoteClusterSecurity{Server: true, Client: true},
+			assert: func(cfg CanonicalConfig) {
+				require.Equal(t, 0, len(cfg.HasKeys([]string{
+					esv1.RemoteClusterServerEnabled,
+					esv1.XPackSecurityRemoteClusterClientSslCertificateAuthorities,
+				})))
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -220,6 +297,8 @@ func TestNewMergedESConfig(t *testing.T) {
 				tt.ipFamily,
 				commonv1.HTTPConfig{},
 				commonv1.Config{Data: tt.cfgData},
+				tt.zoneAwareness,
+				tt.remoteClusterSecurity,
 			)
 			require.NoError(t, err)
 			tt.assert(cfg)
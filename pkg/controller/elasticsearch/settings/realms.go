@@ -0,0 +1,130 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package settings
+
+import (
+	"path"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	common "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+)
+
+// realmFile is a set of files of a realm projected from the keys of a Secret to a directory, along with the settings
+// pointing to them.
+type realmFile struct {
+	dir        string
+	secretName string
+	settings   []realmFileSetting
+}
+
+// realmFileSetting is a setting of a realm pointing to the file projected from a Secret key.
+type realmFileSetting struct {
+	name string
+	key  string
+}
+
+// realmFiles returns the files referenced by the given realm.
+func realmFiles(realm esv1.Realm) []realmFile {
+	var files []realmFile
+	switch realm.Type {
+	case esv1.SAMLRealmType:
+		if realm.IdPMetadata != nil {
+			files = append(files, realmFile{
+				dir:        "idp-metadata",
+				secretName: realm.IdPMetadata.SecretName,
+				settings:   []realmFileSetting{{name: "idp.metadata.path", key: realm.IdPMetadata.Key}},
+			})
+		}
+		if realm.SigningSecretName != "" {
+			files = append(files, realmFile{
+				dir:        "signing",
+				secretName: realm.SigningSecretName,
+				settings: []realmFileSetting{
+					{name: "signing.certificate", key: certificates.CertFileName},
+					{name: "signing.key", key: certificates.KeyFileName},
+				},
+			})
+		}
+	case esv1.OIDCRealmType:
+		if realm.JWKSet != nil {
+			files = append(files, realmFile{
+				dir:        "jwkset",
+				secretName: realm.JWKSet.SecretName,
+				settings:   []realmFileSetting{{name: "op.jwkset_path", key: realm.JWKSet.Key}},
+			})
+		}
+	}
+	return files
+}
+
+func (f realmFile) mountPath(realm esv1.Realm) string {
+	return path.Join(esvolume.RealmsVolumeMountPath, realm.Name, f.dir)
+}
+
+// RealmVolumes returns the volumes holding the files referenced by the given realms.
+func RealmVolumes(realms []esv1.Realm) []volume.SecretVolume {
+	var volumes []volume.SecretVolume
+	for _, realm := range realms {
+		for _, f := range realmFiles(realm) {
+			keys := make([]string, 0, len(f.settings))
+			for _, setting := range f.settings {
+				keys = append(keys, setting.key)
+			}
+			volumes = append(volumes, volume.NewSelectiveSecretVolumeWithMountPath(
+				f.secretName, esvolume.RealmVolumeNamePrefix+realm.Name+"-"+f.dir, f.mountPath(realm), keys,
+			))
+		}
+	}
+	return volumes
+}
+
+// WithRealms returns a copy of the given user provided NodeSet configuration including the settings of the given
+// realms. The NodeSet configuration has precedence over the realm settings.
+func WithRealms(userConfig commonv1.Config, realms []esv1.Realm) (commonv1.Config, error) {
+	if len(realms) == 0 {
+		return userConfig, nil
+	}
+	cfg := common.NewCanonicalConfig()
+	for _, realm := range realms {
+		var realmConfig map[string]interface{}
+		if realm.Config != nil {
+			realmConfig = realm.Config.Data
+		}
+		userRealmCfg, err := common.NewCanonicalConfigFrom(map[string]interface{}{realm.SettingsPrefix(): realmConfig})
+		if err != nil {
+			return commonv1.Config{}, err
+		}
+		// the order and the paths of the mounted files are set by the operator
+		operatorSettings := map[string]interface{}{"order": realm.Order}
+		for _, f := range realmFiles(realm) {
+			for _, setting := range f.settings {
+				operatorSettings[setting.name] = path.Join(f.mountPath(realm), setting.key)
+			}
+		}
+		operatorRealmCfg, err := common.NewCanonicalConfigFrom(map[string]interface{}{realm.SettingsPrefix(): operatorSettings})
+		if err != nil {
+			return commonv1.Config{}, err
+		}
+		if err := cfg.MergeWith(userRealmCfg, operatorRealmCfg); err != nil {
+			return commonv1.Config{}, err
+		}
+	}
+	nodeSetCfg, err := common.NewCanonicalConfigFrom(userConfig.Data)
+	if err != nil {
+		return commonv1.Config{}, err
+	}
+	if err := cfg.MergeWith(nodeSetCfg); err != nil {
+		return commonv1.Config{}, err
+	}
+	var data map[string]interface{}
+	if err := cfg.Unpack(&data); err != nil {
+		return commonv1.Config{}, err
+	}
+	return commonv1.Config{Data: data}, nil
+}
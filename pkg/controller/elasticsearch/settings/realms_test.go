@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package settings
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	common "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+)
+
+var sampleRealms = []esv1.Realm{
+	{
+		Type:  esv1.SAMLRealmType,
+		Name:  "saml1",
+		Order: 2,
+		Config: &commonv1.Config{Data: map[string]interface{}{
+			"idp.entity_id":     "https://idp.example.com",
+			"idp.metadata.path": "ignored",
+			"order":             10,
+		}},
+		IdPMetadata:       &commonv1.SecretKeySelector{SecretName: "idp", Key: "metadata.xml"},
+		SigningSecretName: "saml-signing",
+	},
+	{
+		Type:         esv1.OIDCRealmType,
+		Name:         "oidc1",
+		Order:        3,
+		ClientSecret: &commonv1.SecretKeySelector{SecretName: "oidc", Key: "client-secret"},
+		JWKSet:       &commonv1.SecretKeySelector{SecretName: "oidc", Key: "jwks.json"},
+	},
+}
+
+func TestWithRealms(t *testing.T) {
+	userConfig := commonv1.Config{Data: map[string]interface{}{
+		"node.store.allow_mmap": false,
+		"xpack.security.authc.realms.oidc.oidc1.rp.client_id": "eck",
+	}}
+	got, err := WithRealms(userConfig, sampleRealms)
+	require.NoError(t, err)
+
+	cfg, err := common.NewCanonicalConfigFrom(got.Data)
+	require.NoError(t, err)
+	expected := common.MustCanonicalConfig(map[string]interface{}{
+		"node.store.allow_mmap": false,
+		"xpack.security.authc.realms.saml.saml1": map[string]interface{}{
+			"order":               2,
+			"idp.entity_id":       "https://idp.example.com",
+			"idp.metadata.path":   "/usr/share/elasticsearch/config/realms/saml1/idp-metadata/metadata.xml",
+			"signing.certificate": "/usr/share/elasticsearch/config/realms/saml1/signing/tls.crt",
+			"signing.key":         "/usr/share/elasticsearch/config/realms/saml1/signing/tls.key",
+		},
+		"xpack.security.authc.realms.oidc.oidc1": map[string]interface{}{
+			"order":          3,
+			"rp.client_id":   "eck",
+			"op.jwkset_path": "/usr/share/elasticsearch/config/realms/oidc1/jwkset/jwks.json",
+		},
+	})
+	require.Empty(t, cfg.Diff(expected, nil))
+
+	// no realm: the user config is returned as is
+	got, err = WithRealms(userConfig, nil)
+	require.NoError(t, err)
+	require.Equal(t, userConfig, got)
+}
+
+func TestRealmVolumes(t *testing.T) {
+	volumes := RealmVolumes(sampleRealms)
+	require.Len(t, volumes, 3)
+
+	var names []string
+	for _, v := range volumes {
+		names = append(names, v.Name())
+	}
+	require.Equal(t, []string{
+		"elastic-internal-realm-saml1-idp-metadata",
+		"elastic-internal-realm-saml1-signing",
+		"elastic-internal-realm-oidc1-jwkset",
+	}, names)
+
+	require.Equal(t, "/usr/share/elasticsearch/config/realms/saml1/signing", volumes[1].VolumeMount().MountPath)
+	require.Equal(t, []corev1.KeyToPath{{Key: "tls.crt", Path: "tls.crt"}, {Key: "tls.key", Path: "tls.key"}},
+		volumes[1].Volume().Secret.Items)
+}
+
+func TestRealmVolumes_maxRealmNameLength(t *testing.T) {
+	// realm names are limited to 27 characters by the validation, so that the longest volume name fits in a DNS label
+	name := strings.Repeat("r", 27)
+	volumes := RealmVolumes([]esv1.Realm{{
+		Type:              esv1.SAMLRealmType,
+		Name:              name,
+		IdPMetadata:       &commonv1.SecretKeySelector{SecretName: "idp", Key: "metadata.xml"},
+		SigningSecretName: "saml-signing",
+	}})
+	require.Len(t, volumes, 2)
+	require.Equal(t, "elastic-internal-realm-"+name+"-idp-metadata", volumes[0].Name())
+	for _, v := range volumes {
+		require.Empty(t, validation.IsDNS1123Label(v.Name()), v.Name())
+	}
+}
@@ -15,8 +15,14 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
 )
 
-// ReconcileStatefulSet creates or updates the expected StatefulSet.
-func ReconcileStatefulSet(c k8s.Client, es esv1.Elasticsearch, expected appsv1.StatefulSet, expectations *expectations.Expectations) (appsv1.StatefulSet, error) {
+// ReconcileStatefulSet creates or updates the expected StatefulSet, through server-side apply if serverSideApply is true.
+func ReconcileStatefulSet(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	expected appsv1.StatefulSet,
+	expectations *expectations.Expectations,
+	serverSideApply bool,
+) (appsv1.StatefulSet, error) {
 	podTemplateValidator := newPodTemplateValidator(c, es, expected)
 	var reconciled appsv1.StatefulSet
 	err := reconciler.ReconcileResource(reconciler.Params{
@@ -48,6 +54,7 @@ func ReconcileStatefulSet(c k8s.Client, es esv1.Elasticsearch, expected appsv1.S
 				expectations.ExpectGeneration(reconciled)
 			}
 		},
+		ServerSideApply: serverSideApply,
 	})
 	return reconciled, err
 }
@@ -24,14 +24,14 @@ var (
 )
 
 // ReconcileConfigSecrets reconciles the secrets holding beats configuration
-func ReconcileConfigSecrets(client k8s.Client, es esv1.Elasticsearch) error {
+func ReconcileConfigSecrets(client k8s.Client, es esv1.Elasticsearch, serverSideApply bool) error {
 	if monitoring.IsMetricsDefined(&es) {
 		b, err := Metricbeat(client, es)
 		if err != nil {
 			return err
 		}
 
-		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &es); err != nil {
+		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &es, serverSideApply); err != nil {
 			return err
 		}
 	}
@@ -42,7 +42,7 @@ func ReconcileConfigSecrets(client k8s.Client, es esv1.Elasticsearch) error {
 			return err
 		}
 
-		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &es); err != nil {
+		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &es, serverSideApply); err != nil {
 			return err
 		}
 	}
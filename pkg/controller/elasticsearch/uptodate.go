@@ -0,0 +1,214 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// ResyncIntervalAnnotation is the name of the annotation used to set the interval at which a cluster is reconciled
+// even if nothing changed.
+const ResyncIntervalAnnotation = "eck.k8s.elastic.co/es-resync-interval"
+
+// resyncInterval returns the interval after which the given cluster must be reconciled again even if nothing changed,
+// 0 if it is only reconciled on changes. It is raised to the slow-poll interval while the cluster is quiet.
+func (r *ReconcileElasticsearch) resyncInterval(es esv1.Elasticsearch) time.Duration {
+	interval := annotation.ExtractTimeout(es.ObjectMeta, ResyncIntervalAnnotation, r.ResyncInterval)
+	if interval > 0 && r.SlowPollInterval > interval && r.esObservers.Quiet(k8s.ExtractNamespacedName(&es)) {
+		return r.SlowPollInterval
+	}
+	return interval
+}
+
+// reconcileInputs are the inputs of the reconciliation of an Elasticsearch cluster which may change without the
+// generation of the Elasticsearch resource being updated.
+type reconcileInputs struct {
+	Generation  int64
+	Labels      map[string]string
+	Annotations map[string]string
+	// Health is the last health reported by the observer of the cluster.
+	Health esv1.ElasticsearchHealth
+	// AllocationExplanation is the last summary of the allocation of unassigned shards reported by the observer.
+	AllocationExplanation string
+	// CircuitBreakerOpen is the state of the circuit breaker of the Elasticsearch client, reported as a condition.
+	CircuitBreakerOpen bool
+	// ResourceVersions are the versions of the watched resources related to the cluster, indexed by kind and name.
+	ResourceVersions map[string]string
+}
+
+// reconcileInputsHash returns a hash of the inputs of the reconciliation of the given cluster, as visible through the
+// cache of the client.
+func (r *ReconcileElasticsearch) reconcileInputsHash(es esv1.Elasticsearch) (string, error) {
+	inputs := reconcileInputs{
+		Generation:         es.Generation,
+		Labels:             es.Labels,
+		Annotations:        es.Annotations,
+		ResourceVersions:   make(map[string]string),
+		CircuitBreakerOpen: esclient.CircuitBreakerOpen(k8s.ExtractNamespacedName(&es)),
+	}
+	if state, observed := r.esObservers.LastState(k8s.ExtractNamespacedName(&es)); observed {
+		if state.ClusterHealth != nil {
+			inputs.Health = state.ClusterHealth.Status
+		}
+		if state.AllocationExplanation != nil {
+			inputs.AllocationExplanation = state.AllocationExplanation.Summary()
+		}
+	}
+
+	inNamespace := client.InNamespace(es.Namespace)
+	matchingCluster := client.MatchingLabels{label.ClusterNameLabelName: es.Name}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.Client.List(context.Background(), &statefulSets, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, s := range statefulSets.Items {
+		inputs.ResourceVersions["StatefulSet/"+s.Name] = s.ResourceVersion
+	}
+	var pods corev1.PodList
+	if err := r.Client.List(context.Background(), &pods, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, p := range pods.Items {
+		inputs.ResourceVersions["Pod/"+p.Name] = p.ResourceVersion
+	}
+	var services corev1.ServiceList
+	if err := r.Client.List(context.Background(), &services, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, s := range services.Items {
+		inputs.ResourceVersions["Service/"+s.Name] = s.ResourceVersion
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := r.Client.List(context.Background(), &pvcs, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, p := range pvcs.Items {
+		inputs.ResourceVersions["PersistentVolumeClaim/"+p.Name] = p.ResourceVersion
+	}
+	var configMaps corev1.ConfigMapList
+	if err := r.Client.List(context.Background(), &configMaps, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, cm := range configMaps.Items {
+		inputs.ResourceVersions["ConfigMap/"+cm.Name] = cm.ResourceVersion
+	}
+
+	// owned, soft-owned and labelled secrets, such as the ones of the users and service accounts of associations
+	var secrets corev1.SecretList
+	if err := r.Client.List(context.Background(), &secrets, inNamespace, matchingCluster); err != nil {
+		return "", err
+	}
+	for _, s := range secrets.Items {
+		inputs.ResourceVersions["Secret/"+s.Name] = s.ResourceVersion
+	}
+	// secrets provided by the user, and the API keys of the remote clusters
+	namedSecrets := append(
+		r.dynamicWatches.Secrets.WatchedBy(k8s.ExtractNamespacedName(&es)),
+		types.NamespacedName{Namespace: es.Namespace, Name: esv1.RemoteAPIKeysSecretName(es.Name)},
+	)
+	for _, nsn := range namedSecrets {
+		var secret corev1.Secret
+		err := r.Client.Get(context.Background(), nsn, &secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		inputs.ResourceVersions["Secret/"+nsn.String()] = secret.ResourceVersion
+	}
+
+	// remote clusters
+	for _, remoteCluster := range es.Spec.RemoteClusters {
+		ref := remoteCluster.ElasticsearchRef.WithDefaultNamespace(es.Namespace)
+		if !ref.IsDefined() || ref.IsExternal() {
+			continue
+		}
+		var remoteES esv1.Elasticsearch
+		err := r.Client.Get(context.Background(), ref.NamespacedName(), &remoteES)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		inputs.ResourceVersions["Elasticsearch/"+ref.NamespacedName().String()] = remoteES.ResourceVersion
+	}
+
+	return hash.HashObject(inputs), nil
+}
+
+// upToDate is the hash of the inputs of a reconciliation which left nothing to do, along with the time at which
+// the reconciliation must be performed again regardless of its inputs.
+type upToDate struct {
+	inputsHash string
+	expiresAt  time.Time
+}
+
+// upToDateClusters records the clusters whose last reconciliation left nothing to do, so that the next
+// reconciliations can return immediately as long as their inputs do not change. This avoids rebuilding the expected
+// resources and requesting Elasticsearch on reconciliations triggered by status updates or duplicate events.
+type upToDateClusters struct {
+	mutex    sync.Mutex
+	clusters map[types.NamespacedName]upToDate
+	now      func() time.Time
+}
+
+func newUpToDateClusters() *upToDateClusters {
+	return &upToDateClusters{clusters: make(map[types.NamespacedName]upToDate), now: time.Now}
+}
+
+// IsUpToDate returns true if the last reconciliation of the cluster left nothing to do with the same inputs, along
+// with the delay after which the cluster must be reconciled again if any.
+func (u *upToDateClusters) IsUpToDate(cluster types.NamespacedName, inputsHash string) (bool, time.Duration) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	entry, exists := u.clusters[cluster]
+	if !exists || entry.inputsHash != inputsHash {
+		return false, 0
+	}
+	if entry.expiresAt.IsZero() {
+		return true, 0
+	}
+	remaining := entry.expiresAt.Sub(u.now())
+	return remaining > 0, remaining
+}
+
+// InputsChanged returns true if the given inputs differ from the ones of the last reconciliation which left nothing to
+// do, or if there is none.
+func (u *upToDateClusters) InputsChanged(cluster types.NamespacedName, inputsHash string) bool {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	entry, exists := u.clusters[cluster]
+	return !exists || entry.inputsHash != inputsHash
+}
+
+// Set records that the reconciliation of the cluster with the given inputs left nothing to do, until requeueAfter
+// if not zero.
+func (u *upToDateClusters) Set(cluster types.NamespacedName, inputsHash string, requeueAfter time.Duration) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	entry := upToDate{inputsHash: inputsHash}
+	if requeueAfter > 0 {
+		entry.expiresAt = u.now().Add(requeueAfter)
+	}
+	u.clusters[cluster] = entry
+}
+
+// Forget removes the cluster, so that its next reconciliation is performed.
+func (u *upToDateClusters) Forget(cluster types.NamespacedName) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	delete(u.clusters, cluster)
+}
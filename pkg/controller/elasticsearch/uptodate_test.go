@@ -0,0 +1,183 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileElasticsearch_reconcileInputsHash(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Generation: 1}}
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "es-es-default-0", Labels: map[string]string{label.ClusterNameLabelName: "es"},
+	}}
+	pvc := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "elasticsearch-data-es-es-default-0", Labels: map[string]string{label.ClusterNameLabelName: "es"},
+	}}
+	// secure settings of the snapshot repositories referencing the cluster, owned by the cluster
+	snapshotCredentials := corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: esv1.SnapshotCredentialsSecretName("es"), Labels: map[string]string{label.ClusterNameLabelName: "es"},
+	}}
+	require.NoError(t, controllerutil.SetControllerReference(&es, &snapshotCredentials, scheme.Scheme))
+	userSecret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "user-provided"}}
+	otherSecret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unrelated"}}
+	c := k8s.NewFakeClient(&es, &pod, &pvc, &snapshotCredentials, &userSecret, &otherSecret)
+	r := &ReconcileElasticsearch{
+		Client:         c,
+		esObservers:    observer.NewManager(observer.Settings{}),
+		dynamicWatches: watches.NewDynamicWatches(),
+	}
+	require.NoError(t, r.dynamicWatches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    "user-provided",
+		Watched: []types.NamespacedName{k8s.ExtractNamespacedName(&userSecret)},
+		Watcher: k8s.ExtractNamespacedName(&es),
+	}))
+
+	initial, err := r.reconcileInputsHash(es)
+	require.NoError(t, err)
+	changed := func() bool {
+		h, err := r.reconcileInputsHash(es)
+		require.NoError(t, err)
+		return h != initial
+	}
+
+	// status updates and unrelated resources do not change the inputs
+	es.Status.Phase = esv1.ElasticsearchReadyPhase
+	otherSecret.Data = map[string][]byte{"key": []byte("value")}
+	require.NoError(t, c.Update(context.Background(), &otherSecret))
+	require.False(t, changed())
+
+	// related resources do
+	pod.Labels["foo"] = "bar"
+	require.NoError(t, c.Update(context.Background(), &pod))
+	require.True(t, changed())
+	initial, err = r.reconcileInputsHash(es)
+	require.NoError(t, err)
+
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")}
+	require.NoError(t, c.Update(context.Background(), &pvc))
+	require.True(t, changed())
+	initial, err = r.reconcileInputsHash(es)
+	require.NoError(t, err)
+
+	snapshotCredentials.Data = map[string][]byte{"s3.client.default.access_key": []byte("key")}
+	require.NoError(t, c.Update(context.Background(), &snapshotCredentials))
+	require.True(t, changed())
+	initial, err = r.reconcileInputsHash(es)
+	require.NoError(t, err)
+
+	userSecret.Data = map[string][]byte{"key": []byte("value")}
+	require.NoError(t, c.Update(context.Background(), &userSecret))
+	require.True(t, changed())
+	initial, err = r.reconcileInputsHash(es)
+	require.NoError(t, err)
+
+	remoteAPIKeys := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: esv1.RemoteAPIKeysSecretName("es")}}
+	require.NoError(t, c.Create(context.Background(), &remoteAPIKeys))
+	require.True(t, changed())
+	initial, err = r.reconcileInputsHash(es)
+	require.NoError(t, err)
+
+	// as well as changes to the Elasticsearch resource
+	es.Annotations = map[string]string{"foo": "bar"}
+	require.True(t, changed())
+}
+
+func TestUpToDateClusters(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "ns", Name: "es"}
+	now := time.Now()
+	u := newUpToDateClusters()
+	u.now = func() time.Time { return now }
+
+	upToDate, _ := u.IsUpToDate(cluster, "hash")
+	require.False(t, upToDate)
+
+	u.Set(cluster, "hash", 0)
+	upToDate, requeueAfter := u.IsUpToDate(cluster, "hash")
+	require.True(t, upToDate)
+	require.Equal(t, time.Duration(0), requeueAfter)
+	upToDate, _ = u.IsUpToDate(cluster, "other-hash")
+	require.False(t, upToDate)
+
+	// the cluster must be reconciled again once the requested requeue delay elapsed
+	u.Set(cluster, "hash", time.Minute)
+	now = now.Add(20 * time.Second)
+	upToDate, requeueAfter = u.IsUpToDate(cluster, "hash")
+	require.True(t, upToDate)
+	require.Equal(t, 40*time.Second, requeueAfter)
+	now = now.Add(40 * time.Second)
+	upToDate, _ = u.IsUpToDate(cluster, "hash")
+	require.False(t, upToDate)
+
+	// an expired entry does not mean the inputs changed
+	require.False(t, u.InputsChanged(cluster, "hash"))
+	require.True(t, u.InputsChanged(cluster, "other-hash"))
+
+	u.Set(cluster, "hash", 0)
+	u.Forget(cluster)
+	upToDate, _ = u.IsUpToDate(cluster, "hash")
+	require.False(t, upToDate)
+	require.True(t, u.InputsChanged(cluster, "hash"))
+}
+
+func TestReconcileElasticsearch_resyncInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      operator.Parameters
+		annotations map[string]string
+		want        time.Duration
+	}{
+		{
+			name: "no resync",
+			want: 0,
+		},
+		{
+			name:   "operator resync interval",
+			params: operator.Parameters{ResyncInterval: time.Minute},
+			want:   time.Minute,
+		},
+		{
+			name:        "resync interval annotation",
+			params:      operator.Parameters{ResyncInterval: time.Minute},
+			annotations: map[string]string{ResyncIntervalAnnotation: "30m"},
+			want:        30 * time.Minute,
+		},
+		{
+			name:        "resync disabled by annotation",
+			params:      operator.Parameters{ResyncInterval: time.Minute},
+			annotations: map[string]string{ResyncIntervalAnnotation: "0s"},
+			want:        0,
+		},
+		{
+			name:   "cluster not observed, slow-poll mode does not apply",
+			params: operator.Parameters{ResyncInterval: time.Minute, SlowPollAfter: time.Hour, SlowPollInterval: 10 * time.Minute},
+			want:   time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconcileElasticsearch{Parameters: tt.params, esObservers: observer.NewManager(observer.Settings{})}
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", Annotations: tt.annotations}}
+			require.Equal(t, tt.want, r.resyncInterval(es))
+		})
+	}
+}
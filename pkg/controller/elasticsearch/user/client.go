@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+// NewControllerUserClient returns a client to the given Elasticsearch cluster, authenticated as the controller user
+// and trusting the cluster public certificates. It is meant to be used by controllers other than the Elasticsearch one.
+func NewControllerUserClient(
+	ctx context.Context,
+	c k8s.Client,
+	dialer net.Dialer,
+	es esv1.Elasticsearch,
+	dryRun bool,
+) (esclient.Client, error) {
+	defer tracing.Span(&ctx)()
+	url := services.ExternalServiceURL(es)
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	// Get user Secret
+	var controllerUserSecret corev1.Secret
+	key := types.NamespacedName{
+		Namespace: es.Namespace,
+		Name:      esv1.InternalUsersSecret(es.Name),
+	}
+	if err := c.Get(context.Background(), key, &controllerUserSecret); err != nil {
+		return nil, err
+	}
+	password, ok := controllerUserSecret.Data[ControllerUserName]
+	if !ok {
+		return nil, fmt.Errorf("controller user %s not found in Secret %s/%s", ControllerUserName, key.Namespace, key.Name)
+	}
+
+	// Get public certs
+	var caSecret corev1.Secret
+	key = types.NamespacedName{
+		Namespace: es.Namespace,
+		Name:      certificates.PublicCertsSecretName(esv1.ESNamer, es.Name),
+	}
+	if err := c.Get(context.Background(), key, &caSecret); err != nil {
+		return nil, err
+	}
+	trustedCerts, ok := caSecret.Data[certificates.CertFileName]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in Secret %s/%s", certificates.CertFileName, key.Namespace, key.Name)
+	}
+	caCerts, err := certificates.ParsePEMCerts(trustedCerts)
+	if err != nil {
+		return nil, err
+	}
+	return esclient.SharedStateCache.Wrap(k8s.ExtractNamespacedName(&es), esclient.NewElasticsearchClient(
+		dialer,
+		k8s.ExtractNamespacedName(&es),
+		url,
+		esclient.BasicAuth{
+			Name:     ControllerUserName,
+			Password: string(password),
+		},
+		v,
+		caCerts,
+		esclient.Timeout(es),
+		dryRun,
+	)), nil
+}
+
+// ControllerUserClientProvider returns a function creating clients authenticated as the controller user, which do not
+// send the requests changing the state of the clusters if dryRun is true.
+func ControllerUserClientProvider(dryRun bool) func(context.Context, k8s.Client, net.Dialer, esv1.Elasticsearch) (esclient.Client, error) {
+	return func(ctx context.Context, c k8s.Client, dialer net.Dialer, es esv1.Elasticsearch) (esclient.Client, error) {
+		return NewControllerUserClient(ctx, c, dialer, es, dryRun)
+	}
+}
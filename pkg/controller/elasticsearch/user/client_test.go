@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"context"
+	"encoding/pem"
+	stdnet "net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// serverDialer dials the given address whatever the requested one.
+type serverDialer string
+
+func (d serverDialer) DialContext(ctx context.Context, network, _ string) (stdnet.Conn, error) {
+	var dialer stdnet.Dialer
+	return dialer.DialContext(ctx, network, string(d))
+}
+
+func TestNewControllerUserClient_SharedStateCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"green"}`))
+	}))
+	defer server.Close()
+
+	defaultCache := esclient.SharedStateCache
+	defer func() { esclient.SharedStateCache = defaultCache }()
+	esclient.SharedStateCache = esclient.NewStateCache(time.Minute)
+
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       esv1.ElasticsearchSpec{Version: "7.17.0"},
+	}
+	c := k8s.NewFakeClient(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: esv1.InternalUsersSecret("es")},
+			Data:       map[string][]byte{ControllerUserName: []byte("password")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: certificates.PublicCertsSecretName(esv1.ESNamer, "es")},
+			Data: map[string][]byte{certificates.CertFileName: pem.EncodeToMemory(&pem.Block{
+				Type: "CERTIFICATE", Bytes: server.Certificate().Raw,
+			})},
+		},
+	)
+
+	// clients created by the controllers interacting with the same cluster share the cached cluster state
+	for i := 0; i < 2; i++ {
+		esClient, err := NewControllerUserClient(context.Background(), c, serverDialer(server.Listener.Addr().String()), es, false)
+		require.NoError(t, err)
+		health, err := esClient.GetClusterHealth(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, esv1.ElasticsearchGreenHealth, health.Status)
+		esClient.Close()
+	}
+	require.Equal(t, 1, requests)
+}
@@ -6,6 +6,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -13,13 +14,16 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user/filerealm"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
 )
 
 const (
@@ -35,7 +39,14 @@ const (
 )
 
 // reconcileElasticUser reconciles a single secret holding the "elastic" user password.
-func reconcileElasticUser(c k8s.Client, es esv1.Elasticsearch, existingFileRealm filerealm.Realm) (users, error) {
+func reconcileElasticUser(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	existingFileRealm filerealm.Realm,
+	policy rotationPolicy,
+	recorder record.EventRecorder,
+	serverSideApply bool,
+) (users, error) {
 	return reconcilePredefinedUsers(
 		c,
 		es,
@@ -47,11 +58,22 @@ func reconcileElasticUser(c k8s.Client, es esv1.Elasticsearch, existingFileRealm
 		// Don't set an ownerRef for the elastic user secret, likely to be copied into different namespaces.
 		// See https://github.com/elastic/cloud-on-k8s/issues/3986.
 		false,
+		false,
+		policy,
+		recorder,
+		serverSideApply,
 	)
 }
 
 // reconcileInternalUsers reconciles a single secret holding the internal users passwords.
-func reconcileInternalUsers(c k8s.Client, es esv1.Elasticsearch, existingFileRealm filerealm.Realm) (users, error) {
+func reconcileInternalUsers(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	existingFileRealm filerealm.Realm,
+	policy rotationPolicy,
+	recorder record.EventRecorder,
+	serverSideApply bool,
+) (users, error) {
 	return reconcilePredefinedUsers(
 		c,
 		es,
@@ -63,11 +85,20 @@ func reconcileInternalUsers(c k8s.Client, es esv1.Elasticsearch, existingFileRea
 		},
 		esv1.InternalUsersSecret(es.Name),
 		true,
+		// the operator, the readiness probe and the Metricbeat sidecars only switch to the rotated passwords once
+		// Elasticsearch accepts them
+		true,
+		policy,
+		recorder,
+		serverSideApply,
 	)
 }
 
 // reconcilePredefinedUsers reconciles a secret with the given name holding the given users.
 // It attempts to reuse passwords from pre-existing secrets, and reuse hashes from pre-existing file realms.
+// Passwords are regenerated if the rotation policy requires it: the new hashes end up in the file realm, which
+// Elasticsearch reloads without restarting. If the rotation is staged, the new passwords are stored as pending
+// passwords alongside the current ones until PromotePendingPasswords switches to them.
 func reconcilePredefinedUsers(
 	c k8s.Client,
 	es esv1.Elasticsearch,
@@ -75,15 +106,41 @@ func reconcilePredefinedUsers(
 	users users,
 	secretName string,
 	setOwnerRef bool,
+	staged bool,
+	policy rotationPolicy,
+	recorder record.EventRecorder,
+	serverSideApply bool,
 ) (users, error) {
 	secretNsn := types.NamespacedName{Namespace: es.Namespace, Name: secretName}
 
-	// build users, reusing existing passwords and bcrypt hashes if possible
-	var err error
-	users, err = reuseOrGeneratePassword(c, users, secretNsn)
-	if err != nil {
+	var existing corev1.Secret
+	err := c.Get(context.Background(), secretNsn, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
+	exists := err == nil
+
+	now := time.Now()
+	var rotate bool
+	var trigger string
+	if exists {
+		rotate, trigger = policy.rotationDue(existing, now)
+	}
+	// record the rotation state on new or rotated secrets, existing annotations are otherwise preserved
+	var annotations map[string]string
+	if !exists || rotate {
+		annotations = policy.annotations(now)
+	}
+
+	// build users, reusing existing passwords and bcrypt hashes if possible
+	existingPasswords := existing.Data
+	if rotate && !staged {
+		existingPasswords = nil
+	}
+	users = reuseOrGeneratePassword(users, existingPasswords)
+	if staged {
+		users = reuseOrGeneratePendingPassword(users, existing.Data, rotate)
+	}
 	users, err = reuseOrGenerateHash(users, existingFileRealm)
 	if err != nil {
 		return nil, err
@@ -93,59 +150,82 @@ func reconcilePredefinedUsers(
 	secretData := make(map[string][]byte, len(users))
 	for _, u := range users {
 		secretData[u.Name] = u.Password
+		if u.PendingPassword != nil {
+			secretData[pendingPasswordKey(u.Name)] = u.PendingPassword
+		}
 	}
 
 	expected := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: secretNsn.Namespace,
-			Name:      secretNsn.Name,
-			Labels:    common.AddCredentialsLabel(label.NewLabels(k8s.ExtractNamespacedName(&es))),
+			Namespace:   secretNsn.Namespace,
+			Name:        secretNsn.Name,
+			Labels:      common.AddCredentialsLabel(label.NewLabels(k8s.ExtractNamespacedName(&es))),
+			Annotations: annotations,
 		},
 		Data: secretData,
 	}
 
 	if setOwnerRef {
-		_, err = reconciler.ReconcileSecret(c, expected, &es)
+		_, err = reconciler.ReconcileSecret(c, expected, &es, serverSideApply)
 	} else {
 		_, err = reconciler.ReconcileSecretNoOwnerRef(c, expected, &es)
 	}
-	return users, err
-}
-
-// reuseOrGeneratePassword updates the users with existing passwords reused from the existing K8s secret,
-// or generates new passwords.
-func reuseOrGeneratePassword(c k8s.Client, users users, secretRef types.NamespacedName) (users, error) {
-	var secret corev1.Secret
-	err := c.Get(context.Background(), secretRef, &secret)
-	if err != nil && !apierrors.IsNotFound(err) {
+	if err != nil {
 		return nil, err
 	}
-	// default to an empty secret
-	if apierrors.IsNotFound(err) {
-		secret = corev1.Secret{}
-	}
-	if secret.Data == nil {
-		secret.Data = map[string][]byte{}
+
+	switch {
+	case rotate && staged:
+		log.Info("Generated new predefined users passwords, pending until Elasticsearch accepts them",
+			"namespace", es.Namespace, "es_name", es.Name, "secret_name", secretName, "trigger", trigger)
+		recorder.Eventf(&es, corev1.EventTypeNormal, events.EventReasonRotated,
+			"Rotating the passwords stored in secret %s (trigger: %s)", secretName, trigger)
+		metrics.ESCredentialsRotations.WithLabelValues(es.Namespace, es.Name, trigger).Inc()
+	case rotate:
+		log.Info("Rotated predefined users passwords",
+			"namespace", es.Namespace, "es_name", es.Name, "secret_name", secretName, "trigger", trigger)
+		recorder.Eventf(&es, corev1.EventTypeNormal, events.EventReasonRotated,
+			"Rotated the passwords stored in secret %s (trigger: %s)", secretName, trigger)
+		metrics.ESCredentialsRotations.WithLabelValues(es.Namespace, es.Name, trigger).Inc()
 	}
+	return users, nil
+}
+
+// reuseOrGeneratePassword updates the users with existing passwords reused from the existing K8s secret data,
+// or generates new passwords.
+func reuseOrGeneratePassword(users users, existingPasswords map[string][]byte) users {
 	// either reuse the password or generate a new one
 	for i, u := range users {
-		if password, exists := secret.Data[u.Name]; exists {
+		if password, exists := existingPasswords[u.Name]; exists {
 			users[i].Password = password
 		} else {
 			users[i].Password = common.FixedLengthRandomPasswordBytes()
 		}
 	}
-	return users, nil
+	return users
+}
+
+// reuseOrGeneratePendingPassword updates the users with the pending passwords of a staged rotation, reused from the
+// existing K8s secret data, or newly generated if a rotation is due.
+func reuseOrGeneratePendingPassword(users users, existingPasswords map[string][]byte, rotate bool) users {
+	for i, u := range users {
+		if rotate {
+			users[i].PendingPassword = common.FixedLengthRandomPasswordBytes()
+		} else if password, exists := existingPasswords[pendingPasswordKey(u.Name)]; exists {
+			users[i].PendingPassword = password
+		}
+	}
+	return users
 }
 
 // reuseOrGenerateHash updates the users with existing hashes from the given file realm, or generates new ones.
 func reuseOrGenerateHash(users users, fileRealm filerealm.Realm) (users, error) {
 	for i, u := range users {
 		existingHash := fileRealm.PasswordHashForUser(u.Name)
-		if bcrypt.CompareHashAndPassword(existingHash, u.Password) == nil {
+		if bcrypt.CompareHashAndPassword(existingHash, u.realmPassword()) == nil {
 			users[i].PasswordHash = existingHash
 		} else {
-			hash, err := bcrypt.GenerateFromPassword(u.Password, bcrypt.DefaultCost)
+			hash, err := bcrypt.GenerateFromPassword(u.realmPassword(), bcrypt.DefaultCost)
 			if err != nil {
 				return nil, err
 			}
@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user/filerealm"
@@ -110,7 +111,7 @@ func Test_reconcileElasticUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := k8s.NewFakeClient(tt.existingSecrets...)
-			got, err := reconcileElasticUser(c, es, tt.existingFileRealm)
+			got, err := reconcileElasticUser(c, es, tt.existingFileRealm, rotationPolicy{}, record.NewFakeRecorder(10), false)
 			require.NoError(t, err)
 			// check returned user
 			require.Len(t, got, 1)
@@ -224,7 +225,7 @@ func Test_reconcileInternalUsers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := k8s.NewFakeClient(tt.existingSecrets...)
-			got, err := reconcileInternalUsers(c, es, tt.existingFileRealm)
+			got, err := reconcileInternalUsers(c, es, tt.existingFileRealm, rotationPolicy{}, record.NewFakeRecorder(10), false)
 			require.NoError(t, err)
 			// check returned users
 			require.Len(t, got, 3)
@@ -7,6 +7,7 @@ package user
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"go.elastic.co/apm"
 	corev1 "k8s.io/api/core/v1"
@@ -14,8 +15,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
@@ -31,7 +34,8 @@ var log = ulog.Log.WithName("elasticsearch-user")
 
 // ReconcileUsersAndRoles fetches all users and roles and aggregates them into a single
 // Kubernetes secret mounted in the Elasticsearch Pods.
-// That secret contains the file realm files (`users` and `users_roles`) and the file roles (`roles.yml`).
+// That secret contains the file realm files (`users` and `users_roles`), the file roles (`roles.yml`) and the
+// service account tokens (`service_tokens`).
 // Users are aggregated from various sources:
 // - predefined users include the controller user, the probe user, and the public-facing elastic user
 // - associated users come from resource associations (eg. Kibana or APMServer)
@@ -39,33 +43,65 @@ var log = ulog.Log.WithName("elasticsearch-user")
 // Roles are aggregated from:
 // - predefined roles (for the probe user)
 // - user-provided roles referenced in the Elasticsearch spec
+// Service account tokens come from resource associations (eg. Kibana).
 func ReconcileUsersAndRoles(
 	ctx context.Context,
 	c k8s.Client,
 	es esv1.Elasticsearch,
 	watched watches.DynamicWatches,
 	recorder record.EventRecorder,
-) (esclient.BasicAuth, error) {
+	serverSideApply bool,
+) (esclient.BasicAuth, *reconciler.Results) {
 	span, _ := apm.StartSpan(ctx, "reconcile_users", tracing.SpanTypeApp)
 	defer span.End()
+	results := reconciler.NewResult(ctx)
+
+	policy, err := newRotationPolicy(es)
+	if err != nil {
+		// keep reconciling with the on-demand rotation only, the interval is ignored until fixed
+		log.Info("Ignoring credentials rotation interval", "namespace", es.Namespace, "es_name", es.Name, "error", err.Error())
+		recorder.Event(&es, corev1.EventTypeWarning, events.EventReasonValidation, err.Error())
+	}
 
 	// build aggregate roles and file realms
 	roles, err := aggregateRoles(c, es, watched, recorder)
 	if err != nil {
-		return esclient.BasicAuth{}, err
+		return esclient.BasicAuth{}, results.WithError(err)
+	}
+	fileRealm, internalUsers, err := aggregateFileRealm(c, es, watched, recorder, policy, serverSideApply)
+	if err != nil {
+		return esclient.BasicAuth{}, results.WithError(err)
+	}
+	// grab the controller user credentials for later use
+	controllerUser, err := internalUsers.credentialsFor(ControllerUserName)
+	if err != nil {
+		return esclient.BasicAuth{}, results.WithError(err)
+	}
+	probeUser, err := internalUsers.realmCredentialsFor(ProbeUserName)
+	if err != nil {
+		return esclient.BasicAuth{}, results.WithError(err)
 	}
-	fileRealm, controllerUser, err := aggregateFileRealm(c, es, watched, recorder)
+	serviceAccountTokens, err := retrieveServiceAccountTokens(c, es)
 	if err != nil {
-		return esclient.BasicAuth{}, err
+		return esclient.BasicAuth{}, results.WithError(err)
 	}
 
 	// reconcile the aggregate secret
-	if err := reconcileRolesFileRealmSecret(c, es, roles, fileRealm); err != nil {
-		return esclient.BasicAuth{}, err
+	if err := reconcileRolesFileRealmSecret(c, es, roles, fileRealm, serviceAccountTokens, []byte(probeUser.Password), serverSideApply); err != nil {
+		return esclient.BasicAuth{}, results.WithError(err)
+	}
+
+	// requeue to rotate the passwords once they reach their maximum age
+	requeueAfter, err := nextRotation(c, es, policy, time.Now())
+	if err != nil {
+		return esclient.BasicAuth{}, results.WithError(err)
+	}
+	if requeueAfter > 0 {
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
 	}
 
 	// return the controller user for next reconciliation steps to interact with Elasticsearch
-	return controllerUser, nil
+	return controllerUser, results
 }
 
 func getExistingFileRealm(c k8s.Client, es esv1.Elasticsearch) (filerealm.Realm, error) {
@@ -76,42 +112,44 @@ func getExistingFileRealm(c k8s.Client, es esv1.Elasticsearch) (filerealm.Realm,
 	return filerealm.FromSecret(secret)
 }
 
-// aggregateFileRealm builds a single file realm from multiple ones, and returns the controller user credentials.
+// aggregateFileRealm builds a single file realm from multiple ones, and returns the internal users.
 func aggregateFileRealm(
 	c k8s.Client,
 	es esv1.Elasticsearch,
 	watched watches.DynamicWatches,
 	recorder record.EventRecorder,
-) (filerealm.Realm, esclient.BasicAuth, error) {
+	policy rotationPolicy,
+	serverSideApply bool,
+) (filerealm.Realm, users, error) {
 	// retrieve existing file realm to reuse predefined users password hashes if possible
 	existingFileRealm, err := getExistingFileRealm(c, es)
 	if err != nil && apierrors.IsNotFound(err) {
 		// no secret yet, work with an empty file realm
 		existingFileRealm = filerealm.New()
 	} else if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
+		return filerealm.Realm{}, nil, err
 	}
 
 	// reconcile predefined users
-	elasticUser, err := reconcileElasticUser(c, es, existingFileRealm)
+	elasticUser, err := reconcileElasticUser(c, es, existingFileRealm, policy, recorder, serverSideApply)
 	if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
+		return filerealm.Realm{}, nil, err
 	}
-	internalUsers, err := reconcileInternalUsers(c, es, existingFileRealm)
+	internalUsers, err := reconcileInternalUsers(c, es, existingFileRealm, policy, recorder, serverSideApply)
 	if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
+		return filerealm.Realm{}, nil, err
 	}
 
 	// fetch associated users
 	associatedUsers, err := retrieveAssociatedUsers(c, es)
 	if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
+		return filerealm.Realm{}, nil, err
 	}
 
 	// watch & fetch user-provided file realm & roles
 	userProvidedFileRealm, err := reconcileUserProvidedFileRealm(c, es, watched, recorder)
 	if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
+		return filerealm.Realm{}, nil, err
 	}
 
 	// merge all file realms together, the last one having precedence
@@ -122,12 +160,7 @@ func aggregateFileRealm(
 		userProvidedFileRealm,
 	)
 
-	// grab the controller user credentials for later use
-	controllerCreds, err := internalUsers.credentialsFor(ControllerUserName)
-	if err != nil {
-		return filerealm.Realm{}, esclient.BasicAuth{}, err
-	}
-	return fileRealm, controllerCreds, nil
+	return fileRealm, internalUsers, nil
 }
 
 func aggregateRoles(
@@ -149,14 +182,28 @@ func RolesFileRealmSecretKey(es esv1.Elasticsearch) types.NamespacedName {
 	return types.NamespacedName{Namespace: es.Namespace, Name: esv1.RolesAndFileRealmSecret(es.Name)}
 }
 
-// reconcileRolesFileRealmSecret creates or updates the single secret holding the file realm and the file-based roles.
-func reconcileRolesFileRealmSecret(c k8s.Client, es esv1.Elasticsearch, roles RolesFileContent, fileRealm filerealm.Realm) error {
+// reconcileRolesFileRealmSecret creates or updates the single secret holding the file realm, the file-based roles and
+// the service account tokens. It also holds the password of the probe user matching the file realm: both are updated
+// at once in the Pods, the readiness probe keeps authenticating while the passwords are rotated.
+func reconcileRolesFileRealmSecret(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	roles RolesFileContent,
+	fileRealm filerealm.Realm,
+	serviceAccountTokens ServiceAccountTokens,
+	probePassword []byte,
+	serverSideApply bool,
+) error {
 	secretData := fileRealm.FileBytes()
 	rolesBytes, err := roles.FileBytes()
 	if err != nil {
 		return err
 	}
 	secretData[RolesFile] = rolesBytes
+	secretData[ServiceTokensFile] = serviceAccountTokens.FileBytes()
+	if probePassword != nil {
+		secretData[ProbeUserName] = probePassword
+	}
 
 	expected := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -184,5 +231,6 @@ func reconcileRolesFileRealmSecret(c k8s.Client, es esv1.Elasticsearch, roles Ro
 			maps.Merge(reconciled.Labels, expected.Labels)
 			maps.Merge(reconciled.Annotations, expected.Annotations)
 		},
+		ServerSideApply: serverSideApply,
 	})
 }
@@ -36,6 +36,13 @@ const (
 	// ApmAgentUserRole is the name of the role used by APMServer instances to connect to Kibana
 	ApmAgentUserRole = "eck_apm_agent_user_role"
 
+	// LogstashUserRole is the name of the role used by Logstash instances to write events to Elasticsearch.
+	LogstashUserRole = "eck_logstash_user_role"
+
+	// AgentUserRole is the name of the role used by Elastic Agent instances, other than Fleet Server, to write
+	// events to Elasticsearch.
+	AgentUserRole = "eck_agent_user_role"
+
 	// StackMonitoringMetricsUserRole is the name of the role used by Metricbeat and Filebeat to send metrics and log
 	// data to the monitoring Elasticsearch cluster when Stack Monitoring is enabled
 	StackMonitoringUserRole = "eck_stack_mon_user_role"
@@ -108,6 +115,29 @@ var (
 				},
 			},
 		},
+		// LogstashUserRole allows the Elasticsearch output of the Logstash pipelines to write events in the default
+		// Logstash indices and data streams, and to manage their index templates and ILM policies.
+		// See: https://www.elastic.co/guide/en/logstash/current/ls-security.html.
+		LogstashUserRole: esclient.Role{
+			Cluster: []string{"monitor", "manage_ilm", "read_ilm", "manage_logstash_pipelines", "manage_index_templates", "cluster:admin/ingest/pipeline/get"},
+			Indices: []esclient.IndexRole{
+				{
+					Names:      []string{"logstash", "logstash-*", "ecs-logstash", "ecs-logstash-*", "logs-*", "metrics-*", "synthetics-*", "traces-*"},
+					Privileges: []string{"write", "create", "create_index", "manage", "manage_ilm"},
+				},
+			},
+		},
+		// AgentUserRole allows standalone Elastic Agents to write events in the data streams of the integrations.
+		// See: https://www.elastic.co/guide/en/fleet/current/grant-access-to-elasticsearch.html.
+		AgentUserRole: esclient.Role{
+			Cluster: []string{"monitor"},
+			Indices: []esclient.IndexRole{
+				{
+					Names:      []string{"logs-*-*", "metrics-*-*", "traces-*-*", "synthetics-*-*"},
+					Privileges: []string{"auto_configure", "create_doc"},
+				},
+			},
+		},
 		// StackMonitoringUserRole is a dedicated role for Stack Monitoring with Metricbeat and Filebeat used for the
 		// user sending monitoring data.
 		// See: https://www.elastic.co/guide/en/beats/filebeat/7.14/privileges-to-publish-monitoring.html.
@@ -0,0 +1,179 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// CredentialsRotatedAtAnnotation is set on the Secrets of the predefined users to the time at which their passwords
+	// were generated, to decide when they must be rotated.
+	CredentialsRotatedAtAnnotation = "eck.k8s.elastic.co/credentials-rotated-at"
+	// CredentialsRotationAnnotation is set on the Secrets of the predefined users to the last value of the
+	// esv1.RotateCredentialsAnnotation taken into account.
+	CredentialsRotationAnnotation = "eck.k8s.elastic.co/credentials-rotation"
+
+	// rotationTriggerAnnotation and rotationTriggerInterval are the triggers reported in the rotations metric.
+	rotationTriggerAnnotation = "annotation"
+	rotationTriggerInterval   = "interval"
+)
+
+// pendingPasswordKey returns the key of the Secret of the internal users holding the password generated for the given
+// user by a staged rotation, until Elasticsearch accepts it.
+func pendingPasswordKey(userName string) string {
+	return userName + ".pending"
+}
+
+// rotationPolicy specifies when the passwords of the predefined users must be regenerated.
+type rotationPolicy struct {
+	// trigger is the value of the rotation annotation of the Elasticsearch resource, each new value requests a rotation.
+	trigger string
+	// interval is the maximum age of the passwords, zero if they are not rotated periodically.
+	interval time.Duration
+}
+
+// newRotationPolicy returns the rotation policy set with annotations on the given Elasticsearch resource.
+// An invalid interval is reported as an error, along with a policy ignoring it.
+func newRotationPolicy(es esv1.Elasticsearch) (rotationPolicy, error) {
+	policy := rotationPolicy{trigger: es.Annotations[esv1.RotateCredentialsAnnotation]}
+	value, exists := es.Annotations[esv1.CredentialsRotationIntervalAnnotation]
+	if !exists {
+		return policy, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return policy, fmt.Errorf("invalid value %q for annotation %s: expected a positive duration such as 720h",
+			value, esv1.CredentialsRotationIntervalAnnotation)
+	}
+	policy.interval = interval
+	return policy, nil
+}
+
+// rotationDue returns whether the passwords of the given existing Secret must be rotated, and the reason why.
+func (p rotationPolicy) rotationDue(secret corev1.Secret, now time.Time) (bool, string) {
+	if p.trigger != "" && secret.Annotations[CredentialsRotationAnnotation] != p.trigger {
+		return true, rotationTriggerAnnotation
+	}
+	if p.interval > 0 && !now.Before(rotatedAt(secret).Add(p.interval)) {
+		return true, rotationTriggerInterval
+	}
+	return false, ""
+}
+
+// nextRotation returns the duration until the passwords of the given existing Secret must be rotated, or zero if they
+// are not rotated periodically.
+func (p rotationPolicy) nextRotation(secret corev1.Secret, now time.Time) time.Duration {
+	if p.interval <= 0 {
+		return 0
+	}
+	next := rotatedAt(secret).Add(p.interval).Sub(now)
+	if next <= 0 {
+		// rotation is overdue, retry shortly
+		return time.Second
+	}
+	return next
+}
+
+// annotations returns the annotations recording the rotation state on a Secret whose passwords were generated at the
+// given time.
+func (p rotationPolicy) annotations(generatedAt time.Time) map[string]string {
+	annotations := map[string]string{CredentialsRotatedAtAnnotation: generatedAt.UTC().Format(time.RFC3339)}
+	if p.trigger != "" {
+		annotations[CredentialsRotationAnnotation] = p.trigger
+	}
+	return annotations
+}
+
+// rotatedAt returns the time at which the passwords of the given Secret were generated, defaulting to the creation of
+// the Secret for Secrets created by previous versions of the operator.
+func rotatedAt(secret corev1.Secret) time.Time {
+	if value, exists := secret.Annotations[CredentialsRotatedAtAnnotation]; exists {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+	return secret.CreationTimestamp.Time
+}
+
+// nextRotation returns the duration until the passwords of the predefined users of the given cluster must be rotated,
+// or zero if they are not rotated periodically.
+func nextRotation(c k8s.Client, es esv1.Elasticsearch, policy rotationPolicy, now time.Time) (time.Duration, error) {
+	if policy.interval <= 0 {
+		return 0, nil
+	}
+	var next time.Duration
+	for _, secretName := range []string{esv1.ElasticUserSecret(es.Name), esv1.InternalUsersSecret(es.Name)} {
+		var secret corev1.Secret
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: secretName}, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if d := policy.nextRotation(secret, now); next == 0 || d < next {
+			next = d
+		}
+	}
+	return next, nil
+}
+
+// PromotePendingPasswords completes a staged rotation of the internal users passwords. Elasticsearch reloads the file
+// realm holding the new password hashes asynchronously, the consumers of the internal users Secret keep using the
+// current passwords until authenticate succeeds with the pending password of each user. The pending passwords then
+// replace the current ones.
+// It returns the credentials of the controller user to use for the rest of the reconciliation, and whether pending
+// passwords are still waiting to be accepted.
+func PromotePendingPasswords(
+	c k8s.Client,
+	es esv1.Elasticsearch,
+	authenticate func(esclient.BasicAuth) error,
+) (esclient.BasicAuth, bool, error) {
+	var secret corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.InternalUsersSecret(es.Name)}, &secret); err != nil {
+		return esclient.BasicAuth{}, false, err
+	}
+	controllerUser := esclient.BasicAuth{Name: ControllerUserName, Password: string(secret.Data[ControllerUserName])}
+
+	promoted := make(map[string][]byte)
+	for name, password := range secret.Data {
+		userName := strings.TrimSuffix(name, pendingPasswordKey(""))
+		if userName == name {
+			continue
+		}
+		if err := authenticate(esclient.BasicAuth{Name: userName, Password: string(password)}); err != nil {
+			log.V(1).Info("Pending password not accepted yet, keeping the current one",
+				"namespace", es.Namespace, "es_name", es.Name, "user", userName, "error", err.Error())
+			return controllerUser, true, nil
+		}
+		promoted[userName] = password
+	}
+	if len(promoted) == 0 {
+		return controllerUser, false, nil
+	}
+
+	for userName, password := range promoted {
+		secret.Data[userName] = password
+		delete(secret.Data, pendingPasswordKey(userName))
+	}
+	if err := c.Update(context.Background(), &secret); err != nil {
+		return esclient.BasicAuth{}, false, err
+	}
+	log.Info("Rotated predefined users passwords accepted by Elasticsearch",
+		"namespace", es.Namespace, "es_name", es.Name, "secret_name", secret.Name)
+	return esclient.BasicAuth{Name: ControllerUserName, Password: string(secret.Data[ControllerUserName])}, false, nil
+}
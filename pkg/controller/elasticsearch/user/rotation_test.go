@@ -0,0 +1,276 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user/filerealm"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_newRotationPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        rotationPolicy
+		wantErr     bool
+	}{
+		{
+			name: "no rotation",
+			want: rotationPolicy{},
+		},
+		{
+			name: "on-demand rotation and interval",
+			annotations: map[string]string{
+				esv1.RotateCredentialsAnnotation:           "2021-11-30",
+				esv1.CredentialsRotationIntervalAnnotation: "720h",
+			},
+			want: rotationPolicy{trigger: "2021-11-30", interval: 720 * time.Hour},
+		},
+		{
+			name: "invalid interval is ignored",
+			annotations: map[string]string{
+				esv1.RotateCredentialsAnnotation:           "1",
+				esv1.CredentialsRotationIntervalAnnotation: "one month",
+			},
+			want:    rotationPolicy{trigger: "1"},
+			wantErr: true,
+		},
+		{
+			name:        "negative interval is ignored",
+			annotations: map[string]string{esv1.CredentialsRotationIntervalAnnotation: "-1h"},
+			want:        rotationPolicy{},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := newRotationPolicy(es)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_rotationPolicy_rotationDue(t *testing.T) {
+	now := time.Date(2021, 11, 30, 12, 0, 0, 0, time.UTC)
+	secretRotatedAt := func(rotatedAt time.Time, trigger string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(now.Add(-1000 * time.Hour)),
+			Annotations:       rotationPolicy{trigger: trigger}.annotations(rotatedAt),
+		}}
+	}
+	tests := []struct {
+		name        string
+		policy      rotationPolicy
+		secret      corev1.Secret
+		wantDue     bool
+		wantTrigger string
+		wantNext    time.Duration
+	}{
+		{
+			name:   "no rotation policy",
+			policy: rotationPolicy{},
+			secret: secretRotatedAt(now.Add(-1000*time.Hour), ""),
+		},
+		{
+			name:        "new annotation value",
+			policy:      rotationPolicy{trigger: "2"},
+			secret:      secretRotatedAt(now, "1"),
+			wantDue:     true,
+			wantTrigger: rotationTriggerAnnotation,
+		},
+		{
+			name:   "annotation value already taken into account",
+			policy: rotationPolicy{trigger: "1"},
+			secret: secretRotatedAt(now, "1"),
+		},
+		{
+			name:     "passwords younger than the interval",
+			policy:   rotationPolicy{interval: 24 * time.Hour},
+			secret:   secretRotatedAt(now.Add(-4*time.Hour), ""),
+			wantNext: 20 * time.Hour,
+		},
+		{
+			name:        "passwords older than the interval",
+			policy:      rotationPolicy{interval: 24 * time.Hour},
+			secret:      secretRotatedAt(now.Add(-25*time.Hour), ""),
+			wantDue:     true,
+			wantTrigger: rotationTriggerInterval,
+			wantNext:    time.Second,
+		},
+		{
+			name:        "secret created by a previous operator version: use its creation time",
+			policy:      rotationPolicy{interval: 24 * time.Hour},
+			secret:      corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-48 * time.Hour))}},
+			wantDue:     true,
+			wantTrigger: rotationTriggerInterval,
+			wantNext:    time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, trigger := tt.policy.rotationDue(tt.secret, now)
+			require.Equal(t, tt.wantDue, due)
+			require.Equal(t, tt.wantTrigger, trigger)
+			require.Equal(t, tt.wantNext, tt.policy.nextRotation(tt.secret, now))
+		})
+	}
+}
+
+func Test_reconcilePredefinedUsers_rotation(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	c := k8s.NewFakeClient()
+	recorder := record.NewFakeRecorder(10)
+	secretKey := types.NamespacedName{Namespace: es.Namespace, Name: esv1.ElasticUserSecret(es.Name)}
+
+	// create the elastic user
+	initial, err := reconcileElasticUser(c, es, filerealm.New(), rotationPolicy{}, recorder, false)
+	require.NoError(t, err)
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	require.Contains(t, secret.Annotations, CredentialsRotatedAtAnnotation)
+	require.NotContains(t, secret.Annotations, CredentialsRotationAnnotation)
+	existingRealm := initial.fileRealm()
+
+	// request a rotation
+	policy := rotationPolicy{trigger: "1"}
+	rotated, err := reconcileElasticUser(c, es, existingRealm, policy, recorder, false)
+	require.NoError(t, err)
+	require.NotEqual(t, initial[0].Password, rotated[0].Password)
+	require.NotEqual(t, initial[0].PasswordHash, rotated[0].PasswordHash)
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	require.Equal(t, rotated[0].Password, secret.Data[ElasticUserName])
+	require.Equal(t, "1", secret.Annotations[CredentialsRotationAnnotation])
+	require.Len(t, recorder.Events, 1)
+
+	// the same annotation value does not trigger another rotation
+	again, err := reconcileElasticUser(c, es, rotated.fileRealm(), policy, recorder, false)
+	require.NoError(t, err)
+	require.Equal(t, rotated[0].Password, again[0].Password)
+	require.Equal(t, rotated[0].PasswordHash, again[0].PasswordHash)
+	require.Len(t, recorder.Events, 1)
+}
+
+func Test_reconcilePredefinedUsers_stagedRotation(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	c := k8s.NewFakeClient()
+	recorder := record.NewFakeRecorder(10)
+	secretKey := types.NamespacedName{Namespace: "ns", Name: esv1.InternalUsersSecret("es")}
+
+	initial, err := reconcileInternalUsers(c, es, filerealm.New(), rotationPolicy{}, recorder, false)
+	require.NoError(t, err)
+	initialPasswords := make(map[string][]byte)
+	for _, u := range initial {
+		initialPasswords[u.Name] = u.Password
+	}
+
+	// all the internal users passwords are rotated, the new ones are pending until Elasticsearch accepts them
+	rotated, err := reconcileInternalUsers(c, es, initial.fileRealm(), rotationPolicy{trigger: "1"}, recorder, false)
+	require.NoError(t, err)
+	require.Len(t, recorder.Events, 1)
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.Background(), secretKey, &secret))
+	for _, u := range rotated {
+		require.Equal(t, initialPasswords[u.Name], u.Password, u.Name)
+		require.NotNil(t, u.PendingPassword, u.Name)
+		require.NotEqual(t, initialPasswords[u.Name], u.PendingPassword, u.Name)
+		require.NoError(t, bcrypt.CompareHashAndPassword(u.PasswordHash, u.PendingPassword), u.Name)
+		require.Equal(t, initialPasswords[u.Name], secret.Data[u.Name], u.Name)
+		require.Equal(t, u.PendingPassword, secret.Data[pendingPasswordKey(u.Name)], u.Name)
+	}
+
+	// the pending passwords are kept by the next reconciliations
+	again, err := reconcileInternalUsers(c, es, rotated.fileRealm(), rotationPolicy{trigger: "1"}, recorder, false)
+	require.NoError(t, err)
+	require.Equal(t, rotated, again)
+	require.Len(t, recorder.Events, 1)
+}
+
+func TestPromotePendingPasswords(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	secretKey := types.NamespacedName{Namespace: "ns", Name: esv1.InternalUsersSecret("es")}
+	secret := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+			Data: map[string][]byte{
+				ControllerUserName:                     []byte("controller-current"),
+				pendingPasswordKey(ControllerUserName): []byte("controller-pending"),
+				MonitoringUserName:                     []byte("monitoring-current"),
+				pendingPasswordKey(MonitoringUserName): []byte("monitoring-pending"),
+				ProbeUserName:                          []byte("probe-current"),
+				pendingPasswordKey(ProbeUserName):      []byte("probe-pending"),
+			},
+		}
+	}
+
+	t.Run("pending passwords not accepted yet", func(t *testing.T) {
+		c := k8s.NewFakeClient(secret())
+		controllerUser, pending, err := PromotePendingPasswords(c, es, func(u esclient.BasicAuth) error {
+			if u.Name == MonitoringUserName {
+				return errors.New("401")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, pending)
+		require.Equal(t, esclient.BasicAuth{Name: ControllerUserName, Password: "controller-current"}, controllerUser)
+		var actual corev1.Secret
+		require.NoError(t, c.Get(context.Background(), secretKey, &actual))
+		require.Equal(t, secret().Data, actual.Data)
+	})
+
+	t.Run("pending passwords accepted", func(t *testing.T) {
+		c := k8s.NewFakeClient(secret())
+		var authenticated []string
+		controllerUser, pending, err := PromotePendingPasswords(c, es, func(u esclient.BasicAuth) error {
+			authenticated = append(authenticated, u.Name+":"+u.Password)
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, pending)
+		require.ElementsMatch(t, []string{
+			"elastic-internal:controller-pending",
+			"elastic-internal-monitoring:monitoring-pending",
+			"elastic-internal-probe:probe-pending",
+		}, authenticated)
+		require.Equal(t, esclient.BasicAuth{Name: ControllerUserName, Password: "controller-pending"}, controllerUser)
+		var actual corev1.Secret
+		require.NoError(t, c.Get(context.Background(), secretKey, &actual))
+		require.Equal(t, map[string][]byte{
+			ControllerUserName: []byte("controller-pending"),
+			MonitoringUserName: []byte("monitoring-pending"),
+			ProbeUserName:      []byte("probe-pending"),
+		}, actual.Data)
+	})
+
+	t.Run("no pending password", func(t *testing.T) {
+		c := k8s.NewFakeClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+			Data:       map[string][]byte{ControllerUserName: []byte("controller-current")},
+		})
+		controllerUser, pending, err := PromotePendingPasswords(c, es, func(u esclient.BasicAuth) error {
+			t.Fatalf("unexpected authentication of %s", u.Name)
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, pending)
+		require.Equal(t, esclient.BasicAuth{Name: ControllerUserName, Password: "controller-current"}, controllerUser)
+	})
+}
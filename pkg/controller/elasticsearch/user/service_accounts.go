@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// ServiceTokensFile is the name of the service tokens file in the ES config dir.
+	ServiceTokensFile = "service_tokens"
+
+	// ServiceAccountTokenType is used to annotate a service account token secret, most likely created by an
+	// association controller.
+	ServiceAccountTokenType = "service-account-token"
+
+	// ServiceAccountTokenNameField is the field in the secret that contains the qualified name of the token
+	// (eg. elastic/kibana/my-token).
+	ServiceAccountTokenNameField = "name"
+	// ServiceAccountHashField is the field in the secret that contains the hash of the token.
+	ServiceAccountHashField = "hash"
+)
+
+// ServiceAccountToken represents a service account token allowing a resource associated with Elasticsearch
+// (eg. Kibana) to authenticate as an Elasticsearch service account.
+type ServiceAccountToken struct {
+	// QualifiedName is the name of the token, prefixed by the name of the service account (eg. elastic/kibana/my-token).
+	QualifiedName string
+	// Hash of the token secret.
+	Hash []byte
+}
+
+// ServiceAccountTokens is a list of service account tokens.
+type ServiceAccountTokens []ServiceAccountToken
+
+// FileBytes returns the content of the service tokens file, with tokens sorted by name.
+func (s ServiceAccountTokens) FileBytes() []byte {
+	sorted := make(ServiceAccountTokens, len(s))
+	copy(sorted, s)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].QualifiedName < sorted[j].QualifiedName })
+
+	var buf bytes.Buffer
+	for _, token := range sorted {
+		buf.WriteString(token.QualifiedName)
+		buf.WriteString(":")
+		buf.Write(token.Hash)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// ServiceAccountTokenLabels returns labels matching service account tokens for the given es resource.
+func ServiceAccountTokenLabels(es esv1.Elasticsearch) map[string]string {
+	return map[string]string{
+		label.ClusterNameLabelName: es.Name,
+		common.TypeLabelName:       ServiceAccountTokenType,
+	}
+}
+
+// retrieveServiceAccountTokens fetches the service account tokens resulting from an association (eg. Kibana tokens).
+// Those tokens are created by an association controller.
+func retrieveServiceAccountTokens(c k8s.Client, es esv1.Elasticsearch) (ServiceAccountTokens, error) {
+	var tokenSecrets corev1.SecretList
+	if err := c.List(context.Background(),
+		&tokenSecrets,
+		client.InNamespace(es.Namespace),
+		client.MatchingLabels(ServiceAccountTokenLabels(es)),
+	); err != nil {
+		return nil, err
+	}
+
+	tokens := make(ServiceAccountTokens, 0, len(tokenSecrets.Items))
+	for _, secret := range tokenSecrets.Items {
+		token, err := parseServiceAccountTokenSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// parseServiceAccountTokenSecret reads a service account token from a secret.
+func parseServiceAccountTokenSecret(secret corev1.Secret) (ServiceAccountToken, error) {
+	token := ServiceAccountToken{}
+	if name, ok := secret.Data[ServiceAccountTokenNameField]; ok && len(name) > 0 {
+		token.QualifiedName = string(name)
+	} else {
+		return token, fmt.Errorf(fieldNotFound, ServiceAccountTokenNameField, secret.Namespace, secret.Name)
+	}
+
+	if hash, ok := secret.Data[ServiceAccountHashField]; ok && len(hash) > 0 {
+		token.Hash = hash
+	} else {
+		return token, fmt.Errorf(fieldNotFound, ServiceAccountHashField, secret.Namespace, secret.Name)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_retrieveServiceAccountTokens(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+	}
+	tokenSecret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: es.Namespace, Name: name, Labels: ServiceAccountTokenLabels(es)},
+			Data:       data,
+		}
+	}
+	tests := []struct {
+		name    string
+		secrets []runtime.Object
+		want    ServiceAccountTokens
+		wantErr bool
+	}{
+		{
+			name: "no service account token secret",
+			want: ServiceAccountTokens{},
+		},
+		{
+			name: "some service account token secrets",
+			secrets: []runtime.Object{
+				tokenSecret("token1", map[string][]byte{
+					ServiceAccountTokenNameField: []byte("elastic/kibana/ns_kb1"),
+					ServiceAccountHashField:      []byte("hash1"),
+				}),
+				tokenSecret("token2", map[string][]byte{
+					ServiceAccountTokenNameField: []byte("elastic/fleet-server/ns_agent"),
+					ServiceAccountHashField:      []byte("hash2"),
+				}),
+				// not labelled as a service account token
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: es.Namespace, Name: "user", Labels: AssociatedUserLabels(es)},
+					Data:       map[string][]byte{UserNameField: []byte("user"), PasswordHashField: []byte("hash")},
+				},
+			},
+			want: ServiceAccountTokens{
+				{QualifiedName: "elastic/kibana/ns_kb1", Hash: []byte("hash1")},
+				{QualifiedName: "elastic/fleet-server/ns_agent", Hash: []byte("hash2")},
+			},
+		},
+		{
+			name: "missing hash",
+			secrets: []runtime.Object{
+				tokenSecret("token1", map[string][]byte{ServiceAccountTokenNameField: []byte("elastic/kibana/ns_kb1")}),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := retrieveServiceAccountTokens(k8s.NewFakeClient(tt.secrets...), es)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestServiceAccountTokens_FileBytes(t *testing.T) {
+	tokens := ServiceAccountTokens{
+		{QualifiedName: "elastic/kibana/ns_kb2", Hash: []byte("hash2")},
+		{QualifiedName: "elastic/fleet-server/ns_agent", Hash: []byte("hash3")},
+		{QualifiedName: "elastic/kibana/ns_kb1", Hash: []byte("hash1")},
+	}
+	require.Equal(t,
+		"elastic/fleet-server/ns_agent:hash3\nelastic/kibana/ns_kb1:hash1\nelastic/kibana/ns_kb2:hash2\n",
+		string(tokens.FileBytes()),
+	)
+	require.Empty(t, ServiceAccountTokens{}.FileBytes())
+}
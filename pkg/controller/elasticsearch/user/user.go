@@ -13,10 +13,21 @@ import (
 
 // user is a convenience struct to represent a file realm user.
 type user struct {
-	Name         string
-	Password     []byte
-	PasswordHash []byte
-	Roles        []string
+	Name     string
+	Password []byte
+	// PendingPassword is the password generated by a rotation, used instead of Password in the file realm until
+	// Elasticsearch accepts it.
+	PendingPassword []byte
+	PasswordHash    []byte
+	Roles           []string
+}
+
+// realmPassword returns the password matching the hash of the user in the file realm.
+func (u user) realmPassword() []byte {
+	if u.PendingPassword != nil {
+		return u.PendingPassword
+	}
+	return u.Password
 }
 
 // Realm builds a file realm representation of this user.
@@ -50,6 +61,16 @@ func (users users) credentialsFor(userName string) (client.BasicAuth, error) {
 	return client.BasicAuth{}, fmt.Errorf("user %s not found", userName)
 }
 
+// realmCredentialsFor returns basic auth credentials for the given user, with the password matching the file realm.
+func (users users) realmCredentialsFor(userName string) (client.BasicAuth, error) {
+	for _, u := range users {
+		if u.Name == userName {
+			return client.BasicAuth{Name: userName, Password: string(u.realmPassword())}, nil
+		}
+	}
+	return client.BasicAuth{}, fmt.Errorf("user %s not found", userName)
+}
+
 // fromAssociatedUsers returns a list of user from the given associated users.
 func fromAssociatedUsers(associatedUsers []AssociatedUser) users {
 	users := make(users, 0, len(associatedUsers))
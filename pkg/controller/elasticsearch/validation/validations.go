@@ -7,15 +7,25 @@ package validation
 import (
 	"fmt"
 	"net"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	commonsettings "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
 	stackmon "github.com/elastic/cloud-on-k8s/pkg/controller/common/stackmon/validations"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/jvm"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
 	netutil "github.com/elastic/cloud-on-k8s/pkg/utils/net"
@@ -23,12 +33,39 @@ import (
 
 var log = ulog.Log.WithName("es-validation")
 
+// esJavaOptsEnvVar mirrors settings.EnvEsJavaOpts, which cannot be imported here without an import cycle.
+const esJavaOptsEnvVar = "ES_JAVA_OPTS"
+
+// initTaskContainerNamePrefix mirrors initcontainer.InitTaskContainerNamePrefix, for the same reason.
+const initTaskContainerNamePrefix = "elastic-internal-task-"
+
 const (
 	autoscalingVersionMsg    = "autoscaling is not available in this version of Elasticsearch"
 	cfgInvalidMsg            = "Configuration invalid"
+	dataTiersAutoscalingMsg  = "Data tiers cannot be configured along with autoscaling"
+	dataTiersVersionMsg      = "Data tiers are not available in this version of Elasticsearch"
 	duplicateNodeSets        = "NodeSet names must be unique"
+	invalidTierOrderMsg      = "Downscale policy tier order must list unique node roles"
+	pdbPerTierMsg            = "Pod disruption budgets per tier cannot be combined with a disabled or user-defined pod disruption budget"
+	invalidDataTierMsg       = "Data tiers must list existing NodeSets which do not use the legacy node role settings"
+	invalidDataTierAgeMsg    = "Data tier ages must be a number followed by a time unit, for example 30d"
+	invalidDataTierILMMsg    = "The data tiers ILM policy must have a name, and a snapshot repository if the frozen tier is configured"
+	invalidInitTaskMsg       = "Init tasks must have a unique name other than nodeset-plugins, and specify exactly one of installPlugins, listing unique plugins, or downloadFiles"
+	invalidInitTaskFileMsg   = "Downloaded files must have a http(s) URL and a path relative to the configuration directory"
+	invalidCCRMsg            = "Follower indices and auto-follow patterns must have unique, non-empty names and reference an Elasticsearch cluster"
+	invalidAPIKeyAccessMsg   = "Cross-cluster API keys must reference an Elasticsearch cluster and grant search or replication access to some indices"
+	remoteClusterVersionMsg  = "Cross-cluster API keys and the remote cluster server are not available in this version of Elasticsearch"
+	invalidRealmMsg          = "Realms must have a unique name, a valid DNS label of at most 27 characters, and only reference the secrets of their type"
+	invalidRealmOrderMsg     = "Realm orders must be unique across the realms of the cluster and greater than the order of the native realm (-99)"
+	realmsVersionMsg         = "Realms can only be configured from Elasticsearch 7.0.0"
+	snapshotRestoreChangeMsg = "The initial snapshot restore can only be removed once the cluster exists. Any other change is forbidden"
+	jvmHeapTooLargeMsg       = "JVM heap size must not exceed 50% of the container memory limit"
+	invalidReadinessProbeMsg = "Readiness probe must request the HTTP layer of the local node, on / or /_nodes/_local or with the local=true parameter"
+	duplicateProbeMsg        = "Readiness probe cannot be customized both in the NodeSet and in its PodTemplate"
 	invalidNamesErrMsg       = "Elasticsearch configuration would generate resources with invalid names"
 	invalidSanIPErrMsg       = "Invalid SAN IP address. Must be a valid IPv4 address"
+	transportCertsMsg        = "A transport CA and a transport node certificate cannot be both provided"
+	certManagerMsg           = "cert-manager requires an issuer name and cannot be combined with user-provided HTTP or transport certificates"
 	masterRequiredMsg        = "Elasticsearch needs to have at least one master node"
 	mixedRoleConfigMsg       = "Detected a combination of node.roles and %s. Use only node.roles"
 	noDowngradesMsg          = "Downgrades are not supported"
@@ -52,6 +89,7 @@ func updateValidations(k8sClient k8s.Client, validateStorageClass bool) []update
 	return []updateValidation{
 		noDowngrades,
 		validUpgradePath,
+		noInitialSnapshotRestoreChange,
 		func(current esv1.Elasticsearch, proposed esv1.Elasticsearch) field.ErrorList {
 			return validPVCModification(current, proposed, k8sClient, validateStorageClass)
 		},
@@ -69,6 +107,18 @@ func validations(exposedNodeLabels NodeLabels) []validation {
 		hasCorrectNodeRoles,
 		supportedVersion,
 		validSanIP,
+		validTransportCertificates,
+		validCertManager,
+		validDownscalePolicy,
+		validPodDisruptionBudgetPerTier,
+		validRealms,
+		validInitTasks,
+		validDataTiers,
+		validRemoteClusterRefs,
+		validCrossClusterReplication,
+		validRemoteClusterAPIKeys,
+		validReadinessProbes,
+		validJVMHeap,
 		validAutoscalingConfiguration,
 		validPVCNaming,
 		validMonitoring,
@@ -233,6 +283,452 @@ func validSanIP(es esv1.Elasticsearch) field.ErrorList {
 	return errs
 }
 
+// knownNodeRoles are the node roles that can be listed in the downscale policy tier order.
+var knownNodeRoles = []esv1.NodeRole{
+	esv1.DataColdRole,
+	esv1.DataContentRole,
+	esv1.DataFrozenRole,
+	esv1.DataHotRole,
+	esv1.DataRole,
+	esv1.DataWarmRole,
+	esv1.IngestRole,
+	esv1.MLRole,
+	esv1.MasterRole,
+	esv1.RemoteClusterClientRole,
+	esv1.TransformRole,
+	esv1.VotingOnlyRole,
+}
+
+func validTransportCertificates(es esv1.Elasticsearch) field.ErrorList {
+	if es.Spec.Transport.TLS.UserDefinedCA() && es.Spec.Transport.TLS.UserDefinedNodeCertificate() {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("transport", "tls", "nodeCertificate"), transportCertsMsg)}
+	}
+	return nil
+}
+
+func validCertManager(es esv1.Elasticsearch) field.ErrorList {
+	if es.Spec.CertManager == nil {
+		return nil
+	}
+	if es.Spec.CertManager.IssuerRef.Name == "" || es.Spec.HTTP.TLS.Certificate.SecretName != "" ||
+		es.Spec.Transport.TLS.UserDefinedCA() || es.Spec.Transport.TLS.UserDefinedNodeCertificate() {
+		return field.ErrorList{field.Invalid(field.NewPath("spec").Child("certManager"), es.Spec.CertManager, certManagerMsg)}
+	}
+	return nil
+}
+
+func validDownscalePolicy(es esv1.Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	seen := make(map[esv1.NodeRole]struct{})
+	for i, role := range es.Spec.DownscalePolicy.TierOrder {
+		_, duplicate := seen[role]
+		if duplicate || !isKnownNodeRole(role) {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("downscalePolicy", "tierOrder").Index(i), role, invalidTierOrderMsg))
+		}
+		seen[role] = struct{}{}
+	}
+	return errs
+}
+
+func validPodDisruptionBudgetPerTier(es esv1.Elasticsearch) field.ErrorList {
+	template := es.Spec.PodDisruptionBudget
+	if es.Spec.PodDisruptionBudgetPerTier == nil || template == nil {
+		return nil
+	}
+	if template.IsDisabled() || template.Spec.Selector != nil || template.Spec.MaxUnavailable != nil || template.Spec.MinAvailable != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("podDisruptionBudgetPerTier"), pdbPerTierMsg)}
+	}
+	return nil
+}
+
+// maxRealmNameLength keeps the names of the volumes holding the files of the realms within the 63 characters limit,
+// the longest one being "elastic-internal-realm-<name>-idp-metadata".
+const maxRealmNameLength = 27
+
+// nativeRealmOrder is the order of the native realm configured by the operator, the file realm coming before it.
+const nativeRealmOrder = -99
+
+// validRealms checks that the realms have unique names usable in volume names, that they only reference the secrets
+// expected for their type, and that their orders are unique, including among the realms configured in the NodeSets.
+func validRealms(es esv1.Elasticsearch) field.ErrorList {
+	if len(es.Spec.Auth.Realms) == 0 {
+		return nil
+	}
+	path := field.NewPath("spec").Child("auth").Child("realms")
+	if v, err := version.Parse(es.Spec.Version); err == nil && v.Major < 7 {
+		return field.ErrorList{field.Forbidden(path, realmsVersionMsg)}
+	}
+	var errs field.ErrorList
+	names := make(map[string]struct{})
+	orders := nodeSetRealmOrders(es)
+	for i, realm := range es.Spec.Auth.Realms {
+		_, duplicate := names[realm.Name]
+		names[realm.Name] = struct{}{}
+		if duplicate || len(realm.Name) > maxRealmNameLength || len(k8svalidation.IsDNS1123Label(realm.Name)) > 0 ||
+			!validRealmSecrets(realm) {
+			errs = append(errs, field.Invalid(path.Index(i), realm.Name, invalidRealmMsg))
+		}
+		owner, duplicate := orders[realm.Order]
+		if (duplicate && owner != realm.SettingsPrefix()) || realm.Order <= nativeRealmOrder {
+			errs = append(errs, field.Invalid(path.Index(i).Child("order"), realm.Order, invalidRealmOrderMsg))
+		}
+		orders[realm.Order] = realm.SettingsPrefix()
+	}
+	return errs
+}
+
+// validRealmSecrets returns true if the given realm only references the secrets of its type, with non-empty keys.
+func validRealmSecrets(realm esv1.Realm) bool {
+	validSelector := func(selector *commonv1.SecretKeySelector) bool {
+		return selector == nil || (selector.SecretName != "" && selector.Key != "")
+	}
+	switch realm.Type {
+	case esv1.SAMLRealmType:
+		return realm.ClientSecret == nil && realm.JWKSet == nil && validSelector(realm.IdPMetadata)
+	case esv1.OIDCRealmType:
+		return realm.IdPMetadata == nil && realm.SigningSecretName == "" &&
+			validSelector(realm.ClientSecret) && validSelector(realm.JWKSet)
+	default:
+		return false
+	}
+}
+
+// nodeSetRealmOrders returns the orders of the realms configured in the NodeSets with the 7.x syntax, mapped to the
+// settings prefix of their realm.
+func nodeSetRealmOrders(es esv1.Elasticsearch) map[int]string {
+	orders := make(map[int]string)
+	for _, nodeSet := range es.Spec.NodeSets {
+		if nodeSet.Config == nil {
+			continue
+		}
+		cfg, err := commonsettings.NewCanonicalConfigFrom(nodeSet.Config.Data)
+		if err != nil {
+			// already reported by noUnknownFields
+			continue
+		}
+		var data map[string]interface{}
+		if err := cfg.Unpack(&data); err != nil {
+			continue
+		}
+		realmTypes, _ := nestedMap(data, "xpack", "security", "authc", "realms")
+		for realmType, realms := range realmTypes {
+			realmsOfType, isMap := realms.(map[string]interface{})
+			if !isMap {
+				continue
+			}
+			for name, realm := range realmsOfType {
+				realmSettings, isMap := realm.(map[string]interface{})
+				if !isMap {
+					continue
+				}
+				order, err := strconv.Atoi(fmt.Sprint(realmSettings["order"]))
+				if err != nil {
+					continue
+				}
+				orders[order] = fmt.Sprintf("xpack.security.authc.realms.%s.%s", realmType, name)
+			}
+		}
+	}
+	return orders
+}
+
+// nestedMap returns the map nested in the given one under the given keys.
+func nestedMap(data map[string]interface{}, keys ...string) (map[string]interface{}, bool) {
+	for _, key := range keys {
+		nested, isMap := data[key].(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		data = nested
+	}
+	return data, true
+}
+
+// validInitTasks checks the init tasks of the cluster, and the init task installing the plugins of each NodeSet.
+func validInitTasks(es esv1.Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	seen := make(map[string]struct{})
+	for i, task := range es.Spec.InitTasks {
+		path := field.NewPath("spec").Child("initTasks").Index(i)
+		_, duplicate := seen[task.Name]
+		seen[task.Name] = struct{}{}
+		if duplicate || task.Name == esv1.NodeSetPluginsInitTaskName {
+			errs = append(errs, field.Invalid(path, task.Name, invalidInitTaskMsg))
+		}
+		errs = append(errs, validInitTask(path, path.Child("installPlugins"), task)...)
+	}
+	for i, nodeSet := range es.Spec.NodeSets {
+		if len(nodeSet.Plugins) == 0 {
+			continue
+		}
+		path := field.NewPath("spec").Child("nodeSets").Index(i)
+		task := esv1.InitTask{Name: esv1.NodeSetPluginsInitTaskName, InstallPlugins: nodeSet.Plugins}
+		errs = append(errs, validInitTask(path, path.Child("plugins"), task)...)
+	}
+	return errs
+}
+
+func validInitTask(path, pluginsPath *field.Path, task esv1.InitTask) field.ErrorList {
+	var errs field.ErrorList
+	if (len(task.InstallPlugins) > 0) == (len(task.DownloadFiles) > 0) {
+		errs = append(errs, field.Invalid(path, task.Name, invalidInitTaskMsg))
+	}
+	for _, msg := range k8svalidation.IsDNS1123Label(initTaskContainerNamePrefix + task.Name) {
+		errs = append(errs, field.Invalid(path.Child("name"), task.Name, msg))
+	}
+	plugins := make(map[string]struct{})
+	for j, plugin := range task.InstallPlugins {
+		_, duplicate := plugins[plugin]
+		plugins[plugin] = struct{}{}
+		if strings.TrimSpace(plugin) == "" || duplicate {
+			errs = append(errs, field.Invalid(pluginsPath.Index(j), plugin, invalidInitTaskMsg))
+		}
+	}
+	for j, file := range task.DownloadFiles {
+		validURL := strings.HasPrefix(file.URL, "http://") || strings.HasPrefix(file.URL, "https://")
+		cleanPath := filepath.Clean(file.Path)
+		if !validURL || file.Path == "" || filepath.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			errs = append(errs, field.Invalid(path.Child("downloadFiles").Index(j), file, invalidInitTaskFileMsg))
+		}
+	}
+	return errs
+}
+
+// validRemoteClusterRefs checks that the remote clusters reference Elasticsearch clusters managed by the operator.
+func validRemoteClusterRefs(es esv1.Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	for i, remoteCluster := range es.Spec.RemoteClusters {
+		path := field.NewPath("spec").Child("remoteClusters").Index(i).Child("elasticsearchRef")
+		errs = append(errs, commonv1.CheckManagedRefs(path, remoteCluster.ElasticsearchRef)...)
+	}
+	return errs
+}
+
+// validCrossClusterReplication checks that the follower indices and auto-follow patterns of the remote clusters have
+// unique names across all the remote clusters, and that they are declared for remote clusters referencing an
+// Elasticsearch cluster.
+func validCrossClusterReplication(es esv1.Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	followerIndices := make(map[string]struct{})
+	autoFollowPatterns := make(map[string]struct{})
+	for i, remoteCluster := range es.Spec.RemoteClusters {
+		path := field.NewPath("spec").Child("remoteClusters").Index(i)
+		for j, index := range remoteCluster.FollowerIndices {
+			_, duplicate := followerIndices[index.Name]
+			followerIndices[index.Name] = struct{}{}
+			if index.Name == "" || duplicate || !remoteCluster.ElasticsearchRef.IsDefined() {
+				errs = append(errs, field.Invalid(path.Child("followerIndices").Index(j), index.Name, invalidCCRMsg))
+			}
+		}
+		for j, pattern := range remoteCluster.AutoFollowPatterns {
+			_, duplicate := autoFollowPatterns[pattern.Name]
+			autoFollowPatterns[pattern.Name] = struct{}{}
+			if pattern.Name == "" || duplicate || !remoteCluster.ElasticsearchRef.IsDefined() {
+				errs = append(errs, field.Invalid(path.Child("autoFollowPatterns").Index(j), pattern.Name, invalidCCRMsg))
+			}
+		}
+	}
+	return errs
+}
+
+// validRemoteClusterAPIKeys checks that the cross-cluster API keys and the remote cluster server are supported by the
+// version of Elasticsearch, and that the API keys grant some access to a referenced cluster.
+func validRemoteClusterAPIKeys(es esv1.Elasticsearch) field.ErrorList {
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		// already reported by supportedVersion
+		return nil
+	}
+	supported := v.GTE(version.From(8, 10, 0))
+	var errs field.ErrorList
+	if es.Spec.RemoteClusterServer.Enabled && !supported {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("remoteClusterServer"), es.Spec.RemoteClusterServer, remoteClusterVersionMsg))
+	}
+	for i, remoteCluster := range es.Spec.RemoteClusters {
+		if remoteCluster.APIKey == nil {
+			continue
+		}
+		path := field.NewPath("spec").Child("remoteClusters").Index(i).Child("apiKey")
+		if !supported {
+			errs = append(errs, field.Invalid(path, remoteCluster.APIKey, remoteClusterVersionMsg))
+			continue
+		}
+		access := remoteCluster.APIKey.Access
+		if !remoteCluster.ElasticsearchRef.IsDefined() ||
+			(!hasIndexNames(access.Search) && !hasIndexNames(access.Replication)) {
+			errs = append(errs, field.Invalid(path, remoteCluster.APIKey, invalidAPIKeyAccessMsg))
+		}
+	}
+	return errs
+}
+
+func hasIndexNames(indices *esv1.RemoteClusterIndices) bool {
+	return indices != nil && len(indices.Names) > 0
+}
+
+// dataTierAgeRegexp matches the ages accepted in ILM policies, such as 30d or 12h.
+var dataTierAgeRegexp = regexp.MustCompile(`^[0-9]+(d|h|m|s|ms|micros|nanos)$`)
+
+// validDataTiers checks that the data tiers are supported by the version of Elasticsearch, that they reference
+// existing NodeSets whose roles can be replaced, and that their ILM policy can be published.
+func validDataTiers(es esv1.Elasticsearch) field.ErrorList {
+	tiers := es.Spec.DataTiers
+	if tiers == nil {
+		return nil
+	}
+	path := field.NewPath("spec").Child("dataTiers")
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		// already reported by supportedVersion
+		return nil
+	}
+	if !v.GTE(version.From(7, 10, 0)) {
+		return field.ErrorList{field.Invalid(path, tiers, dataTiersVersionMsg)}
+	}
+
+	var errs field.ErrorList
+	if es.IsAutoscalingDefined() {
+		errs = append(errs, field.Forbidden(path, dataTiersAutoscalingMsg))
+	}
+	if tiers.Frozen != nil && !v.GTE(version.From(7, 12, 0)) {
+		errs = append(errs, field.Invalid(path.Child("frozen"), tiers.Frozen, dataTiersVersionMsg))
+	}
+
+	nodeSets := make(map[string]esv1.NodeSet, len(es.Spec.NodeSets))
+	for _, nodeSet := range es.Spec.NodeSets {
+		nodeSets[nodeSet.Name] = nodeSet
+	}
+	for _, tier := range []struct {
+		name string
+		tier *esv1.DataTier
+	}{{"hot", tiers.Hot}, {"warm", tiers.Warm}, {"cold", tiers.Cold}, {"frozen", tiers.Frozen}} {
+		if tier.tier == nil {
+			continue
+		}
+		tierPath := path.Child(tier.name)
+		if len(tier.tier.NodeSets) == 0 {
+			errs = append(errs, field.Required(tierPath.Child("nodeSets"), invalidDataTierMsg))
+		}
+		for i, name := range tier.tier.NodeSets {
+			nodeSet, exists := nodeSets[name]
+			if !exists {
+				errs = append(errs, field.Invalid(tierPath.Child("nodeSets").Index(i), name, invalidDataTierMsg))
+				continue
+			}
+			cfg := esv1.ElasticsearchSettings{}
+			if err := esv1.UnpackConfig(nodeSet.Config, v, &cfg); err != nil {
+				// already reported by hasCorrectNodeRoles
+				continue
+			}
+			if len(getNodeRoleAttrs(cfg)) > 0 {
+				errs = append(errs, field.Invalid(tierPath.Child("nodeSets").Index(i), name, invalidDataTierMsg))
+			}
+		}
+		if tier.tier.MinAge != "" && !dataTierAgeRegexp.MatchString(tier.tier.MinAge) {
+			errs = append(errs, field.Invalid(tierPath.Child("minAge"), tier.tier.MinAge, invalidDataTierAgeMsg))
+		}
+	}
+
+	if policy := tiers.ILMPolicy; policy != nil {
+		policyPath := path.Child("ilmPolicy")
+		if policy.Name == "" {
+			errs = append(errs, field.Required(policyPath.Child("name"), invalidDataTierILMMsg))
+		}
+		if tiers.Frozen != nil && policy.SnapshotRepository == "" {
+			errs = append(errs, field.Required(policyPath.Child("snapshotRepository"), invalidDataTierILMMsg))
+		}
+		if policy.DeleteAfter != "" && !dataTierAgeRegexp.MatchString(policy.DeleteAfter) {
+			errs = append(errs, field.Invalid(policyPath.Child("deleteAfter"), policy.DeleteAfter, invalidDataTierAgeMsg))
+		}
+	}
+	return errs
+}
+
+// validReadinessProbes checks that the customized readiness probes still report the health of the local node.
+func validReadinessProbes(es esv1.Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	for i, nodeSet := range es.Spec.NodeSets {
+		probe := nodeSet.ReadinessProbe
+		if probe == nil {
+			continue
+		}
+		path := field.NewPath("spec").Child("nodeSets").Index(i).Child("readinessProbe")
+		if container := nodeSet.GetESContainerTemplate(); container != nil && container.ReadinessProbe != nil {
+			errs = append(errs, field.Forbidden(path, duplicateProbeMsg))
+		}
+		if probe.Port != nil && *probe.Port == network.TransportPort {
+			errs = append(errs, field.Invalid(path.Child("port"), *probe.Port, invalidReadinessProbeMsg))
+		}
+		if probe.Path != "" && !isLocalNodePath(probe.Path) {
+			errs = append(errs, field.Invalid(path.Child("path"), probe.Path, invalidReadinessProbeMsg))
+		}
+		if probe.Script != nil {
+			if probe.Script.Name == "" {
+				errs = append(errs, field.Required(path.Child("script", "name"), "ConfigMap name is required"))
+			}
+			if msgs := k8svalidation.IsConfigMapKey(probe.Script.Key); len(msgs) > 0 {
+				errs = append(errs, field.Invalid(path.Child("script", "key"), probe.Script.Key, strings.Join(msgs, ", ")))
+			}
+		}
+	}
+	return errs
+}
+
+// isLocalNodePath returns true if the given HTTP path is only served from the state of the requested node.
+func isLocalNodePath(p string) bool {
+	u, err := url.Parse(p)
+	if err != nil || !strings.HasPrefix(u.Path, "/") {
+		return false
+	}
+	return u.Path == "/" ||
+		u.Path == "/_nodes/_local" || strings.HasPrefix(u.Path, "/_nodes/_local/") ||
+		u.Query().Get("local") == "true"
+}
+
+func isKnownNodeRole(role esv1.NodeRole) bool {
+	for _, known := range knownNodeRoles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validJVMHeap checks that the heap size set by the user does not exceed half of the declared memory limit of the
+// Elasticsearch container, when the heap size is derived from the memory limits.
+func validJVMHeap(es esv1.Elasticsearch) field.ErrorList {
+	if !es.IsJVMHeapFromMemoryLimits() {
+		return nil
+	}
+	var errs field.ErrorList
+	for i, nodeSet := range es.Spec.NodeSets {
+		for j, c := range nodeSet.PodTemplate.Spec.Containers {
+			if c.Name != esv1.ElasticsearchContainerName {
+				continue
+			}
+			memoryLimit, exists := c.Resources.Limits[corev1.ResourceMemory]
+			if !exists || memoryLimit.IsZero() {
+				continue
+			}
+			for k, envVar := range c.Env {
+				if envVar.Name != esJavaOptsEnvVar {
+					continue
+				}
+				path := field.NewPath("spec").Child("nodeSets").Index(i).Child("podTemplate", "spec", "containers").Index(j).Child("env").Index(k)
+				heap, err := jvm.MaxHeapSize(envVar.Value)
+				if err != nil {
+					errs = append(errs, field.Invalid(path, envVar.Value, err.Error()))
+					continue
+				}
+				if heap != nil && heap.Value()*2 > memoryLimit.Value() {
+					errs = append(errs, field.Invalid(path, envVar.Value, jvmHeapTooLargeMsg))
+				}
+			}
+		}
+	}
+	return errs
+}
+
 func checkNodeSetNameUniqueness(es esv1.Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
 	nodeSets := es.Spec.NodeSets
@@ -298,6 +794,15 @@ func validUpgradePath(current, proposed esv1.Elasticsearch) field.ErrorList {
 	return errs
 }
 
+// noInitialSnapshotRestoreChange prevents restoring a snapshot into an existing cluster, which may already hold data.
+func noInitialSnapshotRestoreChange(current, proposed esv1.Elasticsearch) field.ErrorList {
+	if proposed.Spec.InitialSnapshotRestore == nil ||
+		reflect.DeepEqual(current.Spec.InitialSnapshotRestore, proposed.Spec.InitialSnapshotRestore) {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("initialSnapshotRestore"), snapshotRestoreChangeMsg)}
+}
+
 func validMonitoring(es esv1.Elasticsearch) field.ErrorList {
 	return stackmon.Validate(&es, es.Spec.Version)
 }
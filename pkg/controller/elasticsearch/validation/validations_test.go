@@ -5,15 +5,20 @@
 package validation
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 )
 
 func Test_checkNodeSetNameUniqueness(t *testing.T) {
@@ -242,6 +247,646 @@ func Test_validName(t *testing.T) {
 	}
 }
 
+func Test_validDownscalePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		tierOrder  []esv1.NodeRole
+		wantErrors int
+	}{
+		{
+			name: "no tier order",
+		},
+		{
+			name:      "valid tier order",
+			tierOrder: []esv1.NodeRole{esv1.DataColdRole, esv1.DataWarmRole, esv1.DataHotRole},
+		},
+		{
+			name:       "unknown and duplicate roles",
+			tierOrder:  []esv1.NodeRole{esv1.DataColdRole, "data_lukewarm", esv1.DataColdRole},
+			wantErrors: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{DownscalePolicy: esv1.DownscalePolicy{TierOrder: tt.tierOrder}}}
+			assert.Len(t, validDownscalePolicy(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validTransportCertificates(t *testing.T) {
+	tests := []struct {
+		name       string
+		tls        esv1.TransportTLSOptions
+		wantErrors int
+	}{
+		{
+			name: "operator issued certificates",
+		},
+		{
+			name: "custom CA",
+			tls:  esv1.TransportTLSOptions{Certificate: commonv1.SecretRef{SecretName: "ca"}},
+		},
+		{
+			name: "custom node certificate",
+			tls:  esv1.TransportTLSOptions{NodeCertificate: commonv1.SecretRef{SecretName: "cert"}},
+		},
+		{
+			name: "both custom CA and node certificate",
+			tls: esv1.TransportTLSOptions{
+				Certificate:     commonv1.SecretRef{SecretName: "ca"},
+				NodeCertificate: commonv1.SecretRef{SecretName: "cert"},
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{Transport: esv1.TransportConfig{TLS: tt.tls}}}
+			assert.Len(t, validTransportCertificates(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validCertManager(t *testing.T) {
+	issuerRef := esv1.CertManagerIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"}
+	tests := []struct {
+		name       string
+		spec       esv1.ElasticsearchSpec
+		wantErrors int
+	}{
+		{
+			name: "no cert-manager",
+			spec: esv1.ElasticsearchSpec{HTTP: commonv1.HTTPConfig{TLS: commonv1.TLSOptions{Certificate: commonv1.SecretRef{SecretName: "cert"}}}},
+		},
+		{
+			name: "cert-manager",
+			spec: esv1.ElasticsearchSpec{CertManager: &esv1.CertManagerConfig{IssuerRef: issuerRef}},
+		},
+		{
+			name:       "no issuer name",
+			spec:       esv1.ElasticsearchSpec{CertManager: &esv1.CertManagerConfig{}},
+			wantErrors: 1,
+		},
+		{
+			name: "user-provided HTTP certificate",
+			spec: esv1.ElasticsearchSpec{
+				CertManager: &esv1.CertManagerConfig{IssuerRef: issuerRef},
+				HTTP:        commonv1.HTTPConfig{TLS: commonv1.TLSOptions{Certificate: commonv1.SecretRef{SecretName: "cert"}}},
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "user-provided transport CA",
+			spec: esv1.ElasticsearchSpec{
+				CertManager: &esv1.CertManagerConfig{IssuerRef: issuerRef},
+				Transport:   esv1.TransportConfig{TLS: esv1.TransportTLSOptions{Certificate: commonv1.SecretRef{SecretName: "ca"}}},
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, validCertManager(esv1.Elasticsearch{Spec: tt.spec}), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validPodDisruptionBudgetPerTier(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   *commonv1.PodDisruptionBudgetTemplate
+		wantErrors int
+	}{
+		{
+			name: "default pod disruption budget",
+		},
+		{
+			name:     "pod disruption budget metadata",
+			template: &commonv1.PodDisruptionBudgetTemplate{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "b"}}},
+		},
+		{
+			name:       "disabled pod disruption budget",
+			template:   &commonv1.PodDisruptionBudgetTemplate{},
+			wantErrors: 1,
+		},
+		{
+			name: "user-defined pod disruption budget",
+			template: &commonv1.PodDisruptionBudgetTemplate{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "b"}},
+				Spec:       policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &intstr.IntOrString{IntVal: 2}},
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{
+				PodDisruptionBudget:        tt.template,
+				PodDisruptionBudgetPerTier: &esv1.PodDisruptionBudgetPerTier{},
+			}}
+			assert.Len(t, validPodDisruptionBudgetPerTier(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validRealms(t *testing.T) {
+	secretKey := &commonv1.SecretKeySelector{SecretName: "idp", Key: "metadata.xml"}
+	tests := []struct {
+		name       string
+		version    string
+		realms     []esv1.Realm
+		nodeSets   []esv1.NodeSet
+		wantErrors int
+	}{
+		{
+			name: "no realms",
+		},
+		{
+			name: "valid realms",
+			realms: []esv1.Realm{
+				{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2, IdPMetadata: secretKey, SigningSecretName: "saml-signing"},
+				{Type: esv1.OIDCRealmType, Name: "oidc1", Order: 3, ClientSecret: secretKey, JWKSet: secretKey},
+			},
+		},
+		{
+			name:       "realms require Elasticsearch 7",
+			version:    "6.8.0",
+			realms:     []esv1.Realm{{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2}},
+			wantErrors: 1,
+		},
+		{
+			name: "duplicate and invalid names",
+			realms: []esv1.Realm{
+				{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2},
+				{Type: esv1.OIDCRealmType, Name: "saml1", Order: 3},
+				{Type: esv1.OIDCRealmType, Name: "Invalid_Name", Order: 4},
+				{Type: esv1.OIDCRealmType, Name: "a-realm-name-longer-than-thirty-characters", Order: 5},
+			},
+			wantErrors: 3,
+		},
+		{
+			name: "names at the maximum length",
+			realms: []esv1.Realm{
+				{Type: esv1.SAMLRealmType, Name: strings.Repeat("s", maxRealmNameLength), Order: 2, IdPMetadata: secretKey},
+				{Type: esv1.OIDCRealmType, Name: strings.Repeat("o", maxRealmNameLength+1), Order: 3, JWKSet: secretKey},
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "secrets of another realm type",
+			realms: []esv1.Realm{
+				{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2, ClientSecret: secretKey},
+				{Type: esv1.OIDCRealmType, Name: "oidc1", Order: 3, SigningSecretName: "saml-signing"},
+				{Type: esv1.OIDCRealmType, Name: "oidc2", Order: 4, JWKSet: &commonv1.SecretKeySelector{SecretName: "jwks"}},
+			},
+			wantErrors: 3,
+		},
+		{
+			name: "duplicate orders and orders before the operator realms",
+			realms: []esv1.Realm{
+				{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2},
+				{Type: esv1.OIDCRealmType, Name: "oidc1", Order: 2},
+				{Type: esv1.OIDCRealmType, Name: "oidc2", Order: -100},
+			},
+			wantErrors: 2,
+		},
+		{
+			name:   "order already used by a realm configured in a NodeSet",
+			realms: []esv1.Realm{{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2}},
+			nodeSets: []esv1.NodeSet{{Name: "default", Config: &commonv1.Config{Data: map[string]interface{}{
+				"xpack.security.authc.realms.ldap.ldap1.order": 2,
+			}}}},
+			wantErrors: 1,
+		},
+		{
+			name:   "realm settings overridden in a NodeSet",
+			realms: []esv1.Realm{{Type: esv1.SAMLRealmType, Name: "saml1", Order: 2}},
+			nodeSets: []esv1.NodeSet{{Name: "default", Config: &commonv1.Config{Data: map[string]interface{}{
+				"xpack.security.authc.realms.saml.saml1.order": 2,
+			}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ver := tt.version
+			if ver == "" {
+				ver = "8.1.0"
+			}
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{
+				Version:  ver,
+				Auth:     esv1.Auth{Realms: tt.realms},
+				NodeSets: tt.nodeSets,
+			}}
+			assert.Len(t, validRealms(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validRemoteClusterRefs(t *testing.T) {
+	tests := []struct {
+		name           string
+		remoteClusters []esv1.RemoteCluster
+		wantErr        string
+	}{
+		{
+			name:           "reference by name",
+			remoteClusters: []esv1.RemoteCluster{{Name: "leader", ElasticsearchRef: commonv1.ObjectSelector{Name: "leader", Namespace: "ns"}}},
+		},
+		{
+			name: "reference to a cluster not managed by the operator",
+			remoteClusters: []esv1.RemoteCluster{
+				{Name: "leader", ElasticsearchRef: commonv1.ObjectSelector{Name: "leader"}},
+				{Name: "external", ElasticsearchRef: commonv1.ObjectSelector{SecretName: "external"}},
+			},
+			wantErr: "spec.remoteClusters[1].elasticsearchRef.secretName: Forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{RemoteClusters: tt.remoteClusters}}
+			errs := validRemoteClusterRefs(es)
+			if tt.wantErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			assert.Len(t, errs, 1)
+			assert.Contains(t, errs.ToAggregate().Error(), tt.wantErr)
+		})
+	}
+}
+
+func Test_validCrossClusterReplication(t *testing.T) {
+	leader := commonv1.ObjectSelector{Name: "leader"}
+	tests := []struct {
+		name           string
+		remoteClusters []esv1.RemoteCluster
+		wantErrors     int
+	}{
+		{
+			name:           "no cross-cluster replication",
+			remoteClusters: []esv1.RemoteCluster{{Name: "leader", ElasticsearchRef: leader}},
+		},
+		{
+			name: "valid follower indices and auto-follow patterns",
+			remoteClusters: []esv1.RemoteCluster{
+				{
+					Name:               "leader",
+					ElasticsearchRef:   leader,
+					FollowerIndices:    []esv1.FollowerIndex{{Name: "logs"}, {Name: "metrics", LeaderIndex: "metrics-1"}},
+					AutoFollowPatterns: []esv1.AutoFollowPattern{{Name: "logs", LeaderIndexPatterns: []string{"logs-*"}}},
+				},
+				{
+					Name:               "other-leader",
+					ElasticsearchRef:   commonv1.ObjectSelector{Name: "other-leader"},
+					AutoFollowPatterns: []esv1.AutoFollowPattern{{Name: "traces", LeaderIndexPatterns: []string{"traces-*"}}},
+				},
+			},
+		},
+		{
+			name: "duplicate names across remote clusters",
+			remoteClusters: []esv1.RemoteCluster{
+				{
+					Name:               "leader",
+					ElasticsearchRef:   leader,
+					FollowerIndices:    []esv1.FollowerIndex{{Name: "logs"}},
+					AutoFollowPatterns: []esv1.AutoFollowPattern{{Name: "logs", LeaderIndexPatterns: []string{"logs-*"}}},
+				},
+				{
+					Name:               "other-leader",
+					ElasticsearchRef:   commonv1.ObjectSelector{Name: "other-leader"},
+					FollowerIndices:    []esv1.FollowerIndex{{Name: "logs"}},
+					AutoFollowPatterns: []esv1.AutoFollowPattern{{Name: "logs", LeaderIndexPatterns: []string{"logs-*"}}},
+				},
+			},
+			wantErrors: 2,
+		},
+		{
+			name: "remote cluster without Elasticsearch reference",
+			remoteClusters: []esv1.RemoteCluster{
+				{Name: "leader", FollowerIndices: []esv1.FollowerIndex{{Name: "logs"}}},
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{RemoteClusters: tt.remoteClusters}}
+			assert.Len(t, validCrossClusterReplication(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validRemoteClusterAPIKeys(t *testing.T) {
+	searchLogs := &esv1.RemoteClusterAPIKey{Access: esv1.RemoteClusterAccess{
+		Search: &esv1.RemoteClusterIndices{Names: []string{"logs-*"}},
+	}}
+	tests := []struct {
+		name       string
+		version    string
+		spec       esv1.ElasticsearchSpec
+		wantErrors int
+	}{
+		{
+			name:    "no API key",
+			version: "8.9.0",
+			spec: esv1.ElasticsearchSpec{
+				RemoteClusters: []esv1.RemoteCluster{{Name: "remote", ElasticsearchRef: commonv1.ObjectSelector{Name: "remote"}}},
+			},
+		},
+		{
+			name:    "valid API key and remote cluster server",
+			version: "8.10.0",
+			spec: esv1.ElasticsearchSpec{
+				RemoteClusterServer: esv1.RemoteClusterServer{Enabled: true},
+				RemoteClusters: []esv1.RemoteCluster{
+					{Name: "remote", ElasticsearchRef: commonv1.ObjectSelector{Name: "remote"}, APIKey: searchLogs},
+				},
+			},
+		},
+		{
+			name:    "API key and remote cluster server before 8.10",
+			version: "8.9.0",
+			spec: esv1.ElasticsearchSpec{
+				RemoteClusterServer: esv1.RemoteClusterServer{Enabled: true},
+				RemoteClusters: []esv1.RemoteCluster{
+					{Name: "remote", ElasticsearchRef: commonv1.ObjectSelector{Name: "remote"}, APIKey: searchLogs},
+				},
+			},
+			wantErrors: 2,
+		},
+		{
+			name:    "API key without access or Elasticsearch reference",
+			version: "8.10.0",
+			spec: esv1.ElasticsearchSpec{
+				RemoteClusters: []esv1.RemoteCluster{
+					{Name: "remote", ElasticsearchRef: commonv1.ObjectSelector{Name: "remote"}, APIKey: &esv1.RemoteClusterAPIKey{}},
+					{Name: "other", APIKey: searchLogs},
+				},
+			},
+			wantErrors: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Version = tt.version
+			assert.Len(t, validRemoteClusterAPIKeys(esv1.Elasticsearch{Spec: tt.spec}), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validDataTiers(t *testing.T) {
+	legacyRoles := &commonv1.Config{Data: map[string]interface{}{esv1.NodeData: true}}
+	tests := []struct {
+		name       string
+		version    string
+		nodeSets   []esv1.NodeSet
+		tiers      *esv1.DataTiers
+		wantErrors int
+	}{
+		{
+			name:     "no data tiers",
+			version:  "7.15.0",
+			nodeSets: []esv1.NodeSet{{Name: "default"}},
+		},
+		{
+			name:     "valid data tiers",
+			version:  "7.15.0",
+			nodeSets: []esv1.NodeSet{{Name: "hot"}, {Name: "warm"}, {Name: "frozen"}},
+			tiers: &esv1.DataTiers{
+				Hot:       &esv1.DataTier{NodeSets: []string{"hot"}},
+				Warm:      &esv1.DataTier{NodeSets: []string{"warm"}, MinAge: "7d"},
+				Frozen:    &esv1.DataTier{NodeSets: []string{"frozen"}, MinAge: "90d"},
+				ILMPolicy: &esv1.DataTiersILMPolicy{Name: "tiers", SnapshotRepository: "repo", DeleteAfter: "365d"},
+			},
+		},
+		{
+			name:       "version without data tiers",
+			version:    "7.9.0",
+			nodeSets:   []esv1.NodeSet{{Name: "hot"}},
+			tiers:      &esv1.DataTiers{Hot: &esv1.DataTier{NodeSets: []string{"hot"}}},
+			wantErrors: 1,
+		},
+		{
+			name:       "version without the frozen tier",
+			version:    "7.11.0",
+			nodeSets:   []esv1.NodeSet{{Name: "frozen"}},
+			tiers:      &esv1.DataTiers{Frozen: &esv1.DataTier{NodeSets: []string{"frozen"}}},
+			wantErrors: 1,
+		},
+		{
+			name:     "empty, unknown and legacy role NodeSets",
+			version:  "7.15.0",
+			nodeSets: []esv1.NodeSet{{Name: "hot", Config: legacyRoles}},
+			tiers: &esv1.DataTiers{
+				Hot:  &esv1.DataTier{NodeSets: []string{"hot", "unknown"}},
+				Warm: &esv1.DataTier{},
+			},
+			wantErrors: 3,
+		},
+		{
+			name:     "invalid ages and ILM policy",
+			version:  "7.15.0",
+			nodeSets: []esv1.NodeSet{{Name: "warm"}, {Name: "frozen"}},
+			tiers: &esv1.DataTiers{
+				Warm:      &esv1.DataTier{NodeSets: []string{"warm"}, MinAge: "one week"},
+				Frozen:    &esv1.DataTier{NodeSets: []string{"frozen"}},
+				ILMPolicy: &esv1.DataTiersILMPolicy{DeleteAfter: "1y"},
+			},
+			wantErrors: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{Version: tt.version, NodeSets: tt.nodeSets, DataTiers: tt.tiers}}
+			assert.Len(t, validDataTiers(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validInitTasks(t *testing.T) {
+	tests := []struct {
+		name       string
+		tasks      []esv1.InitTask
+		nodeSets   []esv1.NodeSet
+		wantErrors int
+	}{
+		{
+			name: "no tasks",
+		},
+		{
+			name: "valid tasks",
+			tasks: []esv1.InitTask{
+				{Name: "plugins", InstallPlugins: []string{"analysis-icu"}},
+				{Name: "synonyms", DownloadFiles: []esv1.FileDownload{{URL: "https://example.com/synonyms.txt", Path: "analysis/synonyms.txt"}}},
+			},
+		},
+		{
+			name: "duplicate names and invalid container name",
+			tasks: []esv1.InitTask{
+				{Name: "plugins", InstallPlugins: []string{"analysis-icu"}},
+				{Name: "plugins", InstallPlugins: []string{"analysis-kuromoji"}},
+				{Name: "Synonyms", DownloadFiles: []esv1.FileDownload{{URL: "https://example.com/synonyms.txt", Path: "synonyms.txt"}}},
+			},
+			wantErrors: 2,
+		},
+		{
+			name: "no or several task types",
+			tasks: []esv1.InitTask{
+				{Name: "empty"},
+				{Name: "both", InstallPlugins: []string{"analysis-icu"}, DownloadFiles: []esv1.FileDownload{{URL: "https://example.com/synonyms.txt", Path: "synonyms.txt"}}},
+			},
+			wantErrors: 2,
+		},
+		{
+			name: "invalid files",
+			tasks: []esv1.InitTask{
+				{Name: "files", DownloadFiles: []esv1.FileDownload{
+					{URL: "file:///etc/passwd", Path: "passwd"},
+					{URL: "https://example.com/synonyms.txt", Path: "/etc/synonyms.txt"},
+					{URL: "https://example.com/synonyms.txt", Path: "analysis/../../synonyms.txt"},
+				}},
+			},
+			wantErrors: 3,
+		},
+		{
+			name:       "reserved name",
+			tasks:      []esv1.InitTask{{Name: esv1.NodeSetPluginsInitTaskName, InstallPlugins: []string{"analysis-icu"}}},
+			wantErrors: 1,
+		},
+		{
+			name:  "valid NodeSet plugins, also installed by an init task",
+			tasks: []esv1.InitTask{{Name: "plugins", InstallPlugins: []string{"repository-s3"}}},
+			nodeSets: []esv1.NodeSet{
+				{Name: "hot", Plugins: []string{"analysis-icu", "repository-s3"}},
+				{Name: "warm", Plugins: []string{"analysis-icu"}},
+			},
+		},
+		{
+			name:  "empty and duplicate plugins",
+			tasks: []esv1.InitTask{{Name: "plugins", InstallPlugins: []string{"repository-s3", "repository-s3"}}},
+			nodeSets: []esv1.NodeSet{
+				{Name: "default", Plugins: []string{"analysis-icu", " ", "analysis-icu"}},
+			},
+			wantErrors: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{InitTasks: tt.tasks, NodeSets: tt.nodeSets}}
+			assert.Len(t, validInitTasks(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validReadinessProbes(t *testing.T) {
+	tests := []struct {
+		name        string
+		probe       *esv1.ReadinessProbe
+		podTemplate corev1.PodTemplateSpec
+		wantErrors  int
+	}{
+		{
+			name: "no custom probe",
+		},
+		{
+			name: "valid custom probe",
+			probe: &esv1.ReadinessProbe{
+				Port:   pointer.Int32(9201),
+				Path:   "/_cluster/health?local=true",
+				Script: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "probes"}, Key: "probe.sh"},
+			},
+		},
+		{
+			name:  "local node path",
+			probe: &esv1.ReadinessProbe{Path: "/_nodes/_local/stats"},
+		},
+		{
+			name:       "path depending on other nodes",
+			probe:      &esv1.ReadinessProbe{Path: "/_cluster/health"},
+			wantErrors: 1,
+		},
+		{
+			name:       "transport port",
+			probe:      &esv1.ReadinessProbe{Port: pointer.Int32(9300)},
+			wantErrors: 1,
+		},
+		{
+			name: "invalid script reference",
+			probe: &esv1.ReadinessProbe{
+				Script: &corev1.ConfigMapKeySelector{Key: "../probe.sh"},
+			},
+			wantErrors: 2,
+		},
+		{
+			name:  "probe also set in the Pod template",
+			probe: &esv1.ReadinessProbe{Path: "/"},
+			podTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: esv1.ElasticsearchContainerName, ReadinessProbe: &corev1.Probe{}},
+			}}},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{
+				{Name: "default", ReadinessProbe: tt.probe, PodTemplate: tt.podTemplate},
+			}}}
+			assert.Len(t, validReadinessProbes(es), tt.wantErrors)
+		})
+	}
+}
+
+func Test_validJVMHeap(t *testing.T) {
+	esWithHeap := func(annotated bool, memoryLimit string, javaOpts string) esv1.Elasticsearch {
+		es := esv1.Elasticsearch{
+			Spec: esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{{
+				PodTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: esv1.ElasticsearchContainerName,
+					Env:  []corev1.EnvVar{{Name: "ES_JAVA_OPTS", Value: javaOpts}},
+				}}}},
+			}}},
+		}
+		if annotated {
+			es.Annotations = map[string]string{esv1.JVMHeapFromMemoryLimitsAnnotation: "true"}
+		}
+		if memoryLimit != "" {
+			es.Spec.NodeSets[0].PodTemplate.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(memoryLimit),
+			}
+		}
+		return es
+	}
+	tests := []struct {
+		name       string
+		es         esv1.Elasticsearch
+		wantErrors int
+	}{
+		{
+			name: "heap above half of the limit without the annotation",
+			es:   esWithHeap(false, "4Gi", "-Xms3g -Xmx3g"),
+		},
+		{
+			name: "heap at half of the limit",
+			es:   esWithHeap(true, "4Gi", "-Xms2g -Xmx2g"),
+		},
+		{
+			name: "no declared memory limit",
+			es:   esWithHeap(true, "", "-Xms3g -Xmx3g"),
+		},
+		{
+			name:       "heap above half of the limit",
+			es:         esWithHeap(true, "4Gi", "-Xms2100m -Xmx2100m"),
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, validJVMHeap(tt.es), tt.wantErrors)
+		})
+	}
+}
+
 func Test_validSanIP(t *testing.T) {
 	validIP := "3.4.5.6"
 	validIP2 := "192.168.12.13"
@@ -350,6 +995,57 @@ func TestValidation_noDowngrades(t *testing.T) {
 	}
 }
 
+func Test_noInitialSnapshotRestoreChange(t *testing.T) {
+	withRestore := func(snapshot string) esv1.Elasticsearch {
+		cluster := es("7.15.0")
+		cluster.Spec.InitialSnapshotRestore = &esv1.InitialSnapshotRestore{
+			Repository: esv1.SnapshotRestoreRepository{Name: "repo", Type: "fs"},
+			Snapshot:   snapshot,
+		}
+		return cluster
+	}
+	tests := []struct {
+		name         string
+		current      esv1.Elasticsearch
+		proposed     esv1.Elasticsearch
+		expectErrors bool
+	}{
+		{
+			name:     "no restore",
+			current:  es("7.15.0"),
+			proposed: es("7.15.0"),
+		},
+		{
+			name:     "unchanged restore",
+			current:  withRestore("snap-1"),
+			proposed: withRestore("snap-1"),
+		},
+		{
+			name:     "removed restore",
+			current:  withRestore("snap-1"),
+			proposed: es("7.15.0"),
+		},
+		{
+			name:         "added restore",
+			current:      es("7.15.0"),
+			proposed:     withRestore("snap-1"),
+			expectErrors: true,
+		},
+		{
+			name:         "changed restore",
+			current:      withRestore("snap-1"),
+			proposed:     withRestore("snap-2"),
+			expectErrors: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := noInitialSnapshotRestoreChange(tt.current, tt.proposed)
+			assert.Equal(t, tt.expectErrors, len(actual) > 0, actual)
+		})
+	}
+}
+
 func Test_validUpgradePath(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -47,26 +47,44 @@ func canClearVotingConfigExclusions(c k8s.Client, actualStatefulSets sset.Statef
 }
 
 // ClearVotingConfigExclusions resets the voting config exclusions if all excluded nodes are properly removed.
-// It returns true if this should be retried later (re-queued).
-func ClearVotingConfigExclusions(ctx context.Context, es esv1.Elasticsearch, c k8s.Client, esClient client.Client, actualStatefulSets sset.StatefulSetList) (bool, error) {
+// Exclusions left over by a previous reconciliation, for example if the operator restarted in the middle of a
+// downscale, are cleared the same way. It returns the names of the nodes still excluded from voting, and true if this
+// should be retried later (re-queued).
+func ClearVotingConfigExclusions(ctx context.Context, es esv1.Elasticsearch, c k8s.Client, esClient client.Client, actualStatefulSets sset.StatefulSetList) ([]string, bool, error) {
 	compatible, err := AllMastersCompatibleWithZen2(c, es)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 	if !compatible {
 		// nothing to do
-		return false, nil
+		return nil, false, nil
+	}
+
+	exclusions, err := esClient.GetVotingConfigExclusions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(exclusions) == 0 {
+		// nothing to clear
+		return nil, false, nil
+	}
+	excludedNodes := make([]string, 0, len(exclusions))
+	for _, exclusion := range exclusions {
+		excludedNodes = append(excludedNodes, exclusion.NodeName)
 	}
 
 	canClear, err := canClearVotingConfigExclusions(c, actualStatefulSets)
 	if err != nil {
-		return false, err
+		return excludedNodes, false, err
 	}
 	if !canClear {
-		log.V(1).Info("Cannot clear voting exclusions yet", "namespace", es.Namespace, "es_name", es.Name)
-		return true, nil // requeue
+		log.V(1).Info("Cannot clear voting exclusions yet", "namespace", es.Namespace, "es_name", es.Name, "nodes", excludedNodes)
+		return excludedNodes, true, nil // requeue
 	}
 
-	log.Info("Ensuring no voting exclusions are set", "namespace", es.Namespace, "es_name", es.Name)
-	return false, esClient.DeleteVotingConfigExclusions(ctx, false)
+	log.Info("Clearing voting exclusions", "namespace", es.Namespace, "es_name", es.Name, "nodes", excludedNodes)
+	if err := esClient.DeleteVotingConfigExclusions(ctx, false); err != nil {
+		return excludedNodes, false, err
+	}
+	return nil, false, nil
 }
@@ -21,9 +21,14 @@ import (
 type fakeVotingConfigExclusionsESClient struct {
 	called        bool
 	excludedNodes []string
+	exclusions    []client.VotingConfigExclusion
 	client.Client
 }
 
+func (f *fakeVotingConfigExclusionsESClient) GetVotingConfigExclusions(ctx context.Context) ([]client.VotingConfigExclusion, error) {
+	return f.exclusions, nil
+}
+
 func (f *fakeVotingConfigExclusionsESClient) DeleteVotingConfigExclusions(ctx context.Context, waitForRemoval bool) error {
 	f.called = true
 	return nil
@@ -52,13 +57,16 @@ func Test_ClearVotingConfigExclusions(t *testing.T) {
 	}
 	// simulate 2 pods out of the 3
 	statefulSet2rep := sset.TestSset{Name: "nodes", Version: "7.2.0", Replicas: 2, Master: true, Data: true}.Build()
+	exclusions := []client.VotingConfigExclusion{{NodeID: "abc", NodeName: "nodes-2"}}
 	tests := []struct {
 		name               string
 		c                  k8s.Client
 		es                 *esv1.Elasticsearch
 		actualStatefulSets sset.StatefulSetList
+		exclusions         []client.VotingConfigExclusion
 		wantCall           bool
 		wantRequeue        bool
+		wantExcludedNodes  []string
 	}{
 		{
 			name: "no v7 nodes",
@@ -67,14 +75,24 @@ func Test_ClearVotingConfigExclusions(t *testing.T) {
 			actualStatefulSets: sset.StatefulSetList{
 				createStatefulSetWithESVersion("6.8.0"),
 			},
+			exclusions:  exclusions,
 			wantCall:    false,
 			wantRequeue: false,
 		},
+		{
+			name:               "no exclusions set: nothing to clear",
+			c:                  k8s.NewFakeClient(&es, &statefulSet3rep, &pods[0], &pods[1], &pods[2]),
+			es:                 &es,
+			actualStatefulSets: sset.StatefulSetList{statefulSet3rep},
+			wantCall:           false,
+			wantRequeue:        false,
+		},
 		{
 			name:               "3/3 nodes there, should clear",
 			c:                  k8s.NewFakeClient(&es, &statefulSet3rep, &pods[0], &pods[1], &pods[2]),
 			es:                 &es,
 			actualStatefulSets: sset.StatefulSetList{statefulSet3rep},
+			exclusions:         exclusions,
 			wantCall:           true,
 			wantRequeue:        false,
 		},
@@ -83,24 +101,29 @@ func Test_ClearVotingConfigExclusions(t *testing.T) {
 			c:                  k8s.NewFakeClient(&es, &statefulSet3rep, &pods[0], &pods[1]),
 			es:                 &es,
 			actualStatefulSets: sset.StatefulSetList{statefulSet3rep},
+			exclusions:         exclusions,
 			wantCall:           false,
 			wantRequeue:        true,
+			wantExcludedNodes:  []string{"nodes-2"},
 		},
 		{
 			name:               "3/2 nodes there: cannot clear, should requeue",
 			es:                 &es,
 			c:                  k8s.NewFakeClient(&es, &statefulSet2rep, &pods[0], &pods[1], &pods[2]),
 			actualStatefulSets: sset.StatefulSetList{statefulSet2rep},
+			exclusions:         exclusions,
 			wantCall:           false,
 			wantRequeue:        true,
+			wantExcludedNodes:  []string{"nodes-2"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			clientMock := &fakeVotingConfigExclusionsESClient{}
-			requeue, err := ClearVotingConfigExclusions(context.Background(), *tt.es, tt.c, clientMock, tt.actualStatefulSets)
+			clientMock := &fakeVotingConfigExclusionsESClient{exclusions: tt.exclusions}
+			excludedNodes, requeue, err := ClearVotingConfigExclusions(context.Background(), *tt.es, tt.c, clientMock, tt.actualStatefulSets)
 			require.NoError(t, err)
 			require.Equal(t, tt.wantRequeue, requeue)
+			require.Equal(t, tt.wantExcludedNodes, excludedNodes)
 			require.Equal(t, tt.wantCall, clientMock.called)
 			var retrievedES esv1.Elasticsearch
 			err = tt.c.Get(context.Background(), k8s.ExtractNamespacedName(tt.es), &retrievedES)
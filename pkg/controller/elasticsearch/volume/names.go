@@ -23,6 +23,9 @@ const (
 	HTTPCertificatesSecretVolumeName      = "elastic-internal-http-certificates"
 	HTTPCertificatesSecretVolumeMountPath = "/usr/share/elasticsearch/config/http-certs" //nolint:gosec
 
+	RealmVolumeNamePrefix = "elastic-internal-realm-"
+	RealmsVolumeMountPath = "/usr/share/elasticsearch/config/realms"
+
 	XPackFileRealmVolumeName      = "elastic-internal-xpack-file-realm"
 	XPackFileRealmVolumeMountPath = "/mnt/elastic-internal/xpack-file-realm"
 
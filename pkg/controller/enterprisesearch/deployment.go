@@ -25,7 +25,7 @@ func (r *ReconcileEnterpriseSearch) reconcileDeployment(
 	defer span.End()
 
 	deploy := deployment.New(r.deploymentParams(ent, configHash))
-	return deployment.Reconcile(r.K8sClient(), deploy, &ent)
+	return deployment.Reconcile(r.K8sClient(), deploy, &ent, r.RestrictedSecurityContext)
 }
 
 func (r *ReconcileEnterpriseSearch) deploymentParams(ent entv1.EnterpriseSearch, configHash string) deployment.Params {
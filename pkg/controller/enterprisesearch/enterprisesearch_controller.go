@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync/atomic"
+	"time"
 
 	"go.elastic.co/apm"
 	appsv1 "k8s.io/api/apps/v1"
@@ -65,6 +66,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileEn
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		Parameters:     params,
 	}
 }
@@ -120,6 +122,8 @@ type ReconcileEnterpriseSearch struct {
 	k8s.Client
 	recorder       record.EventRecorder
 	dynamicWatches watches.DynamicWatches
+	// statusWriter coalesces successive status updates of the same Enterprise Search.
+	statusWriter *common.StatusWriter
 	operator.Parameters
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
@@ -162,6 +166,11 @@ func (r *ReconcileEnterpriseSearch) Reconcile(ctx context.Context, request recon
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&ent) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", ent.Namespace, "ent_name", ent.Name)
+		return reconcile.Result{}, nil
+	}
+
 	if !association.IsConfiguredIfSet(&ent, r.recorder) {
 		return reconcile.Result{}, nil
 	}
@@ -174,6 +183,7 @@ func (r *ReconcileEnterpriseSearch) onDelete(obj types.NamespacedName) error {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(common.ConfigRefWatchName(obj))
 	// Clean up watches set on custom http tls certificates
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(certificates.CertificateWatchKey(entv1.Namer, obj.Name))
+	r.statusWriter.Forget(obj)
 	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, entv1.Kind)
 }
 
@@ -183,7 +193,7 @@ func (r *ReconcileEnterpriseSearch) doReconcile(ctx context.Context, ent entv1.E
 		return reconcile.Result{}, err
 	}
 
-	svc, err := common.ReconcileService(ctx, r.Client, NewService(ent), &ent)
+	svc, err := common.ReconcileService(ctx, r.Client, NewService(ent), &ent, r.ServerSideApply)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -199,6 +209,7 @@ func (r *ReconcileEnterpriseSearch) doReconcile(ctx context.Context, ent entv1.E
 		CACertRotation:        r.CACertRotation,
 		CertRotation:          r.CertRotation,
 		GarbageCollectSecrets: true,
+		ServerSideApply:       r.ServerSideApply,
 	}.ReconcileCAAndHTTPCerts(ctx)
 	if results.HasError() {
 		res, err := results.Aggregate()
@@ -215,7 +226,7 @@ func (r *ReconcileEnterpriseSearch) doReconcile(ctx context.Context, ent entv1.E
 		return reconcile.Result{}, nil // will eventually retry once updated
 	}
 
-	configSecret, err := ReconcileConfig(r, ent, r.IPFamily)
+	configSecret, err := ReconcileConfig(r, ent, r.IPFamily, r.ServerSideApply)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -237,10 +248,14 @@ func (r *ReconcileEnterpriseSearch) doReconcile(ctx context.Context, ent entv1.E
 		return reconcile.Result{}, fmt.Errorf("reconcile deployment: %w", err)
 	}
 
-	err = r.updateStatus(ent, deploy, svc.Name)
+	requeueAfter, err := r.updateStatus(ent, deploy, svc.Name)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("updating status: %w", err)
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 
 	return results.Aggregate()
 }
@@ -258,14 +273,16 @@ func (r *ReconcileEnterpriseSearch) validate(ctx context.Context, ent *entv1.Ent
 	return nil
 }
 
-func (r *ReconcileEnterpriseSearch) updateStatus(ent entv1.EnterpriseSearch, deploy appsv1.Deployment, svcName string) error {
+// updateStatus writes the status of the Enterprise Search resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func (r *ReconcileEnterpriseSearch) updateStatus(ent entv1.EnterpriseSearch, deploy appsv1.Deployment, svcName string) (time.Duration, error) {
 	pods, err := k8s.PodsMatchingLabels(r.K8sClient(), ent.Namespace, map[string]string{EnterpriseSearchNameLabelName: ent.Name})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	deploymentStatus, err := common.DeploymentStatus(ent.Status.DeploymentStatus, deploy, pods, VersionLabelName)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	newStatus := entv1.EnterpriseSearchStatus{
 		DeploymentStatus: deploymentStatus,
@@ -274,19 +291,24 @@ func (r *ReconcileEnterpriseSearch) updateStatus(ent entv1.EnterpriseSearch, dep
 	}
 
 	if reflect.DeepEqual(newStatus, ent.Status) {
-		return nil // nothing to do
+		return 0, nil // nothing to do
 	}
 	if newStatus.IsDegraded(ent.Status.DeploymentStatus) {
 		r.recorder.Event(&ent, corev1.EventTypeWarning, events.EventReasonUnhealthy, "Enterprise Search health degraded")
 	}
-	log.V(1).Info("Updating status",
+	immediate := newStatus.Health != ent.Status.Health
+	ent.Status = newStatus
+	requeueAfter, err := r.statusWriter.Write(r.Client, &ent, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
 		"iteration", atomic.LoadUint64(&r.iteration),
 		"namespace", ent.Namespace,
 		"ent_name", ent.Name,
 		"status", newStatus,
 	)
-	ent.Status = newStatus
-	return common.UpdateStatus(r.Client, &ent)
+	return 0, nil
 }
 
 func NewService(ent entv1.EnterpriseSearch) *corev1.Service {
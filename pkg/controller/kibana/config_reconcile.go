@@ -69,6 +69,7 @@ func ReconcileConfigSecret(
 	client k8s.Client,
 	kb kbv1.Kibana,
 	kbSettings CanonicalConfig,
+	serverSideApply bool,
 ) error {
 	span, _ := apm.StartSpan(ctx, "reconcile_config_secret", tracing.SpanTypeApp)
 	defer span.End()
@@ -102,7 +103,7 @@ func ReconcileConfigSecret(
 		Data: data,
 	}
 
-	_, err = reconciler.ReconcileSecret(client, expected, &kb)
+	_, err = reconciler.ReconcileSecret(client, expected, &kb, serverSideApply)
 	return err
 }
 
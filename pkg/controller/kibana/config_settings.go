@@ -14,6 +14,7 @@ import (
 	"go.elastic.co/apm"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
@@ -21,6 +22,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
@@ -57,8 +59,9 @@ const (
 	ElasticsearchSslCertificateAuthorities = "elasticsearch.ssl.certificateAuthorities"
 	ElasticsearchSslVerificationMode       = "elasticsearch.ssl.verificationMode"
 
-	ElasticsearchUsername = "elasticsearch.username"
-	ElasticsearchPassword = "elasticsearch.password"
+	ElasticsearchUsername            = "elasticsearch.username"
+	ElasticsearchPassword            = "elasticsearch.password"
+	ElasticsearchServiceAccountToken = "elasticsearch.serviceAccountToken"
 
 	ElasticsearchHosts = "elasticsearch.hosts"
 
@@ -78,11 +81,11 @@ type CanonicalConfig struct {
 }
 
 // NewConfigSettings returns the Kibana configuration settings for the given Kibana resource.
-func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v version.Version, ipFamily corev1.IPFamily) (CanonicalConfig, error) {
+func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v version.Version, ipFamily corev1.IPFamily, serverSideApply bool) (CanonicalConfig, error) {
 	span, _ := apm.StartSpan(ctx, "new_config_settings", tracing.SpanTypeApp)
 	defer span.End()
 
-	reusableSettings, err := getOrCreateReusableSettings(client, kb)
+	reusableSettings, err := getOrCreateReusableSettings(client, kb, serverSideApply)
 	if err != nil {
 		return CanonicalConfig{}, err
 	}
@@ -135,6 +138,15 @@ func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v
 	if err != nil {
 		return CanonicalConfig{}, err
 	}
+	esAuthSettings := map[string]interface{}{
+		ElasticsearchUsername: username,
+		ElasticsearchPassword: password,
+	}
+	if kb.EsAssociation().AssociationConf().IsServiceAccount {
+		esAuthSettings = map[string]interface{}{
+			ElasticsearchServiceAccountToken: password,
+		}
+	}
 
 	// merge the configuration with userSettings last so they take precedence
 	err = cfg.MergeWith(
@@ -144,12 +156,7 @@ func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v
 		entSearchCfg,
 		monitoringCfg,
 		settings.MustCanonicalConfig(elasticsearchTLSSettings(kb)),
-		settings.MustCanonicalConfig(
-			map[string]interface{}{
-				ElasticsearchUsername: username,
-				ElasticsearchPassword: password,
-			},
-		),
+		settings.MustCanonicalConfig(esAuthSettings),
 		userSettings,
 	)
 	if err != nil {
@@ -214,20 +221,14 @@ func getExistingConfig(client k8s.Client, kb kbv1.Kibana) (*settings.CanonicalCo
 	return cfg, nil
 }
 
-// getOrCreateReusableSettings filters an existing config for only items we want to preserve between spec changes
-// because they cannot be generated deterministically, e.g. encryption keys
-func getOrCreateReusableSettings(c k8s.Client, kb kbv1.Kibana) (*settings.CanonicalConfig, error) {
-	cfg, err := getExistingConfig(c, kb)
+// getOrCreateReusableSettings returns the settings we want to preserve between spec changes because they cannot be
+// generated deterministically, e.g. encryption keys. They are persisted in a dedicated Secret, so that all the Kibana
+// instances share the same keys and sessions and encrypted saved objects remain readable across restarts.
+func getOrCreateReusableSettings(c k8s.Client, kb kbv1.Kibana, serverSideApply bool) (*settings.CanonicalConfig, error) {
+	r, err := getExistingReusableSettings(c, kb)
 	if err != nil {
 		return nil, err
 	}
-
-	var r reusableSettings
-	if cfg == nil {
-		r = reusableSettings{}
-	} else if err := cfg.Unpack(&r); err != nil {
-		return nil, err
-	}
 	if len(r.EncryptionKey) == 0 {
 		r.EncryptionKey = string(common.RandomBytes(64))
 	}
@@ -243,9 +244,58 @@ func getOrCreateReusableSettings(c k8s.Client, kb kbv1.Kibana) (*settings.Canoni
 	if len(r.SavedObjectsKey) == 0 && kbVer.GTE(version.From(7, 6, 0)) {
 		r.SavedObjectsKey = string(common.RandomBytes(64))
 	}
+
+	if err := reconcileEncryptionKeysSecret(c, kb, r, serverSideApply); err != nil {
+		return nil, err
+	}
 	return settings.MustCanonicalConfig(r), nil
 }
 
+// getExistingReusableSettings returns the encryption keys persisted in the encryption keys Secret, or in the config
+// Secret for Kibana resources created by previous versions of the operator.
+func getExistingReusableSettings(c k8s.Client, kb kbv1.Kibana) (reusableSettings, error) {
+	var r reusableSettings
+	var secret corev1.Secret
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: kb.Namespace, Name: kbv1.EncryptionKeysSecret(kb.Name)}, &secret)
+	if err == nil {
+		r.EncryptionKey = string(secret.Data[XpackSecurityEncryptionKey])
+		r.ReportingKey = string(secret.Data[XpackReportingEncryptionKey])
+		r.SavedObjectsKey = string(secret.Data[XpackEncryptedSavedObjectsEncryptionKey])
+		return r, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return r, err
+	}
+
+	cfg, err := getExistingConfig(c, kb)
+	if err != nil || cfg == nil {
+		return r, err
+	}
+	err = cfg.Unpack(&r)
+	return r, err
+}
+
+// reconcileEncryptionKeysSecret persists the given encryption keys in the encryption keys Secret.
+func reconcileEncryptionKeysSecret(c k8s.Client, kb kbv1.Kibana, r reusableSettings, serverSideApply bool) error {
+	data := map[string][]byte{
+		XpackSecurityEncryptionKey:  []byte(r.EncryptionKey),
+		XpackReportingEncryptionKey: []byte(r.ReportingKey),
+	}
+	if len(r.SavedObjectsKey) > 0 {
+		data[XpackEncryptedSavedObjectsEncryptionKey] = []byte(r.SavedObjectsKey)
+	}
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: kb.Namespace,
+			Name:      kbv1.EncryptionKeysSecret(kb.Name),
+			Labels:    NewLabels(kb.Name),
+		},
+		Data: data,
+	}
+	_, err := reconciler.ReconcileSecret(c, expected, &kb, serverSideApply)
+	return err
+}
+
 func baseSettings(kb *kbv1.Kibana, ipFamily corev1.IPFamily) (map[string]interface{}, error) {
 	ver, err := version.Parse(kb.Spec.Version)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
@@ -55,6 +56,16 @@ elasticsearch:
     verificationMode: certificate
 `)
 
+var esServiceAccountAssociationConfig = []byte(`
+elasticsearch:
+  hosts:
+    - "https://es-url:9200"
+  serviceAccountToken: "token"
+  ssl:
+    certificateAuthorities: /usr/share/kibana/config/elasticsearch-certs/ca.crt
+    verificationMode: certificate
+`)
+
 var entAssociationConfig = []byte(`
 enterpriseSearch:
   host: https://ent-url:3002
@@ -102,6 +113,37 @@ func Test_reuseOrGenerateSecrets(t *testing.T) {
 				assert.Equal(t, expectedSettings, got)
 			},
 		},
+		{
+			name: "Reuse the keys of the encryption keys secret over the ones of the config secret",
+			args: args{
+				c: k8s.NewFakeClient(
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Namespace: defaultKb.Namespace, Name: SecretName(defaultKb)},
+						Data: map[string][]byte{
+							SettingsFilename: defaultConfig,
+						},
+					},
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Namespace: defaultKb.Namespace, Name: kbv1.EncryptionKeysSecret(defaultKb.Name)},
+						Data: map[string][]byte{
+							XpackSecurityEncryptionKey:              []byte("persistedencryptionkey"),
+							XpackReportingEncryptionKey:             []byte("persistedreportingkey"),
+							XpackEncryptedSavedObjectsEncryptionKey: []byte("persistedobjectkey"),
+						},
+					},
+				),
+				kibana: defaultKb,
+			},
+			assertion: func(t *testing.T, got *settings.CanonicalConfig, err error) {
+				t.Helper()
+				expectedSettings := settings.MustCanonicalConfig(map[string]interface{}{
+					XpackSecurityEncryptionKey:              "persistedencryptionkey",
+					XpackReportingEncryptionKey:             "persistedreportingkey",
+					XpackEncryptedSavedObjectsEncryptionKey: "persistedobjectkey",
+				})
+				assert.Equal(t, expectedSettings, got)
+			},
+		},
 		{
 			name: "Create new encryption keys",
 			args: args{
@@ -152,12 +194,21 @@ func Test_reuseOrGenerateSecrets(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getOrCreateReusableSettings(tt.args.c, tt.args.kibana)
+			got, err := getOrCreateReusableSettings(tt.args.c, tt.args.kibana, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getOrCreateReusableSettings() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			tt.assertion(t, got, err)
+
+			// the keys are persisted in the encryption keys secret
+			var r reusableSettings
+			require.NoError(t, got.Unpack(&r))
+			var secret corev1.Secret
+			require.NoError(t, tt.args.c.Get(context.Background(), types.NamespacedName{Namespace: tt.args.kibana.Namespace, Name: kbv1.EncryptionKeysSecret(tt.args.kibana.Name)}, &secret))
+			require.Equal(t, r.EncryptionKey, string(secret.Data[XpackSecurityEncryptionKey]))
+			require.Equal(t, r.ReportingKey, string(secret.Data[XpackReportingEncryptionKey]))
+			require.Equal(t, r.SavedObjectsKey, string(secret.Data[XpackEncryptedSavedObjectsEncryptionKey]))
 		})
 	}
 }
@@ -288,6 +339,48 @@ func TestNewConfigSettings(t *testing.T) {
 			}(),
 			wantErr: false,
 		},
+		{
+			name: "with elasticsearch Association using a service account",
+			args: args{
+				kb: func() kbv1.Kibana {
+					kb := mkKibana()
+					kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{Name: "test-es"}
+					kb.EsAssociation().SetAssociationConf(&commonv1.AssociationConf{
+						AuthSecretName:   "auth-secret",
+						AuthSecretKey:    "token",
+						IsServiceAccount: true,
+						CASecretName:     "ca-secret",
+						CACertProvided:   true,
+						URL:              "https://es-url:9200",
+					})
+					return kb
+				},
+				client: k8s.NewFakeClient(
+					existingSecret,
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "auth-secret",
+							Namespace: mkKibana().Namespace,
+						},
+						Data: map[string][]byte{
+							"token": []byte("token"),
+						},
+					},
+				),
+				ipFamily: corev1.IPv4Protocol,
+			},
+			want: func() []byte {
+				cfg, err := settings.ParseConfig(defaultConfig)
+				require.NoError(t, err)
+				assocCfg, err := settings.ParseConfig(esServiceAccountAssociationConfig)
+				require.NoError(t, err)
+				require.NoError(t, cfg.MergeWith(assocCfg))
+				bytes, err := cfg.Render()
+				require.NoError(t, err)
+				return bytes
+			}(),
+			wantErr: false,
+		},
 		{
 			name: "with Enterprise Search Association",
 			args: args{
@@ -465,7 +558,7 @@ func TestNewConfigSettings(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			kb := tt.args.kb()
 			v := version.From(7, 6, 0)
-			got, err := NewConfigSettings(context.Background(), tt.args.client, kb, v, tt.args.ipFamily)
+			got, err := NewConfigSettings(context.Background(), tt.args.client, kb, v, tt.args.ipFamily, false)
 			if tt.wantErr {
 				require.Error(t, err)
 			}
@@ -491,7 +584,7 @@ func TestNewConfigSettingsCreateEncryptionKeys(t *testing.T) {
 	client := k8s.NewFakeClient()
 	kb := mkKibana()
 	v := version.MustParse(kb.Spec.Version)
-	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol)
+	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol, false)
 	require.NoError(t, err)
 	for _, key := range []string{XpackSecurityEncryptionKey, XpackReportingEncryptionKey, XpackEncryptedSavedObjectsEncryptionKey} {
 		val, err := (*ucfg.Config)(got.CanonicalConfig).String(key, -1, settings.Options...)
@@ -517,7 +610,7 @@ func TestNewConfigSettingsExistingEncryptionKey(t *testing.T) {
 	}
 	client := k8s.NewFakeClient(existingSecret)
 	v := version.MustParse(kb.Spec.Version)
-	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol)
+	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol, false)
 	require.NoError(t, err)
 	var gotCfg map[string]interface{}
 	require.NoError(t, got.Unpack(&gotCfg))
@@ -546,7 +639,7 @@ func TestNewConfigSettingsExplicitEncryptionKey(t *testing.T) {
 	kb.Spec.Config = &cfg
 	client := k8s.NewFakeClient()
 	v := version.MustParse(kb.Spec.Version)
-	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol)
+	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol, false)
 	require.NoError(t, err)
 	val, err := (*ucfg.Config)(got.CanonicalConfig).String(XpackSecurityEncryptionKey, -1, settings.Options...)
 	require.NoError(t, err)
@@ -559,7 +652,7 @@ func TestNewConfigSettingsPre760(t *testing.T) {
 	kb.Spec.Version = "7.5.0"
 	client := k8s.NewFakeClient()
 	v := version.MustParse(kb.Spec.Version)
-	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol)
+	got, err := NewConfigSettings(context.Background(), client, kb, v, corev1.IPv4Protocol, false)
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(got.CanonicalConfig.HasKeys([]string{XpackEncryptedSavedObjects})))
 }
@@ -8,6 +8,7 @@ import (
 	"context"
 	"reflect"
 	"sync/atomic"
+	"time"
 
 	"go.elastic.co/apm"
 	appsv1 "k8s.io/api/apps/v1"
@@ -61,6 +62,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileKi
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		params:         params,
 	}
 }
@@ -117,6 +119,9 @@ type ReconcileKibana struct {
 
 	dynamicWatches watches.DynamicWatches
 
+	// statusWriter coalesces successive status updates of the same Kibana.
+	statusWriter *common.StatusWriter
+
 	params operator.Parameters
 
 	// iteration is the number of times this controller has run its Reconcile method
@@ -147,6 +152,11 @@ func (r *ReconcileKibana) Reconcile(ctx context.Context, request reconcile.Reque
 		return reconcile.Result{}, nil
 	}
 
+	if !r.params.ResourceSelector.Matches(&kb) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", kb.Namespace, "kibana_name", kb.Name)
+		return reconcile.Result{}, nil
+	}
+
 	// Remove any previous Finalizers
 	if err := finalizer.RemoveAll(r.Client, &kb); err != nil {
 		return reconcile.Result{}, tracing.CaptureError(ctx, err)
@@ -167,7 +177,7 @@ func (r *ReconcileKibana) doReconcile(ctx context.Context, request reconcile.Req
 		return reconcile.Result{}, err
 	}
 
-	driver, err := newDriver(r, r.dynamicWatches, r.recorder, kb, r.params.IPFamily)
+	driver, err := newDriver(r, r.dynamicWatches, r.recorder, kb, r.params)
 	if err != nil {
 		return reconcile.Result{}, tracing.CaptureError(ctx, err)
 	}
@@ -176,11 +186,15 @@ func (r *ReconcileKibana) doReconcile(ctx context.Context, request reconcile.Req
 	results := driver.Reconcile(ctx, &state, kb, r.params)
 
 	// update status
-	err = r.updateStatus(ctx, state)
+	requeueAfter, err := r.updateStatus(ctx, state)
 	if err != nil && apierrors.IsConflict(err) {
 		log.V(1).Info("Conflict while updating status", "namespace", kb.Namespace, "kibana_name", kb.Name)
 		return reconcile.Result{Requeue: true}, nil
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 
 	res, err := results.WithError(err).Aggregate()
 	k8s.EmitErrorEvent(r.recorder, err, kb, events.EventReconciliationError, "Reconciliation error: %v", err)
@@ -200,24 +214,31 @@ func (r *ReconcileKibana) validate(ctx context.Context, kb *kbv1.Kibana) error {
 	return nil
 }
 
-func (r *ReconcileKibana) updateStatus(ctx context.Context, state State) error {
+// updateStatus writes the status of the Kibana resource if it changed. Health changes are written immediately, other
+// changes can be coalesced: it then returns the delay after which the update should be retried.
+func (r *ReconcileKibana) updateStatus(ctx context.Context, state State) (time.Duration, error) {
 	span, _ := apm.StartSpan(ctx, "update_status", tracing.SpanTypeApp)
 	defer span.End()
 
 	current := state.originalKibana
 	if reflect.DeepEqual(current.Status, state.Kibana.Status) {
-		return nil
+		return 0, nil
 	}
 	if state.Kibana.Status.DeploymentStatus.IsDegraded(current.Status.DeploymentStatus) {
 		r.recorder.Event(current, corev1.EventTypeWarning, events.EventReasonUnhealthy, "Kibana health degraded")
 	}
-	log.V(1).Info("Updating status",
+	immediate := state.Kibana.Status.Health != current.Status.Health
+	requeueAfter, err := r.statusWriter.Write(r.Client, state.Kibana, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
 		"iteration", atomic.LoadUint64(&r.iteration),
 		"namespace", state.Kibana.Namespace,
 		"kibana_name", state.Kibana.Name,
 		"status", state.Kibana.Status,
 	)
-	return common.UpdateStatus(r.Client, state.Kibana)
+	return 0, nil
 }
 
 func (r *ReconcileKibana) onDelete(obj types.NamespacedName) error {
@@ -225,6 +246,7 @@ func (r *ReconcileKibana) onDelete(obj types.NamespacedName) error {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(obj))
 	// Clean up watches set on custom http tls certificates
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(certificates.CertificateWatchKey(kbv1.KBNamer, obj.Name))
+	r.statusWriter.Forget(obj)
 	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, kbv1.Kind)
 }
 
@@ -47,6 +47,9 @@ type driver struct {
 	recorder       record.EventRecorder
 	version        version.Version
 	ipFamily       corev1.IPFamily
+	restricted     bool
+	// serverSideApply is true if the resources are written through server-side apply
+	serverSideApply bool
 }
 
 func (d *driver) DynamicWatches() watches.DynamicWatches {
@@ -68,7 +71,7 @@ func newDriver(
 	watches watches.DynamicWatches,
 	recorder record.EventRecorder,
 	kb *kbv1.Kibana,
-	ipFamily corev1.IPFamily,
+	params operator.Parameters,
 ) (*driver, error) {
 	ver, err := version.Parse(kb.Spec.Version)
 	if err != nil {
@@ -83,11 +86,13 @@ func newDriver(
 	}
 
 	return &driver{
-		client:         client,
-		dynamicWatches: watches,
-		recorder:       recorder,
-		version:        ver,
-		ipFamily:       ipFamily,
+		client:          client,
+		dynamicWatches:  watches,
+		recorder:        recorder,
+		version:         ver,
+		ipFamily:        params.IPFamily,
+		restricted:      params.RestrictedSecurityContext,
+		serverSideApply: params.ServerSideApply,
 	}, nil
 }
 
@@ -105,7 +110,7 @@ func (d *driver) Reconcile(
 		return results
 	}
 
-	svc, err := common.ReconcileService(ctx, d.client, NewService(*kb), kb)
+	svc, err := common.ReconcileService(ctx, d.client, NewService(*kb), kb, d.serverSideApply)
 	if err != nil {
 		// TODO: consider updating some status here?
 		return results.WithError(err)
@@ -122,6 +127,7 @@ func (d *driver) Reconcile(
 		CACertRotation:        params.CACertRotation,
 		CertRotation:          params.CertRotation,
 		GarbageCollectSecrets: true,
+		ServerSideApply:       d.serverSideApply,
 	}.ReconcileCAAndHTTPCerts(ctx)
 	if results.HasError() {
 		_, err := results.Aggregate()
@@ -134,17 +140,17 @@ func (d *driver) Reconcile(
 		return results // will eventually retry
 	}
 
-	kbSettings, err := NewConfigSettings(ctx, d.client, *kb, d.version, d.ipFamily)
+	kbSettings, err := NewConfigSettings(ctx, d.client, *kb, d.version, d.ipFamily, d.serverSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
 
-	err = ReconcileConfigSecret(ctx, d.client, *kb, kbSettings)
+	err = ReconcileConfigSecret(ctx, d.client, *kb, kbSettings, d.serverSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
 
-	err = stackmon.ReconcileConfigSecrets(d.client, *kb)
+	err = stackmon.ReconcileConfigSecrets(d.client, *kb, d.serverSideApply)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -158,7 +164,7 @@ func (d *driver) Reconcile(
 	}
 
 	expectedDp := deployment.New(deploymentParams)
-	reconciledDp, err := deployment.Reconcile(d.client, expectedDp, kb)
+	reconciledDp, err := deployment.Reconcile(d.client, expectedDp, kb, d.restricted)
 	if err != nil {
 		return results.WithError(err)
 	}
@@ -211,6 +217,7 @@ func (d *driver) deploymentParams(kb *kbv1.Kibana) (deployment.Params, error) {
 		kbv1.KBNamer,
 		NewLabels(kb.Name),
 		initContainersParameters,
+		d.serverSideApply,
 	)
 	if err != nil {
 		return deployment.Params{}, err
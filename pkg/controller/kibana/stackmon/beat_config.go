@@ -24,14 +24,14 @@ var (
 )
 
 // ReconcileConfigSecrets reconciles the secrets holding beats configuration
-func ReconcileConfigSecrets(client k8s.Client, kb kbv1.Kibana) error {
+func ReconcileConfigSecrets(client k8s.Client, kb kbv1.Kibana, serverSideApply bool) error {
 	if monitoring.IsMetricsDefined(&kb) {
 		b, err := Metricbeat(client, kb)
 		if err != nil {
 			return err
 		}
 
-		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &kb); err != nil {
+		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &kb, serverSideApply); err != nil {
 			return err
 		}
 	}
@@ -42,7 +42,7 @@ func ReconcileConfigSecrets(client k8s.Client, kb kbv1.Kibana) error {
 			return err
 		}
 
-		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &kb); err != nil {
+		if _, err := reconciler.ReconcileSecret(client, b.ConfigSecret, &kb, serverSideApply); err != nil {
 			return err
 		}
 	}
@@ -72,8 +72,10 @@ func Add(mgr manager.Manager, p operator.Parameters) error {
 func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileLicenses {
 	c := mgr.GetClient()
 	return &ReconcileLicenses{
-		Client:  c,
-		checker: license.NewLicenseChecker(c, params.OperatorNamespace),
+		Client:            c,
+		checker:           license.NewLicenseChecker(c, params.OperatorNamespace),
+		operatorNamespace: params.OperatorNamespace,
+		serverSideApply:   params.ServerSideApply,
 	}
 }
 
@@ -143,6 +145,10 @@ type ReconcileLicenses struct {
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
 	checker   license.Checker
+	// operatorNamespace is the namespace of the config map reporting the license of each cluster
+	operatorNamespace string
+	// serverSideApply is true if the license secrets are written through server-side apply
+	serverSideApply bool
 }
 
 // findLicense tries to find the best Elastic stack license available.
@@ -160,6 +166,7 @@ func reconcileSecret(
 	cluster esv1.Elasticsearch,
 	parent string,
 	esLicense esclient.License,
+	serverSideApply bool,
 ) error {
 	secretName := esv1.LicenseSecretName(cluster.Name)
 
@@ -184,7 +191,7 @@ func reconcileSecret(
 		},
 	}
 	// create/update a secret in the cluster's namespace containing the same data
-	_, err = reconciler.ReconcileSecret(c, expected, &cluster)
+	_, err = reconciler.ReconcileSecret(c, expected, &cluster, serverSideApply)
 	return err
 }
 
@@ -210,11 +217,16 @@ func (r *ReconcileLicenses) reconcileClusterLicense(cluster esv1.Elasticsearch)
 		if err != nil && !errors.IsNotFound(err) {
 			log.Error(err, "failed to delete cluster license secret", "secret_name", secretName, "namespace", cluster.Namespace, "es_name", cluster.Name)
 		}
-		return noResult, true, nil
+		status := basicLicenseStatus()
+		return noResult, true, r.reportClusterLicense(k8s.ExtractNamespacedName(&cluster), &status)
 	}
 	log.V(1).Info("Found license for cluster", "eck_license", parent, "es_license", matchingSpec.UID, "license_type", matchingSpec.Type, "namespace", cluster.Namespace, "es_name", cluster.Name)
 	// make sure the signature secret is created in the cluster's namespace
-	if err := reconcileSecret(r, cluster, parent, matchingSpec); err != nil {
+	if err := reconcileSecret(r, cluster, parent, matchingSpec, r.serverSideApply); err != nil {
+		return noResult, false, err
+	}
+	status := newClusterLicenseStatus(matchingSpec.Type, matchingSpec.ExpiryTime(), parent)
+	if err := r.reportClusterLicense(k8s.ExtractNamespacedName(&cluster), &status); err != nil {
 		return noResult, false, err
 	}
 	return matchingSpec.ExpiryTime(), false, nil
@@ -247,15 +259,15 @@ func (r *ReconcileLicenses) reconcileInternal(request reconcile.Request) *reconc
 	err := r.Get(context.Background(), request.NamespacedName, &cluster)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			// nothing to do no cluster
-			return res
+			// nothing to do no cluster, except to stop reporting its license
+			return res.WithError(r.reportClusterLicense(request.NamespacedName, nil))
 		}
 		return res.WithError(err)
 	}
 
 	if !cluster.DeletionTimestamp.IsZero() {
-		// cluster is being deleted nothing to do
-		return res
+		// cluster is being deleted nothing to do, except to stop reporting its license
+		return res.WithError(r.reportClusterLicense(request.NamespacedName, nil))
 	}
 
 	newExpiry, noLicense, err := r.reconcileClusterLicense(cluster)
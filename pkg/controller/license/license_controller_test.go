@@ -11,11 +11,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
@@ -23,6 +25,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/chrono"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
 )
 
 func Test_nextReconcileRelativeTo(t *testing.T) {
@@ -126,6 +129,7 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 		wantNewLicense   bool
 		wantRequeue      bool
 		wantRequeueAfter bool
+		wantLicenseType  string
 	}{
 		{
 			name:             "no existing license: nothing to do",
@@ -135,6 +139,7 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 			wantNewLicense:   false,
 			wantRequeue:      false,
 			wantRequeueAfter: false,
+			wantLicenseType:  "basic",
 		},
 		{
 			name:    "existing gold matching license",
@@ -147,6 +152,7 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 			wantNewLicense:   true,
 			wantRequeue:      false,
 			wantRequeueAfter: true,
+			wantLicenseType:  "gold",
 		},
 		{
 			name:    "existing platinum matching license",
@@ -159,6 +165,7 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 			wantNewLicense:   true,
 			wantRequeue:      false,
 			wantRequeueAfter: true,
+			wantLicenseType:  "platinum",
 		},
 		{
 			name:    "existing license expired",
@@ -171,14 +178,16 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 			wantNewLicense:   false,
 			wantRequeue:      false,
 			wantRequeueAfter: false,
+			wantLicenseType:  "basic",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := k8s.NewFakeClient(tt.k8sResources...)
 			r := &ReconcileLicenses{
-				Client:  client,
-				checker: commonlicense.MockLicenseChecker{EnterpriseEnabled: true},
+				Client:            client,
+				checker:           commonlicense.MockLicenseChecker{EnterpriseEnabled: true},
+				operatorNamespace: "elastic-system",
 			}
 			nsn := k8s.ExtractNamespacedName(tt.cluster)
 			res, err := r.reconcileInternal(reconcile.Request{NamespacedName: nsn}).Aggregate()
@@ -207,6 +216,40 @@ func TestReconcileLicenses_reconcileInternal(t *testing.T) {
 				require.NoError(t, err)
 				require.NotEmpty(t, license.Data)
 			}
+			// verify that the license of the cluster is reported
+			var cm corev1.ConfigMap
+			require.NoError(t, client.Get(context.Background(), types.NamespacedName{Namespace: "elastic-system", Name: ClusterLicensesCfgMapName}, &cm))
+			var status clusterLicenseStatus
+			require.NoError(t, json.Unmarshal([]byte(cm.Data["namespace_cluster"]), &status))
+			require.Equal(t, tt.wantLicenseType, status.Type)
+			require.Equal(t, tt.wantLicenseType == "basic", status.Expiry == "")
 		})
 	}
 }
+
+func TestReconcileLicenses_reportClusterLicense(t *testing.T) {
+	c := k8s.NewFakeClient()
+	r := &ReconcileLicenses{Client: c, operatorNamespace: "elastic-system"}
+	nsn := types.NamespacedName{Namespace: "ns", Name: "es"}
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+	expiry := chrono.MustParseTime("2022-01-01")
+
+	status := newClusterLicenseStatus("platinum", expiry, "eck-license")
+	require.NoError(t, r.reportClusterLicense(nsn, &status))
+	basic := basicLicenseStatus()
+	require.NoError(t, r.reportClusterLicense(other, &basic))
+	require.Equal(t, float64(expiry.Unix()), testutil.ToFloat64(metrics.LicensingClusterExpiryGauge.WithLabelValues("ns", "es")))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.LicensingClusterExpiryGauge.WithLabelValues("ns", "other")))
+
+	var cm corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "elastic-system", Name: ClusterLicensesCfgMapName}, &cm))
+	require.Equal(t, map[string]string{
+		"ns_es":    `{"type":"platinum","expiry":"2022-01-01T00:00:00Z","eck_license":"eck-license"}`,
+		"ns_other": `{"type":"basic"}`,
+	}, cm.Data)
+
+	// the cluster is deleted
+	require.NoError(t, r.reportClusterLicense(nsn, nil))
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "elastic-system", Name: ClusterLicensesCfgMapName}, &cm))
+	require.Equal(t, map[string]string{"ns_other": `{"type":"basic"}`}, cm.Data)
+}
@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package license
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/license"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/metrics"
+)
+
+// ClusterLicensesCfgMapName is the name of the config map, in the operator namespace, reporting the license
+// of each managed Elasticsearch cluster.
+const ClusterLicensesCfgMapName = "elastic-licensing-clusters"
+
+// clusterLicenseStatus is the license status of an Elasticsearch cluster, as reported in the config map.
+type clusterLicenseStatus struct {
+	// Type is the type of the cluster license, basic if no enterprise license applies to the cluster.
+	Type string `json:"type"`
+	// Expiry is the expiry date of the cluster license, empty for a basic license.
+	Expiry string `json:"expiry,omitempty"`
+	// EnterpriseLicense is the name of the operator license the cluster license comes from.
+	EnterpriseLicense string `json:"eck_license,omitempty"`
+}
+
+func basicLicenseStatus() clusterLicenseStatus {
+	return clusterLicenseStatus{Type: string(license.LicenseTypeBasic)}
+}
+
+func newClusterLicenseStatus(licenseType string, expiry time.Time, parent string) clusterLicenseStatus {
+	return clusterLicenseStatus{Type: licenseType, Expiry: expiry.UTC().Format(time.RFC3339), EnterpriseLicense: parent}
+}
+
+// clusterLicenseKey returns the key under which the license status of the given cluster is reported. Underscores
+// are not allowed in namespace and resource names, which prevents collisions.
+func clusterLicenseKey(cluster types.NamespacedName) string {
+	return fmt.Sprintf("%s_%s", cluster.Namespace, cluster.Name)
+}
+
+// reportClusterLicense publishes the license status of the given cluster as a metric and in the config map.
+// A nil status removes the cluster from both.
+func (r *ReconcileLicenses) reportClusterLicense(cluster types.NamespacedName, status *clusterLicenseStatus) error {
+	if status == nil {
+		metrics.LicensingClusterExpiryGauge.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	} else {
+		expiry := float64(0)
+		if t, err := time.Parse(time.RFC3339, status.Expiry); err == nil {
+			expiry = float64(t.Unix())
+		}
+		metrics.LicensingClusterExpiryGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(expiry)
+	}
+
+	if r.operatorNamespace == "" {
+		return nil
+	}
+	return r.updateClusterLicensesConfigMap(cluster, status)
+}
+
+// updateClusterLicensesConfigMap only updates the entry of the given cluster, the config map being shared by the
+// reconciliations of all the clusters. Conflicting updates are retried with the next reconciliation.
+func (r *ReconcileLicenses) updateClusterLicensesConfigMap(cluster types.NamespacedName, status *clusterLicenseStatus) error {
+	key := clusterLicenseKey(cluster)
+	var cm corev1.ConfigMap
+	err := r.Get(context.Background(), types.NamespacedName{Namespace: r.operatorNamespace, Name: ClusterLicensesCfgMapName}, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if status == nil {
+		if _, reported := cm.Data[key]; !exists || !reported {
+			return nil
+		}
+		delete(cm.Data, key)
+		return r.Update(context.Background(), &cm)
+	}
+
+	value, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if exists && cm.Data[key] == string(value) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[key] = string(value)
+	if exists {
+		return r.Update(context.Background(), &cm)
+	}
+	cm.ObjectMeta = metav1.ObjectMeta{
+		Namespace: r.operatorNamespace,
+		Name:      ClusterLicensesCfgMapName,
+		Labels: map[string]string{
+			common.TypeLabelName: license.Type,
+		},
+	}
+	return r.Create(context.Background(), &cm)
+}
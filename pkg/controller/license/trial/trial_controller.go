@@ -54,6 +54,7 @@ type ReconcileTrials struct {
 	iteration         int64
 	trialState        licensing.TrialState
 	operatorNamespace string
+	serverSideApply   bool
 }
 
 // Reconcile watches a trial status secret. If it finds a trial license it checks whether a trial has been started.
@@ -186,7 +187,7 @@ func (r *ReconcileTrials) completeTrialActivation(license types.NamespacedName)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
-		_, err = reconciler.ReconcileSecret(r, expectedStatus, nil)
+		_, err = reconciler.ReconcileSecret(r, expectedStatus, nil, r.serverSideApply)
 		return reconcile.Result{}, err
 	}
 	return reconcile.Result{}, nil
@@ -233,6 +234,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileTr
 		Client:            mgr.GetClient(),
 		recorder:          mgr.GetEventRecorderFor(name),
 		operatorNamespace: params.OperatorNamespace,
+		serverSideApply:   params.ServerSideApply,
 	}
 }
 
@@ -0,0 +1,182 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	ConfigFilename    = "logstash.yml"
+	PipelinesFilename = "pipelines.yml"
+)
+
+// PipelinesRefWatchName returns the name of the watch registered on the secret referenced in `pipelinesRef`.
+func PipelinesRefWatchName(resource types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-pipelinesref", resource.Namespace, resource.Name)
+}
+
+// reconcileConfig reconciles the Secret holding the logstash.yml and pipelines.yml files of the given Logstash.
+func reconcileConfig(d driver.Interface, ls lsv1alpha1.Logstash, ipFamily corev1.IPFamily, serverSideApply bool) (corev1.Secret, error) {
+	cfg, err := newConfig(d, ls, ipFamily)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+
+	cfgBytes, err := cfg.Render()
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+
+	data := map[string][]byte{
+		ConfigFilename: cfgBytes,
+	}
+
+	pipelines, err := newPipelines(d, ls)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+	// keep the default pipelines of the Logstash image if none is specified
+	if pipelines != nil {
+		data[PipelinesFilename] = pipelines
+	}
+
+	// Reconcile the configuration in a secret
+	expectedConfigSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ls.Namespace,
+			Name:      Config(ls.Name),
+			Labels:    common.AddCredentialsLabel(labels(ls.Name)),
+		},
+		Data: data,
+	}
+
+	return reconciler.ReconcileSecret(d.K8sClient(), expectedConfigSecret, &ls, serverSideApply)
+}
+
+func newConfig(d driver.Interface, ls lsv1alpha1.Logstash, ipFamily corev1.IPFamily) (*settings.CanonicalConfig, error) {
+	cfg := settings.NewCanonicalConfig()
+
+	inlineUserCfg, err := inlineUserConfig(ls.Spec.Config)
+	if err != nil {
+		return cfg, err
+	}
+
+	refUserCfg, err := common.ParseConfigRef(d, &ls, ls.Spec.ConfigRef, ConfigFilename)
+	if err != nil {
+		return cfg, err
+	}
+
+	defaults, err := defaultConfig(ls, ipFamily)
+	if err != nil {
+		return cfg, err
+	}
+
+	// user settings take precedence over the defaults
+	err = cfg.MergeWith(defaults, inlineUserCfg, refUserCfg)
+	return cfg, err
+}
+
+func inlineUserConfig(cfg *commonv1.Config) (*settings.CanonicalConfig, error) {
+	if cfg == nil {
+		cfg = &commonv1.Config{}
+	}
+	return settings.NewCanonicalConfigFrom(cfg.Data)
+}
+
+// defaultConfig binds the Logstash API to all the network interfaces, so that it can be probed and exposed through
+// the API Service.
+func defaultConfig(ls lsv1alpha1.Logstash, ipFamily corev1.IPFamily) (*settings.CanonicalConfig, error) {
+	v, err := version.Parse(ls.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	// http.host is deprecated in favor of api.http.host since 8.0
+	hostSetting := "http.host"
+	if v.GTE(version.MinFor(8, 0, 0)) {
+		hostSetting = "api.http.host"
+	}
+	return settings.MustCanonicalConfig(map[string]interface{}{
+		hostSetting: net.InAddrAnyFor(ipFamily).String(),
+	}), nil
+}
+
+// newPipelines returns the content of the pipelines.yml file, or nil if no pipeline is specified.
+func newPipelines(d driver.Interface, ls lsv1alpha1.Logstash) ([]byte, error) {
+	refPipelines, err := parsePipelinesRef(d, ls)
+	if err != nil {
+		return nil, err
+	}
+	if refPipelines != nil {
+		return refPipelines, nil
+	}
+
+	if len(ls.Spec.Pipelines) == 0 {
+		return nil, nil
+	}
+	pipelines := make([]map[string]interface{}, 0, len(ls.Spec.Pipelines))
+	for _, p := range ls.Spec.Pipelines {
+		pipelines = append(pipelines, p.Data)
+	}
+	return yaml.Marshal(pipelines)
+}
+
+// parsePipelinesRef retrieves the content of the secret referenced in `pipelinesRef`, sets up dynamic watches for
+// that secret, and checks it holds a list of pipelines.
+func parsePipelinesRef(d driver.Interface, ls lsv1alpha1.Logstash) ([]byte, error) {
+	nsn := k8s.ExtractNamespacedName(&ls)
+
+	// ensure watches match the referenced secret
+	var secretNames []string
+	if ls.Spec.PipelinesRef != nil && ls.Spec.PipelinesRef.SecretName != "" {
+		secretNames = append(secretNames, ls.Spec.PipelinesRef.SecretName)
+	}
+	if err := watches.WatchUserProvidedSecrets(nsn, d.DynamicWatches(), PipelinesRefWatchName(nsn), secretNames); err != nil {
+		return nil, err
+	}
+
+	if len(secretNames) == 0 {
+		// no secret referenced, nothing to do
+		return nil, nil
+	}
+
+	var secret corev1.Secret
+	if err := d.K8sClient().Get(context.Background(), types.NamespacedName{Namespace: ls.Namespace, Name: secretNames[0]}, &secret); err != nil {
+		// the secret may not exist (yet) in the cache, let's explicitly error out and retry later
+		return nil, err
+	}
+	data, exists := secret.Data[PipelinesFilename]
+	if !exists {
+		msg := fmt.Sprintf("unable to parse pipelinesRef secret %s/%s: missing key %s", ls.Namespace, secret.Name, PipelinesFilename)
+		d.Recorder().Event(&ls, corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
+		return nil, errors.New(msg)
+	}
+	var pipelines []map[string]interface{}
+	if err := yaml.Unmarshal(data, &pipelines); err != nil {
+		msg := fmt.Sprintf("unable to parse %s in pipelinesRef secret %s/%s", PipelinesFilename, ls.Namespace, secret.Name)
+		d.Recorder().Event(&ls, corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
+		return nil, errors.Wrap(err, msg)
+	}
+	return data, nil
+}
@@ -0,0 +1,253 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func Test_newConfig(t *testing.T) {
+	type args struct {
+		runtimeObjs []runtime.Object
+		ls          lsv1alpha1.Logstash
+		ipFamily    corev1.IPFamily
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no user config",
+			args: args{
+				runtimeObjs: nil,
+				ls:          lsWithConfigRef("", nil),
+				ipFamily:    corev1.IPv4Protocol,
+			},
+			want: `api:
+  http:
+    host: 0.0.0.0
+`,
+			wantErr: false,
+		},
+		{
+			name: "no user config before 8.0",
+			args: args{
+				runtimeObjs: nil,
+				ls: lsv1alpha1.Logstash{
+					Spec: lsv1alpha1.LogstashSpec{Version: "7.17.0"},
+				},
+				ipFamily: corev1.IPv6Protocol,
+			},
+			want: `http:
+  host: '::'
+`,
+			wantErr: false,
+		},
+		{
+			name: "inline user config",
+			args: args{
+				runtimeObjs: nil,
+				ls: lsWithConfigRef("", &commonv1.Config{Data: map[string]interface{}{
+					"pipeline.workers": 4,
+				}}),
+				ipFamily: corev1.IPv4Protocol,
+			},
+			want: `api:
+  http:
+    host: 0.0.0.0
+pipeline:
+  workers: 4
+`,
+			wantErr: false,
+		},
+		{
+			name: "with configRef",
+			args: args{
+				runtimeObjs: []runtime.Object{secretWithData("cfg", ConfigFilename, []byte("pipeline.workers: 4"))},
+				ls:          lsWithConfigRef("cfg", nil),
+				ipFamily:    corev1.IPv4Protocol,
+			},
+			want: `api:
+  http:
+    host: 0.0.0.0
+pipeline:
+  workers: 4
+`,
+			wantErr: false,
+		},
+		{
+			name: "user config takes precedence over the defaults",
+			args: args{
+				runtimeObjs: nil,
+				ls: lsWithConfigRef("", &commonv1.Config{Data: map[string]interface{}{
+					"api.http.host": "127.0.0.1",
+				}}),
+				ipFamily: corev1.IPv4Protocol,
+			},
+			want: `api:
+  http:
+    host: 127.0.0.1
+`,
+			wantErr: false,
+		},
+		{
+			name: "non existing configRef",
+			args: args{
+				ls:       lsWithConfigRef("cfg", nil),
+				ipFamily: corev1.IPv4Protocol,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ReconcileLogstash{
+				Client:         k8s.NewFakeClient(tt.args.runtimeObjs...),
+				recorder:       record.NewFakeRecorder(10),
+				dynamicWatches: watches.NewDynamicWatches(),
+			}
+
+			got, err := newConfig(&d, tt.args.ls, tt.args.ipFamily)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return // no point in checking the config contents
+			}
+			rendered, err := got.Render()
+			require.NoError(t, err)
+			if string(rendered) != tt.want {
+				t.Errorf("newConfig() got = \n%v\n, want \n%v\n", string(rendered), tt.want)
+			}
+		})
+	}
+}
+
+func Test_newPipelines(t *testing.T) {
+	pipelines := []commonv1.Config{
+		{Data: map[string]interface{}{"pipeline.id": "main", "config.string": "input {}"}},
+	}
+	tests := []struct {
+		name        string
+		runtimeObjs []runtime.Object
+		ls          lsv1alpha1.Logstash
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "no pipelines",
+			ls:   lsWithPipelinesRef("", nil),
+			want: "",
+		},
+		{
+			name: "inline pipelines",
+			ls:   lsWithPipelinesRef("", pipelines),
+			want: `- config.string: input {}
+  pipeline.id: main
+`,
+		},
+		{
+			name:        "with pipelinesRef",
+			runtimeObjs: []runtime.Object{secretWithData("pipelines", PipelinesFilename, []byte("- pipeline.id: ref\n"))},
+			ls:          lsWithPipelinesRef("pipelines", nil),
+			want:        "- pipeline.id: ref\n",
+		},
+		{
+			name:    "non existing pipelinesRef",
+			ls:      lsWithPipelinesRef("pipelines", nil),
+			wantErr: true,
+		},
+		{
+			name:        "pipelinesRef with a missing key",
+			runtimeObjs: []runtime.Object{secretWithData("pipelines", "other.yml", []byte("- pipeline.id: ref\n"))},
+			ls:          lsWithPipelinesRef("pipelines", nil),
+			wantErr:     true,
+		},
+		{
+			name:        "pipelinesRef with invalid content",
+			runtimeObjs: []runtime.Object{secretWithData("pipelines", PipelinesFilename, []byte("pipeline.id: not-a-list"))},
+			ls:          lsWithPipelinesRef("pipelines", nil),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ReconcileLogstash{
+				Client:         k8s.NewFakeClient(tt.runtimeObjs...),
+				recorder:       record.NewFakeRecorder(10),
+				dynamicWatches: watches.NewDynamicWatches(),
+			}
+
+			got, err := newPipelines(&d, tt.ls)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newPipelines() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			require.Equal(t, tt.want, string(got))
+			// the referenced secret is watched
+			require.Equal(t, tt.ls.Spec.PipelinesRef != nil, len(d.DynamicWatches().Secrets.Registrations()) == 1)
+		})
+	}
+}
+
+func secretWithData(name string, key string, data []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			key: data,
+		},
+	}
+}
+
+func lsWithConfigRef(name string, cfg *commonv1.Config) lsv1alpha1.Logstash {
+	ls := lsv1alpha1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ls",
+			Namespace: "ns",
+		},
+		Spec: lsv1alpha1.LogstashSpec{
+			Version: "8.0.0",
+			Config:  cfg,
+		},
+	}
+	if name != "" {
+		ls.Spec.ConfigRef = &commonv1.ConfigSource{SecretRef: commonv1.SecretRef{SecretName: name}}
+	}
+	return ls
+}
+
+func lsWithPipelinesRef(name string, pipelines []commonv1.Config) lsv1alpha1.Logstash {
+	ls := lsv1alpha1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ls",
+			Namespace: "ns",
+		},
+		Spec: lsv1alpha1.LogstashSpec{
+			Version:   "8.0.0",
+			Pipelines: pipelines,
+		},
+	}
+	if name != "" {
+		ls.Spec.PipelinesRef = &commonv1.ConfigSource{SecretRef: commonv1.SecretRef{SecretName: name}}
+	}
+	return ls
+}
@@ -0,0 +1,469 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.elastic.co/apm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	commonassociation "github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/driver"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/statefulset"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+)
+
+const (
+	controllerName = "logstash-controller"
+)
+
+var log = ulog.Log.WithName(controllerName)
+
+// Add creates a new Logstash Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	reconciler := newReconciler(mgr, params)
+	c, err := common.NewController(mgr, controllerName, reconciler, params)
+	if err != nil {
+		return err
+	}
+	return addWatches(c, reconciler)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileLogstash {
+	client := mgr.GetClient()
+	return &ReconcileLogstash{
+		Client:           client,
+		recorder:         mgr.GetEventRecorderFor(controllerName),
+		dynamicWatches:   watches.NewDynamicWatches(),
+		configMapWatches: watches.NewDynamicEnqueueRequest(),
+		statusWriter:     common.NewStatusWriter(params.StatusFlushInterval),
+		Parameters:       params,
+	}
+}
+
+func addWatches(c controller.Controller, r *ReconcileLogstash) error {
+	// Watch for changes to Logstash
+	if err := c.Watch(&source.Kind{Type: &lsv1alpha1.Logstash{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// Watch StatefulSets
+	if err := c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &lsv1alpha1.Logstash{},
+	}); err != nil {
+		return err
+	}
+
+	// Watch Pods, to ensure `status.version` and version upgrades are correctly reconciled on any change.
+	// Watching StatefulSets only may lead to missing some events.
+	if err := watches.WatchPods(c, NameLabelName); err != nil {
+		return err
+	}
+
+	// Watch services
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &lsv1alpha1.Logstash{},
+	}); err != nil {
+		return err
+	}
+
+	// Watch owned and soft-owned secrets
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &lsv1alpha1.Logstash{},
+	}); err != nil {
+		return err
+	}
+	if err := watches.WatchSoftOwnedSecrets(c, lsv1alpha1.Kind); err != nil {
+		return err
+	}
+
+	// Dynamically watch the ConfigMaps mounted in the Pods, which may hold pipeline definitions
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, r.configMapWatches); err != nil {
+		return err
+	}
+
+	// Dynamically watch referenced secrets to connect to Elasticsearch
+	return c.Watch(&source.Kind{Type: &corev1.Secret{}}, r.dynamicWatches.Secrets)
+}
+
+var _ reconcile.Reconciler = &ReconcileLogstash{}
+
+// ReconcileLogstash reconciles a Logstash object
+type ReconcileLogstash struct {
+	k8s.Client
+	operator.Parameters
+	recorder         record.EventRecorder
+	dynamicWatches   watches.DynamicWatches
+	configMapWatches *watches.DynamicEnqueueRequest
+	// statusWriter coalesces successive status updates of the same Logstash.
+	statusWriter *common.StatusWriter
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+func (r *ReconcileLogstash) K8sClient() k8s.Client {
+	return r.Client
+}
+
+func (r *ReconcileLogstash) DynamicWatches() watches.DynamicWatches {
+	return r.dynamicWatches
+}
+
+func (r *ReconcileLogstash) Recorder() record.EventRecorder {
+	return r.recorder
+}
+
+var _ driver.Interface = &ReconcileLogstash{}
+
+// Reconcile reads that state of the cluster for a Logstash object and makes changes based on the state read and what is
+// in the Logstash.Spec
+func (r *ReconcileLogstash) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "ls_name", &r.iteration)()
+	tx, ctx := tracing.NewTransaction(ctx, r.Tracer, request.NamespacedName, "logstash")
+	defer tracing.EndTransaction(tx)
+
+	// retrieve the Logstash object
+	var ls lsv1alpha1.Logstash
+	if err := association.FetchWithAssociations(ctx, r.Client, request, &ls); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, r.onDelete(request.NamespacedName)
+		}
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	if common.IsUnmanaged(&ls) {
+		log.Info("Object is currently not managed by this controller. Skipping reconciliation", "namespace", ls.Namespace, "ls_name", ls.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if !r.ResourceSelector.Matches(&ls) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", ls.Namespace, "ls_name", ls.Name)
+		return reconcile.Result{}, nil
+	}
+
+	// Logstash will be deleted nothing to do other than remove the watches
+	if ls.IsMarkedForDeletion() {
+		return reconcile.Result{}, r.onDelete(k8s.ExtractNamespacedName(&ls))
+	}
+
+	if !association.IsConfiguredIfSet(&ls, r.recorder) {
+		return reconcile.Result{}, nil
+	}
+
+	// main reconciliation logic
+	return r.doReconcile(ctx, ls)
+}
+
+func (r *ReconcileLogstash) doReconcile(ctx context.Context, ls lsv1alpha1.Logstash) (reconcile.Result, error) {
+	// Run validation in case the webhook is disabled
+	if err := r.validate(ctx, ls); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.reconcileServices(ctx, ls); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	lsVersion, err := version.Parse(ls.Spec.Version)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	logger := log.WithValues("namespace", ls.Namespace, "ls_name", ls.Name)
+	if !association.AllowVersion(lsVersion, ls.Associated(), logger, r.recorder) {
+		return reconcile.Result{}, nil // will eventually retry once updated
+	}
+
+	configSecret, err := reconcileConfig(r, ls, r.IPFamily, r.ServerSideApply)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// build a hash of various inputs to rotate Pods on any change
+	configHash, err := r.buildConfigHash(ls, configSecret)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("build config hash: %w", err)
+	}
+
+	sset, err := r.reconcileStatefulSet(ctx, ls, configHash)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("reconcile statefulset: %w", err)
+	}
+
+	requeueAfter, err := r.updateStatus(ls, sset)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("updating status: %w", err)
+	}
+
+	// requeue to flush a deferred status update
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *ReconcileLogstash) validate(ctx context.Context, ls lsv1alpha1.Logstash) error {
+	span, vctx := apm.StartSpan(ctx, "validate", tracing.SpanTypeApp)
+	defer span.End()
+
+	if err := ls.ValidateCreate(); err != nil {
+		log.Error(err, "Validation failed")
+		k8s.EmitErrorEvent(r.recorder, err, &ls, events.EventReasonValidation, err.Error())
+		return tracing.CaptureError(vctx, err)
+	}
+
+	return nil
+}
+
+// reconcileServices reconciles the Service exposing the Logstash API and the Services defined in the spec, and deletes
+// the Services which are not defined anymore.
+func (r *ReconcileLogstash) reconcileServices(ctx context.Context, ls lsv1alpha1.Logstash) error {
+	expected := append([]corev1.Service{*NewAPIService(ls)}, NewUserServices(ls)...)
+	expectedNames := make(map[string]struct{}, len(expected))
+	for _, svc := range expected {
+		svc := svc
+		if _, err := common.ReconcileService(ctx, r.Client, &svc, &ls, r.ServerSideApply); err != nil {
+			return err
+		}
+		expectedNames[svc.Name] = struct{}{}
+	}
+
+	var actual corev1.ServiceList
+	if err := r.Client.List(context.Background(), &actual, client.InNamespace(ls.Namespace), client.MatchingLabels(labels(ls.Name))); err != nil {
+		return err
+	}
+	for i, svc := range actual.Items {
+		if _, exists := expectedNames[svc.Name]; exists || !metav1.IsControlledBy(&actual.Items[i], &ls) {
+			continue
+		}
+		if err := r.Client.Delete(context.Background(), &actual.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewAPIService returns the Service exposing the Logstash API, which is also the governing Service of the StatefulSet.
+func NewAPIService(ls lsv1alpha1.Logstash) *corev1.Service {
+	svc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ls.Namespace,
+			Name:      APIService(ls.Name),
+		},
+	}
+
+	labels := labels(ls.Name)
+	ports := []corev1.ServicePort{
+		{
+			Name:     "http",
+			Protocol: corev1.ProtocolTCP,
+			Port:     APIPort,
+		},
+	}
+
+	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
+}
+
+// NewUserServices returns the Services defined in the Logstash spec, selecting the Logstash Pods.
+func NewUserServices(ls lsv1alpha1.Logstash) []corev1.Service {
+	services := make([]corev1.Service, 0, len(ls.Spec.Services))
+	for _, s := range ls.Spec.Services {
+		svc := corev1.Service{
+			ObjectMeta: *s.Service.ObjectMeta.DeepCopy(),
+			Spec:       *s.Service.Spec.DeepCopy(),
+		}
+		svc.ObjectMeta.Namespace = ls.Namespace
+		svc.ObjectMeta.Name = UserService(ls.Name, s.Name)
+
+		labels := labels(ls.Name)
+		services = append(services, *defaults.SetServiceDefaults(&svc, labels, labels, nil))
+	}
+	return services
+}
+
+// buildConfigHash builds a hash of the configuration, of the associations and of the ConfigMaps mounted in the Pods,
+// to rotate the Pods on any change. The ConfigMaps are dynamically watched.
+func (r *ReconcileLogstash) buildConfigHash(ls lsv1alpha1.Logstash, configSecret corev1.Secret) (string, error) {
+	configHash := sha256.New224()
+
+	// - in the Logstash configuration and pipelines files content
+	_, _ = configHash.Write(configSecret.Data[ConfigFilename])
+	_, _ = configHash.Write(configSecret.Data[PipelinesFilename])
+
+	// - in the Elasticsearch credentials and TLS certificates
+	if err := commonassociation.WriteAssocsToConfigHash(r.Client, ls.GetAssociations(), configHash); err != nil {
+		return "", err
+	}
+
+	// - in the ConfigMaps mounted in the Pods, which may hold pipeline definitions
+	configMaps := mountedConfigMaps(ls)
+	if err := r.watchConfigMaps(ls, configMaps); err != nil {
+		return "", err
+	}
+	for _, name := range configMaps {
+		var cm corev1.ConfigMap
+		err := r.Client.Get(context.Background(), types.NamespacedName{Namespace: ls.Namespace, Name: name}, &cm)
+		if apierrors.IsNotFound(err) {
+			// the Pods cannot start until the ConfigMap is created, which triggers a new reconciliation
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		keys := make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_, _ = configHash.Write([]byte(k))
+			_, _ = configHash.Write([]byte(cm.Data[k]))
+		}
+	}
+
+	return fmt.Sprintf("%x", configHash.Sum(nil)), nil
+}
+
+// mountedConfigMaps returns the sorted names of the ConfigMaps mounted as volumes in the Logstash Pods.
+func mountedConfigMaps(ls lsv1alpha1.Logstash) []string {
+	var names []string
+	for _, v := range ls.Spec.PodTemplate.Spec.Volumes {
+		if v.ConfigMap != nil {
+			names = append(names, v.ConfigMap.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func configMapsWatchName(ls types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-configmaps", ls.Namespace, ls.Name)
+}
+
+func (r *ReconcileLogstash) watchConfigMaps(ls lsv1alpha1.Logstash, configMaps []string) error {
+	nsn := k8s.ExtractNamespacedName(&ls)
+	if len(configMaps) == 0 {
+		r.configMapWatches.RemoveHandlerForKey(configMapsWatchName(nsn))
+		return nil
+	}
+	watched := make([]types.NamespacedName, 0, len(configMaps))
+	for _, name := range configMaps {
+		watched = append(watched, types.NamespacedName{Namespace: ls.Namespace, Name: name})
+	}
+	return r.configMapWatches.AddHandler(watches.NamedWatch{
+		Name:    configMapsWatchName(nsn),
+		Watched: watched,
+		Watcher: nsn,
+	})
+}
+
+func (r *ReconcileLogstash) reconcileStatefulSet(
+	ctx context.Context,
+	ls lsv1alpha1.Logstash,
+	configHash string,
+) (appsv1.StatefulSet, error) {
+	span, _ := apm.StartSpan(ctx, "reconcile_statefulset", tracing.SpanTypeApp)
+	defer span.End()
+
+	return statefulset.Reconcile(r.K8sClient(), statefulset.New(statefulSetParams(ls, configHash)), &ls, r.RestrictedSecurityContext)
+}
+
+func statefulSetParams(ls lsv1alpha1.Logstash, configHash string) statefulset.Params {
+	podSpec := newPodSpec(ls, configHash)
+
+	ssetLabels := labels(ls.Name)
+
+	podLabels := maps.Merge(labels(ls.Name), versionLabels(ls))
+	// merge with user-provided labels
+	podSpec.Labels = maps.MergePreservingExistingKeys(podSpec.Labels, podLabels)
+
+	return statefulset.Params{
+		Name:            StatefulSet(ls.Name),
+		Namespace:       ls.Namespace,
+		ServiceName:     APIService(ls.Name),
+		Replicas:        ls.Spec.Count,
+		Selector:        ssetLabels,
+		Labels:          ssetLabels,
+		PodTemplateSpec: podSpec,
+	}
+}
+
+// updateStatus writes the status of the Logstash resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func (r *ReconcileLogstash) updateStatus(ls lsv1alpha1.Logstash, sset appsv1.StatefulSet) (time.Duration, error) {
+	pods, err := k8s.PodsMatchingLabels(r.K8sClient(), ls.Namespace, map[string]string{NameLabelName: ls.Name})
+	if err != nil {
+		return 0, err
+	}
+	deploymentStatus, err := common.StatefulSetStatus(ls.Status.DeploymentStatus, sset, pods, versionLabelName)
+	if err != nil {
+		return 0, err
+	}
+	newStatus := lsv1alpha1.LogstashStatus{
+		DeploymentStatus:  deploymentStatus,
+		AssociationStatus: ls.Status.AssociationStatus,
+	}
+
+	if reflect.DeepEqual(newStatus, ls.Status) {
+		return 0, nil // nothing to do
+	}
+	if newStatus.IsDegraded(ls.Status.DeploymentStatus) {
+		r.recorder.Event(&ls, corev1.EventTypeWarning, events.EventReasonUnhealthy, "Logstash health degraded")
+	}
+	immediate := newStatus.Health != ls.Status.Health
+	ls.Status = newStatus
+	requeueAfter, err := r.statusWriter.Write(r.Client, &ls, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
+		"iteration", atomic.LoadUint64(&r.iteration),
+		"namespace", ls.Namespace,
+		"ls_name", ls.Name,
+		"status", newStatus,
+	)
+	return 0, nil
+}
+
+func (r *ReconcileLogstash) onDelete(obj types.NamespacedName) error {
+	// Clean up watches set on the configRef and pipelinesRef secrets
+	r.dynamicWatches.Secrets.RemoveHandlerForKey(common.ConfigRefWatchName(obj))
+	r.dynamicWatches.Secrets.RemoveHandlerForKey(PipelinesRefWatchName(obj))
+	// same for the mounted ConfigMaps
+	r.configMapWatches.RemoveHandlerForKey(configMapsWatchName(obj))
+	r.statusWriter.Forget(obj)
+	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, lsv1alpha1.Kind)
+}
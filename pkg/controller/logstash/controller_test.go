@@ -0,0 +1,277 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+var nsnFixture = types.NamespacedName{
+	Namespace: "ns",
+	Name:      "test-resource",
+}
+var lsFixture = lsv1alpha1.Logstash{
+	ObjectMeta: metav1.ObjectMeta{
+		Namespace: nsnFixture.Namespace,
+		Name:      nsnFixture.Name,
+	},
+	Spec: lsv1alpha1.LogstashSpec{
+		Version: "8.0.0",
+		Count:   1,
+		Pipelines: []commonv1.Config{
+			{Data: map[string]interface{}{
+				"pipeline.id":   "main",
+				"config.string": "input { beats { port => 5044 } } output { stdout {} }",
+			}},
+		},
+		Services: []lsv1alpha1.LogstashService{
+			{
+				Name: "beats",
+				Service: commonv1.ServiceTemplate{
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Name: "beats", Protocol: corev1.ProtocolTCP, Port: 5044}},
+					},
+				},
+			},
+		},
+	},
+}
+
+func newTestReconciler(objs ...runtime.Object) ReconcileLogstash {
+	return ReconcileLogstash{
+		Client:           k8s.NewFakeClient(objs...),
+		recorder:         record.NewFakeRecorder(10),
+		dynamicWatches:   watches.NewDynamicWatches(),
+		configMapWatches: watches.NewDynamicEnqueueRequest(),
+	}
+}
+
+func TestReconcileLogstash_Reconcile(t *testing.T) {
+	timeFixture := metav1.Now()
+	tests := []struct {
+		name        string
+		reconciler  ReconcileLogstash
+		pre         func(r ReconcileLogstash)
+		post        func(r ReconcileLogstash)
+		wantRequeue bool
+		wantErr     bool
+	}{
+		{
+			name:       "Resource not found",
+			reconciler: newTestReconciler(),
+			pre: func(r ReconcileLogstash) {
+				// simulate watches for a configRef and a pipelinesRef that have been added during a previous reconciliation
+				require.NoError(t, watches.WatchUserProvidedSecrets(nsnFixture, r.DynamicWatches(), common.ConfigRefWatchName(nsnFixture), []string{"user-config-secret"}))
+				require.NoError(t, watches.WatchUserProvidedSecrets(nsnFixture, r.DynamicWatches(), PipelinesRefWatchName(nsnFixture), []string{"user-pipelines-secret"}))
+				require.NotEmpty(t, r.DynamicWatches().Secrets.Registrations())
+			},
+			post: func(r ReconcileLogstash) {
+				// watches should have been cleared
+				require.Empty(t, r.DynamicWatches().Secrets.Registrations())
+			},
+			wantErr: false,
+		},
+		{
+			name: "Resource marked for deletion",
+			reconciler: newTestReconciler(&lsv1alpha1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{Name: nsnFixture.Name, Namespace: nsnFixture.Namespace, DeletionTimestamp: &timeFixture},
+			}),
+			pre: func(r ReconcileLogstash) {
+				require.NoError(t, watches.WatchUserProvidedSecrets(nsnFixture, r.DynamicWatches(), PipelinesRefWatchName(nsnFixture), []string{"user-pipelines-secret"}))
+				require.NotEmpty(t, r.DynamicWatches().Secrets.Registrations())
+			},
+			post: func(r ReconcileLogstash) {
+				// watches should have been cleared
+				require.Empty(t, r.DynamicWatches().Secrets.Registrations())
+			},
+			wantErr: false,
+		},
+		{
+			name: "Resource is unmanaged",
+			reconciler: newTestReconciler(&lsv1alpha1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      nsnFixture.Name,
+					Namespace: nsnFixture.Namespace,
+					Annotations: map[string]string{
+						common.ManagedAnnotation: "false",
+					},
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "validates on reconcile",
+			reconciler: newTestReconciler(&lsv1alpha1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      nsnFixture.Name,
+					Namespace: nsnFixture.Namespace,
+				},
+				Spec: lsv1alpha1.LogstashSpec{
+					Version: "7.10.0", // unsupported version
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "Association specified but not configured (yet)",
+			reconciler: newTestReconciler(&lsv1alpha1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      nsnFixture.Name,
+					Namespace: nsnFixture.Namespace,
+				},
+				Spec: lsv1alpha1.LogstashSpec{
+					Version:          "8.0.0",
+					ElasticsearchRef: commonv1.ObjectSelector{Name: "es", Namespace: "ns"},
+				},
+			}),
+			post: func(r ReconcileLogstash) {
+				e := <-r.recorder.(*record.FakeRecorder).Events
+				require.Equal(t, "Warning AssociationError Association backend for elasticsearch is not configured", e)
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Happy path: first reconciliation",
+			reconciler: newTestReconciler(&lsFixture),
+			post: func(r ReconcileLogstash) {
+				// API service
+				var svc corev1.Service
+				err := r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls-api"}, &svc)
+				require.NoError(t, err)
+				require.Equal(t, int32(APIPort), svc.Spec.Ports[0].Port)
+
+				// user defined service
+				err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls-beats"}, &svc)
+				require.NoError(t, err)
+				require.Equal(t, int32(5044), svc.Spec.Ports[0].Port)
+				require.Equal(t, labels(nsnFixture.Name), svc.Spec.Selector)
+
+				// should create a secret for the configuration and the pipelines
+				var config corev1.Secret
+				err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls-config"}, &config)
+				require.NoError(t, err)
+				require.Contains(t, string(config.Data[ConfigFilename]), "host:")
+				require.Contains(t, string(config.Data[PipelinesFilename]), "pipeline.id: main")
+
+				// should create a 1-replica statefulset
+				var sset appsv1.StatefulSet
+				err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls"}, &sset)
+				require.NoError(t, err)
+				require.Equal(t, int32(1), *sset.Spec.Replicas)
+				require.Equal(t, "test-resource-ls-api", sset.Spec.ServiceName)
+				// with the config hash label set
+				require.NotEmpty(t, sset.Spec.Template.Labels[configHashLabel])
+			},
+			wantErr: false,
+		},
+		{
+			name: "Services removed from the spec are deleted",
+			reconciler: newTestReconciler(
+				&lsFixture,
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns",
+						Name:      "test-resource-ls-removed",
+						Labels:    labels(nsnFixture.Name),
+						OwnerReferences: []metav1.OwnerReference{
+							{Kind: lsv1alpha1.Kind, APIVersion: lsv1alpha1.GroupVersion.String(), Name: nsnFixture.Name, Controller: &[]bool{true}[0]},
+						},
+					},
+				},
+			),
+			post: func(r ReconcileLogstash) {
+				var svc corev1.Service
+				err := r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls-removed"}, &svc)
+				require.True(t, apierrors.IsNotFound(err))
+				err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "test-resource-ls-beats"}, &svc)
+				require.NoError(t, err)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pre != nil {
+				tt.pre(tt.reconciler)
+			}
+			got, err := tt.reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: nsnFixture})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Requeue != tt.wantRequeue {
+				t.Errorf("Reconcile() got = %v, wantRequeue %v", got, tt.wantRequeue)
+			}
+			if tt.post != nil {
+				tt.post(tt.reconciler)
+			}
+		})
+	}
+}
+
+func TestReconcileLogstash_buildConfigHash(t *testing.T) {
+	lsWithConfigMap := *lsFixture.DeepCopy()
+	lsWithConfigMap.Spec.PodTemplate.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "pipelines",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "pipelines"}},
+			},
+		},
+	}
+	configMap := func(content string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nsnFixture.Namespace, Name: "pipelines"},
+			Data:       map[string]string{"main.conf": content},
+		}
+	}
+	cfgFixture := corev1.Secret{
+		Data: map[string][]byte{
+			ConfigFilename: []byte("api.http.host: 0.0.0.0"),
+		},
+	}
+
+	hash := func(ls lsv1alpha1.Logstash, objs ...runtime.Object) string {
+		r := newTestReconciler(objs...)
+		h, err := r.buildConfigHash(ls, cfgFixture)
+		require.NoError(t, err)
+		return h
+	}
+
+	// the hash changes with the content of the mounted ConfigMaps
+	noConfigMap := hash(lsFixture)
+	withConfigMap := hash(lsWithConfigMap, configMap("input {}"))
+	require.NotEqual(t, noConfigMap, withConfigMap)
+	require.Equal(t, withConfigMap, hash(lsWithConfigMap, configMap("input {}")))
+	require.NotEqual(t, withConfigMap, hash(lsWithConfigMap, configMap("input { beats {} }")))
+	// a missing ConfigMap is ignored
+	require.Equal(t, noConfigMap, hash(lsWithConfigMap))
+
+	// the ConfigMaps are watched until they are not mounted anymore
+	r := newTestReconciler(configMap("input {}"))
+	_, err := r.buildConfigHash(lsWithConfigMap, cfgFixture)
+	require.NoError(t, err)
+	require.Equal(t, []string{configMapsWatchName(nsnFixture)}, r.configMapWatches.Registrations())
+	_, err = r.buildConfigHash(lsFixture, cfgFixture)
+	require.NoError(t, err)
+	require.Empty(t, r.configMapWatches.Registrations())
+}
@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+)
+
+const (
+	// NameLabelName used to represent a Logstash in k8s resources
+	NameLabelName = "logstash.k8s.elastic.co/name"
+
+	// versionLabelName used to propagate Logstash version from the spec to the pods
+	versionLabelName = "logstash.k8s.elastic.co/version"
+
+	// Type represents the Logstash type
+	Type = "logstash"
+)
+
+// labels constructs a new set of labels for a Logstash pod
+func labels(lsName string) map[string]string {
+	return map[string]string{
+		NameLabelName:        lsName,
+		common.TypeLabelName: Type,
+	}
+}
+
+func versionLabels(ls lsv1alpha1.Logstash) map[string]string {
+	return map[string]string{
+		versionLabelName: ls.Spec.Version,
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/name"
+)
+
+const (
+	configSuffix = "config"
+)
+
+// LSNamer is a Namer that is configured with the defaults for resources related to a Logstash resource.
+var LSNamer = name.NewNamer("ls")
+
+// APIService returns the name of the Service exposing the Logstash API.
+func APIService(lsName string) string {
+	return LSNamer.Suffix(lsName, lsv1alpha1.APIServiceName)
+}
+
+// UserService returns the name of a Service defined in the Logstash spec.
+func UserService(lsName string, svcName string) string {
+	return LSNamer.Suffix(lsName, svcName)
+}
+
+func StatefulSet(lsName string) string {
+	return LSNamer.Suffix(lsName)
+}
+
+func Config(lsName string) string {
+	return LSNamer.Suffix(lsName, configSuffix)
+}
@@ -0,0 +1,191 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/container"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
+)
+
+const (
+	APIPort         = 9600
+	configHashLabel = "logstash.k8s.elastic.co/config-hash"
+
+	// ConfigVolumeMountPath is the Logstash config directory, initialized from the default configuration of the
+	// Logstash image and the generated configuration files.
+	ConfigVolumeName                   = "config"
+	ConfigVolumeMountPath              = "/usr/share/logstash/config"
+	InitContainerConfigVolumeMountPath = "/mnt/elastic-internal/logstash-config-local"
+
+	// InternalConfigVolumeName is a volume which contains the generated configuration.
+	InternalConfigVolumeName      = "elastic-internal-logstash-config"
+	InternalConfigVolumeMountPath = "/mnt/elastic-internal/logstash-config"
+
+	DataVolumeName      = "logstash-data"
+	DataVolumeMountPath = "/usr/share/logstash/data"
+
+	ESCertsPath = "/mnt/elastic-internal/es-certs"
+
+	InitConfigContainerName = "logstash-internal-init-config"
+
+	// InitConfigScript copies the default configuration of the Logstash image along with the generated configuration
+	// files in the config directory, which must be writable for the Logstash Docker entrypoint.
+	InitConfigScript = `#!/usr/bin/env bash
+set -eux
+
+echo "Setup Logstash configuration"
+
+cp -f ` + ConfigVolumeMountPath + `/* ` + InitContainerConfigVolumeMountPath + `/
+cp -f ` + InternalConfigVolumeMountPath + `/* ` + InitContainerConfigVolumeMountPath + `/
+
+echo "Logstash configuration successfully prepared."
+`
+
+	// environment variables exposing the Elasticsearch association to the pipelines
+	ElasticsearchHostsEnv                   = "ELASTICSEARCH_HOSTS"
+	ElasticsearchUsernameEnv                = "ELASTICSEARCH_USERNAME"
+	ElasticsearchPasswordEnv                = "ELASTICSEARCH_PASSWORD" //nolint:gosec
+	ElasticsearchSSLCertificateAuthorityEnv = "ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY"
+)
+
+var (
+	DefaultMemoryLimits = resource.MustParse("2Gi")
+	DefaultResources    = corev1.ResourceRequirements{
+		Requests: map[corev1.ResourceName]resource.Quantity{
+			corev1.ResourceMemory: DefaultMemoryLimits,
+		},
+		Limits: map[corev1.ResourceName]resource.Quantity{
+			corev1.ResourceMemory: DefaultMemoryLimits,
+		},
+	}
+
+	// ConfigSharedVolume contains the Logstash config/ directory, it's an empty volume where the required configuration
+	// is initialized by the init container.
+	ConfigSharedVolume = volume.SharedVolume{
+		VolumeName:             ConfigVolumeName,
+		InitContainerMountPath: InitContainerConfigVolumeMountPath,
+		ContainerMountPath:     ConfigVolumeMountPath,
+	}
+)
+
+// readinessProbe is the readiness probe for the Logstash container
+func readinessProbe() corev1.Probe {
+	return corev1.Probe{
+		FailureThreshold:    3,
+		InitialDelaySeconds: 30,
+		PeriodSeconds:       10,
+		SuccessThreshold:    1,
+		TimeoutSeconds:      5,
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Port:   intstr.FromInt(APIPort),
+				Path:   "/",
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+	}
+}
+
+func configVolume(ls lsv1alpha1.Logstash) volume.SecretVolume {
+	return volume.NewSecretVolumeWithMountPath(Config(ls.Name), InternalConfigVolumeName, InternalConfigVolumeMountPath)
+}
+
+// initConfigContainer returns an init container preparing the Logstash config directory.
+func initConfigContainer(ls lsv1alpha1.Logstash) corev1.Container {
+	privileged := false
+
+	return corev1.Container{
+		// Image will be inherited from pod template defaults
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            InitConfigContainerName,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &privileged,
+		},
+		Command: []string{"/usr/bin/env", "bash", "-c", InitConfigScript},
+		VolumeMounts: []corev1.VolumeMount{
+			ConfigSharedVolume.InitContainerVolumeMount(),
+			configVolume(ls).VolumeMount(),
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+				corev1.ResourceCPU:    resource.MustParse("0.1"),
+			},
+			Limits: map[corev1.ResourceName]resource.Quantity{
+				// Memory limit should be at least 12582912 when running with CRI-O
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+				corev1.ResourceCPU:    resource.MustParse("0.1"),
+			},
+		},
+	}
+}
+
+func newPodSpec(ls lsv1alpha1.Logstash, configHash string) corev1.PodTemplateSpec {
+	// ensure the Pod gets rotated on config change
+	labels := map[string]string{configHashLabel: configHash}
+
+	defaultContainerPorts := []corev1.ContainerPort{
+		{Name: "http", ContainerPort: int32(APIPort), Protocol: corev1.ProtocolTCP},
+	}
+
+	cfgVolume := configVolume(ls)
+	dataVolume := volume.NewEmptyDirVolume(DataVolumeName, DataVolumeMountPath)
+
+	builder := defaults.NewPodTemplateBuilder(ls.Spec.PodTemplate, lsv1alpha1.LogstashContainerName).
+		WithLabels(labels).
+		WithResources(DefaultResources).
+		WithDockerImage(ls.Spec.Image, container.ImageRepository(container.LogstashImage, ls.Spec.Version)).
+		WithReadinessProbe(readinessProbe()).
+		WithPorts(defaultContainerPorts).
+		WithVolumes(cfgVolume.Volume(), ConfigSharedVolume.Volume(), dataVolume.Volume()).
+		WithVolumeMounts(ConfigSharedVolume.VolumeMount(), dataVolume.VolumeMount()).
+		WithInitContainers(initConfigContainer(ls)).
+		WithInitContainerDefaults()
+
+	builder = withElasticsearchAssociation(builder, ls)
+
+	return builder.PodTemplate
+}
+
+// withElasticsearchAssociation exposes the URL, the credentials and the CA certificate of the associated Elasticsearch
+// cluster to the Logstash pipelines through environment variables.
+func withElasticsearchAssociation(builder *defaults.PodTemplateBuilder, ls lsv1alpha1.Logstash) *defaults.PodTemplateBuilder {
+	assocConf := ls.AssociationConf()
+	if !assocConf.IsConfigured() {
+		return builder
+	}
+
+	env := []corev1.EnvVar{{Name: ElasticsearchHostsEnv, Value: assocConf.GetURL()}}
+	if !assocConf.NoAuthRequired() {
+		env = append(env,
+			corev1.EnvVar{Name: ElasticsearchUsernameEnv, Value: assocConf.GetAuthSecretKey()},
+			corev1.EnvVar{Name: ElasticsearchPasswordEnv, ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: assocConf.GetAuthSecretName()},
+					Key:                  assocConf.GetAuthSecretKey(),
+				},
+			}},
+		)
+	}
+	builder = builder.WithEnv(env...)
+
+	if !assocConf.CAIsConfigured() {
+		return builder
+	}
+	vol := volume.NewSecretVolumeWithMountPath(assocConf.GetCASecretName(), "es-certs", ESCertsPath)
+	return builder.
+		WithEnv(corev1.EnvVar{Name: ElasticsearchSSLCertificateAuthorityEnv, Value: filepath.Join(ESCertsPath, certificates.CAFileName)}).
+		WithVolumes(vol.Volume()).
+		WithVolumeMounts(vol.VolumeMount())
+}
@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/container"
+)
+
+func Test_newPodSpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		ls         func() lsv1alpha1.Logstash
+		assertions func(pod corev1.PodTemplateSpec)
+	}{
+		{
+			name: "defaults",
+			ls: func() lsv1alpha1.Logstash {
+				return *lsFixture.DeepCopy()
+			},
+			assertions: func(pod corev1.PodTemplateSpec) {
+				require.Equal(t, "hash", pod.Labels[configHashLabel])
+				require.Len(t, pod.Spec.Containers, 1)
+				c := pod.Spec.Containers[0]
+				require.Equal(t, container.ImageRepository(container.LogstashImage, "8.0.0"), c.Image)
+				require.Equal(t, DefaultResources, c.Resources)
+				require.Equal(t, []corev1.ContainerPort{{Name: "http", ContainerPort: APIPort, Protocol: corev1.ProtocolTCP}}, c.Ports)
+				require.Empty(t, c.Env)
+				// the config directory is prepared by the init container
+				require.Len(t, pod.Spec.InitContainers, 1)
+				require.Equal(t, InitConfigContainerName, pod.Spec.InitContainers[0].Name)
+				require.Equal(t, c.Image, pod.Spec.InitContainers[0].Image)
+				require.Len(t, pod.Spec.Volumes, 3)
+			},
+		},
+		{
+			name: "with a custom image",
+			ls: func() lsv1alpha1.Logstash {
+				ls := *lsFixture.DeepCopy()
+				ls.Spec.Image = "my-custom-image:1.0.0"
+				return ls
+			},
+			assertions: func(pod corev1.PodTemplateSpec) {
+				require.Equal(t, "my-custom-image:1.0.0", pod.Spec.Containers[0].Image)
+			},
+		},
+		{
+			name: "with an Elasticsearch association",
+			ls: func() lsv1alpha1.Logstash {
+				ls := *lsFixture.DeepCopy()
+				ls.Spec.ElasticsearchRef = commonv1.ObjectSelector{Name: "es"}
+				ls.SetAssociationConf(&commonv1.AssociationConf{
+					AuthSecretName: "test-resource-logstash-user",
+					AuthSecretKey:  "ns-test-resource-logstash-user",
+					CACertProvided: true,
+					CASecretName:   "test-resource-logstash-es-ca",
+					URL:            "https://es-es-http.ns.svc:9200",
+				})
+				return ls
+			},
+			assertions: func(pod corev1.PodTemplateSpec) {
+				c := pod.Spec.Containers[0]
+				require.Equal(t, []corev1.EnvVar{
+					{Name: ElasticsearchHostsEnv, Value: "https://es-es-http.ns.svc:9200"},
+					{Name: ElasticsearchUsernameEnv, Value: "ns-test-resource-logstash-user"},
+					{Name: ElasticsearchPasswordEnv, ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "test-resource-logstash-user"},
+							Key:                  "ns-test-resource-logstash-user",
+						},
+					}},
+					{Name: ElasticsearchSSLCertificateAuthorityEnv, Value: "/mnt/elastic-internal/es-certs/ca.crt"},
+				}, c.Env)
+				require.Len(t, pod.Spec.Volumes, 4)
+				require.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: "es-certs", ReadOnly: true, MountPath: ESCertsPath})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.assertions(newPodSpec(tt.ls(), "hash"))
+		})
+	}
+}
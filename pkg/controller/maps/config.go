@@ -34,7 +34,7 @@ func configSecretVolume(ems emsv1alpha1.ElasticMapsServer) volume.SecretVolume {
 	return volume.NewSecretVolume(Config(ems.Name), "config", ConfigMountPath, ConfigFilename, 0444)
 }
 
-func reconcileConfig(driver driver.Interface, ems emsv1alpha1.ElasticMapsServer, ipFamily corev1.IPFamily) (corev1.Secret, error) {
+func reconcileConfig(driver driver.Interface, ems emsv1alpha1.ElasticMapsServer, ipFamily corev1.IPFamily, serverSideApply bool) (corev1.Secret, error) {
 	cfg, err := newConfig(driver, ems, ipFamily)
 	if err != nil {
 		return corev1.Secret{}, err
@@ -57,7 +57,7 @@ func reconcileConfig(driver driver.Interface, ems emsv1alpha1.ElasticMapsServer,
 		},
 	}
 
-	return reconciler.ReconcileSecret(driver.K8sClient(), expectedConfigSecret, &ems)
+	return reconciler.ReconcileSecret(driver.K8sClient(), expectedConfigSecret, &ems, serverSideApply)
 }
 
 func newConfig(d driver.Interface, ems emsv1alpha1.ElasticMapsServer, ipFamily corev1.IPFamily) (*settings.CanonicalConfig, error) {
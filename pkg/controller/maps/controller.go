@@ -67,6 +67,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileMa
 		Client:         client,
 		recorder:       mgr.GetEventRecorderFor(controllerName),
 		dynamicWatches: watches.NewDynamicWatches(),
+		statusWriter:   common.NewStatusWriter(params.StatusFlushInterval),
 		licenseChecker: license.NewLicenseChecker(client, params.OperatorNamespace),
 		Parameters:     params,
 	}
@@ -123,6 +124,8 @@ type ReconcileMapsServer struct {
 	operator.Parameters
 	recorder       record.EventRecorder
 	dynamicWatches watches.DynamicWatches
+	// statusWriter coalesces successive status updates of the same Elastic Maps Server.
+	statusWriter   *common.StatusWriter
 	licenseChecker license.Checker
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
@@ -166,6 +169,11 @@ func (r *ReconcileMapsServer) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&ems) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", ems.Namespace, "name", ems.Name)
+		return reconcile.Result{}, nil
+	}
+
 	enabled, err := r.licenseChecker.EnterpriseFeaturesEnabled()
 	if err != nil {
 		return reconcile.Result{}, err
@@ -198,7 +206,7 @@ func (r *ReconcileMapsServer) doReconcile(ctx context.Context, ems emsv1alpha1.E
 		return reconcile.Result{}, err
 	}
 
-	svc, err := common.ReconcileService(ctx, r.Client, NewService(ems), &ems)
+	svc, err := common.ReconcileService(ctx, r.Client, NewService(ems), &ems, r.ServerSideApply)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -214,6 +222,7 @@ func (r *ReconcileMapsServer) doReconcile(ctx context.Context, ems emsv1alpha1.E
 		CACertRotation:        r.CACertRotation,
 		CertRotation:          r.CertRotation,
 		GarbageCollectSecrets: true,
+		ServerSideApply:       r.ServerSideApply,
 	}.ReconcileCAAndHTTPCerts(ctx)
 	if results.HasError() {
 		res, err := results.Aggregate()
@@ -230,7 +239,7 @@ func (r *ReconcileMapsServer) doReconcile(ctx context.Context, ems emsv1alpha1.E
 		return reconcile.Result{}, nil // will eventually retry once updated
 	}
 
-	configSecret, err := reconcileConfig(r, ems, r.IPFamily)
+	configSecret, err := reconcileConfig(r, ems, r.IPFamily, r.ServerSideApply)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -246,10 +255,14 @@ func (r *ReconcileMapsServer) doReconcile(ctx context.Context, ems emsv1alpha1.E
 		return reconcile.Result{}, fmt.Errorf("reconcile deployment: %w", err)
 	}
 
-	err = r.updateStatus(ems, deploy)
+	requeueAfter, err := r.updateStatus(ems, deploy)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("updating status: %w", err)
 	}
+	if requeueAfter > 0 {
+		// flush the deferred status update
+		results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
 
 	return results.Aggregate()
 }
@@ -331,7 +344,7 @@ func (r *ReconcileMapsServer) reconcileDeployment(
 	defer span.End()
 
 	deploy := deployment.New(r.deploymentParams(ems, configHash))
-	return deployment.Reconcile(r.K8sClient(), deploy, &ems)
+	return deployment.Reconcile(r.K8sClient(), deploy, &ems, r.RestrictedSecurityContext)
 }
 
 func (r *ReconcileMapsServer) deploymentParams(ems emsv1alpha1.ElasticMapsServer, configHash string) deployment.Params {
@@ -354,14 +367,16 @@ func (r *ReconcileMapsServer) deploymentParams(ems emsv1alpha1.ElasticMapsServer
 	}
 }
 
-func (r *ReconcileMapsServer) updateStatus(ems emsv1alpha1.ElasticMapsServer, deploy appsv1.Deployment) error {
+// updateStatus writes the status of the Elastic Maps Server resource if it changed. Health changes are written immediately,
+// other changes can be coalesced: it then returns the delay after which the update should be retried.
+func (r *ReconcileMapsServer) updateStatus(ems emsv1alpha1.ElasticMapsServer, deploy appsv1.Deployment) (time.Duration, error) {
 	pods, err := k8s.PodsMatchingLabels(r.K8sClient(), ems.Namespace, map[string]string{NameLabelName: ems.Name})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	deploymentStatus, err := common.DeploymentStatus(ems.Status.DeploymentStatus, deploy, pods, versionLabelName)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	newStatus := emsv1alpha1.MapsStatus{
 		DeploymentStatus:  deploymentStatus,
@@ -369,19 +384,24 @@ func (r *ReconcileMapsServer) updateStatus(ems emsv1alpha1.ElasticMapsServer, de
 	}
 
 	if reflect.DeepEqual(newStatus, ems.Status) {
-		return nil // nothing to do
+		return 0, nil // nothing to do
 	}
 	if newStatus.IsDegraded(ems.Status.DeploymentStatus) {
 		r.recorder.Event(&ems, corev1.EventTypeWarning, events.EventReasonUnhealthy, "Elastic Maps Server health degraded")
 	}
-	log.V(1).Info("Updating status",
+	immediate := newStatus.Health != ems.Status.Health
+	ems.Status = newStatus
+	requeueAfter, err := r.statusWriter.Write(r.Client, &ems, immediate)
+	if err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+	log.V(1).Info("Updated status",
 		"iteration", atomic.LoadUint64(&r.iteration),
 		"namespace", ems.Namespace,
 		"maps_name", ems.Name,
 		"status", newStatus,
 	)
-	ems.Status = newStatus
-	return common.UpdateStatus(r.Client, &ems)
+	return 0, nil
 }
 
 func (r *ReconcileMapsServer) onDelete(obj types.NamespacedName) error {
@@ -389,5 +409,6 @@ func (r *ReconcileMapsServer) onDelete(obj types.NamespacedName) error {
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(certificates.CertificateWatchKey(EMSNamer, obj.Name))
 	// same for the configRef secret
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(common.ConfigRefWatchName(obj))
+	r.statusWriter.Forget(obj)
 	return reconciler.GarbageCollectSoftOwnedSecrets(r.Client, obj, emsv1alpha1.Kind)
 }
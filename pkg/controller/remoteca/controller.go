@@ -102,6 +102,11 @@ func (r *ReconcileRemoteCa) Reconcile(ctx context.Context, request reconcile.Req
 		return reconcile.Result{}, nil
 	}
 
+	if !r.ResourceSelector.Matches(&es) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+		return reconcile.Result{}, nil
+	}
+
 	return doReconcile(ctx, r, &es)
 }
 
@@ -212,7 +217,7 @@ func getExpectedRemoteClusters(
 
 	// Add remote clusters declared in the Spec
 	for _, remoteCluster := range associatedEs.Spec.RemoteClusters {
-		if !remoteCluster.ElasticsearchRef.IsDefined() {
+		if !remoteCluster.ElasticsearchRef.IsDefined() || remoteCluster.ElasticsearchRef.IsExternal() {
 			continue
 		}
 		esRef := remoteCluster.ElasticsearchRef.WithDefaultNamespace(associatedEs.Namespace)
@@ -228,7 +233,7 @@ func getExpectedRemoteClusters(
 	for _, es := range list.Items {
 		es := es
 		for _, remoteCluster := range es.Spec.RemoteClusters {
-			if !remoteCluster.ElasticsearchRef.IsDefined() {
+			if !remoteCluster.ElasticsearchRef.IsDefined() || remoteCluster.ElasticsearchRef.IsExternal() {
 				continue
 			}
 			esRef := remoteCluster.ElasticsearchRef.WithDefaultNamespace(es.Namespace)
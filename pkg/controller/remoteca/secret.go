@@ -101,7 +101,7 @@ func copyCertificateAuthority(
 	}
 
 	// Reconcile the copy to the target cluster
-	return reconcileRemoteCA(ctx, r.Client, target, sourceKey, sourceCA.Data[certificates.CAFileName])
+	return reconcileRemoteCA(ctx, r.Client, target, sourceKey, sourceCA.Data[certificates.CAFileName], r.ServerSideApply)
 }
 
 // deleteCertificateAuthorities deletes all the Secrets needed to establish a trust relationship between two clusters.
@@ -148,6 +148,7 @@ func reconcileRemoteCA(
 	target *esv1.Elasticsearch,
 	source types.NamespacedName,
 	sourceCA []byte,
+	serverSideApply bool,
 ) error {
 	span, _ := apm.StartSpan(ctx, "reconcile_remote_ca", tracing.SpanTypeApp)
 	defer span.End()
@@ -160,6 +161,6 @@ func reconcileRemoteCA(
 		},
 	}
 
-	_, err := reconciler.ReconcileSecret(c, expected, target)
+	_, err := reconciler.ReconcileSecret(c, expected, target, serverSideApply)
 	return err
 }
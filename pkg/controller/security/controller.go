@@ -0,0 +1,346 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package security
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	securityv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/security/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	name = "security-controller"
+)
+
+var (
+	log = ulog.Log.WithName(name)
+
+	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+	// syncRequeue is used to periodically detect and revert the changes made to the managed users and roles
+	// through the Elasticsearch APIs.
+	syncRequeue = reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Minute}
+)
+
+// EsClientProvider returns a client to the given Elasticsearch cluster.
+type EsClientProvider func(ctx context.Context, c k8s.Client, dialer net.Dialer, es esv1.Elasticsearch) (esclient.Client, error)
+
+// Add creates a new security controller and adds it to the manager with default RBAC.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	r := NewReconciler(mgr, params)
+	c, err := common.NewController(mgr, name, r, params)
+	if err != nil {
+		return err
+	}
+	return addWatches(c, r.Client)
+}
+
+// NewReconciler returns a new reconcile.Reconciler
+func NewReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileSecurity {
+	return &ReconcileSecurity{
+		Client:           mgr.GetClient(),
+		Parameters:       params,
+		esClientProvider: user.ControllerUserClientProvider(params.DryRun),
+		recorder:         mgr.GetEventRecorderFor(name),
+	}
+}
+
+// elasticsearchRequest returns a request for the Elasticsearch cluster referenced by a user or a role. References to
+// another namespace are reported as invalid when reconciling the cluster of the same name in the resource namespace.
+func elasticsearchRequest(obj client.Object, ref string) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: ref}}}
+}
+
+func addWatches(c controller.Controller, k8sClient k8s.Client) error {
+	// Watch Elasticsearch clusters, to configure users and roles once the cluster becomes ready
+	if err := c.Watch(&source.Kind{Type: &esv1.Elasticsearch{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// Watch ElasticsearchUsers and ElasticsearchRoles, reconciled along with the cluster they reference
+	if err := c.Watch(
+		&source.Kind{Type: &securityv1alpha1.ElasticsearchUser{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			esUser, ok := obj.(*securityv1alpha1.ElasticsearchUser)
+			if !ok {
+				return nil
+			}
+			return elasticsearchRequest(esUser, esUser.Spec.ElasticsearchRef.Name)
+		}),
+	); err != nil {
+		return err
+	}
+	if err := c.Watch(
+		&source.Kind{Type: &securityv1alpha1.ElasticsearchRole{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			esRole, ok := obj.(*securityv1alpha1.ElasticsearchRole)
+			if !ok {
+				return nil
+			}
+			return elasticsearchRequest(esRole, esRole.Spec.ElasticsearchRef.Name)
+		}),
+	); err != nil {
+		return err
+	}
+
+	// Watch Secrets, to update the password of the users referencing them
+	return c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			var users securityv1alpha1.ElasticsearchUserList
+			if err := k8sClient.List(context.Background(), &users, client.InNamespace(obj.GetNamespace())); err != nil {
+				log.Error(err, "failed to list Elasticsearch users", "namespace", obj.GetNamespace())
+				return nil
+			}
+			var requests []reconcile.Request
+			for i := range users.Items {
+				if users.Items[i].Spec.PasswordSecretRef.SecretName == obj.GetName() {
+					requests = append(requests, elasticsearchRequest(&users.Items[i], users.Items[i].Spec.ElasticsearchRef.Name)...)
+				}
+			}
+			return requests
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileSecurity{}
+
+// ReconcileSecurity synchronizes the ElasticsearchUsers and ElasticsearchRoles with the security APIs of the
+// Elasticsearch clusters they reference.
+type ReconcileSecurity struct {
+	k8s.Client
+	operator.Parameters
+	esClientProvider EsClientProvider
+	recorder         record.EventRecorder
+
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+// Reconcile synchronizes the users and roles referencing the Elasticsearch cluster of the request. The users, roles
+// and role mappings previously created for resources which do not exist anymore are removed from Elasticsearch.
+func (r *ReconcileSecurity) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "es_name", &r.iteration)()
+	tx, ctx := tracing.NewTransaction(ctx, r.Tracer, request.NamespacedName, "security")
+	defer tracing.EndTransaction(tx)
+
+	resources, err := r.resourcesReferencing(ctx, request.NamespacedName)
+	if err != nil {
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	results := r.doReconcile(ctx, request.NamespacedName, resources)
+	if err := r.updateStatus(ctx, resources); err != nil {
+		if apierrors.IsConflict(err) {
+			return results.WithResult(reconcile.Result{Requeue: true}).Aggregate()
+		}
+		results.WithError(err)
+	}
+	return results.Aggregate()
+}
+
+func (r *ReconcileSecurity) doReconcile(
+	ctx context.Context,
+	esNSN types.NamespacedName,
+	resources *securityResources,
+) *reconciler.Results {
+	results := &reconciler.Results{}
+
+	esClient, phase, err := r.esClient(ctx, esNSN)
+	if err != nil {
+		resources.setPhase(phase, err)
+		if phase == securityv1alpha1.PendingPhase && !resources.empty() {
+			results.WithResult(defaultRequeue)
+		}
+		return results
+	}
+	defer esClient.Close()
+
+	// roles first, as users and role mappings may reference them
+	r.syncObjects(ctx, rolesAPI(esClient), resources.expectedRoles(), resources.roleNames, results)
+	r.syncObjects(ctx, roleMappingsAPI(esClient), resources.expectedRoleMappings(), resources.roleMappingNames, results)
+	r.syncObjects(ctx, usersAPI(esClient), resources.expectedUsers(ctx, r.Client), resources.userNames, results)
+	if !resources.empty() {
+		results.WithResult(syncRequeue)
+	}
+	return results
+}
+
+// esClient returns a client to the given Elasticsearch cluster, or an error along with the phase to report if the
+// cluster cannot be configured.
+func (r *ReconcileSecurity) esClient(ctx context.Context, esNSN types.NamespacedName) (esclient.Client, securityv1alpha1.Phase, error) {
+	if esNSN.Name == "" {
+		// resources referencing a cluster not managed by the operator through a secretName
+		return nil, securityv1alpha1.FailedPhase, fmt.Errorf("referenced Elasticsearch must be managed by the operator, secretName is not supported")
+	}
+	var es esv1.Elasticsearch
+	if err := r.Get(ctx, esNSN, &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, securityv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s not found", esNSN)
+		}
+		return nil, securityv1alpha1.PendingPhase, err
+	}
+	if es.Status.Phase != esv1.ElasticsearchReadyPhase {
+		return nil, securityv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s is not ready", esNSN)
+	}
+	if es.IsInMaintenance() {
+		return nil, securityv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s is in maintenance mode", esNSN)
+	}
+	esClient, err := r.esClientProvider(ctx, r.Client, r.Dialer, es)
+	if err != nil {
+		return nil, securityv1alpha1.PendingPhase, err
+	}
+	return esClient, securityv1alpha1.ReadyPhase, nil
+}
+
+// securityResources are the users and roles referencing an Elasticsearch cluster.
+type securityResources struct {
+	// users and roles are the resources managed by this operator.
+	users []securityv1alpha1.ElasticsearchUser
+	roles []securityv1alpha1.ElasticsearchRole
+	// userNames, roleNames and roleMappingNames are the names in Elasticsearch of the objects of all the resources
+	// referencing the cluster, indexed by resource, including the resources not managed by this operator, which must
+	// not be removed from Elasticsearch.
+	userNames        map[string]string
+	roleNames        map[string]string
+	roleMappingNames map[string]string
+	// previousRoleStatuses are the statuses of the roles before this reconciliation, indexed by resource.
+	previousRoleStatuses map[string]securityv1alpha1.SyncStatus
+}
+
+func (r *ReconcileSecurity) resourcesReferencing(ctx context.Context, esNSN types.NamespacedName) (*securityResources, error) {
+	resources := &securityResources{
+		userNames:            map[string]string{},
+		roleNames:            map[string]string{},
+		roleMappingNames:     map[string]string{},
+		previousRoleStatuses: map[string]securityv1alpha1.SyncStatus{},
+	}
+	var users securityv1alpha1.ElasticsearchUserList
+	if err := r.List(ctx, &users, client.InNamespace(esNSN.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, esUser := range users.Items {
+		if esUser.Spec.ElasticsearchRef.Name != esNSN.Name {
+			continue
+		}
+		resources.userNames[owner(&esUser)] = esUser.UsernameOrDefault()
+		if r.isManaged(&esUser) {
+			resources.users = append(resources.users, esUser)
+		}
+	}
+	var roles securityv1alpha1.ElasticsearchRoleList
+	if err := r.List(ctx, &roles, client.InNamespace(esNSN.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, esRole := range roles.Items {
+		if esRole.Spec.ElasticsearchRef.Name != esNSN.Name {
+			continue
+		}
+		resources.roleNames[owner(&esRole)] = esRole.RoleNameOrDefault()
+		if esRole.Spec.Mapping != nil {
+			resources.roleMappingNames[owner(&esRole)] = esRole.RoleNameOrDefault()
+		}
+		if r.isManaged(&esRole) {
+			resources.roles = append(resources.roles, esRole)
+			resources.previousRoleStatuses[owner(&esRole)] = esRole.Status
+		}
+	}
+	// the first resource created wins if several of them manage the same object
+	sort.SliceStable(resources.users, func(i, j int) bool {
+		return resources.users[i].CreationTimestamp.Before(&resources.users[j].CreationTimestamp)
+	})
+	sort.SliceStable(resources.roles, func(i, j int) bool {
+		return resources.roles[i].CreationTimestamp.Before(&resources.roles[j].CreationTimestamp)
+	})
+	return resources, nil
+}
+
+func (r *ReconcileSecurity) isManaged(obj client.Object) bool {
+	if common.IsUnmanaged(obj) {
+		log.Info("Object is currently not managed by this controller. Skipping reconciliation", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return false
+	}
+	if !r.ResourceSelector.Matches(obj) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return false
+	}
+	return true
+}
+
+func (s *securityResources) empty() bool {
+	return len(s.users) == 0 && len(s.roles) == 0
+}
+
+func (s *securityResources) setPhase(phase securityv1alpha1.Phase, err error) {
+	for i := range s.users {
+		setPhase(&s.users[i].Status.SyncStatus, phase, err)
+	}
+	for i := range s.roles {
+		setPhase(&s.roles[i].Status, phase, err)
+	}
+}
+
+func setPhase(status *securityv1alpha1.SyncStatus, phase securityv1alpha1.Phase, err error) {
+	status.Phase = phase
+	status.Message = ""
+	if err != nil {
+		status.Message = err.Error()
+	}
+}
+
+// updateStatus updates the status of the users and roles, if it has changed.
+func (r *ReconcileSecurity) updateStatus(ctx context.Context, resources *securityResources) error {
+	for i := range resources.users {
+		esUser := &resources.users[i]
+		esUser.Status.ObservedGeneration = esUser.Generation
+		var current securityv1alpha1.ElasticsearchUser
+		if err := r.Get(ctx, k8s.ExtractNamespacedName(esUser), &current); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(current.Status, esUser.Status) {
+			if err := r.Status().Update(ctx, esUser); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range resources.roles {
+		esRole := &resources.roles[i]
+		esRole.Status.ObservedGeneration = esRole.Generation
+		var current securityv1alpha1.ElasticsearchRole
+		if err := r.Get(ctx, k8s.ExtractNamespacedName(esRole), &current); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(current.Status, esRole.Status) {
+			if err := r.Status().Update(ctx, esRole); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
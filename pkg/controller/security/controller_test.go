@@ -0,0 +1,342 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package security
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	securityv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/security/v1alpha1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+type fakeESClient struct {
+	esclient.Client
+	users        map[string]esclient.SecurityObject
+	roles        map[string]esclient.SecurityObject
+	roleMappings map[string]esclient.SecurityObject
+	puts         int
+}
+
+func newFakeESClient() *fakeESClient {
+	return &fakeESClient{
+		users:        map[string]esclient.SecurityObject{},
+		roles:        map[string]esclient.SecurityObject{},
+		roleMappings: map[string]esclient.SecurityObject{},
+	}
+}
+
+// store mimics Elasticsearch, which returns the objects without their write only fields and with their default values.
+func (f *fakeESClient) store(objects map[string]esclient.SecurityObject, name string, object esclient.SecurityObject, defaults esclient.SecurityObject) {
+	stored := esclient.SecurityObject{}
+	if err := normalize(object, &stored); err != nil {
+		panic(err)
+	}
+	delete(stored, "password")
+	for k, v := range defaults {
+		if _, exists := stored[k]; !exists {
+			stored[k] = v
+		}
+	}
+	objects[name] = stored
+	f.puts++
+}
+
+func (f *fakeESClient) GetUsers(_ context.Context) (map[string]esclient.SecurityObject, error) {
+	return f.users, nil
+}
+
+func (f *fakeESClient) PutUser(_ context.Context, name string, user esclient.SecurityObject) error {
+	if _, exists := f.users[name]; !exists && user["password"] == nil {
+		panic("password is required to create a user")
+	}
+	f.store(f.users, name, user, esclient.SecurityObject{"username": name, "full_name": nil, "email": nil})
+	return nil
+}
+
+func (f *fakeESClient) DeleteUser(_ context.Context, name string) error {
+	delete(f.users, name)
+	return nil
+}
+
+func (f *fakeESClient) GetRoles(_ context.Context) (map[string]esclient.SecurityObject, error) {
+	return f.roles, nil
+}
+
+func (f *fakeESClient) PutRole(_ context.Context, name string, role esclient.SecurityObject) error {
+	f.store(f.roles, name, role, esclient.SecurityObject{"run_as": []interface{}{}, "transient_metadata": map[string]interface{}{"enabled": true}})
+	return nil
+}
+
+func (f *fakeESClient) DeleteRole(_ context.Context, name string) error {
+	delete(f.roles, name)
+	return nil
+}
+
+func (f *fakeESClient) GetRoleMappings(_ context.Context) (map[string]esclient.SecurityObject, error) {
+	return f.roleMappings, nil
+}
+
+func (f *fakeESClient) PutRoleMapping(_ context.Context, name string, mapping esclient.SecurityObject) error {
+	f.store(f.roleMappings, name, mapping, nil)
+	return nil
+}
+
+func (f *fakeESClient) DeleteRoleMapping(_ context.Context, name string) error {
+	delete(f.roleMappings, name)
+	return nil
+}
+
+func (f *fakeESClient) Close() {}
+
+func es(phase esv1.ElasticsearchOrchestrationPhase) *esv1.Elasticsearch {
+	return &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Status:     esv1.ElasticsearchStatus{Phase: phase},
+	}
+}
+
+func inMaintenance(es *esv1.Elasticsearch) *esv1.Elasticsearch {
+	es.Annotations = map[string]string{esv1.MaintenanceAnnotation: "true"}
+	return es
+}
+
+func esUser() *securityv1alpha1.ElasticsearchUser {
+	return &securityv1alpha1.ElasticsearchUser{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "alice", Generation: 1},
+		Spec: securityv1alpha1.ElasticsearchUserSpec{
+			ElasticsearchRef:  commonv1.ObjectSelector{Name: "es"},
+			PasswordSecretRef: commonv1.SecretKeySelector{SecretName: "alice-password", Key: "password"},
+			Roles:             []string{"logs-reader"},
+			FullName:          "Alice",
+		},
+	}
+}
+
+func passwordSecret(password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "alice-password"},
+		Data:       map[string][]byte{"password": []byte(password)},
+	}
+}
+
+func esRole() *securityv1alpha1.ElasticsearchRole {
+	return &securityv1alpha1.ElasticsearchRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "logs-reader", Generation: 1},
+		Spec: securityv1alpha1.ElasticsearchRoleSpec{
+			ElasticsearchRef: commonv1.ObjectSelector{Name: "es"},
+			Definition: &commonv1.Config{Data: map[string]interface{}{
+				"indices": []interface{}{map[string]interface{}{"names": []interface{}{"logs-*"}, "privileges": []interface{}{"read"}}},
+			}},
+			Mapping: &securityv1alpha1.RoleMapping{
+				Rules: &commonv1.Config{Data: map[string]interface{}{"field": map[string]interface{}{"groups": "readers"}}},
+			},
+		},
+	}
+}
+
+func newReconciler(c k8s.Client, esClient *fakeESClient) *ReconcileSecurity {
+	return &ReconcileSecurity{
+		Client: c,
+		esClientProvider: func(_ context.Context, _ k8s.Client, _ net.Dialer, _ esv1.Elasticsearch) (esclient.Client, error) {
+			return esClient, nil
+		},
+		recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func reconcileES(t *testing.T, r *ReconcileSecurity) reconcile.Result {
+	t.Helper()
+	res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "es"}})
+	require.NoError(t, err)
+	return res
+}
+
+func userStatus(t *testing.T, c k8s.Client) securityv1alpha1.ElasticsearchUserStatus {
+	t.Helper()
+	var u securityv1alpha1.ElasticsearchUser
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "alice"}, &u))
+	return u.Status
+}
+
+func roleStatus(t *testing.T, c k8s.Client) securityv1alpha1.SyncStatus {
+	t.Helper()
+	var r securityv1alpha1.ElasticsearchRole
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "logs-reader"}, &r))
+	return r.Status
+}
+
+func TestReconcileSecurity_Reconcile(t *testing.T) {
+	secret := passwordSecret("changeme")
+	c := k8s.NewFakeClient(es(esv1.ElasticsearchReadyPhase), esUser(), esRole(), secret)
+	esClient := newFakeESClient()
+	// created outside of the operator, must be left untouched
+	esClient.users["bob"] = esclient.SecurityObject{"username": "bob", "roles": []interface{}{"superuser"}}
+	r := newReconciler(c, esClient)
+
+	// the role, its mapping and the user are created
+	res := reconcileES(t, r)
+	require.Equal(t, syncRequeue, res)
+	require.Equal(t, esclient.SecurityObject{
+		"indices":            []interface{}{map[string]interface{}{"names": []interface{}{"logs-*"}, "privileges": []interface{}{"read"}}},
+		"metadata":           map[string]interface{}{ManagedByMetadataKey: "ns/logs-reader"},
+		"run_as":             []interface{}{},
+		"transient_metadata": map[string]interface{}{"enabled": true},
+	}, esClient.roles["logs-reader"])
+	require.Equal(t, esclient.SecurityObject{
+		"roles":    []interface{}{"logs-reader"},
+		"enabled":  true,
+		"rules":    map[string]interface{}{"field": map[string]interface{}{"groups": "readers"}},
+		"metadata": map[string]interface{}{ManagedByMetadataKey: "ns/logs-reader"},
+	}, esClient.roleMappings["logs-reader"])
+	require.Equal(t, esclient.SecurityObject{
+		"username":  "alice",
+		"roles":     []interface{}{"logs-reader"},
+		"enabled":   true,
+		"full_name": "Alice",
+		"email":     nil,
+		"metadata":  map[string]interface{}{ManagedByMetadataKey: "ns/alice"},
+	}, esClient.users["alice"])
+	require.Equal(t, securityv1alpha1.ReadyPhase, roleStatus(t, c).Phase)
+	require.Equal(t, securityv1alpha1.ReadyPhase, userStatus(t, c).Phase)
+	require.Equal(t, int64(1), userStatus(t, c).ObservedGeneration)
+	require.NotEmpty(t, userStatus(t, c).PasswordSecretVersion)
+
+	// nothing to do if everything is in sync
+	esClient.puts = 0
+	reconcileES(t, r)
+	require.Equal(t, 0, esClient.puts)
+
+	// changes made through the Elasticsearch API are reverted
+	esClient.roles["logs-reader"]["indices"] = []interface{}{map[string]interface{}{"names": []interface{}{"*"}, "privileges": []interface{}{"all"}}}
+	delete(esClient.roleMappings, "logs-reader")
+	reconcileES(t, r)
+	require.Equal(t, 2, esClient.puts)
+	require.Equal(t, []interface{}{map[string]interface{}{"names": []interface{}{"logs-*"}, "privileges": []interface{}{"read"}}}, esClient.roles["logs-reader"]["indices"])
+	require.Contains(t, esClient.roleMappings, "logs-reader")
+	require.NotNil(t, roleStatus(t, c).LastDriftTime)
+	require.Nil(t, userStatus(t, c).LastDriftTime)
+
+	// the password is updated when the Secret changes
+	esClient.puts = 0
+	secret.Data["password"] = []byte("new-password")
+	require.NoError(t, c.Update(context.Background(), secret))
+	reconcileES(t, r)
+	require.Equal(t, 1, esClient.puts)
+	require.Nil(t, userStatus(t, c).LastDriftTime)
+
+	// the objects are removed from Elasticsearch once the resources are deleted
+	require.NoError(t, c.Delete(context.Background(), esUser()))
+	require.NoError(t, c.Delete(context.Background(), esRole()))
+	res = reconcileES(t, r)
+	require.Equal(t, reconcile.Result{}, res)
+	require.Empty(t, esClient.roles)
+	require.Empty(t, esClient.roleMappings)
+	require.Equal(t, map[string]esclient.SecurityObject{
+		"bob": {"username": "bob", "roles": []interface{}{"superuser"}},
+	}, esClient.users)
+}
+
+func TestReconcileSecurity_Reconcile_Failures(t *testing.T) {
+	otherNamespaceUser := esUser()
+	otherNamespaceUser.Spec.ElasticsearchRef.Namespace = "other"
+	externalUser := esUser()
+	externalUser.Spec.ElasticsearchRef.SecretName = "external"
+	sameUsername := esUser()
+	sameUsername.Name = "alice-2"
+	sameUsername.Spec.Username = "alice"
+	sameUsername.CreationTimestamp = metav1.Now()
+
+	tests := []struct {
+		name        string
+		objs        []runtime.Object
+		esUsers     map[string]esclient.SecurityObject
+		wantRequeue bool
+		wantPhase   securityv1alpha1.Phase
+		wantESUsers map[string]esclient.SecurityObject
+	}{
+		{
+			name:        "Elasticsearch not ready: retry later",
+			objs:        []runtime.Object{es(esv1.ElasticsearchApplyingChangesPhase), esUser(), passwordSecret("changeme")},
+			wantRequeue: true,
+			wantPhase:   securityv1alpha1.PendingPhase,
+		},
+		{
+			name:        "Elasticsearch in maintenance mode: retry later",
+			objs:        []runtime.Object{inMaintenance(es(esv1.ElasticsearchReadyPhase)), esUser(), passwordSecret("changeme")},
+			wantRequeue: true,
+			wantPhase:   securityv1alpha1.PendingPhase,
+		},
+		{
+			name:        "Elasticsearch not found: retry later",
+			objs:        []runtime.Object{esUser(), passwordSecret("changeme")},
+			wantRequeue: true,
+			wantPhase:   securityv1alpha1.PendingPhase,
+		},
+		{
+			name:      "Elasticsearch in another namespace: invalid",
+			objs:      []runtime.Object{es(esv1.ElasticsearchReadyPhase), otherNamespaceUser, passwordSecret("changeme")},
+			wantPhase: securityv1alpha1.FailedPhase,
+		},
+		{
+			name:      "Elasticsearch not managed by the operator: invalid",
+			objs:      []runtime.Object{es(esv1.ElasticsearchReadyPhase), externalUser, passwordSecret("changeme")},
+			wantPhase: securityv1alpha1.FailedPhase,
+		},
+		{
+			name:      "password Secret not found: invalid",
+			objs:      []runtime.Object{es(esv1.ElasticsearchReadyPhase), esUser()},
+			wantPhase: securityv1alpha1.FailedPhase,
+		},
+		{
+			name:        "user created outside of the operator: conflict",
+			objs:        []runtime.Object{es(esv1.ElasticsearchReadyPhase), esUser(), passwordSecret("changeme")},
+			esUsers:     map[string]esclient.SecurityObject{"alice": {"username": "alice"}},
+			wantPhase:   securityv1alpha1.FailedPhase,
+			wantESUsers: map[string]esclient.SecurityObject{"alice": {"username": "alice"}},
+		},
+		{
+			name:      "user managed by another resource: the first resource created wins",
+			objs:      []runtime.Object{es(esv1.ElasticsearchReadyPhase), sameUsername, esUser(), passwordSecret("changeme")},
+			wantPhase: securityv1alpha1.ReadyPhase,
+			wantESUsers: map[string]esclient.SecurityObject{"alice": {
+				"username":  "alice",
+				"roles":     []interface{}{"logs-reader"},
+				"enabled":   true,
+				"full_name": "Alice",
+				"email":     nil,
+				"metadata":  map[string]interface{}{ManagedByMetadataKey: "ns/alice"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.NewFakeClient(tt.objs...)
+			esClient := newFakeESClient()
+			if tt.esUsers != nil {
+				esClient.users = tt.esUsers
+			}
+			res := reconcileES(t, newReconciler(c, esClient))
+			require.Equal(t, tt.wantRequeue, res == defaultRequeue)
+			require.Equal(t, tt.wantPhase, userStatus(t, c).Phase)
+			if tt.wantESUsers == nil {
+				tt.wantESUsers = map[string]esclient.SecurityObject{}
+			}
+			require.Equal(t, tt.wantESUsers, esClient.users)
+		})
+	}
+}
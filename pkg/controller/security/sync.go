@@ -0,0 +1,368 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	securityv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/security/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// ManagedByMetadataKey is the metadata key set on the users, roles and role mappings created by the operator.
+// Its value is the namespace and name of the resource managing the object.
+const ManagedByMetadataKey = "eck_managed_by"
+
+// owner returns the value of the ManagedByMetadataKey metadata of the objects managed by the given resource.
+func owner(obj client.Object) string {
+	return types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String()
+}
+
+// managedBy returns the resource managing the given object, or an empty string if it is not managed by the operator.
+func managedBy(object esclient.SecurityObject) string {
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	owner, _ := metadata[ManagedByMetadataKey].(string)
+	return owner
+}
+
+// securityAPI gives access to the users, the roles or the role mappings of an Elasticsearch cluster.
+type securityAPI struct {
+	kind   string
+	get    func(ctx context.Context) (map[string]esclient.SecurityObject, error)
+	put    func(ctx context.Context, name string, object esclient.SecurityObject) error
+	delete func(ctx context.Context, name string) error
+	// readOnlyFields are returned by Elasticsearch but cannot be set.
+	readOnlyFields []string
+}
+
+func usersAPI(c esclient.Client) securityAPI {
+	return securityAPI{kind: "user", get: c.GetUsers, put: c.PutUser, delete: c.DeleteUser, readOnlyFields: []string{"username"}}
+}
+
+func rolesAPI(c esclient.Client) securityAPI {
+	return securityAPI{kind: "role", get: c.GetRoles, put: c.PutRole, delete: c.DeleteRole, readOnlyFields: []string{"transient_metadata"}}
+}
+
+func roleMappingsAPI(c esclient.Client) securityAPI {
+	return securityAPI{kind: "role mapping", get: c.GetRoleMappings, put: c.PutRoleMapping, delete: c.DeleteRoleMapping}
+}
+
+// expectedObject is the expected state of a user, a role or a role mapping managed by a resource.
+type expectedObject struct {
+	resource client.Object
+	status   *securityv1alpha1.SyncStatus
+	// previousStatus is the status of the resource before this reconciliation.
+	previousStatus securityv1alpha1.SyncStatus
+	// name of the object in Elasticsearch.
+	name string
+	// object is the expected state of the object, as returned by Elasticsearch.
+	object esclient.SecurityObject
+	// err is set if the expected object cannot be built from the resource.
+	err error
+	// writeOnly holds the fields which are not returned by Elasticsearch, such as the password of a user.
+	writeOnly esclient.SecurityObject
+	// writeOnlyChanged is true if the write only fields must be sent again to Elasticsearch.
+	writeOnlyChanged bool
+	// onSynced is called once the object is in sync with Elasticsearch.
+	onSynced func()
+}
+
+// syncObjects creates or updates the expected objects which are not in sync with Elasticsearch, and deletes the objects
+// managed by the operator whose resource does not exist anymore. names holds the expected name of the objects of all
+// the resources referencing the cluster, indexed by owner.
+func (r *ReconcileSecurity) syncObjects(
+	ctx context.Context,
+	api securityAPI,
+	expected []expectedObject,
+	names map[string]string,
+	results *reconciler.Results,
+) {
+	actual, err := api.get(ctx)
+	if err != nil {
+		for _, e := range expected {
+			setPhase(e.status, securityv1alpha1.PendingPhase, err)
+		}
+		results.WithError(err)
+		return
+	}
+
+	claimed := map[string]string{}
+	for _, e := range expected {
+		if e.err != nil {
+			setPhase(e.status, securityv1alpha1.FailedPhase, e.err)
+			continue
+		}
+		resourceOwner := owner(e.resource)
+		if other, exists := claimed[e.name]; exists {
+			setPhase(e.status, securityv1alpha1.FailedPhase, fmt.Errorf("%s %s is already managed by %s", api.kind, e.name, other))
+			continue
+		}
+		claimed[e.name] = resourceOwner
+		current, exists := actual[e.name]
+		if exists && managedBy(current) != resourceOwner {
+			setPhase(e.status, securityv1alpha1.FailedPhase, fmt.Errorf("%s %s already exists and is not managed by %s", api.kind, e.name, resourceOwner))
+			continue
+		}
+		if exists && !e.writeOnlyChanged && inSync(e.object, current, api.readOnlyFields) {
+			synced(e)
+			continue
+		}
+
+		// the object was in sync with the current spec and has been modified or deleted through the Elasticsearch API
+		drift := e.previousStatus.Phase == securityv1alpha1.ReadyPhase &&
+			e.previousStatus.ObservedGeneration == e.resource.GetGeneration() &&
+			!e.writeOnlyChanged
+		body := esclient.SecurityObject{}
+		for k, v := range e.object {
+			body[k] = v
+		}
+		if !exists || e.writeOnlyChanged {
+			for k, v := range e.writeOnly {
+				body[k] = v
+			}
+		}
+		err := api.put(ctx, e.name, body)
+		if esclient.IsDryRun(err) {
+			continue
+		}
+		if err != nil {
+			r.recorder.Eventf(e.resource, corev1.EventTypeWarning, events.EventReconciliationError, "Failed to update %s %s: %s", api.kind, e.name, err.Error())
+			setPhase(e.status, securityv1alpha1.FailedPhase, err)
+			results.WithResult(defaultRequeue)
+			continue
+		}
+		if drift {
+			log.Info("Restoring object modified in Elasticsearch", "kind", api.kind, "name", e.name, "namespace", e.resource.GetNamespace(), "resource_name", e.resource.GetName())
+			r.recorder.Eventf(e.resource, corev1.EventTypeWarning, events.EventReasonUnexpected, "Restored %s %s modified in Elasticsearch", api.kind, e.name)
+			now := metav1.Now()
+			e.status.LastDriftTime = &now
+		}
+		synced(e)
+	}
+
+	for objectName, object := range actual {
+		resourceOwner := managedBy(object)
+		if resourceOwner == "" {
+			continue
+		}
+		if expectedName, exists := names[resourceOwner]; exists && expectedName == objectName {
+			continue
+		}
+		log.Info("Deleting object not managed by any resource anymore", "kind", api.kind, "name", objectName, "managed_by", resourceOwner)
+		if err := api.delete(ctx, objectName); err != nil && !esclient.IsNotFound(err) && !esclient.IsDryRun(err) {
+			results.WithError(err)
+		}
+	}
+}
+
+func synced(e expectedObject) {
+	setPhase(e.status, securityv1alpha1.ReadyPhase, nil)
+	if e.onSynced != nil {
+		e.onSynced()
+	}
+}
+
+func (s *securityResources) expectedRoles() []expectedObject {
+	expected := make([]expectedObject, 0, len(s.roles))
+	for i := range s.roles {
+		esRole := &s.roles[i]
+		e := expectedObject{resource: esRole, status: &esRole.Status, previousStatus: esRole.Status, name: esRole.RoleNameOrDefault()}
+		e.err = validateElasticsearchRef(esRole, esRole.ElasticsearchRef())
+		if e.err == nil {
+			definition := map[string]interface{}{}
+			if esRole.Spec.Definition != nil && esRole.Spec.Definition.Data != nil {
+				definition = esRole.Spec.Definition.DeepCopy().Data
+			}
+			e.object, e.err = withManagedBy(definition, esRole)
+		}
+		expected = append(expected, e)
+	}
+	return expected
+}
+
+// expectedRoleMappings returns the role mappings of the roles in sync with Elasticsearch.
+func (s *securityResources) expectedRoleMappings() []expectedObject {
+	var expected []expectedObject
+	for i := range s.roles {
+		esRole := &s.roles[i]
+		if esRole.Spec.Mapping == nil || esRole.Status.Phase != securityv1alpha1.ReadyPhase {
+			continue
+		}
+		e := expectedObject{resource: esRole, status: &esRole.Status, previousStatus: s.previousRoleStatuses[owner(esRole)], name: esRole.RoleNameOrDefault()}
+		if esRole.Spec.Mapping.Rules == nil {
+			e.err = fmt.Errorf("role mapping %s has no rules", e.name)
+		} else {
+			e.object, e.err = withManagedBy(map[string]interface{}{
+				"roles":   []string{e.name},
+				"enabled": esRole.Spec.Mapping.IsEnabled(),
+				"rules":   esRole.Spec.Mapping.Rules.DeepCopy().Data,
+			}, esRole)
+		}
+		expected = append(expected, e)
+	}
+	return expected
+}
+
+func (s *securityResources) expectedUsers(ctx context.Context, c k8s.Client) []expectedObject {
+	expected := make([]expectedObject, 0, len(s.users))
+	for i := range s.users {
+		esUser := &s.users[i]
+		e := expectedObject{resource: esUser, status: &esUser.Status.SyncStatus, previousStatus: esUser.Status.SyncStatus, name: esUser.UsernameOrDefault()}
+		e.err = validateElasticsearchRef(esUser, esUser.ElasticsearchRef())
+		var secret corev1.Secret
+		if e.err == nil {
+			e.err = c.Get(ctx, types.NamespacedName{Namespace: esUser.Namespace, Name: esUser.Spec.PasswordSecretRef.SecretName}, &secret)
+		}
+		if e.err == nil && len(secret.Data[esUser.Spec.PasswordSecretRef.Key]) == 0 {
+			e.err = fmt.Errorf("password %s not found in Secret %s/%s", esUser.Spec.PasswordSecretRef.Key, secret.Namespace, secret.Name)
+		}
+		if e.err == nil {
+			roles := esUser.Spec.Roles
+			if roles == nil {
+				roles = []string{}
+			}
+			user := map[string]interface{}{"roles": roles, "enabled": true}
+			if esUser.Spec.FullName != "" {
+				user["full_name"] = esUser.Spec.FullName
+			}
+			if esUser.Spec.Email != "" {
+				user["email"] = esUser.Spec.Email
+			}
+			if esUser.Spec.Metadata != nil {
+				user["metadata"] = esUser.Spec.Metadata.DeepCopy().Data
+			}
+			e.object, e.err = withManagedBy(user, esUser)
+			e.writeOnly = esclient.SecurityObject{"password": string(secret.Data[esUser.Spec.PasswordSecretRef.Key])}
+			e.writeOnlyChanged = esUser.Status.PasswordSecretVersion != secret.ResourceVersion
+			e.onSynced = func() {
+				esUser.Status.PasswordSecretVersion = secret.ResourceVersion
+			}
+		}
+		expected = append(expected, e)
+	}
+	return expected
+}
+
+func validateElasticsearchRef(obj client.Object, ref commonv1.ObjectSelector) error {
+	if ref.IsExternal() {
+		return fmt.Errorf("referenced Elasticsearch must be managed by the operator, secretName is not supported")
+	}
+	if ref.Namespace != obj.GetNamespace() {
+		return fmt.Errorf("referenced Elasticsearch must be in namespace %s", obj.GetNamespace())
+	}
+	return nil
+}
+
+// withManagedBy adds the ManagedByMetadataKey metadata to the given object.
+func withManagedBy(object map[string]interface{}, resource client.Object) (esclient.SecurityObject, error) {
+	metadata := map[string]interface{}{}
+	switch value := object["metadata"].(type) {
+	case nil:
+	case map[string]interface{}:
+		if value != nil {
+			metadata = value
+		}
+	default:
+		return nil, fmt.Errorf("metadata must be an object")
+	}
+	metadata[ManagedByMetadataKey] = owner(resource)
+	object["metadata"] = metadata
+	return object, nil
+}
+
+// inSync returns true if the actual object matches the expected one. Fields missing from the expected object must
+// have an empty or default value in the actual one.
+func inSync(expected, actual esclient.SecurityObject, readOnlyFields []string) bool {
+	var e, a map[string]interface{}
+	if err := normalize(expected, &e); err != nil {
+		return false
+	}
+	if err := normalize(actual, &a); err != nil {
+		return false
+	}
+	for _, field := range readOnlyFields {
+		delete(a, field)
+	}
+	return matches(e, a)
+}
+
+// normalize converts the given object through JSON, so that objects built by the operator can be compared with the
+// objects returned by Elasticsearch.
+func normalize(in interface{}, out interface{}) error {
+	bytes, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, out)
+}
+
+func matches(expected, actual interface{}) bool {
+	if isEmpty(expected) && isEmpty(actual) {
+		return true
+	}
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range e {
+			if !matches(v, a[k]) {
+				return false
+			}
+		}
+		for k, v := range a {
+			if _, exists := e[k]; !exists && !isEmpty(v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !matches(e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// isEmpty returns true if the given value is the empty or default value returned by Elasticsearch for unset fields.
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,368 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	snapshotv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/snapshot/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	name = "snapshot-controller"
+)
+
+var (
+	log = ulog.Log.WithName(name)
+
+	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+)
+
+// EsClientProvider returns a client to the given Elasticsearch cluster.
+type EsClientProvider func(ctx context.Context, c k8s.Client, dialer net.Dialer, es esv1.Elasticsearch) (esclient.Client, error)
+
+// Add creates a new snapshot controller and adds it to the manager with default RBAC.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	r := NewReconciler(mgr, params)
+	c, err := common.NewController(mgr, name, r, params)
+	if err != nil {
+		return err
+	}
+	return addWatches(c, r.Client)
+}
+
+// NewReconciler returns a new reconcile.Reconciler
+func NewReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileSnapshotRepository {
+	return &ReconcileSnapshotRepository{
+		Client:           mgr.GetClient(),
+		Parameters:       params,
+		esClientProvider: user.ControllerUserClientProvider(params.DryRun),
+		recorder:         mgr.GetEventRecorderFor(name),
+	}
+}
+
+func addWatches(c controller.Controller, k8sClient k8s.Client) error {
+	// Watch SnapshotRepositories
+	if err := c.Watch(&source.Kind{Type: &snapshotv1alpha1.SnapshotRepository{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// Watch SnapshotPolicies, reconciled along with the repository they reference
+	if err := c.Watch(
+		&source.Kind{Type: &snapshotv1alpha1.SnapshotPolicy{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			policy, ok := obj.(*snapshotv1alpha1.SnapshotPolicy)
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Spec.Repository}},
+			}
+		}),
+	); err != nil {
+		return err
+	}
+
+	// Watch Secrets, to copy the secure settings of the repositories when they change, and to restore the copies
+	if err := c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			requests, err := requestsForSecret(k8sClient, obj)
+			if err != nil {
+				log.Error(err, "failed to list snapshot repositories", "namespace", obj.GetNamespace(), "secret_name", obj.GetName())
+				return nil
+			}
+			return requests
+		}),
+	); err != nil {
+		return err
+	}
+
+	// Watch Elasticsearch clusters, to configure repositories once the cluster becomes ready
+	return c.Watch(
+		&source.Kind{Type: &esv1.Elasticsearch{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			repositories, err := repositoriesReferencing(k8sClient, k8s.ExtractNamespacedName(obj))
+			if err != nil {
+				log.Error(err, "failed to list snapshot repositories", "namespace", obj.GetNamespace(), "es_name", obj.GetName())
+				return nil
+			}
+			requests := make([]reconcile.Request, 0, len(repositories))
+			for _, repository := range repositories {
+				requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&repository)})
+			}
+			return requests
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileSnapshotRepository{}
+
+// ReconcileSnapshotRepository registers snapshot repositories and their snapshot lifecycle management policies
+// in the referenced Elasticsearch clusters.
+type ReconcileSnapshotRepository struct {
+	k8s.Client
+	operator.Parameters
+	esClientProvider EsClientProvider
+	recorder         record.EventRecorder
+
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+// Reconcile registers the SnapshotRepository and the SnapshotPolicies referencing it in the Elasticsearch cluster,
+// after adding its secure settings to the keystore of the cluster.
+// Repositories and policies are not removed from Elasticsearch when the corresponding resources are deleted.
+func (r *ReconcileSnapshotRepository) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "repository_name", &r.iteration)()
+	tx, ctx := tracing.NewTransaction(ctx, r.Tracer, request.NamespacedName, "snapshot")
+	defer tracing.EndTransaction(tx)
+
+	// the secure settings of the repositories of the namespace are reconciled together, to remove the ones of deleted
+	// repositories or of repositories now referencing another cluster
+	if err := reconcileSecureSettingsSecrets(ctx, r.Client, r.recorder, request.Namespace, r.ServerSideApply); err != nil {
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	var repository snapshotv1alpha1.SnapshotRepository
+	if err := r.Get(ctx, request.NamespacedName, &repository); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	if common.IsUnmanaged(&repository) {
+		log.Info("Object is currently not managed by this controller. Skipping reconciliation", "namespace", repository.Namespace, "repository_name", repository.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if !r.ResourceSelector.Matches(&repository) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", repository.Namespace, "repository_name", repository.Name)
+		return reconcile.Result{}, nil
+	}
+
+	policies, err := policiesReferencing(r.Client, repository)
+	if err != nil {
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	results := r.doReconcile(ctx, &repository, policies)
+	if err := r.updateStatus(ctx, repository, policies); err != nil {
+		if apierrors.IsConflict(err) {
+			return results.WithResult(reconcile.Result{Requeue: true}).Aggregate()
+		}
+		results.WithError(err)
+	}
+	return results.Aggregate()
+}
+
+func (r *ReconcileSnapshotRepository) doReconcile(
+	ctx context.Context,
+	repository *snapshotv1alpha1.SnapshotRepository,
+	policies []snapshotv1alpha1.SnapshotPolicy,
+) *reconciler.Results {
+	results := &reconciler.Results{}
+
+	esClient, phase, err := r.esClient(ctx, *repository)
+	if err != nil {
+		setPhase(repository, policies, phase, err)
+		if phase == snapshotv1alpha1.FailedPhase {
+			// the spec must be fixed first
+			return results
+		}
+		return results.WithResult(defaultRequeue)
+	}
+	defer esClient.Close()
+
+	var settings map[string]interface{}
+	if repository.Spec.Settings != nil {
+		settings = repository.Spec.Settings.Data
+	}
+	err = esClient.PutSnapshotRepository(ctx, repository.Name, esclient.SnapshotRepository{
+		Type:     string(repository.Spec.Type),
+		Settings: settings,
+	})
+	if esclient.IsDryRun(err) {
+		// the policies cannot reference a repository which was not registered in dry-run mode
+		return results
+	}
+	if err != nil {
+		r.recorder.Eventf(repository, corev1.EventTypeWarning, events.EventReconciliationError, "Failed to register snapshot repository: %s", err.Error())
+		setPhase(repository, policies, snapshotv1alpha1.FailedPhase, err)
+		// credentials may not be in the keystore yet, retry later
+		return results.WithResult(defaultRequeue)
+	}
+	repository.Status.Phase = snapshotv1alpha1.ReadyPhase
+	repository.Status.Message = ""
+
+	for i := range policies {
+		policy := &policies[i]
+		err := esClient.PutSnapshotLifecyclePolicy(ctx, policy.Name, lifecyclePolicy(*policy))
+		if esclient.IsDryRun(err) {
+			continue
+		}
+		if err != nil {
+			r.recorder.Eventf(policy, corev1.EventTypeWarning, events.EventReconciliationError, "Failed to configure snapshot lifecycle policy: %s", err.Error())
+			policy.Status.Phase = snapshotv1alpha1.FailedPhase
+			policy.Status.Message = err.Error()
+			results.WithResult(defaultRequeue)
+			continue
+		}
+		policy.Status.Phase = snapshotv1alpha1.ReadyPhase
+		policy.Status.Message = ""
+	}
+	return results
+}
+
+// esClient returns a client to the Elasticsearch cluster referenced by the repository, or an error along with
+// the phase to report if the cluster cannot be configured.
+func (r *ReconcileSnapshotRepository) esClient(
+	ctx context.Context,
+	repository snapshotv1alpha1.SnapshotRepository,
+) (esclient.Client, snapshotv1alpha1.Phase, error) {
+	ref := repository.ElasticsearchRef()
+	if ref.IsExternal() {
+		return nil, snapshotv1alpha1.FailedPhase, fmt.Errorf("referenced Elasticsearch must be managed by the operator, secretName is not supported")
+	}
+	if ref.Namespace != repository.Namespace {
+		return nil, snapshotv1alpha1.FailedPhase, fmt.Errorf("referenced Elasticsearch %s must be in namespace %s", ref.NamespacedName(), repository.Namespace)
+	}
+	var es esv1.Elasticsearch
+	if err := r.Get(ctx, ref.NamespacedName(), &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, snapshotv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s not found", ref.NamespacedName())
+		}
+		return nil, snapshotv1alpha1.PendingPhase, err
+	}
+	if es.Status.Phase != esv1.ElasticsearchReadyPhase {
+		return nil, snapshotv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s is not ready", ref.NamespacedName())
+	}
+	if es.IsInMaintenance() {
+		return nil, snapshotv1alpha1.PendingPhase, fmt.Errorf("referenced Elasticsearch %s is in maintenance mode", ref.NamespacedName())
+	}
+	esClient, err := r.esClientProvider(ctx, r.Client, r.Dialer, es)
+	if err != nil {
+		return nil, snapshotv1alpha1.PendingPhase, err
+	}
+	return esClient, snapshotv1alpha1.ReadyPhase, nil
+}
+
+// setPhase reports the given phase on the repository, and a pending phase on the policies which cannot be
+// configured until the repository is registered.
+func setPhase(
+	repository *snapshotv1alpha1.SnapshotRepository,
+	policies []snapshotv1alpha1.SnapshotPolicy,
+	phase snapshotv1alpha1.Phase,
+	err error,
+) {
+	repository.Status.Phase = phase
+	repository.Status.Message = ""
+	if err != nil {
+		repository.Status.Message = err.Error()
+	}
+	for i := range policies {
+		policies[i].Status.Phase = snapshotv1alpha1.PendingPhase
+		policies[i].Status.Message = fmt.Sprintf("snapshot repository %s is not ready", repository.Name)
+	}
+}
+
+func lifecyclePolicy(policy snapshotv1alpha1.SnapshotPolicy) esclient.SnapshotLifecyclePolicy {
+	slm := esclient.SnapshotLifecyclePolicy{
+		Schedule:   policy.Spec.Schedule,
+		Name:       policy.SnapshotNameOrDefault(),
+		Repository: policy.Spec.Repository,
+	}
+	if policy.Spec.Config != nil {
+		slm.Config = policy.Spec.Config.Data
+	}
+	if retention := policy.Spec.Retention; retention != nil {
+		slm.Retention = &esclient.SnapshotLifecyclePolicyRetention{
+			ExpireAfter: retention.ExpireAfter,
+			MinCount:    retention.MinCount,
+			MaxCount:    retention.MaxCount,
+		}
+	}
+	return slm
+}
+
+// updateStatus updates the status of the repository and of the policies, if it has changed.
+func (r *ReconcileSnapshotRepository) updateStatus(
+	ctx context.Context,
+	repository snapshotv1alpha1.SnapshotRepository,
+	policies []snapshotv1alpha1.SnapshotPolicy,
+) error {
+	repository.Status.ObservedGeneration = repository.Generation
+	if err := r.updateStatusIfChanged(ctx, &repository, &snapshotv1alpha1.SnapshotRepository{}); err != nil {
+		return err
+	}
+	for i := range policies {
+		policies[i].Status.ObservedGeneration = policies[i].Generation
+		if err := r.updateStatusIfChanged(ctx, &policies[i], &snapshotv1alpha1.SnapshotPolicy{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileSnapshotRepository) updateStatusIfChanged(ctx context.Context, obj client.Object, current client.Object) error {
+	if err := r.Get(ctx, k8s.ExtractNamespacedName(obj), current); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(statusOf(current), statusOf(obj)) {
+		return nil
+	}
+	return r.Status().Update(ctx, obj)
+}
+
+func statusOf(obj client.Object) snapshotv1alpha1.SnapshotStatus {
+	switch o := obj.(type) {
+	case *snapshotv1alpha1.SnapshotRepository:
+		return o.Status
+	case *snapshotv1alpha1.SnapshotPolicy:
+		return o.Status
+	}
+	return snapshotv1alpha1.SnapshotStatus{}
+}
+
+// policiesReferencing returns the SnapshotPolicies referencing the given repository.
+func policiesReferencing(c k8s.Client, repository snapshotv1alpha1.SnapshotRepository) ([]snapshotv1alpha1.SnapshotPolicy, error) {
+	var policies snapshotv1alpha1.SnapshotPolicyList
+	if err := c.List(context.Background(), &policies, client.InNamespace(repository.Namespace)); err != nil {
+		return nil, err
+	}
+	matching := make([]snapshotv1alpha1.SnapshotPolicy, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		if policy.Spec.Repository == repository.Name {
+			matching = append(matching, policy)
+		}
+	}
+	return matching, nil
+}
@@ -0,0 +1,262 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	snapshotv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/snapshot/v1alpha1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+type fakeESClient struct {
+	esclient.Client
+	repositories map[string]esclient.SnapshotRepository
+	policies     map[string]esclient.SnapshotLifecyclePolicy
+}
+
+func newFakeESClient() *fakeESClient {
+	return &fakeESClient{
+		repositories: map[string]esclient.SnapshotRepository{},
+		policies:     map[string]esclient.SnapshotLifecyclePolicy{},
+	}
+}
+
+func (f *fakeESClient) PutSnapshotRepository(_ context.Context, name string, repository esclient.SnapshotRepository) error {
+	f.repositories[name] = repository
+	return nil
+}
+
+func (f *fakeESClient) PutSnapshotLifecyclePolicy(_ context.Context, name string, policy esclient.SnapshotLifecyclePolicy) error {
+	f.policies[name] = policy
+	return nil
+}
+
+func (f *fakeESClient) Close() {}
+
+func es(phase esv1.ElasticsearchOrchestrationPhase) *esv1.Elasticsearch {
+	return &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Status:     esv1.ElasticsearchStatus{Phase: phase},
+	}
+}
+
+func inMaintenance(es *esv1.Elasticsearch) *esv1.Elasticsearch {
+	es.Annotations = map[string]string{esv1.MaintenanceAnnotation: "true"}
+	return es
+}
+
+func repository(esRef commonv1.ObjectSelector) *snapshotv1alpha1.SnapshotRepository {
+	return &snapshotv1alpha1.SnapshotRepository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "repo"},
+		Spec: snapshotv1alpha1.SnapshotRepositorySpec{
+			ElasticsearchRef: esRef,
+			Type:             snapshotv1alpha1.S3RepositoryType,
+			Settings:         &commonv1.Config{Data: map[string]interface{}{"bucket": "my-bucket"}},
+			SecureSettings:   []commonv1.SecretSource{{SecretName: "s3-credentials"}},
+		},
+	}
+}
+
+func policy() *snapshotv1alpha1.SnapshotPolicy {
+	return &snapshotv1alpha1.SnapshotPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "nightly"},
+		Spec: snapshotv1alpha1.SnapshotPolicySpec{
+			Repository: "repo",
+			Schedule:   "0 30 1 * * ?",
+		},
+	}
+}
+
+func TestReconcileSnapshotRepository_Reconcile(t *testing.T) {
+	tests := []struct {
+		name               string
+		es                 *esv1.Elasticsearch
+		esRef              commonv1.ObjectSelector
+		wantRequeue        bool
+		wantRepository     bool
+		wantRepoPhase      snapshotv1alpha1.Phase
+		wantPolicyPhase    snapshotv1alpha1.Phase
+		wantPolicySnapshot string
+	}{
+		{
+			name:               "Elasticsearch ready: register the repository and the policy",
+			es:                 es(esv1.ElasticsearchReadyPhase),
+			esRef:              commonv1.ObjectSelector{Name: "es"},
+			wantRepository:     true,
+			wantRepoPhase:      snapshotv1alpha1.ReadyPhase,
+			wantPolicyPhase:    snapshotv1alpha1.ReadyPhase,
+			wantPolicySnapshot: "<nightly-{now/d}>",
+		},
+		{
+			name:            "Elasticsearch not ready: retry later",
+			es:              es(esv1.ElasticsearchApplyingChangesPhase),
+			esRef:           commonv1.ObjectSelector{Name: "es"},
+			wantRequeue:     true,
+			wantRepoPhase:   snapshotv1alpha1.PendingPhase,
+			wantPolicyPhase: snapshotv1alpha1.PendingPhase,
+		},
+		{
+			name:            "Elasticsearch in maintenance mode: retry later",
+			es:              inMaintenance(es(esv1.ElasticsearchReadyPhase)),
+			esRef:           commonv1.ObjectSelector{Name: "es"},
+			wantRequeue:     true,
+			wantRepoPhase:   snapshotv1alpha1.PendingPhase,
+			wantPolicyPhase: snapshotv1alpha1.PendingPhase,
+		},
+		{
+			name:            "Elasticsearch not found: retry later",
+			esRef:           commonv1.ObjectSelector{Name: "es"},
+			wantRequeue:     true,
+			wantRepoPhase:   snapshotv1alpha1.PendingPhase,
+			wantPolicyPhase: snapshotv1alpha1.PendingPhase,
+		},
+		{
+			name:            "Elasticsearch in another namespace: invalid",
+			es:              es(esv1.ElasticsearchReadyPhase),
+			esRef:           commonv1.ObjectSelector{Name: "es", Namespace: "other"},
+			wantRepoPhase:   snapshotv1alpha1.FailedPhase,
+			wantPolicyPhase: snapshotv1alpha1.PendingPhase,
+		},
+		{
+			name:            "Elasticsearch not managed by the operator: invalid",
+			es:              es(esv1.ElasticsearchReadyPhase),
+			esRef:           commonv1.ObjectSelector{SecretName: "external"},
+			wantRepoPhase:   snapshotv1alpha1.FailedPhase,
+			wantPolicyPhase: snapshotv1alpha1.PendingPhase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []runtime.Object{repository(tt.esRef), policy()}
+			if tt.es != nil {
+				objs = append(objs, tt.es)
+			}
+			c := k8s.NewFakeClient(objs...)
+			esClient := newFakeESClient()
+			r := &ReconcileSnapshotRepository{
+				Client: c,
+				esClientProvider: func(_ context.Context, _ k8s.Client, _ net.Dialer, _ esv1.Elasticsearch) (esclient.Client, error) {
+					return esClient, nil
+				},
+				recorder: record.NewFakeRecorder(10),
+			}
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "repo"}})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRequeue, res.RequeueAfter > 0)
+
+			var repo snapshotv1alpha1.SnapshotRepository
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "repo"}, &repo))
+			require.Equal(t, tt.wantRepoPhase, repo.Status.Phase)
+			var p snapshotv1alpha1.SnapshotPolicy
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "nightly"}, &p))
+			require.Equal(t, tt.wantPolicyPhase, p.Status.Phase)
+
+			if !tt.wantRepository {
+				require.Empty(t, esClient.repositories)
+				require.Empty(t, esClient.policies)
+				return
+			}
+			require.Equal(t, esclient.SnapshotRepository{Type: "s3", Settings: map[string]interface{}{"bucket": "my-bucket"}}, esClient.repositories["repo"])
+			require.Equal(t, tt.wantPolicySnapshot, esClient.policies["nightly"].Name)
+			require.Equal(t, "repo", esClient.policies["nightly"].Repository)
+		})
+	}
+}
+
+func Test_reconcileSecureSettingsSecrets(t *testing.T) {
+	otherRepo := repository(commonv1.ObjectSelector{Name: "other-es"})
+	otherRepo.Name = "other-repo"
+	otherRepo.Spec.SecureSettings = []commonv1.SecretSource{{
+		SecretName: "other-credentials",
+		Entries:    []commonv1.KeyToPath{{Key: "key", Path: "gcs.client.default.credentials_file"}},
+	}}
+	otherES := es(esv1.ElasticsearchReadyPhase)
+	otherES.Name = "other-es"
+	c := k8s.NewFakeClient(
+		es(esv1.ElasticsearchReadyPhase), otherES, repository(commonv1.ObjectSelector{Name: "es"}), otherRepo,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "s3-credentials"},
+			Data:       map[string][]byte{"s3.client.default.access_key": []byte("a"), "s3.client.default.secret_key": []byte("b")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other-credentials"},
+			Data:       map[string][]byte{"key": []byte("c"), "ignored": []byte("d")},
+		},
+	)
+	recorder := record.NewFakeRecorder(10)
+	getSecret := func(esName string) (corev1.Secret, error) {
+		var secret corev1.Secret
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: esv1.SnapshotCredentialsSecretName(esName)}, &secret)
+		return secret, err
+	}
+
+	// the secure settings are copied in a Secret owned by each cluster, added to its keystore
+	require.NoError(t, reconcileSecureSettingsSecrets(context.Background(), c, recorder, "ns", false))
+	secret, err := getSecret("es")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"s3.client.default.access_key": []byte("a"), "s3.client.default.secret_key": []byte("b")}, secret.Data)
+	require.Len(t, secret.OwnerReferences, 1)
+	require.Equal(t, "es", secret.OwnerReferences[0].Name)
+	secret, err = getSecret("other-es")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"gcs.client.default.credentials_file": []byte("c")}, secret.Data)
+
+	// a change limited to a repository is reflected in the Secret, which triggers the reconciliation of the cluster
+	otherRepo.Spec.ElasticsearchRef = commonv1.ObjectSelector{Name: "es"}
+	require.NoError(t, c.Update(context.Background(), otherRepo))
+	require.NoError(t, reconcileSecureSettingsSecrets(context.Background(), c, recorder, "ns", false))
+	secret, err = getSecret("es")
+	require.NoError(t, err)
+	require.Len(t, secret.Data, 3)
+	_, err = getSecret("other-es")
+	require.True(t, apierrors.IsNotFound(err))
+
+	// the Secret is deleted once no repository references the cluster
+	require.NoError(t, c.Delete(context.Background(), otherRepo))
+	require.NoError(t, c.Delete(context.Background(), repository(commonv1.ObjectSelector{Name: "es"})))
+	require.NoError(t, reconcileSecureSettingsSecrets(context.Background(), c, recorder, "ns", false))
+	_, err = getSecret("es")
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func Test_requestsForSecret(t *testing.T) {
+	c := k8s.NewFakeClient(repository(commonv1.ObjectSelector{Name: "es"}))
+	repoRequest := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "repo"}}}
+
+	// referenced Secret
+	requests, err := requestsForSecret(c, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "s3-credentials"}})
+	require.NoError(t, err)
+	require.Equal(t, repoRequest, requests)
+
+	// copy of the secure settings
+	requests, err = requestsForSecret(c, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns",
+		Name:      "es-es-snapshot-credentials",
+		Labels:    map[string]string{label.ClusterNameLabelName: "es"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, repoRequest, requests)
+
+	// unrelated Secret
+	requests, err = requestsForSecret(c, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}})
+	require.NoError(t, err)
+	require.Empty(t, requests)
+}
@@ -0,0 +1,176 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	snapshotv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/snapshot/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// reconcileSecureSettingsSecrets copies the secure settings of the SnapshotRepositories of the given namespace in a
+// Secret owned by each Elasticsearch cluster they reference, which adds it to its keystore. Secrets of clusters which
+// are not referenced anymore are deleted.
+func reconcileSecureSettingsSecrets(ctx context.Context, c k8s.Client, recorder record.EventRecorder, namespace string, serverSideApply bool) error {
+	var clusters esv1.ElasticsearchList
+	if err := c.List(ctx, &clusters, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range clusters.Items {
+		if err := reconcileSecureSettingsSecret(ctx, c, recorder, clusters.Items[i], serverSideApply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileSecureSettingsSecret copies the secure settings of all the SnapshotRepositories referencing the given
+// Elasticsearch cluster in a Secret owned by the cluster, or deletes that Secret if there are none.
+func reconcileSecureSettingsSecret(ctx context.Context, c k8s.Client, recorder record.EventRecorder, es esv1.Elasticsearch, serverSideApply bool) error {
+	repositories, err := repositoriesReferencing(c, k8s.ExtractNamespacedName(&es))
+	if err != nil {
+		return err
+	}
+	data := map[string][]byte{}
+	for i := range repositories {
+		if err := copySecureSettings(ctx, c, recorder, &repositories[i], data); err != nil {
+			return err
+		}
+	}
+
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esv1.SnapshotCredentialsSecretName(es.Name),
+			Namespace: es.Namespace,
+			Labels:    label.NewLabels(k8s.ExtractNamespacedName(&es)),
+		},
+		Data: data,
+	}
+	if len(data) == 0 {
+		if err := c.Delete(ctx, &expected); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	_, err = reconciler.ReconcileSecret(c, expected, &es, serverSideApply)
+	return err
+}
+
+// copySecureSettings copies the entries of the Secrets referenced in the secure settings of the given repository in
+// the given data. Missing Secrets are reported with an event and skipped.
+func copySecureSettings(
+	ctx context.Context,
+	c k8s.Client,
+	recorder record.EventRecorder,
+	repository *snapshotv1alpha1.SnapshotRepository,
+	data map[string][]byte,
+) error {
+	for _, source := range repository.Spec.SecureSettings {
+		var secret corev1.Secret
+		err := c.Get(ctx, types.NamespacedName{Namespace: repository.Namespace, Name: source.SecretName}, &secret)
+		if apierrors.IsNotFound(err) {
+			recorder.Event(repository, corev1.EventTypeWarning, events.EventReasonUnexpected, "Secure settings secret not found: "+source.SecretName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(source.Entries) == 0 {
+			for key, value := range secret.Data {
+				data[key] = value
+			}
+			continue
+		}
+		for _, entry := range source.Entries {
+			value, exists := secret.Data[entry.Key]
+			if !exists {
+				return fmt.Errorf("key %s not found in secure settings secret %s/%s", entry.Key, repository.Namespace, source.SecretName)
+			}
+			key := entry.Key
+			if entry.Path != "" {
+				key = entry.Path
+			}
+			data[key] = value
+		}
+	}
+	return nil
+}
+
+// requestsForSecret returns the SnapshotRepositories to reconcile on a change to the given Secret: the ones
+// referencing it in their secure settings, or the ones whose secure settings it holds.
+func requestsForSecret(c k8s.Client, secret client.Object) ([]reconcile.Request, error) {
+	var repositories []snapshotv1alpha1.SnapshotRepository
+	var err error
+	if esName, exists := secret.GetLabels()[label.ClusterNameLabelName]; exists && secret.GetName() == esv1.SnapshotCredentialsSecretName(esName) {
+		repositories, err = repositoriesReferencing(c, types.NamespacedName{Namespace: secret.GetNamespace(), Name: esName})
+	} else {
+		repositories, err = repositoriesReferencingSecret(c, k8s.ExtractNamespacedName(secret))
+	}
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]reconcile.Request, 0, len(repositories))
+	for _, repository := range repositories {
+		requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&repository)})
+	}
+	return requests, nil
+}
+
+// repositoriesReferencing returns the SnapshotRepositories referencing the given Elasticsearch cluster.
+// Only repositories in the namespace of the cluster are considered.
+func repositoriesReferencing(c k8s.Client, es types.NamespacedName) ([]snapshotv1alpha1.SnapshotRepository, error) {
+	return listRepositories(c, es.Namespace, func(repository snapshotv1alpha1.SnapshotRepository) bool {
+		return repository.ElasticsearchRef().NamespacedName() == es
+	})
+}
+
+// repositoriesReferencingSecret returns the SnapshotRepositories referencing the given Secret in their secure settings.
+func repositoriesReferencingSecret(c k8s.Client, secret types.NamespacedName) ([]snapshotv1alpha1.SnapshotRepository, error) {
+	return listRepositories(c, secret.Namespace, func(repository snapshotv1alpha1.SnapshotRepository) bool {
+		for _, source := range repository.Spec.SecureSettings {
+			if source.SecretName == secret.Name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func listRepositories(
+	c k8s.Client,
+	namespace string,
+	matches func(snapshotv1alpha1.SnapshotRepository) bool,
+) ([]snapshotv1alpha1.SnapshotRepository, error) {
+	var repositories snapshotv1alpha1.SnapshotRepositoryList
+	if err := c.List(context.Background(), &repositories, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			// the SnapshotRepository CRD is not installed
+			return nil, nil
+		}
+		return nil, err
+	}
+	matching := make([]snapshotv1alpha1.SnapshotRepository, 0, len(repositories.Items))
+	for _, repository := range repositories.Items {
+		if matches(repository) {
+			matching = append(matching, repository)
+		}
+	}
+	return matching, nil
+}
@@ -0,0 +1,244 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package stackconfigpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
+)
+
+// AppliedConfigAnnotationName is the name of the annotation recording on an Elasticsearch cluster the configuration
+// applied by a StackConfigPolicy, to remove it once the policy no longer applies.
+const AppliedConfigAnnotationName = "stackconfigpolicy.k8s.elastic.co/applied-config"
+
+// appliedConfig identifies the configuration applied by a policy to an Elasticsearch cluster.
+type appliedConfig struct {
+	// Policy is the namespaced name of the policy.
+	Policy string `json:"policy"`
+	// ClusterSettings are the flattened keys of the persistent cluster settings.
+	ClusterSettings      []string `json:"clusterSettings,omitempty"`
+	SnapshotRepositories []string `json:"snapshotRepositories,omitempty"`
+	RoleMappings         []string `json:"roleMappings,omitempty"`
+}
+
+// newAppliedConfig returns the configuration applied by the given policy.
+func newAppliedConfig(policy types.NamespacedName, spec policyv1alpha1.ElasticsearchConfigPolicySpec) appliedConfig {
+	applied := appliedConfig{Policy: policy.String()}
+	if spec.ClusterSettings != nil {
+		for key := range flatten(spec.ClusterSettings.Data) {
+			applied.ClusterSettings = append(applied.ClusterSettings, key)
+		}
+		sort.Strings(applied.ClusterSettings)
+	}
+	if spec.SnapshotRepositories != nil {
+		applied.SnapshotRepositories = sortedKeys(spec.SnapshotRepositories.Data)
+	}
+	if spec.SecurityRoleMappings != nil {
+		applied.RoleMappings = sortedKeys(spec.SecurityRoleMappings.Data)
+	}
+	return applied
+}
+
+// appliedConfigOf returns the configuration applied to the given cluster by a policy, if any.
+func appliedConfigOf(es esv1.Elasticsearch) (appliedConfig, bool) {
+	value, exists := es.Annotations[AppliedConfigAnnotationName]
+	if !exists {
+		return appliedConfig{}, false
+	}
+	var applied appliedConfig
+	if err := json.Unmarshal([]byte(value), &applied); err != nil {
+		log.Error(err, "Ignoring invalid annotation", "namespace", es.Namespace, "es_name", es.Name, "annotation", AppliedConfigAnnotationName)
+		return appliedConfig{}, false
+	}
+	return applied, true
+}
+
+// without returns the configuration of a which is not in b.
+func (a appliedConfig) without(b appliedConfig) appliedConfig {
+	return appliedConfig{
+		Policy:               a.Policy,
+		ClusterSettings:      difference(a.ClusterSettings, b.ClusterSettings),
+		SnapshotRepositories: difference(a.SnapshotRepositories, b.SnapshotRepositories),
+		RoleMappings:         difference(a.RoleMappings, b.RoleMappings),
+	}
+}
+
+func difference(a, b []string) []string {
+	var diff []string
+	for _, s := range a {
+		if !stringsutil.StringInSlice(s, b) {
+			diff = append(diff, s)
+		}
+	}
+	return diff
+}
+
+// setAppliedConfig records the configuration applied to the given cluster, or removes the record if nil.
+func setAppliedConfig(ctx context.Context, c k8s.Client, es esv1.Elasticsearch, applied *appliedConfig) error {
+	annotations := maps.Merge(map[string]string{}, es.Annotations)
+	if applied == nil {
+		if _, exists := annotations[AppliedConfigAnnotationName]; !exists {
+			return nil
+		}
+		delete(annotations, AppliedConfigAnnotationName)
+	} else {
+		bytes, err := json.Marshal(applied)
+		if err != nil {
+			return err
+		}
+		if annotations[AppliedConfigAnnotationName] == string(bytes) {
+			return nil
+		}
+		annotations[AppliedConfigAnnotationName] = string(bytes)
+	}
+	es.Annotations = annotations
+	return c.Update(ctx, &es)
+}
+
+// configureCluster sets the cluster settings, snapshot repositories and role mappings of the policy which differ from
+// the ones of the cluster.
+func configureCluster(ctx context.Context, esClient esclient.Client, spec policyv1alpha1.ElasticsearchConfigPolicySpec) error {
+	if spec.ClusterSettings != nil && len(spec.ClusterSettings.Data) > 0 {
+		current, err := esClient.GetPersistentClusterSettings(ctx)
+		if err != nil {
+			return fmt.Errorf("while retrieving cluster settings: %w", err)
+		}
+		if !contains(flatten(current), flatten(spec.ClusterSettings.Data)) {
+			if err := esClient.UpdatePersistentClusterSettings(ctx, spec.ClusterSettings.Data); err != nil {
+				return fmt.Errorf("while updating cluster settings: %w", err)
+			}
+		}
+	}
+	if spec.SnapshotRepositories != nil && len(spec.SnapshotRepositories.Data) > 0 {
+		current, err := esClient.GetSnapshotRepositories(ctx)
+		if err != nil {
+			return fmt.Errorf("while retrieving snapshot repositories: %w", err)
+		}
+		for _, name := range sortedKeys(spec.SnapshotRepositories.Data) {
+			var repository esclient.SnapshotRepository
+			if err := convert(spec.SnapshotRepositories.Data[name], &repository); err != nil {
+				return fmt.Errorf("invalid snapshot repository %s: %w", name, err)
+			}
+			if existing, exists := current[name]; exists && existing.Type == repository.Type &&
+				equal(flatten(existing.Settings), flatten(repository.Settings)) {
+				continue
+			}
+			if err := esClient.PutSnapshotRepository(ctx, name, repository); err != nil {
+				return fmt.Errorf("while registering snapshot repository %s: %w", name, err)
+			}
+		}
+	}
+	if spec.SecurityRoleMappings != nil && len(spec.SecurityRoleMappings.Data) > 0 {
+		current, err := esClient.GetRoleMappings(ctx)
+		if err != nil {
+			return fmt.Errorf("while retrieving role mappings: %w", err)
+		}
+		for _, name := range sortedKeys(spec.SecurityRoleMappings.Data) {
+			var mapping esclient.SecurityObject
+			if err := convert(spec.SecurityRoleMappings.Data[name], &mapping); err != nil {
+				return fmt.Errorf("invalid role mapping %s: %w", name, err)
+			}
+			if existing, exists := current[name]; exists && equal(flatten(existing), flatten(mapping)) {
+				continue
+			}
+			if err := esClient.PutRoleMapping(ctx, name, mapping); err != nil {
+				return fmt.Errorf("while creating role mapping %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// removeConfig resets the given cluster settings, and deletes the given snapshot repositories and role mappings.
+func removeConfig(ctx context.Context, esClient esclient.Client, config appliedConfig) error {
+	if len(config.ClusterSettings) > 0 {
+		settings := make(map[string]interface{}, len(config.ClusterSettings))
+		for _, key := range config.ClusterSettings {
+			settings[key] = nil
+		}
+		if err := esClient.UpdatePersistentClusterSettings(ctx, settings); err != nil {
+			return fmt.Errorf("while resetting cluster settings: %w", err)
+		}
+	}
+	for _, name := range config.SnapshotRepositories {
+		if err := esClient.DeleteSnapshotRepository(ctx, name); err != nil && !esclient.IsNotFound(err) {
+			return fmt.Errorf("while deleting snapshot repository %s: %w", name, err)
+		}
+	}
+	for _, name := range config.RoleMappings {
+		if err := esClient.DeleteRoleMapping(ctx, name); err != nil && !esclient.IsNotFound(err) {
+			return fmt.Errorf("while deleting role mapping %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// flatten returns the given settings indexed by flattened key, with their values formatted as strings to compare them
+// with the ones returned by Elasticsearch, which formats scalar values as strings. Null values are ignored.
+func flatten(settings map[string]interface{}) map[string]string {
+	flat := map[string]string{}
+	flattenInto("", settings, flat)
+	return flat
+}
+
+func flattenInto(prefix string, settings map[string]interface{}, flat map[string]string) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			flattenInto(key, v, flat)
+		case esclient.SecurityObject:
+			flattenInto(key, v, flat)
+		default:
+			flat[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// contains returns true if all the entries of subset are in set.
+func contains(set, subset map[string]string) bool {
+	for key, value := range subset {
+		if existing, exists := set[key]; !exists || existing != value {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b map[string]string) bool {
+	return len(a) == len(b) && contains(a, b)
+}
+
+// convert converts a definition from the policy into the given Elasticsearch API object.
+func convert(definition interface{}, into interface{}) error {
+	bytes, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, into)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package stackconfigpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func Test_configureCluster(t *testing.T) {
+	spec := policyv1alpha1.ElasticsearchConfigPolicySpec{
+		ClusterSettings: &commonv1.Config{Data: map[string]interface{}{
+			"indices": map[string]interface{}{"recovery": map[string]interface{}{"max_concurrent_file_chunks": float64(5)}},
+		}},
+		SnapshotRepositories: &commonv1.Config{Data: map[string]interface{}{
+			"backups": map[string]interface{}{"type": "fs", "settings": map[string]interface{}{"location": "/mnt/backups", "compress": true}},
+		}},
+	}
+	tests := []struct {
+		name         string
+		settings     map[string]interface{}
+		repositories map[string]esclient.SnapshotRepository
+		wantUpdates  int
+	}{
+		{
+			name:         "nothing configured",
+			repositories: map[string]esclient.SnapshotRepository{},
+			wantUpdates:  2,
+		},
+		{
+			name:     "already configured, with values formatted by Elasticsearch",
+			settings: map[string]interface{}{"indices.recovery.max_concurrent_file_chunks": "5", "cluster.routing.allocation.enable": "all"},
+			repositories: map[string]esclient.SnapshotRepository{
+				"backups": {Type: "fs", Settings: map[string]interface{}{"location": "/mnt/backups", "compress": "true"}},
+			},
+			wantUpdates: 0,
+		},
+		{
+			name:     "different values",
+			settings: map[string]interface{}{"indices.recovery.max_concurrent_file_chunks": "2"},
+			repositories: map[string]esclient.SnapshotRepository{
+				"backups": {Type: "fs", Settings: map[string]interface{}{"location": "/mnt/backups"}},
+			},
+			wantUpdates: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			esClient := newFakeESClient()
+			esClient.settings = tt.settings
+			esClient.repositories = tt.repositories
+			require.NoError(t, configureCluster(context.Background(), esClient, spec))
+			require.Equal(t, tt.wantUpdates, esClient.updates)
+		})
+	}
+}
+
+func Test_appliedConfig_without(t *testing.T) {
+	previous := appliedConfig{
+		Policy:               "ns/policy",
+		ClusterSettings:      []string{"a.b", "c"},
+		SnapshotRepositories: []string{"backups"},
+		RoleMappings:         []string{"admins"},
+	}
+	current := appliedConfig{
+		Policy:          "ns/policy",
+		ClusterSettings: []string{"a.b"},
+		RoleMappings:    []string{"admins"},
+	}
+	require.Equal(t, appliedConfig{
+		Policy:               "ns/policy",
+		ClusterSettings:      []string{"c"},
+		SnapshotRepositories: []string{"backups"},
+	}, previous.without(current))
+}
@@ -0,0 +1,501 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package stackconfigpolicy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	name = "stackconfigpolicy-controller"
+)
+
+var (
+	log = ulog.Log.WithName(name)
+
+	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+	// resyncPeriod is the interval at which the policies are applied again, to revert the changes made directly
+	// through the Elasticsearch APIs.
+	resyncPeriod = reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Minute}
+)
+
+// EsClientProvider returns a client to the given Elasticsearch cluster.
+type EsClientProvider func(ctx context.Context, c k8s.Client, dialer net.Dialer, es esv1.Elasticsearch) (esclient.Client, error)
+
+// Add creates a new StackConfigPolicy controller and adds it to the manager with default RBAC.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	r := NewReconciler(mgr, params)
+	c, err := common.NewController(mgr, name, r, params)
+	if err != nil {
+		return err
+	}
+	return addWatches(c, r)
+}
+
+// NewReconciler returns a new reconcile.Reconciler
+func NewReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileStackConfigPolicy {
+	return &ReconcileStackConfigPolicy{
+		Client:           mgr.GetClient(),
+		Parameters:       params,
+		esClientProvider: user.ControllerUserClientProvider(params.DryRun),
+		recorder:         mgr.GetEventRecorderFor(name),
+	}
+}
+
+func addWatches(c controller.Controller, r *ReconcileStackConfigPolicy) error {
+	// Watch StackConfigPolicies
+	if err := c.Watch(&source.Kind{Type: &policyv1alpha1.StackConfigPolicy{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// Watch Elasticsearch clusters, to configure them once they are ready or when their labels change
+	if err := c.Watch(
+		&source.Kind{Type: &esv1.Elasticsearch{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			es, ok := obj.(*esv1.Elasticsearch)
+			if !ok {
+				return nil
+			}
+			policies, err := r.policiesFor(*es)
+			if err != nil {
+				log.Error(err, "failed to list stack config policies", "namespace", es.Namespace, "es_name", es.Name)
+				return nil
+			}
+			return requestsFor(policies)
+		}),
+	); err != nil {
+		return err
+	}
+
+	// Watch Secrets, to copy the secure settings referenced by the policies when they change, and to restore the
+	// copies in the namespace of the clusters
+	return c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			if policyName, exists := obj.GetLabels()[PolicyNameLabelName]; exists {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{
+					Namespace: obj.GetLabels()[PolicyNamespaceLabelName],
+					Name:      policyName,
+				}}}
+			}
+			policies, err := policiesReferencingSecret(r.Client, k8s.ExtractNamespacedName(obj))
+			if err != nil {
+				log.Error(err, "failed to list stack config policies", "namespace", obj.GetNamespace(), "secret_name", obj.GetName())
+				return nil
+			}
+			return requestsFor(policies)
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileStackConfigPolicy{}
+
+// ReconcileStackConfigPolicy applies the StackConfigPolicies to the Elasticsearch clusters they select.
+type ReconcileStackConfigPolicy struct {
+	k8s.Client
+	operator.Parameters
+	esClientProvider EsClientProvider
+	recorder         record.EventRecorder
+
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+// Reconcile applies the StackConfigPolicy to the Elasticsearch clusters it selects, and removes its configuration from
+// the clusters it no longer applies to.
+func (r *ReconcileStackConfigPolicy) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "policy_name", &r.iteration)()
+	tx, ctx := tracing.NewTransaction(ctx, r.Tracer, request.NamespacedName, "stackconfigpolicy")
+	defer tracing.EndTransaction(tx)
+
+	var policy policyv1alpha1.StackConfigPolicy
+	if err := r.Get(ctx, request.NamespacedName, &policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			// remove the configuration and the secure settings of the deleted policy
+			results := r.removeAppliedConfigs(ctx, request.NamespacedName, nil)
+			if err := r.deleteSecureSettingsSecrets(ctx, request.NamespacedName, nil); err != nil {
+				results.WithError(tracing.CaptureError(ctx, err))
+			}
+			return results.Aggregate()
+		}
+		return reconcile.Result{}, tracing.CaptureError(ctx, err)
+	}
+
+	if common.IsUnmanaged(&policy) {
+		log.Info("Object is currently not managed by this controller. Skipping reconciliation", "namespace", policy.Namespace, "policy_name", policy.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if !r.ResourceSelector.Matches(&policy) {
+		log.Info("Object is not selected by the resource label selector of this operator. Skipping reconciliation", "namespace", policy.Namespace, "policy_name", policy.Name)
+		return reconcile.Result{}, nil
+	}
+
+	results := r.doReconcile(ctx, &policy)
+	if err := r.updateStatus(ctx, policy); err != nil {
+		if apierrors.IsConflict(err) {
+			return results.WithResult(reconcile.Result{Requeue: true}).Aggregate()
+		}
+		results.WithError(err)
+	}
+	return results.Aggregate()
+}
+
+func (r *ReconcileStackConfigPolicy) doReconcile(ctx context.Context, policy *policyv1alpha1.StackConfigPolicy) *reconciler.Results {
+	results := &reconciler.Results{}
+	policy.Status = policyv1alpha1.StackConfigPolicyStatus{ResourcesStatuses: map[string]policyv1alpha1.ResourcePolicyStatus{}}
+
+	selected, err := r.selectedClusters(*policy)
+	if err != nil {
+		policy.Status.Phase = policyv1alpha1.ErrorPhase
+		r.recorder.Eventf(policy, corev1.EventTypeWarning, events.EventReasonValidation, "Invalid resource selector: %s", err.Error())
+		// the spec must be fixed first
+		return results
+	}
+
+	secureSettings, err := secureSettingsData(r.Client, *policy)
+	if err != nil {
+		r.recorder.Eventf(policy, corev1.EventTypeWarning, events.EventReconciliationError, "Failed to read secure settings: %s", err.Error())
+		policy.Status.Phase = policyv1alpha1.ErrorPhase
+		return results.WithResult(defaultRequeue)
+	}
+
+	var policies policyv1alpha1.StackConfigPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return results.WithError(err)
+	}
+
+	for _, es := range selected {
+		key := policyv1alpha1.ResourceKey(k8s.ExtractNamespacedName(&es))
+		if conflicting := r.conflictingPolicies(*policy, policies.Items, es); len(conflicting) > 0 {
+			// do not configure the cluster until the conflict is resolved
+			policy.Status.ResourcesStatuses[key] = policyv1alpha1.ResourcePolicyStatus{
+				Phase:   policyv1alpha1.ConflictPhase,
+				Message: fmt.Sprintf("cluster also selected by policies %s", strings.Join(conflicting, ", ")),
+			}
+			if err := deleteSecureSettingsSecret(r.Client, *policy, k8s.ExtractNamespacedName(&es)); err != nil {
+				results.WithError(err)
+			}
+			continue
+		}
+		status, res := r.applyPolicy(ctx, *policy, es, secureSettings)
+		policy.Status.ResourcesStatuses[key] = status
+		results.WithResults(res)
+	}
+
+	// remove the configuration and the secure settings of the clusters which are no longer selected
+	results.WithResults(r.removeAppliedConfigs(ctx, k8s.ExtractNamespacedName(policy), policy.Status.ResourcesStatuses))
+	if err := r.deleteSecureSettingsSecrets(ctx, k8s.ExtractNamespacedName(policy), policy.Status.ResourcesStatuses); err != nil {
+		results.WithError(err)
+	}
+
+	policy.Status.Phase = aggregatePhase(&policy.Status)
+	return results.WithResult(resyncPeriod)
+}
+
+// applyPolicy applies the policy to the given cluster and returns the resulting status.
+func (r *ReconcileStackConfigPolicy) applyPolicy(
+	ctx context.Context,
+	policy policyv1alpha1.StackConfigPolicy,
+	es esv1.Elasticsearch,
+	secureSettings map[string][]byte,
+) (policyv1alpha1.ResourcePolicyStatus, *reconciler.Results) {
+	results := &reconciler.Results{}
+	if es.IsInMaintenance() {
+		// neither the settings nor the secure settings of the cluster are changed until the maintenance mode ends
+		return policyv1alpha1.ResourcePolicyStatus{
+			Phase:   policyv1alpha1.ApplyingChangesPhase,
+			Message: "cluster is in maintenance mode",
+		}, results.WithResult(defaultRequeue)
+	}
+	if err := reconcileSecureSettingsSecret(r.Client, policy, es, secureSettings, r.ServerSideApply); err != nil {
+		return errorStatus(err), results.WithError(err)
+	}
+
+	if es.Status.Phase != esv1.ElasticsearchReadyPhase {
+		return policyv1alpha1.ResourcePolicyStatus{
+			Phase:   policyv1alpha1.ApplyingChangesPhase,
+			Message: "cluster is not ready",
+		}, results.WithResult(defaultRequeue)
+	}
+
+	esClient, err := r.esClientProvider(ctx, r.Client, r.Dialer, es)
+	if err != nil {
+		return errorStatus(err), results.WithResult(defaultRequeue)
+	}
+	defer esClient.Close()
+
+	err = configureCluster(ctx, esClient, policy.Spec.Elasticsearch)
+	if esclient.IsDryRun(err) {
+		// the configuration was not applied in dry-run mode
+		return policyv1alpha1.ResourcePolicyStatus{Phase: policyv1alpha1.ApplyingChangesPhase}, results
+	}
+	if err != nil {
+		r.recorder.Eventf(&policy, corev1.EventTypeWarning, events.EventReconciliationError,
+			"Failed to configure Elasticsearch %s/%s: %s", es.Namespace, es.Name, err.Error())
+		// secure settings may not be in the keystore yet, retry later
+		return errorStatus(err), results.WithResult(defaultRequeue)
+	}
+
+	// remove the configuration previously applied by this policy or another one which is not part of the policy anymore
+	applied := newAppliedConfig(k8s.ExtractNamespacedName(&policy), policy.Spec.Elasticsearch)
+	if previous, exists := appliedConfigOf(es); exists {
+		err := removeConfig(ctx, esClient, previous.without(applied))
+		if esclient.IsDryRun(err) {
+			return policyv1alpha1.ResourcePolicyStatus{Phase: policyv1alpha1.ApplyingChangesPhase}, results
+		}
+		if err != nil {
+			return errorStatus(err), results.WithResult(defaultRequeue)
+		}
+	}
+	if err := setAppliedConfig(ctx, r.Client, es, &applied); err != nil {
+		return errorStatus(err), results.WithError(err)
+	}
+	return policyv1alpha1.ResourcePolicyStatus{Phase: policyv1alpha1.ReadyPhase}, results
+}
+
+// removeAppliedConfigs removes the configuration applied by the given policy from the clusters which are not in the
+// given resources statuses, or in conflict.
+func (r *ReconcileStackConfigPolicy) removeAppliedConfigs(
+	ctx context.Context,
+	policy types.NamespacedName,
+	statuses map[string]policyv1alpha1.ResourcePolicyStatus,
+) *reconciler.Results {
+	results := &reconciler.Results{}
+	var clusters esv1.ElasticsearchList
+	if err := r.List(ctx, &clusters); err != nil {
+		return results.WithError(err)
+	}
+	for _, es := range clusters.Items {
+		applied, exists := appliedConfigOf(es)
+		if !exists || applied.Policy != policy.String() {
+			continue
+		}
+		if status, exists := statuses[policyv1alpha1.ResourceKey(k8s.ExtractNamespacedName(&es))]; exists && status.Phase != policyv1alpha1.ConflictPhase {
+			continue
+		}
+		results.WithResults(r.removeAppliedConfig(ctx, es, applied))
+	}
+	return results
+}
+
+// removeAppliedConfig removes the given configuration from the cluster, once it is ready.
+func (r *ReconcileStackConfigPolicy) removeAppliedConfig(ctx context.Context, es esv1.Elasticsearch, applied appliedConfig) *reconciler.Results {
+	results := &reconciler.Results{}
+	if es.Status.Phase != esv1.ElasticsearchReadyPhase || es.IsInMaintenance() {
+		return results.WithResult(defaultRequeue)
+	}
+	esClient, err := r.esClientProvider(ctx, r.Client, r.Dialer, es)
+	if err != nil {
+		return results.WithResult(defaultRequeue)
+	}
+	defer esClient.Close()
+	err = removeConfig(ctx, esClient, applied)
+	if esclient.IsDryRun(err) {
+		return results
+	}
+	if err != nil {
+		log.Error(err, "Failed to remove the configuration of a stack config policy", "namespace", es.Namespace, "es_name", es.Name, "policy", applied.Policy)
+		return results.WithResult(defaultRequeue)
+	}
+	return results.WithError(setAppliedConfig(ctx, r.Client, es, nil))
+}
+
+func errorStatus(err error) policyv1alpha1.ResourcePolicyStatus {
+	return policyv1alpha1.ResourcePolicyStatus{Phase: policyv1alpha1.ErrorPhase, Message: err.Error()}
+}
+
+// aggregatePhase computes the counters of the status from the resources statuses, and returns the phase of the
+// policy: the most severe phase of the resources.
+func aggregatePhase(status *policyv1alpha1.StackConfigPolicyStatus) policyv1alpha1.PolicyPhase {
+	status.Resources = len(status.ResourcesStatuses)
+	phases := map[policyv1alpha1.PolicyPhase]int{}
+	for _, resourceStatus := range status.ResourcesStatuses {
+		phases[resourceStatus.Phase]++
+	}
+	status.Ready = phases[policyv1alpha1.ReadyPhase]
+	status.Errors = phases[policyv1alpha1.ErrorPhase]
+	for _, phase := range []policyv1alpha1.PolicyPhase{
+		policyv1alpha1.ErrorPhase,
+		policyv1alpha1.ConflictPhase,
+		policyv1alpha1.ApplyingChangesPhase,
+	} {
+		if phases[phase] > 0 {
+			return phase
+		}
+	}
+	return policyv1alpha1.ReadyPhase
+}
+
+// selectedClusters returns the Elasticsearch clusters selected by the policy: in all namespaces for a policy in the
+// namespace of the operator, in the namespace of the policy otherwise.
+func (r *ReconcileStackConfigPolicy) selectedClusters(policy policyv1alpha1.StackConfigPolicy) ([]esv1.Elasticsearch, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.ResourceSelector)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+	if policy.Namespace != r.OperatorNamespace {
+		opts = append(opts, client.InNamespace(policy.Namespace))
+	}
+	var clusters esv1.ElasticsearchList
+	if err := r.List(context.Background(), &clusters, opts...); err != nil {
+		return nil, err
+	}
+	return clusters.Items, nil
+}
+
+// selects returns whether the given policy applies to the given cluster.
+func (r *ReconcileStackConfigPolicy) selects(policy policyv1alpha1.StackConfigPolicy, es esv1.Elasticsearch) bool {
+	if policy.Namespace != r.OperatorNamespace && policy.Namespace != es.Namespace {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.ResourceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(es.Labels))
+}
+
+// conflictingPolicies returns the names of the policies other than the given one which also select the cluster.
+func (r *ReconcileStackConfigPolicy) conflictingPolicies(
+	policy policyv1alpha1.StackConfigPolicy,
+	policies []policyv1alpha1.StackConfigPolicy,
+	es esv1.Elasticsearch,
+) []string {
+	var conflicting []string
+	for _, other := range policies {
+		if other.Namespace == policy.Namespace && other.Name == policy.Name {
+			continue
+		}
+		if r.selects(other, es) {
+			conflicting = append(conflicting, k8s.ExtractNamespacedName(&other).String())
+		}
+	}
+	sort.Strings(conflicting)
+	return conflicting
+}
+
+// policiesFor returns the policies which select the given cluster, or which were applied to it.
+func (r *ReconcileStackConfigPolicy) policiesFor(es esv1.Elasticsearch) ([]policyv1alpha1.StackConfigPolicy, error) {
+	var policies policyv1alpha1.StackConfigPolicyList
+	if err := r.List(context.Background(), &policies); err != nil {
+		return nil, err
+	}
+	key := policyv1alpha1.ResourceKey(k8s.ExtractNamespacedName(&es))
+	var matching []policyv1alpha1.StackConfigPolicy
+	for _, policy := range policies.Items {
+		if _, applied := policy.Status.ResourcesStatuses[key]; applied || r.selects(policy, es) {
+			matching = append(matching, policy)
+		}
+	}
+	return matching, nil
+}
+
+// policiesReferencingSecret returns the policies whose secure settings reference the given Secret.
+func policiesReferencingSecret(c k8s.Client, secret types.NamespacedName) ([]policyv1alpha1.StackConfigPolicy, error) {
+	var policies policyv1alpha1.StackConfigPolicyList
+	if err := c.List(context.Background(), &policies, client.InNamespace(secret.Namespace)); err != nil {
+		return nil, err
+	}
+	var matching []policyv1alpha1.StackConfigPolicy
+	for _, policy := range policies.Items {
+		for _, source := range policy.Spec.SecureSettings {
+			if source.SecretName == secret.Name {
+				matching = append(matching, policy)
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+func requestsFor(policies []policyv1alpha1.StackConfigPolicy) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(policies))
+	for _, policy := range policies {
+		requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&policy)})
+	}
+	return requests
+}
+
+// deleteSecureSettingsSecrets deletes the secure settings Secrets copied from the given policy to the clusters which
+// are not in the given resources statuses, or in conflict.
+func (r *ReconcileStackConfigPolicy) deleteSecureSettingsSecrets(
+	ctx context.Context,
+	policy types.NamespacedName,
+	statuses map[string]policyv1alpha1.ResourcePolicyStatus,
+) error {
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.MatchingLabels{
+		PolicyNamespaceLabelName: policy.Namespace,
+		PolicyNameLabelName:      policy.Name,
+	}); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := secrets.Items[i]
+		if status, exists := statuses[policyv1alpha1.ResourceKey(esNameFromSecret(secret))]; exists && status.Phase != policyv1alpha1.ConflictPhase {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// esNameFromSecret returns the cluster a secure settings Secret was copied to.
+func esNameFromSecret(secret corev1.Secret) types.NamespacedName {
+	return types.NamespacedName{Namespace: secret.Namespace, Name: secret.Labels[label.ClusterNameLabelName]}
+}
+
+// updateStatus updates the status of the policy, if it has changed.
+func (r *ReconcileStackConfigPolicy) updateStatus(ctx context.Context, policy policyv1alpha1.StackConfigPolicy) error {
+	policy.Status.ObservedGeneration = policy.Generation
+	if len(policy.Status.ResourcesStatuses) == 0 {
+		// omitted when empty, compare with the status as returned by the API server
+		policy.Status.ResourcesStatuses = nil
+	}
+	var current policyv1alpha1.StackConfigPolicy
+	if err := r.Get(ctx, k8s.ExtractNamespacedName(&policy), &current); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current.Status, policy.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, &policy)
+}
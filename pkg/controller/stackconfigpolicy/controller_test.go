@@ -0,0 +1,353 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package stackconfigpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+type fakeESClient struct {
+	esclient.Client
+	settings     map[string]interface{}
+	repositories map[string]esclient.SnapshotRepository
+	roleMappings map[string]esclient.SecurityObject
+	// updates is the number of requests updating the configuration
+	updates int
+}
+
+func newFakeESClient() *fakeESClient {
+	return &fakeESClient{
+		repositories: map[string]esclient.SnapshotRepository{},
+		roleMappings: map[string]esclient.SecurityObject{},
+	}
+}
+
+func (f *fakeESClient) GetPersistentClusterSettings(_ context.Context) (map[string]interface{}, error) {
+	return f.settings, nil
+}
+
+func (f *fakeESClient) UpdatePersistentClusterSettings(_ context.Context, settings map[string]interface{}) error {
+	f.updates++
+	if f.settings == nil {
+		f.settings = map[string]interface{}{}
+	}
+	for key, value := range settings {
+		if value == nil {
+			delete(f.settings, key)
+			continue
+		}
+		f.settings[key] = value
+	}
+	return nil
+}
+
+func (f *fakeESClient) GetSnapshotRepositories(_ context.Context) (map[string]esclient.SnapshotRepository, error) {
+	return f.repositories, nil
+}
+
+func (f *fakeESClient) PutSnapshotRepository(_ context.Context, name string, repository esclient.SnapshotRepository) error {
+	f.updates++
+	f.repositories[name] = repository
+	return nil
+}
+
+func (f *fakeESClient) DeleteSnapshotRepository(_ context.Context, name string) error {
+	f.updates++
+	delete(f.repositories, name)
+	return nil
+}
+
+func (f *fakeESClient) GetRoleMappings(_ context.Context) (map[string]esclient.SecurityObject, error) {
+	return f.roleMappings, nil
+}
+
+func (f *fakeESClient) PutRoleMapping(_ context.Context, name string, mapping esclient.SecurityObject) error {
+	f.updates++
+	f.roleMappings[name] = mapping
+	return nil
+}
+
+func (f *fakeESClient) DeleteRoleMapping(_ context.Context, name string) error {
+	f.updates++
+	delete(f.roleMappings, name)
+	return nil
+}
+
+func (f *fakeESClient) Close() {}
+
+func es(namespace, name string, phase esv1.ElasticsearchOrchestrationPhase, labels map[string]string) *esv1.Elasticsearch {
+	return &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Status:     esv1.ElasticsearchStatus{Phase: phase},
+	}
+}
+
+func inMaintenance(es *esv1.Elasticsearch) *esv1.Elasticsearch {
+	es.Annotations = map[string]string{esv1.MaintenanceAnnotation: "true"}
+	return es
+}
+
+func stackConfigPolicy(namespace, name string, matchLabels map[string]string) *policyv1alpha1.StackConfigPolicy {
+	return &policyv1alpha1.StackConfigPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: policyv1alpha1.StackConfigPolicySpec{
+			ResourceSelector: metav1.LabelSelector{MatchLabels: matchLabels},
+			SecureSettings:   []commonv1.SecretSource{{SecretName: "s3-credentials"}},
+			Elasticsearch: policyv1alpha1.ElasticsearchConfigPolicySpec{
+				ClusterSettings: &commonv1.Config{Data: map[string]interface{}{
+					"indices.recovery.max_bytes_per_sec": "100mb",
+				}},
+				SnapshotRepositories: &commonv1.Config{Data: map[string]interface{}{
+					"backups": map[string]interface{}{"type": "s3", "settings": map[string]interface{}{"bucket": "my-bucket"}},
+				}},
+				SecurityRoleMappings: &commonv1.Config{Data: map[string]interface{}{
+					"admins": map[string]interface{}{"roles": []interface{}{"superuser"}, "enabled": true},
+				}},
+			},
+		},
+	}
+}
+
+func s3Credentials(namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "s3-credentials"},
+		Data:       map[string][]byte{"s3.client.default.access_key": []byte("key")},
+	}
+}
+
+func TestReconcileStackConfigPolicy_Reconcile(t *testing.T) {
+	prod := map[string]string{"env": "prod"}
+	tests := []struct {
+		name            string
+		policy          *policyv1alpha1.StackConfigPolicy
+		objs            []runtime.Object
+		wantPhase       policyv1alpha1.PolicyPhase
+		wantStatuses    map[string]policyv1alpha1.PolicyPhase
+		wantConfigured  bool
+		wantSecretsIn   []types.NamespacedName
+		wantNoSecretsIn []types.NamespacedName
+	}{
+		{
+			name:   "policy in the namespace of the operator: configure the selected clusters of all namespaces",
+			policy: stackConfigPolicy("elastic-system", "policy", prod),
+			objs: []runtime.Object{
+				s3Credentials("elastic-system"),
+				es("ns1", "es", esv1.ElasticsearchReadyPhase, prod),
+				es("ns2", "es", esv1.ElasticsearchReadyPhase, prod),
+				es("ns2", "dev", esv1.ElasticsearchReadyPhase, map[string]string{"env": "dev"}),
+			},
+			wantPhase: policyv1alpha1.ReadyPhase,
+			wantStatuses: map[string]policyv1alpha1.PolicyPhase{
+				"ns1/es": policyv1alpha1.ReadyPhase,
+				"ns2/es": policyv1alpha1.ReadyPhase,
+			},
+			wantConfigured:  true,
+			wantSecretsIn:   []types.NamespacedName{{Namespace: "ns1", Name: "es"}, {Namespace: "ns2", Name: "es"}},
+			wantNoSecretsIn: []types.NamespacedName{{Namespace: "ns2", Name: "dev"}},
+		},
+		{
+			name:   "policy in another namespace: configure the selected clusters of its namespace only",
+			policy: stackConfigPolicy("ns1", "policy", prod),
+			objs: []runtime.Object{
+				s3Credentials("ns1"),
+				es("ns1", "es", esv1.ElasticsearchReadyPhase, prod),
+				es("ns2", "es", esv1.ElasticsearchReadyPhase, prod),
+			},
+			wantPhase:       policyv1alpha1.ReadyPhase,
+			wantStatuses:    map[string]policyv1alpha1.PolicyPhase{"ns1/es": policyv1alpha1.ReadyPhase},
+			wantConfigured:  true,
+			wantSecretsIn:   []types.NamespacedName{{Namespace: "ns1", Name: "es"}},
+			wantNoSecretsIn: []types.NamespacedName{{Namespace: "ns2", Name: "es"}},
+		},
+		{
+			name:   "cluster not ready: copy the secure settings and retry later",
+			policy: stackConfigPolicy("ns1", "policy", nil),
+			objs: []runtime.Object{
+				s3Credentials("ns1"),
+				es("ns1", "es", esv1.ElasticsearchApplyingChangesPhase, nil),
+			},
+			wantPhase:     policyv1alpha1.ApplyingChangesPhase,
+			wantStatuses:  map[string]policyv1alpha1.PolicyPhase{"ns1/es": policyv1alpha1.ApplyingChangesPhase},
+			wantSecretsIn: []types.NamespacedName{{Namespace: "ns1", Name: "es"}},
+		},
+		{
+			name:   "cluster in maintenance mode: do not copy the secure settings and retry later",
+			policy: stackConfigPolicy("ns1", "policy", nil),
+			objs: []runtime.Object{
+				s3Credentials("ns1"),
+				inMaintenance(es("ns1", "es", esv1.ElasticsearchReadyPhase, nil)),
+			},
+			wantPhase:       policyv1alpha1.ApplyingChangesPhase,
+			wantStatuses:    map[string]policyv1alpha1.PolicyPhase{"ns1/es": policyv1alpha1.ApplyingChangesPhase},
+			wantNoSecretsIn: []types.NamespacedName{{Namespace: "ns1", Name: "es"}},
+		},
+		{
+			name:   "cluster selected by several policies: conflict",
+			policy: stackConfigPolicy("ns1", "policy", nil),
+			objs: []runtime.Object{
+				s3Credentials("ns1"),
+				stackConfigPolicy("elastic-system", "other", prod),
+				es("ns1", "es", esv1.ElasticsearchReadyPhase, prod),
+				es("ns1", "dev", esv1.ElasticsearchReadyPhase, nil),
+			},
+			wantPhase: policyv1alpha1.ConflictPhase,
+			wantStatuses: map[string]policyv1alpha1.PolicyPhase{
+				"ns1/es":  policyv1alpha1.ConflictPhase,
+				"ns1/dev": policyv1alpha1.ReadyPhase,
+			},
+			wantConfigured:  true,
+			wantSecretsIn:   []types.NamespacedName{{Namespace: "ns1", Name: "dev"}},
+			wantNoSecretsIn: []types.NamespacedName{{Namespace: "ns1", Name: "es"}},
+		},
+		{
+			name:   "secure settings secret not found: error",
+			policy: stackConfigPolicy("ns1", "policy", nil),
+			objs: []runtime.Object{
+				es("ns1", "es", esv1.ElasticsearchReadyPhase, nil),
+			},
+			wantPhase:       policyv1alpha1.ErrorPhase,
+			wantNoSecretsIn: []types.NamespacedName{{Namespace: "ns1", Name: "es"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.NewFakeClient(append(tt.objs, tt.policy)...)
+			esClient := newFakeESClient()
+			r := &ReconcileStackConfigPolicy{
+				Client:     c,
+				Parameters: operator.Parameters{OperatorNamespace: "elastic-system"},
+				esClientProvider: func(_ context.Context, _ k8s.Client, _ net.Dialer, _ esv1.Elasticsearch) (esclient.Client, error) {
+					return esClient, nil
+				},
+				recorder: record.NewFakeRecorder(10),
+			}
+			policyKey := k8s.ExtractNamespacedName(tt.policy)
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: policyKey})
+			require.NoError(t, err)
+			require.True(t, res.RequeueAfter > 0)
+
+			var policy policyv1alpha1.StackConfigPolicy
+			require.NoError(t, c.Get(context.Background(), policyKey, &policy))
+			require.Equal(t, tt.wantPhase, policy.Status.Phase)
+			require.Equal(t, len(tt.wantStatuses), policy.Status.Resources)
+			for key, phase := range tt.wantStatuses {
+				require.Equal(t, phase, policy.Status.ResourcesStatuses[key].Phase, key)
+			}
+
+			if tt.wantConfigured {
+				require.Equal(t, map[string]interface{}{"indices.recovery.max_bytes_per_sec": "100mb"}, esClient.settings)
+				require.Equal(t, esclient.SnapshotRepository{Type: "s3", Settings: map[string]interface{}{"bucket": "my-bucket"}}, esClient.repositories["backups"])
+				require.Equal(t, esclient.SecurityObject{"roles": []interface{}{"superuser"}, "enabled": true}, esClient.roleMappings["admins"])
+			} else {
+				require.Nil(t, esClient.settings)
+			}
+
+			for _, es := range tt.wantSecretsIn {
+				var secret corev1.Secret
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.PolicySecureSettingsSecretName(es.Name)}, &secret))
+				require.Equal(t, []byte("key"), secret.Data["s3.client.default.access_key"])
+				require.Equal(t, tt.policy.Name, secret.Labels[PolicyNameLabelName])
+			}
+			for _, es := range tt.wantNoSecretsIn {
+				var secret corev1.Secret
+				err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.PolicySecureSettingsSecretName(es.Name)}, &secret)
+				require.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileStackConfigPolicy_Reconcile_cleanup(t *testing.T) {
+	policy := stackConfigPolicy("ns1", "policy", map[string]string{"env": "prod"})
+	cluster := es("ns1", "es", esv1.ElasticsearchReadyPhase, map[string]string{"env": "prod"})
+	c := k8s.NewFakeClient(policy, cluster, s3Credentials("ns1"))
+	esClient := newFakeESClient()
+	r := &ReconcileStackConfigPolicy{
+		Client: c,
+		esClientProvider: func(_ context.Context, _ k8s.Client, _ net.Dialer, _ esv1.Elasticsearch) (esclient.Client, error) {
+			return esClient, nil
+		},
+		recorder: record.NewFakeRecorder(10),
+	}
+	request := reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(policy)}
+	secretKey := types.NamespacedName{Namespace: "ns1", Name: esv1.PolicySecureSettingsSecretName("es")}
+	setLabels := func(labels map[string]string) {
+		var es esv1.Elasticsearch
+		require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(cluster), &es))
+		es.Labels = labels
+		require.NoError(t, c.Update(context.Background(), &es))
+	}
+	requireConfigured := func(configured bool) {
+		var es esv1.Elasticsearch
+		require.NoError(t, c.Get(context.Background(), k8s.ExtractNamespacedName(cluster), &es))
+		_, annotated := es.Annotations[AppliedConfigAnnotationName]
+		require.Equal(t, configured, annotated)
+		require.Equal(t, configured, len(esClient.settings) > 0)
+		require.Equal(t, configured, len(esClient.repositories) > 0)
+		require.Equal(t, configured, len(esClient.roleMappings) > 0)
+		err := c.Get(context.Background(), secretKey, &corev1.Secret{})
+		if configured {
+			require.NoError(t, err)
+		} else {
+			require.True(t, apierrors.IsNotFound(err))
+		}
+	}
+
+	_, err := r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	requireConfigured(true)
+
+	// the configuration is not updated again if it did not change
+	updates := esClient.updates
+	_, err = r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, updates, esClient.updates)
+
+	// entries removed from the policy are removed from the cluster
+	require.NoError(t, c.Get(context.Background(), request.NamespacedName, policy))
+	policy.Spec.Elasticsearch.SecurityRoleMappings = nil
+	require.NoError(t, c.Update(context.Background(), policy))
+	_, err = r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	require.Empty(t, esClient.roleMappings)
+	require.NotEmpty(t, esClient.repositories)
+
+	// the cluster is no longer selected: the configuration and the secure settings are removed
+	setLabels(nil)
+	_, err = r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	requireConfigured(false)
+
+	// the cluster is selected again, then the policy is deleted
+	require.NoError(t, c.Get(context.Background(), request.NamespacedName, policy))
+	policy.Spec.Elasticsearch.SecurityRoleMappings = stackConfigPolicy("ns1", "policy", nil).Spec.Elasticsearch.SecurityRoleMappings
+	require.NoError(t, c.Update(context.Background(), policy))
+	setLabels(map[string]string{"env": "prod"})
+	_, err = r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	requireConfigured(true)
+	require.NoError(t, c.Delete(context.Background(), policy))
+	_, err = r.Reconcile(context.Background(), request)
+	require.NoError(t, err)
+	requireConfigured(false)
+}
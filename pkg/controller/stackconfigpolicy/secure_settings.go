@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package stackconfigpolicy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	policyv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/stackconfigpolicy/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+)
+
+const (
+	// PolicyNamespaceLabelName and PolicyNameLabelName identify the StackConfigPolicy a secure settings Secret
+	// was copied from.
+	PolicyNamespaceLabelName = "stackconfigpolicy.k8s.elastic.co/namespace"
+	PolicyNameLabelName      = "stackconfigpolicy.k8s.elastic.co/name"
+)
+
+// policyLabels returns the labels identifying the Secrets copied from the given policy.
+func policyLabels(policy policyv1alpha1.StackConfigPolicy) map[string]string {
+	return map[string]string{
+		PolicyNamespaceLabelName: policy.Namespace,
+		PolicyNameLabelName:      policy.Name,
+	}
+}
+
+// secureSettingsData returns the entries of the Secrets referenced in the secure settings of the policy, which are
+// expected in the namespace of the policy.
+func secureSettingsData(c k8s.Client, policy policyv1alpha1.StackConfigPolicy) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for _, source := range policy.Spec.SecureSettings {
+		var secret corev1.Secret
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: policy.Namespace, Name: source.SecretName}, &secret); err != nil {
+			return nil, err
+		}
+		if len(source.Entries) == 0 {
+			for key, value := range secret.Data {
+				data[key] = value
+			}
+			continue
+		}
+		for _, entry := range source.Entries {
+			value, exists := secret.Data[entry.Key]
+			if !exists {
+				return nil, fmt.Errorf("key %s not found in secret %s/%s", entry.Key, policy.Namespace, source.SecretName)
+			}
+			key := entry.Key
+			if entry.Path != "" {
+				key = entry.Path
+			}
+			data[key] = value
+		}
+	}
+	return data, nil
+}
+
+// reconcileSecureSettingsSecret copies the given secure settings of the policy in a Secret owned by the Elasticsearch
+// cluster, or deletes that Secret if there are no secure settings.
+func reconcileSecureSettingsSecret(
+	c k8s.Client,
+	policy policyv1alpha1.StackConfigPolicy,
+	es esv1.Elasticsearch,
+	data map[string][]byte,
+	serverSideApply bool,
+) error {
+	if len(data) == 0 {
+		return deleteSecureSettingsSecret(c, policy, k8s.ExtractNamespacedName(&es))
+	}
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      esv1.PolicySecureSettingsSecretName(es.Name),
+			Namespace: es.Namespace,
+			Labels:    maps.Merge(label.NewLabels(k8s.ExtractNamespacedName(&es)), policyLabels(policy)),
+		},
+		Data: data,
+	}
+	_, err := reconciler.ReconcileSecret(c, expected, &es, serverSideApply)
+	return err
+}
+
+// deleteSecureSettingsSecret deletes the secure settings Secret of the given cluster, if it was copied from the policy.
+func deleteSecureSettingsSecret(c k8s.Client, policy policyv1alpha1.StackConfigPolicy, es types.NamespacedName) error {
+	var secret corev1.Secret
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: es.Namespace, Name: esv1.PolicySecureSettingsSecretName(es.Name)}, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !maps.IsSubset(policyLabels(policy), secret.Labels) {
+		// the Secret belongs to another policy
+		return nil
+	}
+	if err := c.Delete(context.Background(), &secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
+)
+
+// ManagedNamespacesWebhookPath is the path of the webhook validating that resources are created in the namespaces
+// managed by the operator.
+const ManagedNamespacesWebhookPath = "/validate-managed-namespaces"
+
+// RegisterManagedNamespacesWebhook registers the managed namespaces validating webhook. It allows all requests if the
+// operator manages all namespaces.
+func RegisterManagedNamespacesWebhook(mgr ctrl.Manager, managedNamespaces []string) {
+	log.Info("Registering managed namespaces validating webhook", "path", ManagedNamespacesWebhookPath)
+	mgr.GetWebhookServer().Register(ManagedNamespacesWebhookPath, &ctrlwebhook.Admission{
+		Handler: &managedNamespacesWebhook{managedNamespaces: set.Make(managedNamespaces...)},
+	})
+}
+
+type managedNamespacesWebhook struct {
+	managedNamespaces set.StringSet
+}
+
+func (wh *managedNamespacesWebhook) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	if err := validateNamespace(wh.managedNamespaces, req.Kind.Kind, req.Namespace, req.Name); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateNamespace returns an error if a resource is created in a namespace the operator does not manage, in which
+// case it would never be reconciled.
+func validateNamespace(managedNamespaces set.StringSet, kind, namespace, name string) error {
+	if managedNamespaces.Count() == 0 || managedNamespaces.Has(namespace) {
+		return nil
+	}
+	namespaces := managedNamespaces.AsSlice()
+	namespaces.Sort()
+	return fmt.Errorf(
+		"%s %s/%s cannot be created in a namespace not managed by the operator, managed namespaces are: %s",
+		kind, namespace, name, strings.Join(namespaces, ", "),
+	)
+}
@@ -0,0 +1,45 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/set"
+)
+
+func Test_validateNamespace(t *testing.T) {
+	tests := []struct {
+		name              string
+		managedNamespaces set.StringSet
+		namespace         string
+		wantErr           bool
+	}{
+		{
+			name:              "all namespaces managed",
+			managedNamespaces: set.Make(),
+			namespace:         "ns1",
+		},
+		{
+			name:              "managed namespace",
+			managedNamespaces: set.Make("ns1", "ns2"),
+			namespace:         "ns2",
+		},
+		{
+			name:              "namespace not managed",
+			managedNamespaces: set.Make("ns1", "ns2"),
+			namespace:         "ns3",
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNamespace(tt.managedNamespaces, "Elasticsearch", tt.namespace, "es")
+			require.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
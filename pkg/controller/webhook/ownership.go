@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+)
+
+// OwnershipWebhookPath is the path of the webhook validating that resources are not transferred between operator
+// instances configured with a resource label selector.
+const OwnershipWebhookPath = "/validate-resource-ownership"
+
+// RegisterOwnershipWebhook registers the ownership validating webhook. It allows all requests if the operator manages
+// all resources.
+func RegisterOwnershipWebhook(mgr ctrl.Manager, selector operator.ResourceSelector) {
+	log.Info("Registering resource ownership validating webhook", "path", OwnershipWebhookPath)
+	mgr.GetWebhookServer().Register(OwnershipWebhookPath, &ctrlwebhook.Admission{Handler: &ownershipWebhook{selector: selector}})
+}
+
+type ownershipWebhook struct {
+	selector operator.ResourceSelector
+}
+
+func (wh *ownershipWebhook) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update || !wh.selector.Enabled() {
+		return admission.Allowed("")
+	}
+	var prev, curr metav1.PartialObjectMetadata
+	if err := json.Unmarshal(req.OldObject.Raw, &prev); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.Object.Raw, &curr); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := validateOwnership(wh.selector, req.Kind.Kind, &prev, &curr); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateOwnership returns an error if the labels of a resource are updated so that it would be transferred to or
+// from the operator instance with the given selector while being managed. Transferring an unmanaged resource is allowed,
+// since no operator instance acts on it.
+func validateOwnership(selector operator.ResourceSelector, kind string, prev, curr metav1.Object) error {
+	if selector.Matches(prev) == selector.Matches(curr) {
+		return nil
+	}
+	if common.IsUnmanaged(prev) || common.IsUnmanaged(curr) {
+		return nil
+	}
+	return fmt.Errorf(
+		"labels of %s %s/%s cannot be updated to transfer it to or from the operator instance managing resources matching %s, "+
+			"set the annotation %s=false to transfer it and remove it once transferred",
+		kind, curr.GetNamespace(), curr.GetName(), selector.String(), common.ManagedAnnotation,
+	)
+}
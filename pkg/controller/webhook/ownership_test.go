@@ -0,0 +1,86 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+)
+
+func Test_validateOwnership(t *testing.T) {
+	selector, err := operator.NewResourceSelector("eck.k8s.elastic.co/operator=a")
+	require.NoError(t, err)
+	meta := func(owner string, annotations map[string]string) metav1.Object {
+		return &metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "es",
+			Labels:      map[string]string{"eck.k8s.elastic.co/operator": owner},
+			Annotations: annotations,
+		}
+	}
+	unmanaged := map[string]string{common.ManagedAnnotation: "false"}
+
+	tests := []struct {
+		name     string
+		selector operator.ResourceSelector
+		prev     metav1.Object
+		curr     metav1.Object
+		wantErr  bool
+	}{
+		{
+			name:     "all resources managed",
+			selector: operator.ResourceSelector{},
+			prev:     meta("a", nil),
+			curr:     meta("b", nil),
+		},
+		{
+			name:     "resource kept by the operator instance",
+			selector: selector,
+			prev:     meta("a", nil),
+			curr:     meta("a", map[string]string{"foo": "bar"}),
+		},
+		{
+			name:     "resource managed by another operator instance",
+			selector: selector,
+			prev:     meta("b", nil),
+			curr:     meta("c", nil),
+		},
+		{
+			name:     "managed resource transferred from the operator instance",
+			selector: selector,
+			prev:     meta("a", nil),
+			curr:     meta("b", nil),
+			wantErr:  true,
+		},
+		{
+			name:     "managed resource transferred to the operator instance",
+			selector: selector,
+			prev:     meta("b", nil),
+			curr:     meta("a", nil),
+			wantErr:  true,
+		},
+		{
+			name:     "unmanaged resource transferred",
+			selector: selector,
+			prev:     meta("a", unmanaged),
+			curr:     meta("b", unmanaged),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOwnership(tt.selector, "Elasticsearch", tt.prev, tt.curr)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -9,15 +9,28 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
+const (
+	// defaultMaxDialAttempts is the number of times a connection is attempted before giving up.
+	defaultMaxDialAttempts = 3
+	// defaultDialRetryInterval is the initial time to wait between two connection attempts, doubled after each attempt.
+	defaultDialRetryInterval = 100 * time.Millisecond
+)
+
 // ForwardingDialer is a dialer that uses a podForwarder to redirect connections when dialing
 type ForwardingDialer struct {
 	store *ForwarderStore
 
+	// maxDialAttempts is the maximum number of connection attempts for a single DialContext call
+	maxDialAttempts int
+	// dialRetryInterval is the initial backoff between two connection attempts
+	dialRetryInterval time.Duration
+
 	initOnce sync.Once
 	client   client.Client
 
@@ -31,8 +44,10 @@ type ForwardingDialerForwarderFactory func(ctx context.Context, client client.Cl
 // NewForwardingDialer creates a new, initialized ForwardingDialer
 func NewForwardingDialer() *ForwardingDialer {
 	return &ForwardingDialer{
-		store:            NewForwarderStore(),
-		forwarderFactory: defaultForwarderFactory,
+		store:             NewForwarderStore(),
+		maxDialAttempts:   defaultMaxDialAttempts,
+		dialRetryInterval: defaultDialRetryInterval,
+		forwarderFactory:  defaultForwarderFactory,
 	}
 }
 
@@ -78,17 +93,51 @@ func (d *ForwardingDialer) initIfRequired() {
 
 // DialContext uses a cached internal podForwarder to redirect connections.
 //
+// If a connection cannot be established, the forwarder is evicted and the connection is retried with a new
+// forwarder up to maxDialAttempts times. This re-resolves the address to a pod, which allows surviving pods
+// being restarted or re-created with a different IP address.
+//
 // There is no garbage collection involved, so the redirect and podForwarder will live for the duration of
 // the process.
 func (d *ForwardingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
 	d.initIfRequired()
 
+	interval := d.dialRetryInterval
+	for attempt := 1; ; attempt++ {
+		conn, err := d.dial(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if attempt >= d.maxDialAttempts || ctx.Err() != nil {
+			return nil, err
+		}
+
+		log.V(1).Info("Failed to dial forwarded address, retrying", "addr", addr, "attempt", attempt, "error", err.Error())
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, err
+		}
+		interval *= 2
+	}
+}
+
+// dial makes a single connection attempt, evicting the forwarder from the store if the connection failed.
+func (d *ForwardingDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
 	fwd, err := d.store.GetOrCreateForwarder(network, addr, d.newForwarder)
 	if err != nil {
 		return nil, err
 	}
 
-	return fwd.DialContext(ctx)
+	conn, err := fwd.DialContext(ctx)
+	if err != nil {
+		// do not tear down a healthy forwarder because the caller gave up
+		if ctx.Err() == nil {
+			d.store.Evict(network, addr, fwd)
+		}
+		return nil, err
+	}
+	return conn, nil
 }
 
 // newForwarder adapts our internal forwarder factory to the forwarderStore one.
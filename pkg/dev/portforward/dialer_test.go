@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,6 +40,7 @@ func TestForwardingDialer_DialContext(t *testing.T) {
 	customError := errors.New("DialContext test error")
 
 	d := NewForwardingDialer()
+	d.dialRetryInterval = 0
 	d.forwarderFactory = func(_ context.Context, _ client.Client, network, addr string) (Forwarder, error) {
 		return &stubForwarder{
 			network: network, addr: addr,
@@ -52,3 +54,59 @@ func TestForwardingDialer_DialContext(t *testing.T) {
 	_, err := d.DialContext(context.Background(), "tcp", "localhost:8080")
 	assert.Equal(t, customError, err)
 }
+
+func TestForwardingDialer_DialContext_Retry(t *testing.T) {
+	customError := errors.New("DialContext test error")
+
+	var mu sync.Mutex
+	var created []*stubForwarder
+
+	d := NewForwardingDialer()
+	d.dialRetryInterval = 0
+	d.forwarderFactory = func(_ context.Context, _ client.Client, network, addr string) (Forwarder, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fwd := &stubForwarder{network: network, addr: addr}
+		if len(created) == 0 {
+			// the first forwarder targets a pod that does not exist anymore
+			fwd.onDialContext = func(ctx context.Context) (net.Conn, error) {
+				return nil, customError
+			}
+		}
+		created = append(created, fwd)
+		return fwd, nil
+	}
+	d.initOnce.Do(func() {}) // don't init with kubeconfig
+
+	_, err := d.DialContext(context.Background(), "tcp", "localhost:8080")
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the failing forwarder should have been replaced by a new one
+	assert.Len(t, created, 2)
+}
+
+func TestForwardingDialer_DialContext_MaxAttempts(t *testing.T) {
+	customError := errors.New("DialContext test error")
+
+	var mu sync.Mutex
+	attempts := 0
+
+	d := NewForwardingDialer()
+	d.dialRetryInterval = 0
+	d.forwarderFactory = func(_ context.Context, _ client.Client, network, addr string) (Forwarder, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return nil, customError
+	}
+	d.initOnce.Do(func() {}) // don't init with kubeconfig
+
+	_, err := d.DialContext(context.Background(), "tcp", "localhost:8080")
+	assert.Equal(t, customError, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, defaultMaxDialAttempts, attempts)
+}
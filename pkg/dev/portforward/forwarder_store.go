@@ -12,17 +12,23 @@ import (
 
 // ForwarderStore is a store for Forwarders that handles the forwarder lifecycle.
 type ForwarderStore struct {
-	forwarders map[string]Forwarder
+	forwarders map[string]*runningForwarder
 	sync.Mutex
 }
 
+// runningForwarder is a Forwarder along with the function to call to stop it.
+type runningForwarder struct {
+	Forwarder
+	cancel context.CancelFunc
+}
+
 // ForwarderFactory is a function that can produce forwarders
 type ForwarderFactory func(ctx context.Context, network, addr string) (Forwarder, error)
 
 // NewForwarderStore creates a new initialized forwarderStore
 func NewForwarderStore() *ForwarderStore {
 	return &ForwarderStore{
-		forwarders: make(map[string]Forwarder),
+		forwarders: make(map[string]*runningForwarder),
 	}
 }
 
@@ -37,33 +43,66 @@ func (s *ForwarderStore) GetOrCreateForwarder(network, addr string, factory Forw
 
 	fwd, ok := s.forwarders[key]
 	if ok {
-		return fwd, nil
+		return fwd.Forwarder, nil
 	}
 
-	fwd, err := factory(context.Background(), network, addr)
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := factory(ctx, network, addr)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
+	fwd = &runningForwarder{Forwarder: f, cancel: cancel}
 	s.forwarders[key] = fwd
 
 	// run the forwarder in a goroutine
 	go func() {
-		// remove the forwarder from the map when done running
+		// remove the forwarder from the map when done running, unless it has already been replaced
 		defer func() {
 			s.Lock()
 			defer s.Unlock()
 
-			delete(s.forwarders, key)
+			if s.forwarders[key] == fwd {
+				delete(s.forwarders, key)
+			}
 		}()
-		// TODO: cancel this at some point to GC?
-		if err := fwd.Run(context.Background()); err != nil {
+		defer cancel()
+		if err := fwd.Run(ctx); err != nil {
 			log.Error(err, "Forwarder returned with an error", "addr", addr)
 		} else {
 			log.Info("Forwarder returned without an error", "addr", addr)
 		}
 	}()
 
-	return fwd, nil
+	return fwd.Forwarder, nil
+}
+
+// Evict stops the given forwarder and removes it from the store, so that the next call to GetOrCreateForwarder
+// creates a new one. It is a no-op if the forwarder stored for this network+address tuple is a different one.
+func (s *ForwarderStore) Evict(network, addr string, forwarder Forwarder) {
+	s.Lock()
+	defer s.Unlock()
+
+	key := netAddrToKey(network, addr)
+
+	fwd, ok := s.forwarders[key]
+	if !ok || fwd.Forwarder != forwarder {
+		return
+	}
+	log.V(1).Info("Evicting forwarder", "addr", addr)
+	fwd.cancel()
+	delete(s.forwarders, key)
+}
+
+// Close stops and removes all the forwarders of the store.
+func (s *ForwarderStore) Close() {
+	s.Lock()
+	defer s.Unlock()
+
+	for key, fwd := range s.forwarders {
+		fwd.cancel()
+		delete(s.forwarders, key)
+	}
 }
 
 // netAddrToKey returns the map key to use for this network+address tuple
@@ -34,7 +34,9 @@ type PodForwarder struct {
 
 	// initChan is used to wait for the port-forwarder to be set up before redirecting connections
 	initChan chan struct{}
-	// viaErr is set when there's an error during initialization
+	// viaMutex protects viaErr and viaAddr, which are read by dial calls while the forwarder stops
+	viaMutex sync.RWMutex
+	// viaErr is set when there's an error during initialization, or once the forwarder is not running anymore
 	viaErr error
 	// viaAddr is the address that we use when redirecting connections
 	viaAddr string
@@ -64,6 +66,9 @@ type PortForwarder interface {
 	ForwardPorts() error
 }
 
+// errNotForwarding is returned when dialing through a pod forwarder that is not running anymore.
+var errNotForwarding = errors.New("not currently forwarding")
+
 // dialerFunc is a factory for connections
 type dialerFunc func(ctx context.Context, network, address string) (net.Conn, error)
 
@@ -132,6 +137,9 @@ func parsePodAddr(ctx context.Context, addr string, clientSet *kubernetes.Client
 }
 
 // getPodWithIP requests the apiserver for pods with the given IP assigned.
+//
+// If no pod currently has this IP, the pod that was last seen with it is returned instead: pods managed by a
+// StatefulSet keep their name when re-created but usually get a new IP, which clients caching the old IP do not know about.
 func getPodWithIP(ctx context.Context, ip string, clientSet *kubernetes.Clientset) (*types.NamespacedName, error) {
 	pods, err := clientSet.CoreV1().
 		Pods("").
@@ -143,12 +151,45 @@ func getPodWithIP(ctx context.Context, ip string, clientSet *kubernetes.Clientse
 		return nil, err
 	}
 	if pods == nil || len(pods.Items) == 0 {
+		if nsn, ok := knownPodIPs.Get(ip); ok {
+			log.V(1).Info("No pod found with IP, using the last pod known with this IP", "ip", ip, "namespace", nsn.Namespace, "pod_name", nsn.Name)
+			return &nsn, nil
+		}
 		return nil, fmt.Errorf("pod with IP %s not found", ip)
 	}
 	nsn := k8s.ExtractNamespacedName(&(pods.Items[0].ObjectMeta))
+	knownPodIPs.Set(ip, nsn)
 	return &nsn, nil
 }
 
+// knownPodIPs records the pods previously resolved from their IP address.
+var knownPodIPs = newPodIPCache()
+
+// podIPCache is a concurrency-safe mapping of pod IP addresses to the pod last known with this IP.
+type podIPCache struct {
+	pods map[string]types.NamespacedName
+	mu   sync.RWMutex
+}
+
+func newPodIPCache() *podIPCache {
+	return &podIPCache{pods: make(map[string]types.NamespacedName)}
+}
+
+// Get returns the pod last known with the given IP.
+func (c *podIPCache) Get(ip string) (types.NamespacedName, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	nsn, ok := c.pods[ip]
+	return nsn, ok
+}
+
+// Set records the pod currently assigned the given IP.
+func (c *podIPCache) Set(ip string, nsn types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pods[ip] = nsn
+}
+
 // defaultPortForwarderFactory is the default factory used for port forwarders outside of tests
 var defaultPortForwarderFactory PortForwarderFactory = func(
 	ctx context.Context,
@@ -178,13 +219,17 @@ func (f *PodForwarder) DialContext(ctx context.Context) (net.Conn, error) {
 		return nil, ctx.Err()
 	}
 
+	f.viaMutex.RLock()
+	viaErr, viaAddr := f.viaErr, f.viaAddr
+	f.viaMutex.RUnlock()
+
 	// we have an error to return
-	if f.viaErr != nil {
-		return nil, f.viaErr
+	if viaErr != nil {
+		return nil, viaErr
 	}
 
-	log.V(1).Info("Redirecting dial call", "addr", f.addr, "via", f.viaAddr)
-	return f.dialerFunc(ctx, f.network, f.viaAddr)
+	log.V(1).Info("Redirecting dial call", "addr", f.addr, "via", viaAddr)
+	return f.dialerFunc(ctx, f.network, viaAddr)
 }
 
 // Run starts a port forwarder and blocks until either the port forwarding fails or the context is done.
@@ -200,6 +245,13 @@ func (f *PodForwarder) Run(ctx context.Context) error {
 	defer initCloser.Do(func() {
 		close(f.initChan)
 	})
+	// ensure pending and future dial calls fail fast once we are not forwarding anymore, so the caller can retry
+	// with a new forwarder
+	defer func() {
+		f.viaMutex.Lock()
+		defer f.viaMutex.Unlock()
+		f.viaErr = errNotForwarding
+	}()
 
 	// derive a new context so we can ensure the port-forwarding is stopped before we return and that we return as
 	// soon as the port-forwarding stops, whichever occurs first
@@ -264,9 +316,12 @@ func (f *PodForwarder) Run(ctx context.Context) error {
 		select {
 		case <-runCtx.Done():
 		case <-readyChan:
-			f.viaAddr = "127.0.0.1:" + localPort
+			viaAddr := "127.0.0.1:" + localPort
+			f.viaMutex.Lock()
+			f.viaAddr = viaAddr
+			f.viaMutex.Unlock()
 
-			log.Info("Ready to redirect connections", "addr", f.addr, "via", f.viaAddr)
+			log.Info("Ready to redirect connections", "addr", f.addr, "via", viaAddr)
 
 			// wrap this in a sync.Once because it will panic if it happens more than once, which it may if our
 			// outer function returned just as readyChan was closed.
@@ -276,7 +331,5 @@ func (f *PodForwarder) Run(ctx context.Context) error {
 		}
 	}()
 
-	err = fwd.ForwardPorts()
-	f.viaErr = errors.New("not currently forwarding")
-	return err
+	return fwd.ForwardPorts()
 }
@@ -123,6 +123,56 @@ func Test_podForwarder_DialContext(t *testing.T) {
 	}
 }
 
+func Test_podForwarder_DialContext_whileStopping(t *testing.T) {
+	f := NewPodForwarderWithTest(t, "tcp", "foo.bar.pod:9200")
+	f.ephemeralPortFinder = func() (string, error) {
+		return "12345", nil
+	}
+	f.portForwarderFactory = func(
+		ctx context.Context,
+		namespace, podName string,
+		ports []string,
+		readyChan chan struct{},
+	) (PortForwarder, error) {
+		close(readyChan)
+		return &stubPortForwarder{ctx: ctx}, nil
+	}
+	f.dialerFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, nil
+	}
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	runErr := make(chan error)
+	go func() {
+		runErr <- f.Run(runCtx)
+	}()
+
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDial()
+	// wait for the forwarder to be ready
+	_, err := f.DialContext(dialCtx)
+	require.NoError(t, err)
+
+	// dial concurrently until the forwarder reports that it is not forwarding anymore
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := f.DialContext(dialCtx); err != nil {
+					assert.Equal(t, errNotForwarding, err)
+					return
+				}
+			}
+		}()
+	}
+
+	cancelRun()
+	require.NoError(t, <-runErr)
+	wg.Wait()
+}
+
 func Test_parsePodAddr(t *testing.T) {
 	type args struct {
 		addr string
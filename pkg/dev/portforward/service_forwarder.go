@@ -26,6 +26,10 @@ type ServiceForwarder struct {
 	network, addr string
 	serviceNSN    types.NamespacedName
 
+	// hostname is set when a specific endpoint of a headless service is targeted through its per-pod DNS name,
+	// such as {hostname}.{service}.{namespace}.svc
+	hostname string
+
 	// client is used to look up the service and pods selected by the service during dialing
 	client client.Client
 
@@ -48,7 +52,8 @@ var defaultPodForwarderFactory = ForwarderFactory(func(ctx context.Context, netw
 
 // NewServiceForwarder returns a new initialized service forwarder
 func NewServiceForwarder(client client.Client, network, addr string) (*ServiceForwarder, error) {
-	serviceNSN, err := parseServiceAddr(addr)
+	hostname, serviceAddr := splitEndpointHostname(addr)
+	serviceNSN, err := parseServiceAddr(serviceAddr)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +65,7 @@ func NewServiceForwarder(client client.Client, network, addr string) (*ServiceFo
 		client: client,
 
 		serviceNSN: *serviceNSN,
+		hostname:   hostname,
 
 		store:               NewForwarderStore(),
 		podForwarderFactory: defaultPodForwarderFactory,
@@ -78,17 +84,40 @@ func parseServiceAddr(addr string) (*types.NamespacedName, error) {
 	return &types.NamespacedName{Namespace: parts[1], Name: parts[0]}, nil
 }
 
+// splitEndpointHostname splits the endpoint hostname from a per-pod DNS name of a headless service, for instance
+// {hostname}.{service}.{namespace}.svc:{port}. It returns an empty hostname and the address unchanged otherwise.
+func splitEndpointHostname(addr string) (string, string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", addr
+	}
+	parts := strings.SplitN(host, ".", 5)
+	if len(parts) < 4 || parts[3] != "svc" {
+		return "", addr
+	}
+	return parts[0], net.JoinHostPort(strings.Join(parts[1:], "."), port)
+}
+
+// isHeadless returns true if the service does not have a cluster IP.
+func isHeadless(service corev1.Service) bool {
+	return service.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
 // Run starts the service forwarder, blocking until it's done
 func (f *ServiceForwarder) Run(ctx context.Context) error {
 	// TODO: /could/ consider snipping connections here when pods turn unready, but that does not match the default
 	// Service behavior
 	<-ctx.Done()
+	// stop forwarding to the pods behind the service
+	f.store.Close()
 	return nil
 }
 
 // DialContext dials one of the ready pods behind this service forwarder.
 //
-// As an approximation to load balancing, a random ready pod will be chosen for each dialing attempt.
+// As an approximation to load balancing, a random ready pod will be chosen for each dialing attempt. If the
+// forwarder targets a specific endpoint of a headless service, the pod with the matching hostname is dialed
+// whether it is ready or not.
 func (f *ServiceForwarder) DialContext(ctx context.Context) (net.Conn, error) {
 	_, servicePortStr, err := net.SplitHostPort(f.addr)
 	if err != nil {
@@ -122,6 +151,12 @@ func (f *ServiceForwarder) DialContext(ctx context.Context) (net.Conn, error) {
 		}
 	}
 
+	// the DNS name of a headless service resolves to the pod IPs, which can be reached on any port
+	anyPort := targetPort.IntValue() == 0 && isHeadless(service)
+	if anyPort {
+		targetPort = intstr.FromInt(servicePort)
+	}
+
 	if targetPort.IntValue() == 0 {
 		return nil, fmt.Errorf("service is not listening on port: %d", servicePort)
 	}
@@ -134,25 +169,36 @@ func (f *ServiceForwarder) DialContext(ctx context.Context) (net.Conn, error) {
 
 	var podTargets []*corev1.ObjectReference
 	for _, subset := range endpoints.Subsets {
-		foundPort := false
+		foundPort := anyPort
 		for _, port := range subset.Ports {
-			foundPort = port.Port == int32(targetPort.IntValue())
 			if foundPort {
 				break
 			}
+			foundPort = port.Port == int32(targetPort.IntValue())
 		}
 		if !foundPort {
 			continue
 		}
 
-		for _, address := range subset.Addresses {
-			if address.TargetRef.Kind == "Pod" {
-				podTargets = append(podTargets, address.TargetRef)
+		addresses := subset.Addresses
+		if f.hostname != "" {
+			addresses = append(addresses, subset.NotReadyAddresses...)
+		}
+		for _, address := range addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if f.hostname != "" && address.Hostname != f.hostname && address.TargetRef.Name != f.hostname {
+				continue
 			}
+			podTargets = append(podTargets, address.TargetRef)
 		}
 	}
 
 	if len(podTargets) == 0 {
+		if f.hostname != "" {
+			return nil, fmt.Errorf("no pod with hostname %s found in service endpoints", f.hostname)
+		}
 		return nil, errors.New("no pod addresses found in service endpoints")
 	}
 
@@ -165,5 +211,10 @@ func (f *ServiceForwarder) DialContext(ctx context.Context) (net.Conn, error) {
 		return nil, err
 	}
 
-	return forwarder.DialContext(ctx)
+	conn, err := forwarder.DialContext(ctx)
+	if err != nil && ctx.Err() == nil {
+		// the pod may have been restarted, start a new pod forwarder on the next attempt
+		f.store.Evict(f.network, podAddr, forwarder)
+	}
+	return conn, err
 }
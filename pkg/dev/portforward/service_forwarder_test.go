@@ -57,6 +57,50 @@ func Test_parseServiceAddr(t *testing.T) {
 	}
 }
 
+func Test_splitEndpointHostname(t *testing.T) {
+	tests := []struct {
+		name         string
+		addr         string
+		wantHostname string
+		wantAddr     string
+	}{
+		{
+			name:     "service",
+			addr:     "foo.bar.svc:9200",
+			wantAddr: "foo.bar.svc:9200",
+		},
+		{
+			name:     "service with cluster domain",
+			addr:     "foo.bar.svc.cluster.local:9200",
+			wantAddr: "foo.bar.svc.cluster.local:9200",
+		},
+		{
+			name:         "headless service endpoint",
+			addr:         "es-default-0.es-default.bar.svc:9300",
+			wantHostname: "es-default-0",
+			wantAddr:     "es-default.bar.svc:9300",
+		},
+		{
+			name:         "headless service endpoint with cluster domain",
+			addr:         "es-default-0.es-default.bar.svc.cluster.local:9300",
+			wantHostname: "es-default-0",
+			wantAddr:     "es-default.bar.svc.cluster.local:9300",
+		},
+		{
+			name:     "no port",
+			addr:     "es-default-0.es-default.bar.svc",
+			wantAddr: "es-default-0.es-default.bar.svc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostname, addr := splitEndpointHostname(tt.addr)
+			assert.Equal(t, tt.wantHostname, hostname)
+			assert.Equal(t, tt.wantAddr, addr)
+		})
+	}
+}
+
 func Test_serviceForwarder_DialContext(t *testing.T) {
 	type fields struct {
 		client  client.Client
@@ -129,6 +173,93 @@ func Test_serviceForwarder_DialContext(t *testing.T) {
 			},
 			wantErr: errors.New("would dial: some-pod-name.bar.pod:9200"),
 		},
+		{
+			name: "should forward to the endpoint with the requested hostname of a headless service",
+			fields: fields{
+				network: "tcp",
+				addr:    "es-default-1.es-default.bar.svc:9300",
+				client: k8s.NewFakeClient(
+					&corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "es-default",
+							Namespace: "bar",
+						},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: corev1.ClusterIPNone,
+							Ports:     []corev1.ServicePort{{Port: 9200}},
+						},
+					},
+					&corev1.Endpoints{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "es-default",
+							Namespace: "bar",
+						},
+						Subsets: []corev1.EndpointSubset{
+							{
+								Ports: []corev1.EndpointPort{{Port: 9200}},
+								Addresses: []corev1.EndpointAddress{
+									{
+										Hostname:  "es-default-0",
+										TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "es-default-0", Namespace: "bar"},
+									},
+								},
+								NotReadyAddresses: []corev1.EndpointAddress{
+									{
+										Hostname:  "es-default-1",
+										TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "es-default-1", Namespace: "bar"},
+									},
+								},
+							},
+						},
+					},
+				),
+			},
+			tweaks: func(f *ServiceForwarder) {
+				f.podForwarderFactory = func(_ context.Context, network, addr string) (Forwarder, error) {
+					return &stubForwarder{
+						onDialContext: func(ctx context.Context) (net.Conn, error) {
+							return nil, fmt.Errorf("would dial: %s", addr)
+						},
+					}, nil
+				}
+			},
+			wantErr: errors.New("would dial: es-default-1.bar.pod:9300"),
+		},
+		{
+			name: "should fail if no endpoint of the headless service has the requested hostname",
+			fields: fields{
+				network: "tcp",
+				addr:    "es-default-2.es-default.bar.svc:9300",
+				client: k8s.NewFakeClient(
+					&corev1.Service{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "es-default",
+							Namespace: "bar",
+						},
+						Spec: corev1.ServiceSpec{
+							ClusterIP: corev1.ClusterIPNone,
+						},
+					},
+					&corev1.Endpoints{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "es-default",
+							Namespace: "bar",
+						},
+						Subsets: []corev1.EndpointSubset{
+							{
+								Addresses: []corev1.EndpointAddress{
+									{
+										Hostname:  "es-default-0",
+										TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "es-default-0", Namespace: "bar"},
+									},
+								},
+							},
+						},
+					},
+				),
+			},
+			wantErr: errors.New("no pod with hostname es-default-2 found in service endpoints"),
+		},
 		{
 			name: "should fail if the service is not listening on the specified port",
 			fields: fields{
@@ -155,6 +286,10 @@ func Test_serviceForwarder_DialContext(t *testing.T) {
 				tt.tweaks(f)
 			}
 
+			if tt.args.ctx == nil {
+				tt.args.ctx = context.Background()
+			}
+
 			got, err := f.DialContext(tt.args.ctx)
 			if tt.wantErr != nil {
 				assert.Equal(t, tt.wantErr, err)
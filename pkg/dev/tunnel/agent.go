@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tunnel
+
+import (
+	"context"
+	"net"
+	"time"
+
+	utilsnet "github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+const (
+	// DefaultAgentPoolSize is the default number of idle connections maintained by the agent.
+	DefaultAgentPoolSize = 4
+	// agentRetryInterval is the time to wait before attempting to reconnect to the relay.
+	agentRetryInterval = 2 * time.Second
+)
+
+// Agent maintains a pool of idle connections to the relay and forwards the traffic received on each of them to a
+// local target address.
+type Agent struct {
+	// dialer is used to reach the relay, usually through port-forwarding
+	dialer utilsnet.Dialer
+	// localDialer is used to reach the local target
+	localDialer utilsnet.Dialer
+
+	relayAddr, targetAddr string
+	poolSize              int
+}
+
+// NewAgent returns a new agent forwarding connections from the relay to the target.
+func NewAgent(dialer utilsnet.Dialer, relayAddr, targetAddr string) *Agent {
+	return &Agent{
+		dialer:      dialer,
+		localDialer: &net.Dialer{},
+		relayAddr:   relayAddr,
+		targetAddr:  targetAddr,
+		poolSize:    DefaultAgentPoolSize,
+	}
+}
+
+// Run maintains the pool of connections until the context is done.
+func (a *Agent) Run(ctx context.Context) {
+	log.Info("Starting webhook tunnel agent", "relay_addr", a.relayAddr, "target_addr", a.targetAddr)
+	for i := 0; i < a.poolSize; i++ {
+		go a.runWorker(ctx)
+	}
+	<-ctx.Done()
+}
+
+// runWorker keeps one idle connection open to the relay at any time.
+func (a *Agent) runWorker(ctx context.Context) {
+	for ctx.Err() == nil {
+		conn, err := a.dialer.DialContext(ctx, "tcp", a.relayAddr)
+		if err != nil {
+			log.V(1).Info("Failed to connect to the webhook relay, retrying", "relay_addr", a.relayAddr, "error", err.Error())
+			select {
+			case <-time.After(agentRetryInterval):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		// wait for the relay to send the first bytes of a webhook connection
+		first := make([]byte, 1)
+		if _, err := conn.Read(first); err != nil {
+			_ = conn.Close()
+			continue
+		}
+
+		// let another connection take over the idle slot while this one is handled
+		go a.forward(ctx, conn, first)
+	}
+}
+
+// forward connects to the target, then copies the data received from the relay to it, and back.
+func (a *Agent) forward(ctx context.Context, relayConn net.Conn, first []byte) {
+	targetConn, err := a.localDialer.DialContext(ctx, "tcp", a.targetAddr)
+	if err != nil {
+		log.Error(err, "Failed to connect to the webhook tunnel target", "target_addr", a.targetAddr)
+		_ = relayConn.Close()
+		return
+	}
+	if _, err := targetConn.Write(first); err != nil {
+		_ = relayConn.Close()
+		_ = targetConn.Close()
+		return
+	}
+	splice(relayConn, targetConn)
+}
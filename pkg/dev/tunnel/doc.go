@@ -0,0 +1,20 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+// package tunnel provides a reverse tunnel that allows an operator running outside of Kubernetes to serve the
+// admission webhook.
+//
+// A relay Pod deployed in the operator namespace receives the webhook connections from the API server. The operator
+// maintains a pool of idle connections to the relay, through the port-forwarding dialer, and the relay splices each
+// incoming webhook connection with one of them. The operator then forwards the traffic to its local webhook server.
+// TLS is terminated by the local webhook server, the relay only copies bytes.
+//
+// Note: It is intended for development use only.
+package tunnel
+
+import ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+
+var (
+	log = ulog.Log.WithName("dev-tunnel")
+)
@@ -0,0 +1,140 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// maxIdleAgentConns is the maximum number of idle agent connections kept by the relay.
+	maxIdleAgentConns = 16
+	// agentConnWaitTimeout is how long a webhook connection waits for an agent connection to become available.
+	agentConnWaitTimeout = 10 * time.Second
+)
+
+// Relay accepts webhook connections and splices them with connections opened by the agent.
+type Relay struct {
+	webhookListener net.Listener
+	agentListener   net.Listener
+
+	// agentConns holds the idle connections opened by the agent
+	agentConns chan net.Conn
+}
+
+// NewRelay returns a new relay serving connections from the given listeners.
+func NewRelay(webhookListener, agentListener net.Listener) *Relay {
+	return &Relay{
+		webhookListener: webhookListener,
+		agentListener:   agentListener,
+		agentConns:      make(chan net.Conn, maxIdleAgentConns),
+	}
+}
+
+// RunRelay listens on the given addresses and runs a relay until the context is done.
+func RunRelay(ctx context.Context, webhookAddr, agentAddr string) error {
+	webhookListener, err := net.Listen("tcp", webhookAddr)
+	if err != nil {
+		return err
+	}
+	agentListener, err := net.Listen("tcp", agentAddr)
+	if err != nil {
+		_ = webhookListener.Close()
+		return err
+	}
+	log.Info("Starting webhook relay", "webhook_addr", webhookAddr, "agent_addr", agentAddr)
+	return NewRelay(webhookListener, agentListener).Run(ctx)
+}
+
+// Run serves connections until the context is done or one of the listeners fails.
+func (r *Relay) Run(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = r.webhookListener.Close()
+		_ = r.agentListener.Close()
+	}()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- r.acceptAgentConns()
+	}()
+	go func() {
+		errs <- r.acceptWebhookConns(ctx)
+	}()
+
+	err := <-errs
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (r *Relay) acceptAgentConns() error {
+	for {
+		conn, err := r.agentListener.Accept()
+		if err != nil {
+			return err
+		}
+		select {
+		case r.agentConns <- conn:
+			log.V(1).Info("Agent connection registered", "remote_addr", conn.RemoteAddr().String())
+		default:
+			// too many idle connections already
+			_ = conn.Close()
+		}
+	}
+}
+
+func (r *Relay) acceptWebhookConns(ctx context.Context) error {
+	for {
+		conn, err := r.webhookListener.Accept()
+		if err != nil {
+			return err
+		}
+		go r.handleWebhookConn(ctx, conn)
+	}
+}
+
+// handleWebhookConn waits for an agent connection and splices it with the given webhook connection.
+func (r *Relay) handleWebhookConn(ctx context.Context, webhookConn net.Conn) {
+	timer := time.NewTimer(agentConnWaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case agentConn := <-r.agentConns:
+		splice(webhookConn, agentConn)
+	case <-timer.C:
+		log.Info("No agent connection available, dropping webhook connection", "timeout", agentConnWaitTimeout)
+		_ = webhookConn.Close()
+	case <-ctx.Done():
+		_ = webhookConn.Close()
+	}
+}
+
+// splice copies data between the two connections in both directions until one of them is closed.
+func splice(a, b net.Conn) {
+	defer a.Close()
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	cp := func(dst, src net.Conn) {
+		defer wg.Done()
+		if _, err := io.Copy(dst, src); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.V(1).Info("Tunnel connection closed with error", "error", err.Error())
+		}
+		// unblock the copy in the other direction
+		_ = dst.Close()
+		_ = src.Close()
+	}
+	go cp(a, b)
+	go cp(b, a)
+	wg.Wait()
+}
@@ -0,0 +1,72 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runEchoServer accepts connections and writes back each line it receives.
+func runEchoServer(t *testing.T, l net.Listener) {
+	t.Helper()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				if _, err := conn.Write(append(scanner.Bytes(), '\n')); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return l
+}
+
+func TestRelay_Agent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target := listen(t)
+	defer target.Close()
+	go runEchoServer(t, target)
+
+	webhookListener := listen(t)
+	agentListener := listen(t)
+	go func() {
+		_ = NewRelay(webhookListener, agentListener).Run(ctx)
+	}()
+
+	agent := NewAgent(&net.Dialer{}, agentListener.Addr().String(), target.Addr().String())
+	go agent.Run(ctx)
+
+	// several concurrent connections should reach the target through the relay
+	for i := 0; i < DefaultAgentPoolSize+1; i++ {
+		conn, err := net.Dial("tcp", webhookListener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("hello\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", line)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/maps"
+)
+
+const (
+	// RelayPodName is the name of the relay Pod deployed in the operator namespace.
+	RelayPodName = "elastic-operator-webhook-relay"
+	// RelayAgentPort is the port the relay listens on for agent connections.
+	RelayAgentPort = 9444
+	// RelayCommand is the operator subcommand that runs the relay.
+	RelayCommand = "webhook-relay"
+)
+
+// RelayLabels are the labels of the relay Pod, matching the selector of the webhook Service in the default manifests.
+var RelayLabels = map[string]string{"control-plane": "elastic-operator"}
+
+// RelayAgentAddr returns the address of the relay agent port, in a format supported by the port-forwarding dialer.
+func RelayAgentAddr(namespace string) string {
+	return fmt.Sprintf("%s.%s.pod:%d", RelayPodName, namespace, RelayAgentPort)
+}
+
+// NewRelayPod returns the relay Pod. The given labels must match the selector of the webhook Service so that the
+// API server connections are routed to the relay.
+func NewRelayPod(namespace, image string, webhookPort int, labels map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RelayPodName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "relay",
+				Image: image,
+				Args: []string{
+					RelayCommand,
+					"--webhook-port", strconv.Itoa(webhookPort),
+					"--agent-port", strconv.Itoa(RelayAgentPort),
+				},
+				Ports: []corev1.ContainerPort{
+					{Name: "https-webhook", ContainerPort: int32(webhookPort), Protocol: corev1.ProtocolTCP},
+					{Name: "agent", ContainerPort: RelayAgentPort, Protocol: corev1.ProtocolTCP},
+				},
+			}},
+		},
+	}
+}
+
+// ReconcileRelayPod creates the relay Pod, or re-creates it if its specification changed.
+func ReconcileRelayPod(c k8s.Client, expected corev1.Pod) error {
+	reconciled := &corev1.Pod{}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsRecreate: func() bool {
+			// most of the Pod spec is immutable
+			return len(reconciled.Spec.Containers) != 1 ||
+				reconciled.Spec.Containers[0].Image != expected.Spec.Containers[0].Image ||
+				!reflect.DeepEqual(reconciled.Spec.Containers[0].Args, expected.Spec.Containers[0].Args)
+		},
+		NeedsUpdate: func() bool {
+			return !maps.IsSubset(expected.Labels, reconciled.Labels)
+		},
+		UpdateReconciled: func() {
+			reconciled.Labels = maps.Merge(reconciled.Labels, expected.Labels)
+		},
+	})
+}
+
+// EnsureNoOperatorPod returns an error if a Pod other than the relay Pod matches the selector of the webhook Service,
+// such as the Pod of an operator deployed in the cluster: the API server would then route some of the webhook
+// connections to it instead of the relay.
+func EnsureNoOperatorPod(c k8s.Client, namespace string) error {
+	var pods corev1.PodList
+	if err := c.List(context.Background(), &pods, client.InNamespace(namespace), client.MatchingLabels(RelayLabels)); err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Name != RelayPodName {
+			return fmt.Errorf("pod %s/%s matches the selector of the webhook service, "+
+				"scale down the operator deployed in the cluster before enabling the webhook tunnel", pod.Namespace, pod.Name)
+		}
+	}
+	return nil
+}
+
+// DeleteRelayPod deletes the relay Pod, if it exists.
+func DeleteRelayPod(c k8s.Client, namespace string) error {
+	relay := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: RelayPodName}}
+	if err := c.Delete(context.Background(), &relay); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// WriteWebhookCertificates writes the webhook server certificate and key from the given Secret into the given
+// directory, where they are expected by the local webhook server.
+func WriteWebhookCertificates(ctx context.Context, c client.Reader, secret types.NamespacedName, certDir string) error {
+	var webhookSecret corev1.Secret
+	if err := c.Get(ctx, secret, &webhookSecret); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(certDir, 0700); err != nil {
+		return err
+	}
+	for _, key := range []string{certificates.CertFileName, certificates.KeyFileName} {
+		data, exists := webhookSecret.Data[key]
+		if !exists {
+			return fmt.Errorf("key %s not found in webhook secret %s", key, secret)
+		}
+		if err := ioutil.WriteFile(filepath.Join(certDir, key), data, 0600); err != nil {
+			return err
+		}
+	}
+	log.Info("Webhook certificates written", "secret", secret, "dir", certDir)
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package tunnel
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileRelayPod(t *testing.T) {
+	c := k8s.NewFakeClient()
+	pod := NewRelayPod("elastic-system", "image:1", 9443, RelayLabels)
+	require.NoError(t, ReconcileRelayPod(c, pod))
+
+	// changing the image should re-create the Pod
+	pod = NewRelayPod("elastic-system", "image:2", 9443, RelayLabels)
+	require.NoError(t, ReconcileRelayPod(c, pod))
+
+	var actual corev1.Pod
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "elastic-system", Name: RelayPodName}, &actual))
+	require.Equal(t, "image:2", actual.Spec.Containers[0].Image)
+	require.Equal(t, RelayLabels, actual.Labels)
+}
+
+func TestEnsureNoOperatorPod(t *testing.T) {
+	relay := NewRelayPod("elastic-system", "image:1", 9443, RelayLabels)
+	operatorPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-system", Name: "elastic-operator-0", Labels: RelayLabels}}
+	otherNamespace := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "elastic-operator-0", Labels: RelayLabels}}
+
+	require.NoError(t, EnsureNoOperatorPod(k8s.NewFakeClient(), "elastic-system"))
+	require.NoError(t, EnsureNoOperatorPod(k8s.NewFakeClient(&relay, otherNamespace), "elastic-system"))
+	require.Error(t, EnsureNoOperatorPod(k8s.NewFakeClient(&relay, operatorPod), "elastic-system"))
+}
+
+func TestDeleteRelayPod(t *testing.T) {
+	relay := NewRelayPod("elastic-system", "image:1", 9443, RelayLabels)
+	c := k8s.NewFakeClient(&relay)
+	require.NoError(t, DeleteRelayPod(c, "elastic-system"))
+	var pods corev1.PodList
+	require.NoError(t, c.List(context.Background(), &pods))
+	require.Empty(t, pods.Items)
+
+	// already deleted
+	require.NoError(t, DeleteRelayPod(c, "elastic-system"))
+}
+
+func TestWriteWebhookCertificates(t *testing.T) {
+	secret := types.NamespacedName{Namespace: "elastic-system", Name: "elastic-webhook-server-cert"}
+	c := k8s.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secret.Namespace, Name: secret.Name},
+		Data: map[string][]byte{
+			certificates.CertFileName: []byte("cert"),
+			certificates.KeyFileName:  []byte("key"),
+		},
+	})
+
+	dir := filepath.Join(t.TempDir(), "serving-certs")
+	require.NoError(t, WriteWebhookCertificates(context.Background(), c, secret, dir))
+
+	cert, err := ioutil.ReadFile(filepath.Join(dir, certificates.CertFileName))
+	require.NoError(t, err)
+	require.Equal(t, "cert", string(cert))
+	key, err := ioutil.ReadFile(filepath.Join(dir, certificates.KeyFileName))
+	require.NoError(t, err)
+	require.Equal(t, "key", string(key))
+}
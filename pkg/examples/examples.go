@@ -0,0 +1,280 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+// Package examples generates ready-to-apply manifests of Elastic Stack resources for common topologies, based on the
+// defaults used by the controllers.
+package examples
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+)
+
+// Profile is a predefined Elasticsearch topology.
+type Profile string
+
+const (
+	// DefaultProfile is a cluster of 3 nodes holding all the roles.
+	DefaultProfile Profile = "default"
+	// SingleNodeProfile is a cluster of a single node holding all the roles.
+	SingleNodeProfile Profile = "single-node"
+	// DedicatedMastersProfile is a cluster of 3 dedicated master nodes and 3 data nodes.
+	DedicatedMastersProfile Profile = "dedicated-masters"
+	// HotWarmProfile is a cluster of 3 dedicated master nodes, 3 hot data nodes and 2 warm data nodes.
+	HotWarmProfile Profile = "hot-warm"
+)
+
+// Profiles lists the supported profiles.
+var Profiles = []Profile{DefaultProfile, SingleNodeProfile, DedicatedMastersProfile, HotWarmProfile}
+
+var (
+	// nodeRolesMinVersion is the first version supporting the node.roles setting.
+	nodeRolesMinVersion = version.From(7, 9, 0)
+	// dataTiersMinVersion is the first version supporting the data tier roles.
+	dataTiersMinVersion = version.From(7, 10, 0)
+
+	// latestVersions are the versions used when only a major version is requested, such as 7.x.
+	latestVersions = map[string]string{
+		"7": "7.15.2",
+		"8": "8.0.0",
+	}
+)
+
+// Options define the resources to generate.
+type Options struct {
+	// Kind of the resource to generate, resources it depends on are generated as well.
+	Kind string
+	// Name of the resource, also used as a prefix for the name of its dependencies.
+	Name string
+	// Namespace of the resources, may be empty.
+	Namespace string
+	// Version of the Elastic Stack, either a full version or a major version such as 8.x.
+	Version string
+	// Profile is the topology of the Elasticsearch cluster.
+	Profile Profile
+}
+
+// Kinds returns the supported kinds.
+func Kinds() []string {
+	kinds := make([]string, 0, len(generators))
+	for kind := range generators {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+var generators = map[string]func(Options) ([]client.Object, error){
+	esv1.Kind: func(opts Options) ([]client.Object, error) {
+		es, err := newElasticsearch(opts, opts.Name)
+		if err != nil {
+			return nil, err
+		}
+		return []client.Object{es}, nil
+	},
+	kbv1.Kind: func(opts Options) ([]client.Object, error) {
+		es, err := newElasticsearch(opts, opts.Name+"-es")
+		if err != nil {
+			return nil, err
+		}
+		return []client.Object{es, newKibana(opts, opts.Name, es.Name)}, nil
+	},
+	apmv1.Kind: func(opts Options) ([]client.Object, error) {
+		es, err := newElasticsearch(opts, opts.Name+"-es")
+		if err != nil {
+			return nil, err
+		}
+		kb := newKibana(opts, opts.Name+"-kb", es.Name)
+		return []client.Object{es, kb, newApmServer(opts, opts.Name, es.Name, kb.Name)}, nil
+	},
+}
+
+// Generate returns the resources described by the given options.
+func Generate(opts Options) ([]client.Object, error) {
+	generate, exists := generators[opts.Kind]
+	if !exists {
+		return nil, fmt.Errorf("unsupported kind %s, supported kinds: %s", opts.Kind, strings.Join(Kinds(), ", "))
+	}
+	if opts.Name == "" {
+		opts.Name = strings.ToLower(opts.Kind) + "-example"
+	}
+	if opts.Profile == "" {
+		opts.Profile = DefaultProfile
+	}
+	v, err := resolveVersion(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	opts.Version = v
+	return generate(opts)
+}
+
+// resolveVersion returns the latest known version for a major version such as 8.x, or the given version.
+func resolveVersion(v string) (string, error) {
+	if major := strings.TrimSuffix(v, ".x"); major != v {
+		latest, exists := latestVersions[major]
+		if !exists {
+			return "", fmt.Errorf("no known version for %s", v)
+		}
+		return latest, nil
+	}
+	if _, err := version.Parse(v); err != nil {
+		return "", fmt.Errorf("invalid version %s: %w", v, err)
+	}
+	return v, nil
+}
+
+// nodeSetTemplate describes a NodeSet of a profile.
+type nodeSetTemplate struct {
+	name  string
+	count int32
+	// roles of the nodes, all the roles are enabled if nil
+	roles []esv1.NodeRole
+	// attrs are custom node attributes
+	attrs map[string]string
+}
+
+func profileNodeSets(profile Profile, v version.Version) ([]nodeSetTemplate, error) {
+	switch profile {
+	case DefaultProfile:
+		return []nodeSetTemplate{{name: "default", count: 3}}, nil
+	case SingleNodeProfile:
+		return []nodeSetTemplate{{name: "default", count: 1}}, nil
+	case DedicatedMastersProfile:
+		return []nodeSetTemplate{
+			{name: "master", count: 3, roles: []esv1.NodeRole{esv1.MasterRole}},
+			{name: "data", count: 3, roles: []esv1.NodeRole{esv1.DataRole, esv1.IngestRole, esv1.MLRole, esv1.TransformRole}},
+		}, nil
+	case HotWarmProfile:
+		if v.LT(dataTiersMinVersion) {
+			return nil, fmt.Errorf("profile %s requires version %s or later", profile, dataTiersMinVersion)
+		}
+		return []nodeSetTemplate{
+			{name: "master", count: 3, roles: []esv1.NodeRole{esv1.MasterRole}},
+			{
+				name: "hot", count: 3,
+				roles: []esv1.NodeRole{esv1.DataHotRole, esv1.DataContentRole, esv1.IngestRole, esv1.TransformRole},
+				attrs: map[string]string{"data": "hot"},
+			},
+			{
+				name: "warm", count: 2,
+				roles: []esv1.NodeRole{esv1.DataWarmRole},
+				attrs: map[string]string{"data": "warm"},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported profile %s", profile)
+	}
+}
+
+// nodeConfig returns the Elasticsearch configuration of the nodes of the given NodeSet.
+func nodeConfig(tpl nodeSetTemplate, v version.Version) map[string]interface{} {
+	cfg := map[string]interface{}{
+		// this allows ES to run on nodes even if their vm.max_map_count has not been increased, at a performance cost
+		"node.store.allow_mmap": false,
+	}
+	for k, val := range tpl.attrs {
+		cfg[esv1.NodeAttr+"."+k] = val
+	}
+	if tpl.roles == nil {
+		return cfg
+	}
+	if v.GTE(nodeRolesMinVersion) {
+		roles := make([]interface{}, len(tpl.roles))
+		for i, r := range tpl.roles {
+			roles[i] = string(r)
+		}
+		cfg[esv1.NodeRoles] = roles
+		return cfg
+	}
+	has := func(role esv1.NodeRole) bool {
+		for _, r := range tpl.roles {
+			if r == role {
+				return true
+			}
+		}
+		return false
+	}
+	cfg[esv1.NodeMaster] = has(esv1.MasterRole)
+	cfg[esv1.NodeData] = has(esv1.DataRole)
+	cfg[esv1.NodeIngest] = has(esv1.IngestRole)
+	cfg[esv1.NodeML] = has(esv1.MLRole)
+	return cfg
+}
+
+func newElasticsearch(opts Options, name string) (*esv1.Elasticsearch, error) {
+	v, err := version.Parse(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := profileNodeSets(opts.Profile, v)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeSets := make([]esv1.NodeSet, 0, len(templates))
+	for _, tpl := range templates {
+		nodeSets = append(nodeSets, esv1.NodeSet{
+			Name:   tpl.name,
+			Count:  tpl.count,
+			Config: &commonv1.Config{Data: nodeConfig(tpl, v)},
+			PodTemplate: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:      esv1.ElasticsearchContainerName,
+						Resources: nodespec.DefaultResources,
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{esvolume.DefaultDataVolumeClaim},
+		})
+	}
+
+	return &esv1.Elasticsearch{
+		TypeMeta:   metav1.TypeMeta{APIVersion: esv1.GroupVersion.String(), Kind: esv1.Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: opts.Namespace},
+		Spec: esv1.ElasticsearchSpec{
+			Version:  opts.Version,
+			NodeSets: nodeSets,
+		},
+	}, nil
+}
+
+func newKibana(opts Options, name, esName string) *kbv1.Kibana {
+	return &kbv1.Kibana{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kbv1.GroupVersion.String(), Kind: kbv1.Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: opts.Namespace},
+		Spec: kbv1.KibanaSpec{
+			Version:          opts.Version,
+			Count:            1,
+			ElasticsearchRef: commonv1.ObjectSelector{Name: esName},
+		},
+	}
+}
+
+func newApmServer(opts Options, name, esName, kbName string) *apmv1.ApmServer {
+	return &apmv1.ApmServer{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apmv1.GroupVersion.String(), Kind: apmv1.Kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: opts.Namespace},
+		Spec: apmv1.ApmServerSpec{
+			Version:          opts.Version,
+			Count:            1,
+			ElasticsearchRef: commonv1.ObjectSelector{Name: esName},
+			KibanaRef:        commonv1.ObjectSelector{Name: kbName},
+		},
+	}
+}
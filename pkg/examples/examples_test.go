@@ -0,0 +1,123 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package examples
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		wantKinds     []string
+		wantNodeSets  []string
+		wantESVersion string
+		wantErr       bool
+	}{
+		{
+			name:          "default Elasticsearch",
+			opts:          Options{Kind: "Elasticsearch", Version: "7.15.2"},
+			wantKinds:     []string{"Elasticsearch"},
+			wantNodeSets:  []string{"default"},
+			wantESVersion: "7.15.2",
+		},
+		{
+			name:          "hot-warm Elasticsearch with a major version",
+			opts:          Options{Kind: "Elasticsearch", Version: "8.x", Profile: HotWarmProfile},
+			wantKinds:     []string{"Elasticsearch"},
+			wantNodeSets:  []string{"master", "hot", "warm"},
+			wantESVersion: latestVersions["8"],
+		},
+		{
+			name:          "Kibana comes with Elasticsearch",
+			opts:          Options{Kind: "Kibana", Version: "7.15.2", Profile: SingleNodeProfile},
+			wantKinds:     []string{"Elasticsearch", "Kibana"},
+			wantNodeSets:  []string{"default"},
+			wantESVersion: "7.15.2",
+		},
+		{
+			name:    "hot-warm is not supported before data tiers",
+			opts:    Options{Kind: "Elasticsearch", Version: "7.9.0", Profile: HotWarmProfile},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			opts:    Options{Kind: "Foo", Version: "7.15.2"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown major version",
+			opts:    Options{Kind: "Elasticsearch", Version: "5.x"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := Generate(tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			kinds := make([]string, 0, len(objs))
+			for _, obj := range objs {
+				kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+			}
+			assert.Equal(t, tt.wantKinds, kinds)
+
+			es, ok := objs[0].(*esv1.Elasticsearch)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantESVersion, es.Spec.Version)
+			assert.Equal(t, tt.wantNodeSets, esv1.NodeSetList(es.Spec.NodeSets).Names())
+		})
+	}
+}
+
+func Test_nodeConfig(t *testing.T) {
+	tpl := nodeSetTemplate{name: "master", count: 3, roles: []esv1.NodeRole{esv1.MasterRole}}
+
+	assert.Equal(t, map[string]interface{}{
+		"node.store.allow_mmap": false,
+		"node.roles":            []interface{}{"master"},
+	}, nodeConfig(tpl, latestVersion(t, "7")))
+
+	assert.Equal(t, map[string]interface{}{
+		"node.store.allow_mmap": false,
+		"node.master":           true,
+		"node.data":             false,
+		"node.ingest":           false,
+		"node.ml":               false,
+	}, nodeConfig(tpl, mustParse(t, "7.8.0")))
+}
+
+func TestToYAML(t *testing.T) {
+	objs, err := Generate(Options{Kind: "Elasticsearch", Name: "quickstart", Version: "7.15.2", Profile: SingleNodeProfile})
+	require.NoError(t, err)
+	out, err := ToYAML(objs)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "status")
+	assert.NotContains(t, string(out), "creationTimestamp")
+	assert.Contains(t, string(out), "name: quickstart")
+}
+
+func mustParse(t *testing.T, v string) version.Version {
+	t.Helper()
+	parsed, err := version.Parse(v)
+	require.NoError(t, err)
+	return parsed
+}
+
+func latestVersion(t *testing.T, major string) version.Version {
+	t.Helper()
+	return mustParse(t, latestVersions[major])
+}
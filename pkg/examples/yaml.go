@@ -0,0 +1,66 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ToYAML serializes the given resources as a multi-document YAML manifest, leaving out the status and the empty
+// fields which are otherwise serialized for struct types.
+func ToYAML(objs []client.Object) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objs {
+		asJSON, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(asJSON, &fields); err != nil {
+			return nil, err
+		}
+		delete(fields, "status")
+		asYAML, err := yaml.Marshal(prune(fields))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(asYAML)
+	}
+	return buf.Bytes(), nil
+}
+
+// prune recursively removes nil values and empty maps.
+func prune(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			pruned := prune(child)
+			if pruned == nil {
+				delete(v, k)
+				continue
+			}
+			if m, isMap := pruned.(map[string]interface{}); isMap && len(m) == 0 {
+				delete(v, k)
+				continue
+			}
+			v[k] = pruned
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = prune(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
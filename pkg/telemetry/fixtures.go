@@ -53,6 +53,7 @@ const expectedTelemetryTemplate = `eck:
     enterprise_resource_units: "1"
     total_managed_memory: 3.22GB
   operator_uuid: 15039433-f873-41bd-b6e7-10ee3665cafa
+  platform: gke
   stats:
     agents:
       multiple_refs: 0
@@ -87,7 +88,23 @@ const expectedTelemetryTemplate = `eck:
     kibanas:
       pod_count: 0
       resource_count: 1
+    logstashes:
+      pod_count: 0
+      resource_count: 0
     maps:
       pod_count: 0
       resource_count: 0
+    resource_states:
+      elasticsearches:
+        health:
+          unknown: {{ .ElasticsearchTemplateData.ResourceCount }}
+        phases:
+          unknown: {{ .ElasticsearchTemplateData.ResourceCount }}
+        versions:
+          unknown: {{ .ElasticsearchTemplateData.ResourceCount }}
+      kibanas:
+        health:
+          unknown: 1
+        versions:
+          unknown: 1
 `
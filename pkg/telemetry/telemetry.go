@@ -7,10 +7,12 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/ghodss/yaml"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -21,6 +23,7 @@ import (
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	entv1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
 	mapsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/maps/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/stackmon/monitoring"
@@ -36,6 +39,9 @@ const (
 	podCount      = "pod_count"
 
 	timestampFieldName = "timestamp"
+
+	// unknownState is reported for resources whose version, health or phase is not known yet.
+	unknownState = "unknown"
 )
 
 var log = ulog.Log.WithName("usage")
@@ -58,6 +64,8 @@ func NewReporter(
 	operatorNamespace string,
 	managedNamespaces []string,
 	telemetryInterval time.Duration,
+	configMapName string,
+	serverSideApply bool,
 ) Reporter {
 	if len(managedNamespaces) == 0 {
 		// treat no managed namespaces as managing all namespaces, ie. set empty string for namespace filtering
@@ -70,6 +78,8 @@ func NewReporter(
 		operatorNamespace: operatorNamespace,
 		managedNamespaces: managedNamespaces,
 		telemetryInterval: telemetryInterval,
+		configMapName:     configMapName,
+		serverSideApply:   serverSideApply,
 	}
 }
 
@@ -79,6 +89,11 @@ type Reporter struct {
 	operatorNamespace string
 	managedNamespaces []string
 	telemetryInterval time.Duration
+	// configMapName is the name of a ConfigMap in the operator namespace to which the telemetry data is also written,
+	// for consumers other than Kibana. Disabled if empty.
+	configMapName string
+	// serverSideApply is true if the Kibana secrets are written through server-side apply.
+	serverSideApply bool
 }
 
 func (r *Reporter) Start() {
@@ -108,6 +123,8 @@ func (r *Reporter) getResourceStats() (map[string]interface{}, error) {
 		entStats,
 		agentStats,
 		mapsStats,
+		logstashStats,
+		resourceStatesStats,
 	} {
 		key, statsPart, err := f(r.client, r.managedNamespaces)
 		if err != nil {
@@ -138,6 +155,12 @@ func (r *Reporter) report() {
 		return
 	}
 
+	if r.configMapName != "" {
+		if err := r.reconcileConfigMap(telemetryBytes); err != nil {
+			log.Error(err, "failed to reconcile telemetry config map", "namespace", r.operatorNamespace, "name", r.configMapName)
+		}
+	}
+
 	for _, ns := range r.managedNamespaces {
 		var kibanaList kbv1.KibanaList
 		if err := r.client.List(context.Background(), &kibanaList, client.InNamespace(ns)); err != nil {
@@ -159,7 +182,7 @@ func (r *Reporter) report() {
 
 			secret.Data[kibana.TelemetryFilename] = telemetryBytes
 
-			if _, err := reconciler.ReconcileSecret(r.client, secret, nil); err != nil {
+			if _, err := reconciler.ReconcileSecret(r.client, secret, nil, r.serverSideApply); err != nil {
 				log.Error(err, "failed to reconcile Kibana secret")
 				continue
 			}
@@ -167,6 +190,29 @@ func (r *Reporter) report() {
 	}
 }
 
+// reconcileConfigMap writes the telemetry data to the telemetry ConfigMap in the operator namespace.
+func (r *Reporter) reconcileConfigMap(telemetryBytes []byte) error {
+	expected := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.operatorNamespace,
+			Name:      r.configMapName,
+		},
+		Data: map[string]string{kibana.TelemetryFilename: string(telemetryBytes)},
+	}
+	reconciled := &corev1.ConfigMap{}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     r.client,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			return !reflect.DeepEqual(expected.Data, reconciled.Data)
+		},
+		UpdateReconciled: func() {
+			reconciled.Data = expected.Data
+		},
+	})
+}
+
 func (r *Reporter) getLicenseInfo() (map[string]string, error) {
 	nsn := types.NamespacedName{
 		Namespace: r.operatorNamespace,
@@ -356,3 +402,121 @@ func mapsStats(k8sClient k8s.Client, managedNamespaces []string) (string, interf
 	}
 	return "maps", stats, nil
 }
+
+func logstashStats(k8sClient k8s.Client, managedNamespaces []string) (string, interface{}, error) {
+	stats := map[string]int32{resourceCount: 0, podCount: 0}
+
+	var lsList lsv1alpha1.LogstashList
+	for _, ns := range managedNamespaces {
+		if err := k8sClient.List(context.Background(), &lsList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+
+		for _, ls := range lsList.Items {
+			stats[resourceCount]++
+			stats[podCount] += ls.Status.AvailableNodes
+		}
+	}
+	return "logstashes", stats, nil
+}
+
+// resourceStates counts the resources of a kind by version, health and, for the kinds which have one, orchestration phase.
+type resourceStates struct {
+	Versions map[string]int32 `json:"versions"`
+	Health   map[string]int32 `json:"health"`
+	Phases   map[string]int32 `json:"phases,omitempty"`
+}
+
+func countState(counts map[string]int32, state string) {
+	if state == "" {
+		state = unknownState
+	}
+	counts[state]++
+}
+
+// resourceStatesStats breaks down the managed resources of each kind by version, health and phase. Kinds without any
+// resource are omitted.
+func resourceStatesStats(k8sClient k8s.Client, managedNamespaces []string) (string, interface{}, error) {
+	stats := map[string]*resourceStates{}
+	add := func(kind, version, health string) *resourceStates {
+		states, exists := stats[kind]
+		if !exists {
+			states = &resourceStates{Versions: map[string]int32{}, Health: map[string]int32{}}
+			stats[kind] = states
+		}
+		countState(states.Versions, version)
+		countState(states.Health, health)
+		return states
+	}
+
+	for _, ns := range managedNamespaces {
+		var esList esv1.ElasticsearchList
+		if err := k8sClient.List(context.Background(), &esList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, es := range esList.Items {
+			states := add("elasticsearches", es.Status.Version, string(es.Status.Health))
+			if states.Phases == nil {
+				states.Phases = map[string]int32{}
+			}
+			countState(states.Phases, string(es.Status.Phase))
+		}
+
+		var kbList kbv1.KibanaList
+		if err := k8sClient.List(context.Background(), &kbList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, kb := range kbList.Items {
+			add("kibanas", kb.Status.Version, string(kb.Status.Health))
+		}
+
+		var apmList apmv1.ApmServerList
+		if err := k8sClient.List(context.Background(), &apmList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, apm := range apmList.Items {
+			add("apms", apm.Status.Version, string(apm.Status.Health))
+		}
+
+		var beatList beatv1beta1.BeatList
+		if err := k8sClient.List(context.Background(), &beatList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, beat := range beatList.Items {
+			add("beats", beat.Status.Version, string(beat.Status.Health))
+		}
+
+		var entList entv1.EnterpriseSearchList
+		if err := k8sClient.List(context.Background(), &entList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, ent := range entList.Items {
+			add("enterprisesearches", ent.Status.Version, string(ent.Status.Health))
+		}
+
+		var agentList agentv1alpha1.AgentList
+		if err := k8sClient.List(context.Background(), &agentList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, agent := range agentList.Items {
+			add("agents", agent.Status.Version, string(agent.Status.Health))
+		}
+
+		var mapsList mapsv1alpha1.ElasticMapsServerList
+		if err := k8sClient.List(context.Background(), &mapsList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, maps := range mapsList.Items {
+			add("maps", maps.Status.Version, string(maps.Status.Health))
+		}
+
+		var lsList lsv1alpha1.LogstashList
+		if err := k8sClient.List(context.Background(), &lsList, client.InNamespace(ns)); err != nil {
+			return "", nil, err
+		}
+		for _, ls := range lsList.Items {
+			add("logstashes", ls.Status.Version, string(ls.Status.Health))
+		}
+	}
+	return "resource_states", stats, nil
+}
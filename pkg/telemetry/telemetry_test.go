@@ -14,6 +14,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/elastic/cloud-on-k8s/pkg/about"
 	agentv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/agent/v1alpha1"
@@ -23,6 +24,7 @@ import (
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	entv1 "github.com/elastic/cloud-on-k8s/pkg/apis/enterprisesearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	lsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/logstash/v1alpha1"
 	mapsv1alpha1 "github.com/elastic/cloud-on-k8s/pkg/apis/maps/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -33,6 +35,7 @@ var (
 		OperatorUUID:            "15039433-f873-41bd-b6e7-10ee3665cafa",
 		CustomOperatorNamespace: true,
 		Distribution:            "v1.16.13-gke.1",
+		Platform:                "gke",
 		DistributionChannel:     "test-channel",
 		BuildInfo: about.BuildInfo{
 			Version:  "1.1.0",
@@ -79,6 +82,7 @@ func TestMarshalTelemetry(t *testing.T) {
   distributionChannel: ""
   license: null
   operator_uuid: ""
+  platform: ""
   stats: null
 `,
 		},
@@ -106,6 +110,7 @@ func TestMarshalTelemetry(t *testing.T) {
   license:
     eck_license_level: basic
   operator_uuid: 15039433-f873-41bd-b6e7-10ee3665cafa
+  platform: gke
   stats:
     apms:
       pod_count: 2
@@ -162,6 +167,9 @@ func TestNewReporter(t *testing.T) {
 			},
 			Status: esv1.ElasticsearchStatus{
 				AvailableNodes: 3,
+				Version:        "7.15.0",
+				Health:         esv1.ElasticsearchHealth("green"),
+				Phase:          esv1.ElasticsearchOrchestrationPhase("Ready"),
 			},
 		},
 		&esv1.Elasticsearch{
@@ -171,6 +179,9 @@ func TestNewReporter(t *testing.T) {
 			},
 			Status: esv1.ElasticsearchStatus{
 				AvailableNodes: 6,
+				Version:        "7.15.0",
+				Health:         esv1.ElasticsearchHealth("yellow"),
+				Phase:          esv1.ElasticsearchOrchestrationPhase("ApplyingChanges"),
 			},
 		},
 		&esv1.Elasticsearch{
@@ -301,11 +312,24 @@ func TestNewReporter(t *testing.T) {
 				},
 			},
 		},
+		&lsv1alpha1.Logstash{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ls1",
+				Namespace: "ns2",
+			},
+			Status: lsv1alpha1.LogstashStatus{
+				DeploymentStatus: commonv1.DeploymentStatus{
+					AvailableNodes: 2,
+					Version:        "8.0.0",
+					Health:         commonv1.GreenHealth,
+				},
+			},
+		},
 		licenceConfigMap,
 	)
 
 	// We only want the reporter to handle the managed namespaces, in this test only ns1 and ns2 are managed.
-	r := NewReporter(testOperatorInfo, client, "elastic-system", []string{kb1.Namespace, kb2.Namespace}, 1*time.Hour)
+	r := NewReporter(testOperatorInfo, client, "elastic-system", []string{kb1.Namespace, kb2.Namespace}, 1*time.Hour, "", false)
 	r.report()
 
 	wantData := map[string][]byte{
@@ -323,6 +347,7 @@ func TestNewReporter(t *testing.T) {
     enterprise_resource_units: "1"
     total_managed_memory: 3.22GB
   operator_uuid: 15039433-f873-41bd-b6e7-10ee3665cafa
+  platform: gke
   stats:
     agents:
       fleet_mode: 2
@@ -354,9 +379,60 @@ func TestNewReporter(t *testing.T) {
     kibanas:
       pod_count: 0
       resource_count: 2
+    logstashes:
+      pod_count: 2
+      resource_count: 1
     maps:
       pod_count: 1
       resource_count: 1
+    resource_states:
+      agents:
+        health:
+          unknown: 4
+        versions:
+          unknown: 4
+      apms:
+        health:
+          unknown: 1
+        versions:
+          unknown: 1
+      beats:
+        health:
+          unknown: 2
+        versions:
+          unknown: 2
+      elasticsearches:
+        health:
+          green: 1
+          unknown: 1
+          yellow: 1
+        phases:
+          ApplyingChanges: 1
+          Ready: 1
+          unknown: 1
+        versions:
+          7.15.0: 2
+          unknown: 1
+      enterprisesearches:
+        health:
+          unknown: 1
+        versions:
+          unknown: 1
+      kibanas:
+        health:
+          unknown: 2
+        versions:
+          unknown: 2
+      logstashes:
+        health:
+          green: 1
+        versions:
+          8.0.0: 1
+      maps:
+        health:
+          unknown: 1
+        versions:
+          unknown: 1
 `),
 	}
 
@@ -372,6 +448,20 @@ func TestNewReporter(t *testing.T) {
 	require.Nil(t, s3.Data)
 }
 
+func TestReporter_report_ConfigMap(t *testing.T) {
+	kb, secret := createKbAndSecret("kb1", "ns1", 1)
+	client := k8s.NewFakeClient(&kb, &secret, licenceConfigMap)
+	r := NewReporter(testOperatorInfo, client, "elastic-system", []string{"ns1"}, 1*time.Hour, "eck-telemetry", false)
+	r.report()
+
+	// the telemetry data is written to the config map in the operator namespace, as well as to the Kibana secret
+	var configMap corev1.ConfigMap
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Namespace: "elastic-system", Name: "eck-telemetry"}, &configMap))
+	require.NoError(t, client.Get(context.Background(), k8s.ExtractNamespacedName(&secret), &secret))
+	require.NotEmpty(t, configMap.Data[kibana.TelemetryFilename])
+	require.Equal(t, string(secret.Data[kibana.TelemetryFilename]), configMap.Data[kibana.TelemetryFilename])
+}
+
 // assertSameSecretContent compares 2 data secrets and print a human friendly diff if not equal.
 func assertSameSecretContent(t *testing.T, expectedData, actualData map[string][]byte) {
 	t.Helper()
@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package jvm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// maxHeapSizeRe is the pattern to extract the max Java heap size (-Xmx<size>[g|G|m|M|k|K] in binary units)
+var maxHeapSizeRe = regexp.MustCompile(`-Xmx([0-9]+)([gGmMkK]?)(?:\s|$)`)
+
+// MaxHeapSize extracts the maximum Java heap size from the given Java options. It returns nil if it is not set.
+func MaxHeapSize(javaOpts string) (*resource.Quantity, error) {
+	match := maxHeapSizeRe.FindStringSubmatch(javaOpts)
+	if match == nil {
+		return nil, nil
+	}
+	suffix := match[2]
+	if suffix != "" {
+		// capitalize the suffix and add `i` to have a surjection of [g|G|m|M|k|K] in [Gi|Mi|Ki]
+		suffix = strings.ToUpper(suffix) + "i"
+	}
+	heap, err := resource.ParseQuantity(match[1] + suffix)
+	if err != nil {
+		return nil, err
+	}
+	return &heap, nil
+}
+
+// HeapSizeJavaOpts returns the Java options setting both the initial and the maximum heap size to the given size.
+func HeapSizeJavaOpts(heapMB int64) string {
+	return fmt.Sprintf("-Xms%dm -Xmx%dm", heapMB, heapMB)
+}
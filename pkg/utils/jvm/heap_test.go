@@ -0,0 +1,52 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package jvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMaxHeapSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		javaOpts string
+		want     string
+	}{
+		{
+			name:     "not set",
+			javaOpts: "-Dlog4j2.formatMsgNoLookups=true",
+		},
+		{
+			name:     "gigabytes",
+			javaOpts: "-Xms2g -Xmx2g",
+			want:     "2Gi",
+		},
+		{
+			name:     "megabytes among other options",
+			javaOpts: "-Xmx1536M -Dfoo=bar",
+			want:     "1536Mi",
+		},
+		{
+			name:     "bytes",
+			javaOpts: "-Xmx1073741824",
+			want:     "1Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MaxHeapSize(tt.javaOpts)
+			require.NoError(t, err)
+			if tt.want == "" {
+				require.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			require.Equal(t, 0, got.Cmp(resource.MustParse(tt.want)))
+		})
+	}
+}
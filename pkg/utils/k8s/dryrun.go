@@ -0,0 +1,105 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	ulog "github.com/elastic/cloud-on-k8s/pkg/utils/log"
+)
+
+var dryRunLog = ulog.Log.WithName("dry-run")
+
+// NewDryRunClient returns a client which logs the changes it is asked to make and submits them to the API server in
+// dry-run mode: they are validated and admitted, but never persisted. Reads are served as usual.
+func NewDryRunClient(c Client) Client {
+	return &dryRunClient{Client: client.NewDryRunClient(c)}
+}
+
+type dryRunClient struct {
+	Client
+}
+
+func (c *dryRunClient) logChange(change string, obj client.Object) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	dryRunLog.Info("Dry run: change not applied", "change", change, "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.logChange("create", obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.logChange("update", obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.logChange("patch", obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.logChange("delete", obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.logChange("delete all of", obj)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Status() client.StatusWriter {
+	return &dryRunStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type dryRunStatusWriter struct {
+	client.StatusWriter
+	client *dryRunClient
+}
+
+func (w *dryRunStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.client.logChange("update status", obj)
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *dryRunStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.client.logChange("patch status", obj)
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// NewDryRunRecorder returns an event recorder which logs the events it is asked to emit instead of creating them.
+func NewDryRunRecorder() record.EventRecorder {
+	return dryRunRecorder{}
+}
+
+type dryRunRecorder struct{}
+
+func (dryRunRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	keysAndValues := []interface{}{"type", eventtype, "reason", reason, "message", message}
+	if accessor, err := meta.Accessor(object); err == nil {
+		keysAndValues = append(keysAndValues, "namespace", accessor.GetNamespace(), "name", accessor.GetName())
+	}
+	dryRunLog.Info("Dry run: event not emitted", keysAndValues...)
+}
+
+func (r dryRunRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r dryRunRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewDryRunClient(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "existing"},
+		Data:       map[string]string{"key": "value"},
+	}
+	c := NewDryRunClient(NewFakeClient(existing))
+
+	// changes are not persisted
+	created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "created"}}
+	require.NoError(t, c.Create(context.Background(), created))
+	err := c.Get(context.Background(), ExtractNamespacedName(created), &corev1.ConfigMap{})
+	require.True(t, apierrors.IsNotFound(err))
+
+	var updated corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), ExtractNamespacedName(existing), &updated))
+	updated.Data["key"] = "updated"
+	require.NoError(t, c.Update(context.Background(), &updated))
+	require.NoError(t, c.Status().Update(context.Background(), &updated))
+
+	var actual corev1.ConfigMap
+	require.NoError(t, c.Get(context.Background(), ExtractNamespacedName(existing), &actual))
+	require.Equal(t, "value", actual.Data["key"])
+}
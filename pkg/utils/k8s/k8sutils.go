@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -50,6 +51,15 @@ func ExtractNamespacedName(object metav1.Object) types.NamespacedName {
 	}
 }
 
+// ParseNamespacedName parses a reference in the <namespace>/<name> format.
+func ParseNamespacedName(ref string) (types.NamespacedName, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid reference %q, expected <namespace>/<name>", ref)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
 // ObjectExists returns true if the object pointed by ref exists.
 // typedReceiver acts as a generic object but must be of the desired object underlying type.
 func ObjectExists(c Client, ref types.NamespacedName, typedReceiver client.Object) (bool, error) {
@@ -62,6 +62,17 @@ func TestExtractNamespacedName(t *testing.T) {
 	)
 }
 
+func TestParseNamespacedName(t *testing.T) {
+	nsn, err := ParseNamespacedName("namespace/name")
+	require.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "namespace", Name: "name"}, nsn)
+
+	for _, invalid := range []string{"name", "namespace/", "/name", "a/b/c"} {
+		_, err := ParseNamespacedName(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestGetServiceDNSName(t *testing.T) {
 	type args struct {
 		svc corev1.Service
@@ -0,0 +1,197 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	LevelsFlagName             = "log-levels"
+	SamplingInitialFlagName    = "log-sampling-initial"
+	SamplingThereafterFlagName = "log-sampling-thereafter"
+)
+
+// loggerLevels holds the verbosity of the loggers whose verbosity differs from the global one, by logger name.
+// The verbosity of a logger applies to its child loggers, e.g. elasticsearch-controller applies to
+// elasticsearch-controller.driver, unless they have their own verbosity.
+type loggerLevels struct {
+	mutex  sync.RWMutex
+	global zap.AtomicLevel
+	levels map[string]zapcore.Level
+}
+
+var levels = &loggerLevels{global: zap.NewAtomicLevel(), levels: map[string]zapcore.Level{}}
+
+// levelFor returns the minimum enabled level of the logger with the given name.
+func (l *loggerLevels) levelFor(loggerName string) zapcore.Level {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for name := loggerName; name != ""; {
+		if level, exists := l.levels[name]; exists {
+			return level
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return l.global.Level()
+}
+
+// Enabled returns true if the given level is enabled for at least one logger.
+func (l *loggerLevels) Enabled(level zapcore.Level) bool {
+	if l.global.Enabled(level) {
+		return true
+	}
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for _, loggerLevel := range l.levels {
+		if loggerLevel.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLoggerVerbosities parses <logger>=<verbosity> pairs, such as elasticsearch-controller=1.
+func ParseLoggerVerbosities(pairs []string) (map[string]int, error) {
+	verbosities := make(map[string]int, len(pairs))
+	for _, pair := range pairs {
+		name, value := pair, ""
+		if i := strings.LastIndex(pair, "="); i >= 0 {
+			name, value = strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		}
+		v, err := strconv.Atoi(value)
+		if name == "" || err != nil {
+			return nil, fmt.Errorf("invalid logger verbosity %s, expected <logger>=<verbosity>", pair)
+		}
+		verbosities[name] = v
+	}
+	return verbosities, nil
+}
+
+// SetLoggerVerbosities replaces the verbosity of the named loggers. Other loggers use the global verbosity.
+func SetLoggerVerbosities(verbosities map[string]int) {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+	levels.levels = make(map[string]zapcore.Level, len(verbosities))
+	for name, v := range verbosities {
+		levels.levels[name] = zapcore.Level(v * -1)
+	}
+}
+
+// SetLoggerVerbosity sets the verbosity of the logger with the given name and of its child loggers.
+func SetLoggerVerbosity(name string, v int) {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+	levels.levels[name] = zapcore.Level(v * -1)
+}
+
+// ResetLoggerVerbosity restores the global verbosity for the logger with the given name.
+func ResetLoggerVerbosity(name string) {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+	delete(levels.levels, name)
+}
+
+// loggerVerbosities returns the global verbosity and the verbosity of the named loggers.
+func loggerVerbosities() (int, map[string]int) {
+	levels.mutex.RLock()
+	defer levels.mutex.RUnlock()
+	verbosities := make(map[string]int, len(levels.levels))
+	for name, level := range levels.levels {
+		verbosities[name] = int(level) * -1
+	}
+	return int(levels.global.Level()) * -1, verbosities
+}
+
+// LevelsHandler returns an HTTP handler to inspect and change the verbosity of the loggers at runtime:
+//  GET                                       returns the global verbosity and the verbosity of the named loggers
+//  PUT    ?logger=<name>&verbosity=<level>   sets the verbosity of a logger and of its child loggers
+//  DELETE ?logger=<name>                     restores the global verbosity for a logger
+func LevelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("logger")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			v, err := strconv.Atoi(r.URL.Query().Get("verbosity"))
+			if name == "" || err != nil {
+				http.Error(w, "logger and verbosity query parameters are required", http.StatusBadRequest)
+				return
+			}
+			SetLoggerVerbosity(name, v)
+			Log.Info("Logger verbosity changed", "logger", name, "verbosity", v)
+		case http.MethodDelete:
+			if name == "" {
+				http.Error(w, "logger query parameter is required", http.StatusBadRequest)
+				return
+			}
+			ResetLoggerVerbosity(name)
+			Log.Info("Logger verbosity reset", "logger", name)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		global, loggers := loggerVerbosities()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"verbosity": global, "loggers": loggers})
+	})
+}
+
+// levelsCore filters the entries of the wrapped core according to the verbosity of the logger that emits them.
+type levelsCore struct {
+	zapcore.Core
+	levels *loggerLevels
+}
+
+func (c *levelsCore) Enabled(level zapcore.Level) bool {
+	return c.levels.Enabled(level)
+}
+
+func (c *levelsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelsCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.levelFor(entry.LoggerName).Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// samplingCore samples the entries of the wrapped core logged at the debug level and above, to prevent a resource
+// flapping between states from flooding the logs. The first entries with a given level and message are logged every
+// second, then only one in thereafter. Entries of the custom levels more verbose than debug are not sampled.
+type samplingCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+func newSamplingCore(core zapcore.Core, initial, thereafter int) zapcore.Core {
+	return &samplingCore{Core: core, sampled: zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter)}
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), sampled: c.sampled.With(fields)}
+}
+
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < zapcore.DebugLevel {
+		return c.Core.Check(entry, checked)
+	}
+	return c.sampled.Check(entry, checked)
+}
@@ -0,0 +1,94 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseLoggerVerbosities(t *testing.T) {
+	got, err := ParseLoggerVerbosities([]string{"elasticsearch-controller=1", " license-controller = -1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"elasticsearch-controller": 1, "license-controller": -1}, got)
+
+	for _, invalid := range []string{"elasticsearch-controller", "=1", "elasticsearch-controller=debug"} {
+		_, err := ParseLoggerVerbosities([]string{invalid})
+		require.Error(t, err)
+	}
+}
+
+func write(logger *zap.Logger, level zapcore.Level, message string) {
+	if entry := logger.Check(level, message); entry != nil {
+		entry.Write()
+	}
+}
+
+func Test_levelsCore(t *testing.T) {
+	l := &loggerLevels{
+		global: zap.NewAtomicLevelAt(zapcore.InfoLevel),
+		levels: map[string]zapcore.Level{"elasticsearch-controller": zapcore.Level(-2), "license-controller": zapcore.ErrorLevel},
+	}
+	observed, logs := observer.New(zapcore.Level(-10))
+	logger := zap.New(&levelsCore{Core: observed, levels: l})
+
+	write(logger.Named("elasticsearch-controller").Named("driver"), zapcore.Level(-2), "logged with the verbosity of the parent logger")
+	write(logger.Named("elasticsearch-controller"), zapcore.Level(-3), "dropped")
+	logger.Named("license-controller").Info("dropped")
+	logger.Named("kibana-controller").Info("logged with the global verbosity")
+	logger.Named("kibana-controller").Debug("dropped")
+
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	require.Equal(t, []string{"logged with the verbosity of the parent logger", "logged with the global verbosity"}, messages)
+}
+
+func Test_samplingCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.Level(-10))
+	logger := zap.New(newSamplingCore(observed, 2, 100))
+
+	for i := 0; i < 10; i++ {
+		logger.Info("sampled")
+		write(logger, zapcore.Level(-2), "not sampled")
+	}
+	require.Equal(t, 2, logs.FilterMessage("sampled").Len())
+	require.Equal(t, 10, logs.FilterMessage("not sampled").Len())
+}
+
+func TestLevelsHandler(t *testing.T) {
+	defer SetLoggerVerbosities(nil)
+	handler := LevelsHandler()
+	call := func(method, query string) (int, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/debug/log-levels"+query, nil))
+		var body map[string]interface{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+		return recorder.Code, body
+	}
+
+	code, body := call(http.MethodPut, "?logger=elasticsearch-controller&verbosity=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]interface{}{"elasticsearch-controller": float64(2)}, body["loggers"])
+	require.Equal(t, zapcore.Level(-2), levels.levelFor("elasticsearch-controller.driver"))
+
+	code, _ = call(http.MethodPut, "?logger=elasticsearch-controller")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, body = call(http.MethodDelete, "?logger=elasticsearch-controller")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, body["loggers"])
+
+	code, _ = call(http.MethodPost, "")
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -48,9 +48,33 @@ func init() {
 
 var verbosity = flag.Int(FlagName, 0, "Verbosity level of logs (-2=Error, -1=Warn, 0=Info, >0=Debug)")
 
+// sampling of the logs, disabled if samplingInitial is not positive
+var samplingInitial, samplingThereafter = 100, 100
+
 // BindFlags attaches logging flags to the given flag set.
 func BindFlags(flags *pflag.FlagSet) {
 	flags.AddGoFlag(flag.Lookup("log-verbosity"))
+	flags.StringSlice(
+		LevelsFlagName,
+		[]string{},
+		"Comma-separated list of <logger>=<verbosity> pairs overriding log-verbosity for specific loggers and their child loggers, such as elasticsearch-controller=1",
+	)
+	flags.Int(
+		SamplingInitialFlagName,
+		samplingInitial,
+		"Number of log entries with the same level and message logged every second before sampling them (set 0 to disable sampling)",
+	)
+	flags.Int(
+		SamplingThereafterFlagName,
+		samplingThereafter,
+		"Once log-sampling-initial is reached, only one log entry with the same level and message is logged every log-sampling-thereafter entries during the rest of the second",
+	)
+}
+
+// SetSampling configures the sampling of the logs applied by the next logger set up. Sampling is disabled if initial is
+// not positive, and in development mode.
+func SetSampling(initial, thereafter int) {
+	samplingInitial, samplingThereafter = initial, thereafter
 }
 
 // InitLogger initializes the global logger informed by the value of log-verbosity flag.
@@ -73,6 +97,7 @@ func ChangeVerbosity(v int) {
 
 func setLogger(v *int) {
 	zapLevel := determineLogLevel(v)
+	levels.global.SetLevel(zapLevel.Level())
 
 	// if the Zap custom level is less than debug (verbosity level 2 and above) set the klog level to the same level
 	if zapLevel.Level() < zap.DebugLevel {
@@ -106,11 +131,20 @@ func setLogger(v *int) {
 			))
 	}
 
+	// entries are filtered by the verbosity of the logger that emits them, the underlying core enables all levels
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if samplingInitial > 0 && samplingThereafter > 0 && !dev.Enabled {
+			core = newSamplingCore(core, samplingInitial, samplingThereafter)
+		}
+		return &levelsCore{Core: core, levels: levels}
+	}))
+	allLevels := zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+
 	stackTraceLevel := zap.NewAtomicLevelAt(zapcore.ErrorLevel)
 	crlog.SetLogger(crzap.New(func(o *crzap.Options) {
 		o.DestWriter = os.Stderr
 		o.Development = dev.Enabled
-		o.Level = &zapLevel
+		o.Level = allLevels
 		o.StacktraceLevel = &stackTraceLevel
 		o.Encoder = encoder
 		o.ZapOpts = opts
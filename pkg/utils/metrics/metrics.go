@@ -16,10 +16,18 @@ const (
 	namespace          = "elastic"
 	LeaderKey          = "leader"
 	licensingSubsystem = "licensing"
+	esClientSubsystem  = "elasticsearch_client"
+	esSubsystem        = "elasticsearch"
 
 	LicenseLevelLabel      = "license_level"
 	OperatorNamespaceLabel = "operator_namespace"
+	ResultLabel            = "result"
 	UUIDLabel              = "uuid"
+	NamespaceLabel         = "namespace"
+	ESNameLabel            = "es_name"
+	PhaseLabel             = "phase"
+	HealthLabel            = "health"
+	TriggerLabel           = "trigger"
 )
 
 var (
@@ -52,6 +60,104 @@ var (
 		Name:      "memory_gigabytes_total",
 		Help:      "Total memory used in GB",
 	}, []string{LicenseLevelLabel}))
+
+	// LicensingClusterExpiryGauge reports the expiry date of the license of each Elasticsearch cluster.
+	LicensingClusterExpiryGauge = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: licensingSubsystem,
+		Name:      "cluster_license_expiry_timestamp_seconds",
+		Help:      "Unix time at which the license of the Elasticsearch cluster expires, 0 for a basic license",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESClientPoolSize reports the number of HTTP clients pooled for Elasticsearch clusters.
+	ESClientPoolSize = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esClientSubsystem,
+		Name:      "pool_size",
+		Help:      "Number of HTTP clients pooled for Elasticsearch clusters",
+	}, []string{}))
+
+	// ESClientPoolRequests counts the requests for an HTTP client to the pool, by result (hit, miss or rebuild).
+	ESClientPoolRequests = registerCounter(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: esClientSubsystem,
+		Name:      "pool_requests_total",
+		Help:      "Total number of requests for an HTTP client to the pool, by result",
+	}, []string{ResultLabel}))
+
+	// ESReconcileDuration reports the duration of the reconciliations of each Elasticsearch cluster.
+	ESReconcileDuration = registerHistogram(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations of the Elasticsearch cluster",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESPhase reports the orchestration phase of each Elasticsearch cluster: 1 for the current phase, 0 for the others.
+	ESPhase = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "phase",
+		Help:      "Orchestration phase of the Elasticsearch cluster, 1 for the current phase",
+	}, []string{NamespaceLabel, ESNameLabel, PhaseLabel}))
+
+	// ESHealth reports the health of each Elasticsearch cluster: 1 for the current health, 0 for the others.
+	ESHealth = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "health",
+		Help:      "Health of the Elasticsearch cluster, 1 for the current health",
+	}, []string{NamespaceLabel, ESNameLabel, HealthLabel}))
+
+	// ESAvailableNodes reports the number of available nodes of each Elasticsearch cluster.
+	ESAvailableNodes = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "available_nodes",
+		Help:      "Number of available nodes of the Elasticsearch cluster",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESPendingPodChanges reports the number of Pods of each Elasticsearch cluster to be created, deleted or updated.
+	ESPendingPodChanges = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "pending_pod_changes",
+		Help:      "Number of Pods of the Elasticsearch cluster to be created, deleted or updated",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESReconcileError reports whether the last reconciliation of each Elasticsearch cluster failed.
+	ESReconcileError = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "reconcile_error",
+		Help:      "Whether the last reconciliation of the Elasticsearch cluster failed (1) or not (0)",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESLastReconcileErrorTimestamp reports the time of the last failed reconciliation of each Elasticsearch cluster.
+	ESLastReconcileErrorTimestamp = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "last_reconcile_error_timestamp_seconds",
+		Help:      "Unix time of the last failed reconciliation of the Elasticsearch cluster",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESPhaseTransitionTimestamp reports the time at which each Elasticsearch cluster entered its current phase.
+	ESPhaseTransitionTimestamp = registerGauge(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "phase_transition_timestamp_seconds",
+		Help:      "Unix time at which the Elasticsearch cluster entered its current phase",
+	}, []string{NamespaceLabel, ESNameLabel}))
+
+	// ESCredentialsRotations counts the rotations of the passwords of the elastic and internal users of each
+	// Elasticsearch cluster, by trigger (annotation or interval).
+	ESCredentialsRotations = registerCounter(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: esSubsystem,
+		Name:      "credentials_rotations_total",
+		Help:      "Total number of rotations of the passwords of the elastic and internal users of the Elasticsearch cluster, by trigger",
+	}, []string{NamespaceLabel, ESNameLabel, TriggerLabel}))
 )
 
 func registerGauge(gauge *prometheus.GaugeVec) *prometheus.GaugeVec {
@@ -67,3 +173,31 @@ func registerGauge(gauge *prometheus.GaugeVec) *prometheus.GaugeVec {
 
 	return gauge
 }
+
+func registerCounter(counter *prometheus.CounterVec) *prometheus.CounterVec {
+	err := crmetrics.Registry.Register(counter)
+	if err != nil {
+		existsErr := new(prometheus.AlreadyRegisteredError)
+		if errors.As(err, &existsErr) {
+			return existsErr.ExistingCollector.(*prometheus.CounterVec)
+		}
+
+		panic(fmt.Errorf("failed to register counter: %w", err))
+	}
+
+	return counter
+}
+
+func registerHistogram(histogram *prometheus.HistogramVec) *prometheus.HistogramVec {
+	err := crmetrics.Registry.Register(histogram)
+	if err != nil {
+		existsErr := new(prometheus.AlreadyRegisteredError)
+		if errors.As(err, &existsErr) {
+			return existsErr.ExistingCollector.(*prometheus.HistogramVec)
+		}
+
+		panic(fmt.Errorf("failed to register histogram: %w", err))
+	}
+
+	return histogram
+}
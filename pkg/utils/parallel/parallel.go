@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package parallel
+
+import (
+	"sync"
+)
+
+// ForEach calls f for each index in [0, n), running at most maxConcurrency calls at the same time.
+// It waits for all the calls to return, then returns the error of the lowest index if any, so that the outcome is the
+// same as when calling f sequentially until the first error.
+func ForEach(n int, maxConcurrency int, f func(i int) error) error {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	errs := make([]error, n)
+	tokens := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	var running, maxRunning, calls int32
+	err := ForEach(20, 3, func(i int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(20), calls)
+	require.LessOrEqual(t, maxRunning, int32(3))
+
+	// the error of the lowest index is returned, once all calls returned
+	calls = 0
+	err = ForEach(10, 4, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i%3 == 2 {
+			return fmt.Errorf("error %d", i)
+		}
+		return nil
+	})
+	require.Equal(t, errors.New("error 2"), err)
+	require.Equal(t, int32(10), calls)
+
+	require.NoError(t, ForEach(0, 4, func(i int) error { return errors.New("not called") }))
+}
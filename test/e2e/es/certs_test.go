@@ -75,7 +75,7 @@ func TestCustomHTTPCA(t *testing.T) {
 				Name: "Create an invalid CA secret",
 				Test: test.Eventually(func() error {
 					bogusSecret := mkCertSecret([]byte("garbage"), []byte("more garbage"))
-					_, err := reconciler.ReconcileSecret(k.Client, bogusSecret, nil)
+					_, err := reconciler.ReconcileSecret(k.Client, bogusSecret, nil, false)
 					return err
 				}),
 			}).
@@ -122,7 +122,7 @@ func TestCustomHTTPCA(t *testing.T) {
 						certificates.EncodePEMCert(customCA.Cert.Raw),
 						privateKey,
 					)
-					_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil)
+					_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil, false)
 					return err
 				}),
 			},
@@ -208,7 +208,7 @@ func TestHTTPECDSA(t *testing.T) {
 						certificates.EncodePEMCert(customCA.Cert.Raw),
 						privateKey,
 					)
-					_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil)
+					_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil, false)
 					return err
 				}),
 			},
@@ -337,7 +337,7 @@ func TestCustomTransportCA(t *testing.T) {
 					Name: "Create an invalid CA secret",
 					Test: test.Eventually(func() error {
 						bogusSecret := mkCertSecret([]byte("garbage"), []byte("more garbage"))
-						_, err := reconciler.ReconcileSecret(k.Client, bogusSecret, nil)
+						_, err := reconciler.ReconcileSecret(k.Client, bogusSecret, nil, false)
 						return err
 					}),
 				},
@@ -399,7 +399,7 @@ func TestCustomTransportCA(t *testing.T) {
 							certificates.EncodePEMCert(ca.Cert.Raw),
 							privateKey,
 						)
-						_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil)
+						_, err = reconciler.ReconcileSecret(k.Client, caSecret, nil, false)
 						return err
 					}),
 				},
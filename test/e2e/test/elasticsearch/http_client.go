@@ -55,6 +55,7 @@ func NewElasticsearchClientWithUser(es esv1.Elasticsearch, k *test.K8sClient, us
 		v,
 		caCert,
 		client.Timeout(es),
+		false,
 	)
 	return esClient, nil
 }